@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+	"github.com/songzhibin97/perfinspector/pkg/locator"
+	"github.com/songzhibin97/perfinspector/pkg/reporter"
+	"github.com/songzhibin97/perfinspector/pkg/rules"
+)
+
+// logLevel 诊断信息的输出级别
+type logLevel int
+
+const (
+	logQuiet   logLevel = iota // 只输出错误
+	logNormal                  // 默认：警告、进度和 -stats/-debug 的输出
+	logVerbose                 // 额外输出每个解析的文件、每条规则的评估结果和生成的问题上下文
+)
+
+// cliLogger 按级别向标准错误输出诊断信息
+type cliLogger struct {
+	w     io.Writer
+	level logLevel
+}
+
+// newLogger 创建 cliLogger，noEmoji 时 emoji 替换为 ASCII 符号
+func newLogger(w io.Writer, level logLevel, noEmoji bool) *cliLogger {
+	if noEmoji {
+		w = reporter.NewPlainWriter(w)
+	}
+	return &cliLogger{w: w, level: level}
+}
+
+// Writer 返回 level 级别的输出，当前级别低于 level 时丢弃写入
+// 用于接收 log 包和 io.Writer 形式的诊断输出
+func (l *cliLogger) Writer(level logLevel) io.Writer {
+	if l.level < level {
+		return io.Discard
+	}
+	return l.w
+}
+
+// Errorf 输出错误，所有级别都输出
+func (l *cliLogger) Errorf(format string, args ...interface{}) {
+	fmt.Fprintf(l.w, format+"\n", args...)
+}
+
+// Infof 输出警告和进度，-quiet 时不输出
+func (l *cliLogger) Infof(format string, args ...interface{}) {
+	fmt.Fprintf(l.Writer(logNormal), format+"\n", args...)
+}
+
+// Verbosef 输出逐项的详细信息，只在 -verbose 时输出
+func (l *cliLogger) Verbosef(format string, args ...interface{}) {
+	fmt.Fprintf(l.Writer(logVerbose), format+"\n", args...)
+}
+
+// Warnings 逐条输出警告
+func (l *cliLogger) Warnings(warnings []string) {
+	for _, warning := range warnings {
+		l.Infof("⚠️ %s", warning)
+	}
+}
+
+// ParsedFiles 输出每个解析的 profile 文件的类型、时间和样本数
+func (l *cliLogger) ParsedFiles(groups []analyzer.ProfileGroup) {
+	if l.level < logVerbose {
+		return
+	}
+	for _, group := range groups {
+		for _, file := range group.Files {
+			var samples int64
+			if file.Metrics != nil {
+				samples = file.Metrics.TotalSamples
+			}
+			l.Verbosef("📄 已解析 %s: %s, %s, %s 个样本", file.Path, group.Type, file.Time.Format(time.RFC3339), analyzer.FormatInt(samples))
+		}
+	}
+}
+
+// RuleOutcomes 输出每条规则的评估结果
+func (l *cliLogger) RuleOutcomes(stats rules.EvaluationStats) {
+	for _, outcome := range stats.Outcomes {
+		l.Verbosef("📋 规则 %s: %s", outcome.RuleID, outcome.Outcome)
+	}
+}
+
+// Contexts 按规则 ID 顺序输出生成的问题上下文
+func (l *cliLogger) Contexts(contexts map[string]*locator.ProblemContext) {
+	if l.level < logVerbose {
+		return
+	}
+	ids := make([]string, 0, len(contexts))
+	for id := range contexts {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		ctx := contexts[id]
+		if ctx == nil {
+			continue
+		}
+		l.Verbosef("🔍 问题上下文 %s: %d 条热点路径, %d 条命令", id, len(ctx.HotPaths), len(ctx.Commands))
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+	"github.com/songzhibin97/perfinspector/pkg/locator"
+	"github.com/songzhibin97/perfinspector/pkg/rules"
+	"github.com/stretchr/testify/assert"
+)
+
+// logAll 按各种级别输出一遍诊断信息
+func logAll(logger *cliLogger) {
+	logger.Errorf("Error: %s", "boom")
+	logger.Warnings([]string{"规则加载失败"})
+	log.New(logger.Writer(logNormal), "", 0).Print("跳过文件")
+	logger.ParsedFiles([]analyzer.ProfileGroup{{
+		Type:  "heap",
+		Files: []analyzer.ProfileFile{{Path: "heap.pprof", Time: time.Date(2023, 11, 15, 14, 30, 0, 0, time.UTC), Metrics: &analyzer.ProfileMetrics{TotalSamples: 1200}}},
+	}})
+	logger.RuleOutcomes(rules.EvaluationStats{Outcomes: []rules.RuleOutcome{{RuleID: "memory_leak", Outcome: rules.OutcomeMatched}}})
+	logger.Contexts(map[string]*locator.ProblemContext{"memory_leak": {HotPaths: make([]locator.HotPath, 2)}, "missing": nil})
+}
+
+func TestCLILogger_Levels(t *testing.T) {
+	var buf bytes.Buffer
+	logAll(newLogger(&buf, logQuiet, false))
+	assert.Equal(t, "Error: boom\n", buf.String())
+
+	buf.Reset()
+	logAll(newLogger(&buf, logNormal, false))
+	assert.Equal(t, "Error: boom\n⚠️ 规则加载失败\n跳过文件\n", buf.String())
+
+	buf.Reset()
+	logAll(newLogger(&buf, logVerbose, false))
+	assert.Equal(t, "Error: boom\n⚠️ 规则加载失败\n跳过文件\n"+
+		"📄 已解析 heap.pprof: heap, 2023-11-15T14:30:00Z, 1,200 个样本\n"+
+		"📋 规则 memory_leak: matched\n"+
+		"🔍 问题上下文 memory_leak: 2 条热点路径, 0 条命令\n", buf.String())
+}
+
+func TestCLILogger_NoEmoji(t *testing.T) {
+	var buf bytes.Buffer
+	newLogger(&buf, logNormal, true).Warnings([]string{"规则加载失败"})
+	assert.Equal(t, "[!] 规则加载失败\n", buf.String())
+}
@@ -1,97 +1,505 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"log"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/pprof/profile"
 	"github.com/songzhibin97/perfinspector/pkg/analyzer"
 	"github.com/songzhibin97/perfinspector/pkg/locator"
+	"github.com/songzhibin97/perfinspector/pkg/parser"
+	"github.com/songzhibin97/perfinspector/pkg/perfinspector"
 	"github.com/songzhibin97/perfinspector/pkg/reporter"
 	"github.com/songzhibin97/perfinspector/pkg/rules"
+	"github.com/songzhibin97/perfinspector/pkg/server"
 )
 
 // Config 命令行配置
 type Config struct {
-	InputPath  string // 输入路径（目录或文件）
-	Format     string // 输出格式: text, html
-	OutputPath string // 输出文件路径
-	RulesPath  string // 规则文件路径
+	InputPaths []string // 输入路径（目录或文件）
+	PathsFrom  string   // profile 路径清单文件，"-" 表示标准输入
+	Extensions []string // 额外接受的 profile 文件扩展名
+	Sniff      bool     // 通过文件头识别没有扩展名的 profile 文件
+	Format     string   // 输出格式: text, html, json, markdown, dot, csv, summary
+	OutputPath string   // 输出文件路径
+	RulesPath  string   // 规则文件路径
+	MetricsOut string   // Prometheus 指标输出文件路径
+	History    string   // 运行历史文件路径，用于与上一次运行对比
+	OnlyNew    bool     // 只展示相对上一次运行新增或恶化的发现，需要 -history
+	TUI        bool     // 交互式浏览模式
+	Serve      string   // 以 HTTP 服务提供实时 HTML 报告的监听地址，空表示生成一次报告
+	MaxUpload  int64    // -serve 时 POST /analyze 请求体的上限 (MB)
+	Debug      bool     // 输出调试日志
+	Quiet      bool     // 只输出报告路径和错误
+	Verbose    bool     // 输出每个解析的文件、每条规则的评估结果和生成的问题上下文
+	Stats      bool     // 输出规则评估汇总
+	FailOn     string   // 存在不低于该严重程度的发现时以退出码 2 结束，空表示不检查
+
+	Concurrency int           // 并行解析文件和定位问题的最大 goroutine 数
+	Timeout     time.Duration // 解析和定位问题的总超时，0 表示不限制
+
+	OrderByFilename bool   // 组内文件按文件名序号而不是采集时间排序
+	ValueType       string // 无法识别类型的 profile 使用的 sample type，为空时使用第一个
+	Dedup           bool   // 指标相同的连续快照只保留最早的一个参与趋势计算
+	// Window 只分析采集时间在 -since/-until 之间的快照，零值表示不限制
+	Window analyzer.TimeWindow
+
+	// 基准测试模式
+	Bench       bool   // 过滤 testing 框架帧，按每次操作展示消耗
+	BenchN      int64  // 基准测试迭代次数，0 表示未知
+	BenchOutput string // go test -bench 输出文件路径，用于读取迭代次数
 
 	// Problem Locator 配置
-	ModuleName         string   // 用户模块名
-	ThirdPartyPrefixes []string // 额外的第三方包前缀
-	StackDepth         int      // 最大调用栈深度
-	HotPaths           int      // 最大热点路径数
+	ModuleName         string                          // 用户模块名
+	ThirdPartyPrefixes []string                        // 额外的第三方包前缀
+	ClassifyOverrides  map[string]locator.CodeCategory // 包路径前缀到分类的覆盖
+	StackDepth         int                             // 最大调用栈深度
+	HotPaths           int                             // 最大热点路径数
+	ReadableNames      bool                            // 使用格式化的函数展示名
+	HideRuntimeOnly    bool                            // 排除没有业务代码的热点路径
+	CollapseRecursion  bool                            // 折叠调用链中连续重复的递归帧
+	RootCausePolicy    locator.RootCausePolicy         // 根因帧选择策略
+	WindowPivot        time.Time                       // 热点迁移对比的切分时间点，零值表示按快照数对半切分
+	CommandsTopOnly    bool                            // 只为排名第一的热点路径生成 -focus/-list 命令
+	CommandTemplate    locator.CommandTemplate         // 生成命令的前缀、参数写法和模板
+	ExplainFunc        string                          // 只输出该函数的视图，空表示输出完整报告
+	Index              bool                            // 只输出所有 profile 的汇总表 (文件、类型、时间、大小、样本数、关键指标)
+
+	// 报告配置
+	TrendThresholds analyzer.TrendThresholds // 趋势展示的 R² 阈值
+	HeapSampleType  string                   // heap 趋势使用的 sample type
+	MinTrendPoints  int                      // 计算趋势和评估趋势规则所需的最少快照数
+	GeneratedAt     time.Time                // 报告生成时间，零值表示当前时间
+	Limits          reporter.Limits          // 报告规模上限
+	Sort            reporter.SortOptions     // 分组和文件的展示顺序
+	CategoryConfig  string                   // 代码分类样式配置文件路径 (图标、展示名、颜色)
+	NoEmoji         bool                     // 报告和诊断信息中的 emoji 替换为 ASCII 符号
+	// HTML 报告源码位置的链接模板，{file} 替换为相对模块根目录的路径、{line} 替换为行号；为空时生成 file:// 链接
+	SourceLinkTemplate string
+	SourceRoot         string // 采集环境中的模块根目录，为空时按模块名在文件路径中定位
+	HTMLTemplatePath   string // 自定义 HTML 报告模板文件，为空时使用内置模板
 }
 
 // DefaultRulesPath 默认规则文件路径
 const DefaultRulesPath = "assets/default_rules.yaml"
 
+// exitFailOn 存在达到 -fail-on 严重程度的发现时的退出码，与出错时的 1 区分
+const exitFailOn = 2
+
 func main() {
+	startTime := time.Now()
+
 	config, err := parseArgs()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	paths, err := getProfilePaths(config.InputPath)
+	// 诊断信息 (警告、解析日志、-stats 汇总) 与报告一样按 -no-emoji 输出，按 -quiet/-verbose 过滤
+	logger := newLogger(os.Stderr, config.logLevel(), config.NoEmoji)
+	log.SetOutput(logger.Writer(logNormal))
+
+	if err := applyCategoryConfig(config.CategoryConfig, config.NoEmoji); err != nil {
+		logger.Errorf("Error: %v", err)
+		os.Exit(1)
+	}
+
+	inputs := config.InputPaths
+	if config.PathsFrom != "" {
+		listed, err := readPathsFrom(config.PathsFrom, os.Stdin)
+		if err != nil {
+			logger.Errorf("Error: %v", err)
+			os.Exit(1)
+		}
+		inputs = append(inputs, listed...)
+	}
+
+	paths, err := collectProfilePathsWithFilter(inputs, newProfileFilter(config.Extensions, config.Sniff))
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		logger.Errorf("Error: %v", err)
 		os.Exit(1)
 	}
 
 	if len(paths) == 0 {
-		fmt.Fprintln(os.Stderr, "No valid profile files found")
+		logger.Errorf("No valid profile files found")
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	if config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.Timeout)
+		defer cancel()
+	}
+
+	// 分析选项，基准测试迭代次数可能需要从 -bench-output 读取
+	opts := createOptions(config)
+	if config.Bench {
+		opts.BenchN, err = resolveBenchIterations(config)
+		if err != nil {
+			logger.Errorf("Error: %v", err)
+			os.Exit(1)
+		}
+	}
+
+	// 以 HTTP 服务提供报告，每次 profile 文件变化后重新展开输入路径并分析
+	if config.Serve != "" {
+		filter := newProfileFilter(config.Extensions, config.Sniff)
+		logger.Infof("🌐 报告服务已启动: http://%s", serveURLHost(config.Serve))
+		err := server.Serve(config.Serve, server.Config{
+			Inputs:        inputs,
+			Resolve:       func(inputs []string) ([]string, error) { return collectProfilePathsWithFilter(inputs, filter) },
+			Options:       opts,
+			ReportOptions: createReportOptions(config),
+			Timeout:       config.Timeout,
+			MaxUploadSize: config.MaxUpload << 20,
+		})
+		logger.Errorf("Error: %v", err)
 		os.Exit(1)
 	}
 
-	// 分组分析
-	groups, err := analyzer.GroupProfiles(paths)
+	// 解析并分组
+	loaded, err := perfinspector.LoadCtx(ctx, paths, opts)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Analysis failed: %v\n", err)
+		var valueTypeErr *perfinspector.ValueTypeError
+		if errors.As(err, &valueTypeErr) {
+			logger.Errorf("Error: -value-type: %v", valueTypeErr.Err)
+			os.Exit(1)
+		}
+		logger.Errorf("Analysis failed: %v", err)
 		os.Exit(1)
 	}
+	logger.Warnings(loaded.Warnings)
+	groups := loaded.Groups
+	logger.ParsedFiles(groups)
 
-	// 计算趋势
-	trends := make(map[string]*analyzer.GroupTrends)
-	for _, group := range groups {
-		if t := analyzer.CalculateTrends(group); t != nil {
-			trends[group.Type] = t
+	// 只输出文件索引，不分析热点和规则
+	if config.Index {
+		if err := renderIndex(config.Format, config.OutputPath, reporter.BuildIndex(groups), createReportOptions(config), logger); err != nil {
+			logger.Errorf("Error: %v", err)
+			os.Exit(1)
 		}
+		return
 	}
 
-	// 加载规则引擎
-	var findings []rules.Finding
-	engine, err := rules.NewEngine(config.RulesPath)
-	if err != nil {
-		// 规则加载失败只是警告，不影响主流程
-		fmt.Fprintf(os.Stderr, "⚠️ 规则加载失败: %v\n", err)
-	} else if engine != nil {
-		findings = engine.Evaluate(groups, trends)
+	// 单个函数的视图，不评估规则
+	if config.ExplainFunc != "" {
+		locatorConfig := opts.LocatorConfig()
+		logger.Warnings(locatorConfig.NormalizeThirdPartyPrefixes())
+		report, err := buildFunctionReport(ctx, groups, config, locatorConfig)
+		if err != nil {
+			logger.Errorf("Analysis failed: %v", err)
+			os.Exit(1)
+		}
+		reporter.GenerateFunctionReport(report, createReportOptions(config))
+		return
 	}
 
-	// 初始化 Problem Locator
-	locatorConfig := createLocatorConfig(config)
-	contexts := generateProblemContexts(findings, groups, locatorConfig)
+	// 计算趋势、评估规则并定位问题上下文
+	result := perfinspector.AnalyzeGroupsCtx(ctx, groups, opts)
+	logger.Warnings(result.Warnings)
+	trends, findings, contexts, ruleStats := result.Trends, result.Findings, result.Contexts, result.RuleStats
+	logger.RuleOutcomes(ruleStats)
+	logger.Contexts(contexts)
+	if config.Debug {
+		logTrendWeights(logger.Writer(logNormal), trends)
+	}
 
 	// 生成报告
-	switch config.Format {
-	case "html":
-		outputPath := config.OutputPath
-		if outputPath == "" {
-			outputPath = "report.html"
+	reportOptions := createReportOptions(config)
+
+	// 与上一次运行对比关键指标
+	var history *reporter.History
+	var runSummary reporter.RunSummary
+	runKey := historyKey(inputs)
+	if config.History != "" {
+		runAt := config.GeneratedAt
+		if runAt.IsZero() {
+			runAt = startTime
 		}
-		if err := reporter.GenerateHTMLReportWithContext(groups, trends, findings, contexts, outputPath); err != nil {
-			fmt.Fprintf(os.Stderr, "HTML report generation failed: %v\n", err)
+		runSummary = reporter.SummarizeRun(groups, findings, runAt)
+		runSummary.Findings = reporter.SummarizeFindings(findings, contexts)
+		history, err = reporter.LoadHistory(config.History)
+		if err != nil {
+			// 历史文件损坏时不覆盖它，报告照常生成
+			logger.Infof("⚠️ 运行历史读取失败: %v", err)
+		} else {
+			reportOptions.History = history.Compare(runKey, runSummary)
+			reportOptions.FindingChanges = history.CompareFindings(runKey, runSummary)
+		}
+	}
+
+	// -only-new 只渲染新增和恶化的发现；指标输出和运行历史仍使用全部发现
+	shownFindings := findings
+	if config.OnlyNew && reportOptions.FindingChanges != nil {
+		shownFindings, reportOptions.FindingChanges.Hidden = reportOptions.FindingChanges.OnlyChanged(findings)
+	}
+	switch {
+	case config.TUI:
+		restore, ok := reporter.EnableTUITerminal(os.Stdin)
+		err := reporter.RunTUI(shownFindings, contexts, os.Stdin, os.Stdout, !ok, reportOptions)
+		restore()
+		if err != nil {
+			logger.Errorf("TUI failed: %v", err)
 			os.Exit(1)
 		}
-		fmt.Printf("✅ HTML 报告已生成: %s\n", outputPath)
 	default:
-		reporter.GenerateTextReportWithContext(groups, trends, findings, contexts)
+		report := &reporter.Report{Groups: groups, Trends: trends, Findings: shownFindings, Contexts: contexts, Options: reportOptions}
+		if err := renderReport(config.Format, config.OutputPath, report, logger); err != nil {
+			logger.Errorf("Report generation failed: %v", err)
+			os.Exit(1)
+		}
+	}
+
+	// 输出 Prometheus 指标
+	if config.MetricsOut != "" {
+		if err := reporter.GeneratePrometheusMetrics(groups, trends, findings, time.Since(startTime), config.MetricsOut); err != nil {
+			logger.Errorf("Metrics generation failed: %v", err)
+			os.Exit(1)
+		}
+	}
+
+	// 规则评估汇总，输出到标准错误，不影响 DOT 等写入标准输出的报告
+	if config.Stats {
+		logger.Infof("📋 %s", ruleStats)
+	}
+
+	// 保存本次运行的关键指标，供下一次运行对比
+	if history != nil {
+		history.Record(runKey, runSummary)
+		if err := reporter.SaveHistory(config.History, history); err != nil {
+			logger.Infof("⚠️ 运行历史保存失败: %v", err)
+		}
+	}
+
+	// 报告和历史都已写出后再按 -fail-on 决定退出码，CI 中失败的构建同样留有报告
+	if config.FailOn != "" {
+		if count := countFindingsAtLeast(findings, config.FailOn); count > 0 {
+			logger.Errorf("❌ %d 个发现达到 -fail-on %s", count, config.FailOn)
+			os.Exit(exitFailOn)
+		}
+	}
+}
+
+// logLevel 返回 -quiet/-verbose 对应的诊断信息级别
+func (c *Config) logLevel() logLevel {
+	switch {
+	case c.Quiet:
+		return logQuiet
+	case c.Verbose:
+		return logVerbose
+	default:
+		return logNormal
+	}
+}
+
+// countFindingsAtLeast 统计严重程度不低于 severity 的发现数，严重程度按 locator 的规则标准化 ("高" 与 "high" 等价)
+func countFindingsAtLeast(findings []rules.Finding, severity string) int {
+	threshold := locator.SeverityRank(severity)
+	count := 0
+	for _, f := range findings {
+		if locator.SeverityRank(f.Severity) >= threshold {
+			count++
+		}
+	}
+	return count
+}
+
+// parseTimeBound 解析 -since/-until 的值，支持 RFC3339 和相对 now 的时长 (如 -2h)，空值返回零值
+func parseTimeBound(name, value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid %s '%s', must be RFC3339 (e.g. 2023-11-15T14:30:00Z) or a duration relative to now (e.g. -2h)", name, value)
+}
+
+// serveURLHost 返回监听地址在浏览器中访问的 host，":8080" 这样省略主机名的地址使用 localhost
+func serveURLHost(addr string) string {
+	if strings.HasPrefix(addr, ":") {
+		return "localhost" + addr
+	}
+	return addr
+}
+
+// renderReport 使用 format 对应的渲染器输出报告
+// outputPath 为空时 html 写入 report.html，其他格式写入标准输出 (便于将 dot 管道给 dot -Tsvg)；
+// 写入文件后通过 logger 输出报告路径，与其他诊断信息一样遵循 -no-emoji 和 -quiet
+func renderReport(format, outputPath string, report *reporter.Report, logger *cliLogger) error {
+	renderer, ok := reporter.LookupRenderer(format)
+	if !ok {
+		return fmt.Errorf("unknown format '%s'", format)
+	}
+	if outputPath == "" && format == "html" {
+		outputPath = "report.html"
+	}
+	if outputPath == "" {
+		return renderer.Render(report, os.Stdout)
+	}
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create output file '%s': %w", outputPath, err)
+	}
+	if err := renderer.Render(report, file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write output file '%s': %w", outputPath, err)
+	}
+
+	switch format {
+	case "html":
+		logger.Infof("✅ HTML 报告已生成: %s", outputPath)
+	case "json":
+		logger.Infof("✅ JSON 报告已生成: %s", outputPath)
+	case "markdown":
+		logger.Infof("✅ Markdown 报告已生成: %s", outputPath)
+	case "dot":
+		logger.Infof("✅ DOT 调用图已生成: %s", outputPath)
+	case "csv":
+		logger.Infof("✅ CSV 报告已生成: %s", outputPath)
+	case "summary":
+		logger.Infof("✅ 摘要已生成: %s", outputPath)
+	default:
+		logger.Infof("✅ 报告已生成: %s", outputPath)
+	}
+	return nil
+}
+
+// renderIndex 输出 -index 的文件索引，outputPath 为空时 html 写入 index.html，text 写入标准输出
+func renderIndex(format, outputPath string, rows []reporter.IndexRow, opts reporter.Options, logger *cliLogger) error {
+	write := reporter.WriteTextIndex
+	if format == "html" {
+		write = reporter.WriteHTMLIndex
+		if outputPath == "" {
+			outputPath = "index.html"
+		}
+	}
+	if outputPath == "" {
+		return write(os.Stdout, rows, opts)
+	}
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create output file '%s': %w", outputPath, err)
+	}
+	if err := write(file, rows, opts); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write output file '%s': %w", outputPath, err)
+	}
+	logger.Infof("✅ 文件索引已生成: %s", outputPath)
+	return nil
+}
+
+// historyKey 返回运行历史中标识本次输入的键：去重排序后的绝对路径，逗号分隔
+// 分析同一目录的定时任务得到相同的键，不同目录的历史互不影响
+func historyKey(inputs []string) string {
+	seen := make(map[string]bool, len(inputs))
+	paths := make([]string, 0, len(inputs))
+	for _, input := range inputs {
+		if abs, err := filepath.Abs(input); err == nil {
+			input = abs
+		}
+		input = filepath.Clean(input)
+		if !seen[input] {
+			seen[input] = true
+			paths = append(paths, input)
+		}
+	}
+	sort.Strings(paths)
+	return strings.Join(paths, ",")
+}
+
+// resolveBenchIterations 返回基准测试迭代次数：优先使用 -bench-n，其次从 -bench-output 解析，都未提供时为 0
+func resolveBenchIterations(config *Config) (int64, error) {
+	if config.BenchN > 0 || config.BenchOutput == "" {
+		return config.BenchN, nil
+	}
+
+	f, err := os.Open(config.BenchOutput)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open benchmark output: %w", err)
+	}
+	defer f.Close()
+
+	results, err := analyzer.ParseBenchmarkOutput(f)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read benchmark output: %w", err)
+	}
+	n, err := analyzer.BenchmarkIterations(results)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", config.BenchOutput, err)
+	}
+	return n, nil
+}
+
+// applyCategoryConfig 加载代码分类样式配置并全局生效，path 为空时使用内置样式
+// noEmoji 时内置的 emoji 图标替换为 ASCII 图标
+func applyCategoryConfig(path string, noEmoji bool) error {
+	theme := locator.DefaultCategoryTheme()
+	if path != "" {
+		loaded, err := locator.LoadCategoryTheme(path)
+		if err != nil {
+			return err
+		}
+		theme = loaded
+	}
+	if noEmoji {
+		theme = locator.PlainIcons(theme)
+	}
+	locator.SetCategoryTheme(theme)
+	return nil
+}
+
+// logTrendWeights 输出各趋势回归使用的加权方式和数据点权重
+func logTrendWeights(w io.Writer, trends map[string]*analyzer.GroupTrends) {
+	types := make([]string, 0, len(trends))
+	for profileType := range trends {
+		types = append(types, profileType)
+	}
+	sort.Strings(types)
+
+	for _, profileType := range types {
+		t := trends[profileType]
+		for _, item := range []struct {
+			metric string
+			trend  *analyzer.TrendMetrics
+		}{
+			{analyzer.MetricHeapInuse, t.HeapInuse},
+			{analyzer.MetricGoroutineCount, t.GoroutineCount},
+		} {
+			if item.trend == nil {
+				continue
+			}
+			weights := make([]string, len(item.trend.Weights))
+			for i, weight := range item.trend.Weights {
+				weights[i] = strconv.FormatFloat(weight, 'g', 4, 64)
+			}
+			fmt.Fprintf(w, "[debug] trend %s: weighting=%s weights=[%s] slope=%.4f r2=%.4f\n",
+				item.metric, item.trend.Weighting, strings.Join(weights, " "), item.trend.Slope, item.trend.R2)
+		}
 	}
 }
 
@@ -100,35 +508,143 @@ func parseArgs() (*Config, error) {
 	config := &Config{}
 
 	// 基础配置
-	flag.StringVar(&config.Format, "format", "text", "输出格式: text, html")
-	flag.StringVar(&config.OutputPath, "output", "", "输出文件路径")
-	flag.StringVar(&config.RulesPath, "rules", DefaultRulesPath, "规则文件路径")
+	flag.StringVar(&config.Format, "format", "text", "输出格式: text, html, json (供程序消费的完整结果), markdown (可粘贴到 issue), dot (热点路径调用图)，csv (每个文件一行的原始指标)，summary (一行 key=value 摘要，供脚本使用)，以及通过 reporter.RegisterRenderer 注册的格式")
+	flag.StringVar(&config.OutputPath, "output", "", "输出文件路径，未指定时 html 写入 report.html，其他格式写入标准输出")
+	flag.StringVar(&config.RulesPath, "rules", DefaultRulesPath, "规则文件路径，多个文件用逗号分隔，后面的文件按规则 ID 覆盖前面的")
+	flag.BoolVar(&config.NoEmoji, "no-emoji", false, "将报告中的 emoji 替换为 ASCII 符号 (如 [!]、[CPU]、[business])，用于不支持 emoji 的终端和日志系统")
+	flag.StringVar(&config.SourceLinkTemplate, "source-link", "", "HTML 报告源码位置的链接模板，如 https://git.example.com/myapp/src/{file}#L{line}；默认生成 file:// 链接")
+	flag.StringVar(&config.SourceRoot, "source-root", "", "采集 profile 的环境中模块根目录，-source-link 的 {file} 为相对它的路径；默认按模块名在路径中定位")
+	flag.StringVar(&config.HTMLTemplatePath, "html-template", "", "自定义 HTML 报告模板文件 (html/template 语法)，数据和辅助函数与内置模板相同；默认使用内置模板")
+	flag.StringVar(&config.CategoryConfig, "category-config", "", "代码分类样式配置文件 (YAML)，自定义各分类的图标、展示名和 HTML/DOT 颜色")
+	flag.BoolVar(&config.TUI, "tui", false, "交互式浏览发现和热点路径 (方向键/hjkl 导航，c 查看命令，q 退出)")
+	flag.StringVar(&config.Serve, "serve", "", "以 HTTP 服务提供实时 HTML 报告的监听地址 (如 :8080)，profile 文件变化后自动重新分析")
+	flag.Int64Var(&config.MaxUpload, "max-upload", server.DefaultMaxUploadSize>>20, "-serve 时 POST /analyze 上传的 profile 总大小上限 (MB)")
+	flag.StringVar(&config.MetricsOut, "metrics-out", "", "额外输出 Prometheus 文本格式指标文件 (如 perfinspector.prom)")
+	flag.StringVar(&config.History, "history", "", "运行历史文件 (JSON)，报告开头展示关键指标相对上一次运行的变化，并记录本次运行")
+	flag.BoolVar(&config.OnlyNew, "only-new", false, "只展示相对 -history 中上一次运行新增或恶化的发现，已解决的发现在报告开头列出")
+	flag.BoolVar(&config.Stats, "stats", false, "运行结束时输出规则评估汇总到标准错误 (评估、匹配、跳过的规则数)")
+	flag.StringVar(&config.FailOn, "fail-on", "", "存在不低于该严重程度的发现时以退出码 2 结束 (low, medium, high, critical)，报告照常生成")
+	flag.BoolVar(&config.Debug, "debug", false, "输出调试日志到标准错误 (如趋势回归的数据点权重)")
+	flag.BoolVar(&config.Quiet, "quiet", false, "只输出报告路径和错误，不输出警告、进度和解析日志，适合 CI")
+	flag.BoolVar(&config.Verbose, "verbose", false, "额外输出每个解析的文件、每条规则的评估结果和生成的问题上下文到标准错误")
+	flag.StringVar(&config.PathsFrom, "paths-from", "", "从清单文件读取 profile 路径，每行一个，支持 # 注释，- 表示标准输入")
+	var extensions string
+	flag.StringVar(&extensions, "ext", "", "额外接受的 profile 文件扩展名，逗号分隔，不区分大小写 (如 .prof,.pb.gz)；默认只接受 .pprof 和 .profile")
+	flag.BoolVar(&config.Sniff, "sniff", false, "通过文件头 (gzip/protobuf) 识别没有扩展名的 profile 文件，不匹配的文件静默跳过")
+	flag.DurationVar(&config.Timeout, "timeout", 0, "解析和定位问题的总超时 (如 30s, 2m)；超时后解析失败退出，定位未完成的发现不附带上下文 (0 表示不限制)")
+	flag.BoolVar(&config.Bench, "bench", false, "基准测试模式：过滤 testing 框架帧 (testing.*, runtime.goexit)，按每次操作展示 CPU 时间和分配量")
+	flag.Int64Var(&config.BenchN, "bench-n", 0, "基准测试迭代次数 (go test -bench 输出中的 N)，用于换算每次操作的消耗")
+	flag.StringVar(&config.BenchOutput, "bench-output", "", "go test -bench 的输出文件，从中读取迭代次数 (-bench-n 优先)")
+	flag.BoolVar(&config.OrderByFilename, "order-by-filename", false, "组内文件按文件名中的序号 (如 heap.003.pprof) 排序，而不是按采集时间；用于采集主机之间存在时钟偏差的场景")
+	flag.StringVar(&config.ValueType, "value-type", "", "无法识别类型的自定义 profile 计算指标和热点路径使用的 sample type (如 orders)；默认使用第一个 sample type")
+	flag.BoolVar(&config.Dedup, "dedup", false, "指标相同 (相对差异不超过 0.1%) 的连续快照只保留采集时间最早的一个参与趋势计算，报告中仍列出所有文件")
+	var since, until string
+	flag.StringVar(&since, "since", "", "只分析采集时间不早于该时间的快照 (RFC3339，如 2023-11-15T14:00:00Z，或相对当前时间，如 -2h)")
+	flag.StringVar(&until, "until", "", "只分析采集时间不晚于该时间的快照 (RFC3339 或相对当前时间，如 -30m)")
+	flag.IntVar(&config.Concurrency, "concurrency", runtime.GOMAXPROCS(0), "并行解析文件和定位问题的最大 goroutine 数 (默认 GOMAXPROCS)")
 
 	// Problem Locator 配置
 	flag.StringVar(&config.ModuleName, "module", "", "用户模块名 (默认从 go.mod 自动检测)")
 	var thirdPartyPrefixes string
 	flag.StringVar(&thirdPartyPrefixes, "third-party-prefixes", "", "额外的第三方包前缀，逗号分隔")
+	var classifyOverrides string
+	flag.StringVar(&classifyOverrides, "classify-override", "", "覆盖自动分类，逗号分隔的 前缀=分类 (business/third_party/stdlib/runtime/unknown)，如 'github.com/x=third_party,internal/shared=business'")
 	flag.IntVar(&config.StackDepth, "stack-depth", 10, "最大调用栈深度 (默认 10)")
 	flag.IntVar(&config.HotPaths, "hot-paths", 5, "最大热点路径数 (默认 5)")
+	flag.BoolVar(&config.ReadableNames, "readable-names", false, "报告中使用易读的函数名，如 Server.handleRequest closure#1")
+	flag.BoolVar(&config.HideRuntimeOnly, "hide-runtime-only", false, "排除没有业务代码帧的热点路径 (如纯 GC/运行时开销)，只汇总其占比")
+	flag.BoolVar(&config.CollapseRecursion, "collapse-recursion", false, "将调用链中连续重复的递归帧折叠为一帧并标注次数 (如 HandleNode ×14)，不占用 -stack-depth")
+	var rootCausePolicy string
+	flag.StringVar(&rootCausePolicy, "root-cause", "deepest", "根因帧选择策略: deepest (最深业务帧), costliest (累计消耗最大的业务帧), boundary (直接调用标准库/运行时的业务帧)")
+	var windowPivot string
+	flag.BoolVar(&config.CommandsTopOnly, "commands-top-only", false, "只为排名第一的热点路径生成 -focus/-list 命令，保持命令列表简洁")
+	flag.StringVar(&config.CommandTemplate.Binary, "pprof-bin", locator.DefaultPprofBinary, "生成命令使用的 pprof 命令前缀，如 pprof、'mycli profile'")
+	var flagStyle string
+	flag.StringVar(&flagStyle, "pprof-flag-style", "single", "生成命令的参数写法: single (-focus=X), double (--focus=X), separate (-focus X)")
+	flag.StringVar(&config.CommandTemplate.Template, "command-template", locator.DefaultCommandTemplate, "生成命令的模板 (text/template)，可用变量 {{.bin}} {{.flags}} {{.profile}} {{.kind}} {{.base}}")
+	flag.StringVar(&config.ExplainFunc, "explain-func", "", "只输出指定函数 (pprof 中的完整名称，如 github.com/myorg/app.HandleOrder) 在所有 profile 中的消耗、趋势和经过它的热点路径")
+	flag.BoolVar(&config.Index, "index", false, "只输出所有 profile 的汇总表 (文件、类型、时间、大小、样本数、关键指标)，支持 text 和 html")
+	flag.StringVar(&windowPivot, "window-pivot", "", "热点迁移对比的切分时间 (RFC3339，如 2023-11-15T14:30:00Z)；默认按快照数对半切分")
+
+	// 报告配置
+	var minR2 string
+	flag.IntVar(&config.MinTrendPoints, "min-trend-points", analyzer.DefaultMinTrendPoints, "计算趋势和评估趋势规则所需的最少快照数 (至少 3)")
+	flag.StringVar(&minR2, "min-r2", "0.7", "趋势展示的 R² 阈值，可按指标覆盖，如 0.7,goroutine_count=0.5")
+	flag.StringVar(&minR2, "trend-confidence", "0.7", "-min-r2 的别名")
+	var heapSampleType string
+	flag.StringVar(&heapSampleType, "heap-trend", analyzer.HeapSampleInuseSpace, "heap 趋势使用的 sample type: inuse_space, alloc_space, inuse_objects, alloc_objects")
+	flag.IntVar(&config.Limits.MaxFindings, "max-findings", reporter.DefaultMaxFindings, "报告最多渲染的发现数 (0 表示不限制)")
+	flag.IntVar(&config.Limits.MaxHotPaths, "max-finding-paths", reporter.DefaultMaxHotPaths, "每个发现最多渲染的热点路径数 (0 表示不限制)")
+	flag.IntVar(&config.Limits.MaxFrames, "max-chain-frames", reporter.DefaultMaxFrames, "每条调用链最多渲染的栈帧数 (0 表示不限制)")
+	flag.IntVar(&config.Limits.MaxFunctions, "max-functions", reporter.DefaultMaxFunctions, "每个文件 Top 函数列表最多渲染的函数数 (0 表示不限制)")
+	flag.IntVar(&config.Limits.MaxFunctions, "top-functions", reporter.DefaultMaxFunctions, "-max-functions 的别名")
+	var sortSpecs stringListFlag
+	flag.Var(&sortSpecs, "sort", "分组和文件的展示顺序，可重复: groups=type|severity, files=asc|desc (如 -sort groups=severity -sort files=desc)")
 
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "PerfInspector v0.1 - 智能时间序列 pprof 分析工具\n\n")
-		fmt.Fprintf(os.Stderr, "Usage: %s [options] <profile_dir_or_file>\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "PerfInspector %s - 智能时间序列 pprof 分析工具\n\n", reporter.BuildVersion())
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] <profile_dir_or_file>...\n\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		flag.PrintDefaults()
 		fmt.Fprintf(os.Stderr, "\nExamples:\n")
 		fmt.Fprintf(os.Stderr, "  %s ./profiles/\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s -format html -output report.html ./profiles/\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -format dot ./profiles/ | dot -Tsvg -o hotpaths.svg\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s -rules custom_rules.yaml ./profiles/\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s -module github.com/myorg/myapp -stack-depth 15 ./profiles/\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -paths-from manifest.txt\n", os.Args[0])
 	}
 
 	flag.Parse()
 
 	// 验证 format 参数
-	if config.Format != "text" && config.Format != "html" {
-		return nil, fmt.Errorf("invalid format '%s', must be 'text' or 'html'", config.Format)
+	if _, ok := reporter.LookupRenderer(config.Format); !ok {
+		return nil, fmt.Errorf("invalid format '%s', must be one of: %s", config.Format, strings.Join(reporter.RendererFormats(), ", "))
+	}
+
+	// -tui 从标准输入读取按键，不能与 HTML/DOT 输出或从标准输入读取路径清单同时使用
+	if config.TUI && config.Format != "text" {
+		return nil, fmt.Errorf("-tui cannot be combined with -format %s", config.Format)
+	}
+	if config.OnlyNew && config.History == "" {
+		return nil, fmt.Errorf("-only-new requires -history")
+	}
+	if config.Quiet && config.Verbose {
+		return nil, fmt.Errorf("-quiet cannot be combined with -verbose")
+	}
+	// -serve 总是提供 HTML 报告，显式指定的 -format 不会生效
+	if config.Serve != "" && (config.TUI || config.OutputPath != "" || flagsSet("format") || config.Index || config.History != "" || config.FailOn != "") {
+		return nil, fmt.Errorf("-serve cannot be combined with -tui, -output, -format, -index, -history or -fail-on")
+	}
+	if config.MaxUpload <= 0 {
+		return nil, fmt.Errorf("invalid -max-upload: must be positive, got %d", config.MaxUpload)
+	}
+	if config.FailOn != "" {
+		severity, err := locator.ParseSeverity(config.FailOn)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -fail-on: %w", err)
+		}
+		config.FailOn = severity
+	}
+	if config.ExplainFunc != "" && (config.TUI || config.Format != "text") {
+		return nil, fmt.Errorf("-explain-func only supports text output")
+	}
+	if config.Index && (config.TUI || (config.Format != "text" && config.Format != "html")) {
+		return nil, fmt.Errorf("-index only supports text and html output")
+	}
+	if config.Index && config.ExplainFunc != "" {
+		return nil, fmt.Errorf("-index cannot be combined with -explain-func")
+	}
+	if config.TUI && config.PathsFrom == "-" {
+		return nil, fmt.Errorf("-tui cannot be combined with -paths-from -")
+	}
+
+	// 解析额外的文件扩展名
+	exts, err := parseExtensions(extensions)
+	if err != nil {
+		return nil, err
 	}
+	config.Extensions = exts
 
 	// 解析第三方包前缀
 	if thirdPartyPrefixes != "" {
@@ -139,6 +655,97 @@ func parseArgs() (*Config, error) {
 		}
 	}
 
+	// 解析分类覆盖
+	if classifyOverrides != "" {
+		config.ClassifyOverrides, err = locator.ParseClassifyOverrides(classifyOverrides)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 解析并校验命令模板
+	config.CommandTemplate.FlagStyle, err = locator.ParseFlagStyle(flagStyle)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.CommandTemplate.Validate(); err != nil {
+		return nil, err
+	}
+
+	// 解析根因策略
+	policy, err := locator.ParseRootCausePolicy(rootCausePolicy)
+	if err != nil {
+		return nil, err
+	}
+	config.RootCausePolicy = policy
+
+	// 解析热点迁移切分时间
+	if windowPivot != "" {
+		config.WindowPivot, err = time.Parse(time.RFC3339, windowPivot)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -window-pivot '%s', must be RFC3339 (e.g. 2023-11-15T14:30:00Z)", windowPivot)
+		}
+	}
+
+	// 解析快照时间窗口，相对时间以解析参数的时刻为准
+	now := time.Now()
+	if config.Window.Since, err = parseTimeBound("-since", since, now); err != nil {
+		return nil, err
+	}
+	if config.Window.Until, err = parseTimeBound("-until", until, now); err != nil {
+		return nil, err
+	}
+	if !config.Window.Since.IsZero() && !config.Window.Until.IsZero() && config.Window.Since.After(config.Window.Until) {
+		return nil, fmt.Errorf("-since %s is later than -until %s", config.Window.Since.Format(time.RFC3339), config.Window.Until.Format(time.RFC3339))
+	}
+
+	// 解析趋势展示阈值，-trend-confidence 与 -min-r2 写入同一个变量，同时指定时无法确定以哪个为准
+	if flagsSet("min-r2", "trend-confidence") {
+		return nil, fmt.Errorf("-trend-confidence is an alias of -min-r2, specify only one of them")
+	}
+	thresholds, err := parseMinR2(minR2)
+	if err != nil {
+		return nil, err
+	}
+	config.TrendThresholds = thresholds
+
+	if err := reporter.ValidateSourceLinkTemplate(config.SourceLinkTemplate); err != nil {
+		return nil, err
+	}
+	if err := reporter.ValidateHTMLTemplate(config.HTMLTemplatePath); err != nil {
+		return nil, err
+	}
+
+	// 解析 heap 趋势 sample type
+	config.HeapSampleType, err = analyzer.ParseHeapSampleType(heapSampleType)
+	if err != nil {
+		return nil, err
+	}
+
+	// 解析展示顺序
+	config.Sort, err = reporter.MergeSortOptions(sortSpecs)
+	if err != nil {
+		return nil, err
+	}
+
+	// 可复现构建：SOURCE_DATE_EPOCH 固定报告生成时间
+	config.GeneratedAt, err = parseSourceDateEpoch(os.Getenv("SOURCE_DATE_EPOCH"))
+	if err != nil {
+		return nil, err
+	}
+
+	// 验证报告规模上限，-top-functions 与 -max-functions 写入同一个变量
+	if flagsSet("max-functions", "top-functions") {
+		return nil, fmt.Errorf("-top-functions is an alias of -max-functions, specify only one of them")
+	}
+	if err := validateLimits(config.Limits); err != nil {
+		return nil, err
+	}
+	// 每个 profile 最多提取 analyzer.MaxTopFunctions 个 Top 函数，更大的值没有意义
+	if config.Limits.MaxFunctions > analyzer.MaxTopFunctions {
+		config.Limits.MaxFunctions = analyzer.MaxTopFunctions
+	}
+
 	// 验证配置限制
 	if config.StackDepth < 1 {
 		config.StackDepth = 1
@@ -152,19 +759,80 @@ func parseArgs() (*Config, error) {
 	if config.HotPaths > 50 {
 		config.HotPaths = 50
 	}
+	if config.Concurrency < 1 {
+		config.Concurrency = 1
+	}
+	if config.Timeout < 0 {
+		return nil, fmt.Errorf("invalid -timeout %s, must not be negative", config.Timeout)
+	}
+	// 两个点总能完美拟合直线，R² 没有意义
+	if config.MinTrendPoints < analyzer.DefaultMinTrendPoints {
+		return nil, fmt.Errorf("invalid -min-trend-points %d, must be at least %d", config.MinTrendPoints, analyzer.DefaultMinTrendPoints)
+	}
+	if config.BenchN < 0 {
+		return nil, fmt.Errorf("invalid -bench-n %d, must not be negative", config.BenchN)
+	}
+	if !config.Bench && (config.BenchN > 0 || config.BenchOutput != "") {
+		return nil, fmt.Errorf("-bench-n and -bench-output require -bench")
+	}
 
 	// 获取输入路径
-	args := flag.Args()
-	if len(args) < 1 {
+	config.InputPaths = flag.Args()
+	if len(config.InputPaths) == 0 && config.PathsFrom == "" {
 		flag.Usage()
 		return nil, fmt.Errorf("missing input path")
 	}
-	config.InputPath = args[0]
 
 	return config, nil
 }
 
 func getProfilePaths(path string) ([]string, error) {
+	return getProfilePathsWithFilter(path, defaultProfileFilter())
+}
+
+// getProfilePathsWithFilter 使用指定的过滤条件展开目录、单个文件或 glob 模式
+// 含有 glob 元字符 (*?[) 的路径按 filepath.Glob 展开，匹配到的目录同样递归查找；
+// 没有匹配到任何 profile 文件时报错，避免拼错的模式悄悄得到空结果
+func getProfilePathsWithFilter(path string, filter profileFilter) ([]string, error) {
+	if !strings.ContainsAny(path, "*?[") {
+		return walkProfilePath(path, filter)
+	}
+
+	matches, err := filepath.Glob(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob pattern: %w", err)
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("glob pattern matched no files")
+	}
+
+	var paths []string
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil {
+			return nil, err
+		}
+		// 模式匹配到的非 profile 文件 (如 heap_*.log) 跳过，而不是像单个文件参数那样报错
+		if !info.IsDir() {
+			if filter.matches(match) {
+				paths = append(paths, match)
+			}
+			continue
+		}
+		expanded, err := walkProfilePath(match, filter)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, expanded...)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("glob pattern matched %d paths but no profile files", len(matches))
+	}
+	return paths, nil
+}
+
+// walkProfilePath 使用指定的过滤条件展开目录或单个文件
+func walkProfilePath(path string, filter profileFilter) ([]string, error) {
 	var paths []string
 	fileInfo, err := os.Stat(path)
 	if err != nil {
@@ -176,12 +844,12 @@ func getProfilePaths(path string) ([]string, error) {
 			if err != nil {
 				return err
 			}
-			if !info.IsDir() && isProfileFile(p) {
+			if !info.IsDir() && filter.matches(p) {
 				paths = append(paths, p)
 			}
 			return nil
 		})
-	} else if isProfileFile(path) {
+	} else if filter.matches(path) {
 		paths = []string{path}
 	} else {
 		return nil, fmt.Errorf("path is not a directory or valid profile file")
@@ -190,105 +858,315 @@ func getProfilePaths(path string) ([]string, error) {
 	return paths, err
 }
 
-func isProfileFile(path string) bool {
-	ext := filepath.Ext(path)
-	return ext == ".pprof" || ext == ".profile"
+// readPathsFrom 从清单读取 profile 路径
+// 每行一个路径，忽略空行和 # 开头的注释行；source 为 "-" 时从 stdin 读取
+func readPathsFrom(source string, stdin io.Reader) ([]string, error) {
+	reader := stdin
+	if source != "-" {
+		file, err := os.Open(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open paths file '%s': %w", source, err)
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	var paths []string
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		paths = append(paths, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read paths file '%s': %w", source, err)
+	}
+	return paths, nil
 }
 
-// createLocatorConfig 创建 Problem Locator 配置
-func createLocatorConfig(config *Config) locator.LocatorConfig {
-	locatorConfig := locator.DefaultConfig()
+// collectProfilePaths 展开所有输入路径并去重，保持首次出现的顺序
+// 任一输入路径无效时返回错误
+func collectProfilePaths(inputs []string) ([]string, error) {
+	return collectProfilePathsWithFilter(inputs, defaultProfileFilter())
+}
 
-	// 设置模块名
-	if config.ModuleName != "" {
-		locatorConfig.ModuleName = config.ModuleName
-	} else {
-		// 尝试从 go.mod 自动检测
-		if moduleName, err := locator.DetectModuleName("."); err == nil {
-			locatorConfig.ModuleName = moduleName
+// collectProfilePathsWithFilter 使用指定的过滤条件展开并去重所有输入路径
+func collectProfilePathsWithFilter(inputs []string, filter profileFilter) ([]string, error) {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, input := range inputs {
+		expanded, err := getProfilePathsWithFilter(input, filter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid input path '%s': %w", input, err)
+		}
+		for _, p := range expanded {
+			key := filepath.Clean(p)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			paths = append(paths, p)
 		}
 	}
+	return paths, nil
+}
+
+// defaultProfileExtensions 默认接受的 profile 文件扩展名
+var defaultProfileExtensions = []string{".pprof", ".profile"}
 
-	// 设置第三方包前缀
-	if len(config.ThirdPartyPrefixes) > 0 {
-		locatorConfig.ThirdPartyPrefixes = config.ThirdPartyPrefixes
+// profileFilter 判断文件是否作为 profile 参与分析
+type profileFilter struct {
+	extensions map[string]bool
+	sniff      bool // 没有扩展名的文件通过文件头识别
+}
+
+// defaultProfileFilter 只接受默认扩展名的过滤条件
+func defaultProfileFilter() profileFilter {
+	return newProfileFilter(nil, false)
+}
+
+// newProfileFilter 在默认扩展名基础上增加额外扩展名，扩展名不区分大小写
+func newProfileFilter(extraExtensions []string, sniff bool) profileFilter {
+	filter := profileFilter{extensions: make(map[string]bool), sniff: sniff}
+	for _, ext := range defaultProfileExtensions {
+		filter.extensions[ext] = true
+	}
+	for _, ext := range extraExtensions {
+		filter.extensions[strings.ToLower(ext)] = true
 	}
+	return filter
+}
 
-	// 设置调用栈深度和热点路径数
-	locatorConfig.MaxCallStackDepth = config.StackDepth
-	locatorConfig.MaxHotPaths = config.HotPaths
+// compressedProfileExtension gzip 压缩的 profile 的后缀，如 heap.pprof.gz；google/pprof 解析时自动解压
+const compressedProfileExtension = ".gz"
 
-	return locatorConfig
+// matches 检查文件扩展名 (不区分大小写)，开启 sniff 时没有扩展名的文件读取文件头判断
+// 以 .gz 结尾时按去掉 .gz 后的扩展名判断，如 heap.pprof.gz 与 heap.pprof 相同
+func (f profileFilter) matches(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == compressedProfileExtension && !f.extensions[ext] {
+		path = path[:len(path)-len(ext)]
+		ext = strings.ToLower(filepath.Ext(path))
+	}
+	if ext == "" {
+		return f.sniff && parser.SniffProfileFile(path)
+	}
+	return f.extensions[ext]
+}
+
+func isProfileFile(path string) bool {
+	return defaultProfileFilter().matches(path)
+}
+
+// parseExtensions 解析 -ext 参数，缺少前导点时自动补全，统一转为小写
+// .gz 压缩的扩展名 (如 .pb.gz) 按去掉 .gz 后的扩展名记录，压缩文件由 profileFilter.matches 统一处理
+func parseExtensions(value string) ([]string, error) {
+	var extensions []string
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if !strings.HasPrefix(entry, ".") {
+			entry = "." + entry
+		}
+		if inner := strings.TrimSuffix(entry, compressedProfileExtension); inner != entry && inner != "" {
+			entry = inner
+		}
+		if entry == "." || strings.ContainsAny(entry[1:], "./\\") {
+			return nil, fmt.Errorf("invalid extension '%s', must look like .prof", entry)
+		}
+		extensions = append(extensions, entry)
+	}
+	return extensions, nil
 }
 
-// generateProblemContexts 为每个 Finding 生成 ProblemContext
-func generateProblemContexts(findings []rules.Finding, groups []analyzer.ProfileGroup, config locator.LocatorConfig) map[string]*locator.ProblemContext {
-	if len(findings) == 0 {
-		return nil
+// flagsSet 命令行是否显式指定了 names 中的全部参数
+func flagsSet(names ...string) bool {
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	for _, name := range names {
+		if !set[name] {
+			return false
+		}
 	}
+	return true
+}
 
-	// 创建 locator 组件
-	classifier := locator.NewClassifier(config)
-	extractor := locator.NewExtractor(classifier)
-	pathAnalyzer := locator.NewPathAnalyzer(extractor, config)
-	contextGenerator := locator.NewContextGenerator(pathAnalyzer)
+// parseMinR2 解析 -min-r2 参数
+// 格式为逗号分隔的条目：纯数字设置默认阈值，metric=value 按指标覆盖
+func parseMinR2(value string) (analyzer.TrendThresholds, error) {
+	thresholds := analyzer.DefaultTrendThresholds()
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
 
-	// 收集所有 profiles，按类型组织（用于向后兼容，保留最新的单个 profile）
-	profiles := make(map[string]*profile.Profile)
-	// 收集所有 profiles，按类型组织（用于综合分析）
-	allProfiles := make(map[string][]*profile.Profile)
-	// 收集所有 profile 文件路径，按类型组织
-	profilePaths := make(map[string][]string)
+		metric := ""
+		if idx := strings.Index(entry, "="); idx >= 0 {
+			metric = strings.TrimSpace(entry[:idx])
+			entry = strings.TrimSpace(entry[idx+1:])
+			if metric != analyzer.MetricHeapInuse && metric != analyzer.MetricHeapInuseObjects && metric != analyzer.MetricGoroutineCount {
+				return thresholds, fmt.Errorf("invalid min-r2 metric '%s', must be '%s', '%s' or '%s'",
+					metric, analyzer.MetricHeapInuse, analyzer.MetricHeapInuseObjects, analyzer.MetricGoroutineCount)
+			}
+		}
 
-	for _, group := range groups {
-		if len(group.Files) > 0 {
-			// 使用最新的 profile（最后一个）- 向后兼容
-			profiles[group.Type] = group.Files[len(group.Files)-1].Profile
-
-			// 收集该类型的所有 profiles（用于综合分析）
-			for _, file := range group.Files {
-				if file.Profile != nil {
-					allProfiles[group.Type] = append(allProfiles[group.Type], file.Profile)
-				}
-				profilePaths[group.Type] = append(profilePaths[group.Type], file.Path)
+		r2, err := strconv.ParseFloat(entry, 64)
+		if err != nil || r2 < 0 || r2 > 1 {
+			return thresholds, fmt.Errorf("invalid min-r2 value '%s', must be a number in [0, 1]", entry)
+		}
+
+		if metric == "" {
+			thresholds.Default = r2
+		} else {
+			if thresholds.Metrics == nil {
+				thresholds.Metrics = make(map[string]float64)
 			}
+			thresholds.Metrics[metric] = r2
 		}
 	}
+	return thresholds, nil
+}
 
-	// 为每个 Finding 生成 ProblemContext
-	contexts := make(map[string]*locator.ProblemContext)
-	for _, finding := range findings {
-		// 确定该 finding 对应的 profile 类型
-		profileType := determineProfileTypeFromFinding(finding)
-		// 获取对应类型的 profile 路径
-		paths := profilePaths[profileType]
-		// 使用新的综合分析方法
-		ctx := contextGenerator.GenerateContextWithAllProfiles(finding, profiles, allProfiles, paths)
-		if ctx != nil {
-			contexts[finding.RuleID] = ctx
+// validateLimits 验证报告规模上限，不允许负数
+func validateLimits(limits reporter.Limits) error {
+	values := []struct {
+		flag  string
+		value int
+	}{
+		{"max-findings", limits.MaxFindings},
+		{"max-finding-paths", limits.MaxHotPaths},
+		{"max-chain-frames", limits.MaxFrames},
+		{"max-functions", limits.MaxFunctions},
+	}
+	for _, v := range values {
+		if v.value < 0 {
+			return fmt.Errorf("invalid %s '%d', must be >= 0", v.flag, v.value)
 		}
 	}
+	return nil
+}
 
-	return contexts
+// createReportOptions 创建报告渲染选项
+func createReportOptions(config *Config) reporter.Options {
+	opts := reporter.DefaultOptions()
+	opts.TrendThresholds = config.TrendThresholds
+	opts.ReadableNames = config.ReadableNames
+	opts.Limits = config.Limits
+	opts.GeneratedAt = config.GeneratedAt
+	opts.Sort = config.Sort
+	opts.NoEmoji = config.NoEmoji
+	opts.SourceLinks = reporter.SourceLinks{Template: config.SourceLinkTemplate, Root: config.SourceRoot, Module: config.ModuleName}
+	opts.HTMLTemplatePath = config.HTMLTemplatePath
+	if config.SourceLinkTemplate != "" && opts.SourceLinks.Module == "" {
+		opts.SourceLinks.Module, _ = locator.DetectModuleName(".")
+	}
+	return opts
 }
 
-// determineProfileTypeFromFinding 从 Finding 确定 profile 类型
-func determineProfileTypeFromFinding(finding rules.Finding) string {
-	title := strings.ToLower(finding.Title)
-	ruleID := strings.ToLower(finding.RuleID)
+// stringListFlag 可重复指定的字符串参数
+type stringListFlag []string
 
-	if strings.Contains(title, "cpu") || strings.Contains(ruleID, "cpu") {
-		return "cpu"
+// String 实现 flag.Value
+func (f *stringListFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+// Set 实现 flag.Value，每次出现追加一个值
+func (f *stringListFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// parseSourceDateEpoch 解析 SOURCE_DATE_EPOCH (Unix 秒)，为空时返回零值
+func parseSourceDateEpoch(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
 	}
-	if strings.Contains(title, "内存") || strings.Contains(title, "memory") ||
-		strings.Contains(title, "heap") || strings.Contains(ruleID, "heap") ||
-		strings.Contains(ruleID, "memory") {
-		return "heap"
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds < 0 {
+		return time.Time{}, fmt.Errorf("invalid SOURCE_DATE_EPOCH '%s', must be a non-negative Unix timestamp", value)
 	}
-	if strings.Contains(title, "goroutine") || strings.Contains(ruleID, "goroutine") ||
-		strings.Contains(title, "协程") {
-		return "goroutine"
+	return time.Unix(seconds, 0).UTC(), nil
+}
+
+// createOptions 将命令行配置转换为分析选项
+func createOptions(config *Config) perfinspector.Options {
+	return perfinspector.Options{
+		Concurrency:        config.Concurrency,
+		OrderByFilename:    config.OrderByFilename,
+		ValueType:          config.ValueType,
+		Dedup:              config.Dedup,
+		Window:             config.Window,
+		Bench:              config.Bench,
+		BenchN:             config.BenchN,
+		ModuleName:         config.ModuleName,
+		ThirdPartyPrefixes: config.ThirdPartyPrefixes,
+		ClassifyOverrides:  config.ClassifyOverrides,
+		StackDepth:         config.StackDepth,
+		HotPaths:           config.HotPaths,
+		ReadableNames:      config.ReadableNames,
+		HideRuntimeOnly:    config.HideRuntimeOnly,
+		CollapseRecursion:  config.CollapseRecursion,
+		RootCausePolicy:    config.RootCausePolicy,
+		WindowPivot:        config.WindowPivot,
+		CommandsTopOnly:    config.CommandsTopOnly,
+		CommandTemplate:    config.CommandTemplate,
+		HeapSampleType:     config.HeapSampleType,
+		MinTrendPoints:     config.MinTrendPoints,
+		RulesPath:          config.RulesPath,
+	}
+}
+
+// createLocatorConfig 创建 Problem Locator 配置
+func createLocatorConfig(config *Config) locator.LocatorConfig {
+	return createOptions(config).LocatorConfig()
+}
+
+// buildFunctionReport 汇总 -explain-func 指定函数在各 profile 中的消耗、经过它的热点路径和 pprof 命令
+func buildFunctionReport(ctx context.Context, groups []analyzer.ProfileGroup, config *Config, locatorConfig locator.LocatorConfig) (reporter.FunctionReport, error) {
+	function := config.ExplainFunc
+	explanation := analyzer.ExplainFunction(groups, function, config.MinTrendPoints)
+	classifier := locator.NewClassifier(locatorConfig)
+	report := reporter.FunctionReport{
+		Explanation: explanation,
+		Category:    classifier.Classify(locator.ExtractPackageName(function)),
+		HotPaths:    make(map[string][]locator.HotPath),
+	}
+	if !explanation.Found() {
+		return report, nil
 	}
 
-	return "cpu"
+	pathAnalyzer := locator.NewPathAnalyzer(locator.NewExtractorWithConfig(classifier, locatorConfig), locatorConfig)
+	commands := locator.NewCommandGeneratorWithOptions(locator.CommandOptions{Template: locatorConfig.CommandTemplate})
+	for _, group := range groups {
+		var profiles []*profile.Profile
+		for _, file := range group.Files {
+			if file.Profile != nil {
+				profiles = append(profiles, file.Profile)
+			}
+		}
+		hotPaths, err := pathAnalyzer.HotPathsThrough(ctx, profiles, group.Type, function)
+		if err != nil {
+			return report, err
+		}
+		if len(hotPaths) == 0 {
+			continue
+		}
+		report.HotPaths[group.Type] = hotPaths
+
+		// goroutine/block/mutex 查看经过该函数的调用上下文，cpu/heap 查看源码行的消耗
+		latest := group.Files[len(group.Files)-1].Path
+		if group.Type == "cpu" || group.Type == "heap" {
+			report.Commands = append(report.Commands, commands.GenerateListCommand(latest, function))
+		} else {
+			report.Commands = append(report.Commands, commands.GenerateFocusCommand(latest, function))
+		}
+	}
+	return report, nil
 }
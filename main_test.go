@@ -1,13 +1,26 @@
 package main
 
 import (
+	"bytes"
 	"flag"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"strings"
 	"testing"
 	"testing/quick"
+	"time"
+	"unicode"
 
 	"github.com/google/pprof/profile"
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
 	"github.com/songzhibin97/perfinspector/pkg/locator"
+	"github.com/songzhibin97/perfinspector/pkg/reporter"
+	"github.com/songzhibin97/perfinspector/pkg/rules"
+	"github.com/songzhibin97/perfinspector/pkg/server"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -25,6 +38,10 @@ func TestIsProfileFile(t *testing.T) {
 		{"data.json", false},
 		{".pprof", true},
 		{"path/to/cpu.pprof", true},
+		{"heap.pprof.gz", true},
+		{"cpu.profile.gz", true},
+		{"logs.tar.gz", false},
+		{"archive.gz", false},
 	}
 
 	for _, tt := range tests {
@@ -73,6 +90,77 @@ func TestGetProfilePaths_Directory(t *testing.T) {
 	assert.Len(t, paths, 2) // 只有 .pprof 文件
 }
 
+// TestGetProfilePaths_Gzip 测试 gzip 压缩的 profile 与未压缩的同一 profile 分组结果相同
+func TestGetProfilePaths_Gzip(t *testing.T) {
+	dir := t.TempDir()
+	p := &profile.Profile{
+		TimeNanos: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC).UnixNano(),
+		SampleType: []*profile.ValueType{
+			{Type: "alloc_objects", Unit: "count"},
+			{Type: "alloc_space", Unit: "bytes"},
+			{Type: "inuse_objects", Unit: "count"},
+			{Type: "inuse_space", Unit: "bytes"},
+		},
+		Sample: []*profile.Sample{{Value: []int64{10, 1024, 5, 512}}},
+	}
+	var raw, compressed bytes.Buffer
+	require.NoError(t, p.WriteUncompressed(&raw))
+	require.NoError(t, p.Write(&compressed))
+	require.Equal(t, []byte{0x1f, 0x8b}, compressed.Bytes()[:2], "fixture is gzip compressed")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "heap.pprof"), raw.Bytes(), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "heap.pprof.gz"), compressed.Bytes(), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt.gz"), compressed.Bytes(), 0644))
+
+	paths, err := getProfilePaths(dir)
+	require.NoError(t, err)
+	require.Len(t, paths, 2)
+
+	groups, err := analyzer.GroupProfiles(paths)
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Equal(t, "heap", groups[0].Type)
+	require.Len(t, groups[0].Files, 2)
+	assert.Equal(t, groups[0].Files[0].Metrics.InuseSpace, groups[0].Files[1].Metrics.InuseSpace)
+	assert.Equal(t, groups[0].Files[0].Metrics.AllocSpace, groups[0].Files[1].Metrics.AllocSpace)
+	assert.Equal(t, groups[0].Files[0].Time, groups[0].Files[1].Time)
+}
+
+// TestGetProfilePaths_Glob 测试 glob 模式跨多个子目录匹配 profile 文件
+func TestGetProfilePaths_Glob(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"pod-a/heap_1.pprof", "pod-a/heap_2.pprof", "pod-a/cpu_1.pprof",
+		"pod-b/heap_1.pprof", "pod-b/heap_1.log",
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, nil, 0644))
+	}
+
+	paths, err := getProfilePaths(filepath.Join(dir, "*", "heap_*"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "pod-a", "heap_1.pprof"),
+		filepath.Join(dir, "pod-a", "heap_2.pprof"),
+		filepath.Join(dir, "pod-b", "heap_1.pprof"),
+	}, paths)
+
+	// 匹配到的目录递归查找
+	paths, err = getProfilePaths(filepath.Join(dir, "pod-?"))
+	require.NoError(t, err)
+	assert.Len(t, paths, 4)
+
+	// 没有匹配时明确报错而不是得到空结果
+	_, err = getProfilePaths(filepath.Join(dir, "*", "goroutine_*.pprof"))
+	assert.ErrorContains(t, err, "glob pattern matched no files")
+
+	_, err = getProfilePaths(filepath.Join(dir, "*", "*.log"))
+	assert.ErrorContains(t, err, "matched 1 paths but no profile files")
+
+	_, err = getProfilePaths(filepath.Join(dir, "[", "*.pprof"))
+	assert.ErrorContains(t, err, "invalid glob pattern")
+}
+
 func TestGetProfilePaths_NonExistent(t *testing.T) {
 	_, err := getProfilePaths("/nonexistent/path")
 	assert.Error(t, err)
@@ -89,6 +177,56 @@ func TestGetProfilePaths_InvalidFile(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestReadPathsFrom(t *testing.T) {
+	manifest := "# profiles from nightly job\n/data/cpu1.pprof\n\n  /data/heap1.pprof  \n# /data/skipped.pprof\n"
+
+	t.Run("file", func(t *testing.T) {
+		manifestPath := filepath.Join(t.TempDir(), "manifest.txt")
+		require.NoError(t, os.WriteFile(manifestPath, []byte(manifest), 0644))
+
+		paths, err := readPathsFrom(manifestPath, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"/data/cpu1.pprof", "/data/heap1.pprof"}, paths)
+	})
+
+	t.Run("stdin", func(t *testing.T) {
+		paths, err := readPathsFrom("-", strings.NewReader(manifest))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"/data/cpu1.pprof", "/data/heap1.pprof"}, paths)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := readPathsFrom("/nonexistent/manifest.txt", nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestCollectProfilePaths(t *testing.T) {
+	tempDir := t.TempDir()
+	cpuPath := filepath.Join(tempDir, "cpu.pprof")
+	heapPath := filepath.Join(tempDir, "heap.pprof")
+	require.NoError(t, os.WriteFile(cpuPath, nil, 0644))
+	require.NoError(t, os.WriteFile(heapPath, nil, 0644))
+
+	t.Run("combines and dedupes", func(t *testing.T) {
+		paths, err := collectProfilePaths([]string{cpuPath, tempDir, tempDir + "/./cpu.pprof"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{cpuPath, heapPath}, paths)
+	})
+
+	t.Run("invalid path", func(t *testing.T) {
+		_, err := collectProfilePaths([]string{cpuPath, "/nonexistent/heap.pprof"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "/nonexistent/heap.pprof")
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		paths, err := collectProfilePaths(nil)
+		require.NoError(t, err)
+		assert.Empty(t, paths)
+	})
+}
+
 // Feature: problem-locator, Property 9: Configuration Limits Respected
 // Validates: Requirements 8.3, 8.4
 
@@ -242,6 +380,22 @@ func TestCreateLocatorConfig(t *testing.T) {
 		assert.Equal(t, 5, locatorConfig.MaxHotPaths)
 	})
 
+	t.Run("hide runtime only", func(t *testing.T) {
+		locatorConfig := createLocatorConfig(&Config{StackDepth: 10, HotPaths: 5})
+		assert.False(t, locatorConfig.HideRuntimeOnly)
+
+		locatorConfig = createLocatorConfig(&Config{StackDepth: 10, HotPaths: 5, HideRuntimeOnly: true})
+		assert.True(t, locatorConfig.HideRuntimeOnly)
+	})
+
+	t.Run("collapse recursion", func(t *testing.T) {
+		locatorConfig := createLocatorConfig(&Config{StackDepth: 10, HotPaths: 5})
+		assert.False(t, locatorConfig.CollapseRecursion)
+
+		locatorConfig = createLocatorConfig(&Config{StackDepth: 10, HotPaths: 5, CollapseRecursion: true})
+		assert.True(t, locatorConfig.CollapseRecursion)
+	})
+
 	t.Run("custom module name", func(t *testing.T) {
 		config := &Config{
 			ModuleName: "github.com/custom/module",
@@ -276,24 +430,24 @@ func TestCreateLocatorConfig(t *testing.T) {
 	})
 }
 
-// TestParseArgs_LocatorOptions tests parsing of locator-related command line options
-func TestParseArgs_LocatorOptions(t *testing.T) {
-	// Save original args and restore after test
+// parseTestArgs 以 args 加一个空的 profile 文件作为命令行调用 parseArgs
+// 每次调用都重置 flag.CommandLine，测试结束后恢复 os.Args
+func parseTestArgs(t *testing.T, args ...string) (*Config, error) {
+	t.Helper()
 	originalArgs := os.Args
-	defer func() { os.Args = originalArgs }()
+	t.Cleanup(func() { os.Args = originalArgs })
 
-	t.Run("default locator options", func(t *testing.T) {
-		// Reset flags for this test
-		flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
-
-		// Create a temp file to use as input
-		tempFile, err := os.CreateTemp("", "test*.pprof")
-		require.NoError(t, err)
-		defer os.Remove(tempFile.Name())
-		tempFile.Close()
+	tempFile := filepath.Join(t.TempDir(), "test.pprof")
+	require.NoError(t, os.WriteFile(tempFile, nil, 0644))
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	os.Args = append(append([]string{"cmd"}, args...), tempFile)
+	return parseArgs()
+}
 
-		os.Args = []string{"cmd", tempFile.Name()}
-		config, err := parseArgs()
+// TestParseArgs_LocatorOptions tests parsing of locator-related command line options
+func TestParseArgs_LocatorOptions(t *testing.T) {
+	t.Run("default locator options", func(t *testing.T) {
+		config, err := parseTestArgs(t)
 		require.NoError(t, err)
 
 		assert.Equal(t, "", config.ModuleName)
@@ -303,24 +457,12 @@ func TestParseArgs_LocatorOptions(t *testing.T) {
 	})
 
 	t.Run("custom locator options", func(t *testing.T) {
-		// Reset flags for this test
-		flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
-
-		// Create a temp file to use as input
-		tempFile, err := os.CreateTemp("", "test*.pprof")
-		require.NoError(t, err)
-		defer os.Remove(tempFile.Name())
-		tempFile.Close()
-
-		os.Args = []string{
-			"cmd",
+		config, err := parseTestArgs(t,
 			"-module", "github.com/myorg/myapp",
 			"-third-party-prefixes", "github.com/vendor1,github.com/vendor2",
 			"-stack-depth", "15",
 			"-hot-paths", "10",
-			tempFile.Name(),
-		}
-		config, err := parseArgs()
+		)
 		require.NoError(t, err)
 
 		assert.Equal(t, "github.com/myorg/myapp", config.ModuleName)
@@ -330,54 +472,803 @@ func TestParseArgs_LocatorOptions(t *testing.T) {
 	})
 
 	t.Run("stack depth limits", func(t *testing.T) {
-		// Reset flags for this test
-		flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
-
-		// Create a temp file to use as input
-		tempFile, err := os.CreateTemp("", "test*.pprof")
-		require.NoError(t, err)
-		defer os.Remove(tempFile.Name())
-		tempFile.Close()
-
 		// Test minimum limit
-		os.Args = []string{"cmd", "-stack-depth", "0", tempFile.Name()}
-		config, err := parseArgs()
+		config, err := parseTestArgs(t, "-stack-depth", "0")
 		require.NoError(t, err)
 		assert.Equal(t, 1, config.StackDepth) // Should be clamped to 1
 
-		// Reset flags for next test
-		flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
-
 		// Test maximum limit
-		os.Args = []string{"cmd", "-stack-depth", "200", tempFile.Name()}
-		config, err = parseArgs()
+		config, err = parseTestArgs(t, "-stack-depth", "200")
 		require.NoError(t, err)
 		assert.Equal(t, 100, config.StackDepth) // Should be clamped to 100
 	})
 
 	t.Run("hot paths limits", func(t *testing.T) {
-		// Reset flags for this test
-		flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
-
-		// Create a temp file to use as input
-		tempFile, err := os.CreateTemp("", "test*.pprof")
-		require.NoError(t, err)
-		defer os.Remove(tempFile.Name())
-		tempFile.Close()
-
 		// Test minimum limit
-		os.Args = []string{"cmd", "-hot-paths", "0", tempFile.Name()}
-		config, err := parseArgs()
+		config, err := parseTestArgs(t, "-hot-paths", "0")
 		require.NoError(t, err)
 		assert.Equal(t, 1, config.HotPaths) // Should be clamped to 1
 
-		// Reset flags for next test
-		flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
-
 		// Test maximum limit
-		os.Args = []string{"cmd", "-hot-paths", "100", tempFile.Name()}
-		config, err = parseArgs()
+		config, err = parseTestArgs(t, "-hot-paths", "100")
 		require.NoError(t, err)
 		assert.Equal(t, 50, config.HotPaths) // Should be clamped to 50
 	})
+
+	t.Run("window pivot", func(t *testing.T) {
+		config, err := parseTestArgs(t)
+		require.NoError(t, err)
+		assert.True(t, config.WindowPivot.IsZero())
+
+		config, err = parseTestArgs(t, "-window-pivot", "2023-11-15T14:30:00Z")
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2023, 11, 15, 14, 30, 0, 0, time.UTC), config.WindowPivot)
+		assert.Equal(t, config.WindowPivot, createLocatorConfig(config).WindowPivot)
+
+		_, err = parseTestArgs(t, "-window-pivot", "14:30")
+		assert.Error(t, err)
+	})
+
+	t.Run("min trend points", func(t *testing.T) {
+		config, err := parseTestArgs(t)
+		require.NoError(t, err)
+		assert.Equal(t, 3, config.MinTrendPoints)
+
+		config, err = parseTestArgs(t, "-min-trend-points", "6")
+		require.NoError(t, err)
+		assert.Equal(t, 6, config.MinTrendPoints)
+
+		// 两个点总能完美拟合直线，不允许低于 3
+		_, err = parseTestArgs(t, "-min-trend-points", "2")
+		assert.Error(t, err)
+	})
+
+	t.Run("commands top only", func(t *testing.T) {
+		config, err := parseTestArgs(t)
+		require.NoError(t, err)
+		assert.False(t, config.CommandsTopOnly)
+
+		config, err = parseTestArgs(t, "-commands-top-only")
+		require.NoError(t, err)
+		assert.True(t, config.CommandsTopOnly)
+		assert.True(t, createLocatorConfig(config).CommandsTopOnly)
+	})
+
+	t.Run("no emoji", func(t *testing.T) {
+		config, err := parseTestArgs(t)
+		require.NoError(t, err)
+		assert.False(t, config.NoEmoji)
+		assert.False(t, createReportOptions(config).NoEmoji)
+
+		config, err = parseTestArgs(t, "-no-emoji")
+		require.NoError(t, err)
+		assert.True(t, config.NoEmoji)
+		assert.True(t, createReportOptions(config).NoEmoji)
+	})
+}
+
+// TestParseArgs_TrendConfidence tests the -trend-confidence alias of -min-r2
+func TestParseArgs_TrendConfidence(t *testing.T) {
+	trend := &analyzer.TrendMetrics{R2: 0.6}
+
+	// 默认阈值 0.7 隐藏 R²=0.6 的趋势
+	config, err := parseTestArgs(t)
+	require.NoError(t, err)
+	assert.False(t, config.TrendThresholds.IsSignificant(analyzer.MetricHeapInuse, trend))
+
+	// 降低到 0.5 后展示
+	config, err = parseTestArgs(t, "-trend-confidence", "0.5")
+	require.NoError(t, err)
+	assert.Equal(t, 0.5, config.TrendThresholds.Default)
+	assert.True(t, config.TrendThresholds.IsSignificant(analyzer.MetricHeapInuse, trend))
+	assert.True(t, createReportOptions(config).TrendThresholds.IsSignificant(analyzer.MetricHeapInuse, trend))
+
+	// 与 -min-r2 语法相同，支持按指标覆盖
+	config, err = parseTestArgs(t, "-trend-confidence", "0.8,goroutine_count=0.5")
+	require.NoError(t, err)
+	assert.Equal(t, 0.5, config.TrendThresholds.MinR2(analyzer.MetricGoroutineCount))
+
+	_, err = parseTestArgs(t, "-trend-confidence", "0.5", "-min-r2", "0.6")
+	assert.ErrorContains(t, err, "-trend-confidence is an alias of -min-r2")
+}
+
+// TestParseArgs_TopFunctions -top-functions 是 -max-functions 的别名
+func TestParseArgs_TopFunctions(t *testing.T) {
+	config, err := parseTestArgs(t)
+	require.NoError(t, err)
+	assert.Equal(t, reporter.DefaultMaxFunctions, config.Limits.MaxFunctions)
+
+	config, err = parseTestArgs(t, "-top-functions", "20")
+	require.NoError(t, err)
+	assert.Equal(t, 20, config.Limits.MaxFunctions)
+	assert.Equal(t, 20, createReportOptions(config).Limits.MaxFunctions)
+
+	// 超过提取上限时截断到上限
+	config, err = parseTestArgs(t, "-top-functions", "500")
+	require.NoError(t, err)
+	assert.Equal(t, analyzer.MaxTopFunctions, config.Limits.MaxFunctions)
+
+	_, err = parseTestArgs(t, "-top-functions", "-1")
+	assert.ErrorContains(t, err, "invalid max-functions")
+
+	_, err = parseTestArgs(t, "-top-functions", "10", "-max-functions", "20")
+	assert.ErrorContains(t, err, "-top-functions is an alias of -max-functions")
+}
+
+// TestParseArgs_FailOn -fail-on 中英文严重程度等价，无效值报错
+func TestParseArgs_FailOn(t *testing.T) {
+	config, err := parseTestArgs(t)
+	require.NoError(t, err)
+	assert.Empty(t, config.FailOn)
+
+	config, err = parseTestArgs(t, "-fail-on", "高")
+	require.NoError(t, err)
+	assert.Equal(t, "high", config.FailOn)
+
+	config, err = parseTestArgs(t, "-fail-on", "CRITICAL")
+	require.NoError(t, err)
+	assert.Equal(t, "critical", config.FailOn)
+
+	_, err = parseTestArgs(t, "-fail-on", "urgent")
+	assert.ErrorContains(t, err, "invalid -fail-on")
+}
+
+// TestParseArgs_SourceLink 源码链接模板必须包含 {file}，并传入报告选项
+func TestParseArgs_SourceLink(t *testing.T) {
+	config, err := parseTestArgs(t)
+	require.NoError(t, err)
+	assert.Empty(t, config.SourceLinkTemplate)
+	assert.Empty(t, createReportOptions(config).SourceLinks.Template)
+
+	config, err = parseTestArgs(t, "-source-link", "https://git.example.com/src/{file}#L{line}", "-source-root", "/build/app", "-module", "github.com/myapp")
+	require.NoError(t, err)
+	assert.Equal(t, reporter.SourceLinks{
+		Template: "https://git.example.com/src/{file}#L{line}",
+		Root:     "/build/app",
+		Module:   "github.com/myapp",
+	}, createReportOptions(config).SourceLinks)
+
+	_, err = parseTestArgs(t, "-source-link", "https://git.example.com/src#L{line}")
+	assert.ErrorContains(t, err, "missing {file} placeholder")
+}
+
+// TestParseArgs_HTMLTemplate 自定义 HTML 模板在解析参数时校验，并传入报告选项
+func TestParseArgs_HTMLTemplate(t *testing.T) {
+	config, err := parseTestArgs(t)
+	require.NoError(t, err)
+	assert.Empty(t, createReportOptions(config).HTMLTemplatePath)
+
+	dir := t.TempDir()
+	custom := filepath.Join(dir, "brand.html")
+	require.NoError(t, os.WriteFile(custom, []byte(`<h1>{{.Title}}</h1>`), 0644))
+	config, err = parseTestArgs(t, "-html-template", custom)
+	require.NoError(t, err)
+	assert.Equal(t, custom, createReportOptions(config).HTMLTemplatePath)
+
+	broken := filepath.Join(dir, "broken.html")
+	require.NoError(t, os.WriteFile(broken, []byte(`{{if .Title}}`), 0644))
+	_, err = parseTestArgs(t, "-html-template", broken)
+	assert.ErrorContains(t, err, "invalid HTML template")
+}
+
+func TestParseArgs_LogLevel(t *testing.T) {
+	config, err := parseTestArgs(t)
+	require.NoError(t, err)
+	assert.Equal(t, logNormal, config.logLevel())
+
+	config, err = parseTestArgs(t, "-quiet")
+	require.NoError(t, err)
+	assert.Equal(t, logQuiet, config.logLevel())
+
+	config, err = parseTestArgs(t, "-verbose")
+	require.NoError(t, err)
+	assert.Equal(t, logVerbose, config.logLevel())
+
+	_, err = parseTestArgs(t, "-quiet", "-verbose")
+	assert.ErrorContains(t, err, "-quiet cannot be combined with -verbose")
+}
+
+// TestCountFindingsAtLeast 按标准化后的严重程度统计达到阈值的发现
+func TestCountFindingsAtLeast(t *testing.T) {
+	findings := []rules.Finding{
+		{RuleID: "a", Severity: "low"},
+		{RuleID: "b", Severity: "高"},
+		{RuleID: "c", Severity: "critical"},
+		{RuleID: "d", Severity: "medium"},
+	}
+	assert.Equal(t, 4, countFindingsAtLeast(findings, "low"))
+	assert.Equal(t, 3, countFindingsAtLeast(findings, "medium"))
+	assert.Equal(t, 2, countFindingsAtLeast(findings, "high"))
+	assert.Equal(t, 1, countFindingsAtLeast(findings, "critical"))
+	assert.Equal(t, 0, countFindingsAtLeast(nil, "low"))
+}
+
+// TestParseArgs_Serve -serve 不能与写出文件或一次性输出的参数同时使用
+func TestParseArgs_Serve(t *testing.T) {
+	config, err := parseTestArgs(t, "-serve", ":8080")
+	require.NoError(t, err)
+	assert.Equal(t, ":8080", config.Serve)
+	assert.Equal(t, server.DefaultMaxUploadSize>>20, config.MaxUpload)
+
+	config, err = parseTestArgs(t, "-serve", ":8080", "-max-upload", "8")
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), config.MaxUpload)
+	_, err = parseTestArgs(t, "-serve", ":8080", "-max-upload", "0")
+	assert.ErrorContains(t, err, "invalid -max-upload")
+
+	_, err = parseTestArgs(t, "-serve", ":8080", "-output", "report.html")
+	assert.ErrorContains(t, err, "-serve cannot be combined")
+	_, err = parseTestArgs(t, "-serve", ":8080", "-tui")
+	assert.ErrorContains(t, err, "-serve cannot be combined")
+	_, err = parseTestArgs(t, "-serve", ":8080", "-format", "json")
+	assert.ErrorContains(t, err, "-serve cannot be combined")
+
+	assert.Equal(t, "localhost:8080", serveURLHost(":8080"))
+	assert.Equal(t, "0.0.0.0:8080", serveURLHost("0.0.0.0:8080"))
+}
+
+// TestParseArgs_TimeWindow -since/-until 接受 RFC3339 和相对当前时间的时长
+func TestParseArgs_TimeWindow(t *testing.T) {
+	config, err := parseTestArgs(t)
+	require.NoError(t, err)
+	assert.True(t, config.Window.IsZero())
+
+	config, err = parseTestArgs(t, "-since", "2023-11-15T14:00:00Z", "-until", "2023-11-15T15:00:00Z")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 11, 15, 14, 0, 0, 0, time.UTC), config.Window.Since.UTC())
+	assert.Equal(t, time.Date(2023, 11, 15, 15, 0, 0, 0, time.UTC), config.Window.Until.UTC())
+	assert.Equal(t, config.Window, createOptions(config).Window)
+
+	before := time.Now()
+	config, err = parseTestArgs(t, "-since", "-2h")
+	require.NoError(t, err)
+	assert.WithinDuration(t, before.Add(-2*time.Hour), config.Window.Since, time.Minute)
+	assert.True(t, config.Window.Until.IsZero())
+
+	_, err = parseTestArgs(t, "-since", "yesterday")
+	assert.ErrorContains(t, err, "invalid -since 'yesterday'")
+	_, err = parseTestArgs(t, "-since", "-1h", "-until", "-2h")
+	assert.ErrorContains(t, err, "is later than -until")
+}
+
+func TestParseTimeBound(t *testing.T) {
+	now := time.Date(2023, 11, 15, 14, 0, 0, 0, time.UTC)
+
+	bound, err := parseTimeBound("-since", "", now)
+	require.NoError(t, err)
+	assert.True(t, bound.IsZero())
+
+	bound, err = parseTimeBound("-since", "-90m", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 11, 15, 12, 30, 0, 0, time.UTC), bound)
+
+	bound, err = parseTimeBound("-until", "2023-11-15T22:00:00+08:00", now)
+	require.NoError(t, err)
+	assert.True(t, bound.Equal(now))
+
+	_, err = parseTimeBound("-until", "2023-11-15", now)
+	assert.ErrorContains(t, err, "invalid -until '2023-11-15'")
+}
+
+// TestParseMinR2 tests parsing of the -min-r2 option
+func TestParseMinR2(t *testing.T) {
+	t.Run("default only", func(t *testing.T) {
+		thresholds, err := parseMinR2("0.5")
+		require.NoError(t, err)
+		assert.Equal(t, 0.5, thresholds.Default)
+		assert.Equal(t, 0.5, thresholds.MinR2("heap_inuse"))
+	})
+
+	t.Run("per metric override", func(t *testing.T) {
+		thresholds, err := parseMinR2("0.8, goroutine_count=0.4")
+		require.NoError(t, err)
+		assert.Equal(t, 0.8, thresholds.MinR2("heap_inuse"))
+		assert.Equal(t, 0.4, thresholds.MinR2("goroutine_count"))
+
+		thresholds, err = parseMinR2("heap_inuse_objects=0.6")
+		require.NoError(t, err)
+		assert.Equal(t, 0.6, thresholds.MinR2("heap_inuse_objects"))
+		assert.Equal(t, 0.7, thresholds.MinR2("heap_inuse"))
+	})
+
+	t.Run("empty keeps default", func(t *testing.T) {
+		thresholds, err := parseMinR2("")
+		require.NoError(t, err)
+		assert.Equal(t, 0.7, thresholds.Default)
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		for _, value := range []string{"abc", "1.5", "-0.1", "cpu=0.5", "heap_inuse=x"} {
+			_, err := parseMinR2(value)
+			assert.Error(t, err, value)
+		}
+	})
+}
+
+// TestValidateLimits tests validation of report size limits
+func TestValidateLimits(t *testing.T) {
+	assert.NoError(t, validateLimits(reporter.DefaultLimits()))
+	assert.NoError(t, validateLimits(reporter.Limits{}), "zero means unlimited")
+
+	err := validateLimits(reporter.Limits{MaxFrames: -1})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "max-chain-frames")
+}
+
+// TestCreateReportOptions_Limits tests that limits are passed to the reporter
+func TestCreateReportOptions_Limits(t *testing.T) {
+	config := &Config{Limits: reporter.Limits{MaxFindings: 3, MaxFunctions: 7}}
+	opts := createReportOptions(config)
+	assert.Equal(t, 3, opts.Limits.MaxFindings)
+	assert.Equal(t, 7, opts.Limits.MaxFunctions)
+}
+
+func TestLogTrendWeights(t *testing.T) {
+	trends := map[string]*analyzer.GroupTrends{
+		"heap":      {HeapInuse: &analyzer.TrendMetrics{Slope: 2, R2: 0.9, Weighting: analyzer.WeightingDuration, Weights: []float64{30, 1, 30}}},
+		"goroutine": {GoroutineCount: &analyzer.TrendMetrics{Slope: 1, R2: 1, Weighting: analyzer.WeightingNone}},
+	}
+
+	var buf strings.Builder
+	logTrendWeights(&buf, trends)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, "[debug] trend goroutine_count: weighting=none weights=[] slope=1.0000 r2=1.0000", lines[0])
+	assert.Equal(t, "[debug] trend heap_inuse: weighting=duration weights=[30 1 30] slope=2.0000 r2=0.9000", lines[1])
+}
+
+func TestParseSourceDateEpoch(t *testing.T) {
+	got, err := parseSourceDateEpoch("")
+	require.NoError(t, err)
+	assert.True(t, got.IsZero())
+
+	got, err = parseSourceDateEpoch("1704164645")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), got)
+
+	for _, invalid := range []string{"yesterday", "-1", "1.5"} {
+		_, err = parseSourceDateEpoch(invalid)
+		assert.Error(t, err, invalid)
+	}
+
+	opts := createReportOptions(&Config{GeneratedAt: got})
+	assert.Equal(t, got, opts.GeneratedAt)
+}
+
+func TestParseArgs_Sort(t *testing.T) {
+	t.Run("default order", func(t *testing.T) {
+		config, err := parseTestArgs(t)
+		require.NoError(t, err)
+		assert.Equal(t, reporter.DefaultSortOptions(), config.Sort)
+	})
+
+	t.Run("repeated flags", func(t *testing.T) {
+		config, err := parseTestArgs(t, "-sort", "groups=severity", "-sort", "files=desc")
+		require.NoError(t, err)
+		assert.Equal(t, reporter.SortOptions{Groups: reporter.GroupOrderSeverity, Files: reporter.FileOrderDesc}, config.Sort)
+		assert.Equal(t, config.Sort, createReportOptions(config).Sort)
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		_, err := parseTestArgs(t, "-sort", "files=random")
+		assert.Error(t, err)
+	})
+}
+
+func TestParseArgs_Format(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{name: "dot", args: []string{"-format", "dot"}, want: "dot"},
+		{name: "json", args: []string{"-format", "json"}, want: "json"},
+		{name: "markdown", args: []string{"-format", "markdown"}, want: "markdown"},
+		{name: "unknown", args: []string{"-format", "svg"}, wantErr: true},
+		{name: "tui with dot", args: []string{"-tui", "-format", "dot"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := parseTestArgs(t, tt.args...)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, config.Format)
+		})
+	}
+}
+
+// TestRenderReport tests rendering through registered renderers
+func TestRenderReport(t *testing.T) {
+	require.NoError(t, reporter.RegisterRenderer("test-dashboard", reporter.RendererFunc(func(report *reporter.Report, w io.Writer) error {
+		_, err := fmt.Fprintf(w, "findings=%d\n", len(report.Findings))
+		return err
+	})))
+
+	// 注册后 -format 可以选择该格式
+	config, err := parseTestArgs(t, "-format", "test-dashboard")
+	require.NoError(t, err)
+	assert.Equal(t, "test-dashboard", config.Format)
+
+	outputPath := filepath.Join(t.TempDir(), "dashboard.txt")
+	report := &reporter.Report{Findings: []rules.Finding{{RuleID: "a"}, {RuleID: "b"}}}
+	require.NoError(t, renderReport("test-dashboard", outputPath, report, newLogger(io.Discard, logNormal, false)))
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.Equal(t, "findings=2\n", string(content))
+
+	assert.EqualError(t, renderReport("svg", outputPath, report, newLogger(io.Discard, logNormal, false)), "unknown format 'svg'")
+}
+
+// TestRenderReport_NoEmoji 报告路径通过 logger 输出，-no-emoji 时标准输出和标准错误中都没有 emoji，-quiet 时不输出
+func TestRenderReport_NoEmoji(t *testing.T) {
+	config, err := parseTestArgs(t, "-no-emoji")
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	render := func(level logLevel) (string, string) {
+		originalStdout := os.Stdout
+		defer func() { os.Stdout = originalStdout }()
+		r, w, err := os.Pipe()
+		require.NoError(t, err)
+		os.Stdout = w
+
+		var stderr strings.Builder
+		logger := newLogger(&stderr, level, config.NoEmoji)
+		report := &reporter.Report{Options: createReportOptions(config)}
+		for _, format := range []string{"html", "json", "markdown", "summary"} {
+			require.NoError(t, renderReport(format, filepath.Join(dir, "report."+format), report, logger))
+		}
+		require.NoError(t, renderIndex("html", filepath.Join(dir, "index.html"), nil, report.Options, logger))
+
+		require.NoError(t, w.Close())
+		stdout, err := io.ReadAll(r)
+		require.NoError(t, err)
+		return string(stdout), stderr.String()
+	}
+
+	stdout, stderr := render(logNormal)
+	assert.Empty(t, stdout)
+	assert.Contains(t, stderr, "[OK] HTML 报告已生成: "+filepath.Join(dir, "report.html"))
+	assert.Contains(t, stderr, "[OK] 文件索引已生成: ")
+	for _, r := range stdout + stderr {
+		assert.False(t, unicode.Is(unicode.So, r) || unicode.Is(unicode.Variation_Selector, r), "unexpected icon %q", r)
+	}
+
+	// -quiet 时不输出报告路径
+	stdout, stderr = render(logQuiet)
+	assert.Empty(t, stdout)
+	assert.Empty(t, stderr)
+}
+
+func TestParseExtensions(t *testing.T) {
+	exts, err := parseExtensions(" .prof, out ,,")
+	require.NoError(t, err)
+	assert.Equal(t, []string{".prof", ".out"}, exts)
+
+	exts, err = parseExtensions("")
+	require.NoError(t, err)
+	assert.Nil(t, exts)
+
+	// 统一为小写；.gz 压缩的扩展名按内层扩展名记录
+	exts, err = parseExtensions(".PROF,.pb.gz,gz")
+	require.NoError(t, err)
+	assert.Equal(t, []string{".prof", ".pb", ".gz"}, exts)
+
+	for _, invalid := range []string{".", ".a.b", ".a.b.gz", "a/b"} {
+		_, err := parseExtensions(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestProfileFilter(t *testing.T) {
+	dir := t.TempDir()
+	var buf bytes.Buffer
+	require.NoError(t, pprof.Lookup("goroutine").WriteTo(&buf, 0))
+
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, data, 0644))
+		return path
+	}
+	pprofFile := write("heap.pprof", buf.Bytes())
+	profFile := write("cpu.prof", buf.Bytes())
+	hashFile := write("3f9a2c7e", buf.Bytes())
+	notesFile := write("NOTES", []byte("capture notes"))
+	write("readme.md", []byte("# readme"))
+
+	t.Run("default", func(t *testing.T) {
+		filter := defaultProfileFilter()
+		assert.True(t, filter.matches(pprofFile))
+		assert.False(t, filter.matches(profFile))
+		assert.False(t, filter.matches(hashFile))
+	})
+
+	t.Run("extra extensions and sniff", func(t *testing.T) {
+		filter := newProfileFilter([]string{".prof"}, true)
+		assert.True(t, filter.matches(pprofFile))
+		assert.True(t, filter.matches(profFile))
+		assert.True(t, filter.matches(hashFile))
+		assert.False(t, filter.matches(notesFile), "extensionless files that fail the sniff are skipped")
+	})
+
+	t.Run("case insensitive", func(t *testing.T) {
+		other := t.TempDir()
+		upper := filepath.Join(other, "CPU.PROF")
+		compressed := filepath.Join(other, "heap.Pb.GZ")
+		assert.False(t, defaultProfileFilter().matches(upper), ".prof is rejected unless configured")
+		assert.True(t, defaultProfileFilter().matches(filepath.Join(other, "HEAP.PPROF")))
+
+		filter := newProfileFilter([]string{".prof", ".pb"}, false)
+		assert.True(t, filter.matches(upper))
+		assert.True(t, filter.matches(compressed))
+	})
+
+	t.Run("directory walk", func(t *testing.T) {
+		paths, err := collectProfilePathsWithFilter([]string{dir}, newProfileFilter([]string{".prof"}, true))
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{pprofFile, profFile, hashFile}, paths)
+
+		paths, err = collectProfilePaths([]string{dir})
+		require.NoError(t, err)
+		assert.Equal(t, []string{pprofFile}, paths)
+	})
+}
+
+func TestParseArgs_Extensions(t *testing.T) {
+	config, err := parseTestArgs(t, "-ext", ".prof,out", "-sniff")
+	require.NoError(t, err)
+	assert.Equal(t, []string{".prof", ".out"}, config.Extensions)
+	assert.True(t, config.Sniff)
+
+	_, err = parseTestArgs(t, "-ext", "a/b")
+	assert.Error(t, err)
+}
+
+// TestParseArgs_Timeout tests the -timeout flag
+func TestParseArgs_Timeout(t *testing.T) {
+	config, err := parseTestArgs(t)
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), config.Timeout)
+
+	config, err = parseTestArgs(t, "-timeout", "30s")
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, config.Timeout)
+
+	_, err = parseTestArgs(t, "-timeout", "-1s")
+	assert.Error(t, err)
+}
+
+// TestParseArgs_Stats tests the -stats flag
+func TestParseArgs_Stats(t *testing.T) {
+	config, err := parseTestArgs(t)
+	require.NoError(t, err)
+	assert.False(t, config.Stats)
+
+	config, err = parseTestArgs(t, "-stats")
+	require.NoError(t, err)
+	assert.True(t, config.Stats)
+}
+
+// TestParseArgs_OrderByFilename tests the -order-by-filename flag
+func TestParseArgs_OrderByFilename(t *testing.T) {
+	config, err := parseTestArgs(t)
+	require.NoError(t, err)
+	assert.False(t, config.OrderByFilename)
+
+	config, err = parseTestArgs(t, "-order-by-filename")
+	require.NoError(t, err)
+	assert.True(t, config.OrderByFilename)
+}
+
+func TestParseArgs_Dedup(t *testing.T) {
+	config, err := parseTestArgs(t)
+	require.NoError(t, err)
+	assert.False(t, createOptions(config).Dedup)
+
+	config, err = parseTestArgs(t, "-dedup")
+	require.NoError(t, err)
+	assert.True(t, createOptions(config).Dedup)
+}
+
+// TestParseArgs_Bench tests the -bench, -bench-n and -bench-output flags
+func TestParseArgs_Bench(t *testing.T) {
+	config, err := parseTestArgs(t)
+	require.NoError(t, err)
+	assert.False(t, config.Bench)
+
+	config, err = parseTestArgs(t, "-bench", "-bench-n", "5000")
+	require.NoError(t, err)
+	assert.True(t, config.Bench)
+	assert.Equal(t, int64(5000), config.BenchN)
+
+	_, err = parseTestArgs(t, "-bench", "-bench-n", "-1")
+	assert.Error(t, err)
+
+	_, err = parseTestArgs(t, "-bench-n", "5000")
+	assert.Error(t, err)
+}
+
+// TestResolveBenchIterations tests reading the iteration count from flags or benchmark output
+func TestResolveBenchIterations(t *testing.T) {
+	n, err := resolveBenchIterations(&Config{Bench: true})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), n)
+
+	output := filepath.Join(t.TempDir(), "bench.txt")
+	require.NoError(t, os.WriteFile(output, []byte("goos: linux\nBenchmarkEncode-8   \t  200000\t      6012 ns/op\nPASS\n"), 0o644))
+
+	n, err = resolveBenchIterations(&Config{Bench: true, BenchOutput: output})
+	require.NoError(t, err)
+	assert.Equal(t, int64(200000), n)
+
+	// -bench-n 优先于输出文件
+	n, err = resolveBenchIterations(&Config{Bench: true, BenchN: 42, BenchOutput: output})
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), n)
+
+	_, err = resolveBenchIterations(&Config{Bench: true, BenchOutput: filepath.Join(t.TempDir(), "missing.txt")})
+	assert.Error(t, err)
+
+	empty := filepath.Join(t.TempDir(), "empty.txt")
+	require.NoError(t, os.WriteFile(empty, []byte("PASS\n"), 0o644))
+	_, err = resolveBenchIterations(&Config{Bench: true, BenchOutput: empty})
+	assert.Error(t, err)
+}
+
+// TestParseArgs_Concurrency tests the -concurrency flag
+func TestParseArgs_Concurrency(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected int
+	}{
+		{name: "default", args: nil, expected: runtime.GOMAXPROCS(0)},
+		{name: "explicit", args: []string{"-concurrency", "3"}, expected: 3},
+		{name: "clamped", args: []string{"-concurrency", "0"}, expected: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := parseTestArgs(t, tt.args...)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, config.Concurrency)
+		})
+	}
+}
+
+// TestApplyCategoryConfig tests loading the category style config
+func TestApplyCategoryConfig(t *testing.T) {
+	t.Cleanup(func() { locator.SetCategoryTheme(nil) })
+
+	assert.NoError(t, applyCategoryConfig("", false))
+	assert.Equal(t, "业务", locator.CategoryBusiness.String())
+
+	path := filepath.Join(t.TempDir(), "categories.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("categories:\n  business:\n    label: Business\n"), 0o644))
+	require.NoError(t, applyCategoryConfig(path, false))
+	assert.Equal(t, "Business", locator.CategoryBusiness.String())
+
+	assert.Error(t, applyCategoryConfig(filepath.Join(t.TempDir(), "missing.yaml"), false))
+
+	// -no-emoji 只替换内置 emoji 图标，自定义图标保持不变
+	require.NoError(t, os.WriteFile(path, []byte("categories:\n  stdlib:\n    icon: \"STD\"\n"), 0o644))
+	require.NoError(t, applyCategoryConfig(path, true))
+	assert.Equal(t, "[business]", locator.CategoryBusiness.Icon())
+	assert.Equal(t, "STD", locator.CategoryStdlib.Icon())
+	require.NoError(t, applyCategoryConfig("", true))
+	assert.Equal(t, "[runtime]", locator.CategoryRuntime.Icon())
+}
+
+// TestHistoryKey tests that the history key is stable across path spellings and order
+func TestHistoryKey(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a")
+	b := filepath.Join(dir, "b")
+
+	assert.Equal(t, historyKey([]string{a, b}), historyKey([]string{b, a + "/", a}))
+	assert.Equal(t, a+","+b, historyKey([]string{b, a}))
+	assert.NotEqual(t, historyKey([]string{a}), historyKey([]string{b}))
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(wd, "profiles"), historyKey([]string{"./profiles"}))
+}
+
+// TestParseArgs_ExplainFunc tests the -explain-func flag
+func TestParseArgs_ExplainFunc(t *testing.T) {
+	config, err := parseTestArgs(t, "-explain-func", "github.com/myorg/app.HandleOrder")
+	require.NoError(t, err)
+	assert.Equal(t, "github.com/myorg/app.HandleOrder", config.ExplainFunc)
+
+	_, err = parseTestArgs(t, "-explain-func", "main.main", "-format", "html")
+	assert.EqualError(t, err, "-explain-func only supports text output")
+
+	_, err = parseTestArgs(t, "-explain-func", "main.main", "-tui")
+	assert.EqualError(t, err, "-explain-func only supports text output")
+}
+
+// TestParseArgs_Index tests the -index flag
+func TestParseArgs_Index(t *testing.T) {
+	config, err := parseTestArgs(t, "-index", "-format", "html")
+	require.NoError(t, err)
+	assert.True(t, config.Index)
+
+	_, err = parseTestArgs(t, "-index", "-format", "dot")
+	assert.EqualError(t, err, "-index only supports text and html output")
+
+	_, err = parseTestArgs(t, "-index", "-explain-func", "main.main")
+	assert.EqualError(t, err, "-index cannot be combined with -explain-func")
+}
+
+// TestParseArgs_OnlyNew tests the -only-new flag
+func TestParseArgs_OnlyNew(t *testing.T) {
+	config, err := parseTestArgs(t, "-only-new", "-history", "history.json")
+	require.NoError(t, err)
+	assert.True(t, config.OnlyNew)
+
+	_, err = parseTestArgs(t, "-only-new")
+	assert.EqualError(t, err, "-only-new requires -history")
+}
+
+// TestParseArgs_ClassifyOverride tests the -classify-override flag
+func TestParseArgs_ClassifyOverride(t *testing.T) {
+	config, err := parseTestArgs(t, "-classify-override", "github.com/x=third_party,internal/shared=business")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]locator.CodeCategory{
+		"github.com/x":    locator.CategoryThirdParty,
+		"internal/shared": locator.CategoryBusiness,
+	}, config.ClassifyOverrides)
+
+	locatorConfig := createLocatorConfig(config)
+	assert.Equal(t, locator.CategoryThirdParty, locator.NewClassifier(locatorConfig).Classify("github.com/x/y"))
+
+	_, err = parseTestArgs(t, "-classify-override", "github.com/x=vendor")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown category 'vendor'")
+}
+
+// TestParseArgs_CommandTemplate tests the -pprof-bin, -pprof-flag-style and -command-template flags
+func TestParseArgs_CommandTemplate(t *testing.T) {
+	config, err := parseTestArgs(t)
+	require.NoError(t, err)
+	assert.Equal(t, locator.CommandTemplate{
+		Binary:    locator.DefaultPprofBinary,
+		FlagStyle: locator.FlagStyleSingle,
+		Template:  locator.DefaultCommandTemplate,
+	}, config.CommandTemplate)
+
+	config, err = parseTestArgs(t,
+		"-pprof-bin", "mycli profile", "-pprof-flag-style", "separate",
+		"-command-template", "{{.bin}} --file {{.profile}} {{.flags}}",
+	)
+	require.NoError(t, err)
+	locatorConfig := createLocatorConfig(config)
+	assert.Equal(t, config.CommandTemplate, locatorConfig.CommandTemplate)
+	command := locator.NewCommandGeneratorWithOptions(locator.CommandOptions{Template: locatorConfig.CommandTemplate}).GenerateTopCommand("cpu.pprof")
+	assert.Equal(t, "mycli profile --file cpu.pprof -top", command.Command)
+
+	_, err = parseTestArgs(t, "-pprof-flag-style", "posix")
+	assert.EqualError(t, err, "invalid flag style 'posix', must be one of: single, double, separate")
+
+	_, err = parseTestArgs(t, "-command-template", "{{.bin}} {{.flags}}")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not contain the profile path")
+}
+
+// TestParseArgs_ValueType tests the -value-type flag
+func TestParseArgs_ValueType(t *testing.T) {
+	config, err := parseTestArgs(t)
+	require.NoError(t, err)
+	assert.Empty(t, config.ValueType)
+	assert.Empty(t, createLocatorConfig(config).ValueType)
+
+	config, err = parseTestArgs(t, "-value-type", "revenue")
+	require.NoError(t, err)
+	assert.Equal(t, "revenue", config.ValueType)
+	assert.Equal(t, "revenue", createLocatorConfig(config).ValueType)
 }
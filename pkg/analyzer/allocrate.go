@@ -0,0 +1,118 @@
+package analyzer
+
+import (
+	"math"
+	"time"
+)
+
+// DefaultAllocRateMinGrowth 分配速率和相关比值至少增长 20% 才视为变化
+const DefaultAllocRateMinGrowth = 0.2
+
+// 分配速率增长的两种模式，处理方式截然不同
+const (
+	// AllocGrowthMoreGoroutines goroutine 变多，每个 goroutine 的分配速率基本不变：应限制并发
+	AllocGrowthMoreGoroutines = "more_goroutines"
+	// AllocGrowthPerGoroutine 每个 goroutine 的分配速率上升：应优化分配热点
+	AllocGrowthPerGoroutine = "per_goroutine"
+)
+
+// AllocRatePoint 一个 heap 采样区间的分配速率，以及同一时刻的 goroutine 数
+type AllocRatePoint struct {
+	Time         time.Time // 区间结束时的 heap 快照时间
+	AllocRate    float64   // 区间内的分配速率 (bytes/s)
+	Goroutines   int64     // 时间最接近的 goroutine 快照中的 goroutine 数
+	PerGoroutine float64   // 每个 goroutine 的分配速率 (bytes/s)
+}
+
+// AllocsPerGoroutine 分配速率与 goroutine 数的关联分析结果
+type AllocsPerGoroutine struct {
+	Points []AllocRatePoint // 按时间排序的每个 heap 区间
+	Mode   string           // AllocGrowthMoreGoroutines 或 AllocGrowthPerGoroutine
+
+	// 最后一个区间相对第一个区间的相对变化，0.5 表示 +50%
+	RateGrowth         float64
+	GoroutineGrowth    float64
+	PerGoroutineGrowth float64
+}
+
+// AnalyzeAllocsPerGoroutine 关联 heap 和 goroutine 快照，判断分配速率的增长来自哪里
+// heap 的 alloc_space 是进程启动以来的累计值，相邻快照的差值除以时间间隔即为分配速率；
+// 每个区间取时间最接近的 goroutine 快照计算每个 goroutine 的分配速率。
+// 分配速率增长至少 minGrowth 时：每个 goroutine 的速率也增长至少 minGrowth 为 AllocGrowthPerGoroutine，
+// 否则 goroutine 数增长至少 minGrowth 为 AllocGrowthMoreGoroutines。
+// 需要至少 3 个带时间的 heap 快照 (2 个区间) 和 1 个 goroutine 快照，条件不满足时返回 nil
+func AnalyzeAllocsPerGoroutine(heapFiles, goroutineFiles []ProfileFile, minGrowth float64) *AllocsPerGoroutine {
+	var points []AllocRatePoint
+	for i := 1; i < len(heapFiles); i++ {
+		prev, cur := heapFiles[i-1], heapFiles[i]
+		if prev.Metrics == nil || cur.Metrics == nil {
+			continue
+		}
+		seconds := cur.Time.Sub(prev.Time).Seconds()
+		allocated := cur.Metrics.AllocSpace - prev.Metrics.AllocSpace
+		// 时间未知或进程重启 (累计值回落) 的区间没有可用的速率
+		if seconds <= 0 || allocated < 0 {
+			continue
+		}
+		goroutines := nearestGoroutineCount(goroutineFiles, cur.Time)
+		if goroutines <= 0 {
+			continue
+		}
+		rate := float64(allocated) / seconds
+		points = append(points, AllocRatePoint{
+			Time:         cur.Time,
+			AllocRate:    rate,
+			Goroutines:   goroutines,
+			PerGoroutine: rate / float64(goroutines),
+		})
+	}
+	if len(points) < 2 {
+		return nil
+	}
+
+	first, last := points[0], points[len(points)-1]
+	result := &AllocsPerGoroutine{
+		Points:             points,
+		RateGrowth:         relativeGrowth(first.AllocRate, last.AllocRate),
+		GoroutineGrowth:    relativeGrowth(float64(first.Goroutines), float64(last.Goroutines)),
+		PerGoroutineGrowth: relativeGrowth(first.PerGoroutine, last.PerGoroutine),
+	}
+	switch {
+	case result.RateGrowth < minGrowth:
+		return nil
+	case result.PerGoroutineGrowth >= minGrowth:
+		result.Mode = AllocGrowthPerGoroutine
+	case result.GoroutineGrowth >= minGrowth:
+		result.Mode = AllocGrowthMoreGoroutines
+	default:
+		return nil
+	}
+	return result
+}
+
+// nearestGoroutineCount 返回时间最接近 at 的 goroutine 快照中的 goroutine 数，没有可用快照时返回 0
+func nearestGoroutineCount(files []ProfileFile, at time.Time) int64 {
+	var count int64
+	best := time.Duration(math.MaxInt64)
+	for _, file := range files {
+		if file.Metrics == nil {
+			continue
+		}
+		diff := file.Time.Sub(at)
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff < best {
+			best, count = diff, file.Metrics.GoroutineCount
+		}
+	}
+	return count
+}
+
+// relativeGrowth 返回 after 相对 before 的变化，before 为 0 时返回 0
+func relativeGrowth(before, after float64) float64 {
+	if before == 0 {
+		return 0
+	}
+	return (after - before) / before
+}
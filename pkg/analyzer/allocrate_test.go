@@ -0,0 +1,80 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAllocRateFiles 创建每分钟一个的 heap 和 goroutine 快照
+// allocSpace 为累计分配字节数，goroutines 为同一时刻的 goroutine 数
+func newAllocRateFiles(allocSpace, goroutines []int64) (heapFiles, goroutineFiles []ProfileFile) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	for i := range allocSpace {
+		at := start.Add(time.Duration(i) * time.Minute)
+		heapFiles = append(heapFiles, ProfileFile{Time: at, Metrics: &ProfileMetrics{AllocSpace: allocSpace[i]}})
+		goroutineFiles = append(goroutineFiles, ProfileFile{Time: at, Metrics: &ProfileMetrics{GoroutineCount: goroutines[i]}})
+	}
+	return heapFiles, goroutineFiles
+}
+
+func TestAnalyzeAllocsPerGoroutine(t *testing.T) {
+	t.Run("more goroutines allocating normally", func(t *testing.T) {
+		// 每个 goroutine 每分钟分配 60 KB，goroutine 从 10 增加到 20
+		heapFiles, goroutineFiles := newAllocRateFiles(
+			[]int64{0, 600 * 1024, 600*1024 + 900*1024, 1500*1024 + 1200*1024},
+			[]int64{10, 10, 15, 20},
+		)
+		result := AnalyzeAllocsPerGoroutine(heapFiles, goroutineFiles, DefaultAllocRateMinGrowth)
+		require.NotNil(t, result)
+		assert.Equal(t, AllocGrowthMoreGoroutines, result.Mode)
+		require.Len(t, result.Points, 3)
+		assert.Equal(t, int64(15), result.Points[1].Goroutines)
+		assert.InDelta(t, 1024.0, result.Points[0].PerGoroutine, 0.001)
+		assert.InDelta(t, 1.0, result.RateGrowth, 0.001)
+		assert.InDelta(t, 1.0, result.GoroutineGrowth, 0.001)
+		assert.InDelta(t, 0.0, result.PerGoroutineGrowth, 0.001)
+	})
+
+	t.Run("allocations per goroutine rising", func(t *testing.T) {
+		heapFiles, goroutineFiles := newAllocRateFiles(
+			[]int64{0, 600 * 1024, 1800 * 1024, 3600 * 1024},
+			[]int64{10, 10, 10, 10},
+		)
+		result := AnalyzeAllocsPerGoroutine(heapFiles, goroutineFiles, DefaultAllocRateMinGrowth)
+		require.NotNil(t, result)
+		assert.Equal(t, AllocGrowthPerGoroutine, result.Mode)
+		assert.InDelta(t, 2.0, result.PerGoroutineGrowth, 0.001)
+		assert.InDelta(t, 0.0, result.GoroutineGrowth, 0.001)
+	})
+
+	t.Run("steady allocation rate", func(t *testing.T) {
+		heapFiles, goroutineFiles := newAllocRateFiles([]int64{0, 1024, 2048, 3072}, []int64{10, 20, 30, 40})
+		assert.Nil(t, AnalyzeAllocsPerGoroutine(heapFiles, goroutineFiles, DefaultAllocRateMinGrowth))
+	})
+
+	t.Run("process restart skips the interval", func(t *testing.T) {
+		// 第三个快照累计值回落，只剩一个可用区间
+		heapFiles, goroutineFiles := newAllocRateFiles([]int64{0, 1024, 0}, []int64{10, 10, 10})
+		assert.Nil(t, AnalyzeAllocsPerGoroutine(heapFiles, goroutineFiles, DefaultAllocRateMinGrowth))
+	})
+
+	t.Run("missing goroutine snapshots", func(t *testing.T) {
+		heapFiles, _ := newAllocRateFiles([]int64{0, 1024, 4096}, []int64{10, 10, 10})
+		assert.Nil(t, AnalyzeAllocsPerGoroutine(heapFiles, nil, DefaultAllocRateMinGrowth))
+	})
+}
+
+func TestNearestGoroutineCount(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	files := []ProfileFile{
+		{Time: start, Metrics: &ProfileMetrics{GoroutineCount: 10}},
+		{Time: start.Add(10 * time.Minute), Metrics: &ProfileMetrics{GoroutineCount: 50}},
+		{Time: start.Add(20 * time.Minute)},
+	}
+	assert.Equal(t, int64(10), nearestGoroutineCount(files, start.Add(4*time.Minute)))
+	assert.Equal(t, int64(50), nearestGoroutineCount(files, start.Add(19*time.Minute)))
+	assert.Zero(t, nearestGoroutineCount(nil, start))
+}
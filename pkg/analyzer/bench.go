@@ -0,0 +1,201 @@
+package analyzer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/pprof/profile"
+)
+
+// BenchStats 基准测试 profile 过滤 testing 框架帧后，归一化到每次操作的消耗
+type BenchStats struct {
+	N              int64   // 基准测试迭代次数，未知时为 0
+	HarnessSamples int64   // 被过滤掉的 testing 框架样本数
+	NsPerOp        float64 // 每次操作的 CPU 时间 (仅 cpu profile，N 未知或缺少采集时长时为 0)
+	NoDuration     bool    // CPU profile 缺少采集时长，无法换算 ns/op
+	BytesPerOp     float64 // 每次操作分配的字节数 (仅 heap profile，N 未知时为 0)
+	AllocsPerOp    float64 // 每次操作的分配次数 (仅 heap profile，N 未知时为 0)
+}
+
+// BenchmarkResult go test -bench 输出中的一行结果
+type BenchmarkResult struct {
+	Name        string  // 基准测试名，不含 -GOMAXPROCS 后缀
+	N           int64   // 迭代次数
+	NsPerOp     float64 // ns/op
+	BytesPerOp  float64 // B/op，没有 -benchmem 时为 0
+	AllocsPerOp float64 // allocs/op，没有 -benchmem 时为 0
+}
+
+// benchmarkLinePattern 匹配 "BenchmarkFoo-8   1000000   1234 ns/op ..." 形式的结果行
+var benchmarkLinePattern = regexp.MustCompile(`^(Benchmark\S+?)(?:-\d+)?\s+(\d+)\s+(.*)$`)
+
+// ParseBenchmarkOutput 解析 go test -bench 的文本输出，忽略非结果行
+func ParseBenchmarkOutput(r io.Reader) ([]BenchmarkResult, error) {
+	var results []BenchmarkResult
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		match := benchmarkLinePattern.FindStringSubmatch(strings.TrimSpace(scanner.Text()))
+		if match == nil {
+			continue
+		}
+		n, err := strconv.ParseInt(match[2], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		result := BenchmarkResult{Name: match[1], N: n}
+		fields := strings.Fields(match[3])
+		for i := 0; i+1 < len(fields); i += 2 {
+			value, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				break
+			}
+			switch fields[i+1] {
+			case "ns/op":
+				result.NsPerOp = value
+			case "B/op":
+				result.BytesPerOp = value
+			case "allocs/op":
+				result.AllocsPerOp = value
+			}
+		}
+		results = append(results, result)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// BenchmarkIterations 从基准测试结果中得出 profile 覆盖的总迭代次数
+// 一次 go test 只生成一个 profile，所以只能对应一个基准测试；-count 重复运行时累加各次的 N。
+// 包含多个不同的基准测试时无法拆分，返回错误
+func BenchmarkIterations(results []BenchmarkResult) (int64, error) {
+	if len(results) == 0 {
+		return 0, fmt.Errorf("no benchmark results found")
+	}
+	var total int64
+	for _, r := range results {
+		if r.Name != results[0].Name {
+			return 0, fmt.Errorf("benchmark output contains multiple benchmarks (%s, %s); run one benchmark per profile or pass the iteration count explicitly", results[0].Name, r.Name)
+		}
+		total += r.N
+	}
+	return total, nil
+}
+
+// IsBenchHarnessFrame 是否为基准测试框架的栈帧 (testing 包或 goroutine 入口 runtime.goexit)
+func IsBenchHarnessFrame(funcName string) bool {
+	return funcName == "runtime.goexit" || PackageOf(funcName) == "testing"
+}
+
+// FilterBenchHarness 返回去掉 testing 框架开销后的 profile 副本和被丢弃的样本数
+// 从叶子向根第一个非 runtime 函数属于 testing 包的样本是框架自身的工作 (计时、ReportAllocs 等)，整体丢弃；
+// 其余样本去掉根部连续的框架帧 (runtime.goexit → testing.tRunner/(*B).runN ...)，
+// 使调用链从基准测试函数开始。不经过业务代码的运行时样本 (如 GC 后台标记) 保留。
+// 原 profile 不被修改，副本与它共享 Location 和 Function
+func FilterBenchHarness(p *profile.Profile) (*profile.Profile, int64) {
+	if p == nil {
+		return nil, 0
+	}
+	filtered := &profile.Profile{
+		SampleType:        p.SampleType,
+		DefaultSampleType: p.DefaultSampleType,
+		Mapping:           p.Mapping,
+		Location:          p.Location,
+		Function:          p.Function,
+		Comments:          p.Comments,
+		DropFrames:        p.DropFrames,
+		KeepFrames:        p.KeepFrames,
+		TimeNanos:         p.TimeNanos,
+		DurationNanos:     p.DurationNanos,
+		PeriodType:        p.PeriodType,
+		Period:            p.Period,
+		Sample:            make([]*profile.Sample, 0, len(p.Sample)),
+	}
+
+	var dropped int64
+	for _, sample := range p.Sample {
+		if harnessOwned(sampleFunctions(sample)) {
+			dropped++
+			continue
+		}
+
+		// Location 从叶子到根排列，根部在末尾
+		end := len(sample.Location)
+		for end > 0 && locationIsHarness(sample.Location[end-1]) {
+			end--
+		}
+		if end == 0 {
+			dropped++
+			continue
+		}
+		trimmed := *sample
+		trimmed.Location = sample.Location[:end:end]
+		filtered.Sample = append(filtered.Sample, &trimmed)
+	}
+	return filtered, dropped
+}
+
+// harnessOwned 从叶子向根第一个非 runtime 函数是否属于 testing 包
+func harnessOwned(frames []string) bool {
+	for _, name := range frames {
+		pkg := PackageOf(name)
+		if pkg == "runtime" || strings.HasPrefix(pkg, "runtime/") {
+			continue
+		}
+		return pkg == "testing"
+	}
+	return false
+}
+
+// locationIsHarness Location 的所有内联函数是否都是框架帧
+func locationIsHarness(loc *profile.Location) bool {
+	if loc == nil || len(loc.Line) == 0 {
+		return false
+	}
+	for _, line := range loc.Line {
+		if line.Function == nil || !IsBenchHarnessFrame(line.Function.Name) {
+			return false
+		}
+	}
+	return true
+}
+
+// ApplyBenchMode 对所有快照过滤 testing 框架开销并重新提取指标
+// n 为基准测试迭代次数，大于 0 时把 CPU 时间和分配量换算为每次操作的消耗 (ProfileMetrics.Bench)
+func ApplyBenchMode(groups []ProfileGroup, n int64) {
+	for gi := range groups {
+		group := &groups[gi]
+		for fi := range group.Files {
+			file := &group.Files[fi]
+			if file.Profile == nil {
+				continue
+			}
+			var dropped int64
+			file.Profile, dropped = FilterBenchHarness(file.Profile)
+			file.Metrics = ExtractMetrics(file.Profile, group.Type)
+			file.Metrics.Bench = benchStats(file.Metrics, group.Type, n, dropped)
+		}
+	}
+}
+
+// benchStats 根据过滤后的指标计算每次操作的消耗
+func benchStats(m *ProfileMetrics, profileType string, n, dropped int64) *BenchStats {
+	stats := &BenchStats{N: n, HarnessSamples: dropped, NoDuration: m.NoDuration}
+	if n <= 0 {
+		return stats
+	}
+	switch profileType {
+	case "cpu":
+		stats.NsPerOp = float64(m.CPUTime.Nanoseconds()) / float64(n)
+	case "heap":
+		stats.BytesPerOp = float64(m.AllocSpace) / float64(n)
+		stats.AllocsPerOp = float64(m.AllocObjects) / float64(n)
+	}
+	return stats
+}
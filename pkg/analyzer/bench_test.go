@@ -0,0 +1,133 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// benchRoot 基准测试框架在栈底添加的帧 (从叶子到根)
+var benchRoot = []string{"testing.(*B).runN", "testing.(*B).launch", "runtime.goexit"}
+
+// newBenchCPUProfile 创建 go test -cpuprofile 形式的 CPU profile
+func newBenchCPUProfile(samples ...*profile.Sample) *profile.Profile {
+	return &profile.Profile{
+		SampleType:    []*profile.ValueType{{Type: "samples", Unit: "count"}, {Type: "cpu", Unit: "nanoseconds"}},
+		Sample:        samples,
+		DurationNanos: int64(time.Second),
+	}
+}
+
+// newCPUSample 创建 CPU 样本，funcNames 从叶子到根
+func newCPUSample(nanos int64, funcNames ...string) *profile.Sample {
+	sample := newStackSample(0, funcNames...)
+	sample.Value = []int64{1, nanos}
+	return sample
+}
+
+func TestParseBenchmarkOutput(t *testing.T) {
+	output := `goos: linux
+goarch: amd64
+pkg: github.com/myapp/codec
+BenchmarkEncode-8   	 1000000	      1234 ns/op	      48 B/op	       2 allocs/op
+BenchmarkEncode-8   	 1200000	      1200.5 ns/op	      48 B/op	       2 allocs/op
+BenchmarkDecode     	     500	   2400000 ns/op
+PASS
+ok  	github.com/myapp/codec	3.2s
+`
+	results, err := ParseBenchmarkOutput(strings.NewReader(output))
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	assert.Equal(t, BenchmarkResult{Name: "BenchmarkEncode", N: 1000000, NsPerOp: 1234, BytesPerOp: 48, AllocsPerOp: 2}, results[0])
+	assert.Equal(t, 1200.5, results[1].NsPerOp)
+	assert.Equal(t, BenchmarkResult{Name: "BenchmarkDecode", N: 500, NsPerOp: 2400000}, results[2])
+}
+
+func TestBenchmarkIterations(t *testing.T) {
+	n, err := BenchmarkIterations([]BenchmarkResult{{Name: "BenchmarkEncode", N: 1000}, {Name: "BenchmarkEncode", N: 2000}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3000), n)
+
+	_, err = BenchmarkIterations([]BenchmarkResult{{Name: "BenchmarkEncode", N: 1000}, {Name: "BenchmarkDecode", N: 10}})
+	assert.ErrorContains(t, err, "multiple benchmarks")
+
+	_, err = BenchmarkIterations(nil)
+	assert.Error(t, err)
+}
+
+func TestIsBenchHarnessFrame(t *testing.T) {
+	assert.True(t, IsBenchHarnessFrame("testing.(*B).runN"))
+	assert.True(t, IsBenchHarnessFrame("testing.tRunner"))
+	assert.True(t, IsBenchHarnessFrame("runtime.goexit"))
+	assert.False(t, IsBenchHarnessFrame("testing/iotest.OneByteReader"))
+	assert.False(t, IsBenchHarnessFrame("runtime.mallocgc"))
+	assert.False(t, IsBenchHarnessFrame("github.com/myapp/codec.BenchmarkEncode"))
+}
+
+func TestFilterBenchHarness(t *testing.T) {
+	bench := append([]string{"runtime.mallocgc", "github.com/myapp/codec.Encode", "github.com/myapp/codec.BenchmarkEncode"}, benchRoot...)
+	harness := append([]string{"runtime.mallocgc", "testing.(*B).ReportAllocs"}, benchRoot...)
+	gc := []string{"runtime.gcDrain", "runtime.gcBgMarkWorker", "runtime.goexit"}
+
+	p := newBenchCPUProfile(
+		newCPUSample(800, bench...),
+		newCPUSample(150, harness...),
+		newCPUSample(50, gc...),
+		newCPUSample(10, "runtime.goexit"),
+	)
+
+	filtered, dropped := FilterBenchHarness(p)
+	require.NotNil(t, filtered)
+	assert.Equal(t, int64(2), dropped)
+	require.Len(t, filtered.Sample, 2)
+
+	// 根部的框架帧被去掉，调用链从基准测试函数开始
+	assert.Equal(t, []string{"runtime.mallocgc", "github.com/myapp/codec.Encode", "github.com/myapp/codec.BenchmarkEncode"}, sampleFunctions(filtered.Sample[0]))
+	// GC 后台标记保留，只去掉 runtime.goexit
+	assert.Equal(t, []string{"runtime.gcDrain", "runtime.gcBgMarkWorker"}, sampleFunctions(filtered.Sample[1]))
+
+	// 原 profile 不变
+	assert.Len(t, p.Sample, 4)
+	assert.Len(t, p.Sample[0].Location, len(bench))
+
+	filtered, dropped = FilterBenchHarness(nil)
+	assert.Nil(t, filtered)
+	assert.Zero(t, dropped)
+}
+
+func TestApplyBenchMode(t *testing.T) {
+	bench := append([]string{"github.com/myapp/codec.Encode", "github.com/myapp/codec.BenchmarkEncode"}, benchRoot...)
+	harness := append([]string{"runtime.mallocgc", "testing.(*B).StopTimer"}, benchRoot...)
+
+	cpu := newBenchCPUProfile(newCPUSample(int64(4*time.Millisecond), bench...), newCPUSample(int64(time.Millisecond), harness...))
+	heap := newHeapProfile(
+		newAllocSample(2000, 96000, append([]string{"runtime.mallocgc"}, bench...)...),
+		newAllocSample(10, 5000, harness...),
+	)
+	groups := []ProfileGroup{
+		{Type: "cpu", Files: []ProfileFile{{Path: "cpu.out", Profile: cpu, Metrics: ExtractMetrics(cpu, "cpu")}}},
+		{Type: "heap", Files: []ProfileFile{{Path: "mem.out", Profile: heap, Metrics: ExtractMetrics(heap, "heap")}}},
+	}
+
+	ApplyBenchMode(groups, 1000)
+
+	cpuMetrics := groups[0].Files[0].Metrics
+	assert.Equal(t, 4*time.Millisecond, cpuMetrics.CPUTime)
+	require.NotNil(t, cpuMetrics.Bench)
+	assert.Equal(t, BenchStats{N: 1000, HarnessSamples: 1, NsPerOp: 4000}, *cpuMetrics.Bench)
+
+	heapMetrics := groups[1].Files[0].Metrics
+	require.NotNil(t, heapMetrics.Bench)
+	assert.Equal(t, BenchStats{N: 1000, HarnessSamples: 1, BytesPerOp: 96, AllocsPerOp: 2}, *heapMetrics.Bench)
+	assert.Equal(t, int64(96000), heapMetrics.AllocSpace)
+
+	// 迭代次数未知时只过滤，不换算
+	groups[0].Files[0].Profile = cpu
+	ApplyBenchMode(groups[:1], 0)
+	assert.Equal(t, BenchStats{HarnessSamples: 1}, *groups[0].Files[0].Metrics.Bench)
+}
@@ -0,0 +1,48 @@
+package analyzer
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/pprof/profile"
+)
+
+// blockSampleIndices 返回 block profile 中 contentions 和 delay 的 sample 索引，缺少时为 -1
+func blockSampleIndices(p *profile.Profile) (contentionsIndex, delayIndex int) {
+	contentionsIndex, delayIndex = -1, -1
+	for i, st := range p.SampleType {
+		switch strings.ToLower(st.Type) {
+		case "contentions":
+			contentionsIndex = i
+		case "delay":
+			delayIndex = i
+		}
+	}
+	return contentionsIndex, delayIndex
+}
+
+// extractBlockMetrics 汇总 block profile 的阻塞次数和阻塞时间 (delay 的单位为纳秒)
+func extractBlockMetrics(p *profile.Profile) (contentions int64, delay time.Duration) {
+	contentionsIndex, delayIndex := blockSampleIndices(p)
+	for _, sample := range p.Sample {
+		if contentionsIndex >= 0 && contentionsIndex < len(sample.Value) {
+			contentions += sample.Value[contentionsIndex]
+		}
+		if delayIndex >= 0 && delayIndex < len(sample.Value) {
+			delay += time.Duration(sample.Value[delayIndex])
+		}
+	}
+	return contentions, delay
+}
+
+// blockValueIndex 返回 block profile 计算 Top 函数使用的 sample 索引，优先使用 delay
+func blockValueIndex(p *profile.Profile) int {
+	contentionsIndex, delayIndex := blockSampleIndices(p)
+	if delayIndex >= 0 {
+		return delayIndex
+	}
+	if contentionsIndex >= 0 {
+		return contentionsIndex
+	}
+	return 0
+}
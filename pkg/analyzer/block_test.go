@@ -0,0 +1,84 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newBlockProfile 创建 block profile，sample 值为 [contentions, delay]
+func newBlockProfile(samples ...*profile.Sample) *profile.Profile {
+	return &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "contentions", Unit: "count"}, {Type: "delay", Unit: "nanoseconds"}},
+		Sample:     samples,
+	}
+}
+
+// newBlockSample 创建 block 样本，funcNames 从叶子到根
+// 与 newStackSample 不同，函数 ID 按名称分配，跨样本保持一致
+func newBlockSample(contentions, delayNanos int64, funcNames ...string) *profile.Sample {
+	locs := make([]*profile.Location, len(funcNames))
+	for i, name := range funcNames {
+		id := blockFuncIDs[name]
+		if id == 0 {
+			id = uint64(len(blockFuncIDs) + 1)
+			blockFuncIDs[name] = id
+		}
+		fn := &profile.Function{ID: id, Name: name}
+		locs[i] = &profile.Location{ID: id, Line: []profile.Line{{Function: fn}}}
+	}
+	return &profile.Sample{Location: locs, Value: []int64{contentions, delayNanos}}
+}
+
+var blockFuncIDs = map[string]uint64{}
+
+func TestExtractBlockMetrics(t *testing.T) {
+	p := newBlockProfile(
+		newBlockSample(3, int64(2*time.Second), "sync.(*Mutex).Lock", "main.handle", "main.main"),
+		newBlockSample(1, int64(500*time.Millisecond), "runtime.chanrecv1", "main.worker"),
+	)
+
+	contentions, delay := extractBlockMetrics(p)
+	assert.Equal(t, int64(4), contentions)
+	assert.Equal(t, 2500*time.Millisecond, delay)
+}
+
+func TestBlockValueIndex(t *testing.T) {
+	assert.Equal(t, 1, blockValueIndex(newBlockProfile()))
+
+	onlyContentions := &profile.Profile{SampleType: []*profile.ValueType{{Type: "contentions", Unit: "count"}}}
+	assert.Equal(t, 0, blockValueIndex(onlyContentions))
+
+	unknown := &profile.Profile{SampleType: []*profile.ValueType{{Type: "samples", Unit: "count"}}}
+	assert.Equal(t, 0, blockValueIndex(unknown))
+}
+
+func TestExtractMetrics_Block(t *testing.T) {
+	p := newBlockProfile(
+		newBlockSample(3, int64(2*time.Second), "sync.(*Mutex).Lock", "main.handle", "main.main"),
+		newBlockSample(10, int64(100*time.Millisecond), "runtime.chanrecv1", "main.worker"),
+	)
+
+	metrics := ExtractMetrics(p, "block")
+	assert.Equal(t, int64(13), metrics.Contentions)
+	assert.Equal(t, 2100*time.Millisecond, metrics.BlockDelay)
+	require.NotEmpty(t, metrics.TopFunctions)
+	// Top 函数按阻塞时间而非阻塞次数排序
+	assert.Contains(t, []string{"sync.(*Mutex).Lock", "main.handle", "main.main"}, metrics.TopFunctions[0].Name)
+	assert.Equal(t, int64(2*time.Second), metrics.TopFunctions[0].Cum)
+	for _, fn := range metrics.TopFunctions {
+		if fn.Name == "runtime.chanrecv1" {
+			assert.Equal(t, int64(100*time.Millisecond), fn.Cum)
+		}
+	}
+}
+
+func TestExtractMetrics_EmptyBlockProfile(t *testing.T) {
+	metrics := ExtractMetrics(newBlockProfile(), "block")
+	assert.Zero(t, metrics.Contentions)
+	assert.Zero(t, metrics.BlockDelay)
+	assert.Empty(t, metrics.TopFunctions)
+}
@@ -0,0 +1,15 @@
+package analyzer
+
+import "context"
+
+// CancelCheckInterval 逐样本循环中每处理多少个样本检查一次 ctx，避免每次迭代都调用 ctx.Err()
+const CancelCheckInterval = 1024
+
+// CheckCanceled 供逐样本循环调用：第 i 次迭代是 CancelCheckInterval 的整数倍时返回 ctx.Err()，
+// 其余迭代直接返回 nil
+func CheckCanceled(ctx context.Context, i int) error {
+	if i%CancelCheckInterval != 0 {
+		return nil
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,103 @@
+package analyzer
+
+import "github.com/google/pprof/profile"
+
+// PackageClassifier 将包路径映射为代码分类 (如 business、third_party、stdlib、runtime)
+// analyzer 不依赖 locator，分类规则由调用方注入
+type PackageClassifier func(packageName string) string
+
+// ComputeCategoryTotals 按代码分类汇总每个文件的样本值，结果写入 ProfileMetrics.CategoryTotals
+// 样本归属于栈顶函数所在包的分类；heap 使用 heapSampleType 指定的 sample type，
+// 与报告展示的趋势一致，heap 文件同时填充按包汇总的 ProfileMetrics.PackageMemory；
+// classify 为 nil 时不做任何处理
+func ComputeCategoryTotals(groups []ProfileGroup, classify PackageClassifier, heapSampleType string) {
+	ComputeCategoryTotalsWithConcurrency(groups, classify, heapSampleType, 1)
+}
+
+// ComputeCategoryTotalsWithConcurrency 使用最多 concurrency 个 goroutine 并行汇总各文件的分类样本值
+// classify 会被并发调用，必须是并发安全的
+func ComputeCategoryTotalsWithConcurrency(groups []ProfileGroup, classify PackageClassifier, heapSampleType string, concurrency int) {
+	if classify == nil {
+		return
+	}
+
+	type job struct {
+		profileType string
+		file        ProfileFile
+	}
+	var jobs []job
+	for _, group := range groups {
+		for _, file := range group.Files {
+			if file.Profile == nil || file.Metrics == nil {
+				continue
+			}
+			jobs = append(jobs, job{profileType: group.Type, file: file})
+		}
+	}
+
+	// 每个任务只写入自己文件的 Metrics
+	ParallelFor(len(jobs), concurrency, func(i int) {
+		j := jobs[i]
+		index := categorySampleIndex(j.file.Profile, j.profileType, heapSampleType)
+		if custom := valueTypeIndex(j.file.Metrics, j.file.Profile); custom >= 0 {
+			index = custom
+		}
+		j.file.Metrics.CategoryTotals = extractCategoryTotals(j.file.Profile, index, classify)
+		if j.profileType == "heap" {
+			j.file.Metrics.PackageMemory = PackageMemoryBreakdown(j.file.Metrics, classify, DefaultPackageMemoryTopN)
+		}
+	})
+}
+
+// extractCategoryTotals 按栈顶函数的分类汇总指定 sample index 的值
+func extractCategoryTotals(p *profile.Profile, valueIndex int, classify PackageClassifier) map[string]int64 {
+	if valueIndex < 0 {
+		return nil
+	}
+
+	// 同一包的分类只计算一次
+	categoryOf := make(map[string]string)
+	totals := make(map[string]int64)
+	for _, sample := range p.Sample {
+		if len(sample.Value) <= valueIndex {
+			continue
+		}
+		pkg := samplePackage(sample)
+		category, ok := categoryOf[pkg]
+		if !ok {
+			category = classify(pkg)
+			categoryOf[pkg] = category
+		}
+		totals[category] += sample.Value[valueIndex]
+	}
+	if len(totals) == 0 {
+		return nil
+	}
+	return totals
+}
+
+// categorySampleIndex 返回分类汇总使用的 sample index，找不到时返回 -1
+func categorySampleIndex(p *profile.Profile, profileType, heapSampleType string) int {
+	switch profileType {
+	case "cpu", "block":
+		for i, st := range p.SampleType {
+			if st.Type == "cpu" || st.Unit == "nanoseconds" {
+				return i
+			}
+		}
+	case "heap":
+		if heapSampleType == "" {
+			heapSampleType = HeapSampleInuseSpace
+		}
+		for i, st := range p.SampleType {
+			if st.Type == heapSampleType {
+				return i
+			}
+		}
+		return -1
+	}
+	if len(p.SampleType) == 0 {
+		return -1
+	}
+	return 0
+}
@@ -0,0 +1,109 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testClassifier 按包路径前缀分类的测试分类器
+func testClassifier(packageName string) string {
+	switch {
+	case packageName == "runtime":
+		return "runtime"
+	case strings.HasPrefix(packageName, "github.com/myapp"):
+		return "business"
+	case strings.Contains(packageName, "."):
+		return "third_party"
+	default:
+		return "stdlib"
+	}
+}
+
+// newInuseSample 创建 heap 样本，inuse_space 为 inuse，funcNames 从栈顶到栈底排列
+func newInuseSample(inuse int64, funcNames ...string) *profile.Sample {
+	sample := newStackSample(inuse*2, funcNames...)
+	sample.Value[3] = inuse
+	return sample
+}
+
+func TestComputeCategoryTotals_Heap(t *testing.T) {
+	p := newHeapProfile(
+		newInuseSample(100, "github.com/myapp/cache.(*LRU).Add", "main.main"),
+		newInuseSample(50, "github.com/redis/go-redis.(*Conn).Read", "github.com/myapp/store.Get"),
+		newInuseSample(30, "encoding/json.Marshal", "github.com/myapp/api.Handle"),
+		newInuseSample(20, "runtime.malg"),
+		newInuseSample(25, "github.com/myapp/cache.(*LRU).Add", "main.main"),
+	)
+	groups := []ProfileGroup{{Type: "heap", Files: []ProfileFile{{Profile: p, Metrics: &ProfileMetrics{}}}}}
+
+	ComputeCategoryTotals(groups, testClassifier, HeapSampleInuseSpace)
+	assert.Equal(t, map[string]int64{
+		"business": 125, "third_party": 50, "stdlib": 30, "runtime": 20,
+	}, groups[0].Files[0].Metrics.CategoryTotals)
+
+	// 选择 alloc_space 时按 alloc_space 汇总
+	ComputeCategoryTotals(groups, testClassifier, HeapSampleAllocSpace)
+	assert.Equal(t, int64(250), groups[0].Files[0].Metrics.CategoryTotals["business"])
+}
+
+func TestComputeCategoryTotals_CPU(t *testing.T) {
+	leaf := func(name string) []*profile.Location {
+		return []*profile.Location{{ID: 1, Line: []profile.Line{{Function: &profile.Function{ID: 1, Name: name}}}}}
+	}
+	p := &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "samples", Unit: "count"}, {Type: "cpu", Unit: "nanoseconds"}},
+		Sample: []*profile.Sample{
+			{Location: leaf("github.com/myapp/api.Handle"), Value: []int64{1, 3e6}},
+			{Location: leaf("runtime.scanobject"), Value: []int64{1, 1e6}},
+		},
+	}
+	groups := []ProfileGroup{{Type: "cpu", Files: []ProfileFile{{Profile: p, Metrics: &ProfileMetrics{}}}}}
+
+	ComputeCategoryTotals(groups, testClassifier, "")
+	assert.Equal(t, map[string]int64{"business": 3e6, "runtime": 1e6}, groups[0].Files[0].Metrics.CategoryTotals)
+}
+
+func TestComputeCategoryTotals_Skips(t *testing.T) {
+	// 缺少 sample type、没有 profile 或没有分类器时不计算
+	p := &profile.Profile{SampleType: []*profile.ValueType{{Type: "alloc_space", Unit: "bytes"}}, Sample: []*profile.Sample{{Value: []int64{1}}}}
+	groups := []ProfileGroup{{Type: "heap", Files: []ProfileFile{
+		{Profile: p, Metrics: &ProfileMetrics{}},
+		{Metrics: &ProfileMetrics{}},
+	}}}
+
+	ComputeCategoryTotals(groups, testClassifier, HeapSampleInuseSpace)
+	assert.Nil(t, groups[0].Files[0].Metrics.CategoryTotals)
+	assert.Nil(t, groups[0].Files[1].Metrics.CategoryTotals)
+
+	inuse := newHeapProfile(newInuseSample(10, "main.main"))
+	groups[0].Files[0].Profile = inuse
+	ComputeCategoryTotals(groups, nil, HeapSampleInuseSpace)
+	assert.Nil(t, groups[0].Files[0].Metrics.CategoryTotals)
+
+	ComputeCategoryTotals(groups, testClassifier, HeapSampleInuseSpace)
+	require.NotNil(t, groups[0].Files[0].Metrics.CategoryTotals)
+	assert.Equal(t, int64(10), groups[0].Files[0].Metrics.CategoryTotals["stdlib"])
+}
+
+func TestComputeCategoryTotalsWithConcurrency(t *testing.T) {
+	var files []ProfileFile
+	for i := 1; i <= 10; i++ {
+		p := newHeapProfile(
+			newInuseSample(int64(i*10), "github.com/myapp/cache.(*LRU).Add", "main.main"),
+			newInuseSample(int64(i), "runtime.malg"),
+		)
+		files = append(files, ProfileFile{Profile: p, Metrics: &ProfileMetrics{}})
+	}
+	groups := []ProfileGroup{{Type: "heap", Files: files}}
+
+	ComputeCategoryTotalsWithConcurrency(groups, testClassifier, HeapSampleInuseSpace, 4)
+	for i, file := range groups[0].Files {
+		require.NotNil(t, file.Metrics.CategoryTotals)
+		assert.Equal(t, int64((i+1)*10), file.Metrics.CategoryTotals["business"])
+		assert.Equal(t, int64(i+1), file.Metrics.CategoryTotals["runtime"])
+	}
+}
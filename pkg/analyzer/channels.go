@@ -0,0 +1,216 @@
+package analyzer
+
+import (
+	"sort"
+
+	"github.com/google/pprof/profile"
+)
+
+// DefaultChannelBacklogMinBlocked 最新快照中阻塞在 channel 发送上的 goroutine 达到 10 个才视为积压
+const DefaultChannelBacklogMinBlocked = 10
+
+// channelOps runtime 中 channel 阻塞函数对应的操作
+var channelOps = map[string]string{
+	"runtime.chansend":  "send",
+	"runtime.chansend1": "send",
+	"runtime.chanrecv":  "recv",
+	"runtime.chanrecv1": "recv",
+	"runtime.chanrecv2": "recv",
+	"runtime.selectgo":  "select",
+}
+
+// ChannelBlockSite 阻塞在 channel 操作上的 goroutine（按操作和调用点统计）
+type ChannelBlockSite struct {
+	Op     string // send、recv 或 select
+	Caller string // 执行 channel 操作的调用点（优先取第一个非标准库帧）
+	Count  int64  // goroutine 数量
+}
+
+// ChannelAllocation 由业务调用点创建的 channel 占用的内存 (runtime.makechan)
+type ChannelAllocation struct {
+	Caller     string // 创建 channel 的调用点
+	InuseSpace int64  // 仍在使用的字节数
+	AllocSpace int64  // 累计分配字节数
+	Growth     int64  // 相对最早快照的 InuseSpace 增量，仅 DetectChannelBacklog 填充
+}
+
+// ChannelBacklog goroutine 阻塞在 channel 发送上、同时 channel 内存持续增长的积压信号
+type ChannelBacklog struct {
+	BlockedSends       int64               // 最新快照中阻塞在发送上的 goroutine 数
+	BlockedSendsGrowth int64               // 相对最早快照的增量
+	SendSites          []ChannelBlockSite  // 最新快照的发送阻塞点，按数量降序
+	ChannelInuseGrowth int64               // channel 占用内存相对最早快照的增量
+	ChannelSites       []ChannelAllocation // 内存增长的 channel 创建点，按增量降序
+}
+
+// ChannelOp 返回 runtime 函数对应的 channel 操作，不是 channel 阻塞函数时返回空字符串
+func ChannelOp(funcName string) string {
+	return channelOps[funcName]
+}
+
+// BlockedOn 返回阻塞在指定 channel 操作上的 goroutine 总数
+func BlockedOn(sites []ChannelBlockSite, op string) int64 {
+	var count int64
+	for _, site := range sites {
+		if site.Op == op {
+			count += site.Count
+		}
+	}
+	return count
+}
+
+// ChannelInuse 返回 channel 占用的内存总量
+func ChannelInuse(allocs []ChannelAllocation) int64 {
+	var total int64
+	for _, a := range allocs {
+		total += a.InuseSpace
+	}
+	return total
+}
+
+// DetectChannelBacklog 关联 goroutine 和 heap 快照，识别 channel 积压
+// 阻塞在 chan send 上的 goroutine 持续增加，同时 runtime.makechan 的存活内存增长，
+// 说明消费者跟不上生产者，或者不断有新 channel 被创建后无人接收。
+// 两组快照各需至少两个，条件不满足时返回 nil
+func DetectChannelBacklog(goroutineFiles, heapFiles []ProfileFile, minBlocked int64) *ChannelBacklog {
+	if len(goroutineFiles) < 2 || len(heapFiles) < 2 {
+		return nil
+	}
+	firstG, latestG := goroutineFiles[0].Metrics, goroutineFiles[len(goroutineFiles)-1].Metrics
+	firstH, latestH := heapFiles[0].Metrics, heapFiles[len(heapFiles)-1].Metrics
+	if firstG == nil || latestG == nil || firstH == nil || latestH == nil {
+		return nil
+	}
+
+	blocked := BlockedOn(latestG.ChannelBlocks, "send")
+	blockedGrowth := blocked - BlockedOn(firstG.ChannelBlocks, "send")
+	if blocked < minBlocked || blockedGrowth <= 0 {
+		return nil
+	}
+
+	inuseGrowth := ChannelInuse(latestH.ChannelAllocations) - ChannelInuse(firstH.ChannelAllocations)
+	if inuseGrowth <= 0 {
+		return nil
+	}
+
+	backlog := &ChannelBacklog{
+		BlockedSends:       blocked,
+		BlockedSendsGrowth: blockedGrowth,
+		ChannelInuseGrowth: inuseGrowth,
+	}
+	for _, site := range latestG.ChannelBlocks {
+		if site.Op == "send" {
+			backlog.SendSites = append(backlog.SendSites, site)
+		}
+	}
+
+	before := make(map[string]int64)
+	for _, a := range firstH.ChannelAllocations {
+		before[a.Caller] += a.InuseSpace
+	}
+	for _, a := range latestH.ChannelAllocations {
+		a.Growth = a.InuseSpace - before[a.Caller]
+		if a.Growth > 0 {
+			backlog.ChannelSites = append(backlog.ChannelSites, a)
+		}
+	}
+	sort.SliceStable(backlog.ChannelSites, func(i, j int) bool {
+		return backlog.ChannelSites[i].Growth > backlog.ChannelSites[j].Growth
+	})
+	return backlog
+}
+
+// extractChannelBlocks 按操作和调用点汇总阻塞在 channel 上的 goroutine
+// 结果按数量降序排列
+func extractChannelBlocks(p *profile.Profile) []ChannelBlockSite {
+	bySite := make(map[[2]string]*ChannelBlockSite)
+	for _, sample := range p.Sample {
+		if len(sample.Value) == 0 {
+			continue
+		}
+		frames := sampleFunctions(sample)
+		for i, name := range frames {
+			op := ChannelOp(name)
+			if op == "" {
+				continue
+			}
+			key := [2]string{op, conversionCaller(frames[i+1:])}
+			entry, ok := bySite[key]
+			if !ok {
+				entry = &ChannelBlockSite{Op: key[0], Caller: key[1]}
+				bySite[key] = entry
+			}
+			entry.Count += sample.Value[0]
+			break
+		}
+	}
+	if len(bySite) == 0 {
+		return nil
+	}
+
+	result := make([]ChannelBlockSite, 0, len(bySite))
+	for _, entry := range bySite {
+		result = append(result, *entry)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		if result[i].Caller != result[j].Caller {
+			return result[i].Caller < result[j].Caller
+		}
+		return result[i].Op < result[j].Op
+	})
+	return result
+}
+
+// extractChannelAllocations 按调用点汇总 runtime.makechan 的 inuse_space 和 alloc_space
+// 结果按存活字节数降序排列
+func extractChannelAllocations(p *profile.Profile) []ChannelAllocation {
+	allocIndex, inuseIndex := -1, -1
+	for i, st := range p.SampleType {
+		switch st.Type {
+		case "alloc_space":
+			allocIndex = i
+		case "inuse_space":
+			inuseIndex = i
+		}
+	}
+	if allocIndex < 0 || inuseIndex < 0 {
+		return nil
+	}
+
+	byCaller := make(map[string]*ChannelAllocation)
+	for _, sample := range p.Sample {
+		if len(sample.Value) <= allocIndex || len(sample.Value) <= inuseIndex {
+			continue
+		}
+		frames := sampleFunctions(sample)
+		if len(frames) == 0 || frames[0] != "runtime.makechan" {
+			continue
+		}
+		caller := conversionCaller(frames[1:])
+		entry, ok := byCaller[caller]
+		if !ok {
+			entry = &ChannelAllocation{Caller: caller}
+			byCaller[caller] = entry
+		}
+		entry.AllocSpace += sample.Value[allocIndex]
+		entry.InuseSpace += sample.Value[inuseIndex]
+	}
+	if len(byCaller) == 0 {
+		return nil
+	}
+
+	result := make([]ChannelAllocation, 0, len(byCaller))
+	for _, entry := range byCaller {
+		result = append(result, *entry)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].InuseSpace != result[j].InuseSpace {
+			return result[i].InuseSpace > result[j].InuseSpace
+		}
+		return result[i].Caller < result[j].Caller
+	})
+	return result
+}
@@ -0,0 +1,131 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newGoroutineSample 创建 goroutine 样本，funcNames 从栈顶到栈底排列
+func newGoroutineSample(count int64, funcNames ...string) *profile.Sample {
+	sample := newStackSample(0, funcNames...)
+	sample.Value = []int64{count}
+	return sample
+}
+
+// newChanSample 创建 runtime.makechan 的 heap 样本
+func newChanSample(allocSpace, inuseSpace int64, caller string) *profile.Sample {
+	sample := newStackSample(allocSpace, "runtime.makechan", caller, "main.main")
+	sample.Value[3] = inuseSpace
+	return sample
+}
+
+func TestChannelOp(t *testing.T) {
+	assert.Equal(t, "send", ChannelOp("runtime.chansend1"))
+	assert.Equal(t, "recv", ChannelOp("runtime.chanrecv2"))
+	assert.Equal(t, "select", ChannelOp("runtime.selectgo"))
+	assert.Equal(t, "", ChannelOp("runtime.gopark"))
+}
+
+func TestExtractChannelBlocks(t *testing.T) {
+	p := &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "goroutines", Unit: "count"}},
+		Sample: []*profile.Sample{
+			newGoroutineSample(30, "runtime.gopark", "runtime.chansend", "runtime.chansend1", "github.com/myapp/queue.(*Producer).Push", "main.main"),
+			newGoroutineSample(20, "runtime.gopark", "runtime.chansend", "runtime.chansend1", "github.com/myapp/queue.(*Producer).Push", "main.worker"),
+			newGoroutineSample(5, "runtime.gopark", "runtime.chanrecv", "runtime.chanrecv2", "github.com/myapp/queue.(*Consumer).Run"),
+			newGoroutineSample(1, "runtime.gopark", "runtime.netpollblock", "net.(*conn).Read"),
+		},
+	}
+
+	blocks := extractChannelBlocks(p)
+	require.Len(t, blocks, 2)
+	assert.Equal(t, ChannelBlockSite{Op: "send", Caller: "github.com/myapp/queue.(*Producer).Push", Count: 50}, blocks[0])
+	assert.Equal(t, ChannelBlockSite{Op: "recv", Caller: "github.com/myapp/queue.(*Consumer).Run", Count: 5}, blocks[1])
+	assert.Equal(t, int64(50), BlockedOn(blocks, "send"))
+	assert.Equal(t, int64(0), BlockedOn(blocks, "select"))
+
+	metrics := ExtractMetrics(p, "goroutine")
+	require.NotNil(t, metrics)
+	assert.Len(t, metrics.ChannelBlocks, 2)
+}
+
+func TestExtractChannelAllocations(t *testing.T) {
+	p := newHeapProfile(
+		newChanSample(400, 300, "github.com/myapp/queue.New"),
+		newChanSample(100, 100, "github.com/myapp/queue.New"),
+		newChanSample(50, 0, "github.com/myapp/rpc.(*Client).Call"),
+		newStackSample(1000, "github.com/myapp/cache.New"),
+	)
+
+	allocs := extractChannelAllocations(p)
+	require.Len(t, allocs, 2)
+	assert.Equal(t, ChannelAllocation{Caller: "github.com/myapp/queue.New", InuseSpace: 400, AllocSpace: 500}, allocs[0])
+	assert.Equal(t, "github.com/myapp/rpc.(*Client).Call", allocs[1].Caller)
+	assert.Equal(t, int64(400), ChannelInuse(allocs))
+
+	assert.Len(t, ExtractMetrics(p, "heap").ChannelAllocations, 2)
+}
+
+func TestDetectChannelBacklog(t *testing.T) {
+	goroutineFile := func(sends int64) ProfileFile {
+		return ProfileFile{Metrics: &ProfileMetrics{ChannelBlocks: []ChannelBlockSite{
+			{Op: "send", Caller: "main.produce", Count: sends},
+			{Op: "recv", Caller: "main.consume", Count: 100},
+		}}}
+	}
+	heapFile := func(queue, rpc int64) ProfileFile {
+		return ProfileFile{Metrics: &ProfileMetrics{ChannelAllocations: []ChannelAllocation{
+			{Caller: "main.newQueue", InuseSpace: queue},
+			{Caller: "main.call", InuseSpace: rpc},
+		}}}
+	}
+
+	t.Run("blocked sends with growing channels", func(t *testing.T) {
+		backlog := DetectChannelBacklog(
+			[]ProfileFile{goroutineFile(5), goroutineFile(40)},
+			[]ProfileFile{heapFile(1000, 500), heapFile(5000, 400)},
+			DefaultChannelBacklogMinBlocked,
+		)
+		require.NotNil(t, backlog)
+		assert.Equal(t, int64(40), backlog.BlockedSends)
+		assert.Equal(t, int64(35), backlog.BlockedSendsGrowth)
+		assert.Equal(t, int64(3900), backlog.ChannelInuseGrowth)
+		require.Len(t, backlog.SendSites, 1)
+		assert.Equal(t, "main.produce", backlog.SendSites[0].Caller)
+		// 只列出内存增长的创建点
+		require.Len(t, backlog.ChannelSites, 1)
+		assert.Equal(t, "main.newQueue", backlog.ChannelSites[0].Caller)
+		assert.Equal(t, int64(4000), backlog.ChannelSites[0].Growth)
+	})
+
+	t.Run("too few blocked sends", func(t *testing.T) {
+		assert.Nil(t, DetectChannelBacklog(
+			[]ProfileFile{goroutineFile(1), goroutineFile(5)},
+			[]ProfileFile{heapFile(1000, 0), heapFile(5000, 0)},
+			DefaultChannelBacklogMinBlocked,
+		))
+	})
+
+	t.Run("blocked sends not growing", func(t *testing.T) {
+		assert.Nil(t, DetectChannelBacklog(
+			[]ProfileFile{goroutineFile(40), goroutineFile(40)},
+			[]ProfileFile{heapFile(1000, 0), heapFile(5000, 0)},
+			DefaultChannelBacklogMinBlocked,
+		))
+	})
+
+	t.Run("channel memory not growing", func(t *testing.T) {
+		assert.Nil(t, DetectChannelBacklog(
+			[]ProfileFile{goroutineFile(5), goroutineFile(40)},
+			[]ProfileFile{heapFile(1000, 500), heapFile(1000, 500)},
+			DefaultChannelBacklogMinBlocked,
+		))
+	})
+
+	t.Run("single snapshot", func(t *testing.T) {
+		assert.Nil(t, DetectChannelBacklog([]ProfileFile{goroutineFile(40)}, []ProfileFile{heapFile(1, 0)}, 1))
+	})
+}
@@ -0,0 +1,118 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// SkewInversion 文件名序号相邻的两个文件，序号较大的文件采集时间反而更早
+type SkewInversion struct {
+	Earlier     string    // 序号较小的文件路径
+	EarlierTime time.Time // 序号较小的文件的采集时间
+	Later       string    // 序号较大的文件路径
+	LaterTime   time.Time // 序号较大的文件的采集时间
+}
+
+// ClockSkew 一个 profile 组中文件名序号与采集时间顺序不一致的情况
+type ClockSkew struct {
+	Type       string          // profile 类型
+	Inversions []SkewInversion // 按文件名序号排列的逆序对
+}
+
+// FileSequence 返回文件名中最后一段数字作为序号，如 heap.003.pprof 返回 3
+// 文件名中没有数字或数字超出 int64 范围时返回 false
+func FileSequence(path string) (int64, bool) {
+	name := filepath.Base(path)
+	end := -1
+	for i := len(name) - 1; i >= 0; i-- {
+		if name[i] >= '0' && name[i] <= '9' {
+			end = i + 1
+			break
+		}
+	}
+	if end < 0 {
+		return 0, false
+	}
+	start := end
+	for start > 0 && name[start-1] >= '0' && name[start-1] <= '9' {
+		start--
+	}
+	seq, err := strconv.ParseInt(name[start:end], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return seq, true
+}
+
+// DetectClockSkew 检查每个组中采集时间是否随文件名序号单调递增
+// 来自多台主机的 profile 可能存在时钟偏差，按时间排序会得到颠倒或无意义的趋势。
+// 只检查所有文件都带有互不相同的序号的组，结果按组的顺序排列
+func DetectClockSkew(groups []ProfileGroup) []ClockSkew {
+	var skews []ClockSkew
+	for _, group := range groups {
+		files, ok := filesBySequence(group.Files)
+		if !ok {
+			continue
+		}
+		skew := ClockSkew{Type: group.Type}
+		for i := 1; i < len(files); i++ {
+			prev, cur := files[i-1], files[i]
+			if cur.Time.Before(prev.Time) {
+				skew.Inversions = append(skew.Inversions, SkewInversion{
+					Earlier:     prev.Path,
+					EarlierTime: prev.Time,
+					Later:       cur.Path,
+					LaterTime:   cur.Time,
+				})
+			}
+		}
+		if len(skew.Inversions) > 0 {
+			skews = append(skews, skew)
+		}
+	}
+	return skews
+}
+
+// OrderByFilename 将每个组的文件改为按文件名序号排序，用于时钟不可信的场景
+// 文件名没有完整序号的组保持按时间排序，返回这些组的类型
+func OrderByFilename(groups []ProfileGroup) []string {
+	var unordered []string
+	for i := range groups {
+		files, ok := filesBySequence(groups[i].Files)
+		if !ok {
+			if len(groups[i].Files) > 1 {
+				unordered = append(unordered, groups[i].Type)
+			}
+			continue
+		}
+		groups[i].Files = files
+	}
+	return unordered
+}
+
+// filesBySequence 返回按文件名序号排序的文件副本
+// 有文件缺少序号或序号重复时返回 false
+func filesBySequence(files []ProfileFile) ([]ProfileFile, bool) {
+	if len(files) < 2 {
+		return nil, false
+	}
+	seqs := make(map[string]int64, len(files))
+	seen := make(map[int64]bool, len(files))
+	for _, file := range files {
+		seq, ok := FileSequence(file.Path)
+		if !ok || seen[seq] {
+			return nil, false
+		}
+		seqs[file.Path] = seq
+		seen[seq] = true
+	}
+
+	sorted := make([]ProfileFile, len(files))
+	copy(sorted, files)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return seqs[sorted[i].Path] < seqs[sorted[j].Path]
+	})
+	return sorted, true
+}
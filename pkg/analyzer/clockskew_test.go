@@ -0,0 +1,95 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSkewGroup 创建一个按采集时间排序的组，minutes 为各文件相对 10:00 的采集时间
+func newSkewGroup(profileType string, paths []string, minutes []int) ProfileGroup {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	group := ProfileGroup{Type: profileType}
+	for i, path := range paths {
+		group.Files = append(group.Files, ProfileFile{
+			Path:    path,
+			Time:    start.Add(time.Duration(minutes[i]) * time.Minute),
+			Metrics: &ProfileMetrics{},
+		})
+	}
+	return group
+}
+
+func TestFileSequence(t *testing.T) {
+	tests := []struct {
+		path string
+		seq  int64
+		ok   bool
+	}{
+		{"profiles/heap.003.pprof", 3, true},
+		{"/tmp/pprof.app.samples.cpu.012.pb.gz", 12, true},
+		{"host-a/goroutine-20240101-103000.pprof", 103000, true},
+		{"heap10.pprof", 10, true},
+		{"dir1/heap.pprof", 0, false},
+		{"heap.pprof", 0, false},
+		{"heap.99999999999999999999.pprof", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			seq, ok := FileSequence(tt.path)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.seq, seq)
+		})
+	}
+}
+
+func TestDetectClockSkew(t *testing.T) {
+	t.Run("times follow filename order", func(t *testing.T) {
+		group := newSkewGroup("heap", []string{"heap.1.pprof", "heap.2.pprof", "heap.3.pprof"}, []int{0, 1, 2})
+		assert.Empty(t, DetectClockSkew([]ProfileGroup{group}))
+	})
+
+	t.Run("skewed host inverts order", func(t *testing.T) {
+		// heap.3 来自时钟慢 5 分钟的主机，按时间排在最前
+		group := newSkewGroup("heap", []string{"heap.3.pprof", "heap.1.pprof", "heap.2.pprof"}, []int{-3, 0, 1})
+		skews := DetectClockSkew([]ProfileGroup{group})
+		require.Len(t, skews, 1)
+		assert.Equal(t, "heap", skews[0].Type)
+		require.Len(t, skews[0].Inversions, 1)
+		inv := skews[0].Inversions[0]
+		assert.Equal(t, "heap.2.pprof", inv.Earlier)
+		assert.Equal(t, "heap.3.pprof", inv.Later)
+		assert.True(t, inv.LaterTime.Before(inv.EarlierTime))
+	})
+
+	t.Run("equal times are not skew", func(t *testing.T) {
+		group := newSkewGroup("heap", []string{"heap.1.pprof", "heap.2.pprof"}, []int{0, 0})
+		assert.Empty(t, DetectClockSkew([]ProfileGroup{group}))
+	})
+
+	t.Run("groups without complete sequences are skipped", func(t *testing.T) {
+		missing := newSkewGroup("heap", []string{"heap.2.pprof", "heap.pprof"}, []int{0, 1})
+		duplicate := newSkewGroup("cpu", []string{"a/cpu.1.pprof", "b/cpu.1.pprof"}, []int{0, 1})
+		single := newSkewGroup("goroutine", []string{"goroutine.1.pprof"}, []int{0})
+		assert.Empty(t, DetectClockSkew([]ProfileGroup{missing, duplicate, single}))
+	})
+}
+
+func TestOrderByFilename(t *testing.T) {
+	skewed := newSkewGroup("heap", []string{"heap.3.pprof", "heap.1.pprof", "heap.2.pprof"}, []int{-3, 0, 1})
+	unnumbered := newSkewGroup("cpu", []string{"cpu-b.pprof", "cpu-a.pprof"}, []int{0, 1})
+	single := newSkewGroup("goroutine", []string{"goroutine.pprof"}, []int{0})
+	groups := []ProfileGroup{unnumbered, single, skewed}
+
+	unordered := OrderByFilename(groups)
+	assert.Equal(t, []string{"cpu"}, unordered)
+
+	var paths []string
+	for _, file := range groups[2].Files {
+		paths = append(paths, file.Path)
+	}
+	assert.Equal(t, []string{"heap.1.pprof", "heap.2.pprof", "heap.3.pprof"}, paths)
+	assert.Equal(t, "cpu-b.pprof", groups[0].Files[0].Path, "unnumbered group keeps time order")
+}
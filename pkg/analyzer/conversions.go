@@ -0,0 +1,156 @@
+package analyzer
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/google/pprof/profile"
+)
+
+// DefaultConversionMinShare 转换分配占累计分配的比例达到 20% 视为热点
+const DefaultConversionMinShare = 0.2
+
+// conversionFuncs string/[]byte 转换在 runtime 中的分配函数
+var conversionFuncs = map[string]bool{
+	"runtime.stringtoslicebyte": true,
+	"runtime.slicebytetostring": true,
+	"runtime.stringtoslicerune": true,
+	"runtime.slicerunetostring": true,
+}
+
+// ConversionHotspot 由业务调用点触发的 string/[]byte 转换分配
+type ConversionHotspot struct {
+	RuntimeFunc string  // 实际分配的 runtime 转换函数
+	Caller      string  // 触发转换的调用点（优先取第一个非标准库帧）
+	AllocSpace  int64   // 累计分配字节数
+	Share       float64 // 占 profile 累计分配的比例
+}
+
+// IsConversionFunc 判断函数是否是 string/[]byte 转换的 runtime 分配函数
+func IsConversionFunc(funcName string) bool {
+	return conversionFuncs[funcName]
+}
+
+// ConversionShare 返回转换分配占累计分配的总比例
+func ConversionShare(hotspots []ConversionHotspot) float64 {
+	var share float64
+	for _, h := range hotspots {
+		share += h.Share
+	}
+	return share
+}
+
+// DetectConversionHotspots 当转换分配在累计分配中占比达到 minShare 时返回各调用点
+// 转换分配本身不会出现在业务函数的 flat 中，这里把它们归到触发转换的调用点上
+func DetectConversionHotspots(metrics *ProfileMetrics, minShare float64) []ConversionHotspot {
+	if metrics == nil || len(metrics.ConversionHotspots) == 0 {
+		return nil
+	}
+	if ConversionShare(metrics.ConversionHotspots) < minShare {
+		return nil
+	}
+	return metrics.ConversionHotspots
+}
+
+// extractConversionHotspots 按调用点汇总 string/[]byte 转换的 alloc_space
+// 结果按分配字节数降序排列
+func extractConversionHotspots(p *profile.Profile) []ConversionHotspot {
+	allocIndex := -1
+	for i, st := range p.SampleType {
+		if st.Type == "alloc_space" {
+			allocIndex = i
+		}
+	}
+	if allocIndex < 0 {
+		return nil
+	}
+
+	var total int64
+	byCaller := make(map[[2]string]*ConversionHotspot)
+	for _, sample := range p.Sample {
+		if len(sample.Value) <= allocIndex {
+			continue
+		}
+		value := sample.Value[allocIndex]
+		total += value
+
+		frames := sampleFunctions(sample)
+		if len(frames) == 0 || !IsConversionFunc(frames[0]) {
+			continue
+		}
+
+		key := [2]string{frames[0], conversionCaller(frames[1:])}
+		entry, ok := byCaller[key]
+		if !ok {
+			entry = &ConversionHotspot{RuntimeFunc: key[0], Caller: key[1]}
+			byCaller[key] = entry
+		}
+		entry.AllocSpace += value
+	}
+	if total <= 0 || len(byCaller) == 0 {
+		return nil
+	}
+
+	result := make([]ConversionHotspot, 0, len(byCaller))
+	for _, entry := range byCaller {
+		entry.Share = float64(entry.AllocSpace) / float64(total)
+		result = append(result, *entry)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].AllocSpace != result[j].AllocSpace {
+			return result[i].AllocSpace > result[j].AllocSpace
+		}
+		if result[i].Caller != result[j].Caller {
+			return result[i].Caller < result[j].Caller
+		}
+		return result[i].RuntimeFunc < result[j].RuntimeFunc
+	})
+	return result
+}
+
+// conversionCaller 从转换函数的调用者中找出触发转换的业务帧
+// 跳过 runtime 和标准库帧（如 strings.Builder 内部调用），都不满足时退回第一个非 runtime 帧
+func conversionCaller(callers []string) string {
+	fallback := ""
+	for _, name := range callers {
+		pkg := PackageOf(name)
+		if pkg == "runtime" || strings.HasPrefix(pkg, "runtime/") {
+			continue
+		}
+		if fallback == "" {
+			fallback = name
+		}
+		if !isStdPackage(pkg) {
+			return name
+		}
+	}
+	return fallback
+}
+
+// isStdPackage 根据导入路径判断是否是标准库包：标准库路径的第一段不含点号
+func isStdPackage(pkg string) bool {
+	if pkg == "main" || pkg == "" {
+		return false
+	}
+	first := pkg
+	if i := strings.Index(pkg, "/"); i >= 0 {
+		first = pkg[:i]
+	}
+	return !strings.Contains(first, ".")
+}
+
+// sampleFunctions 返回样本从栈顶到栈底的函数名（包含内联帧）
+func sampleFunctions(sample *profile.Sample) []string {
+	var names []string
+	for _, loc := range sample.Location {
+		if loc == nil {
+			continue
+		}
+		for _, line := range loc.Line {
+			if line.Function != nil {
+				names = append(names, line.Function.Name)
+			}
+		}
+	}
+	return names
+}
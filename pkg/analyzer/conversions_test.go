@@ -0,0 +1,85 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newStackSample 创建 heap 样本，funcNames 从栈顶到栈底排列
+func newStackSample(allocSpace int64, funcNames ...string) *profile.Sample {
+	locs := make([]*profile.Location, len(funcNames))
+	for i, name := range funcNames {
+		fn := &profile.Function{ID: uint64(i + 1), Name: name}
+		locs[i] = &profile.Location{ID: uint64(i + 1), Line: []profile.Line{{Function: fn}}}
+	}
+	return &profile.Sample{Location: locs, Value: []int64{1, allocSpace, 0, 0}}
+}
+
+func TestIsConversionFunc(t *testing.T) {
+	assert.True(t, IsConversionFunc("runtime.stringtoslicebyte"))
+	assert.True(t, IsConversionFunc("runtime.slicebytetostring"))
+	assert.True(t, IsConversionFunc("runtime.slicerunetostring"))
+	assert.False(t, IsConversionFunc("runtime.mallocgc"))
+	assert.False(t, IsConversionFunc("strings.(*Builder).String"))
+}
+
+func TestExtractConversionHotspots(t *testing.T) {
+	p := newHeapProfile(
+		newStackSample(300, "runtime.stringtoslicebyte", "github.com/myapp/codec.Encode", "main.main"),
+		newStackSample(100, "runtime.slicebytetostring", "strings.ToUpper", "github.com/myapp/codec.Decode", "main.main"),
+		newStackSample(50, "runtime.stringtoslicebyte", "github.com/myapp/codec.Encode", "main.main"),
+		newStackSample(50, "runtime.slicebytetostring", "bytes.(*Buffer).String"),
+		newStackSample(500, "github.com/myapp/cache.New", "main.main"),
+	)
+
+	hotspots := extractConversionHotspots(p)
+	require.Len(t, hotspots, 3)
+
+	// 同一调用点的分配合并，按字节数降序
+	assert.Equal(t, "github.com/myapp/codec.Encode", hotspots[0].Caller)
+	assert.Equal(t, "runtime.stringtoslicebyte", hotspots[0].RuntimeFunc)
+	assert.Equal(t, int64(350), hotspots[0].AllocSpace)
+	assert.InDelta(t, 0.35, hotspots[0].Share, 0.0001)
+
+	// 跳过标准库帧，追溯到业务调用点
+	assert.Equal(t, "github.com/myapp/codec.Decode", hotspots[1].Caller)
+
+	// 没有业务帧时退回第一个非 runtime 帧
+	assert.Equal(t, "bytes.(*Buffer).String", hotspots[2].Caller)
+
+	assert.InDelta(t, 0.5, ConversionShare(hotspots), 0.0001)
+}
+
+func TestExtractMetrics_ConversionHotspots(t *testing.T) {
+	p := newHeapProfile(newStackSample(100, "runtime.stringtoslicebyte", "main.handle"))
+	metrics := ExtractMetrics(p, "heap")
+	require.NotNil(t, metrics)
+	require.Len(t, metrics.ConversionHotspots, 1)
+	assert.Equal(t, "main.handle", metrics.ConversionHotspots[0].Caller)
+
+	assert.Nil(t, ExtractMetrics(p, "cpu").ConversionHotspots)
+}
+
+func TestDetectConversionHotspots(t *testing.T) {
+	metrics := &ProfileMetrics{
+		ConversionHotspots: []ConversionHotspot{
+			{RuntimeFunc: "runtime.stringtoslicebyte", Caller: "main.a", AllocSpace: 15, Share: 0.15},
+			{RuntimeFunc: "runtime.slicebytetostring", Caller: "main.b", AllocSpace: 10, Share: 0.10},
+		},
+	}
+
+	assert.Len(t, DetectConversionHotspots(metrics, DefaultConversionMinShare), 2)
+	assert.Nil(t, DetectConversionHotspots(metrics, 0.3))
+	assert.Nil(t, DetectConversionHotspots(nil, DefaultConversionMinShare))
+}
+
+func TestIsStdPackage(t *testing.T) {
+	assert.True(t, isStdPackage("strings"))
+	assert.True(t, isStdPackage("encoding/json"))
+	assert.False(t, isStdPackage("main"))
+	assert.False(t, isStdPackage("github.com/myapp/codec"))
+	assert.False(t, isStdPackage("golang.org/x/text/unicode"))
+}
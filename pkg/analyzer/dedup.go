@@ -0,0 +1,91 @@
+package analyzer
+
+import (
+	"math"
+	"time"
+)
+
+// DefaultDedupTolerance 两个快照的各项指标相对差异都不超过该比例时视为重复快照
+const DefaultDedupTolerance = 0.001
+
+// dedupMetrics 判断快照是否重复时比较的指标
+var dedupMetrics = []func(m *ProfileMetrics) float64{
+	func(m *ProfileMetrics) float64 { return float64(m.TotalSamples) },
+	func(m *ProfileMetrics) float64 { return float64(m.TotalValue) },
+	func(m *ProfileMetrics) float64 { return float64(m.CPUTime) },
+	func(m *ProfileMetrics) float64 { return float64(m.AllocObjects) },
+	func(m *ProfileMetrics) float64 { return float64(m.AllocSpace) },
+	func(m *ProfileMetrics) float64 { return float64(m.InuseObjects) },
+	func(m *ProfileMetrics) float64 { return float64(m.InuseSpace) },
+	func(m *ProfileMetrics) float64 { return float64(m.GoroutineCount) },
+	func(m *ProfileMetrics) float64 { return float64(m.Contentions) },
+	func(m *ProfileMetrics) float64 { return float64(m.BlockDelay) },
+}
+
+// MarkDuplicateFiles 标记组内相邻的重复快照，返回标记的文件数
+// 采集过于频繁时会得到指标完全相同的连续快照，它们在趋势回归中被重复计入。
+// 连续且与簇中第一个快照的各项指标相对差异都不超过 tolerance 的快照组成一个簇，
+// 簇内保留采集时间最早的快照，其余快照的 DuplicateOf 设为它的路径，不参与趋势计算
+func MarkDuplicateFiles(files []ProfileFile, tolerance float64) int {
+	marked := 0
+	for start := 0; start < len(files); {
+		end := start + 1
+		for end < len(files) && sameMetrics(files[start].Metrics, files[end].Metrics, tolerance) {
+			end++
+		}
+		if end-start > 1 {
+			kept := start
+			for i := start + 1; i < end; i++ {
+				if files[i].Time.Before(files[kept].Time) {
+					kept = i
+				}
+			}
+			for i := start; i < end; i++ {
+				if i != kept {
+					files[i].DuplicateOf = files[kept].Path
+					marked++
+				}
+			}
+		}
+		start = end
+	}
+	return marked
+}
+
+// sameMetrics 判断两个快照的指标是否在容差内相同，缺少指标的快照不视为重复
+func sameMetrics(a, b *ProfileMetrics, tolerance float64) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	for _, metric := range dedupMetrics {
+		x, y := metric(a), metric(b)
+		if math.Abs(x-y) > tolerance*math.Max(math.Abs(x), math.Abs(y)) {
+			return false
+		}
+	}
+	return true
+}
+
+// countDuplicates 返回被标记为重复的快照数
+func countDuplicates(files []ProfileFile) int {
+	count := 0
+	for _, file := range files {
+		if file.DuplicateOf != "" {
+			count++
+		}
+	}
+	return count
+}
+
+// trendFiles 返回参与趋势计算的快照及其时间，跳过缺少指标和被标记为重复的快照
+func trendFiles(files []ProfileFile) ([]*ProfileMetrics, []time.Time) {
+	var points []*ProfileMetrics
+	var times []time.Time
+	for _, file := range files {
+		if file.Metrics != nil && file.DuplicateOf == "" {
+			points = append(points, file.Metrics)
+			times = append(times, file.Time)
+		}
+	}
+	return points, times
+}
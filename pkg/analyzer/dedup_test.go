@@ -0,0 +1,87 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dedupFile 创建 heap 快照，inuse 为存活内存 (MB)
+func dedupFile(path string, minute int, inuse int64) ProfileFile {
+	return ProfileFile{
+		Path:    path,
+		Time:    time.Date(2023, 11, 15, 14, minute, 0, 0, time.UTC),
+		Metrics: &ProfileMetrics{TotalSamples: 100, InuseSpace: inuse << 20, AllocSpace: inuse << 21},
+	}
+}
+
+func TestMarkDuplicateFiles(t *testing.T) {
+	files := []ProfileFile{
+		dedupFile("heap1.pprof", 0, 100),
+		dedupFile("heap2.pprof", 1, 100),
+		dedupFile("heap3.pprof", 2, 100),
+		dedupFile("heap4.pprof", 3, 200),
+		dedupFile("heap5.pprof", 4, 300),
+	}
+	assert.Equal(t, 2, MarkDuplicateFiles(files, DefaultDedupTolerance))
+	assert.Empty(t, files[0].DuplicateOf)
+	assert.Equal(t, "heap1.pprof", files[1].DuplicateOf)
+	assert.Equal(t, "heap1.pprof", files[2].DuplicateOf)
+	assert.Empty(t, files[3].DuplicateOf)
+	assert.Empty(t, files[4].DuplicateOf)
+}
+
+// TestMarkDuplicateFiles_KeepEarliest 文件不按时间排序时 (如 -order-by-filename) 保留簇内最早的快照
+func TestMarkDuplicateFiles_KeepEarliest(t *testing.T) {
+	files := []ProfileFile{
+		dedupFile("heap1.pprof", 5, 100),
+		dedupFile("heap2.pprof", 1, 100),
+		dedupFile("heap3.pprof", 3, 100),
+	}
+	assert.Equal(t, 2, MarkDuplicateFiles(files, DefaultDedupTolerance))
+	assert.Equal(t, "heap2.pprof", files[0].DuplicateOf)
+	assert.Empty(t, files[1].DuplicateOf)
+	assert.Equal(t, "heap2.pprof", files[2].DuplicateOf)
+}
+
+func TestMarkDuplicateFiles_Tolerance(t *testing.T) {
+	// 与簇中第一个快照比较，逐次的小幅变化不会累积成一个簇
+	files := []ProfileFile{
+		{Path: "a", Metrics: &ProfileMetrics{InuseSpace: 100000}},
+		{Path: "b", Metrics: &ProfileMetrics{InuseSpace: 100050}},
+		{Path: "c", Metrics: &ProfileMetrics{InuseSpace: 100150}},
+		{Path: "d"}, // 缺少指标的快照不视为重复
+		{Path: "e"},
+	}
+	assert.Equal(t, 1, MarkDuplicateFiles(files, DefaultDedupTolerance))
+	assert.Equal(t, "a", files[1].DuplicateOf)
+	assert.Empty(t, files[2].DuplicateOf)
+	assert.Empty(t, files[4].DuplicateOf)
+
+	assert.Zero(t, MarkDuplicateFiles(nil, DefaultDedupTolerance))
+}
+
+// TestCalculateTrends_Dedup 三个相同的快照在趋势中只计一次，分组中仍保留所有文件
+func TestCalculateTrends_Dedup(t *testing.T) {
+	group := ProfileGroup{Type: "heap", Files: []ProfileFile{
+		dedupFile("heap1.pprof", 0, 100),
+		dedupFile("heap2.pprof", 1, 100),
+		dedupFile("heap3.pprof", 2, 100),
+		dedupFile("heap4.pprof", 3, 200),
+		dedupFile("heap5.pprof", 4, 300),
+	}}
+	require.Equal(t, 5, CalculateTrends(group).HeapInuse.Points)
+
+	MarkDuplicateFiles(group.Files, DefaultDedupTolerance)
+	trends := CalculateTrends(group)
+	require.NotNil(t, trends)
+	assert.Equal(t, 3, trends.HeapInuse.Points)
+	assert.Equal(t, "increasing", trends.HeapInuse.Direction)
+	assert.Len(t, group.Files, 5)
+
+	// 去重后不足最少快照数时不计算趋势
+	group.Files = group.Files[:4]
+	assert.Nil(t, CalculateTrends(group))
+}
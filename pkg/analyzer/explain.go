@@ -0,0 +1,182 @@
+package analyzer
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/pprof/profile"
+)
+
+// FunctionUsage 单个快照中目标函数的消耗
+type FunctionUsage struct {
+	Path    string
+	Time    time.Time
+	Flat    int64   // 目标函数位于栈顶的样本值
+	FlatPct float64 // Flat 占快照总值的百分比
+	Cum     int64   // 调用栈包含目标函数的样本值，每个样本只计一次
+	CumPct  float64 // Cum 占快照总值的百分比
+}
+
+// FunctionProfileUsage 目标函数在一种 profile (及 sample type) 中的消耗
+type FunctionProfileUsage struct {
+	Type       string          // profile 类型
+	SampleType string          // 使用的 sample type，如 cpu、alloc_space、inuse_space、goroutine
+	Unit       string          // sample type 的单位，如 nanoseconds、bytes、count
+	Snapshots  []FunctionUsage // 按组内顺序排列的每个快照
+	Trend      *TrendMetrics   // Cum 随快照的趋势，快照数不足时为 nil
+}
+
+// Found 判断目标函数是否出现在任一快照的调用栈中
+func (u FunctionProfileUsage) Found() bool {
+	for _, s := range u.Snapshots {
+		if s.Cum > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Latest 返回最后一个快照的消耗，没有快照时返回零值
+func (u FunctionProfileUsage) Latest() FunctionUsage {
+	if len(u.Snapshots) == 0 {
+		return FunctionUsage{}
+	}
+	return u.Snapshots[len(u.Snapshots)-1]
+}
+
+// FunctionExplanation 单个函数在所有 profile 中的情况
+type FunctionExplanation struct {
+	Function string                 // 查询的函数名
+	Names    []string               // profile 中匹配到的函数名 (含闭包)，按名称排序
+	Profiles []FunctionProfileUsage // 每种 profile 的消耗，顺序与 groups 一致；heap 分别给出 alloc_space 和 inuse_space
+}
+
+// Found 判断目标函数是否出现在任一 profile 中
+func (e *FunctionExplanation) Found() bool {
+	return e != nil && len(e.Names) > 0
+}
+
+// MatchesFunction 判断 profile 中的函数名是否属于目标函数
+// 完全相同，或者是目标函数内的闭包 (如 app.HandleOrder.func1)
+func MatchesFunction(name, function string) bool {
+	if function == "" {
+		return false
+	}
+	return name == function || strings.HasPrefix(name, function+".func")
+}
+
+// explainSampleTypes 返回解释函数时使用的 sample type
+// heap 同时查看累计分配和存活内存，其他类型优先使用以纳秒计的值 (CPU 时间、阻塞时长)
+func explainSampleTypes(profileType string, p *profile.Profile) []int {
+	if p == nil || len(p.SampleType) == 0 {
+		return nil
+	}
+	if profileType == "heap" {
+		var indices []int
+		for _, want := range []string{HeapSampleAllocSpace, HeapSampleInuseSpace} {
+			for i, st := range p.SampleType {
+				if st.Type == want {
+					indices = append(indices, i)
+				}
+			}
+		}
+		return indices
+	}
+	for i, st := range p.SampleType {
+		if st.Type == "cpu" || st.Unit == "nanoseconds" {
+			return []int{i}
+		}
+	}
+	return []int{0}
+}
+
+// ExplainFunction 汇总目标函数在每种 profile 中的 flat/cum 消耗及其随时间的趋势
+// 以组内第一个 profile 的 sample type 为准，自定义 profile 使用 -value-type 指定的 sample type；快照数达到 minPoints (<= 0 时为默认值) 时计算 cum 趋势。
+// goroutine profile 的 Cum 即调用栈包含目标函数的 goroutine 数
+func ExplainFunction(groups []ProfileGroup, function string, minPoints int) *FunctionExplanation {
+	explanation := &FunctionExplanation{Function: function}
+	names := make(map[string]bool)
+
+	for _, group := range groups {
+		if len(group.Files) == 0 || group.Files[0].Profile == nil {
+			continue
+		}
+		first := group.Files[0].Profile
+		indices := explainSampleTypes(group.Type, first)
+		if custom := valueTypeIndex(group.Files[0].Metrics, first); custom >= 0 {
+			indices = []int{custom}
+		}
+		for _, index := range indices {
+			st := first.SampleType[index]
+			usage := FunctionProfileUsage{Type: group.Type, SampleType: st.Type, Unit: st.Unit}
+			var values []float64
+			var points []*ProfileMetrics
+			var times []time.Time
+			for _, file := range group.Files {
+				snapshot := functionUsage(file.Profile, index, function, names)
+				snapshot.Path, snapshot.Time = file.Path, file.Time
+				usage.Snapshots = append(usage.Snapshots, snapshot)
+				if file.Metrics != nil {
+					values = append(values, float64(snapshot.Cum))
+					points = append(points, file.Metrics)
+					times = append(times, file.Time)
+				}
+			}
+			if len(values) >= MinTrendPoints(minPoints) {
+				usage.Trend = calculateTrend(values, points, times)
+			}
+			explanation.Profiles = append(explanation.Profiles, usage)
+		}
+	}
+
+	for name := range names {
+		explanation.Names = append(explanation.Names, name)
+	}
+	sort.Strings(explanation.Names)
+	return explanation
+}
+
+// functionUsage 计算单个 profile 中目标函数的 flat 和 cum，并记录匹配到的函数名
+func functionUsage(p *profile.Profile, index int, function string, names map[string]bool) FunctionUsage {
+	var usage FunctionUsage
+	if p == nil {
+		return usage
+	}
+
+	var total int64
+	for _, sample := range p.Sample {
+		if len(sample.Value) <= index {
+			continue
+		}
+		value := sample.Value[index]
+		total += value
+
+		inStack := false
+		for i, loc := range sample.Location {
+			if loc == nil {
+				continue
+			}
+			for j, line := range loc.Line {
+				if line.Function == nil || !MatchesFunction(line.Function.Name, function) {
+					continue
+				}
+				names[line.Function.Name] = true
+				inStack = true
+				// 栈顶位置的第一行 (最内层的内联函数) 为 flat
+				if i == 0 && j == 0 {
+					usage.Flat += value
+				}
+			}
+		}
+		if inStack {
+			usage.Cum += value
+		}
+	}
+
+	if total > 0 {
+		usage.FlatPct = float64(usage.Flat) / float64(total) * 100
+		usage.CumPct = float64(usage.Cum) / float64(total) * 100
+	}
+	return usage
+}
@@ -0,0 +1,110 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const explainTarget = "github.com/myapp/orders.Handle"
+
+// newExplainGroups 创建 cpu、heap、goroutine 三组快照，目标函数的 CPU 消耗逐个快照增长
+func newExplainGroups() []ProfileGroup {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	var cpuFiles []ProfileFile
+	for i, nanos := range []int64{100, 200, 300} {
+		p := newBenchCPUProfile(
+			newCPUSample(nanos, "encoding/json.Marshal", explainTarget, "main.main"),
+			newCPUSample(50, explainTarget+".func1", explainTarget, "main.main"),
+			newCPUSample(650-nanos, "github.com/myapp/reports.Build", "main.main"),
+		)
+		cpuFiles = append(cpuFiles, ProfileFile{
+			Path:    "cpu." + itoa(int64(i+1)) + ".pprof",
+			Time:    start.Add(time.Duration(i) * time.Minute),
+			Profile: p,
+			Metrics: &ProfileMetrics{},
+		})
+	}
+
+	heap := newHeapProfile(newHeapSample(1, explainTarget, 300, 100), newHeapSample(2, "main.main", 700, 0))
+	goroutine := &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "goroutine", Unit: "count"}},
+		Sample: []*profile.Sample{
+			newGoroutineSample(3, "runtime.gopark", "runtime.chansend1", explainTarget, "main.main"),
+			newGoroutineSample(7, "runtime.gopark", "main.main"),
+		},
+	}
+
+	return []ProfileGroup{
+		{Type: "cpu", Files: cpuFiles},
+		{Type: "goroutine", Files: []ProfileFile{{Path: "goroutine.pprof", Profile: goroutine, Metrics: &ProfileMetrics{}}}},
+		{Type: "heap", Files: []ProfileFile{{Path: "heap.pprof", Profile: heap, Metrics: &ProfileMetrics{}}}},
+	}
+}
+
+func TestMatchesFunction(t *testing.T) {
+	assert.True(t, MatchesFunction(explainTarget, explainTarget))
+	assert.True(t, MatchesFunction(explainTarget+".func1", explainTarget))
+	assert.True(t, MatchesFunction(explainTarget+".func1.2", explainTarget))
+	assert.False(t, MatchesFunction(explainTarget+"Batch", explainTarget))
+	assert.False(t, MatchesFunction("github.com/myapp/orders.(*Svc).Handle", explainTarget))
+	assert.False(t, MatchesFunction(explainTarget, ""))
+}
+
+func TestExplainFunction(t *testing.T) {
+	explanation := ExplainFunction(newExplainGroups(), explainTarget, 0)
+	require.True(t, explanation.Found())
+	assert.Equal(t, []string{explainTarget, explainTarget + ".func1"}, explanation.Names)
+
+	// cpu 一种，goroutine 一种，heap 分别给出 alloc_space 和 inuse_space
+	require.Len(t, explanation.Profiles, 4)
+
+	cpu := explanation.Profiles[0]
+	assert.Equal(t, "cpu", cpu.Type)
+	assert.Equal(t, "cpu", cpu.SampleType)
+	assert.Equal(t, "nanoseconds", cpu.Unit)
+	require.Len(t, cpu.Snapshots, 3)
+	latest := cpu.Latest()
+	assert.Equal(t, "cpu.3.pprof", latest.Path)
+	// 闭包位于栈顶的样本计入 flat，两个样本都计入 cum
+	assert.Equal(t, int64(50), latest.Flat)
+	assert.Equal(t, int64(350), latest.Cum)
+	assert.InDelta(t, 50.0, latest.CumPct, 0.001)
+	require.NotNil(t, cpu.Trend)
+	assert.Equal(t, "increasing", cpu.Trend.Direction)
+	assert.InDelta(t, 100.0, cpu.Trend.Slope, 0.001)
+	assert.Equal(t, 3, cpu.Trend.Points)
+
+	goroutine := explanation.Profiles[1]
+	assert.Equal(t, "goroutine", goroutine.Type)
+	assert.Equal(t, int64(3), goroutine.Latest().Cum)
+	assert.InDelta(t, 30.0, goroutine.Latest().CumPct, 0.001)
+	assert.Nil(t, goroutine.Trend, "a single snapshot has no trend")
+
+	alloc, inuse := explanation.Profiles[2], explanation.Profiles[3]
+	assert.Equal(t, HeapSampleAllocSpace, alloc.SampleType)
+	assert.Equal(t, int64(300), alloc.Latest().Flat)
+	assert.InDelta(t, 30.0, alloc.Latest().FlatPct, 0.001)
+	assert.Equal(t, HeapSampleInuseSpace, inuse.SampleType)
+	assert.Equal(t, int64(100), inuse.Latest().Cum)
+	assert.InDelta(t, 100.0, inuse.Latest().CumPct, 0.001)
+}
+
+func TestExplainFunction_NotFound(t *testing.T) {
+	explanation := ExplainFunction(newExplainGroups(), "github.com/myapp/orders.Cancel", 0)
+	assert.False(t, explanation.Found())
+	assert.Empty(t, explanation.Names)
+	for _, usage := range explanation.Profiles {
+		assert.False(t, usage.Found(), usage.Type)
+	}
+
+	assert.False(t, ExplainFunction(nil, explainTarget, 0).Found())
+}
+
+func TestExplainFunction_MinPoints(t *testing.T) {
+	explanation := ExplainFunction(newExplainGroups(), explainTarget, 4)
+	assert.Nil(t, explanation.Profiles[0].Trend, "3 snapshots are below -min-trend-points 4")
+}
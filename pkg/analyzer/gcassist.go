@@ -0,0 +1,93 @@
+package analyzer
+
+import (
+	"sort"
+
+	"github.com/google/pprof/profile"
+)
+
+// DefaultGCAssistMinShare GC 辅助标记占 CPU 时间 5% 以上视为分配速度超过了 GC 的回收速度
+const DefaultGCAssistMinShare = 0.05
+
+// GCAssistSite 触发 GC 辅助标记的分配点
+type GCAssistSite struct {
+	Site  string  // 触发辅助标记的分配点（优先取第一个非标准库帧）
+	Value int64   // 辅助标记消耗的 CPU 时间 (纳秒)
+	Share float64 // 占 profile 总 CPU 时间的比例
+}
+
+// GCAssistShare 返回 GC 辅助标记的总 CPU 占比
+func GCAssistShare(sites []GCAssistSite) float64 {
+	var share float64
+	for _, s := range sites {
+		share += s.Share
+	}
+	return share
+}
+
+// DetectGCAssistStalls 当 GC 辅助标记的 CPU 占比达到 minShare 时返回各分配点
+// 与后台 GC 不同，辅助标记发生在业务 goroutine 的分配路径上，直接拖慢请求
+func DetectGCAssistStalls(metrics *ProfileMetrics, minShare float64) []GCAssistSite {
+	if metrics == nil || len(metrics.GCAssistSites) == 0 {
+		return nil
+	}
+	if GCAssistShare(metrics.GCAssistSites) < minShare {
+		return nil
+	}
+	return metrics.GCAssistSites
+}
+
+// extractGCAssistSites 按分配点汇总 CPU profile 中 GC 辅助标记的消耗
+// 调用栈包含辅助标记帧的样本计入，从最外层的辅助标记帧向上跳过 mallocgc 等 runtime 帧，
+// 追溯到触发分配的调用点。结果按消耗降序排列
+func extractGCAssistSites(p *profile.Profile) []GCAssistSite {
+	valueIndex, scale := cpuValueIndex(p)
+	if valueIndex < 0 {
+		return nil
+	}
+
+	var total int64
+	bySite := make(map[string]*GCAssistSite)
+	for _, sample := range p.Sample {
+		if len(sample.Value) <= valueIndex {
+			continue
+		}
+		value := sample.Value[valueIndex] * scale
+		total += value
+
+		frames := sampleFunctions(sample)
+		outermost := -1
+		for i, name := range frames {
+			if IsGCAssistFrame(name) {
+				outermost = i
+			}
+		}
+		if outermost < 0 {
+			continue
+		}
+
+		site := conversionCaller(frames[outermost+1:])
+		entry, ok := bySite[site]
+		if !ok {
+			entry = &GCAssistSite{Site: site}
+			bySite[site] = entry
+		}
+		entry.Value += value
+	}
+	if total <= 0 || len(bySite) == 0 {
+		return nil
+	}
+
+	result := make([]GCAssistSite, 0, len(bySite))
+	for _, entry := range bySite {
+		entry.Share = float64(entry.Value) / float64(total)
+		result = append(result, *entry)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Value != result[j].Value {
+			return result[i].Value > result[j].Value
+		}
+		return result[i].Site < result[j].Site
+	})
+	return result
+}
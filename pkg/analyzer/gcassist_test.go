@@ -0,0 +1,69 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsGCAssistFrame(t *testing.T) {
+	assert.True(t, IsGCAssistFrame("runtime.gcAssistAlloc"))
+	assert.True(t, IsGCAssistFrame("runtime.gcAssistAlloc1"))
+	assert.True(t, IsGCAssistFrame("runtime.gcParkAssist"))
+	assert.True(t, IsGCAssistFrame("runtime.deductAssistCredit"))
+	assert.False(t, IsGCAssistFrame("runtime.gcBgMarkWorker"))
+	assert.False(t, IsGCAssistFrame("runtime.mallocgc"))
+
+	// 辅助标记帧同时是 GC 帧
+	assert.True(t, NewRuntimeFrameMatcher("").IsGCFrame("runtime.gcAssistAlloc"))
+}
+
+func TestExtractGCAssistSites(t *testing.T) {
+	p := newBenchCPUProfile(
+		// 辅助标记向上跳过 mallocgc/newobject 和标准库帧，追溯到业务分配点
+		newCPUSample(300, "runtime.gcDrainN", "runtime.gcAssistAlloc1", "runtime.systemstack", "runtime.gcAssistAlloc",
+			"runtime.deductAssistCredit", "runtime.mallocgc", "runtime.newobject", "encoding/json.(*decodeState).object",
+			"github.com/myapp/api.DecodeOrder", "main.main"),
+		newCPUSample(100, "runtime.gcParkAssist", "runtime.gcAssistAlloc", "runtime.mallocgc", "runtime.makeslice",
+			"github.com/myapp/api.DecodeOrder", "main.main"),
+		newCPUSample(100, "runtime.gcAssistAlloc", "runtime.mallocgc", "github.com/myapp/cache.Put"),
+		// 后台标记不是辅助标记
+		newCPUSample(200, "runtime.scanobject", "runtime.gcDrain", "runtime.gcBgMarkWorker"),
+		newCPUSample(300, "github.com/myapp/api.Compute"),
+	)
+
+	sites := extractGCAssistSites(p)
+	require.Len(t, sites, 2)
+
+	// 同一分配点合并
+	assert.Equal(t, "github.com/myapp/api.DecodeOrder", sites[0].Site)
+	assert.Equal(t, int64(400), sites[0].Value)
+	assert.InDelta(t, 0.4, sites[0].Share, 0.0001)
+	assert.Equal(t, "github.com/myapp/cache.Put", sites[1].Site)
+	assert.InDelta(t, 0.5, GCAssistShare(sites), 0.0001)
+
+	assert.Nil(t, extractGCAssistSites(newBenchCPUProfile(newCPUSample(100, "main.main"))))
+}
+
+func TestExtractMetrics_GCAssistSites(t *testing.T) {
+	cpu := ExtractMetrics(newBenchCPUProfile(newCPUSample(100, "runtime.gcAssistAlloc", "runtime.mallocgc", "main.handle")), "cpu")
+	require.Len(t, cpu.GCAssistSites, 1)
+	assert.Equal(t, "main.handle", cpu.GCAssistSites[0].Site)
+
+	assert.Nil(t, ExtractMetrics(newHeapProfile(newStackSample(100, "runtime.gcAssistAlloc", "main.handle")), "heap").GCAssistSites)
+}
+
+func TestDetectGCAssistStalls(t *testing.T) {
+	metrics := &ProfileMetrics{
+		GCAssistSites: []GCAssistSite{
+			{Site: "main.a", Value: 3, Share: 0.03},
+			{Site: "main.b", Value: 2, Share: 0.02},
+		},
+	}
+
+	assert.Len(t, DetectGCAssistStalls(metrics, DefaultGCAssistMinShare), 2)
+	assert.Nil(t, DetectGCAssistStalls(metrics, 0.1))
+	assert.Nil(t, DetectGCAssistStalls(nil, DefaultGCAssistMinShare))
+	assert.Nil(t, DetectGCAssistStalls(&ProfileMetrics{}, DefaultGCAssistMinShare))
+}
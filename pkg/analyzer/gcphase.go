@@ -0,0 +1,93 @@
+package analyzer
+
+import (
+	"math"
+	"time"
+)
+
+// GC 阶段偏斜检测参数
+const (
+	// GCPhaseDeviation 快照比两侧参照值都低 (或都高) 该比例以上时，视为在 GC 刚结束 (或即将开始) 时采集
+	GCPhaseDeviation = 0.35
+	// GCPhaseWeight 疑似偏斜快照在趋势拟合中的权重系数
+	GCPhaseWeight = 0.25
+	// GCPhaseSensitiveRatio 疑似偏斜快照至少占数据点的该比例 (且不少于 2 个) 时，整个序列标记为对 GC 阶段敏感
+	GCPhaseSensitiveRatio = 0.25
+)
+
+// GCPhaseSkew 疑似在 GC 周期极值处采集的 heap 快照
+// GC 刚结束时存活内存最少，下一次 GC 开始前最多 (默认 GOGC=100 时约为两倍)，周期性采集的序列因此呈锯齿状
+type GCPhaseSkew struct {
+	Index     int       // 数据点索引 (按文件顺序)
+	Time      time.Time // 快照时间
+	Value     float64   // 实际值
+	Reference float64   // 相邻快照给出的参照值，偏低时取较小者，偏高时取较大者
+	Low       bool      // true 表示远低于参照值 (GC 刚结束)，false 表示远高于参照值 (GC 之前)
+}
+
+// Deviation 返回快照相对参照值的偏离比例，如 -0.5 表示低 50%
+func (s GCPhaseSkew) Deviation() float64 {
+	if s.Reference == 0 {
+		return 0
+	}
+	return (s.Value - s.Reference) / s.Reference
+}
+
+// DetectGCPhaseSkew 检测 heap 存活内存序列中疑似在 GC 极值处采集的快照，按顺序返回
+// 中间的快照与前后两个快照比较，比两者都低 (或都高) GCPhaseDeviation 以上时判为偏斜；
+// 首尾快照与相邻快照及相邻两点的线性外推比较。持续增长或阶跃变化的序列不会被误判
+func DetectGCPhaseSkew(values []float64) []GCPhaseSkew {
+	n := len(values)
+	if n < 3 {
+		return nil
+	}
+	for _, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) || v < 0 {
+			return nil
+		}
+	}
+
+	var skews []GCPhaseSkew
+	for i, v := range values {
+		var a, b float64
+		switch i {
+		case 0:
+			a, b = values[1], 2*values[1]-values[2]
+		case n - 1:
+			a, b = values[n-2], 2*values[n-2]-values[n-3]
+		default:
+			a, b = values[i-1], values[i+1]
+		}
+		if a <= 0 || b <= 0 {
+			continue
+		}
+
+		if low := math.Min(a, b); v < low*(1-GCPhaseDeviation) {
+			skews = append(skews, GCPhaseSkew{Index: i, Value: v, Reference: low, Low: true})
+		} else if high := math.Max(a, b); v > high*(1+GCPhaseDeviation) {
+			skews = append(skews, GCPhaseSkew{Index: i, Value: v, Reference: high})
+		}
+	}
+	return skews
+}
+
+// IsGCPhaseSensitive 判断偏斜快照是否多到让整个序列的趋势结论不可靠
+func IsGCPhaseSensitive(skews []GCPhaseSkew, points int) bool {
+	return len(skews) >= 2 && float64(len(skews)) >= GCPhaseSensitiveRatio*float64(points)
+}
+
+// gcPhaseWeights 将偏斜快照的权重乘以 GCPhaseWeight，weights 为空 (不加权) 时以 1 为基准
+// 返回新的切片，不修改 weights
+func gcPhaseWeights(weights []float64, n int, skews []GCPhaseSkew) []float64 {
+	adjusted := make([]float64, n)
+	for i := range adjusted {
+		adjusted[i] = 1
+		if len(weights) == n {
+			adjusted[i] = weights[i]
+		}
+	}
+	for _, skew := range skews {
+		adjusted[skew.Index] *= GCPhaseWeight
+	}
+	return adjusted
+}
@@ -0,0 +1,102 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectGCPhaseSkew(t *testing.T) {
+	indices := func(skews []GCPhaseSkew) []int {
+		result := make([]int, 0, len(skews))
+		for _, s := range skews {
+			result = append(result, s.Index)
+		}
+		return result
+	}
+
+	t.Run("dip after gc", func(t *testing.T) {
+		skews := DetectGCPhaseSkew([]float64{100, 110, 120, 60, 140, 150})
+		require.Len(t, skews, 1)
+		assert.Equal(t, 3, skews[0].Index)
+		assert.True(t, skews[0].Low)
+		assert.Equal(t, 120.0, skews[0].Reference)
+		assert.InDelta(t, -0.5, skews[0].Deviation(), 1e-9)
+	})
+
+	t.Run("peak before gc at the end", func(t *testing.T) {
+		skews := DetectGCPhaseSkew([]float64{100, 100, 100, 100, 200})
+		require.Len(t, skews, 1)
+		assert.Equal(t, 4, skews[0].Index)
+		assert.False(t, skews[0].Low)
+		assert.InDelta(t, 1.0, skews[0].Deviation(), 1e-9)
+	})
+
+	t.Run("sawtooth", func(t *testing.T) {
+		skews := DetectGCPhaseSkew([]float64{100, 200, 100, 200, 100, 200})
+		assert.Equal(t, []int{0, 1, 2, 3, 4}, indices(skews))
+		assert.True(t, IsGCPhaseSensitive(skews, 6))
+	})
+
+	t.Run("steady growth and steps are not skewed", func(t *testing.T) {
+		assert.Empty(t, DetectGCPhaseSkew([]float64{100, 150, 200, 250, 300}))
+		assert.Empty(t, DetectGCPhaseSkew([]float64{100, 100, 200, 200, 200}))
+		assert.Empty(t, DetectGCPhaseSkew([]float64{100, 105, 98, 102, 101}))
+	})
+
+	t.Run("too few or invalid points", func(t *testing.T) {
+		assert.Empty(t, DetectGCPhaseSkew([]float64{100, 10}))
+		assert.Empty(t, DetectGCPhaseSkew([]float64{0, 0, 0, 0}))
+		assert.Empty(t, DetectGCPhaseSkew([]float64{100, -1, 100}))
+	})
+}
+
+func TestIsGCPhaseSensitive(t *testing.T) {
+	one := []GCPhaseSkew{{Index: 1}}
+	two := []GCPhaseSkew{{Index: 1}, {Index: 3}}
+	assert.False(t, IsGCPhaseSensitive(one, 3), "a single skewed snapshot only down-weights")
+	assert.True(t, IsGCPhaseSensitive(two, 8))
+	assert.False(t, IsGCPhaseSensitive(two, 9))
+}
+
+func TestGCPhaseWeights(t *testing.T) {
+	skews := []GCPhaseSkew{{Index: 1}}
+	assert.Equal(t, []float64{1, GCPhaseWeight, 1}, gcPhaseWeights(nil, 3, skews))
+
+	weights := []float64{30, 30, 10}
+	assert.Equal(t, []float64{30, 30 * GCPhaseWeight, 10}, gcPhaseWeights(weights, 3, skews))
+	assert.Equal(t, []float64{30, 30, 10}, weights, "input weights should not be modified")
+}
+
+func TestCalculateTrends_GCPhase(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	inuse := []int64{100, 110, 120, 30, 140, 150}
+	var files []ProfileFile
+	for i, v := range inuse {
+		files = append(files, ProfileFile{
+			Time:    base.Add(time.Duration(i) * time.Minute),
+			Metrics: &ProfileMetrics{InuseSpace: v, AllocSpace: int64(i+1) * 1000},
+		})
+	}
+
+	trends := CalculateTrends(ProfileGroup{Type: "heap", Files: files})
+	require.NotNil(t, trends)
+
+	heap := trends.HeapInuse
+	require.Len(t, heap.GCPhase, 1)
+	assert.Equal(t, 3, heap.GCPhase[0].Index)
+	assert.Equal(t, base.Add(3*time.Minute), heap.GCPhase[0].Time)
+	assert.False(t, heap.GCPhaseSensitive)
+	assert.Equal(t, GCPhaseWeight, heap.Weights[3])
+
+	// 降权后的拟合比不加权更接近真实的增长 (每个快照 +10)
+	unweighted, unweightedR2 := LinearRegression([]float64{100, 110, 120, 30, 140, 150})
+	assert.Greater(t, heap.Slope, unweighted)
+	assert.Greater(t, heap.R2, unweightedR2)
+
+	// 累计分配不受 GC 阶段影响
+	assert.Empty(t, trends.HeapTrends[HeapSampleAllocSpace].GCPhase)
+	assert.Nil(t, trends.HeapTrends[HeapSampleAllocSpace].Weights)
+}
@@ -0,0 +1,138 @@
+package analyzer
+
+import (
+	"sort"
+
+	"github.com/google/pprof/profile"
+)
+
+// goroutine 的状态，与 runtime 的 waitReason 和 debug=2 goroutine dump 中的写法一致
+const (
+	GoroutineStateRunning     = "running"
+	GoroutineStateWaiting     = "waiting" // 停在 runtime.gopark 上但无法识别原因
+	GoroutineStateChanReceive = "chan receive"
+	GoroutineStateChanSend    = "chan send"
+	GoroutineStateSelect      = "select"
+	GoroutineStateSelectNone  = "select (no cases)"
+	GoroutineStateIOWait      = "IO wait"
+	GoroutineStateSleep       = "sleep"
+	GoroutineStateSemacquire  = "semacquire"
+	GoroutineStateMutexLock   = "sync.Mutex.Lock"
+	GoroutineStateRWMutexR    = "sync.RWMutex.RLock"
+	GoroutineStateRWMutexW    = "sync.RWMutex.Lock"
+	GoroutineStateCondWait    = "sync.Cond.Wait"
+	GoroutineStateSyscall     = "syscall"
+	GoroutineStateGCIdle      = "GC worker (idle)"
+)
+
+// goroutineStateLabel 部分工具在 goroutine profile 的样本标签中记录状态
+const goroutineStateLabel = "state"
+
+// goroutineStateFuncs 调用栈中标识等待原因的函数，从叶子帧向上第一个命中的函数决定状态
+var goroutineStateFuncs = map[string]string{
+	"runtime.chanrecv":                  GoroutineStateChanReceive,
+	"runtime.chanrecv1":                 GoroutineStateChanReceive,
+	"runtime.chanrecv2":                 GoroutineStateChanReceive,
+	"runtime.chansend":                  GoroutineStateChanSend,
+	"runtime.chansend1":                 GoroutineStateChanSend,
+	"runtime.selectgo":                  GoroutineStateSelect,
+	"runtime.block":                     GoroutineStateSelectNone,
+	"runtime.netpollblock":              GoroutineStateIOWait,
+	"internal/poll.runtime_pollWait":    GoroutineStateIOWait,
+	"time.Sleep":                        GoroutineStateSleep,
+	"sync.runtime_Semacquire":           GoroutineStateSemacquire,
+	"sync.runtime_SemacquireMutex":      GoroutineStateMutexLock,
+	"sync.runtime_SemacquireRWMutexR":   GoroutineStateRWMutexR,
+	"sync.runtime_SemacquireRWMutex":    GoroutineStateRWMutexW,
+	"sync.runtime_notifyListWait":       GoroutineStateCondWait,
+	"runtime.gcBgMarkWorker":            GoroutineStateGCIdle,
+	"syscall.Syscall":                   GoroutineStateSyscall,
+	"syscall.Syscall6":                  GoroutineStateSyscall,
+	"syscall.RawSyscall":                GoroutineStateSyscall,
+	"runtime/internal/syscall.Syscall6": GoroutineStateSyscall,
+	"internal/runtime/syscall.Syscall6": GoroutineStateSyscall,
+	"golang.org/x/sys/unix.Syscall":     GoroutineStateSyscall,
+	"golang.org/x/sys/unix.Syscall6":    GoroutineStateSyscall,
+	"golang.org/x/sys/unix.RawSyscall":  GoroutineStateSyscall,
+	"golang.org/x/sys/unix.RawSyscall6": GoroutineStateSyscall,
+	"runtime.gopark":                    GoroutineStateWaiting,
+	"runtime.goparkunlock":              GoroutineStateWaiting,
+	"runtime.notetsleepg":               GoroutineStateWaiting,
+	"runtime.semacquire":                GoroutineStateSemacquire,
+}
+
+// GoroutineState 一种状态的 goroutine 数
+type GoroutineState struct {
+	State string
+	Count int
+}
+
+// extractGoroutineStates 按状态统计 goroutine 数
+// 样本带有 state 标签时直接使用；否则从叶子帧向上查找标识等待原因的 runtime/sync 函数，
+// gopark 之上没有可识别的调用方时为 waiting，没有停在 gopark 上的为 running。
+// 调用栈没有符号信息 (无法判断状态) 的样本不计入；所有样本都无法判断时返回 nil
+func extractGoroutineStates(p *profile.Profile) map[string]int {
+	var states map[string]int
+	for _, sample := range p.Sample {
+		if len(sample.Value) == 0 || sample.Value[0] <= 0 {
+			continue
+		}
+		state := goroutineSampleState(sample)
+		if state == "" {
+			continue
+		}
+		if states == nil {
+			states = make(map[string]int)
+		}
+		states[state] += int(sample.Value[0])
+	}
+	return states
+}
+
+// goroutineSampleState 返回一个样本的 goroutine 状态，无法判断时返回空字符串
+func goroutineSampleState(sample *profile.Sample) string {
+	if values := sample.Label[goroutineStateLabel]; len(values) > 0 && values[0] != "" {
+		return values[0]
+	}
+
+	state, named := "", false
+	for _, loc := range sample.Location {
+		for _, line := range loc.Line {
+			if line.Function == nil || line.Function.Name == "" {
+				continue
+			}
+			named = true
+			s, ok := goroutineStateFuncs[line.Function.Name]
+			if !ok {
+				continue
+			}
+			if s != GoroutineStateWaiting {
+				return s
+			}
+			// gopark 只说明在等待，继续向上查找具体原因
+			state = s
+		}
+	}
+	if !named {
+		return ""
+	}
+	if state == "" {
+		return GoroutineStateRunning
+	}
+	return state
+}
+
+// SortedGoroutineStates 按 goroutine 数降序返回各状态，数量相同时按状态名排序
+func SortedGoroutineStates(states map[string]int) []GoroutineState {
+	result := make([]GoroutineState, 0, len(states))
+	for state, count := range states {
+		result = append(result, GoroutineState{State: state, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].State < result[j].State
+	})
+	return result
+}
@@ -0,0 +1,64 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExtractMetrics_GoroutineStates 按叶子帧区分各种等待状态和运行中的 goroutine
+func TestExtractMetrics_GoroutineStates(t *testing.T) {
+	p := &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "goroutines", Unit: "count"}},
+		Sample: []*profile.Sample{
+			newGoroutineSample(40, "runtime.gopark", "runtime.chanrecv", "runtime.chanrecv1", "github.com/myapp/pool.(*Worker).Run"),
+			newGoroutineSample(10, "runtime.gopark", "runtime.selectgo", "github.com/myapp/pool.(*Dispatcher).Loop"),
+			newGoroutineSample(5, "runtime.gopark", "runtime.netpollblock", "internal/poll.runtime_pollWait", "net.(*conn).Read"),
+			newGoroutineSample(3, "runtime.gopark", "runtime.goparkunlock", "runtime.semacquire1", "sync.runtime_SemacquireMutex", "sync.(*Mutex).Lock"),
+			newGoroutineSample(2, "runtime.gopark", "time.Sleep", "main.tick"),
+			newGoroutineSample(1, "runtime.gopark", "runtime.unknownPark"),
+			newGoroutineSample(1, "runtime/pprof.writeGoroutine", "net/http.HandlerFunc.ServeHTTP"),
+		},
+	}
+
+	metrics := ExtractMetrics(p, "goroutine")
+	require.NotNil(t, metrics)
+	assert.Equal(t, int64(62), metrics.GoroutineCount)
+	assert.Equal(t, map[string]int{
+		GoroutineStateChanReceive: 40,
+		GoroutineStateSelect:      10,
+		GoroutineStateIOWait:      5,
+		GoroutineStateMutexLock:   3,
+		GoroutineStateSleep:       2,
+		GoroutineStateWaiting:     1,
+		GoroutineStateRunning:     1,
+	}, metrics.GoroutineStates)
+}
+
+// TestExtractGoroutineStates_Labels 样本带有 state 标签时优先使用标签
+func TestExtractGoroutineStates_Labels(t *testing.T) {
+	sample := newGoroutineSample(4, "main.worker")
+	sample.Label = map[string][]string{"state": {"chan send"}}
+	p := &profile.Profile{Sample: []*profile.Sample{sample}}
+	assert.Equal(t, map[string]int{"chan send": 4}, extractGoroutineStates(p))
+}
+
+// TestExtractGoroutineStates_Unavailable 调用栈没有符号信息时不给出状态
+func TestExtractGoroutineStates_Unavailable(t *testing.T) {
+	loc := &profile.Location{ID: 1, Address: 0x1000}
+	p := &profile.Profile{Sample: []*profile.Sample{{Location: []*profile.Location{loc}, Value: []int64{7}}}}
+	assert.Empty(t, extractGoroutineStates(p))
+
+	metrics := ExtractMetrics(p, "goroutine")
+	require.NotNil(t, metrics)
+	assert.Equal(t, int64(7), metrics.GoroutineCount)
+	assert.Nil(t, metrics.GoroutineStates)
+}
+
+func TestSortedGoroutineStates(t *testing.T) {
+	states := SortedGoroutineStates(map[string]int{"select": 5, "chan receive": 5, "running": 9})
+	assert.Equal(t, []GoroutineState{{"running", 9}, {"chan receive", 5}, {"select", 5}}, states)
+	assert.Empty(t, SortedGoroutineStates(nil))
+}
@@ -1,6 +1,8 @@
 package analyzer
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"os"
 	"sort"
@@ -18,29 +20,58 @@ type ProfileFile struct {
 	Size    int64
 	Profile *profile.Profile
 	Metrics *ProfileMetrics // 性能指标
+	// DuplicateOf 与该快照指标相同的较早快照的路径，由 MarkDuplicateFiles 填充；非空时不参与趋势计算
+	DuplicateOf string
 }
 
 // ProfileGroup 表示按类型分组的 profile 集合
 type ProfileGroup struct {
-	Type  string
-	Files []ProfileFile
+	Type string
+	// Allocs heap 组中的 profile 全部来自 /debug/pprof/allocs (默认 sample type 为 alloc_space)，
+	// 与 /debug/pprof/heap 的 sample type 相同，只有默认展示的 sample type 不同
+	Allocs bool
+	Files  []ProfileFile
 }
 
 // GroupProfiles 将 profile 文件按类型分组
 func GroupProfiles(paths []string) ([]ProfileGroup, error) {
-	groups := make(map[string][]ProfileFile)
+	return GroupProfilesWithConcurrency(paths, 1)
+}
+
+// GroupProfilesWithConcurrency 使用最多 concurrency 个 goroutine 并行解析文件和提取指标，再按类型分组
+// 结果按输入顺序收集，跳过文件的日志也按输入顺序输出，与并发度和完成顺序无关
+func GroupProfilesWithConcurrency(paths []string, concurrency int) ([]ProfileGroup, error) {
+	return GroupProfilesCtx(context.Background(), paths, concurrency)
+}
+
+// GroupProfilesCtx 与 GroupProfilesWithConcurrency 相同，但可以通过 ctx 取消或设置超时
+// 取消后不再开始解析新文件，正在提取的指标在下一次检查时中止；
+// 此时返回已完成文件组成的部分分组和 ctx.Err()
+func GroupProfilesCtx(ctx context.Context, paths []string, concurrency int) ([]ProfileGroup, error) {
+	type loadResult struct {
+		profileType string
+		file        ProfileFile
+		skip        string // 跳过原因，非空时忽略该文件
+		done        bool   // 是否处理完成，取消时未完成的文件不记录日志
+	}
 
-	for _, path := range paths {
+	results := make([]loadResult, len(paths))
+	ctxErr := ParallelForCtx(ctx, len(paths), concurrency, func(i int) {
+		if ctx.Err() != nil {
+			return
+		}
+		results[i].done = true
+		path := paths[i]
 		fileInfo, err := os.Stat(path)
 		if err != nil {
-			log.Printf("❌ 文件不存在或无效: %s, 错误: %v", path, err)
-			continue
+			results[i].skip = fmt.Sprintf("❌ 文件不存在或无效: %s, 错误: %v", path, err)
+			return
 		}
 
 		p, err := parser.LoadProfile(path)
 		if err != nil {
-			log.Printf("⚠️ 跳过文件: %s, 错误: %v", path, err)
-			continue
+			results[i].skip = fmt.Sprintf("⚠️ 跳过文件: %s, 错误: %v", path, err)
+			return
 		}
 
 		profileType := detectProfileType(p)
@@ -53,23 +84,47 @@ func GroupProfiles(paths []string) ([]ProfileGroup, error) {
 			timestamp = fileInfo.ModTime()
 		}
 
-		groups[profileType] = append(groups[profileType], ProfileFile{
-			Path:    path,
-			Time:    timestamp,
-			Size:    fileInfo.Size(),
-			Profile: p,
-			Metrics: ExtractMetrics(p, profileType),
-		})
+		metrics, err := ExtractMetricsCtx(ctx, p, profileType)
+		if err != nil {
+			results[i].done = false
+			return
+		}
+
+		results[i] = loadResult{
+			profileType: profileType,
+			file: ProfileFile{
+				Path:    path,
+				Time:    timestamp,
+				Size:    fileInfo.Size(),
+				Profile: p,
+				Metrics: metrics,
+			},
+			done: true,
+		}
+	})
+
+	groups := make(map[string][]ProfileFile)
+	for _, r := range results {
+		if !r.done {
+			continue
+		}
+		if r.skip != "" {
+			log.Print(r.skip)
+			continue
+		}
+		groups[r.profileType] = append(groups[r.profileType], r.file)
 	}
 
 	var result []ProfileGroup
 	for groupType, files := range groups {
-		sort.Slice(files, func(i, j int) bool {
+		// 时间相同的文件保持输入顺序，保证输出稳定
+		sort.SliceStable(files, func(i, j int) bool {
 			return files[i].Time.Before(files[j].Time)
 		})
 		result = append(result, ProfileGroup{
-			Type:  groupType,
-			Files: files,
+			Type:   groupType,
+			Allocs: groupType == "heap" && allAllocsProfiles(files),
+			Files:  files,
 		})
 	}
 
@@ -78,7 +133,7 @@ func GroupProfiles(paths []string) ([]ProfileGroup, error) {
 		return result[i].Type < result[j].Type
 	})
 
-	return result, nil
+	return result, ctxErr
 }
 
 // detectProfileType 检测 profile 的类型
@@ -87,6 +142,11 @@ func detectProfileType(p *profile.Profile) string {
 		return "unknown"
 	}
 
+	// heap 和 allocs profile 的 sample type 相同，DefaultSampleType 为 alloc_* 或 inuse_* 时直接归为 heap
+	if isHeapSampleType(p.DefaultSampleType) {
+		return "heap"
+	}
+
 	// 检查 SampleType 来判断类型
 	if len(p.SampleType) > 0 {
 		for _, st := range p.SampleType {
@@ -101,8 +161,7 @@ func detectProfileType(p *profile.Profile) string {
 			}
 
 			// Heap/Memory profile
-			if typeLower == "alloc_objects" || typeLower == "alloc_space" ||
-				typeLower == "inuse_objects" || typeLower == "inuse_space" {
+			if isHeapSampleType(typeLower) {
 				return "heap"
 			}
 
@@ -111,6 +170,11 @@ func detectProfileType(p *profile.Profile) string {
 				return "goroutine"
 			}
 
+			// Threadcreate profile
+			if typeLower == "threadcreate" {
+				return "threadcreate"
+			}
+
 			// Block profile
 			if typeLower == "contentions" || typeLower == "delay" {
 				return "block"
@@ -130,3 +194,52 @@ func detectProfileType(p *profile.Profile) string {
 
 	return "unknown"
 }
+
+// isHeapSampleType 判断是否为 heap/allocs profile 的 sample type
+func isHeapSampleType(sampleType string) bool {
+	switch strings.ToLower(sampleType) {
+	case "alloc_objects", "alloc_space", "inuse_objects", "inuse_space":
+		return true
+	}
+	return false
+}
+
+// IsAllocsProfile 判断 heap 类 profile 是否来自 /debug/pprof/allocs
+// runtime 为 allocs profile 写入 DefaultSampleType "alloc_space"，heap profile 不写 (pprof 默认展示最后一个 inuse_space)；
+// 没有 DefaultSampleType 时，只有 alloc_* 而没有 inuse_* sample type 的 profile 也视为 allocs
+func IsAllocsProfile(p *profile.Profile) bool {
+	if p == nil {
+		return false
+	}
+	switch strings.ToLower(p.DefaultSampleType) {
+	case "alloc_space", "alloc_objects":
+		return true
+	case "":
+	default:
+		return false
+	}
+
+	hasAlloc := false
+	for _, st := range p.SampleType {
+		switch strings.ToLower(st.Type) {
+		case "alloc_space", "alloc_objects":
+			hasAlloc = true
+		case "inuse_space", "inuse_objects":
+			return false
+		}
+	}
+	return hasAlloc
+}
+
+// allAllocsProfiles 判断文件是否全部为 allocs profile，混合 heap 和 allocs 时按 heap 处理
+func allAllocsProfiles(files []ProfileFile) bool {
+	if len(files) == 0 {
+		return false
+	}
+	for _, file := range files {
+		if !IsAllocsProfile(file.Profile) {
+			return false
+		}
+	}
+	return true
+}
@@ -1,6 +1,8 @@
 package analyzer
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -80,6 +82,102 @@ func TestGroupProfiles(t *testing.T) {
 	assert.True(t, cpuGroup.Files[0].Time.Before(cpuGroup.Files[1].Time))
 }
 
+// TestGroupProfilesWithConcurrency 测试并行解析的结果与顺序解析一致
+func TestGroupProfilesWithConcurrency(t *testing.T) {
+	tempDir := t.TempDir()
+
+	base := time.Date(2023, 11, 15, 14, 0, 0, 0, time.UTC)
+	var paths []string
+	for i := 0; i < 8; i++ {
+		cpuFile := filepath.Join(tempDir, fmt.Sprintf("cpu%d.pprof", i))
+		heapFile := filepath.Join(tempDir, fmt.Sprintf("heap%d.pprof", i))
+		// 相同时间戳的文件保持输入顺序
+		createCPUProfile(t, cpuFile, base.Add(time.Duration(i/2)*time.Minute))
+		createHeapProfile(t, heapFile, base.Add(time.Duration(i)*time.Minute))
+		paths = append(paths, cpuFile, heapFile)
+	}
+	paths = append(paths, filepath.Join(tempDir, "missing.pprof"))
+
+	sequential, err := GroupProfiles(paths)
+	require.NoError(t, err)
+
+	for _, concurrency := range []int{0, 2, 4, 32} {
+		groups, err := GroupProfilesWithConcurrency(paths, concurrency)
+		require.NoError(t, err)
+		require.Len(t, groups, len(sequential))
+		for i := range groups {
+			assert.Equal(t, sequential[i].Type, groups[i].Type)
+			require.Len(t, groups[i].Files, len(sequential[i].Files))
+			for j := range groups[i].Files {
+				assert.Equal(t, sequential[i].Files[j].Path, groups[i].Files[j].Path, "concurrency %d", concurrency)
+				assert.Equal(t, sequential[i].Files[j].Metrics, groups[i].Files[j].Metrics)
+			}
+		}
+	}
+}
+
+// TestGroupProfilesCtx 测试已取消的 ctx 返回 context.Canceled，未取消时结果与 GroupProfiles 一致
+func TestGroupProfilesCtx(t *testing.T) {
+	tempDir := t.TempDir()
+	base := time.Date(2023, 11, 15, 14, 0, 0, 0, time.UTC)
+	var paths []string
+	for i := 0; i < 4; i++ {
+		heapFile := filepath.Join(tempDir, fmt.Sprintf("heap%d.pprof", i))
+		createHeapProfile(t, heapFile, base.Add(time.Duration(i)*time.Minute))
+		paths = append(paths, heapFile)
+	}
+
+	groups, err := GroupProfilesCtx(context.Background(), paths, 2)
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Len(t, groups[0].Files, 4)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, concurrency := range []int{1, 4} {
+		groups, err = GroupProfilesCtx(ctx, paths, concurrency)
+		assert.ErrorIs(t, err, context.Canceled)
+		for _, g := range groups {
+			assert.Less(t, len(g.Files), 4)
+		}
+	}
+}
+
+// TestExtractMetricsCtx 测试取消时不返回部分指标
+func TestExtractMetricsCtx(t *testing.T) {
+	p := newHeapProfile(newStackSample(1024, "main.alloc"))
+
+	metrics, err := ExtractMetricsCtx(context.Background(), p, "heap")
+	require.NoError(t, err)
+	assert.Equal(t, ExtractMetrics(p, "heap"), metrics)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	metrics, err = ExtractMetricsCtx(ctx, p, "heap")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, metrics)
+}
+
+func TestExtractMetrics_NoDuration(t *testing.T) {
+	p := newBenchCPUProfile(newCPUSample(4_000_000, "main.work"))
+
+	metrics := ExtractMetrics(p, "cpu")
+	assert.False(t, metrics.NoDuration)
+	assert.Equal(t, 4*time.Millisecond, metrics.CPUTime)
+
+	// 缺少采集时长时不计算绝对 CPU 时间，百分比不受影响
+	p.DurationNanos = 0
+	metrics = ExtractMetrics(p, "cpu")
+	assert.True(t, metrics.NoDuration)
+	assert.Zero(t, metrics.CPUTime)
+	require.NotEmpty(t, metrics.TopFunctions)
+	assert.Equal(t, "main.work", metrics.TopFunctions[0].Name)
+	assert.InDelta(t, 100.0, metrics.TopFunctions[0].FlatPct, 0.001)
+
+	// heap/goroutine 本来就是瞬时快照，不标记
+	assert.False(t, ExtractMetrics(newHeapProfile(newStackSample(1024, "main.alloc")), "heap").NoDuration)
+}
+
 func TestDetectProfileType(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -120,6 +218,28 @@ func TestDetectProfileType(t *testing.T) {
 			},
 			expected: "goroutine",
 		},
+		{
+			// DefaultSampleType 优先于 DurationNanos 和 sample type 的顺序
+			name: "allocs profile with duration",
+			profile: &profile.Profile{
+				DurationNanos: 1000000000,
+				SampleType: []*profile.ValueType{
+					{Type: "samples", Unit: "count"},
+					{Type: "alloc_space", Unit: "bytes"},
+				},
+				DefaultSampleType: "alloc_space",
+			},
+			expected: "heap",
+		},
+		{
+			name: "threadcreate profile",
+			profile: &profile.Profile{
+				SampleType: []*profile.ValueType{
+					{Type: "threadcreate", Unit: "count"},
+				},
+			},
+			expected: "threadcreate",
+		},
 		{
 			name: "cpu profile by duration",
 			profile: &profile.Profile{
@@ -137,6 +257,77 @@ func TestDetectProfileType(t *testing.T) {
 	}
 }
 
+// TestGroupProfiles_Allocs allocs profile 与 heap profile 的 sample type 相同，按 DefaultSampleType 标注为 allocs
+func TestGroupProfiles_Allocs(t *testing.T) {
+	tempDir := t.TempDir()
+	allocsFile := filepath.Join(tempDir, "allocs.pprof")
+	heapFile := filepath.Join(tempDir, "heap.pprof")
+	createAllocsProfile(t, allocsFile, time.Date(2023, 11, 15, 14, 30, 0, 0, time.UTC))
+	createHeapProfile(t, heapFile, time.Date(2023, 11, 15, 14, 35, 0, 0, time.UTC))
+
+	groups, err := GroupProfiles([]string{allocsFile})
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Equal(t, "heap", groups[0].Type)
+	assert.True(t, groups[0].Allocs)
+
+	// 与 heap profile 混合时按 heap 处理
+	groups, err = GroupProfiles([]string{allocsFile, heapFile})
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Len(t, groups[0].Files, 2)
+	assert.False(t, groups[0].Allocs)
+}
+
+// TestGroupProfiles_Threadcreate threadcreate profile 单独分组，统计线程数和线程创建调用点
+func TestGroupProfiles_Threadcreate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "threadcreate.pprof")
+	createThreadcreateProfile(t, path, time.Date(2023, 11, 15, 14, 30, 0, 0, time.UTC))
+
+	groups, err := GroupProfiles([]string{path})
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Equal(t, "threadcreate", groups[0].Type)
+
+	metrics := groups[0].Files[0].Metrics
+	require.NotNil(t, metrics)
+	assert.Equal(t, int64(12), metrics.ThreadCount)
+	// runtime 的线程创建函数出现在每个调用栈中，不计入创建调用点
+	require.NotEmpty(t, metrics.TopFunctions)
+	assert.Equal(t, "github.com/myapp/codec.Encode", metrics.TopFunctions[0].Name)
+	assert.Equal(t, int64(10), metrics.TopFunctions[0].Cum)
+	for _, fn := range metrics.TopFunctions {
+		assert.NotContains(t, fn.Name, "runtime.")
+	}
+}
+
+func TestIsAllocsProfile(t *testing.T) {
+	heapTypes := []*profile.ValueType{
+		{Type: "alloc_objects", Unit: "count"},
+		{Type: "alloc_space", Unit: "bytes"},
+		{Type: "inuse_objects", Unit: "count"},
+		{Type: "inuse_space", Unit: "bytes"},
+	}
+	tests := []struct {
+		name     string
+		profile  *profile.Profile
+		expected bool
+	}{
+		{"nil profile", nil, false},
+		{"heap profile", &profile.Profile{SampleType: heapTypes}, false},
+		{"allocs profile", &profile.Profile{SampleType: heapTypes, DefaultSampleType: "alloc_space"}, true},
+		{"default alloc_objects", &profile.Profile{SampleType: heapTypes, DefaultSampleType: "alloc_objects"}, true},
+		{"default inuse_space", &profile.Profile{SampleType: heapTypes, DefaultSampleType: "inuse_space"}, false},
+		{"alloc sample types only", &profile.Profile{SampleType: heapTypes[:2]}, true},
+		{"cpu profile", &profile.Profile{SampleType: []*profile.ValueType{{Type: "cpu", Unit: "nanoseconds"}}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsAllocsProfile(tt.profile))
+		})
+	}
+}
+
 // createValidProfile 创建带有指定时间戳的有效 pprof 文件
 func createValidProfile(t *testing.T, path string, timestamp time.Time) {
 	p := &profile.Profile{
@@ -200,3 +391,55 @@ func createHeapProfile(t *testing.T, path string, timestamp time.Time) {
 
 	require.NoError(t, p.Write(f))
 }
+
+// createAllocsProfile 创建 /debug/pprof/allocs 格式的 profile：sample type 与 heap 相同，DefaultSampleType 为 alloc_space
+func createAllocsProfile(t *testing.T, path string, timestamp time.Time) {
+	p := &profile.Profile{
+		TimeNanos:     timestamp.UnixNano(),
+		DurationNanos: 30 * int64(time.Second),
+		SampleType: []*profile.ValueType{
+			{Type: "alloc_objects", Unit: "count"},
+			{Type: "alloc_space", Unit: "bytes"},
+			{Type: "inuse_objects", Unit: "count"},
+			{Type: "inuse_space", Unit: "bytes"},
+		},
+		DefaultSampleType: "alloc_space",
+		Sample: []*profile.Sample{
+			{Value: []int64{10, 1024, 5, 512}},
+		},
+	}
+
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	require.NoError(t, p.Write(f))
+}
+
+// createThreadcreateProfile 创建 threadcreate profile：10 个线程由 cgo 调用触发，2 个由调度器创建
+func createThreadcreateProfile(t *testing.T, path string, timestamp time.Time) {
+	names := []string{"runtime.newm", "runtime.startm", "runtime.cgocall", "github.com/myapp/codec.Encode", "main.main"}
+	functions := make([]*profile.Function, len(names))
+	locations := make([]*profile.Location, len(names))
+	for i, name := range names {
+		functions[i] = &profile.Function{ID: uint64(i + 1), Name: name}
+		locations[i] = &profile.Location{ID: uint64(i + 1), Line: []profile.Line{{Function: functions[i]}}}
+	}
+	p := &profile.Profile{
+		TimeNanos:  timestamp.UnixNano(),
+		SampleType: []*profile.ValueType{{Type: "threadcreate", Unit: "count"}},
+		PeriodType: &profile.ValueType{Type: "threadcreate", Unit: "count"},
+		Sample: []*profile.Sample{
+			{Location: locations, Value: []int64{10}},
+			{Location: locations[:2], Value: []int64{2}},
+		},
+		Function: functions,
+		Location: locations,
+	}
+
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	require.NoError(t, p.Write(f))
+}
@@ -12,6 +12,20 @@ type HeapInsight struct {
 	Description string // 详细描述
 }
 
+// DefaultAllocChurnRatio alloc_space 达到 inuse_space 的这个倍数时视为高频短生命周期分配
+const DefaultAllocChurnRatio = 10.0
+
+// DefaultAllocChurnMinBytes 累计分配低于该值时不判断分配周转，避免小 profile 的噪声
+const DefaultAllocChurnMinBytes = 64 * 1024 * 1024
+
+// AllocChurnRatio 返回累计分配与使用中内存之比，inuse 为 0 时返回 0
+func AllocChurnRatio(alloc, inuse int64) float64 {
+	if inuse <= 0 {
+		return 0
+	}
+	return float64(alloc) / float64(inuse)
+}
+
 // AnalyzeHeapInsights 分析堆内存并生成洞察（只指出问题点，不给建议）
 func AnalyzeHeapInsights(metrics *ProfileMetrics) []HeapInsight {
 	var insights []HeapInsight
@@ -63,7 +77,17 @@ func AnalyzeHeapInsights(metrics *ProfileMetrics) []HeapInsight {
 		}
 	}
 
-	// 4. 指出 Top 内存占用函数（业务代码）
+	// 4. 分配周转：累计分配远超使用中内存，说明大量对象分配后很快被回收
+	if metrics.AllocSpace >= DefaultAllocChurnMinBytes && metrics.AllocRate >= DefaultAllocChurnRatio {
+		insights = append(insights, HeapInsight{
+			Level: "warning",
+			Title: "♻️  高频短生命周期分配",
+			Description: fmt.Sprintf("累计分配是使用中内存的 %.1f 倍 (%s / %s)，对象分配后很快被回收，GC 压力大，可用 sync.Pool 复用频繁创建的对象",
+				metrics.AllocRate, FormatBytes(metrics.AllocSpace), FormatBytes(metrics.InuseSpace)),
+		})
+	}
+
+	// 5. 指出 Top 内存占用函数（业务代码）
 	if len(metrics.TopFunctions) > 0 {
 		topFunc := metrics.TopFunctions[0]
 		funcName := topFunc.Name
@@ -0,0 +1,59 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// findInsight 按标题查找洞察，找不到时返回 nil
+func findInsight(insights []HeapInsight, title string) *HeapInsight {
+	for i := range insights {
+		if insights[i].Title == title {
+			return &insights[i]
+		}
+	}
+	return nil
+}
+
+func TestAllocChurnRatio(t *testing.T) {
+	assert.Equal(t, 20.0, AllocChurnRatio(2000, 100))
+	assert.Equal(t, 1.0, AllocChurnRatio(100, 100))
+	assert.Equal(t, 0.0, AllocChurnRatio(100, 0))
+}
+
+// TestExtractMetrics_AllocRate 提取 heap 指标时计算分配周转比
+func TestExtractMetrics_AllocRate(t *testing.T) {
+	p := newHeapProfile(
+		newHeapSample(1, "main.handle", 900, 10),
+		newHeapSample(2, "main.cache", 100, 40),
+	)
+	metrics := ExtractMetrics(p, "heap")
+	require.NotNil(t, metrics)
+	assert.Equal(t, 20.0, metrics.AllocRate)
+}
+
+// TestAnalyzeHeapInsights_AllocChurn 累计分配远超使用中内存时提示复用对象
+func TestAnalyzeHeapInsights_AllocChurn(t *testing.T) {
+	const mb = 1024 * 1024
+	const title = "♻️  高频短生命周期分配"
+
+	churn := &ProfileMetrics{AllocSpace: 2000 * mb, InuseSpace: 50 * mb}
+	churn.AllocRate = AllocChurnRatio(churn.AllocSpace, churn.InuseSpace)
+	insight := findInsight(AnalyzeHeapInsights(churn), title)
+	require.NotNil(t, insight)
+	assert.Equal(t, "warning", insight.Level)
+	assert.Contains(t, insight.Description, "40.0 倍")
+	assert.Contains(t, insight.Description, "sync.Pool")
+
+	// 分配与使用中内存相当：没有周转问题
+	steady := &ProfileMetrics{AllocSpace: 110 * mb, InuseSpace: 100 * mb}
+	steady.AllocRate = AllocChurnRatio(steady.AllocSpace, steady.InuseSpace)
+	assert.Nil(t, findInsight(AnalyzeHeapInsights(steady), title))
+
+	// 累计分配太少时不判断
+	small := &ProfileMetrics{AllocSpace: 10 * mb, InuseSpace: 100 * 1024}
+	small.AllocRate = AllocChurnRatio(small.AllocSpace, small.InuseSpace)
+	assert.Nil(t, findInsight(AnalyzeHeapInsights(small), title))
+}
@@ -1,6 +1,7 @@
 package analyzer
 
 import (
+	"context"
 	"sort"
 	"strings"
 	"time"
@@ -16,25 +17,72 @@ type ProfileMetrics struct {
 	Duration     time.Duration
 	NumLocations int
 	NumFunctions int
+	GoVersion    string // profile 对应的 Go 版本（来自注释或推断），未知时为空
+	// 按代码分类汇总的样本值，由 ComputeCategoryTotals 填充，未计算时为 nil
+	CategoryTotals map[string]int64
+	// 基准测试模式下每次操作的消耗，由 ApplyBenchMode 填充，其他模式为 nil
+	Bench *BenchStats
+	// 无法识别类型的 profile 使用的 sample type 和单位，由 ApplyValueType 填充，未指定时为空
+	ValueType string
+	ValueUnit string
 
 	// CPU 指标
-	CPUTime time.Duration
+	CPUTime    time.Duration // 缺少采集时长时为 0，见 NoDuration
+	GCFraction float64       // 调用栈包含 GC 帧的 CPU 时间占比
+	// 按分配点汇总的 GC 辅助标记消耗 (仅 cpu profile)
+	GCAssistSites []GCAssistSite
+	// 按栈顶函数所在包汇总的 CPU 时间前 DefaultTopPackagesN 名 (仅 cpu profile)
+	TopPackages []PackageStat
+	// CPU profile 缺少采集时长 (DurationNanos 为 0)，一些工具导出的 profile 会这样
+	// 此时绝对 CPU 时间没有参照，不计算 CPUTime，只保留样本百分比
+	NoDuration bool
 
 	// Heap 指标
 	AllocObjects int64
 	AllocSpace   int64 // bytes
 	InuseObjects int64
 	InuseSpace   int64 // bytes
+	// 累计分配与使用中内存之比 (alloc_space / inuse_space)，越大说明短生命周期分配越多，inuse_space 为 0 时为 0
+	AllocRate float64
+	// 按包汇总的 inuse/alloc 保留情况 (仅 heap profile)
+	PackageRetention []PackageRetention
+	// 按包汇总的内存占用前 DefaultPackageMemoryTopN 名，由 ComputeCategoryTotals 填充 (仅 heap profile)
+	PackageMemory []PackageMemory
+	// 按调用点汇总的 string/[]byte 转换分配 (仅 heap profile)
+	ConversionHotspots []ConversionHotspot
+	// 按调用点汇总的反射开销 (cpu 为 CPU 时间，heap 为 alloc_space)
+	ReflectionHotspots []ReflectionHotspot
+	// 按分配点汇总的累计分配次数和字节数 (仅 heap profile)
+	AllocationSites []AllocationSite
+	// 按调用点汇总的 channel 内存 (仅 heap profile)
+	ChannelAllocations []ChannelAllocation
 
 	// Goroutine 指标
 	GoroutineCount int64
+	// 按状态 (如 chan receive、select、IO wait、running) 汇总的 goroutine 数，profile 没有可用的状态信息时为空
+	GoroutineStates map[string]int
+	// 按操作和调用点汇总的 channel 阻塞 (仅 goroutine profile)
+	ChannelBlocks []ChannelBlockSite
+
+	// Threadcreate 指标
+	ThreadCount int64 // profile 记录的 OS 线程创建数 (线程创建后不会退出，即进程的线程数)
+
+	// Block 指标 (runtime.SetBlockProfileRate 采集的 block profile)
+	Contentions int64         // 阻塞次数
+	BlockDelay  time.Duration // 累计阻塞时间
 
 	// Top 函数 (基于 inuse_space)
 	TopFunctions []FunctionStat
 	// Top 函数 (基于 alloc_space，用于 heap profile)
 	TopAllocFunctions []FunctionStat
+	// 按 flat 排名的 Top 函数，用于比较快照间的排名变化
+	// (CPU 为 CPU 时间，heap 为 alloc_space，仅 cpu/heap profile)
+	TopFlatFunctions []FunctionStat
 }
 
+// MaxTopFunctions 每个 profile 提取的 Top 函数数上限，报告再按 -top-functions 截断展示
+const MaxTopFunctions = 50
+
 // FunctionStat 函数统计
 type FunctionStat struct {
 	Name    string
@@ -46,13 +94,21 @@ type FunctionStat struct {
 
 // ExtractMetrics 从 profile 中提取性能指标
 func ExtractMetrics(p *profile.Profile, profileType string) *ProfileMetrics {
+	metrics, _ := ExtractMetricsCtx(context.Background(), p, profileType)
+	return metrics
+}
+
+// ExtractMetricsCtx 从 profile 中提取性能指标，ctx 取消或超时时返回 nil 和 ctx.Err()
+// 样本计数循环中逐样本检查取消，各项指标的提取（每项遍历一次样本）之间也会检查
+func ExtractMetricsCtx(ctx context.Context, p *profile.Profile, profileType string) (*ProfileMetrics, error) {
 	if p == nil {
-		return nil
+		return nil, nil
 	}
 
 	metrics := &ProfileMetrics{
 		NumLocations: len(p.Location),
 		NumFunctions: len(p.Function),
+		GoVersion:    DetectGoVersion(p),
 	}
 
 	if p.DurationNanos > 0 {
@@ -60,59 +116,106 @@ func ExtractMetrics(p *profile.Profile, profileType string) *ProfileMetrics {
 	}
 
 	// 计算总样本数和总值
-	for _, sample := range p.Sample {
+	for i, sample := range p.Sample {
+		if err := CheckCanceled(ctx, i); err != nil {
+			return nil, err
+		}
 		metrics.TotalSamples++
 		if len(sample.Value) > 0 {
 			metrics.TotalValue += sample.Value[0]
 		}
 	}
 
-	// 根据类型提取特定指标
+	// 根据类型提取特定指标，每一步遍历一次样本
+	var steps []func()
 	switch profileType {
 	case "cpu":
-		metrics.CPUTime = extractCPUTime(p)
-		metrics.TopFunctions = extractTopFunctions(p, 10, 1) // CPU 时间在 index 1
+		metrics.NoDuration = metrics.Duration == 0
+		// CPU 时间通常在 index 1；只有样本次数时按 Period 换算
+		cpuIndex, scale := cpuValueIndex(p)
+		if cpuIndex < 0 {
+			cpuIndex, scale = 1, 1
+		}
+		steps = []func(){
+			func() {
+				if !metrics.NoDuration {
+					metrics.CPUTime = extractCPUTime(p)
+				}
+			},
+			func() {
+				metrics.TopFunctions = scaleFunctionStats(extractTopFunctions(p, MaxTopFunctions, cpuIndex), scale)
+			},
+			func() {
+				metrics.TopFlatFunctions = scaleFunctionStats(extractTopFlatFunctions(p, DefaultNewTopN, cpuIndex), scale)
+			},
+			func() { metrics.GCFraction = gcSampleFraction(p, NewRuntimeFrameMatcher(metrics.GoVersion), cpuIndex) },
+			func() { metrics.TopPackages = extractTopPackages(p, DefaultTopPackagesN, cpuIndex, scale) },
+			func() { metrics.GCAssistSites = extractGCAssistSites(p) },
+			func() { metrics.ReflectionHotspots = extractReflectionHotspots(p, profileType) },
+		}
 	case "heap":
-		metrics.AllocObjects, metrics.AllocSpace, metrics.InuseObjects, metrics.InuseSpace = extractHeapMetrics(p)
-		// 提取两个维度的 Top 函数
-		metrics.TopFunctions = extractTopFunctions(p, 10, 3)      // inuse_space 在 index 3
-		metrics.TopAllocFunctions = extractTopFunctions(p, 10, 1) // alloc_space 在 index 1
+		steps = []func(){
+			func() {
+				metrics.AllocObjects, metrics.AllocSpace, metrics.InuseObjects, metrics.InuseSpace = extractHeapMetrics(p)
+				metrics.AllocRate = AllocChurnRatio(metrics.AllocSpace, metrics.InuseSpace)
+			},
+			// 提取两个维度的 Top 函数
+			func() { metrics.TopFunctions = extractTopFunctions(p, MaxTopFunctions, 3) },      // inuse_space 在 index 3
+			func() { metrics.TopAllocFunctions = extractTopFunctions(p, MaxTopFunctions, 1) }, // alloc_space 在 index 1
+			func() { metrics.TopFlatFunctions = extractTopFlatFunctions(p, DefaultNewTopN, 1) },
+			func() { metrics.PackageRetention = extractPackageRetention(p) },
+			func() { metrics.ConversionHotspots = extractConversionHotspots(p) },
+			func() { metrics.ReflectionHotspots = extractReflectionHotspots(p, profileType) },
+			func() { metrics.AllocationSites = extractAllocationSites(p) },
+			func() { metrics.ChannelAllocations = extractChannelAllocations(p) },
+		}
 	case "goroutine":
-		metrics.GoroutineCount = extractGoroutineCount(p)
-		metrics.TopFunctions = extractTopFunctions(p, 10, 0)
+		steps = []func(){
+			func() { metrics.GoroutineCount = extractGoroutineCount(p) },
+			func() { metrics.GoroutineStates = extractGoroutineStates(p) },
+			func() { metrics.ChannelBlocks = extractChannelBlocks(p) },
+			func() { metrics.TopFunctions = extractTopFunctions(p, MaxTopFunctions, 0) },
+		}
+	case "threadcreate":
+		steps = []func(){
+			func() { metrics.ThreadCount = extractThreadCount(p) },
+			func() { metrics.TopFunctions = extractTopFunctions(p, MaxTopFunctions, 0) },
+		}
+	case "block":
+		steps = []func(){
+			func() { metrics.Contentions, metrics.BlockDelay = extractBlockMetrics(p) },
+			func() { metrics.TopFunctions = extractTopFunctions(p, MaxTopFunctions, blockValueIndex(p)) },
+		}
 	default:
-		metrics.TopFunctions = extractTopFunctions(p, 10, 0)
+		steps = []func(){
+			func() { metrics.TopFunctions = extractTopFunctions(p, MaxTopFunctions, 0) },
+		}
 	}
 
-	return metrics
-}
-
-// extractCPUTime 提取 CPU 时间
-func extractCPUTime(p *profile.Profile) time.Duration {
-	var totalNanos int64
-
-	// 查找 CPU 时间的 sample type index
-	cpuIndex := -1
-	for i, st := range p.SampleType {
-		if st.Type == "cpu" && st.Unit == "nanoseconds" {
-			cpuIndex = i
-			break
+	for _, step := range steps {
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
+		step()
 	}
 
-	if cpuIndex == -1 && len(p.SampleType) > 1 {
-		cpuIndex = 1 // 默认第二列是 CPU 时间
+	return metrics, nil
+}
+
+// extractCPUTime 提取 CPU 时间，只有样本次数的 profile 按 Period 换算 (见 cpuValueIndex)
+func extractCPUTime(p *profile.Profile) time.Duration {
+	cpuIndex, scale := cpuValueIndex(p)
+	if cpuIndex < 0 {
+		return 0
 	}
 
-	if cpuIndex >= 0 {
-		for _, sample := range p.Sample {
-			if cpuIndex < len(sample.Value) {
-				totalNanos += sample.Value[cpuIndex]
-			}
+	var totalNanos int64
+	for _, sample := range p.Sample {
+		if cpuIndex < len(sample.Value) {
+			totalNanos += sample.Value[cpuIndex]
 		}
 	}
-
-	return time.Duration(totalNanos)
+	return time.Duration(totalNanos * scale)
 }
 
 // extractHeapMetrics 提取堆内存指标
@@ -152,8 +255,60 @@ func extractGoroutineCount(p *profile.Profile) int64 {
 	return count
 }
 
-// extractTopFunctions 提取 Top N 函数
+// extractThreadCount 提取 OS 线程创建数，每个样本的值为在该调用栈上创建的线程数
+func extractThreadCount(p *profile.Profile) int64 {
+	var count int64
+	for _, sample := range p.Sample {
+		if len(sample.Value) > 0 {
+			count += sample.Value[0]
+		}
+	}
+	return count
+}
+
+// extractTopFunctions 提取 Top N 函数 (按 cum 排序)
 func extractTopFunctions(p *profile.Profile, n int, valueIndex int) []FunctionStat {
+	stats := functionStats(p, valueIndex)
+
+	// 按 cum 值降序排序（对于 goroutine profile 更有意义）
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Cum > stats[j].Cum
+	})
+
+	// 取 Top N
+	if len(stats) > n {
+		stats = stats[:n]
+	}
+
+	return stats
+}
+
+// extractTopFlatFunctions 提取 flat 值最高的 N 个函数，跳过 flat 为 0 的函数
+// 值相同时按函数名排序，保证排名稳定
+func extractTopFlatFunctions(p *profile.Profile, n int, valueIndex int) []FunctionStat {
+	var stats []FunctionStat
+	for _, stat := range functionStats(p, valueIndex) {
+		if stat.Flat > 0 {
+			stats = append(stats, stat)
+		}
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Flat != stats[j].Flat {
+			return stats[i].Flat > stats[j].Flat
+		}
+		return stats[i].Name < stats[j].Name
+	})
+
+	if len(stats) > n {
+		stats = stats[:n]
+	}
+
+	return stats
+}
+
+// functionStats 计算每个函数的 flat 和 cum 值，结果未排序
+func functionStats(p *profile.Profile, valueIndex int) []FunctionStat {
 	if p == nil || len(p.Sample) == 0 {
 		return nil
 	}
@@ -172,7 +327,8 @@ func extractTopFunctions(p *profile.Profile, n int, valueIndex int) []FunctionSt
 		value := sample.Value[valueIndex]
 		totalValue += value
 
-		// 遍历调用栈
+		// 遍历调用栈，递归出现多次的函数 cum 只计一次，保证 CumPct 不超过 100%
+		seen := make(map[uint64]bool)
 		for i, loc := range sample.Location {
 			if loc == nil {
 				continue
@@ -184,8 +340,11 @@ func extractTopFunctions(p *profile.Profile, n int, valueIndex int) []FunctionSt
 				funcID := line.Function.ID
 				funcMap[funcID] = line.Function
 
-				// Cum: 所有出现的位置都计入
-				cumMap[funcID] += value
+				// Cum: 出现在调用栈中的样本都计入
+				if !seen[funcID] {
+					seen[funcID] = true
+					cumMap[funcID] += value
+				}
 
 				// Flat: 只有栈顶（第一个位置）计入
 				// 但对于 goroutine profile，我们使用 cum 值来展示所有调用路径
@@ -199,10 +358,11 @@ func extractTopFunctions(p *profile.Profile, n int, valueIndex int) []FunctionSt
 	// 转换为切片并排序
 	var stats []FunctionStat
 
-	// 对于 goroutine profile，我们需要过滤掉 runtime 函数，展示业务相关的调用
-	isGoroutineProfile := false
-	if len(p.SampleType) > 0 && p.SampleType[0].Type == "goroutine" {
-		isGoroutineProfile = true
+	// 对于 goroutine 和 threadcreate profile，我们需要过滤掉 runtime 函数，展示业务相关的调用
+	// (goroutine 阻塞点和线程创建点所在的 runtime 函数出现在每个调用栈中)
+	skipRuntime := false
+	if len(p.SampleType) > 0 && (p.SampleType[0].Type == "goroutine" || p.SampleType[0].Type == "threadcreate") {
+		skipRuntime = true
 	}
 
 	for funcID, cum := range cumMap {
@@ -216,8 +376,8 @@ func extractTopFunctions(p *profile.Profile, n int, valueIndex int) []FunctionSt
 			name = "<unknown>"
 		}
 
-		// 对于 goroutine profile，过滤掉纯 runtime 函数
-		if isGoroutineProfile {
+		// 对于 goroutine 和 threadcreate profile，过滤掉纯 runtime 函数
+		if skipRuntime {
 			if strings.HasPrefix(name, "runtime.") ||
 				strings.HasPrefix(name, "runtime/") {
 				continue
@@ -241,16 +401,6 @@ func extractTopFunctions(p *profile.Profile, n int, valueIndex int) []FunctionSt
 		})
 	}
 
-	// 按 cum 值降序排序（对于 goroutine profile 更有意义）
-	sort.Slice(stats, func(i, j int) bool {
-		return stats[i].Cum > stats[j].Cum
-	})
-
-	// 取 Top N
-	if len(stats) > n {
-		stats = stats[:n]
-	}
-
 	return stats
 }
 
@@ -0,0 +1,23 @@
+package analyzer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExtractMetrics_TopFunctionsLimit Top 函数最多提取 MaxTopFunctions 个，供报告按 -top-functions 截断
+func TestExtractMetrics_TopFunctionsLimit(t *testing.T) {
+	var samples []*profile.Sample
+	for i := 1; i <= MaxTopFunctions+10; i++ {
+		samples = append(samples, newHeapSample(uint64(i), fmt.Sprintf("main.fn%d", i), int64(i*100), int64(i*10)))
+	}
+	metrics := ExtractMetrics(newHeapProfile(samples...), "heap")
+	require.NotNil(t, metrics)
+	assert.Len(t, metrics.TopFunctions, MaxTopFunctions)
+	assert.Len(t, metrics.TopAllocFunctions, MaxTopFunctions)
+	assert.Equal(t, fmt.Sprintf("main.fn%d", MaxTopFunctions+10), metrics.TopFunctions[0].Name)
+}
@@ -0,0 +1,74 @@
+package analyzer
+
+// 新进入 Top-N 检测的默认参数
+const (
+	DefaultNewTopN      = 20  // 比较 flat 排名前 20 的函数
+	DefaultNewTopMinPct = 5.0 // 新进入的函数 flat 占比达到 5% 才报告，过滤排名末尾的抖动
+)
+
+// NewTopFunction 最新快照中新进入 Top-N 的函数
+type NewTopFunction struct {
+	Name    string
+	Rank    int     // 在最新快照中的排名，从 1 开始
+	Flat    int64   // 最新快照中的 flat 值
+	FlatPct float64 // 最新快照中的 flat 占比 (0-100)
+}
+
+// NewTopConfig 新进入 Top-N 检测配置
+type NewTopConfig struct {
+	TopN   int     // 比较的排名范围
+	MinPct float64 // 报告的最小 flat 占比
+}
+
+// DefaultNewTopConfig 返回默认的新进入 Top-N 检测配置
+func DefaultNewTopConfig() NewTopConfig {
+	return NewTopConfig{
+		TopN:   DefaultNewTopN,
+		MinPct: DefaultNewTopMinPct,
+	}
+}
+
+// DetectNewTopFunctions 找出最新快照 Top-N 中、在所有更早快照的 Top-N 里都没有出现过的函数
+// 绝对阈值只能发现消耗已经很高的函数，排名的突变（之前不在前 20，现在排第 1）
+// 能更早暴露新引入的回归。至少需要两个快照，结果按最新排名排序
+func DetectNewTopFunctions(files []ProfileFile, config NewTopConfig) []NewTopFunction {
+	if len(files) < 2 {
+		return nil
+	}
+	latest := files[len(files)-1].Metrics
+	if latest == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	compared := 0
+	for _, file := range files[:len(files)-1] {
+		if file.Metrics == nil {
+			continue
+		}
+		compared++
+		for _, fn := range topN(file.Metrics.TopFlatFunctions, config.TopN) {
+			seen[fn.Name] = true
+		}
+	}
+	if compared == 0 {
+		return nil
+	}
+
+	var result []NewTopFunction
+	for i, fn := range topN(latest.TopFlatFunctions, config.TopN) {
+		if seen[fn.Name] || fn.FlatPct < config.MinPct {
+			continue
+		}
+		result = append(result, NewTopFunction{Name: fn.Name, Rank: i + 1, Flat: fn.Flat, FlatPct: fn.FlatPct})
+	}
+	return result
+}
+
+// topN 返回前 n 个函数，n <= 0 时返回全部
+func topN(functions []FunctionStat, n int) []FunctionStat {
+	if n > 0 && len(functions) > n {
+		return functions[:n]
+	}
+	return functions
+}
@@ -0,0 +1,91 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRankedFile 创建按 flat 排名的文件，names 从高到低排列，占比依次为 40%、30%、...
+func newRankedFile(names ...string) ProfileFile {
+	metrics := &ProfileMetrics{}
+	for i, name := range names {
+		pct := float64(40 - i*10)
+		metrics.TopFlatFunctions = append(metrics.TopFlatFunctions, FunctionStat{Name: name, Flat: int64(pct), FlatPct: pct})
+	}
+	return ProfileFile{Metrics: metrics}
+}
+
+func TestExtractTopFlatFunctions(t *testing.T) {
+	mainFn := &profile.Function{ID: 1, Name: "main.main"}
+	sample := func(id uint64, name string, value int64) *profile.Sample {
+		leaf := &profile.Function{ID: id, Name: name}
+		return &profile.Sample{
+			Location: []*profile.Location{
+				{ID: id, Line: []profile.Line{{Function: leaf}}},
+				{ID: 1, Line: []profile.Line{{Function: mainFn}}},
+			},
+			Value: []int64{1, value},
+		}
+	}
+	p := &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "samples"}, {Type: "cpu"}},
+		Sample: []*profile.Sample{
+			sample(2, "main.leaf", 50),
+			sample(3, "main.tie", 30),
+			sample(4, "main.other", 30),
+		},
+	}
+
+	stats := extractTopFlatFunctions(p, 2, 1)
+	require.Len(t, stats, 2)
+	// main.main 只在调用栈中间，flat 为 0 不参与排名
+	assert.Equal(t, "main.leaf", stats[0].Name)
+	// flat 相同时按函数名排序
+	assert.Equal(t, "main.other", stats[1].Name)
+
+	metrics := ExtractMetrics(p, "cpu")
+	require.NotNil(t, metrics)
+	assert.Len(t, metrics.TopFlatFunctions, 3)
+	// TopFunctions 仍按 cum 排序
+	assert.Equal(t, "main.main", metrics.TopFunctions[0].Name)
+}
+
+func TestDetectNewTopFunctions(t *testing.T) {
+	config := NewTopConfig{TopN: 2, MinPct: 5}
+
+	t.Run("function newly entering top-N", func(t *testing.T) {
+		files := []ProfileFile{
+			newRankedFile("main.a", "main.b", "main.c"),
+			newRankedFile("main.b", "main.a"),
+			newRankedFile("main.c", "main.a"),
+		}
+		// main.c 之前排第 3，不在前 2 名内
+		result := DetectNewTopFunctions(files, config)
+		require.Len(t, result, 1)
+		assert.Equal(t, NewTopFunction{Name: "main.c", Rank: 1, Flat: 40, FlatPct: 40}, result[0])
+	})
+
+	t.Run("stable ranking", func(t *testing.T) {
+		files := []ProfileFile{
+			newRankedFile("main.a", "main.b"),
+			newRankedFile("main.b", "main.a"),
+		}
+		assert.Empty(t, DetectNewTopFunctions(files, config))
+	})
+
+	t.Run("minor new functions are ignored", func(t *testing.T) {
+		files := []ProfileFile{
+			newRankedFile("main.a"),
+			newRankedFile("main.a", "main.b"),
+		}
+		assert.Empty(t, DetectNewTopFunctions(files, NewTopConfig{TopN: 2, MinPct: 35}))
+	})
+
+	t.Run("needs an earlier snapshot", func(t *testing.T) {
+		assert.Nil(t, DetectNewTopFunctions([]ProfileFile{newRankedFile("main.a")}, config))
+		assert.Nil(t, DetectNewTopFunctions([]ProfileFile{{}, newRankedFile("main.a")}, config))
+	})
+}
@@ -0,0 +1,183 @@
+package analyzer
+
+import (
+	"math"
+	"time"
+)
+
+// 离群点检测参数
+const (
+	// MinOutlierPoints 离群点检测至少需要的数据点数，点太少时留一法拟合没有意义
+	MinOutlierPoints = 5
+	// OutlierThreshold 预测误差超过其余点残差标准差的倍数时判为离群
+	OutlierThreshold = 3.0
+	// OutlierMinDeviation 预测误差至少达到数据均值绝对值的比例，避免把平稳序列中的微小抖动判为离群
+	OutlierMinDeviation = 0.05
+)
+
+// TrendOutlier 破坏趋势拟合的离群快照
+type TrendOutlier struct {
+	Index    int       // 数据点索引 (按文件顺序)
+	Time     time.Time // 快照时间
+	Value    float64   // 实际值
+	Expected float64   // 排除该点后拟合直线的预测值
+}
+
+// DetectTrendOutliers 使用留一法检测离群数据点，返回按顺序排列的索引
+// 对每个点，用其余点拟合直线，预测误差超过其余点残差标准差的 OutlierThreshold 倍
+// 且超过均值的 OutlierMinDeviation 时判为离群；超过 1/3 的点离群时视为整体噪声，不报告
+// weights 为空时不加权
+func DetectTrendOutliers(values, weights []float64) []int {
+	outliers, _ := detectTrendOutliers(values, weights)
+	return outliers
+}
+
+// detectTrendOutliers 返回离群点索引及其留一法预测值
+func detectTrendOutliers(values, weights []float64) ([]int, map[int]float64) {
+	n := len(values)
+	if n < MinOutlierPoints {
+		return nil, nil
+	}
+	if len(weights) != n {
+		weights = nil
+	}
+
+	var meanAbs float64
+	for _, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, nil
+		}
+		meanAbs += math.Abs(v)
+	}
+	meanAbs /= float64(n)
+
+	var outliers []int
+	expected := make(map[int]float64)
+	for i := range values {
+		xs, ys, ws := excludePoints(values, weights, map[int]bool{i: true})
+		slope, intercept, _, ok := fitLine(xs, ys, ws)
+		if !ok {
+			continue
+		}
+
+		// 其余点的 (加权) 残差标准差，自由度为 m-2
+		var ssRes, sumW float64
+		for j, x := range xs {
+			w := 1.0
+			if ws != nil {
+				w = ws[j]
+			}
+			e := ys[j] - (slope*x + intercept)
+			ssRes += w * e * e
+			sumW += w
+		}
+		m := float64(len(xs))
+		var sd float64
+		if sumW > 0 {
+			sd = math.Sqrt(ssRes / sumW * m / (m - 2))
+		}
+
+		predicted := slope*float64(i) + intercept
+		deviation := math.Abs(values[i] - predicted)
+		if deviation > OutlierThreshold*sd && deviation > OutlierMinDeviation*meanAbs {
+			outliers = append(outliers, i)
+			expected[i] = predicted
+		}
+	}
+
+	if len(outliers) == 0 || len(outliers)*3 > n {
+		return nil, nil
+	}
+	return outliers, expected
+}
+
+// trendWithoutOutliers 排除离群点后重新拟合，x 坐标保持原始索引，斜率与原拟合可比
+func trendWithoutOutliers(values, weights []float64, weighting string, outliers []int) *TrendMetrics {
+	excluded := make(map[int]bool, len(outliers))
+	for _, i := range outliers {
+		excluded[i] = true
+	}
+	xs, ys, ws := excludePoints(values, weights, excluded)
+	slope, _, r2, ok := fitLine(xs, ys, ws)
+	if !ok {
+		return nil
+	}
+
+	return &TrendMetrics{
+		Slope:     slope,
+		R2:        r2,
+		Direction: getDirection(slope),
+		Points:    len(ys),
+		Weighting: weighting,
+		Weights:   ws,
+	}
+}
+
+// excludePoints 返回去掉指定索引后的 x、y 和权重
+func excludePoints(values, weights []float64, excluded map[int]bool) (xs, ys, ws []float64) {
+	for i, v := range values {
+		if excluded[i] {
+			continue
+		}
+		xs = append(xs, float64(i))
+		ys = append(ys, v)
+		if weights != nil {
+			ws = append(ws, weights[i])
+		}
+	}
+	return xs, ys, ws
+}
+
+// fitLine 对任意 x 坐标做 (加权) 最小二乘拟合，ws 为空时不加权
+func fitLine(xs, ys, ws []float64) (slope, intercept, r2 float64, ok bool) {
+	if len(xs) < 2 || len(xs) != len(ys) {
+		return 0, 0, 0, false
+	}
+	weight := func(i int) float64 {
+		if ws == nil {
+			return 1
+		}
+		return ws[i]
+	}
+
+	var sumW, sumWX, sumWY float64
+	for i := range xs {
+		w := weight(i)
+		sumW += w
+		sumWX += w * xs[i]
+		sumWY += w * ys[i]
+	}
+	if sumW <= 0 {
+		return 0, 0, 0, false
+	}
+	meanX := sumWX / sumW
+	meanY := sumWY / sumW
+
+	var numerator, denominator float64
+	for i := range xs {
+		dx := xs[i] - meanX
+		numerator += weight(i) * dx * (ys[i] - meanY)
+		denominator += weight(i) * dx * dx
+	}
+	if denominator == 0 {
+		return 0, 0, 0, false
+	}
+	slope = numerator / denominator
+	intercept = meanY - slope*meanX
+
+	var ssRes, ssTot float64
+	for i := range xs {
+		e := ys[i] - (slope*xs[i] + intercept)
+		ssRes += weight(i) * e * e
+		ssTot += weight(i) * (ys[i] - meanY) * (ys[i] - meanY)
+	}
+	if ssTot == 0 {
+		r2 = 1
+	} else {
+		r2 = math.Max(0, math.Min(1, 1-ssRes/ssTot))
+	}
+	if math.IsNaN(slope) || math.IsInf(slope, 0) || math.IsNaN(r2) {
+		return 0, 0, 0, false
+	}
+	return slope, intercept, r2, true
+}
@@ -0,0 +1,97 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectTrendOutliers(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []float64
+		expected []int
+	}{
+		{name: "clean line", values: []float64{100, 110, 120, 130, 140, 150}},
+		{name: "spike in the middle", values: []float64{100, 110, 400, 130, 140, 150}, expected: []int{2}},
+		{name: "dip at the end", values: []float64{100, 110, 120, 130, 140, 20}, expected: []int{5}},
+		{name: "too few points", values: []float64{100, 110, 400, 130}},
+		{name: "small jitter on flat series", values: []float64{1000, 1001, 999, 1000, 1010, 1000}},
+		{name: "noisy series", values: []float64{100, 300, 50, 280, 90, 310}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DetectTrendOutliers(tt.values, nil))
+		})
+	}
+}
+
+func TestDetectTrendOutliers_Weighted(t *testing.T) {
+	values := []float64{100, 110, 400, 130, 140, 150}
+	weights := []float64{30, 30, 30, 30, 30, 30}
+	assert.Equal(t, []int{2}, DetectTrendOutliers(values, weights))
+
+	// 权重长度不一致时退化为不加权
+	assert.Equal(t, []int{2}, DetectTrendOutliers(values, []float64{1}))
+}
+
+func TestCalculateTrends_Outliers(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	counts := []int64{100, 110, 400, 130, 140, 150}
+	group := ProfileGroup{Type: "goroutine"}
+	for i, count := range counts {
+		group.Files = append(group.Files, ProfileFile{
+			Time:    base.Add(time.Duration(i) * time.Minute),
+			Metrics: &ProfileMetrics{GoroutineCount: count},
+		})
+	}
+
+	trends := CalculateTrends(group)
+	require.NotNil(t, trends)
+	trend := trends.GoroutineCount
+	require.NotNil(t, trend)
+
+	// 离群快照拉低了整体拟合的 R²
+	assert.Less(t, trend.R2, 0.2)
+
+	require.Len(t, trend.Outliers, 1)
+	outlier := trend.Outliers[0]
+	assert.Equal(t, 2, outlier.Index)
+	assert.Equal(t, base.Add(2*time.Minute), outlier.Time)
+	assert.Equal(t, 400.0, outlier.Value)
+	assert.InDelta(t, 120.0, outlier.Expected, 0.001)
+
+	require.NotNil(t, trend.WithoutOutliers)
+	assert.InDelta(t, 10.0, trend.WithoutOutliers.Slope, 0.001)
+	assert.InDelta(t, 1.0, trend.WithoutOutliers.R2, 0.001)
+	assert.Equal(t, "increasing", trend.WithoutOutliers.Direction)
+}
+
+func TestCalculateTrends_NoOutliers(t *testing.T) {
+	group := ProfileGroup{Type: "goroutine"}
+	for i := 0; i < 6; i++ {
+		group.Files = append(group.Files, ProfileFile{Metrics: &ProfileMetrics{GoroutineCount: int64(100 + i*10)}})
+	}
+
+	trend := CalculateTrends(group).GoroutineCount
+	require.NotNil(t, trend)
+	assert.Empty(t, trend.Outliers)
+	assert.Nil(t, trend.WithoutOutliers)
+}
+
+func TestFitLine(t *testing.T) {
+	// x 不连续时仍按原始坐标拟合
+	slope, intercept, r2, ok := fitLine([]float64{0, 1, 3, 4}, []float64{1, 3, 7, 9}, nil)
+	require.True(t, ok)
+	assert.InDelta(t, 2.0, slope, 0.001)
+	assert.InDelta(t, 1.0, intercept, 0.001)
+	assert.InDelta(t, 1.0, r2, 0.001)
+
+	_, _, _, ok = fitLine([]float64{1}, []float64{1}, nil)
+	assert.False(t, ok)
+	_, _, _, ok = fitLine([]float64{2, 2}, []float64{1, 5}, nil)
+	assert.False(t, ok)
+}
@@ -0,0 +1,129 @@
+package analyzer
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/google/pprof/profile"
+)
+
+// DefaultOversizedMinAvgSize 单次分配平均大小达到 1MB 视为超大分配
+const DefaultOversizedMinAvgSize = 1 * 1024 * 1024
+
+// AllocationSite 单个分配点的累计分配情况（按栈顶第一个非 runtime 函数统计）
+type AllocationSite struct {
+	Function      string // 执行分配的函数
+	BusinessFrame string // 调用链中最接近分配点的业务帧，没有时为空
+	AllocObjects  int64  // 累计分配次数
+	AllocSpace    int64  // 累计分配字节数
+	AvgSize       int64  // AllocSpace / AllocObjects
+}
+
+// Caller 返回用于展示的责任帧：优先业务帧，没有时返回分配函数本身
+func (s AllocationSite) Caller() string {
+	if s.BusinessFrame != "" {
+		return s.BusinessFrame
+	}
+	return s.Function
+}
+
+// DetectOversizedAllocations 找出平均单次分配达到 minAvgSize 的分配点
+// 大小分布只能说明存在大对象，这里定位到具体分配点，便于预分配容量或改为流式处理。
+// 结果按平均大小降序排列
+func DetectOversizedAllocations(metrics *ProfileMetrics, minAvgSize int64) []AllocationSite {
+	if metrics == nil {
+		return nil
+	}
+
+	var oversized []AllocationSite
+	for _, site := range metrics.AllocationSites {
+		if site.AllocObjects > 0 && site.AvgSize >= minAvgSize {
+			oversized = append(oversized, site)
+		}
+	}
+
+	sort.Slice(oversized, func(i, j int) bool {
+		if oversized[i].AvgSize != oversized[j].AvgSize {
+			return oversized[i].AvgSize > oversized[j].AvgSize
+		}
+		return oversized[i].Function < oversized[j].Function
+	})
+	return oversized
+}
+
+// extractAllocationSites 按分配点汇总 alloc_objects 和 alloc_space
+// 分配点取栈顶第一个非 runtime 函数，业务帧取沿调用栈向下第一个非标准库函数
+func extractAllocationSites(p *profile.Profile) []AllocationSite {
+	objectsIndex, spaceIndex := -1, -1
+	for i, st := range p.SampleType {
+		switch st.Type {
+		case "alloc_objects":
+			objectsIndex = i
+		case "alloc_space":
+			spaceIndex = i
+		}
+	}
+	if objectsIndex < 0 || spaceIndex < 0 {
+		return nil
+	}
+
+	bySite := make(map[[2]string]*AllocationSite)
+	for _, sample := range p.Sample {
+		if len(sample.Value) <= objectsIndex || len(sample.Value) <= spaceIndex {
+			continue
+		}
+
+		frames := sampleFunctions(sample)
+		function, business := allocationSite(frames)
+		if function == "" {
+			continue
+		}
+
+		key := [2]string{function, business}
+		entry, ok := bySite[key]
+		if !ok {
+			entry = &AllocationSite{Function: function, BusinessFrame: business}
+			bySite[key] = entry
+		}
+		entry.AllocObjects += sample.Value[objectsIndex]
+		entry.AllocSpace += sample.Value[spaceIndex]
+	}
+	if len(bySite) == 0 {
+		return nil
+	}
+
+	result := make([]AllocationSite, 0, len(bySite))
+	for _, entry := range bySite {
+		if entry.AllocObjects > 0 {
+			entry.AvgSize = entry.AllocSpace / entry.AllocObjects
+		}
+		result = append(result, *entry)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].AllocSpace != result[j].AllocSpace {
+			return result[i].AllocSpace > result[j].AllocSpace
+		}
+		if result[i].Function != result[j].Function {
+			return result[i].Function < result[j].Function
+		}
+		return result[i].BusinessFrame < result[j].BusinessFrame
+	})
+	return result
+}
+
+// allocationSite 从栈顶到栈底的函数名中找出分配函数和负责的业务帧
+func allocationSite(frames []string) (function, business string) {
+	for _, name := range frames {
+		pkg := PackageOf(name)
+		if pkg == "runtime" || strings.HasPrefix(pkg, "runtime/") {
+			continue
+		}
+		if function == "" {
+			function = name
+		}
+		if !isStdPackage(pkg) {
+			return function, name
+		}
+	}
+	return function, ""
+}
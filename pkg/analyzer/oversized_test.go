@@ -0,0 +1,76 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAllocSample 创建带分配次数的 heap 样本，funcNames 从栈顶到栈底排列
+func newAllocSample(allocObjects, allocSpace int64, funcNames ...string) *profile.Sample {
+	sample := newStackSample(allocSpace, funcNames...)
+	sample.Value[0] = allocObjects
+	return sample
+}
+
+func TestExtractAllocationSites(t *testing.T) {
+	const mb = 1024 * 1024
+	p := newHeapProfile(
+		newAllocSample(2, 8*mb, "runtime.makeslice", "io.ReadAll", "github.com/myapp/api.(*Handler).Upload", "main.main"),
+		newAllocSample(2, 4*mb, "runtime.makeslice", "io.ReadAll", "github.com/myapp/api.(*Handler).Upload", "main.main"),
+		newAllocSample(1000, 64000, "github.com/myapp/cache.New", "main.main"),
+		newAllocSample(1, 3*mb, "bytes.growSlice", "bytes.(*Buffer).Write"),
+	)
+
+	sites := extractAllocationSites(p)
+	require.Len(t, sites, 3)
+
+	// 同一分配点合并，跳过 runtime 帧，业务帧取第一个非标准库帧
+	assert.Equal(t, "io.ReadAll", sites[0].Function)
+	assert.Equal(t, "github.com/myapp/api.(*Handler).Upload", sites[0].BusinessFrame)
+	assert.Equal(t, int64(4), sites[0].AllocObjects)
+	assert.Equal(t, int64(12*mb), sites[0].AllocSpace)
+	assert.Equal(t, int64(3*mb), sites[0].AvgSize)
+
+	// 没有业务帧时 Caller 退回分配函数
+	assert.Equal(t, "bytes.growSlice", sites[1].Function)
+	assert.Equal(t, "", sites[1].BusinessFrame)
+	assert.Equal(t, "bytes.growSlice", sites[1].Caller())
+
+	// 分配函数本身就是业务帧
+	assert.Equal(t, "github.com/myapp/cache.New", sites[2].Function)
+	assert.Equal(t, "github.com/myapp/cache.New", sites[2].Caller())
+	assert.Equal(t, int64(64), sites[2].AvgSize)
+}
+
+func TestExtractMetrics_AllocationSites(t *testing.T) {
+	p := newHeapProfile(newAllocSample(4, 400, "main.handle"))
+	metrics := ExtractMetrics(p, "heap")
+	require.NotNil(t, metrics)
+	require.Len(t, metrics.AllocationSites, 1)
+	assert.Equal(t, int64(100), metrics.AllocationSites[0].AvgSize)
+
+	assert.Nil(t, ExtractMetrics(p, "cpu").AllocationSites)
+}
+
+func TestDetectOversizedAllocations(t *testing.T) {
+	const mb = 1024 * 1024
+	metrics := &ProfileMetrics{
+		AllocationSites: []AllocationSite{
+			{Function: "main.small", AllocObjects: 1000, AllocSpace: 100 * mb, AvgSize: 100 * 1024},
+			{Function: "main.big", AllocObjects: 2, AllocSpace: 4 * mb, AvgSize: 2 * mb},
+			{Function: "main.huge", AllocObjects: 1, AllocSpace: 16 * mb, AvgSize: 16 * mb},
+			{Function: "main.empty", AllocObjects: 0, AllocSpace: 0},
+		},
+	}
+
+	oversized := DetectOversizedAllocations(metrics, DefaultOversizedMinAvgSize)
+	require.Len(t, oversized, 2)
+	assert.Equal(t, "main.huge", oversized[0].Function)
+	assert.Equal(t, "main.big", oversized[1].Function)
+
+	assert.Empty(t, DetectOversizedAllocations(metrics, 32*mb))
+	assert.Nil(t, DetectOversizedAllocations(nil, DefaultOversizedMinAvgSize))
+}
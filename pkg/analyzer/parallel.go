@@ -0,0 +1,57 @@
+package analyzer
+
+import (
+	"context"
+	"sync"
+)
+
+// ParallelFor 使用最多 concurrency 个 goroutine 对 [0, n) 的每个索引调用 fn
+// fn 应只写入与索引对应的结果槽位，调用方按索引收集结果即可得到与完成顺序无关的确定输出；
+// concurrency 小于 1 时按 1 处理，此时在当前 goroutine 中按顺序执行
+func ParallelFor(n, concurrency int, fn func(i int)) {
+	_ = ParallelForCtx(context.Background(), n, concurrency, fn)
+}
+
+// ParallelForCtx 与 ParallelFor 相同，但 ctx 取消后不再分发新的索引
+// 已开始的 fn 会执行完毕，返回前等待所有 goroutine 退出；被取消时返回 ctx.Err()，
+// 此时只有部分索引被处理过
+func ParallelForCtx(ctx context.Context, n, concurrency int, fn func(i int)) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if concurrency > n {
+		concurrency = n
+	}
+	if concurrency <= 1 {
+		for i := 0; i < n; i++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			fn(i)
+		}
+		return ctx.Err()
+	}
+
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for w := 0; w < concurrency; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				fn(i)
+			}
+		}()
+	}
+dispatch:
+	for i := 0; i < n; i++ {
+		select {
+		case indices <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(indices)
+	wg.Wait()
+	return ctx.Err()
+}
@@ -0,0 +1,102 @@
+package analyzer
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParallelFor 测试每个索引恰好处理一次
+func TestParallelFor(t *testing.T) {
+	for _, concurrency := range []int{-1, 0, 1, 3, 100} {
+		const n = 50
+		var counts [n]int32
+		ParallelFor(n, concurrency, func(i int) {
+			atomic.AddInt32(&counts[i], 1)
+		})
+		for i := range counts {
+			assert.Equal(t, int32(1), counts[i], "concurrency %d index %d", concurrency, i)
+		}
+	}
+
+	called := false
+	ParallelFor(0, 4, func(int) { called = true })
+	assert.False(t, called)
+}
+
+// TestParallelFor_Bounded 测试同时运行的 goroutine 数不超过 concurrency
+func TestParallelFor_Bounded(t *testing.T) {
+	var active, peak int32
+	ParallelFor(20, 3, func(int) {
+		current := atomic.AddInt32(&active, 1)
+		for {
+			old := atomic.LoadInt32(&peak)
+			if current <= old || atomic.CompareAndSwapInt32(&peak, old, current) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&active, -1)
+	})
+	assert.LessOrEqual(t, peak, int32(3))
+	assert.Greater(t, peak, int32(0))
+}
+
+// TestParallelFor_Sequential 测试并发度为 1 时按顺序执行
+func TestParallelFor_Sequential(t *testing.T) {
+	var order []int
+	ParallelFor(5, 1, func(i int) { order = append(order, i) })
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, order)
+}
+
+// TestParallelForCtx 测试取消后停止分发并返回 ctx 错误
+func TestParallelForCtx(t *testing.T) {
+	for _, concurrency := range []int{1, 4} {
+		var calls int32
+		err := ParallelForCtx(context.Background(), 20, concurrency, func(int) {
+			atomic.AddInt32(&calls, 1)
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, int32(20), calls)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		calls = 0
+		err = ParallelForCtx(ctx, 20, concurrency, func(int) {
+			atomic.AddInt32(&calls, 1)
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Less(t, calls, int32(20), "concurrency %d", concurrency)
+	}
+}
+
+// TestParallelForCtx_CancelMidway 测试处理过程中取消时已开始的任务完成、剩余任务不再执行
+func TestParallelForCtx_CancelMidway(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls int32
+	err := ParallelForCtx(ctx, 100, 1, func(i int) {
+		atomic.AddInt32(&calls, 1)
+		if i == 4 {
+			cancel()
+		}
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int32(5), calls)
+}
+
+// TestCheckCanceled 测试只在检查间隔处返回 ctx 错误
+func TestCheckCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	assert.NoError(t, CheckCanceled(ctx, 0))
+	cancel()
+
+	assert.ErrorIs(t, CheckCanceled(ctx, 0), context.Canceled)
+	assert.ErrorIs(t, CheckCanceled(ctx, CancelCheckInterval), context.Canceled)
+	assert.NoError(t, CheckCanceled(ctx, 1))
+	assert.NoError(t, CheckCanceled(context.Background(), CancelCheckInterval))
+}
@@ -0,0 +1,41 @@
+package analyzer
+
+import "github.com/google/pprof/profile"
+
+// cpuValueIndex 返回 CPU profile 中 CPU 时间所在的 sample index，以及样本值换算为纳秒的倍数
+//
+// Go 运行时写出的 CPU profile 带有 cpu/nanoseconds 列，样本值已经是次数乘以 Period 的 CPU 时间，倍数为 1。
+// 一些工具转换的 profile 只有 samples/count 一列，此时按 PeriodType (cpu/nanoseconds) 和 Period 换算。
+// heap profile 不需要换算：Go 运行时写出的样本值已按采样率还原，旧版文本格式在解析时由 pprof 还原，
+// 与 go tool pprof 展示的值一致。
+// 找不到 CPU 时间时返回 -1
+func cpuValueIndex(p *profile.Profile) (index int, scale int64) {
+	for i, st := range p.SampleType {
+		if st.Type == "cpu" && st.Unit == "nanoseconds" {
+			return i, 1
+		}
+	}
+	if pt := p.PeriodType; pt != nil && pt.Type == "cpu" && pt.Unit == "nanoseconds" && p.Period > 0 {
+		for i, st := range p.SampleType {
+			if st.Type == "samples" && st.Unit == "count" {
+				return i, p.Period
+			}
+		}
+	}
+	if len(p.SampleType) > 1 {
+		return 1, 1 // 默认第二列是 CPU 时间
+	}
+	return -1, 0
+}
+
+// scaleFunctionStats 将函数统计的 flat/cum 值乘以 scale，百分比不变
+func scaleFunctionStats(stats []FunctionStat, scale int64) []FunctionStat {
+	if scale == 1 {
+		return stats
+	}
+	for i := range stats {
+		stats[i].Flat *= scale
+		stats[i].Cum *= scale
+	}
+	return stats
+}
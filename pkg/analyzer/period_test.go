@@ -0,0 +1,75 @@
+package analyzer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// legacyHeapProfile 旧版文本格式的 heap profile，采样率 512KB
+// 两个分配点：inuse 1 个 1KB 对象、累计 2 个 1KB 对象；inuse 0、累计 1 个 4KB 对象
+const legacyHeapProfile = `heap profile: 1: 1024 [3: 6144] @ heap_v2/524288
+1: 1024 [2: 2048] @ 0x1001 0x1002
+0: 0 [1: 4096] @ 0x1003 0x1002
+`
+
+func TestExtractMetrics_CPUPeriod(t *testing.T) {
+	// 只有样本次数的 CPU profile，每个样本代表 10ms
+	p := &profile.Profile{
+		SampleType:    []*profile.ValueType{{Type: "samples", Unit: "count"}},
+		PeriodType:    &profile.ValueType{Type: "cpu", Unit: "nanoseconds"},
+		Period:        int64(10 * time.Millisecond),
+		DurationNanos: int64(time.Second),
+		Sample:        []*profile.Sample{newGoroutineSample(3, "main.work")},
+	}
+
+	metrics := ExtractMetrics(p, "cpu")
+	assert.Equal(t, 30*time.Millisecond, metrics.CPUTime)
+	require.NotEmpty(t, metrics.TopFunctions)
+	assert.Equal(t, int64(30*time.Millisecond), metrics.TopFunctions[0].Flat)
+	assert.InDelta(t, 100.0, metrics.TopFunctions[0].FlatPct, 0.001)
+	require.NotEmpty(t, metrics.TopFlatFunctions)
+	assert.Equal(t, int64(30*time.Millisecond), metrics.TopFlatFunctions[0].Flat)
+
+	// Go 运行时的 CPU profile 已带有 cpu/nanoseconds 列，不重复乘以 Period
+	p = newBenchCPUProfile(newCPUSample(int64(30*time.Millisecond), "main.work"))
+	p.PeriodType = &profile.ValueType{Type: "cpu", Unit: "nanoseconds"}
+	p.Period = int64(10 * time.Millisecond)
+	assert.Equal(t, 30*time.Millisecond, ExtractMetrics(p, "cpu").CPUTime)
+}
+
+func TestCPUValueIndex(t *testing.T) {
+	index, scale := cpuValueIndex(newBenchCPUProfile())
+	assert.Equal(t, 1, index)
+	assert.Equal(t, int64(1), scale)
+
+	// 没有 cpu 类型的 Period 时无法换算样本次数
+	index, _ = cpuValueIndex(&profile.Profile{SampleType: []*profile.ValueType{{Type: "samples", Unit: "count"}}})
+	assert.Equal(t, -1, index)
+}
+
+// TestExtractMetrics_HeapSampling 旧版 heap profile 的采样值在解析时已按采样率还原，与 go tool pprof 一致，
+// 写成 proto 再读回也不会重复还原
+func TestExtractMetrics_HeapSampling(t *testing.T) {
+	p, err := profile.Parse(strings.NewReader(legacyHeapProfile))
+	require.NoError(t, err)
+	assert.Equal(t, int64(524288), p.Period)
+
+	metrics := ExtractMetrics(p, "heap")
+	assert.Equal(t, int64(1025+128), metrics.AllocObjects)
+	assert.Equal(t, int64(1049600+526338), metrics.AllocSpace)
+	assert.Equal(t, int64(512), metrics.InuseObjects)
+	assert.Equal(t, int64(524800), metrics.InuseSpace)
+
+	var buf bytes.Buffer
+	require.NoError(t, p.Write(&buf))
+	roundTrip, err := profile.Parse(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, metrics.AllocSpace, ExtractMetrics(roundTrip, "heap").AllocSpace)
+	assert.Equal(t, metrics.InuseSpace, ExtractMetrics(roundTrip, "heap").InuseSpace)
+}
@@ -0,0 +1,64 @@
+package analyzer
+
+import "sort"
+
+// DefaultPackageMemoryTopN 包内存视图默认展示的包数
+const DefaultPackageMemoryTopN = 10
+
+// PackageMemory 单个包的内存占用（按分配点所在包统计），覆盖所有代码分类
+type PackageMemory struct {
+	Package    string
+	Category   string  // 包的代码分类，如 business、third_party、stdlib、runtime
+	AllocSpace int64   // 累计分配字节数
+	InuseSpace int64   // 仍在使用的字节数
+	AllocPct   float64 // 占全部累计分配的百分比 (0-100)
+	InusePct   float64 // 占全部使用中内存的百分比 (0-100)
+}
+
+// PackageMemoryBreakdown 按包汇总 heap profile 的 alloc_space 和 inuse_space，
+// 按累计分配降序 (相同时按使用中内存) 返回前 n 个包，n <= 0 时返回全部
+// 与按函数排名的 Top 列表相比，同一个包分散在多个函数上的开销会合并到一起；
+// classify 为 nil 时不填充分类
+func PackageMemoryBreakdown(metrics *ProfileMetrics, classify PackageClassifier, n int) []PackageMemory {
+	if metrics == nil || len(metrics.PackageRetention) == 0 {
+		return nil
+	}
+
+	var totalAlloc, totalInuse int64
+	for _, pkg := range metrics.PackageRetention {
+		totalAlloc += pkg.AllocSpace
+		totalInuse += pkg.InuseSpace
+	}
+
+	result := make([]PackageMemory, 0, len(metrics.PackageRetention))
+	for _, pkg := range metrics.PackageRetention {
+		if pkg.AllocSpace <= 0 && pkg.InuseSpace <= 0 {
+			continue
+		}
+		entry := PackageMemory{Package: pkg.Package, AllocSpace: pkg.AllocSpace, InuseSpace: pkg.InuseSpace}
+		if classify != nil {
+			entry.Category = classify(pkg.Package)
+		}
+		if totalAlloc > 0 {
+			entry.AllocPct = float64(pkg.AllocSpace) / float64(totalAlloc) * 100
+		}
+		if totalInuse > 0 {
+			entry.InusePct = float64(pkg.InuseSpace) / float64(totalInuse) * 100
+		}
+		result = append(result, entry)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].AllocSpace != result[j].AllocSpace {
+			return result[i].AllocSpace > result[j].AllocSpace
+		}
+		if result[i].InuseSpace != result[j].InuseSpace {
+			return result[i].InuseSpace > result[j].InuseSpace
+		}
+		return result[i].Package < result[j].Package
+	})
+	if n > 0 && len(result) > n {
+		result = result[:n]
+	}
+	return result
+}
@@ -0,0 +1,58 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackageMemoryBreakdown(t *testing.T) {
+	metrics := &ProfileMetrics{PackageRetention: []PackageRetention{
+		{Package: "encoding/json", AllocSpace: 300, InuseSpace: 10},
+		{Package: "github.com/myapp/cache", AllocSpace: 500, InuseSpace: 400},
+		{Package: "github.com/redis/go-redis", AllocSpace: 150, InuseSpace: 50},
+		{Package: "runtime", AllocSpace: 50, InuseSpace: 40},
+		{Package: "github.com/myapp/empty"},
+	}}
+
+	packages := PackageMemoryBreakdown(metrics, testClassifier, 0)
+	require.Len(t, packages, 4, "packages without memory are skipped")
+
+	assert.Equal(t, PackageMemory{
+		Package: "github.com/myapp/cache", Category: "business",
+		AllocSpace: 500, InuseSpace: 400, AllocPct: 50, InusePct: 80,
+	}, packages[0])
+	assert.Equal(t, "encoding/json", packages[1].Package)
+	assert.Equal(t, "stdlib", packages[1].Category)
+	assert.Equal(t, "third_party", packages[2].Category)
+	assert.Equal(t, "runtime", packages[3].Category)
+
+	top := PackageMemoryBreakdown(metrics, testClassifier, 2)
+	assert.Equal(t, packages[:2], top)
+
+	// 没有分类器时不填充分类
+	assert.Empty(t, PackageMemoryBreakdown(metrics, nil, 1)[0].Category)
+
+	assert.Nil(t, PackageMemoryBreakdown(nil, testClassifier, 0))
+	assert.Nil(t, PackageMemoryBreakdown(&ProfileMetrics{}, testClassifier, 0))
+}
+
+func TestComputeCategoryTotals_PackageMemory(t *testing.T) {
+	p := newHeapProfile(
+		newInuseSample(100, "github.com/myapp/cache.(*LRU).Add", "main.main"),
+		newInuseSample(50, "github.com/myapp/cache.(*LRU).Evict", "main.main"),
+		newInuseSample(30, "encoding/json.Marshal", "github.com/myapp/api.Handle"),
+	)
+	groups := []ProfileGroup{
+		{Type: "heap", Files: []ProfileFile{{Profile: p, Metrics: ExtractMetrics(p, "heap")}}},
+	}
+
+	ComputeCategoryTotals(groups, testClassifier, HeapSampleInuseSpace)
+	packages := groups[0].Files[0].Metrics.PackageMemory
+	require.Len(t, packages, 2)
+	assert.Equal(t, "github.com/myapp/cache", packages[0].Package, "functions of one package are merged")
+	assert.Equal(t, "business", packages[0].Category)
+	assert.Equal(t, int64(150), packages[0].InuseSpace)
+	assert.Equal(t, "encoding/json", packages[1].Package)
+}
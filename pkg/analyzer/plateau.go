@@ -0,0 +1,39 @@
+package analyzer
+
+// goroutine 数增长的形态，线性拟合无法区分一次性的阶跃和持续增长
+const (
+	GoroutineGrowthSustained = "sustained" // 最近的快照仍在增长，疑似泄漏
+	GoroutineGrowthPlateau   = "plateau"   // 增长后趋于平稳，通常是一次扩容
+	GoroutineGrowthStable    = "stable"    // 整体没有增长
+)
+
+// DefaultPlateauMinWindow 判断平台期时最近窗口的最少快照数
+const DefaultPlateauMinWindow = 3
+
+// DefaultPlateauSlopeRatio 最近窗口的斜率不超过整体斜率的这个比例时视为进入平台期
+const DefaultPlateauSlopeRatio = 0.2
+
+// ClassifyGoroutineGrowth 区分 goroutine 数的持续增长和阶跃后的平台期
+// 先对整个序列拟合，没有增长时为 GoroutineGrowthStable；否则对最近一半 (至少 DefaultPlateauMinWindow 个) 快照单独拟合，
+// 最近斜率不超过整体斜率的 DefaultPlateauSlopeRatio 时为 GoroutineGrowthPlateau，其余为 GoroutineGrowthSustained。
+// 快照数不足以划出更早的数据时无法区分，按整体趋势处理
+func ClassifyGoroutineGrowth(values []float64) string {
+	slope, _ := LinearRegression(values)
+	if getDirection(slope) != "increasing" {
+		return GoroutineGrowthStable
+	}
+
+	window := len(values) / 2
+	if window < DefaultPlateauMinWindow {
+		window = DefaultPlateauMinWindow
+	}
+	if len(values) <= window {
+		return GoroutineGrowthSustained
+	}
+
+	recentSlope, _ := LinearRegression(values[len(values)-window:])
+	if recentSlope <= slope*DefaultPlateauSlopeRatio {
+		return GoroutineGrowthPlateau
+	}
+	return GoroutineGrowthSustained
+}
@@ -0,0 +1,50 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyGoroutineGrowth(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   string
+	}{
+		{"linear growth", []float64{100, 200, 300, 400, 500, 600}, GoroutineGrowthSustained},
+		{"step then flat", []float64{100, 100, 500, 500, 500, 500}, GoroutineGrowthPlateau},
+		{"ramp then flat", []float64{100, 200, 300, 400, 400, 400, 400, 400}, GoroutineGrowthPlateau},
+		{"accelerating", []float64{100, 100, 100, 150, 250, 400}, GoroutineGrowthSustained},
+		{"flat", []float64{100, 100, 100, 100}, GoroutineGrowthStable},
+		{"decreasing", []float64{400, 300, 200, 100}, GoroutineGrowthStable},
+		// 快照数不足以划出更早的数据，按整体趋势处理
+		{"too few points", []float64{100, 500, 500}, GoroutineGrowthSustained},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ClassifyGoroutineGrowth(tt.values))
+		})
+	}
+}
+
+// TestCalculateTrends_GoroutineGrowthKind 测试 goroutine 分组的趋势带有增长形态
+func TestCalculateTrends_GoroutineGrowthKind(t *testing.T) {
+	newGroup := func(counts ...int64) ProfileGroup {
+		group := ProfileGroup{Type: "goroutine"}
+		for _, count := range counts {
+			group.Files = append(group.Files, ProfileFile{Metrics: &ProfileMetrics{GoroutineCount: count}})
+		}
+		return group
+	}
+
+	step := CalculateTrends(newGroup(100, 100, 500, 500, 500, 500))
+	assert.Equal(t, "increasing", step.GoroutineCount.Direction, "a single linear fit still reads as increasing")
+	assert.Equal(t, GoroutineGrowthPlateau, step.GoroutineGrowthKind)
+
+	linear := CalculateTrends(newGroup(100, 200, 300, 400, 500, 600))
+	assert.Equal(t, GoroutineGrowthSustained, linear.GoroutineGrowthKind)
+
+	// 其他类型的分组不判断
+	assert.Empty(t, CalculateTrends(ProfileGroup{Type: "heap", Files: newGroup(1, 2, 3).Files}).GoroutineGrowthKind)
+}
@@ -0,0 +1,111 @@
+package analyzer
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/google/pprof/profile"
+)
+
+// DefaultReflectionMinShare reflect 包的消耗占 CPU 时间或累计分配的比例达到 10% 视为热点
+const DefaultReflectionMinShare = 0.1
+
+// ReflectionHotspot 由业务调用点触发的反射开销
+type ReflectionHotspot struct {
+	Caller string  // 触发反射的调用点（优先取第一个非标准库帧）
+	Value  int64   // CPU 时间 (纳秒) 或累计分配字节数
+	Share  float64 // 占 profile 总 CPU 时间或累计分配的比例
+}
+
+// IsReflectionFunc 判断函数是否属于 reflect 包
+func IsReflectionFunc(funcName string) bool {
+	return PackageOf(funcName) == "reflect"
+}
+
+// ReflectionShare 返回反射开销的总比例
+func ReflectionShare(hotspots []ReflectionHotspot) float64 {
+	var share float64
+	for _, h := range hotspots {
+		share += h.Share
+	}
+	return share
+}
+
+// DetectReflectionHotspots 当反射开销的占比达到 minShare 时返回各调用点
+// 反射通常由 encoding/json 等标准库间接调用，这里把开销归到触发反射的业务调用点上
+func DetectReflectionHotspots(metrics *ProfileMetrics, minShare float64) []ReflectionHotspot {
+	if metrics == nil || len(metrics.ReflectionHotspots) == 0 {
+		return nil
+	}
+	if ReflectionShare(metrics.ReflectionHotspots) < minShare {
+		return nil
+	}
+	return metrics.ReflectionHotspots
+}
+
+// extractReflectionHotspots 按调用点汇总反射开销 (cpu 为 CPU 时间，heap 为 alloc_space)
+// 从栈顶跳过 runtime 帧后第一个帧属于 reflect 包的样本计为反射开销，
+// 这样 reflect 内部的分配和内存拷贝计入反射，而 reflect.Value.Call 调用的业务函数不计入。
+// 结果按消耗降序排列
+func extractReflectionHotspots(p *profile.Profile, profileType string) []ReflectionHotspot {
+	valueIndex := categorySampleIndex(p, profileType, HeapSampleAllocSpace)
+	if valueIndex < 0 {
+		return nil
+	}
+
+	var total int64
+	byCaller := make(map[string]*ReflectionHotspot)
+	for _, sample := range p.Sample {
+		if len(sample.Value) <= valueIndex {
+			continue
+		}
+		value := sample.Value[valueIndex]
+		total += value
+
+		frames := sampleFunctions(sample)
+		first := firstNonRuntimeFrame(frames)
+		if first < 0 || !IsReflectionFunc(frames[first]) {
+			continue
+		}
+
+		// 跳过连续的 reflect 帧，从反射的入口开始向上追溯
+		callers := frames[first:]
+		for len(callers) > 0 && IsReflectionFunc(callers[0]) {
+			callers = callers[1:]
+		}
+		caller := conversionCaller(callers)
+		entry, ok := byCaller[caller]
+		if !ok {
+			entry = &ReflectionHotspot{Caller: caller}
+			byCaller[caller] = entry
+		}
+		entry.Value += value
+	}
+	if total <= 0 || len(byCaller) == 0 {
+		return nil
+	}
+
+	result := make([]ReflectionHotspot, 0, len(byCaller))
+	for _, entry := range byCaller {
+		entry.Share = float64(entry.Value) / float64(total)
+		result = append(result, *entry)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Value != result[j].Value {
+			return result[i].Value > result[j].Value
+		}
+		return result[i].Caller < result[j].Caller
+	})
+	return result
+}
+
+// firstNonRuntimeFrame 返回从栈顶开始第一个不属于 runtime 的帧下标，找不到时返回 -1
+func firstNonRuntimeFrame(frames []string) int {
+	for i, name := range frames {
+		pkg := PackageOf(name)
+		if pkg != "runtime" && !strings.HasPrefix(pkg, "runtime/") && !strings.HasPrefix(pkg, "internal/") {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,83 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsReflectionFunc(t *testing.T) {
+	assert.True(t, IsReflectionFunc("reflect.Value.Field"))
+	assert.True(t, IsReflectionFunc("reflect.(*rtype).Method"))
+	assert.False(t, IsReflectionFunc("internal/reflectlite.TypeOf"))
+	assert.False(t, IsReflectionFunc("github.com/myapp/reflect.Walk"))
+	assert.False(t, IsReflectionFunc("encoding/json.Marshal"))
+}
+
+func TestExtractReflectionHotspots_Heap(t *testing.T) {
+	p := newHeapProfile(
+		// reflect 内部的分配计入反射，跳过 encoding/json 追溯到业务调用点
+		newStackSample(300, "runtime.mallocgc", "reflect.unsafe_New", "reflect.New", "encoding/json.(*decodeState).object",
+			"encoding/json.Unmarshal", "github.com/myapp/api.DecodeOrder", "main.main"),
+		newStackSample(100, "reflect.Value.Interface", "github.com/myapp/orm.Scan", "github.com/myapp/api.ListOrders"),
+		newStackSample(100, "runtime.mallocgc", "reflect.New", "github.com/myapp/orm.Scan", "github.com/myapp/api.ListOrders"),
+		// reflect.Value.Call 调用的业务函数自身的分配不计入反射
+		newStackSample(200, "github.com/myapp/rpc.Handle", "reflect.Value.call", "reflect.Value.Call", "main.main"),
+		newStackSample(300, "github.com/myapp/cache.New", "main.main"),
+	)
+
+	hotspots := extractReflectionHotspots(p, "heap")
+	require.Len(t, hotspots, 2)
+
+	assert.Equal(t, "github.com/myapp/api.DecodeOrder", hotspots[0].Caller)
+	assert.Equal(t, int64(300), hotspots[0].Value)
+	assert.InDelta(t, 0.3, hotspots[0].Share, 0.0001)
+
+	// 同一调用点的开销合并
+	assert.Equal(t, "github.com/myapp/orm.Scan", hotspots[1].Caller)
+	assert.Equal(t, int64(200), hotspots[1].Value)
+
+	assert.InDelta(t, 0.5, ReflectionShare(hotspots), 0.0001)
+}
+
+func TestExtractReflectionHotspots_CPU(t *testing.T) {
+	p := newBenchCPUProfile(
+		newCPUSample(250, "reflect.Value.FieldByName", "encoding/json.Marshal", "github.com/myapp/api.Encode"),
+		newCPUSample(750, "github.com/myapp/api.Compute"),
+	)
+
+	hotspots := extractReflectionHotspots(p, "cpu")
+	require.Len(t, hotspots, 1)
+	assert.Equal(t, "github.com/myapp/api.Encode", hotspots[0].Caller)
+	assert.Equal(t, int64(250), hotspots[0].Value)
+	assert.InDelta(t, 0.25, hotspots[0].Share, 0.0001)
+
+	assert.Nil(t, extractReflectionHotspots(newBenchCPUProfile(newCPUSample(100, "main.main")), "cpu"))
+}
+
+func TestExtractMetrics_ReflectionHotspots(t *testing.T) {
+	p := newHeapProfile(newStackSample(100, "reflect.New", "main.handle"))
+	metrics := ExtractMetrics(p, "heap")
+	require.NotNil(t, metrics)
+	require.Len(t, metrics.ReflectionHotspots, 1)
+	assert.Equal(t, "main.handle", metrics.ReflectionHotspots[0].Caller)
+
+	cpu := ExtractMetrics(newBenchCPUProfile(newCPUSample(100, "reflect.Value.Field", "main.handle")), "cpu")
+	require.Len(t, cpu.ReflectionHotspots, 1)
+
+	assert.Nil(t, ExtractMetrics(p, "goroutine").ReflectionHotspots)
+}
+
+func TestDetectReflectionHotspots(t *testing.T) {
+	metrics := &ProfileMetrics{
+		ReflectionHotspots: []ReflectionHotspot{
+			{Caller: "main.a", Value: 8, Share: 0.08},
+			{Caller: "main.b", Value: 4, Share: 0.04},
+		},
+	}
+
+	assert.Len(t, DetectReflectionHotspots(metrics, DefaultReflectionMinShare), 2)
+	assert.Nil(t, DetectReflectionHotspots(metrics, 0.2))
+	assert.Nil(t, DetectReflectionHotspots(nil, DefaultReflectionMinShare))
+}
@@ -0,0 +1,133 @@
+package analyzer
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/google/pprof/profile"
+)
+
+// 保留率检测的默认阈值
+const (
+	DefaultRetentionMinRatio      = 0.8             // inuse/alloc 比例达到 80% 视为异常
+	DefaultRetentionMinAllocSpace = 1 * 1024 * 1024 // 累计分配低于 1MB 的包不参与检测
+)
+
+// PackageRetention 单个包的内存保留情况（按分配点所在包统计）
+type PackageRetention struct {
+	Package    string
+	AllocSpace int64   // 累计分配字节数
+	InuseSpace int64   // 仍在使用的字节数
+	Ratio      float64 // InuseSpace / AllocSpace
+}
+
+// RetentionConfig inuse/alloc 背离检测配置
+type RetentionConfig struct {
+	MinRatio      float64 // 保留率阈值
+	MinAllocSpace int64   // 参与检测的最小累计分配字节数
+}
+
+// DefaultRetentionConfig 返回默认的保留率检测配置
+func DefaultRetentionConfig() RetentionConfig {
+	return RetentionConfig{
+		MinRatio:      DefaultRetentionMinRatio,
+		MinAllocSpace: DefaultRetentionMinAllocSpace,
+	}
+}
+
+// DetectRetainedPackages 找出 inuse 占 alloc 比例异常高的包
+// 健康的堆中大部分分配会被回收，inuse 紧跟 alloc 说明对象没有被释放，
+// 这是单个 profile 即可观察到的泄漏信号。结果按保留字节数降序排列
+func DetectRetainedPackages(metrics *ProfileMetrics, config RetentionConfig) []PackageRetention {
+	if metrics == nil {
+		return nil
+	}
+
+	var retained []PackageRetention
+	for _, pkg := range metrics.PackageRetention {
+		if pkg.AllocSpace <= 0 || pkg.AllocSpace < config.MinAllocSpace {
+			continue
+		}
+		if pkg.Ratio >= config.MinRatio {
+			retained = append(retained, pkg)
+		}
+	}
+
+	sort.Slice(retained, func(i, j int) bool {
+		if retained[i].InuseSpace != retained[j].InuseSpace {
+			return retained[i].InuseSpace > retained[j].InuseSpace
+		}
+		return retained[i].Package < retained[j].Package
+	})
+	return retained
+}
+
+// extractPackageRetention 按分配点（栈顶函数）所在包汇总 alloc_space 和 inuse_space
+func extractPackageRetention(p *profile.Profile) []PackageRetention {
+	allocIndex, inuseIndex := -1, -1
+	for i, st := range p.SampleType {
+		switch st.Type {
+		case "alloc_space":
+			allocIndex = i
+		case "inuse_space":
+			inuseIndex = i
+		}
+	}
+	if allocIndex < 0 || inuseIndex < 0 {
+		return nil
+	}
+
+	byPackage := make(map[string]*PackageRetention)
+	for _, sample := range p.Sample {
+		if len(sample.Value) <= allocIndex || len(sample.Value) <= inuseIndex {
+			continue
+		}
+		pkg := samplePackage(sample)
+		if pkg == "" {
+			continue
+		}
+
+		entry, ok := byPackage[pkg]
+		if !ok {
+			entry = &PackageRetention{Package: pkg}
+			byPackage[pkg] = entry
+		}
+		entry.AllocSpace += sample.Value[allocIndex]
+		entry.InuseSpace += sample.Value[inuseIndex]
+	}
+
+	result := make([]PackageRetention, 0, len(byPackage))
+	for _, entry := range byPackage {
+		if entry.AllocSpace > 0 {
+			entry.Ratio = float64(entry.InuseSpace) / float64(entry.AllocSpace)
+		}
+		result = append(result, *entry)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Package < result[j].Package
+	})
+	return result
+}
+
+// samplePackage 返回样本栈顶函数所在的包
+func samplePackage(sample *profile.Sample) string {
+	if len(sample.Location) == 0 || sample.Location[0] == nil || len(sample.Location[0].Line) == 0 {
+		return ""
+	}
+	fn := sample.Location[0].Line[0].Function
+	if fn == nil {
+		return ""
+	}
+	return PackageOf(fn.Name)
+}
+
+// PackageOf 从完整函数名中提取包路径
+// 例如 github.com/myapp/cache.(*LRU).Add -> github.com/myapp/cache
+func PackageOf(funcName string) string {
+	lastSlash := strings.LastIndex(funcName, "/")
+	dot := strings.Index(funcName[lastSlash+1:], ".")
+	if dot < 0 {
+		return funcName
+	}
+	return funcName[:lastSlash+1+dot]
+}
@@ -0,0 +1,115 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newHeapSample 创建 heap 样本，栈顶为 funcName
+func newHeapSample(id uint64, funcName string, allocSpace, inuseSpace int64) *profile.Sample {
+	fn := &profile.Function{ID: id, Name: funcName}
+	loc := &profile.Location{ID: id, Line: []profile.Line{{Function: fn}}}
+	return &profile.Sample{
+		Location: []*profile.Location{loc},
+		Value:    []int64{1, allocSpace, 1, inuseSpace},
+	}
+}
+
+// newHeapProfile 创建带标准 heap sample type 的 profile
+func newHeapProfile(samples ...*profile.Sample) *profile.Profile {
+	return &profile.Profile{
+		SampleType: []*profile.ValueType{
+			{Type: "alloc_objects", Unit: "count"},
+			{Type: "alloc_space", Unit: "bytes"},
+			{Type: "inuse_objects", Unit: "count"},
+			{Type: "inuse_space", Unit: "bytes"},
+		},
+		Sample: samples,
+	}
+}
+
+func TestPackageOf(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"github.com/myapp/cache.(*LRU).Add", "github.com/myapp/cache"},
+		{"github.com/myapp/cache.New.func1", "github.com/myapp/cache"},
+		{"encoding/json.Marshal", "encoding/json"},
+		{"main.main", "main"},
+		{"runtime.mallocgc", "runtime"},
+		{"noDot", "noDot"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, PackageOf(tt.name), tt.name)
+	}
+}
+
+func TestExtractMetrics_PackageRetention(t *testing.T) {
+	const mb = 1024 * 1024
+	p := newHeapProfile(
+		newHeapSample(1, "github.com/myapp/cache.(*LRU).Add", 10*mb, 9*mb),
+		newHeapSample(2, "github.com/myapp/cache.New", 2*mb, 1*mb),
+		newHeapSample(3, "encoding/json.Marshal", 100*mb, 1*mb),
+	)
+
+	metrics := ExtractMetrics(p, "heap")
+	require.NotNil(t, metrics)
+	require.Len(t, metrics.PackageRetention, 2)
+
+	// 按包名排序
+	cache := metrics.PackageRetention[1]
+	assert.Equal(t, "github.com/myapp/cache", cache.Package)
+	assert.Equal(t, int64(12*mb), cache.AllocSpace)
+	assert.Equal(t, int64(10*mb), cache.InuseSpace)
+	assert.InDelta(t, 10.0/12.0, cache.Ratio, 0.0001)
+
+	assert.Equal(t, "encoding/json", metrics.PackageRetention[0].Package)
+	assert.InDelta(t, 0.01, metrics.PackageRetention[0].Ratio, 0.0001)
+}
+
+func TestExtractMetrics_PackageRetention_NonHeap(t *testing.T) {
+	p := newHeapProfile(newHeapSample(1, "main.main", 100, 100))
+	metrics := ExtractMetrics(p, "cpu")
+	require.NotNil(t, metrics)
+	assert.Nil(t, metrics.PackageRetention)
+}
+
+func TestDetectRetainedPackages(t *testing.T) {
+	const mb = 1024 * 1024
+	metrics := &ProfileMetrics{
+		PackageRetention: []PackageRetention{
+			{Package: "github.com/myapp/cache", AllocSpace: 10 * mb, InuseSpace: 9 * mb, Ratio: 0.9},
+			{Package: "github.com/myapp/registry", AllocSpace: 40 * mb, InuseSpace: 40 * mb, Ratio: 1.0},
+			{Package: "encoding/json", AllocSpace: 100 * mb, InuseSpace: 1 * mb, Ratio: 0.01},
+			{Package: "github.com/myapp/tiny", AllocSpace: 1024, InuseSpace: 1024, Ratio: 1.0},
+		},
+	}
+
+	t.Run("default config", func(t *testing.T) {
+		retained := DetectRetainedPackages(metrics, DefaultRetentionConfig())
+		require.Len(t, retained, 2)
+		// 按保留字节数降序
+		assert.Equal(t, "github.com/myapp/registry", retained[0].Package)
+		assert.Equal(t, "github.com/myapp/cache", retained[1].Package)
+	})
+
+	t.Run("stricter ratio", func(t *testing.T) {
+		retained := DetectRetainedPackages(metrics, RetentionConfig{MinRatio: 0.95, MinAllocSpace: mb})
+		require.Len(t, retained, 1)
+		assert.Equal(t, "github.com/myapp/registry", retained[0].Package)
+	})
+
+	t.Run("no minimum allocation", func(t *testing.T) {
+		retained := DetectRetainedPackages(metrics, RetentionConfig{MinRatio: 0.8})
+		assert.Len(t, retained, 3)
+	})
+
+	t.Run("nil metrics", func(t *testing.T) {
+		assert.Nil(t, DetectRetainedPackages(nil, DefaultRetentionConfig()))
+	})
+}
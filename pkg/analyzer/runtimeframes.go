@@ -0,0 +1,233 @@
+package analyzer
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/pprof/profile"
+)
+
+// RuntimeFrameKind runtime 帧的用途分类
+type RuntimeFrameKind string
+
+const (
+	RuntimeFrameNone      RuntimeFrameKind = ""          // 不是可识别的 runtime 帧
+	RuntimeFrameGC        RuntimeFrameKind = "gc"        // 垃圾回收：标记、清扫、辅助标记
+	RuntimeFrameAlloc     RuntimeFrameKind = "alloc"     // 内存分配
+	RuntimeFrameScheduler RuntimeFrameKind = "scheduler" // 调度和阻塞等待
+)
+
+// runtimePatternSet 从某个 Go 版本开始出现的 runtime 函数名模式
+// 模式使用前缀/正则而不是精确函数名，运行时内部函数拆分或改名时仍能匹配
+type runtimePatternSet struct {
+	minVersion string // 引入这些函数名的 Go 版本，空表示所有版本
+	kind       RuntimeFrameKind
+	patterns   []*regexp.Regexp
+}
+
+// runtimePatternSets 按版本维护的 runtime 帧模式
+// 新版本 Go 改动运行时函数名时在这里追加一组，不修改已有的组
+var runtimePatternSets = []runtimePatternSet{
+	{
+		kind: RuntimeFrameGC,
+		patterns: []*regexp.Regexp{
+			regexp.MustCompile(`^runtime\.gc(BgMarkWorker|Drain|Mark|Sweep|Start|Assist|FlushBgCredit|ResetMarkState|ControllerCommit)`),
+			regexp.MustCompile(`^runtime\.(scanobject|scanblock|scanstack|scanframeworker|greyobject|shade|markroot|markBits|bgsweep|bgscavenge|sweepone|findObject|wbBufFlush)`),
+			regexp.MustCompile(`^runtime\.\(\*(gcWork|gcControllerState|mspan|mheap|sweepLocked|scavengerState|pageAlloc)\)\.(sweep|scavenge|tryGet|put|balance|dispose|markBitsForIndex|reclaim|endCycle|enlistWorker|findRunnableGCWorker)`),
+		},
+	},
+	{
+		kind: RuntimeFrameAlloc,
+		patterns: []*regexp.Regexp{
+			// mallocgc 在 Go 1.24 拆分为 mallocgcSmallNoscan/mallocgcLarge 等，前缀匹配覆盖新旧版本
+			regexp.MustCompile(`^runtime\.(mallocgc|newobject|newarray|makeslice|growslice|makemap|rawstring|rawbyteslice|rawruneslice|largeAlloc|nextFreeFast)`),
+			regexp.MustCompile(`^runtime\.\(\*(mcache|mcentral|mheap)\)\.(refill|nextFree|cacheSpan|grow|alloc|allocSpan|allocLarge)`),
+		},
+	},
+	{
+		kind: RuntimeFrameScheduler,
+		patterns: []*regexp.Regexp{
+			// findrunnable 在 Go 1.19 改名为 findRunnable
+			regexp.MustCompile(`^runtime\.(gopark|goparkunlock|park_m|schedule|find[Rr]unnable|mcall|selectgo|block|chanrecv|chansend|semacquire|notetsleepg|netpollblock|gosched_m|goschedImpl|stopm|usleep|futex)`),
+		},
+	},
+	{
+		// Go 1.22 allocation headers 引入的类型指针遍历，用于 GC 扫描
+		minVersion: "go1.22",
+		kind:       RuntimeFrameGC,
+		patterns: []*regexp.Regexp{
+			regexp.MustCompile(`^runtime\.(typePointers\.|\(\*mspan\)\.typePointersOf)`),
+		},
+	},
+	{
+		// Go 1.25 greenteagc 按 span 扫描
+		minVersion: "go1.25",
+		kind:       RuntimeFrameGC,
+		patterns: []*regexp.Regexp{
+			regexp.MustCompile(`^runtime\.(scanSpan|scanObjectsSmall|tryDeferToSpanScan)`),
+		},
+	},
+}
+
+// RuntimeFrameMatcher 识别 runtime 帧的用途，按 Go 版本选择模式集合
+type RuntimeFrameMatcher struct {
+	goVersion string
+	sets      []runtimePatternSet
+}
+
+// NewRuntimeFrameMatcher 创建 runtime 帧匹配器
+// goVersion 形如 go1.22 / go1.22.3，为空或无法解析时启用所有版本的模式
+func NewRuntimeFrameMatcher(goVersion string) *RuntimeFrameMatcher {
+	m := &RuntimeFrameMatcher{goVersion: goVersion}
+	known := ParseGoVersion(goVersion) != nil
+	for _, set := range runtimePatternSets {
+		if !known || set.minVersion == "" || CompareGoVersions(goVersion, set.minVersion) >= 0 {
+			m.sets = append(m.sets, set)
+		}
+	}
+	return m
+}
+
+// GoVersion 返回匹配器使用的 Go 版本，空表示未知
+func (m *RuntimeFrameMatcher) GoVersion() string {
+	return m.goVersion
+}
+
+// Kind 返回函数所属的 runtime 帧分类
+func (m *RuntimeFrameMatcher) Kind(funcName string) RuntimeFrameKind {
+	if !strings.HasPrefix(funcName, "runtime.") {
+		return RuntimeFrameNone
+	}
+	for _, set := range m.sets {
+		for _, re := range set.patterns {
+			if re.MatchString(funcName) {
+				return set.kind
+			}
+		}
+	}
+	return RuntimeFrameNone
+}
+
+// IsGCFrame 判断函数是否是垃圾回收相关的 runtime 帧
+func (m *RuntimeFrameMatcher) IsGCFrame(funcName string) bool {
+	return m.Kind(funcName) == RuntimeFrameGC
+}
+
+// gcAssistPattern 匹配 GC 辅助标记的 runtime 函数 (GC 帧的子集)
+// 分配速度超过后台标记时，分配内存的 goroutine 被要求先偿还标记工作，或在 gcParkAssist 中挂起等待
+var gcAssistPattern = regexp.MustCompile(`^runtime\.(gcAssistAlloc|gcParkAssist|deductAssistCredit)`)
+
+// IsGCAssistFrame 判断函数是否是 GC 辅助标记帧
+func IsGCAssistFrame(funcName string) bool {
+	return gcAssistPattern.MatchString(funcName)
+}
+
+// defaultRuntimeFrameMatcher 不区分版本的匹配器
+var defaultRuntimeFrameMatcher = NewRuntimeFrameMatcher("")
+
+// ClassifyRuntimeFrame 使用所有版本的模式识别 runtime 帧，适用于无法确定 Go 版本的场景
+func ClassifyRuntimeFrame(funcName string) RuntimeFrameKind {
+	return defaultRuntimeFrameMatcher.Kind(funcName)
+}
+
+// goVersionPattern 匹配 go1.N 或 go1.N.M
+var goVersionPattern = regexp.MustCompile(`go1\.(\d+)(?:\.(\d+))?`)
+
+// ParseGoVersion 解析 Go 版本号，返回 [major, minor, patch]，无法解析时返回 nil
+func ParseGoVersion(version string) []int {
+	match := goVersionPattern.FindStringSubmatch(version)
+	if match == nil {
+		return nil
+	}
+	minor, _ := strconv.Atoi(match[1])
+	patch := 0
+	if match[2] != "" {
+		patch, _ = strconv.Atoi(match[2])
+	}
+	return []int{1, minor, patch}
+}
+
+// CompareGoVersions 比较两个 Go 版本，a<b 返回 -1，相等返回 0，a>b 返回 1
+// 无法解析的版本视为最旧
+func CompareGoVersions(a, b string) int {
+	va, vb := ParseGoVersion(a), ParseGoVersion(b)
+	switch {
+	case va == nil && vb == nil:
+		return 0
+	case va == nil:
+		return -1
+	case vb == nil:
+		return 1
+	}
+	for i := range va {
+		if va[i] != vb[i] {
+			if va[i] < vb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// goVersionMarkers 只在某个 Go 版本及之后出现的 runtime 函数，用于推断版本下限
+// 按版本从新到旧排列
+var goVersionMarkers = []struct {
+	version  string
+	funcName string
+}{
+	{"go1.24", "runtime.mallocgcSmallNoscan"},
+	{"go1.24", "runtime.mallocgcLarge"},
+	{"go1.22", "runtime.(*mspan).typePointersOfUnchecked"},
+	{"go1.19", "runtime.findRunnable"},
+}
+
+// DetectGoVersion 读取 profile 对应的 Go 版本
+// 优先使用注释中的版本号（如 go1.22.3），其次根据特定版本才有的 runtime 函数推断版本下限，
+// 都没有时返回空字符串
+func DetectGoVersion(p *profile.Profile) string {
+	if p == nil {
+		return ""
+	}
+	for _, comment := range p.Comments {
+		if match := goVersionPattern.FindString(comment); match != "" {
+			return match
+		}
+	}
+
+	names := make(map[string]bool, len(p.Function))
+	for _, fn := range p.Function {
+		if fn != nil {
+			names[fn.Name] = true
+		}
+	}
+	for _, marker := range goVersionMarkers {
+		if names[marker.funcName] {
+			return marker.version
+		}
+	}
+	return ""
+}
+
+// gcSampleFraction 返回调用栈中包含 GC 帧的样本占比（按 valueIndex 加权）
+func gcSampleFraction(p *profile.Profile, matcher *RuntimeFrameMatcher, valueIndex int) float64 {
+	var total, gc int64
+	for _, sample := range p.Sample {
+		if len(sample.Value) <= valueIndex {
+			continue
+		}
+		value := sample.Value[valueIndex]
+		total += value
+		for _, name := range sampleFunctions(sample) {
+			if matcher.IsGCFrame(name) {
+				gc += value
+				break
+			}
+		}
+	}
+	if total <= 0 {
+		return 0
+	}
+	return float64(gc) / float64(total)
+}
@@ -0,0 +1,118 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyRuntimeFrame(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected RuntimeFrameKind
+	}{
+		{"runtime.gcBgMarkWorker", RuntimeFrameGC},
+		{"runtime.gcDrain", RuntimeFrameGC},
+		{"runtime.gcDrainMarkWorkerDedicated", RuntimeFrameGC},
+		{"runtime.scanobject", RuntimeFrameGC},
+		{"runtime.markroot", RuntimeFrameGC},
+		{"runtime.gcAssistAlloc1", RuntimeFrameGC},
+		{"runtime.(*gcWork).tryGet", RuntimeFrameGC},
+		{"runtime.(*mspan).sweep", RuntimeFrameGC},
+		{"runtime.bgsweep", RuntimeFrameGC},
+		// Go 1.24 拆分后的 mallocgc
+		{"runtime.mallocgc", RuntimeFrameAlloc},
+		{"runtime.mallocgcSmallNoscan", RuntimeFrameAlloc},
+		{"runtime.mallocgcLarge", RuntimeFrameAlloc},
+		{"runtime.growslice", RuntimeFrameAlloc},
+		{"runtime.(*mcache).refill", RuntimeFrameAlloc},
+		// Go 1.19 前后的 findrunnable
+		{"runtime.findrunnable", RuntimeFrameScheduler},
+		{"runtime.findRunnable", RuntimeFrameScheduler},
+		{"runtime.gopark", RuntimeFrameScheduler},
+		{"runtime.selectgo", RuntimeFrameScheduler},
+		{"runtime.memmove", RuntimeFrameNone},
+		{"main.gcDrain", RuntimeFrameNone},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, ClassifyRuntimeFrame(tt.name), tt.name)
+	}
+}
+
+func TestRuntimeFrameMatcher_Versioned(t *testing.T) {
+	// typePointers 只在 Go 1.22 及之后的模式集中
+	old := NewRuntimeFrameMatcher("go1.21.5")
+	assert.False(t, old.IsGCFrame("runtime.typePointers.next"))
+	assert.True(t, old.IsGCFrame("runtime.scanobject"))
+	assert.Equal(t, "go1.21.5", old.GoVersion())
+
+	current := NewRuntimeFrameMatcher("go1.22")
+	assert.True(t, current.IsGCFrame("runtime.typePointers.next"))
+	assert.False(t, current.IsGCFrame("runtime.scanSpan"))
+
+	assert.True(t, NewRuntimeFrameMatcher("go1.25.1").IsGCFrame("runtime.scanSpan"))
+
+	// 版本未知时启用全部模式
+	unknown := NewRuntimeFrameMatcher("")
+	assert.True(t, unknown.IsGCFrame("runtime.typePointers.next"))
+	assert.True(t, unknown.IsGCFrame("runtime.scanSpan"))
+}
+
+func TestCompareGoVersions(t *testing.T) {
+	assert.Equal(t, 0, CompareGoVersions("go1.22", "go1.22.0"))
+	assert.Equal(t, -1, CompareGoVersions("go1.21.9", "go1.22"))
+	assert.Equal(t, 1, CompareGoVersions("go1.22.1", "go1.22"))
+	assert.Equal(t, -1, CompareGoVersions("go1.9", "go1.10"))
+	assert.Equal(t, -1, CompareGoVersions("devel", "go1.0"))
+
+	assert.Equal(t, []int{1, 21, 3}, ParseGoVersion("built with go1.21.3"))
+	assert.Nil(t, ParseGoVersion("unknown"))
+}
+
+func TestDetectGoVersion(t *testing.T) {
+	t.Run("from comments", func(t *testing.T) {
+		p := &profile.Profile{Comments: []string{"service=api", "go version go1.22.3 linux/amd64"}}
+		assert.Equal(t, "go1.22.3", DetectGoVersion(p))
+	})
+
+	t.Run("inferred from runtime functions", func(t *testing.T) {
+		p := &profile.Profile{Function: []*profile.Function{
+			{Name: "main.main"},
+			{Name: "runtime.findRunnable"},
+		}}
+		assert.Equal(t, "go1.19", DetectGoVersion(p))
+
+		p.Function = append(p.Function, &profile.Function{Name: "runtime.mallocgcSmallNoscan"})
+		assert.Equal(t, "go1.24", DetectGoVersion(p))
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		assert.Equal(t, "", DetectGoVersion(&profile.Profile{}))
+		assert.Equal(t, "", DetectGoVersion(nil))
+	})
+}
+
+func TestExtractMetrics_GCFraction(t *testing.T) {
+	newSample := func(cpu int64, funcNames ...string) *profile.Sample {
+		s := newStackSample(0, funcNames...)
+		s.Value = []int64{1, cpu}
+		return s
+	}
+	p := &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "samples", Unit: "count"}, {Type: "cpu", Unit: "nanoseconds"}},
+		Comments:   []string{"go1.21.0"},
+		Sample: []*profile.Sample{
+			newSample(30, "runtime.scanobject", "runtime.gcDrain", "runtime.gcBgMarkWorker"),
+			newSample(10, "runtime.typePointers.next", "runtime.gcDrain"),
+			newSample(60, "main.compute", "main.main"),
+		},
+	}
+
+	metrics := ExtractMetrics(p, "cpu")
+	require.NotNil(t, metrics)
+	assert.Equal(t, "go1.21.0", metrics.GoVersion)
+	assert.InDelta(t, 0.4, metrics.GCFraction, 0.0001)
+}
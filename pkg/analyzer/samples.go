@@ -0,0 +1,53 @@
+package analyzer
+
+import "sort"
+
+// DefaultUndersampledRatio 样本数低于组内中位数的 50% 视为采样不足
+const DefaultUndersampledRatio = 0.5
+
+// TotalSamples 返回组内所有文件的样本数之和
+func (g ProfileGroup) TotalSamples() int64 {
+	var total int64
+	for _, file := range g.Files {
+		if file.Metrics != nil {
+			total += file.Metrics.TotalSamples
+		}
+	}
+	return total
+}
+
+// UndersampledFiles 返回样本数低于组内中位数 ratio 倍的文件路径
+// 样本数决定了快照的统计权重，采样不足的快照容易让趋势拟合失真。
+// 少于 3 个文件时没有可靠的参照，返回 nil
+func UndersampledFiles(files []ProfileFile, ratio float64) map[string]bool {
+	if len(files) < 3 {
+		return nil
+	}
+
+	counts := make([]int64, 0, len(files))
+	for _, file := range files {
+		if file.Metrics != nil {
+			counts = append(counts, file.Metrics.TotalSamples)
+		}
+	}
+	if len(counts) < 3 {
+		return nil
+	}
+	sort.Slice(counts, func(i, j int) bool { return counts[i] < counts[j] })
+	median := float64(counts[len(counts)/2])
+	if len(counts)%2 == 0 {
+		median = float64(counts[len(counts)/2-1]+counts[len(counts)/2]) / 2
+	}
+
+	var result map[string]bool
+	for _, file := range files {
+		if file.Metrics == nil || float64(file.Metrics.TotalSamples) >= median*ratio {
+			continue
+		}
+		if result == nil {
+			result = make(map[string]bool)
+		}
+		result[file.Path] = true
+	}
+	return result
+}
@@ -0,0 +1,39 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newSampledFile 创建带样本数的文件
+func newSampledFile(path string, samples int64) ProfileFile {
+	return ProfileFile{Path: path, Metrics: &ProfileMetrics{TotalSamples: samples}}
+}
+
+func TestProfileGroup_TotalSamples(t *testing.T) {
+	group := ProfileGroup{Files: []ProfileFile{
+		newSampledFile("a", 100),
+		newSampledFile("b", 250),
+		{Path: "c"},
+	}}
+	assert.Equal(t, int64(350), group.TotalSamples())
+	assert.Equal(t, int64(0), ProfileGroup{}.TotalSamples())
+}
+
+func TestUndersampledFiles(t *testing.T) {
+	files := []ProfileFile{
+		newSampledFile("a", 1000),
+		newSampledFile("b", 1200),
+		newSampledFile("c", 300),
+		newSampledFile("d", 900),
+	}
+
+	// 中位数 950，低于 475 的文件采样不足
+	assert.Equal(t, map[string]bool{"c": true}, UndersampledFiles(files, DefaultUndersampledRatio))
+	assert.Nil(t, UndersampledFiles(files, 0.3))
+
+	// 文件太少时不判断
+	assert.Nil(t, UndersampledFiles(files[:2], DefaultUndersampledRatio))
+	assert.Nil(t, UndersampledFiles([]ProfileFile{files[0], files[2], {Path: "e"}}, DefaultUndersampledRatio))
+}
@@ -0,0 +1,49 @@
+package analyzer
+
+import "math"
+
+// GC 锯齿检测参数
+const (
+	// SawtoothDropRatio 相邻快照下降超过序列振幅 (最大值 - 最小值) 的该比例时，视为一次 GC 回落
+	SawtoothDropRatio = 0.3
+	// SawtoothMinDrops 至少出现该次数的回落才视为锯齿
+	SawtoothMinDrops = 2
+	// SawtoothTroughTolerance 最后一次回落的谷值比第一次高出不超过振幅的该比例时，视为基线没有抬升
+	SawtoothTroughTolerance = 0.2
+)
+
+// DetectSawtooth 判断 heap 存活内存序列是否呈正常的 GC 锯齿：反复上升后明显回落，且回落后的谷值没有持续抬升
+// 每次回落之前都要有上升 (持续下降不算锯齿)；谷值逐次抬升说明 GC 之后仍有内存留存，仍按泄漏处理
+func DetectSawtooth(values []float64) bool {
+	if len(values) < 2*SawtoothMinDrops {
+		return false
+	}
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) || v < 0 {
+			return false
+		}
+		low, high = math.Min(low, v), math.Max(high, v)
+	}
+	amplitude := high - low
+	if amplitude <= 0 {
+		return false
+	}
+
+	var troughs []float64
+	base := math.Inf(-1) // 上一次回落后的谷值，下一次回落前的峰值必须高于它
+	for i := 0; i+1 < len(values); i++ {
+		if values[i]-values[i+1] < SawtoothDropRatio*amplitude {
+			continue
+		}
+		if values[i] <= base {
+			return false
+		}
+		troughs = append(troughs, values[i+1])
+		base = values[i+1]
+	}
+	if len(troughs) < SawtoothMinDrops {
+		return false
+	}
+	return troughs[len(troughs)-1]-troughs[0] <= SawtoothTroughTolerance*amplitude
+}
@@ -0,0 +1,49 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectSawtooth(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   bool
+	}{
+		{"regular gc cycles", []float64{100, 150, 200, 100, 150, 200, 100, 150, 200}, true},
+		{"starts at a peak", []float64{200, 100, 200, 110, 200, 105}, true},
+		{"strictly increasing", []float64{100, 120, 140, 160, 180, 200}, false},
+		{"rising troughs", []float64{100, 200, 120, 220, 140, 240, 160, 260}, false},
+		{"single drop", []float64{100, 150, 200, 100, 120, 140}, false},
+		{"step down", []float64{300, 200, 100, 100}, false},
+		{"flat", []float64{100, 100, 100, 100}, false},
+		{"too few points", []float64{100, 200, 100}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, DetectSawtooth(tt.values))
+		})
+	}
+}
+
+// TestCalculateTrends_HeapSawtooth 锯齿状的 inuse_space 不判为增长，持续增长的序列不受影响
+func TestCalculateTrends_HeapSawtooth(t *testing.T) {
+	newGroup := func(values ...int64) ProfileGroup {
+		group := ProfileGroup{Type: "heap"}
+		for _, v := range values {
+			group.Files = append(group.Files, ProfileFile{Metrics: &ProfileMetrics{InuseSpace: v}})
+		}
+		return group
+	}
+
+	sawtooth := CalculateTrends(newGroup(100, 150, 200, 100, 150, 200, 100, 150, 200))
+	assert.True(t, sawtooth.HeapSawtooth)
+	assert.Equal(t, "stable", sawtooth.HeapInuse.Direction)
+	assert.Greater(t, sawtooth.HeapInuse.Slope, 0.0, "the fitted slope is kept")
+
+	increasing := CalculateTrends(newGroup(100, 120, 140, 160, 180, 200))
+	assert.False(t, increasing.HeapSawtooth)
+	assert.Equal(t, "increasing", increasing.HeapInuse.Direction)
+}
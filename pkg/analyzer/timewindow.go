@@ -0,0 +1,65 @@
+package analyzer
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeWindow 只分析采集时间在 [Since, Until] 内的快照，零值表示该端不限制
+type TimeWindow struct {
+	Since time.Time
+	Until time.Time
+}
+
+// IsZero 判断时间窗口两端都不限制
+func (w TimeWindow) IsZero() bool {
+	return w.Since.IsZero() && w.Until.IsZero()
+}
+
+// Contains 判断时间 t 是否在窗口内，两端都包含
+func (w TimeWindow) Contains(t time.Time) bool {
+	if !w.Since.IsZero() && t.Before(w.Since) {
+		return false
+	}
+	if !w.Until.IsZero() && t.After(w.Until) {
+		return false
+	}
+	return true
+}
+
+// String 返回 "[since, until]" 格式的窗口描述，不限制的一端为 "-"
+func (w TimeWindow) String() string {
+	bound := func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.Format(time.RFC3339)
+	}
+	return fmt.Sprintf("[%s, %s]", bound(w.Since), bound(w.Until))
+}
+
+// FilterTimeWindow 按采集时间过滤各组的快照，去掉过滤后为空的组，返回过滤后的分组和排除的快照数
+// 在计算趋势之前过滤，趋势只反映窗口内的快照
+func FilterTimeWindow(groups []ProfileGroup, window TimeWindow) ([]ProfileGroup, int) {
+	if window.IsZero() {
+		return groups, 0
+	}
+
+	excluded := 0
+	result := make([]ProfileGroup, 0, len(groups))
+	for _, group := range groups {
+		files := make([]ProfileFile, 0, len(group.Files))
+		for _, file := range group.Files {
+			if window.Contains(file.Time) {
+				files = append(files, file)
+			} else {
+				excluded++
+			}
+		}
+		if len(files) > 0 {
+			group.Files = files
+			result = append(result, group)
+		}
+	}
+	return result, excluded
+}
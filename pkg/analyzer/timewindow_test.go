@@ -0,0 +1,77 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func windowTime(minute int) time.Time {
+	return time.Date(2023, 11, 15, 14, minute, 0, 0, time.UTC)
+}
+
+func TestFilterTimeWindow(t *testing.T) {
+	groups := []ProfileGroup{
+		{Type: "heap", Files: []ProfileFile{
+			dedupFile("heap1.pprof", 0, 100),
+			dedupFile("heap2.pprof", 10, 200),
+			dedupFile("heap3.pprof", 20, 300),
+			dedupFile("heap4.pprof", 30, 400),
+		}},
+		{Type: "cpu", Files: []ProfileFile{{Path: "cpu1.pprof", Time: windowTime(40)}}},
+	}
+
+	// 两端都包含，过滤后为空的组被去掉
+	filtered, excluded := FilterTimeWindow(groups, TimeWindow{Since: windowTime(10), Until: windowTime(30)})
+	assert.Equal(t, 2, excluded)
+	require.Len(t, filtered, 1)
+	assert.Equal(t, "heap", filtered[0].Type)
+	require.Len(t, filtered[0].Files, 3)
+	assert.Equal(t, "heap2.pprof", filtered[0].Files[0].Path)
+	assert.Equal(t, "heap4.pprof", filtered[0].Files[2].Path)
+	assert.Len(t, groups[0].Files, 4, "不修改输入的分组")
+
+	// 只限制一端
+	filtered, excluded = FilterTimeWindow(groups, TimeWindow{Since: windowTime(25)})
+	assert.Equal(t, 3, excluded)
+	require.Len(t, filtered, 2)
+	assert.Len(t, filtered[0].Files, 1)
+
+	filtered, excluded = FilterTimeWindow(groups, TimeWindow{Until: windowTime(5)})
+	assert.Equal(t, 4, excluded)
+	require.Len(t, filtered, 1)
+	assert.Equal(t, "heap1.pprof", filtered[0].Files[0].Path)
+
+	// 窗口外没有快照时返回空分组
+	filtered, excluded = FilterTimeWindow(groups, TimeWindow{Since: windowTime(50)})
+	assert.Equal(t, 5, excluded)
+	assert.Empty(t, filtered)
+
+	// 不限制时原样返回
+	filtered, excluded = FilterTimeWindow(groups, TimeWindow{})
+	assert.Equal(t, 0, excluded)
+	assert.Equal(t, groups, filtered)
+}
+
+// TestFilterTimeWindow_TooFewForTrends 窗口内快照少于趋势所需的数量时不计算趋势
+func TestFilterTimeWindow_TooFewForTrends(t *testing.T) {
+	groups := []ProfileGroup{{Type: "heap", Files: []ProfileFile{
+		dedupFile("heap1.pprof", 0, 100),
+		dedupFile("heap2.pprof", 10, 200),
+		dedupFile("heap3.pprof", 20, 300),
+		dedupFile("heap4.pprof", 30, 400),
+	}}}
+	require.NotNil(t, CalculateTrends(groups[0]))
+
+	filtered, _ := FilterTimeWindow(groups, TimeWindow{Since: windowTime(15)})
+	require.Len(t, filtered, 1)
+	assert.Nil(t, CalculateTrends(filtered[0]))
+}
+
+func TestTimeWindow_String(t *testing.T) {
+	assert.Equal(t, "[2023-11-15T14:10:00Z, -]", TimeWindow{Since: windowTime(10)}.String())
+	assert.Equal(t, "[-, 2023-11-15T14:30:00Z]", TimeWindow{Until: windowTime(30)}.String())
+	assert.True(t, TimeWindow{}.IsZero())
+}
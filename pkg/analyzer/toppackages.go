@@ -0,0 +1,67 @@
+package analyzer
+
+import (
+	"sort"
+
+	"github.com/google/pprof/profile"
+)
+
+// DefaultTopPackagesN 每个 CPU profile 保留的 Top 包数
+const DefaultTopPackagesN = 10
+
+// unknownPackage 栈顶没有符号信息的样本归入的包名
+const unknownPackage = "<unknown>"
+
+// PackageStat 单个包的 CPU flat 时间
+type PackageStat struct {
+	Package string
+	Flat    int64   // 栈顶函数属于该包的 CPU 时间 (纳秒)
+	FlatPct float64 // 占全部 CPU 时间的百分比 (0-100)
+}
+
+// extractTopPackages 按栈顶函数所在包汇总 CPU flat 时间，按 flat 降序返回前 n 个包
+// 同一个包的开销可能分散在许多函数上，单个函数都排不进 Top 列表，按包汇总后才能看出来。
+// 内联展开时栈顶取最内层的函数；包名与 locator.ExtractPackageName 一致，方法接收者归入所在包；
+// 栈顶没有符号信息的样本计入 <unknown>，因此所有包的 FlatPct 之和为 100
+func extractTopPackages(p *profile.Profile, n int, valueIndex int, scale int64) []PackageStat {
+	if p == nil || valueIndex < 0 {
+		return nil
+	}
+
+	flat := make(map[string]int64)
+	var total int64
+	for _, sample := range p.Sample {
+		if len(sample.Value) <= valueIndex || sample.Value[valueIndex] <= 0 {
+			continue
+		}
+		value := sample.Value[valueIndex]
+		total += value
+		pkg := samplePackage(sample)
+		if pkg == "" {
+			pkg = unknownPackage
+		}
+		flat[pkg] += value
+	}
+	if total == 0 {
+		return nil
+	}
+
+	stats := make([]PackageStat, 0, len(flat))
+	for pkg, value := range flat {
+		stats = append(stats, PackageStat{
+			Package: pkg,
+			Flat:    value * scale,
+			FlatPct: float64(value) / float64(total) * 100,
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Flat != stats[j].Flat {
+			return stats[i].Flat > stats[j].Flat
+		}
+		return stats[i].Package < stats[j].Package
+	})
+	if n > 0 && len(stats) > n {
+		stats = stats[:n]
+	}
+	return stats
+}
@@ -0,0 +1,80 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCPUStackSample 创建 CPU 样本，funcNames 从栈顶到栈底排列；没有函数名时为无符号信息的样本
+func newCPUStackSample(cpu int64, funcNames ...string) *profile.Sample {
+	sample := newStackSample(0, funcNames...)
+	if len(funcNames) == 0 {
+		sample.Location = []*profile.Location{{ID: 1, Address: 0x1000}}
+	}
+	sample.Value = []int64{1, cpu}
+	return sample
+}
+
+func TestExtractTopPackages(t *testing.T) {
+	p := &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "samples", Unit: "count"}, {Type: "cpu", Unit: "nanoseconds"}},
+		Sample: []*profile.Sample{
+			// encoding/json 的开销分散在多个函数上，单个函数都不如 main.work，按包汇总后排第一
+			newCPUStackSample(20, "encoding/json.(*decodeState).object", "main.main"),
+			newCPUStackSample(20, "encoding/json.Marshal", "main.main"),
+			newCPUStackSample(15, "encoding/json.(*encodeState).string", "main.main"),
+			newCPUStackSample(30, "main.work", "main.main"),
+			newCPUStackSample(10, "github.com/myapp/cache.(*LRU).Get", "main.work"),
+			newCPUStackSample(5),
+		},
+	}
+
+	stats := extractTopPackages(p, 10, 1, 1)
+	require.Len(t, stats, 4)
+	want := []PackageStat{
+		{Package: "encoding/json", Flat: 55, FlatPct: 55},
+		{Package: "main", Flat: 30, FlatPct: 30},
+		{Package: "github.com/myapp/cache", Flat: 10, FlatPct: 10},
+		{Package: unknownPackage, Flat: 5, FlatPct: 5},
+	}
+	for i, w := range want {
+		assert.Equal(t, w.Package, stats[i].Package)
+		assert.Equal(t, w.Flat, stats[i].Flat)
+		assert.InDelta(t, w.FlatPct, stats[i].FlatPct, 1e-9)
+	}
+
+	var total float64
+	for _, s := range stats {
+		total += s.FlatPct
+	}
+	assert.InDelta(t, 100, total, 1e-9)
+
+	// 只保留前 n 个包
+	assert.Len(t, extractTopPackages(p, 2, 1, 1), 2)
+	// 缺少 CPU 列或没有样本时返回 nil
+	assert.Nil(t, extractTopPackages(p, 10, -1, 1))
+	assert.Nil(t, extractTopPackages(&profile.Profile{}, 10, 1, 1))
+}
+
+// TestExtractMetrics_TopPackagesPeriod 只有 samples/count 列时按 Period 换算为 CPU 时间
+func TestExtractMetrics_TopPackagesPeriod(t *testing.T) {
+	sample := newStackSample(0, "encoding/json.Marshal", "main.main")
+	sample.Value = []int64{3}
+	p := &profile.Profile{
+		SampleType:    []*profile.ValueType{{Type: "samples", Unit: "count"}},
+		PeriodType:    &profile.ValueType{Type: "cpu", Unit: "nanoseconds"},
+		Period:        int64(10 * time.Millisecond),
+		DurationNanos: int64(time.Second),
+		Sample:        []*profile.Sample{sample},
+	}
+
+	metrics := ExtractMetrics(p, "cpu")
+	require.NotNil(t, metrics)
+	require.Len(t, metrics.TopPackages, 1)
+	assert.Equal(t, int64(30*time.Millisecond), metrics.TopPackages[0].Flat)
+	assert.Equal(t, 100.0, metrics.TopPackages[0].FlatPct)
+}
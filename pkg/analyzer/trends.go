@@ -1,7 +1,10 @@
 package analyzer
 
 import (
+	"fmt"
 	"math"
+	"strings"
+	"time"
 )
 
 // TrendMetrics 趋势指标
@@ -9,60 +12,319 @@ type TrendMetrics struct {
 	Slope     float64 // 斜率
 	R2        float64 // R² 决定系数
 	Direction string  // "increasing", "decreasing", "stable"
+	Points    int     // 参与拟合的数据点数
+
+	// 数据序列的均值、最小值、最大值和总体标准差 (不加权)
+	// 斜率相同时，标准差可以区分平稳增长的序列和剧烈波动的序列
+	Mean   float64
+	Min    float64
+	Max    float64
+	StdDev float64
+
+	// 加权回归信息，Weighting 为 WeightingNone 且没有 GC 阶段降权时 Weights 为空
+	Weighting string    // "duration", "samples", "none"
+	Weights   []float64 // 每个数据点的权重，按文件顺序，已包含 GC 阶段降权
+
+	// 离群快照，未检测到时为空；WithoutOutliers 为排除离群快照后的拟合结果
+	Outliers        []TrendOutlier
+	WithoutOutliers *TrendMetrics
+
+	// 疑似在 GC 前后极值处采集的快照 (仅 heap 的 inuse_space/inuse_objects)，在拟合中按 GCPhaseWeight 降权；
+	// GCPhaseSensitive 表示这类快照较多，趋势可能只是 GC 锯齿
+	GCPhase          []GCPhaseSkew
+	GCPhaseSensitive bool
 }
 
+// 趋势回归的加权方式
+const (
+	WeightingDuration = "duration" // 按 profile 采集时长加权
+	WeightingSamples  = "samples"  // 按 profile 样本数加权
+	WeightingNone     = "none"     // 不加权
+)
+
 // GroupTrends 分组趋势数据
 type GroupTrends struct {
-	HeapInuse      *TrendMetrics // 堆内存使用趋势
-	GoroutineCount *TrendMetrics // Goroutine 数量趋势
+	HeapInuse        *TrendMetrics // 堆内存使用趋势 (inuse_space，规则评估使用)
+	HeapInuseObjects *TrendMetrics // 堆对象数趋势 (inuse_objects，规则评估使用)，小对象泄漏时空间趋势可能平稳
+	GoroutineCount   *TrendMetrics // Goroutine 数量趋势
+	// GoroutineGrowthKind goroutine 数的增长形态: GoroutineGrowthSustained、GoroutineGrowthPlateau 或 GoroutineGrowthStable，仅 goroutine 分组
+	GoroutineGrowthKind string
+
+	// HeapTrends 按 heap sample type 计算的趋势，HeapSampleType 为报告展示的类型
+	HeapTrends     map[string]*TrendMetrics
+	HeapSampleType string
+
+	// HeapSawtooth inuse_space 呈正常的 GC 锯齿 (见 DetectSawtooth)，此时 HeapInuse 的方向记为 stable，仅 heap 分组
+	HeapSawtooth bool
+}
+
+// SelectedHeapTrend 返回报告展示的 heap 趋势，未配置时使用 inuse_space
+func (g *GroupTrends) SelectedHeapTrend() *TrendMetrics {
+	if g == nil {
+		return nil
+	}
+	if t, ok := g.HeapTrends[g.HeapSampleType]; ok {
+		return t
+	}
+	return g.HeapInuse
+}
+
+// heap profile 的 sample type，可用于选择趋势分析的数据序列
+const (
+	HeapSampleInuseSpace   = "inuse_space"
+	HeapSampleAllocSpace   = "alloc_space"
+	HeapSampleInuseObjects = "inuse_objects"
+	HeapSampleAllocObjects = "alloc_objects"
+)
+
+// heapSampleTypes 所有支持的 heap sample type
+var heapSampleTypes = []string{HeapSampleInuseSpace, HeapSampleAllocSpace, HeapSampleInuseObjects, HeapSampleAllocObjects}
+
+// ParseHeapSampleType 校验 heap 趋势的 sample type，空字符串返回默认的 inuse_space
+func ParseHeapSampleType(name string) (string, error) {
+	if name == "" {
+		return HeapSampleInuseSpace, nil
+	}
+	for _, t := range heapSampleTypes {
+		if name == t {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("invalid heap sample type '%s', must be one of: %s", name, strings.Join(heapSampleTypes, ", "))
+}
+
+// IsHeapObjectSampleType 判断 heap sample type 是否以对象数为单位
+func IsHeapObjectSampleType(sampleType string) bool {
+	return sampleType == HeapSampleInuseObjects || sampleType == HeapSampleAllocObjects
+}
+
+// HeapSampleValue 返回 profile 指标中指定 heap sample type 的值
+func HeapSampleValue(m *ProfileMetrics, sampleType string) int64 {
+	if m == nil {
+		return 0
+	}
+	switch sampleType {
+	case HeapSampleAllocSpace:
+		return m.AllocSpace
+	case HeapSampleInuseObjects:
+		return m.InuseObjects
+	case HeapSampleAllocObjects:
+		return m.AllocObjects
+	default:
+		return m.InuseSpace
+	}
+}
+
+// DefaultMinTrendPoints 计算趋势所需的默认最少数据点数
+const DefaultMinTrendPoints = 3
+
+// TrendConfig 趋势计算配置
+type TrendConfig struct {
+	HeapSampleType string // 报告展示的 heap 趋势 sample type
+	MinPoints      int    // 计算趋势所需的最少数据点数，<= 0 时使用 DefaultMinTrendPoints
+}
+
+// DefaultTrendConfig 返回默认的趋势计算配置
+func DefaultTrendConfig() TrendConfig {
+	return TrendConfig{HeapSampleType: HeapSampleInuseSpace, MinPoints: DefaultMinTrendPoints}
+}
+
+// MinTrendPoints 返回 n，n <= 0 时返回 DefaultMinTrendPoints
+func MinTrendPoints(n int) int {
+	if n <= 0 {
+		return DefaultMinTrendPoints
+	}
+	return n
 }
 
-// CalculateTrends 计算 profile 组的趋势
-// 需要至少 3 个文件才能计算趋势
+// 趋势指标名称，与规则条件中的 trends.<metric> 保持一致
+const (
+	MetricHeapInuse        = "heap_inuse"
+	MetricHeapInuseObjects = "heap_inuse_objects"
+	MetricGoroutineCount   = "goroutine_count"
+)
+
+// DefaultMinR2 趋势展示的默认 R² 阈值
+const DefaultMinR2 = 0.7
+
+// TrendThresholds 趋势展示的 R² 阈值配置
+// Default 对所有指标生效，Metrics 可按指标单独覆盖
+type TrendThresholds struct {
+	Default float64            // 默认阈值
+	Metrics map[string]float64 // 指标级覆盖 (heap_inuse / heap_inuse_objects / goroutine_count)
+}
+
+// DefaultTrendThresholds 返回默认的趋势展示阈值
+func DefaultTrendThresholds() TrendThresholds {
+	return TrendThresholds{Default: DefaultMinR2}
+}
+
+// MinR2 返回指定指标的 R² 阈值
+func (t TrendThresholds) MinR2(metric string) float64 {
+	if v, ok := t.Metrics[metric]; ok {
+		return v
+	}
+	return t.Default
+}
+
+// IsSignificant 判断趋势是否达到展示阈值（R² 严格大于阈值）
+func (t TrendThresholds) IsSignificant(metric string, trend *TrendMetrics) bool {
+	return trend != nil && trend.R2 > t.MinR2(metric)
+}
+
+// CalculateTrends 计算 profile 组的趋势，使用默认配置
 func CalculateTrends(group ProfileGroup) *GroupTrends {
-	if len(group.Files) < 3 {
+	return CalculateTrendsWithConfig(group, DefaultTrendConfig())
+}
+
+// CalculateTrendsWithConfig 使用指定配置计算 profile 组的趋势
+// 需要至少 config.MinPoints 个文件 (默认 3 个) 才能计算趋势，被 MarkDuplicateFiles 标记为重复的快照不计入
+// 所有数据点都带有采集时长或样本数时使用加权最小二乘，降低短时低质量快照对斜率和 R² 的影响
+func CalculateTrendsWithConfig(group ProfileGroup, config TrendConfig) *GroupTrends {
+	minPoints := MinTrendPoints(config.MinPoints)
+	if len(group.Files)-countDuplicates(group.Files) < minPoints {
 		return nil
 	}
 
 	trends := &GroupTrends{}
 
+	points, times := trendFiles(group.Files)
+	if len(points) < minPoints {
+		return trends
+	}
+
 	switch group.Type {
 	case "heap":
-		// 从 Metrics 中提取堆内存数据点
-		var heapValues []float64
-		for _, file := range group.Files {
-			if file.Metrics != nil {
-				heapValues = append(heapValues, float64(file.Metrics.InuseSpace))
+		// 每种 sample type 各计算一条趋势，规则评估使用 inuse_space 和 inuse_objects
+		trends.HeapTrends = make(map[string]*TrendMetrics, len(heapSampleTypes))
+		for _, sampleType := range heapSampleTypes {
+			heapValues := make([]float64, len(points))
+			for i, m := range points {
+				heapValues[i] = float64(HeapSampleValue(m, sampleType))
 			}
-		}
-		if len(heapValues) >= 3 {
-			slope, r2 := LinearRegression(heapValues)
-			trends.HeapInuse = &TrendMetrics{
-				Slope:     slope,
-				R2:        r2,
-				Direction: getDirection(slope),
+			if sampleType == HeapSampleInuseSpace {
+				trends.HeapSawtooth = DetectSawtooth(heapValues)
 			}
+			if sampleType == HeapSampleInuseSpace || sampleType == HeapSampleInuseObjects {
+				// 存活内存随 GC 周期呈锯齿状，alloc_* 是累计值不受影响
+				trends.HeapTrends[sampleType] = calculateInuseTrend(heapValues, points, times)
+			} else {
+				trends.HeapTrends[sampleType] = calculateTrend(heapValues, points, times)
+			}
+		}
+		trends.HeapInuse = trends.HeapTrends[HeapSampleInuseSpace]
+		if trends.HeapSawtooth && trends.HeapInuse.Direction == "increasing" {
+			// 回落后谷值没有抬升，拟合出的增长只是采集时所处的 GC 阶段不同
+			trends.HeapInuse.Direction = "stable"
+		}
+		trends.HeapInuseObjects = trends.HeapTrends[HeapSampleInuseObjects]
+		trends.HeapSampleType = config.HeapSampleType
+		if trends.HeapSampleType == "" {
+			trends.HeapSampleType = HeapSampleInuseSpace
 		}
 
 	case "goroutine":
 		// 从 Metrics 中提取 goroutine 数量数据点
-		var goroutineValues []float64
-		for _, file := range group.Files {
-			if file.Metrics != nil {
-				goroutineValues = append(goroutineValues, float64(file.Metrics.GoroutineCount))
-			}
+		goroutineValues := make([]float64, len(points))
+		for i, m := range points {
+			goroutineValues[i] = float64(m.GoroutineCount)
 		}
-		if len(goroutineValues) >= 3 {
-			slope, r2 := LinearRegression(goroutineValues)
-			trends.GoroutineCount = &TrendMetrics{
-				Slope:     slope,
-				R2:        r2,
-				Direction: getDirection(slope),
+		trends.GoroutineCount = calculateTrend(goroutineValues, points, times)
+		trends.GoroutineGrowthKind = ClassifyGoroutineGrowth(goroutineValues)
+	}
+
+	return trends
+}
+
+// calculateTrend 根据数据点计算单个指标的趋势，并检测破坏拟合的离群快照
+// times 为各数据点的快照时间，与 values 一一对应
+func calculateTrend(values []float64, points []*ProfileMetrics, times []time.Time) *TrendMetrics {
+	weights, weighting := TrendWeights(points)
+	return fitTrend(values, weights, weighting, times)
+}
+
+// calculateInuseTrend 计算 heap 存活内存的趋势，疑似在 GC 极值处采集的快照降权后再拟合
+func calculateInuseTrend(values []float64, points []*ProfileMetrics, times []time.Time) *TrendMetrics {
+	weights, weighting := TrendWeights(points)
+	skews := DetectGCPhaseSkew(values)
+	if len(skews) > 0 {
+		weights = gcPhaseWeights(weights, len(values), skews)
+	}
+
+	trend := fitTrend(values, weights, weighting, times)
+	for i := range skews {
+		if skews[i].Index < len(times) {
+			skews[i].Time = times[skews[i].Index]
+		}
+	}
+	trend.GCPhase = skews
+	trend.GCPhaseSensitive = IsGCPhaseSensitive(skews, len(values))
+	return trend
+}
+
+// fitTrend 使用给定权重拟合趋势，并检测破坏拟合的离群快照
+func fitTrend(values, weights []float64, weighting string, times []time.Time) *TrendMetrics {
+	slope, r2 := WeightedLinearRegression(values, weights)
+	trend := &TrendMetrics{
+		Slope:     slope,
+		R2:        r2,
+		Direction: getDirection(slope),
+		Points:    len(values),
+		Weighting: weighting,
+		Weights:   weights,
+	}
+	trend.Mean, trend.Min, trend.Max, trend.StdDev = seriesStats(values)
+
+	indices, expected := detectTrendOutliers(values, weights)
+	if len(indices) > 0 {
+		for _, i := range indices {
+			outlier := TrendOutlier{Index: i, Value: values[i], Expected: expected[i]}
+			if i < len(times) {
+				outlier.Time = times[i]
 			}
+			trend.Outliers = append(trend.Outliers, outlier)
 		}
+		trend.WithoutOutliers = trendWithoutOutliers(values, weights, weighting, indices)
 	}
+	return trend
+}
 
-	return trends
+// TrendWeights 根据 profile 的采集质量计算回归权重
+// 优先使用采集时长，其次使用样本数；任一数据点缺少信息时不加权
+func TrendWeights(points []*ProfileMetrics) ([]float64, string) {
+	if len(points) == 0 {
+		return nil, WeightingNone
+	}
+
+	hasDuration, hasSamples := true, true
+	for _, m := range points {
+		if m == nil || m.Duration <= 0 {
+			hasDuration = false
+		}
+		if m == nil || m.TotalSamples <= 0 {
+			hasSamples = false
+		}
+	}
+
+	var weighting string
+	switch {
+	case hasDuration:
+		weighting = WeightingDuration
+	case hasSamples:
+		weighting = WeightingSamples
+	default:
+		return nil, WeightingNone
+	}
+
+	weights := make([]float64, len(points))
+	for i, m := range points {
+		if weighting == WeightingDuration {
+			weights[i] = m.Duration.Seconds()
+		} else {
+			weights[i] = float64(m.TotalSamples)
+		}
+	}
+	return weights, weighting
 }
 
 // LinearRegression 计算线性回归的斜率和 R²
@@ -155,6 +417,103 @@ func LinearRegression(values []float64) (slope, r2 float64) {
 	return slope, r2
 }
 
+// seriesStats 计算序列的均值、最小值、最大值和总体标准差，空序列全部返回 0
+func seriesStats(values []float64) (mean, min, max, stddev float64) {
+	if len(values) == 0 {
+		return 0, 0, 0, 0
+	}
+	min, max = values[0], values[0]
+	var sum float64
+	for _, v := range values {
+		sum += v
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+	mean = sum / float64(len(values))
+
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	return mean, min, max, math.Sqrt(squares / float64(len(values)))
+}
+
+// WeightedLinearRegression 计算加权线性回归的斜率和 R²
+// weights 为空或长度与 values 不一致时退化为普通最小二乘
+func WeightedLinearRegression(values, weights []float64) (slope, r2 float64) {
+	if len(weights) == 0 || len(weights) != len(values) {
+		return LinearRegression(values)
+	}
+	if len(values) < 2 {
+		return 0, 0
+	}
+
+	// 检查是否有无效值或无效权重
+	var sumW float64
+	for i, v := range values {
+		w := weights[i]
+		if math.IsNaN(v) || math.IsInf(v, 0) ||
+			math.IsNaN(w) || math.IsInf(w, 0) || w < 0 {
+			return 0, 0
+		}
+		sumW += w
+	}
+	if sumW == 0 {
+		return LinearRegression(values)
+	}
+
+	// 计算加权均值
+	var sumWX, sumWY float64
+	for i, y := range values {
+		sumWX += weights[i] * float64(i)
+		sumWY += weights[i] * y
+	}
+	meanX := sumWX / sumW
+	meanY := sumWY / sumW
+
+	// 计算斜率
+	var numerator, denominator float64
+	for i, y := range values {
+		dx := float64(i) - meanX
+		numerator += weights[i] * dx * (y - meanY)
+		denominator += weights[i] * dx * dx
+	}
+	if denominator == 0 {
+		return 0, 0
+	}
+
+	slope = numerator / denominator
+	if math.IsNaN(slope) || math.IsInf(slope, 0) {
+		return 0, 0
+	}
+
+	// 计算加权 R²
+	intercept := meanY - slope*meanX
+	var ssRes, ssTot float64
+	for i, y := range values {
+		predicted := slope*float64(i) + intercept
+		ssRes += weights[i] * (y - predicted) * (y - predicted)
+		ssTot += weights[i] * (y - meanY) * (y - meanY)
+	}
+	if math.IsNaN(ssRes) || math.IsInf(ssRes, 0) ||
+		math.IsNaN(ssTot) || math.IsInf(ssTot, 0) {
+		return 0, 0
+	}
+
+	if ssTot == 0 {
+		// 所有值相同
+		r2 = 1.0
+	} else {
+		r2 = 1 - ssRes/ssTot
+	}
+	if math.IsNaN(r2) || math.IsInf(r2, 0) {
+		r2 = 0
+	}
+	r2 = math.Max(0, math.Min(1, r2))
+
+	return slope, r2
+}
+
 // getDirection 根据斜率判断趋势方向
 func getDirection(slope float64) string {
 	const threshold = 0.01 // 斜率阈值
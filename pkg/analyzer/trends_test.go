@@ -4,8 +4,10 @@ import (
 	"math"
 	"testing"
 	"testing/quick"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestLinearRegression_PerfectLine 测试完美线性数据
@@ -180,6 +182,30 @@ func TestCalculateTrends_InsufficientFiles(t *testing.T) {
 	assert.Nil(t, trends, "少于 3 个文件不应该计算趋势")
 }
 
+// TestCalculateTrendsWithConfig_MinPoints 测试可配置的最少数据点数和趋势的数据点标注
+func TestCalculateTrendsWithConfig_MinPoints(t *testing.T) {
+	var files []ProfileFile
+	for i := 0; i < 4; i++ {
+		files = append(files, ProfileFile{Metrics: &ProfileMetrics{GoroutineCount: int64(100 * (i + 1))}})
+	}
+	group := ProfileGroup{Type: "goroutine", Files: files}
+
+	trends := CalculateTrendsWithConfig(group, TrendConfig{MinPoints: 4})
+	require.NotNil(t, trends)
+	require.NotNil(t, trends.GoroutineCount)
+	assert.Equal(t, 4, trends.GoroutineCount.Points)
+
+	assert.Nil(t, CalculateTrendsWithConfig(group, TrendConfig{MinPoints: 5}), "少于最少数据点数不应该计算趋势")
+
+	// 未设置时使用默认值 3
+	group.Files = files[:3]
+	trends = CalculateTrendsWithConfig(group, TrendConfig{})
+	require.NotNil(t, trends)
+	assert.Equal(t, 3, trends.GoroutineCount.Points)
+	assert.Equal(t, DefaultMinTrendPoints, MinTrendPoints(0))
+	assert.Equal(t, 6, MinTrendPoints(6))
+}
+
 // TestCalculateTrends_EmptyGroup 测试空分组
 func TestCalculateTrends_EmptyGroup(t *testing.T) {
 	group := ProfileGroup{
@@ -190,3 +216,192 @@ func TestCalculateTrends_EmptyGroup(t *testing.T) {
 	trends := CalculateTrends(group)
 	assert.Nil(t, trends)
 }
+
+// TestTrendThresholds 测试趋势展示阈值（含指标级覆盖）
+func TestTrendThresholds(t *testing.T) {
+	thresholds := DefaultTrendThresholds()
+	assert.Equal(t, DefaultMinR2, thresholds.MinR2(MetricHeapInuse))
+
+	trend := &TrendMetrics{Slope: 1, R2: 0.6, Direction: "increasing"}
+	assert.False(t, thresholds.IsSignificant(MetricHeapInuse, trend))
+	assert.False(t, thresholds.IsSignificant(MetricHeapInuse, nil))
+
+	thresholds.Metrics = map[string]float64{MetricGoroutineCount: 0.5}
+	assert.True(t, thresholds.IsSignificant(MetricGoroutineCount, trend))
+	assert.False(t, thresholds.IsSignificant(MetricHeapInuse, trend))
+}
+
+// TestWeightedLinearRegression 测试加权线性回归
+func TestWeightedLinearRegression(t *testing.T) {
+	t.Run("equal weights match unweighted", func(t *testing.T) {
+		values := []float64{1, 3, 2, 5, 4}
+		slope, r2 := LinearRegression(values)
+		wSlope, wR2 := WeightedLinearRegression(values, []float64{2, 2, 2, 2, 2})
+		assert.InDelta(t, slope, wSlope, 1e-9)
+		assert.InDelta(t, r2, wR2, 1e-9)
+	})
+
+	t.Run("nil or mismatched weights fall back", func(t *testing.T) {
+		values := []float64{1, 2, 4}
+		slope, r2 := LinearRegression(values)
+		wSlope, wR2 := WeightedLinearRegression(values, nil)
+		assert.Equal(t, slope, wSlope)
+		assert.Equal(t, r2, wR2)
+		wSlope, wR2 = WeightedLinearRegression(values, []float64{1, 2})
+		assert.Equal(t, slope, wSlope)
+		assert.Equal(t, r2, wR2)
+	})
+
+	t.Run("low weight outlier has less influence", func(t *testing.T) {
+		// 最后一个点是低质量快照的异常值
+		values := []float64{10, 20, 30, 40, 200}
+		slope, r2 := LinearRegression(values)
+		wSlope, wR2 := WeightedLinearRegression(values, []float64{30, 30, 30, 30, 0.1})
+		assert.Greater(t, slope, 35.0)
+		assert.InDelta(t, 10.0, wSlope, 1)
+		assert.Greater(t, wR2, r2)
+	})
+
+	t.Run("invalid weights", func(t *testing.T) {
+		slope, r2 := WeightedLinearRegression([]float64{1, 2, 3}, []float64{1, -1, 1})
+		assert.Equal(t, 0.0, slope)
+		assert.Equal(t, 0.0, r2)
+	})
+}
+
+// TestTrendWeights 测试回归权重的来源选择
+func TestTrendWeights(t *testing.T) {
+	t.Run("duration preferred", func(t *testing.T) {
+		weights, weighting := TrendWeights([]*ProfileMetrics{
+			{Duration: 30 * time.Second, TotalSamples: 10},
+			{Duration: time.Second, TotalSamples: 20},
+		})
+		assert.Equal(t, WeightingDuration, weighting)
+		assert.Equal(t, []float64{30, 1}, weights)
+	})
+
+	t.Run("samples when duration missing", func(t *testing.T) {
+		weights, weighting := TrendWeights([]*ProfileMetrics{
+			{Duration: 30 * time.Second, TotalSamples: 10},
+			{TotalSamples: 20},
+		})
+		assert.Equal(t, WeightingSamples, weighting)
+		assert.Equal(t, []float64{10, 20}, weights)
+	})
+
+	t.Run("unweighted when info missing", func(t *testing.T) {
+		weights, weighting := TrendWeights([]*ProfileMetrics{{TotalSamples: 10}, {}})
+		assert.Equal(t, WeightingNone, weighting)
+		assert.Nil(t, weights)
+	})
+}
+
+// TestCalculateTrends_Weighted 测试趋势计算使用样本质量加权
+func TestCalculateTrends_Weighted(t *testing.T) {
+	durations := []time.Duration{30 * time.Second, 30 * time.Second, time.Second, 30 * time.Second}
+	goroutines := []int64{100, 200, 1000, 400}
+	var files []ProfileFile
+	for i := range durations {
+		files = append(files, ProfileFile{Metrics: &ProfileMetrics{Duration: durations[i], GoroutineCount: goroutines[i]}})
+	}
+
+	trends := CalculateTrends(ProfileGroup{Type: "goroutine", Files: files})
+	require.NotNil(t, trends)
+	require.NotNil(t, trends.GoroutineCount)
+	assert.Equal(t, WeightingDuration, trends.GoroutineCount.Weighting)
+	assert.Equal(t, []float64{30, 30, 1, 30}, trends.GoroutineCount.Weights)
+
+	unweighted, _ := LinearRegression([]float64{100, 200, 1000, 400})
+	assert.Less(t, trends.GoroutineCount.Slope, unweighted)
+	assert.Equal(t, "increasing", trends.GoroutineCount.Direction)
+}
+
+// TestCalculateTrends_SeriesStats 测试趋势的均值、最小值、最大值和标准差
+func TestCalculateTrends_SeriesStats(t *testing.T) {
+	var files []ProfileFile
+	for _, count := range []int64{2, 4, 4, 4, 5, 5, 7, 9} {
+		files = append(files, ProfileFile{Metrics: &ProfileMetrics{GoroutineCount: count}})
+	}
+	trends := CalculateTrends(ProfileGroup{Type: "goroutine", Files: files})
+	require.NotNil(t, trends.GoroutineCount)
+	assert.InDelta(t, 5.0, trends.GoroutineCount.Mean, 1e-9)
+	assert.Equal(t, 2.0, trends.GoroutineCount.Min)
+	assert.Equal(t, 9.0, trends.GoroutineCount.Max)
+	assert.InDelta(t, 2.0, trends.GoroutineCount.StdDev, 1e-9)
+
+	// 斜率相同时，标准差区分平稳增长和剧烈波动的序列
+	heapTrend := func(values ...int64) *TrendMetrics {
+		var files []ProfileFile
+		for _, v := range values {
+			files = append(files, ProfileFile{Metrics: &ProfileMetrics{InuseSpace: v}})
+		}
+		return CalculateTrends(ProfileGroup{Type: "heap", Files: files}).HeapInuse
+	}
+	steady := heapTrend(1000, 1100, 1200, 1300)
+	oscillating := heapTrend(1300, 800, 900, 1600)
+	assert.InDelta(t, steady.Slope, oscillating.Slope, 1e-9)
+	assert.InDelta(t, 111.8, steady.StdDev, 0.1)
+	assert.InDelta(t, 320.2, oscillating.StdDev, 0.1)
+	assert.Equal(t, 1600.0, oscillating.Max)
+	assert.Equal(t, 800.0, oscillating.Min)
+}
+
+// TestParseHeapSampleType 测试 heap 趋势 sample type 解析
+func TestParseHeapSampleType(t *testing.T) {
+	for _, name := range []string{"inuse_space", "alloc_space", "inuse_objects", "alloc_objects"} {
+		got, err := ParseHeapSampleType(name)
+		require.NoError(t, err)
+		assert.Equal(t, name, got)
+	}
+
+	got, err := ParseHeapSampleType("")
+	require.NoError(t, err)
+	assert.Equal(t, HeapSampleInuseSpace, got)
+
+	_, err = ParseHeapSampleType("inuse")
+	assert.Error(t, err)
+}
+
+// TestCalculateTrendsWithConfig_HeapSampleType 测试按 sample type 计算多条 heap 趋势
+func TestCalculateTrendsWithConfig_HeapSampleType(t *testing.T) {
+	var files []ProfileFile
+	for i := int64(0); i < 4; i++ {
+		files = append(files, ProfileFile{Metrics: &ProfileMetrics{
+			InuseSpace:   1000,
+			AllocSpace:   1000 + i*500,
+			InuseObjects: 10 + i*10,
+			AllocObjects: 100,
+		}})
+	}
+	group := ProfileGroup{Type: "heap", Files: files}
+
+	trends := CalculateTrendsWithConfig(group, TrendConfig{HeapSampleType: HeapSampleAllocSpace})
+	require.NotNil(t, trends)
+	require.Len(t, trends.HeapTrends, 4)
+
+	// 规则使用的 HeapInuse 始终是 inuse_space
+	assert.Equal(t, "stable", trends.HeapInuse.Direction)
+	assert.Same(t, trends.HeapTrends[HeapSampleInuseSpace], trends.HeapInuse)
+
+	selected := trends.SelectedHeapTrend()
+	require.NotNil(t, selected)
+	assert.InDelta(t, 500.0, selected.Slope, 1e-9)
+	assert.Equal(t, "increasing", selected.Direction)
+	assert.InDelta(t, 10.0, trends.HeapTrends[HeapSampleInuseObjects].Slope, 1e-9)
+
+	// 规则使用的 HeapInuseObjects 始终是 inuse_objects：空间平稳而对象数增长
+	assert.Same(t, trends.HeapTrends[HeapSampleInuseObjects], trends.HeapInuseObjects)
+	assert.Equal(t, "increasing", trends.HeapInuseObjects.Direction)
+
+	// 默认配置展示 inuse_space
+	trends = CalculateTrends(group)
+	assert.Equal(t, HeapSampleInuseSpace, trends.HeapSampleType)
+	assert.Same(t, trends.HeapInuse, trends.SelectedHeapTrend())
+}
+
+// TestSelectedHeapTrend_Fallback 测试未计算多条趋势时回退到 HeapInuse
+func TestSelectedHeapTrend_Fallback(t *testing.T) {
+	inuse := &TrendMetrics{Slope: 1}
+	assert.Same(t, inuse, (&GroupTrends{HeapInuse: inuse}).SelectedHeapTrend())
+	assert.Nil(t, (*GroupTrends)(nil).SelectedHeapTrend())
+}
@@ -0,0 +1,89 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/pprof/profile"
+)
+
+// SampleTypeNames 返回 profile 中所有 sample type，格式为 "type/unit"
+func SampleTypeNames(p *profile.Profile) []string {
+	if p == nil {
+		return nil
+	}
+	names := make([]string, 0, len(p.SampleType))
+	for _, st := range p.SampleType {
+		names = append(names, st.Type+"/"+st.Unit)
+	}
+	return names
+}
+
+// FindSampleType 返回名称为 name 的 sample type 的索引
+// 找不到时返回的错误中列出 profile 中可用的 sample type
+func FindSampleType(p *profile.Profile, name string) (int, error) {
+	if p != nil {
+		for i, st := range p.SampleType {
+			if st.Type == name {
+				return i, nil
+			}
+		}
+	}
+	available := strings.Join(SampleTypeNames(p), ", ")
+	if available == "" {
+		available = "(none)"
+	}
+	return -1, fmt.Errorf("sample type %q not found, available: %s", name, available)
+}
+
+// ApplyValueType 使用名为 valueType 的 sample type 重新计算无法识别类型 (unknown) 的 profile 的指标
+// 内置类型 (cpu、heap、goroutine 等) 有固定的样本布局，不受影响。
+// valueType 为空时不做任何处理；任一文件缺少该 sample type 时返回错误，错误中列出可用的 sample type
+func ApplyValueType(groups []ProfileGroup, valueType string) error {
+	if valueType == "" {
+		return nil
+	}
+	for _, group := range groups {
+		if group.Type != "unknown" {
+			continue
+		}
+		for _, file := range group.Files {
+			if file.Profile == nil || file.Metrics == nil {
+				continue
+			}
+			index, err := FindSampleType(file.Profile, valueType)
+			if err != nil {
+				return fmt.Errorf("%s: %w", file.Path, err)
+			}
+			applyValueIndex(file.Metrics, file.Profile, index)
+		}
+	}
+	return nil
+}
+
+// applyValueIndex 按指定的 sample index 重新计算总值和 Top 函数
+func applyValueIndex(metrics *ProfileMetrics, p *profile.Profile, index int) {
+	st := p.SampleType[index]
+	metrics.ValueType, metrics.ValueUnit = st.Type, st.Unit
+
+	metrics.TotalValue = 0
+	for _, sample := range p.Sample {
+		if len(sample.Value) > index {
+			metrics.TotalValue += sample.Value[index]
+		}
+	}
+	metrics.TopFunctions = extractTopFunctions(p, 10, index)
+	metrics.TopFlatFunctions = extractTopFlatFunctions(p, DefaultNewTopN, index)
+}
+
+// valueTypeIndex 返回 metrics 记录的自定义 sample type 在 profile 中的索引，未设置时返回 -1
+func valueTypeIndex(metrics *ProfileMetrics, p *profile.Profile) int {
+	if metrics == nil || metrics.ValueType == "" {
+		return -1
+	}
+	index, err := FindSampleType(p, metrics.ValueType)
+	if err != nil {
+		return -1
+	}
+	return index
+}
@@ -0,0 +1,99 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCustomProfile 创建带 orders/count 和 revenue/cents 两种 sample type 的自定义 profile
+func newCustomProfile() *profile.Profile {
+	functions := make(map[string]*profile.Location)
+	location := func(name string) *profile.Location {
+		if loc, ok := functions[name]; ok {
+			return loc
+		}
+		id := uint64(len(functions) + 1)
+		loc := &profile.Location{ID: id, Line: []profile.Line{{Function: &profile.Function{ID: id, Name: name}}}}
+		functions[name] = loc
+		return loc
+	}
+	sample := func(orders, revenue int64, funcs ...string) *profile.Sample {
+		s := &profile.Sample{Value: []int64{orders, revenue}}
+		for _, name := range funcs {
+			s.Location = append(s.Location, location(name))
+		}
+		return s
+	}
+	return &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "orders", Unit: "count"}, {Type: "revenue", Unit: "cents"}},
+		Sample: []*profile.Sample{
+			sample(10, 100, "github.com/myapp/shop.Checkout", "main.main"),
+			sample(30, 900, "github.com/myapp/shop.Refund", "main.main"),
+		},
+	}
+}
+
+func TestSampleTypeNames(t *testing.T) {
+	assert.Equal(t, []string{"orders/count", "revenue/cents"}, SampleTypeNames(newCustomProfile()))
+	assert.Nil(t, SampleTypeNames(nil))
+}
+
+func TestFindSampleType(t *testing.T) {
+	index, err := FindSampleType(newCustomProfile(), "revenue")
+	require.NoError(t, err)
+	assert.Equal(t, 1, index)
+
+	_, err = FindSampleType(newCustomProfile(), "latency")
+	assert.EqualError(t, err, `sample type "latency" not found, available: orders/count, revenue/cents`)
+
+	_, err = FindSampleType(&profile.Profile{}, "latency")
+	assert.EqualError(t, err, `sample type "latency" not found, available: (none)`)
+}
+
+func TestApplyValueType(t *testing.T) {
+	custom := newCustomProfile()
+	cpu := newBenchCPUProfile(newCPUSample(100, "main.main"))
+	groups := []ProfileGroup{
+		{Type: "cpu", Files: []ProfileFile{{Path: "cpu.pprof", Profile: cpu, Metrics: ExtractMetrics(cpu, "cpu")}}},
+		{Type: "unknown", Files: []ProfileFile{{Path: "orders.pprof", Profile: custom, Metrics: ExtractMetrics(custom, "unknown")}}},
+	}
+	// 默认使用第一个 sample type
+	assert.Equal(t, int64(40), groups[1].Files[0].Metrics.TotalValue)
+
+	require.NoError(t, ApplyValueType(groups, "revenue"))
+	metrics := groups[1].Files[0].Metrics
+	assert.Equal(t, "revenue", metrics.ValueType)
+	assert.Equal(t, "cents", metrics.ValueUnit)
+	assert.Equal(t, int64(1000), metrics.TotalValue)
+	require.NotEmpty(t, metrics.TopFunctions)
+	assert.Equal(t, "main.main", metrics.TopFunctions[0].Name)
+	require.NotEmpty(t, metrics.TopFlatFunctions)
+	assert.Equal(t, "github.com/myapp/shop.Refund", metrics.TopFlatFunctions[0].Name)
+	assert.InDelta(t, 90.0, metrics.TopFlatFunctions[0].FlatPct, 0.001)
+
+	// 内置类型不受影响
+	assert.Empty(t, groups[0].Files[0].Metrics.ValueType)
+
+	assert.NoError(t, ApplyValueType(groups, ""))
+	err := ApplyValueType(groups, "latency")
+	assert.EqualError(t, err, `orders.pprof: sample type "latency" not found, available: orders/count, revenue/cents`)
+}
+
+func TestApplyValueType_CategoriesAndExplain(t *testing.T) {
+	custom := newCustomProfile()
+	groups := []ProfileGroup{
+		{Type: "unknown", Files: []ProfileFile{{Path: "orders.pprof", Profile: custom, Metrics: ExtractMetrics(custom, "unknown")}}},
+	}
+	require.NoError(t, ApplyValueType(groups, "revenue"))
+
+	ComputeCategoryTotals(groups, func(string) string { return "business" }, "")
+	assert.Equal(t, map[string]int64{"business": 1000}, groups[0].Files[0].Metrics.CategoryTotals)
+
+	explanation := ExplainFunction(groups, "github.com/myapp/shop.Refund", 0)
+	require.Len(t, explanation.Profiles, 1)
+	assert.Equal(t, "revenue", explanation.Profiles[0].SampleType)
+	assert.Equal(t, int64(900), explanation.Profiles[0].Latest().Flat)
+}
@@ -1,9 +1,11 @@
 package locator
 
 import (
+	"context"
 	"sort"
 
 	"github.com/google/pprof/profile"
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
 )
 
 // PathAnalyzer 热点路径分析器
@@ -29,9 +31,26 @@ func NewPathAnalyzer(extractor *Extractor, config LocatorConfig) *PathAnalyzer {
 }
 
 // AnalyzeHotPaths 分析热点路径，从 profile 提取 top N 热点路径
+// 相同调用路径的样本会被合并，结果按 Chain.TotalValue 降序排列，最多 MaxHotPaths 条；
+// profile 为空或样本值总和为 0 时返回 nil。profileType 为 cpu/heap/goroutine 等分组类型，
+// 会写入 HotPath.ProfileType 并影响样本值的选择
+// 超过 MaxCallStackDepth 的调用链保留入口一侧的栈帧
 func (a *PathAnalyzer) AnalyzeHotPaths(p *profile.Profile, profileType string) []HotPath {
+	hotPaths, _, _ := a.analyzeHotPaths(context.Background(), p, profileType)
+	return hotPaths
+}
+
+// AnalyzeHotPathsCtx 与 AnalyzeHotPaths 相同，但在逐样本循环中检查 ctx
+// ctx 取消或超时时返回 nil 和 ctx.Err()
+func (a *PathAnalyzer) AnalyzeHotPathsCtx(ctx context.Context, p *profile.Profile, profileType string) ([]HotPath, error) {
+	hotPaths, _, err := a.analyzeHotPaths(ctx, p, profileType)
+	return hotPaths, err
+}
+
+// analyzeHotPaths 分析单个 profile 的热点路径，同时返回被排除的纯运行时路径统计
+func (a *PathAnalyzer) analyzeHotPaths(ctx context.Context, p *profile.Profile, profileType string) ([]HotPath, HiddenHotPaths, error) {
 	if p == nil || len(p.Sample) == 0 {
-		return nil
+		return nil, HiddenHotPaths{}, nil
 	}
 
 	// 根据 profile 类型选择合适的值索引
@@ -52,22 +71,31 @@ func (a *PathAnalyzer) AnalyzeHotPaths(p *profile.Profile, profileType string) [
 		valueIndex = 1 // 使用 cum 值
 		useCumValue = true
 	}
+	if index, ok := a.config.customValueIndex(p, profileType); ok {
+		valueIndex, useCumValue = index, false
+	}
 
 	// 计算总值（用于百分比计算）
 	totalValue := int64(0)
-	for _, sample := range p.Sample {
+	for i, sample := range p.Sample {
+		if err := analyzer.CheckCanceled(ctx, i); err != nil {
+			return nil, HiddenHotPaths{}, err
+		}
 		if len(sample.Value) > valueIndex {
 			totalValue += sample.Value[valueIndex]
 		}
 	}
 
 	if totalValue == 0 {
-		return nil
+		return nil, HiddenHotPaths{}, nil
 	}
 
 	// 提取所有调用链
 	chains := make([]CallChain, 0, len(p.Sample))
-	for _, sample := range p.Sample {
+	for i, sample := range p.Sample {
+		if err := analyzer.CheckCanceled(ctx, i); err != nil {
+			return nil, HiddenHotPaths{}, err
+		}
 		var chain CallChain
 		if useCumValue {
 			chain = a.extractor.ExtractCallChainWithCumValue(sample, totalValue)
@@ -88,53 +116,103 @@ func (a *PathAnalyzer) AnalyzeHotPaths(p *profile.Profile, profileType string) [
 	})
 
 	// 取 top N
-	maxPaths := a.config.MaxHotPaths
-	if len(aggregated) < maxPaths {
-		maxPaths = len(aggregated)
-	}
-	topChains := aggregated[:maxPaths]
+	topChains, hidden := a.selectTopChains(aggregated)
+	hidden.Unit = sampleValueUnit(p, valueIndex, totalValue)
 
 	// 转换为 HotPath
-	hotPaths := make([]HotPath, 0, len(topChains))
-	for _, chain := range topChains {
-		// 限制调用栈深度
-		if len(chain.Frames) > a.config.MaxCallStackDepth {
-			chain.Frames = chain.Frames[:a.config.MaxCallStackDepth]
-			// 重新计算边界点和类别统计
-			chain.BoundaryPoints = FindBoundaryPoints(chain.Frames)
-			chain.CategoryBreakdown = calculateCategoryBreakdown(chain.Frames)
-		}
-
-		businessFrames := FindBusinessFrames(chain.Frames)
-		rootCauseIndex := -1
-		if len(businessFrames) > 0 {
-			// 根因是最深的业务代码帧（最接近热点的业务代码）
-			rootCauseIndex = businessFrames[len(businessFrames)-1]
-		}
-
-		hotPaths = append(hotPaths, HotPath{
-			Chain:          chain,
-			BusinessFrames: businessFrames,
-			RootCauseIndex: rootCauseIndex,
-			ProfileType:    profileType,
-		})
+	hotPaths, err := a.buildHotPaths(ctx, topChains, profileType, []*profile.Profile{p}, valueIndex, totalValue)
+	if err != nil {
+		return nil, HiddenHotPaths{}, err
 	}
-
-	return hotPaths
+	return hotPaths, hidden, nil
 }
 
 // AnalyzeMultipleProfiles 分析多个 profile 文件，综合所有热点函数
 // 用于 CPU 热点分析，综合多个 profile 文件的结果
 func (a *PathAnalyzer) AnalyzeMultipleProfiles(profiles []*profile.Profile, profileType string) []HotPath {
+	hotPaths, _, _ := a.analyzeMultipleProfiles(context.Background(), profiles, profileType)
+	return hotPaths
+}
+
+// AnalyzeMultipleProfilesCtx 与 AnalyzeMultipleProfiles 相同，但在逐样本循环中检查 ctx
+// ctx 取消或超时时返回 nil 和 ctx.Err()
+func (a *PathAnalyzer) AnalyzeMultipleProfilesCtx(ctx context.Context, profiles []*profile.Profile, profileType string) ([]HotPath, error) {
+	hotPaths, _, err := a.analyzeMultipleProfiles(ctx, profiles, profileType)
+	return hotPaths, err
+}
+
+// analyzeMultipleProfiles 综合分析多个 profile，同时返回被排除的纯运行时路径统计
+func (a *PathAnalyzer) analyzeMultipleProfiles(ctx context.Context, profiles []*profile.Profile, profileType string) ([]HotPath, HiddenHotPaths, error) {
 	if len(profiles) == 0 {
-		return nil
+		return nil, HiddenHotPaths{}, nil
 	}
 
 	// 如果只有一个 profile，直接分析
 	if len(profiles) == 1 {
-		return a.AnalyzeHotPaths(profiles[0], profileType)
+		return a.analyzeHotPaths(ctx, profiles[0], profileType)
+	}
+
+	aggregated, valueIndex, total, err := a.aggregateProfiles(ctx, profiles, profileType)
+	if err != nil || len(aggregated) == 0 {
+		return nil, HiddenHotPaths{}, err
 	}
 
+	// 按 TotalValue 降序排序
+	sort.Slice(aggregated, func(i, j int) bool {
+		return aggregated[i].TotalValue > aggregated[j].TotalValue
+	})
+
+	// 取 top N
+	topChains, hidden := a.selectTopChains(aggregated)
+	hidden.Unit = sampleValueUnit(profiles[0], valueIndex, total)
+
+	// 转换为 HotPath
+	hotPaths, err := a.buildHotPaths(ctx, topChains, profileType, profiles, valueIndex, total)
+	if err != nil {
+		return nil, HiddenHotPaths{}, err
+	}
+	return hotPaths, hidden, nil
+}
+
+// HotPathsThrough 返回经过目标函数 (含其闭包，见 analyzer.MatchesFunction) 的热点路径
+// 合并 profiles 的全部调用链后只保留包含目标函数的路径，按消耗降序取前 MaxHotPaths 条，
+// TotalPct 相对所有 profile 的总值。目标函数超出 MaxCallStackDepth 时加深截断位置，保证它出现在路径中
+func (a *PathAnalyzer) HotPathsThrough(ctx context.Context, profiles []*profile.Profile, profileType, function string) ([]HotPath, error) {
+	aggregated, valueIndex, total, err := a.aggregateProfiles(ctx, profiles, profileType)
+	if err != nil || len(aggregated) == 0 {
+		return nil, err
+	}
+
+	through := *a
+	matched := make([]CallChain, 0, len(aggregated))
+	for _, chain := range aggregated {
+		depth := 0
+		for i, frame := range chain.Frames {
+			if analyzer.MatchesFunction(frame.FunctionName, function) {
+				depth = i + 1
+			}
+		}
+		if depth == 0 {
+			continue
+		}
+		matched = append(matched, chain)
+		if depth > through.config.MaxCallStackDepth {
+			through.config.MaxCallStackDepth = depth
+		}
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].TotalValue > matched[j].TotalValue
+	})
+	if len(matched) > a.config.MaxHotPaths {
+		matched = matched[:a.config.MaxHotPaths]
+	}
+	return through.buildHotPaths(ctx, matched, profileType, profiles, valueIndex, total)
+}
+
+// aggregateProfiles 提取多个 profile 的全部调用链并按路径合并，TotalPct 基于所有 profile 的总值
+// 同时返回使用的样本值索引和所有 profile 的总值，结果未排序
+func (a *PathAnalyzer) aggregateProfiles(ctx context.Context, profiles []*profile.Profile, profileType string) ([]CallChain, int, int64, error) {
 	// 根据 profile 类型选择合适的值索引
 	valueIndex := 0
 	useCumValue := false
@@ -152,6 +230,11 @@ func (a *PathAnalyzer) AnalyzeMultipleProfiles(profiles []*profile.Profile, prof
 		valueIndex = 1 // 使用 cum 值
 		useCumValue = true
 	}
+	if len(profiles) > 0 {
+		if index, ok := a.config.customValueIndex(profiles[0], profileType); ok {
+			valueIndex, useCumValue = index, false
+		}
+	}
 
 	// 收集所有 profile 的热点路径
 	allChains := make([]CallChain, 0)
@@ -163,7 +246,10 @@ func (a *PathAnalyzer) AnalyzeMultipleProfiles(profiles []*profile.Profile, prof
 		}
 
 		profileTotalValue := int64(0)
-		for _, sample := range p.Sample {
+		for i, sample := range p.Sample {
+			if err := analyzer.CheckCanceled(ctx, i); err != nil {
+				return nil, 0, 0, err
+			}
 			if len(sample.Value) > valueIndex {
 				profileTotalValue += sample.Value[valueIndex]
 			}
@@ -176,7 +262,10 @@ func (a *PathAnalyzer) AnalyzeMultipleProfiles(profiles []*profile.Profile, prof
 		totalValueAcrossProfiles += profileTotalValue
 
 		// 提取该 profile 的所有调用链
-		for _, sample := range p.Sample {
+		for i, sample := range p.Sample {
+			if err := analyzer.CheckCanceled(ctx, i); err != nil {
+				return nil, 0, 0, err
+			}
 			var chain CallChain
 			if useCumValue {
 				chain = a.extractor.ExtractCallChainWithCumValue(sample, profileTotalValue)
@@ -190,7 +279,7 @@ func (a *PathAnalyzer) AnalyzeMultipleProfiles(profiles []*profile.Profile, prof
 	}
 
 	if len(allChains) == 0 {
-		return nil
+		return nil, valueIndex, 0, nil
 	}
 
 	// 聚合所有调用链
@@ -203,43 +292,180 @@ func (a *PathAnalyzer) AnalyzeMultipleProfiles(profiles []*profile.Profile, prof
 		}
 	}
 
-	// 按 TotalValue 降序排序
-	sort.Slice(aggregated, func(i, j int) bool {
-		return aggregated[i].TotalValue > aggregated[j].TotalValue
-	})
+	return aggregated, valueIndex, totalValueAcrossProfiles, nil
+}
 
-	// 取 top N
-	maxPaths := a.config.MaxHotPaths
-	if len(aggregated) < maxPaths {
-		maxPaths = len(aggregated)
+// customValueIndex 返回无法识别类型的 profile 中 ValueType 对应的 sample index
+// 内置类型、未设置 ValueType 或 profile 中没有该 sample type 时返回 false
+func (c LocatorConfig) customValueIndex(p *profile.Profile, profileType string) (int, bool) {
+	if c.ValueType == "" || profileType != "unknown" {
+		return 0, false
 	}
-	topChains := aggregated[:maxPaths]
+	index, err := analyzer.FindSampleType(p, c.ValueType)
+	return index, err == nil
+}
 
-	// 转换为 HotPath
-	hotPaths := make([]HotPath, 0, len(topChains))
-	for _, chain := range topChains {
+// selectTopChains 取消耗最大的 MaxHotPaths 条调用链，chains 需已按 TotalValue 降序排列
+// 开启 HideRuntimeOnly 时先排除没有业务代码帧的调用链，再取 top N，并统计被排除的路径
+func (a *PathAnalyzer) selectTopChains(chains []CallChain) ([]CallChain, HiddenHotPaths) {
+	var hidden HiddenHotPaths
+	if a.config.HideRuntimeOnly {
+		kept := make([]CallChain, 0, len(chains))
+		for _, chain := range chains {
+			if chain.HasBusinessCode() {
+				kept = append(kept, chain)
+				continue
+			}
+			hidden.Count++
+			hidden.Value += chain.TotalValue
+			hidden.Pct += chain.TotalPct
+		}
+		if hidden.Pct > 100 {
+			hidden.Pct = 100
+		}
+		chains = kept
+	}
+
+	if len(chains) > a.config.MaxHotPaths {
+		chains = chains[:a.config.MaxHotPaths]
+	}
+	return chains, hidden
+}
+
+// buildHotPaths 将排序后的调用链转换为 HotPath，并按配置的策略选择根因帧
+// profiles 和 valueIndex 用于 costliest 策略计算函数的累计消耗
+func (a *PathAnalyzer) buildHotPaths(ctx context.Context, chains []CallChain, profileType string, profiles []*profile.Profile, valueIndex int, total int64) ([]HotPath, error) {
+	var cumValues map[string]int64
+	if a.config.RootCausePolicy == RootCauseCostliest {
+		var err error
+		if cumValues, err = functionCumValues(ctx, profiles, valueIndex); err != nil {
+			return nil, err
+		}
+	}
+
+	var unit ValueUnit
+	if len(profiles) > 0 {
+		unit = sampleValueUnit(profiles[0], valueIndex, total)
+	}
+
+	hotPaths := make([]HotPath, 0, len(chains))
+	for _, chain := range chains {
 		// 限制调用栈深度
 		if len(chain.Frames) > a.config.MaxCallStackDepth {
 			chain.Frames = chain.Frames[:a.config.MaxCallStackDepth]
+			// 重新计算边界点和类别统计
 			chain.BoundaryPoints = FindBoundaryPoints(chain.Frames)
-			chain.CategoryBreakdown = calculateCategoryBreakdown(chain.Frames)
+			chain.CategoryBreakdown = CalculateCategoryBreakdown(chain.Frames)
 		}
 
 		businessFrames := FindBusinessFrames(chain.Frames)
-		rootCauseIndex := -1
-		if len(businessFrames) > 0 {
-			rootCauseIndex = businessFrames[len(businessFrames)-1]
+
+		// 优先从手写的业务代码中选根因，只有生成代码 (如 pb handler) 时才退回到生成代码帧
+		rootCause := SelectRootCause(chain.Frames, businessFrames, a.config.RootCausePolicy, cumValues)
+		if rootCause < 0 {
+			rootCause = SelectRootCause(chain.Frames, FindGeneratedFrames(chain.Frames), a.config.RootCausePolicy, cumValues)
 		}
 
 		hotPaths = append(hotPaths, HotPath{
 			Chain:          chain,
 			BusinessFrames: businessFrames,
-			RootCauseIndex: rootCauseIndex,
+			RootCauseIndex: rootCause,
 			ProfileType:    profileType,
+			Unit:           unit,
 		})
 	}
 
-	return hotPaths
+	return hotPaths, nil
+}
+
+// SelectRootCause 根据策略从业务代码帧中选择根因帧索引，无业务代码时返回 -1
+// deepest: 最深的业务代码帧（最接近热点的业务代码）
+// costliest: 累计消耗最大的业务代码帧，消耗相同时取更深的帧；
+// cumValues 为空时退化为 deepest
+// boundary: 紧挨着进入标准库/运行时的类别边界之前的业务代码帧 (见 BoundaryRootCause)，没有这样的边界时退化为 deepest
+func SelectRootCause(frames []StackFrame, businessFrames []int, policy RootCausePolicy, cumValues map[string]int64) int {
+	if len(businessFrames) == 0 {
+		return -1
+	}
+
+	deepest := businessFrames[len(businessFrames)-1]
+	if policy == RootCauseBoundary {
+		if idx := BoundaryRootCause(frames, businessFrames, FindBoundaryPoints(frames)); idx >= 0 {
+			return idx
+		}
+		return deepest
+	}
+	if policy != RootCauseCostliest || len(cumValues) == 0 {
+		return deepest
+	}
+
+	best := deepest
+	bestValue := cumValues[frames[deepest].FunctionName]
+	for i := len(businessFrames) - 2; i >= 0; i-- {
+		idx := businessFrames[i]
+		if value := cumValues[frames[idx].FunctionName]; value > bestValue {
+			best = idx
+			bestValue = value
+		}
+	}
+	return best
+}
+
+// BoundaryRootCause 返回直接调用标准库或运行时的业务代码帧，没有时返回 -1
+// 最深的业务帧常常是无关紧要的辅助函数 (比如调用第三方库的薄封装)，真正的开销来源是
+// 直接调用昂贵标准库/运行时函数 (如 json.Marshal) 的业务帧。
+// boundaryPoints 为 FindBoundaryPoints 的结果 (与 CallChain.BoundaryPoints 一致)，
+// 边界帧属于标准库或运行时、前一帧在 businessFrames 中时，前一帧为候选；有多个候选时取最深的一个
+func BoundaryRootCause(frames []StackFrame, businessFrames []int, boundaryPoints []int) int {
+	isBusiness := make(map[int]bool, len(businessFrames))
+	for _, idx := range businessFrames {
+		isBusiness[idx] = true
+	}
+
+	for i := len(boundaryPoints) - 1; i >= 0; i-- {
+		boundary := boundaryPoints[i]
+		if boundary <= 0 || boundary >= len(frames) || !isBusiness[boundary-1] {
+			continue
+		}
+		if category := frames[boundary].Category; category == CategoryStdlib || category == CategoryRuntime {
+			return boundary - 1
+		}
+	}
+	return -1
+}
+
+// functionCumValues 计算每个函数在所有 profile 中的累计消耗
+// 同一样本中重复出现的函数（递归）只计算一次
+func functionCumValues(ctx context.Context, profiles []*profile.Profile, valueIndex int) (map[string]int64, error) {
+	cumValues := make(map[string]int64)
+	for _, p := range profiles {
+		if p == nil {
+			continue
+		}
+		for i, sample := range p.Sample {
+			if err := analyzer.CheckCanceled(ctx, i); err != nil {
+				return nil, err
+			}
+			if len(sample.Value) <= valueIndex {
+				continue
+			}
+			value := sample.Value[valueIndex]
+			seen := make(map[string]bool)
+			for _, loc := range sample.Location {
+				if loc == nil {
+					continue
+				}
+				for _, line := range loc.Line {
+					if line.Function == nil || seen[line.Function.Name] {
+						continue
+					}
+					seen[line.Function.Name] = true
+					cumValues[line.Function.Name] += value
+				}
+			}
+		}
+	}
+	return cumValues, nil
 }
 
 // AggregateCallChains 聚合相同调用路径的样本
@@ -262,6 +488,7 @@ func (a *PathAnalyzer) AggregateCallChains(chains []CallChain) []CallChain {
 			existing.TotalValue += chain.TotalValue
 			existing.TotalPct += chain.TotalPct
 			existing.SampleCount += chain.SampleCount
+			mergeRecursion(existing.Frames, chain.Frames)
 		} else {
 			// 创建新条目（复制以避免修改原始数据）
 			newChain := CallChain{
@@ -290,6 +517,18 @@ func (a *PathAnalyzer) AggregateCallChains(chains []CallChain) []CallChain {
 	return result
 }
 
+// mergeRecursion 合并递归深度不同的同一调用链时，每帧保留最大的递归次数
+func mergeRecursion(dst, src []StackFrame) {
+	if len(dst) != len(src) {
+		return
+	}
+	for i := range dst {
+		if src[i].Recursion > dst[i].Recursion {
+			dst[i].Recursion = src[i].Recursion
+		}
+	}
+}
+
 // generateCallChainKey 生成调用链的唯一标识
 func generateCallChainKey(frames []StackFrame) string {
 	if len(frames) == 0 {
@@ -353,7 +592,7 @@ func generateSmartCallChainKey(frames []StackFrame) string {
 }
 
 // FindBoundaryPoints 找出类别边界索引
-// 边界点是类别发生变化的位置（从索引 1 开始检查）
+// 边界点是类别发生变化的位置（从索引 1 开始检查），frames 需按从入口到叶子排列
 func FindBoundaryPoints(frames []StackFrame) []int {
 	if len(frames) <= 1 {
 		return nil
@@ -369,7 +608,7 @@ func FindBoundaryPoints(frames []StackFrame) []int {
 }
 
 // FindBusinessFrames 找出所有业务代码帧索引
-// 返回的索引按升序排列（从入口到叶子）
+// frames 需按从入口到叶子排列，返回的索引按升序排列；没有业务代码时返回空切片而非 nil
 func FindBusinessFrames(frames []StackFrame) []int {
 	indices := make([]int, 0)
 	for i, frame := range frames {
@@ -380,6 +619,17 @@ func FindBusinessFrames(frames []StackFrame) []int {
 	return indices
 }
 
+// FindGeneratedFrames 找出所有生成代码帧索引，按升序排列
+func FindGeneratedFrames(frames []StackFrame) []int {
+	indices := make([]int, 0)
+	for i, frame := range frames {
+		if frame.Category == CategoryGenerated {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
 // GenerateCategorySummary 生成类别分布摘要字符串
 // 例如: "2 业务 → 1 第三方 → 2 标准库 → 3 运行时"
 func GenerateCategorySummary(frames []StackFrame) string {
@@ -423,8 +673,8 @@ func GenerateCategorySummary(frames []StackFrame) string {
 	return result
 }
 
-// calculateCategoryBreakdown 计算类别分布统计
-func calculateCategoryBreakdown(frames []StackFrame) map[CodeCategory]int {
+// CalculateCategoryBreakdown 计算各类别的帧数，结果与 CallChain.CategoryBreakdown 一致
+func CalculateCategoryBreakdown(frames []StackFrame) map[CodeCategory]int {
 	breakdown := make(map[CodeCategory]int)
 	for _, frame := range frames {
 		breakdown[frame.Category]++
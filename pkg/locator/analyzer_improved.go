@@ -1,6 +1,7 @@
 package locator
 
 import (
+	"context"
 	"sort"
 
 	"github.com/google/pprof/profile"
@@ -8,6 +9,8 @@ import (
 
 // AnalyzeHotPathsImproved 改进的热点路径分析
 // 使用 CPU 时间值而非采样次数，能更好地识别业务代码影响
+//
+// Deprecated: AnalyzeHotPaths 已按相同规则选择 cpu/nanoseconds 值，请直接使用 AnalyzeHotPaths
 func (a *PathAnalyzer) AnalyzeHotPathsImproved(p *profile.Profile, profileType string) []HotPath {
 	if p == nil || len(p.Sample) == 0 {
 		return nil
@@ -15,7 +18,7 @@ func (a *PathAnalyzer) AnalyzeHotPathsImproved(p *profile.Profile, profileType s
 
 	// 选择合适的值索引
 	// 对于 CPU profile，优先使用 cpu/nanoseconds 类型的值
-	valueIndex := selectBestValueIndex(p)
+	valueIndex := SelectValueIndex(p)
 
 	// 计算总值
 	totalValue := int64(0)
@@ -47,42 +50,16 @@ func (a *PathAnalyzer) AnalyzeHotPathsImproved(p *profile.Profile, profileType s
 	})
 
 	// 取 top N
-	maxPaths := a.config.MaxHotPaths
-	if len(aggregated) < maxPaths {
-		maxPaths = len(aggregated)
-	}
-	topChains := aggregated[:maxPaths]
-
-	// 转换为 HotPath
-	hotPaths := make([]HotPath, 0, len(topChains))
-	for _, chain := range topChains {
-		// 限制调用栈深度
-		if len(chain.Frames) > a.config.MaxCallStackDepth {
-			chain.Frames = chain.Frames[:a.config.MaxCallStackDepth]
-			chain.BoundaryPoints = FindBoundaryPoints(chain.Frames)
-			chain.CategoryBreakdown = calculateCategoryBreakdown(chain.Frames)
-		}
-
-		businessFrames := FindBusinessFrames(chain.Frames)
-		rootCauseIndex := -1
-		if len(businessFrames) > 0 {
-			rootCauseIndex = businessFrames[len(businessFrames)-1]
-		}
-
-		hotPaths = append(hotPaths, HotPath{
-			Chain:          chain,
-			BusinessFrames: businessFrames,
-			RootCauseIndex: rootCauseIndex,
-			ProfileType:    profileType,
-		})
-	}
+	topChains, _ := a.selectTopChains(aggregated)
 
+	// 转换为 HotPath，Background 不会被取消
+	hotPaths, _ := a.buildHotPaths(context.Background(), topChains, profileType, []*profile.Profile{p}, valueIndex, totalValue)
 	return hotPaths
 }
 
-// selectBestValueIndex 选择最佳的值索引
-// 对于 CPU profile，优先选择 cpu/nanoseconds 类型
-func selectBestValueIndex(p *profile.Profile) int {
+// SelectValueIndex 选择调用 ExtractCallChain 时使用的 sample value 索引
+// 优先选择 cpu/nanoseconds 类型，没有时使用第一个值
+func SelectValueIndex(p *profile.Profile) int {
 	if len(p.SampleType) == 0 {
 		return 0
 	}
@@ -1,11 +1,13 @@
 package locator
 
 import (
+	"context"
 	"testing"
 	"testing/quick"
 
 	"github.com/google/pprof/profile"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Feature: problem-locator, Property 3: Call Chain Completeness and Ordering
@@ -677,6 +679,204 @@ func TestRootCauseIndex(t *testing.T) {
 	})
 }
 
+// TestRootCausePolicy tests that deepest and costliest policies pick different frames
+// when the deepest business frame is a thin wrapper
+func TestRootCausePolicy(t *testing.T) {
+	// handler.Serve -> service.Compute -> util.Wrap -> runtime.mallocgc (600)
+	// handler.Serve -> service.Compute -> runtime.memmove (400)
+	// service.Compute 的累计消耗 (1000) 大于 util.Wrap (600)
+	newProfile := func(classifier *Classifier) *profile.Profile {
+		return createTestProfile([]*profile.Sample{
+			createTestSample([]string{
+				"github.com/myapp/handler.Serve",   // business - index 0
+				"github.com/myapp/service.Compute", // business - index 1
+				"github.com/myapp/util.Wrap",       // business - index 2 (deepest)
+				"runtime.mallocgc",
+			}, 600, classifier),
+			createTestSample([]string{
+				"github.com/myapp/handler.Serve",
+				"github.com/myapp/service.Compute",
+				"runtime.memmove",
+			}, 400, classifier),
+		})
+	}
+
+	tests := []struct {
+		name     string
+		policy   RootCausePolicy
+		expected int
+	}{
+		{"default policy", "", 2},
+		{"deepest", RootCauseDeepest, 2},
+		{"costliest", RootCauseCostliest, 1},
+		{"boundary", RootCauseBoundary, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := LocatorConfig{
+				ModuleName:        "github.com/myapp",
+				MaxCallStackDepth: 10,
+				MaxHotPaths:       5,
+				RootCausePolicy:   tt.policy,
+			}
+			classifier := NewClassifier(config)
+			analyzer := NewPathAnalyzer(NewExtractor(classifier), config)
+
+			hotPaths := analyzer.AnalyzeHotPaths(newProfile(classifier), "cpu")
+			assert.Len(t, hotPaths, 2)
+			assert.Equal(t, "github.com/myapp/util.Wrap", hotPaths[0].Chain.Frames[2].FunctionName)
+			assert.Equal(t, tt.expected, hotPaths[0].RootCauseIndex)
+
+			multi := analyzer.AnalyzeMultipleProfiles([]*profile.Profile{newProfile(classifier), newProfile(classifier)}, "cpu")
+			assert.Len(t, multi, 2)
+			assert.Equal(t, tt.expected, multi[0].RootCauseIndex)
+		})
+	}
+}
+
+// TestRootCausePolicy_Boundary boundary 策略选择直接调用标准库/运行时的业务帧
+func TestRootCausePolicy_Boundary(t *testing.T) {
+	config := LocatorConfig{
+		ModuleName:        "github.com/myapp",
+		MaxCallStackDepth: 10,
+		MaxHotPaths:       5,
+		RootCausePolicy:   RootCauseBoundary,
+	}
+	classifier := NewClassifier(config)
+	analyzer := NewPathAnalyzer(NewExtractor(classifier), config)
+
+	t.Run("business frame calling stdlib", func(t *testing.T) {
+		p := createTestProfile([]*profile.Sample{createTestSample([]string{
+			"github.com/myapp/handler.Serve",  // business - index 0
+			"github.com/myapp/handler.encode", // business - index 1, 调用 json.Marshal
+			"encoding/json.Marshal",           // stdlib - index 2
+			"runtime.mallocgc",                // runtime - index 3
+		}, 1000, classifier)})
+
+		hotPaths := analyzer.AnalyzeHotPaths(p, "cpu")
+		require.Len(t, hotPaths, 1)
+		assert.Equal(t, []int{2, 3}, hotPaths[0].Chain.BoundaryPoints)
+		assert.Equal(t, 1, hotPaths[0].RootCauseIndex)
+	})
+
+	t.Run("deepest business frame calls third party", func(t *testing.T) {
+		// 最深的业务帧 formatName 只是模板回调，调用的是第三方库；
+		// 直接调用 text/template 的 render.Page 才是开销来源
+		p := createTestProfile([]*profile.Sample{createTestSample([]string{
+			"github.com/myapp/handler.Serve",     // business - index 0
+			"github.com/myapp/render.Page",       // business - index 1
+			"text/template.(*Template).Execute",  // stdlib - index 2
+			"github.com/myapp/render.formatName", // business - index 3 (deepest)
+			"github.com/other/strutil.Title",     // third party - index 4
+			"runtime.memmove",                    // runtime - index 5
+		}, 1000, classifier)})
+
+		hotPaths := analyzer.AnalyzeHotPaths(p, "cpu")
+		require.Len(t, hotPaths, 1)
+		assert.Equal(t, []int{0, 1, 3}, hotPaths[0].BusinessFrames)
+		assert.Equal(t, 1, hotPaths[0].RootCauseIndex)
+	})
+}
+
+// TestSelectRootCause tests root cause selection edge cases
+func TestSelectRootCause(t *testing.T) {
+	frames := []StackFrame{
+		{FunctionName: "a", Category: CategoryBusiness},
+		{FunctionName: "b", Category: CategoryBusiness},
+		{FunctionName: "runtime.mallocgc", Category: CategoryRuntime},
+	}
+	business := []int{0, 1}
+
+	t.Run("no business frames", func(t *testing.T) {
+		assert.Equal(t, -1, SelectRootCause(frames, nil, RootCauseCostliest, map[string]int64{"a": 1}))
+	})
+
+	t.Run("costliest without values falls back to deepest", func(t *testing.T) {
+		assert.Equal(t, 1, SelectRootCause(frames, business, RootCauseCostliest, nil))
+	})
+
+	t.Run("costliest tie prefers deeper frame", func(t *testing.T) {
+		assert.Equal(t, 1, SelectRootCause(frames, business, RootCauseCostliest, map[string]int64{"a": 10, "b": 10}))
+	})
+
+	t.Run("costliest picks highest cumulative value", func(t *testing.T) {
+		assert.Equal(t, 0, SelectRootCause(frames, business, RootCauseCostliest, map[string]int64{"a": 11, "b": 10}))
+	})
+
+	t.Run("boundary picks frame before runtime", func(t *testing.T) {
+		assert.Equal(t, 1, SelectRootCause(frames, business, RootCauseBoundary, nil))
+	})
+
+	t.Run("boundary without stdlib or runtime falls back to deepest", func(t *testing.T) {
+		thirdParty := []StackFrame{
+			{FunctionName: "a", Category: CategoryBusiness},
+			{FunctionName: "b", Category: CategoryBusiness},
+			{FunctionName: "github.com/other/lib.Do", Category: CategoryThirdParty},
+		}
+		assert.Equal(t, 1, SelectRootCause(thirdParty, business, RootCauseBoundary, nil))
+		assert.Equal(t, -1, BoundaryRootCause(thirdParty, business, FindBoundaryPoints(thirdParty)))
+	})
+}
+
+// TestAnalyzeHotPaths_GeneratedRootCause 根因优先取手写的业务代码，只有生成代码时退回到生成代码帧
+func TestAnalyzeHotPaths_GeneratedRootCause(t *testing.T) {
+	config := LocatorConfig{ModuleName: "github.com/myapp", MaxCallStackDepth: 10, MaxHotPaths: 5}
+	analyzer := NewPathAnalyzer(NewExtractor(NewClassifier(config)), config)
+
+	// frames 从入口到叶子，每项为 函数名 和 文件名
+	sampleOf := func(value int64, frames ...[2]string) *profile.Sample {
+		locations := make([]*profile.Location, len(frames))
+		for i, f := range frames {
+			fn := &profile.Function{ID: uint64(i + 1), Name: f[0], Filename: f[1]}
+			locations[len(frames)-1-i] = &profile.Location{ID: uint64(i + 1), Line: []profile.Line{{Function: fn, Line: 1}}}
+		}
+		return &profile.Sample{Location: locations, Value: []int64{value}}
+	}
+
+	t.Run("prefers handwritten business code", func(t *testing.T) {
+		p := createTestProfile([]*profile.Sample{sampleOf(100,
+			[2]string{"github.com/myapp/service.(*UserService).Get", "/src/myapp/service/user_service.go"},
+			[2]string{"github.com/myapp/api.(*GetUserRequest).Unmarshal", "/src/myapp/api/user_service.pb.go"},
+			[2]string{"runtime.mallocgc", "/go/src/runtime/malloc.go"},
+		)})
+		hotPaths := analyzer.AnalyzeHotPaths(p, "cpu")
+		require.Len(t, hotPaths, 1)
+		assert.Equal(t, CategoryGenerated, hotPaths[0].Chain.Frames[1].Category)
+		assert.Equal(t, []int{0}, hotPaths[0].BusinessFrames)
+		assert.Equal(t, 0, hotPaths[0].RootCauseIndex)
+	})
+
+	t.Run("falls back to generated code", func(t *testing.T) {
+		p := createTestProfile([]*profile.Sample{sampleOf(100,
+			[2]string{"github.com/myapp/api.(*GetUserRequest).Unmarshal", "/src/myapp/api/user_service.pb.go"},
+			[2]string{"runtime.mallocgc", "/go/src/runtime/malloc.go"},
+		)})
+		hotPaths := analyzer.AnalyzeHotPaths(p, "cpu")
+		require.Len(t, hotPaths, 1)
+		assert.Empty(t, hotPaths[0].BusinessFrames)
+		assert.Equal(t, 0, hotPaths[0].RootCauseIndex)
+	})
+}
+
+// TestParseRootCausePolicy tests policy name parsing
+func TestParseRootCausePolicy(t *testing.T) {
+	policy, err := ParseRootCausePolicy("")
+	assert.NoError(t, err)
+	assert.Equal(t, RootCauseDeepest, policy)
+
+	policy, err = ParseRootCausePolicy("costliest")
+	assert.NoError(t, err)
+	assert.Equal(t, RootCauseCostliest, policy)
+
+	policy, err = ParseRootCausePolicy("boundary")
+	assert.NoError(t, err)
+	assert.Equal(t, RootCauseBoundary, policy)
+
+	_, err = ParseRootCausePolicy("shallowest")
+	assert.Error(t, err)
+}
+
 // TestGetCategoryBreakdownSum tests the helper function
 func TestGetCategoryBreakdownSum(t *testing.T) {
 	t.Run("empty breakdown", func(t *testing.T) {
@@ -697,3 +897,228 @@ func TestGetCategoryBreakdownSum(t *testing.T) {
 		assert.Equal(t, 10, sum)
 	})
 }
+
+// TestCalculateCategoryBreakdown tests per-category frame counting
+func TestCalculateCategoryBreakdown(t *testing.T) {
+	frames := []StackFrame{
+		{Category: CategoryBusiness},
+		{Category: CategoryStdlib},
+		{Category: CategoryRuntime},
+		{Category: CategoryRuntime},
+	}
+
+	breakdown := CalculateCategoryBreakdown(frames)
+	assert.Equal(t, map[CodeCategory]int{
+		CategoryBusiness: 1,
+		CategoryStdlib:   1,
+		CategoryRuntime:  2,
+	}, breakdown)
+	assert.Equal(t, len(frames), GetCategoryBreakdownSum(breakdown))
+	assert.Empty(t, CalculateCategoryBreakdown(nil))
+}
+
+// TestSelectValueIndex tests sample value selection
+func TestSelectValueIndex(t *testing.T) {
+	t.Run("cpu nanoseconds preferred", func(t *testing.T) {
+		p := &profile.Profile{SampleType: []*profile.ValueType{
+			{Type: "samples", Unit: "count"},
+			{Type: "cpu", Unit: "nanoseconds"},
+		}}
+		assert.Equal(t, 1, SelectValueIndex(p))
+	})
+
+	t.Run("falls back to first value", func(t *testing.T) {
+		p := &profile.Profile{SampleType: []*profile.ValueType{
+			{Type: "inuse_objects", Unit: "count"},
+			{Type: "inuse_space", Unit: "bytes"},
+		}}
+		assert.Equal(t, 0, SelectValueIndex(p))
+		assert.Equal(t, 0, SelectValueIndex(&profile.Profile{}))
+	})
+}
+
+// TestAnalyzeHotPaths_HideRuntimeOnly tests excluding hot paths without business frames
+func TestAnalyzeHotPaths_HideRuntimeOnly(t *testing.T) {
+	gcChain := []string{"runtime.gcBgMarkWorker", "runtime.gcDrain", "runtime.scanobject"}
+	businessChain := []string{"main.main", "github.com/myapp/handler.ProcessRequest", "runtime.mallocgc"}
+	otherBusiness := []string{"main.main", "github.com/myapp/handler.Encode", "encoding/json.Marshal"}
+
+	newProfile := func() *profile.Profile {
+		return createTestProfile([]*profile.Sample{
+			createTestSample(gcChain, 600, nil),
+			createTestSample(businessChain, 300, nil),
+			createTestSample(otherBusiness, 100, nil),
+		})
+	}
+
+	config := LocatorConfig{
+		ModuleName:        "github.com/myapp",
+		MaxCallStackDepth: 10,
+		MaxHotPaths:       2,
+	}
+
+	t.Run("disabled keeps runtime paths", func(t *testing.T) {
+		analyzer := NewPathAnalyzer(NewExtractor(NewClassifier(config)), config)
+		hotPaths, hidden, err := analyzer.analyzeHotPaths(context.Background(), newProfile(), "cpu")
+		assert.NoError(t, err)
+
+		assert.Len(t, hotPaths, 2)
+		assert.Equal(t, "runtime.gcBgMarkWorker", hotPaths[0].Chain.Frames[0].FunctionName)
+		assert.Equal(t, HiddenHotPaths{}, hidden)
+	})
+
+	t.Run("enabled recomputes top N after exclusion", func(t *testing.T) {
+		cfg := config
+		cfg.HideRuntimeOnly = true
+		analyzer := NewPathAnalyzer(NewExtractor(NewClassifier(cfg)), cfg)
+		hotPaths, hidden, err := analyzer.analyzeHotPaths(context.Background(), newProfile(), "cpu")
+		assert.NoError(t, err)
+
+		assert.Len(t, hotPaths, 2, "top N should be filled from remaining paths")
+		for _, hp := range hotPaths {
+			assert.NotEmpty(t, hp.BusinessFrames)
+		}
+		assert.Equal(t, 1, hidden.Count)
+		assert.Equal(t, int64(600), hidden.Value)
+		assert.InDelta(t, 60.0, hidden.Pct, 0.001)
+	})
+
+	t.Run("multiple profiles", func(t *testing.T) {
+		cfg := config
+		cfg.HideRuntimeOnly = true
+		analyzer := NewPathAnalyzer(NewExtractor(NewClassifier(cfg)), cfg)
+		hotPaths, hidden, err := analyzer.analyzeMultipleProfiles(context.Background(), []*profile.Profile{newProfile(), newProfile()}, "cpu")
+		assert.NoError(t, err)
+
+		assert.Len(t, hotPaths, 2)
+		assert.Equal(t, 1, hidden.Count)
+		assert.Equal(t, int64(1200), hidden.Value)
+		assert.InDelta(t, 60.0, hidden.Pct, 0.001)
+	})
+}
+
+// TestPathAnalyzer_Ctx 测试热点路径和归属分析在 ctx 取消时中止
+func TestPathAnalyzer_Ctx(t *testing.T) {
+	config := DefaultConfig()
+	config.ModuleName = "github.com/myapp"
+	config.RootCausePolicy = RootCauseCostliest
+	pa := NewPathAnalyzer(NewExtractor(NewClassifier(config)), config)
+
+	fn := &profile.Function{ID: 1, Name: "github.com/myapp/service.Handle", Filename: "service.go"}
+	loc := &profile.Location{ID: 1, Line: []profile.Line{{Function: fn, Line: 10}}}
+	p := &profile.Profile{
+		SampleType: []*profile.ValueType{
+			{Type: "alloc_objects"}, {Type: "alloc_space"}, {Type: "inuse_objects"}, {Type: "inuse_space"},
+		},
+		Sample: []*profile.Sample{{Location: []*profile.Location{loc}, Value: []int64{1, 100, 1, 100}}},
+	}
+
+	hotPaths, err := pa.AnalyzeHotPathsCtx(context.Background(), p, "heap")
+	assert.NoError(t, err)
+	assert.Len(t, hotPaths, 1)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	hotPaths, err = pa.AnalyzeHotPathsCtx(canceled, p, "heap")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, hotPaths)
+
+	hotPaths, err = pa.AnalyzeMultipleProfilesCtx(canceled, []*profile.Profile{p, p}, "heap")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, hotPaths)
+
+	tree, err := pa.AnalyzeOwnershipCtx(canceled, p)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, tree)
+
+	tree, err = pa.AnalyzeOwnershipCtx(context.Background(), p)
+	assert.NoError(t, err)
+	assert.NotNil(t, tree)
+}
+
+// TestHotPathsThrough tests selecting the hot paths that pass through a single function
+func TestHotPathsThrough(t *testing.T) {
+	config := LocatorConfig{ModuleName: "github.com/myapp", MaxCallStackDepth: 3, MaxHotPaths: 5}
+	pa := NewPathAnalyzer(NewExtractor(NewClassifier(config)), config)
+
+	target := "github.com/myapp/orders.Handle"
+	profiles := []*profile.Profile{
+		createTestProfile([]*profile.Sample{
+			createTestSample([]string{"main.main", "github.com/myapp/server.Serve", "github.com/myapp/router.Route", target, "encoding/json.Marshal"}, 30, nil),
+			createTestSample([]string{"main.main", target + ".func1", "runtime.mallocgc"}, 20, nil),
+			createTestSample([]string{"main.main", "github.com/myapp/reports.Build"}, 50, nil),
+		}),
+		createTestProfile([]*profile.Sample{
+			createTestSample([]string{"main.main", "github.com/myapp/reports.Build"}, 100, nil),
+		}),
+	}
+
+	hotPaths, err := pa.HotPathsThrough(context.Background(), profiles, "cpu", target)
+	require.NoError(t, err)
+	require.Len(t, hotPaths, 2)
+
+	// TotalPct 相对两个 profile 的总值 (200)
+	assert.InDelta(t, 15.0, hotPaths[0].Chain.TotalPct, 0.001)
+	assert.InDelta(t, 10.0, hotPaths[1].Chain.TotalPct, 0.001)
+
+	// 目标函数位于第 4 帧，超出 MaxCallStackDepth 3 时加深截断位置
+	frames := hotPaths[0].Chain.Frames
+	require.Len(t, frames, 4)
+	assert.Equal(t, target, frames[3].FunctionName)
+	assert.Equal(t, "cpu", hotPaths[0].ProfileType)
+
+	none, err := pa.HotPathsThrough(context.Background(), profiles, "cpu", "github.com/myapp/orders.Cancel")
+	require.NoError(t, err)
+	assert.Empty(t, none)
+
+	config.MaxHotPaths = 1
+	limited := NewPathAnalyzer(NewExtractor(NewClassifier(config)), config)
+	hotPaths, err = limited.HotPathsThrough(context.Background(), profiles, "cpu", target)
+	require.NoError(t, err)
+	assert.Len(t, hotPaths, 1)
+}
+
+// TestAnalyzeHotPaths_ValueType tests that unknown profiles rank paths by the configured sample type
+func TestAnalyzeHotPaths_ValueType(t *testing.T) {
+	newSample := func(funcNames []string, orders, revenue int64) *profile.Sample {
+		s := createTestSample(funcNames, 0, nil)
+		s.Value = []int64{orders, revenue}
+		return s
+	}
+	p := &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "orders", Unit: "count"}, {Type: "revenue", Unit: "cents"}},
+		Sample: []*profile.Sample{
+			newSample([]string{"main.main", "github.com/myapp/shop.Checkout"}, 90, 100),
+			newSample([]string{"main.main", "github.com/myapp/shop.Refund"}, 10, 900),
+		},
+	}
+
+	leaf := func(config LocatorConfig, profileType string) string {
+		pa := NewPathAnalyzer(NewExtractor(NewClassifier(config)), config)
+		hotPaths, err := pa.AnalyzeHotPathsCtx(context.Background(), p, profileType)
+		require.NoError(t, err)
+		require.NotEmpty(t, hotPaths)
+		frames := hotPaths[0].Chain.Frames
+		return frames[len(frames)-1].FunctionName
+	}
+
+	config := LocatorConfig{ModuleName: "github.com/myapp", MaxCallStackDepth: 10, MaxHotPaths: 5}
+	assert.Equal(t, "github.com/myapp/shop.Checkout", leaf(config, "unknown"), "defaults to the first sample type")
+
+	config.ValueType = "revenue"
+	assert.Equal(t, "github.com/myapp/shop.Refund", leaf(config, "unknown"))
+
+	// 内置类型不使用 ValueType；找不到时回退到默认选择
+	assert.Equal(t, "github.com/myapp/shop.Checkout", leaf(config, "goroutine"))
+	config.ValueType = "latency"
+	assert.Equal(t, "github.com/myapp/shop.Checkout", leaf(config, "unknown"))
+
+	// 多 profile 分析使用相同的选择
+	config.ValueType = "revenue"
+	pa := NewPathAnalyzer(NewExtractor(NewClassifier(config)), config)
+	hotPaths, err := pa.AnalyzeMultipleProfilesCtx(context.Background(), []*profile.Profile{p, p}, "unknown")
+	require.NoError(t, err)
+	require.NotEmpty(t, hotPaths)
+	assert.InDelta(t, 90.0, hotPaths[0].Chain.TotalPct, 0.001)
+}
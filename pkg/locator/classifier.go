@@ -2,29 +2,40 @@ package locator
 
 import (
 	"bufio"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
 )
 
+//go:generate go run gen_stdlib.go
+
 // Classifier 代码分类器
 type Classifier struct {
 	moduleName         string
 	thirdPartyPrefixes []string
-	stdlibPackages     map[string]bool // 预加载的标准库包列表
+	overrides          []classifyOverride // 用户指定的分类覆盖，按前缀长度降序
+	stdlibPackages     map[string]bool    // 预加载的标准库包列表 (见 stdlib_list.go)
+	stdlibTopLevels    map[string]bool    // 标准库导入路径的第一段，用于启发式判断
 }
 
 // NewClassifier 创建分类器
 func NewClassifier(config LocatorConfig) *Classifier {
 	c := &Classifier{
 		moduleName:         config.ModuleName,
-		thirdPartyPrefixes: config.ThirdPartyPrefixes,
+		thirdPartyPrefixes: normalizeThirdPartyPrefixes(config.ThirdPartyPrefixes, config.ModuleName, nil),
+		overrides:          normalizeOverrides(config.Overrides),
 		stdlibPackages:     make(map[string]bool),
+		stdlibTopLevels:    make(map[string]bool),
 	}
 
 	// 初始化标准库包列表
 	for _, pkg := range goStdlibPackages {
 		c.stdlibPackages[pkg] = true
+		c.stdlibTopLevels[strings.SplitN(pkg, "/", 2)[0]] = true
 	}
 
 	return c
@@ -36,6 +47,11 @@ func (c *Classifier) Classify(packageName string) CodeCategory {
 		return CategoryUnknown
 	}
 
+	// 0. 用户指定的分类覆盖优先于所有启发式判断
+	if category, ok := c.override(packageName); ok {
+		return category
+	}
+
 	// 1. 检查是否是 runtime 包
 	if c.isRuntimePackage(packageName) {
 		return CategoryRuntime
@@ -46,12 +62,17 @@ func (c *Classifier) Classify(packageName string) CodeCategory {
 		return CategoryStdlib
 	}
 
-	// 3. 检查是否是业务代码（用户模块）
+	// 3. 模块 vendor 目录中的依赖带有模块路径前缀，但属于第三方代码
+	if isVendoredPackage(packageName) {
+		return CategoryThirdParty
+	}
+
+	// 4. 检查是否是业务代码（用户模块）
 	if c.isBusinessPackage(packageName) {
 		return CategoryBusiness
 	}
 
-	// 4. 检查是否是第三方包
+	// 5. 检查是否是第三方包
 	if c.isThirdPartyPackage(packageName) {
 		return CategoryThirdParty
 	}
@@ -59,6 +80,81 @@ func (c *Classifier) Classify(packageName string) CodeCategory {
 	return CategoryUnknown
 }
 
+// classifyOverride 单条分类覆盖：以 prefix 为路径前缀的包归入 category
+type classifyOverride struct {
+	prefix   string
+	category CodeCategory
+}
+
+// override 返回包名匹配的分类覆盖，多条匹配时取最长 (最具体) 的前缀
+func (c *Classifier) override(packageName string) (CodeCategory, bool) {
+	for _, o := range c.overrides {
+		if hasPathPrefix(packageName, o.prefix) {
+			return o.category, true
+		}
+	}
+	return "", false
+}
+
+// normalizeOverrides 去掉前缀首尾的空白和斜杠，丢弃空前缀和无效分类，按前缀长度降序排列
+func normalizeOverrides(overrides map[string]CodeCategory) []classifyOverride {
+	result := make([]classifyOverride, 0, len(overrides))
+	for prefix, category := range overrides {
+		prefix = strings.Trim(strings.TrimSpace(prefix), "/")
+		if prefix == "" || !category.Valid() {
+			continue
+		}
+		result = append(result, classifyOverride{prefix: prefix, category: category})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if len(result[i].prefix) != len(result[j].prefix) {
+			return len(result[i].prefix) > len(result[j].prefix)
+		}
+		return result[i].prefix < result[j].prefix
+	})
+	return result
+}
+
+// ParseClassifyOverrides 解析 -classify-override 的值，格式为逗号分隔的 "包路径前缀=分类"，
+// 如 "github.com/x=third_party,internal/shared=business"；分类必须是 Categories 中的一个 (缺少符号除外)
+func ParseClassifyOverrides(spec string) (map[string]CodeCategory, error) {
+	overrides := make(map[string]CodeCategory)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		prefix, category, ok := strings.Cut(item, "=")
+		prefix = strings.Trim(strings.TrimSpace(prefix), "/")
+		category = strings.TrimSpace(category)
+		if !ok || prefix == "" {
+			return nil, fmt.Errorf("invalid classify override '%s', must be prefix=category", item)
+		}
+		if !CodeCategory(category).Valid() || CodeCategory(category) == CategoryMissingSymbol {
+			names := make([]string, 0, len(Categories()))
+			for _, c := range Categories() {
+				if c != CategoryMissingSymbol {
+					names = append(names, string(c))
+				}
+			}
+			return nil, fmt.Errorf("invalid classify override '%s': unknown category '%s', must be one of: %s",
+				item, category, strings.Join(names, ", "))
+		}
+		if existing, dup := overrides[prefix]; dup && existing != CodeCategory(category) {
+			return nil, fmt.Errorf("conflicting classify overrides for '%s': %s and %s", prefix, string(existing), category)
+		}
+		overrides[prefix] = CodeCategory(category)
+	}
+	return overrides, nil
+}
+
+// CategoryFunc 返回供 analyzer.ComputeCategoryTotals 使用的分类函数
+func (c *Classifier) CategoryFunc() analyzer.PackageClassifier {
+	return func(packageName string) string {
+		return string(c.Classify(packageName))
+	}
+}
+
 // isRuntimePackage 检查是否是 Go 运行时包
 func (c *Classifier) isRuntimePackage(packageName string) bool {
 	return packageName == "runtime" || strings.HasPrefix(packageName, "runtime/")
@@ -66,29 +162,40 @@ func (c *Classifier) isRuntimePackage(packageName string) bool {
 
 // isStdlibPackage 检查是否是 Go 标准库包
 func (c *Classifier) isStdlibPackage(packageName string) bool {
-	// 检查完整包名
+	// 优先查询由工具链生成的标准库包列表 (包含 internal/... 和 vendor/...)
 	if c.stdlibPackages[packageName] {
 		return true
 	}
 
-	// 检查是否是标准库子包 (如 net/http/httptest)
-	// 通过检查顶级包是否在标准库列表中
-	topLevel := packageName
-	if idx := strings.Index(packageName, "/"); idx > 0 {
-		topLevel = packageName[:idx]
-	}
-
-	// 如果顶级包在标准库中，且不包含 "." (排除域名)
-	if c.stdlibPackages[topLevel] && !strings.Contains(topLevel, ".") {
+	// golang.org/x/* 不属于标准库，但作为扩展标准库一并归类
+	if strings.HasPrefix(packageName, "golang.org/x/") {
 		return true
 	}
 
-	// golang.org/x/* 被视为扩展标准库
-	if strings.HasPrefix(packageName, "golang.org/x/") {
-		return true
+	// 列表之外的包可能来自更新的 Go 版本，退回启发式判断
+	return isStdlibByHeuristic(packageName, c.stdlibTopLevels)
+}
+
+// isStdlibByHeuristic 对不在标准库列表中的包做启发式判断
+// 标准库导入路径的第一段不含点号且是已知的顶级目录 (如新增的 net/http/xxx)，
+// 包括标准库自身的 internal/、vendor/ 目录。用户模块中的 vendor、internal
+// 目录带有模块路径前缀，不会被误判
+func isStdlibByHeuristic(packageName string, topLevels map[string]bool) bool {
+	idx := strings.Index(packageName, "/")
+	if idx <= 0 {
+		return false
 	}
+	topLevel := packageName[:idx]
+	if strings.Contains(topLevel, ".") {
+		return false
+	}
+	return topLevels[topLevel]
+}
 
-	return false
+// isVendoredPackage 检查包是否位于某个模块的 vendor 目录中 (路径含 "/vendor/" 段)
+// 标准库自身的 vendor/... 以 "vendor/" 开头，已在标准库判断中处理
+func isVendoredPackage(packageName string) bool {
+	return strings.Contains(packageName, "/vendor/")
 }
 
 // isBusinessPackage 检查是否是业务代码包
@@ -116,9 +223,9 @@ func (c *Classifier) isBusinessPackage(packageName string) bool {
 
 // isThirdPartyPackage 检查是否是第三方包
 func (c *Classifier) isThirdPartyPackage(packageName string) bool {
-	// 检查用户配置的第三方前缀
+	// 检查用户配置的第三方前缀，按路径段匹配
 	for _, prefix := range c.thirdPartyPrefixes {
-		if strings.HasPrefix(packageName, prefix) {
+		if hasPathPrefix(packageName, prefix) {
 			return true
 		}
 	}
@@ -149,6 +256,44 @@ func (c *Classifier) isThirdPartyPackage(packageName string) bool {
 	return false
 }
 
+// NormalizeThirdPartyPrefixes 规范化 ThirdPartyPrefixes 并返回被丢弃前缀的警告
+// 前缀去掉首尾空白和斜杠后按路径段匹配 ("github.com/foo" 与 "github.com/foo/" 等价，都不匹配 "github.com/foobar")；
+// 空前缀和重复前缀被丢弃；与 ModuleName 重叠的前缀 (等于模块名、是模块的上级路径或位于模块内) 会覆盖业务代码的判断，
+// 同样被丢弃并给出警告。NewClassifier 总会做同样的规范化，这里用于在启动时把问题告诉用户
+func (c *LocatorConfig) NormalizeThirdPartyPrefixes() []string {
+	var warnings []string
+	c.ThirdPartyPrefixes = normalizeThirdPartyPrefixes(c.ThirdPartyPrefixes, c.ModuleName, &warnings)
+	return warnings
+}
+
+// normalizeThirdPartyPrefixes 规范化第三方前缀，warnings 不为 nil 时追加与模块名重叠的前缀的警告
+func normalizeThirdPartyPrefixes(prefixes []string, moduleName string, warnings *[]string) []string {
+	moduleName = strings.Trim(strings.TrimSpace(moduleName), "/")
+	seen := make(map[string]bool, len(prefixes))
+	var normalized []string
+	for _, prefix := range prefixes {
+		prefix = strings.Trim(strings.TrimSpace(prefix), "/")
+		if prefix == "" || seen[prefix] {
+			continue
+		}
+		seen[prefix] = true
+
+		if moduleName != "" && (hasPathPrefix(moduleName, prefix) || hasPathPrefix(prefix, moduleName)) {
+			if warnings != nil {
+				*warnings = append(*warnings, fmt.Sprintf("third-party prefix '%s' overlaps module '%s' and would shadow business code; ignored", prefix, moduleName))
+			}
+			continue
+		}
+		normalized = append(normalized, prefix)
+	}
+	return normalized
+}
+
+// hasPathPrefix 按路径段判断 path 是否等于 prefix 或位于 prefix 之下
+func hasPathPrefix(path, prefix string) bool {
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
 // DetectModuleName 从 go.mod 检测模块名
 func DetectModuleName(workDir string) (string, error) {
 	goModPath := filepath.Join(workDir, "go.mod")
@@ -176,170 +321,3 @@ func DetectModuleName(workDir string) (string, error) {
 
 	return "", os.ErrNotExist
 }
-
-// goStdlibPackages Go 标准库包列表
-var goStdlibPackages = []string{
-	// 基础包
-	"archive",
-	"archive/tar",
-	"archive/zip",
-	"bufio",
-	"builtin",
-	"bytes",
-	"compress",
-	"compress/bzip2",
-	"compress/flate",
-	"compress/gzip",
-	"compress/lzw",
-	"compress/zlib",
-	"container",
-	"container/heap",
-	"container/list",
-	"container/ring",
-	"context",
-	"crypto",
-	"crypto/aes",
-	"crypto/cipher",
-	"crypto/des",
-	"crypto/dsa",
-	"crypto/ecdh",
-	"crypto/ecdsa",
-	"crypto/ed25519",
-	"crypto/elliptic",
-	"crypto/hmac",
-	"crypto/md5",
-	"crypto/rand",
-	"crypto/rc4",
-	"crypto/rsa",
-	"crypto/sha1",
-	"crypto/sha256",
-	"crypto/sha512",
-	"crypto/subtle",
-	"crypto/tls",
-	"crypto/x509",
-	"crypto/x509/pkix",
-	"database",
-	"database/sql",
-	"database/sql/driver",
-	"debug",
-	"debug/buildinfo",
-	"debug/dwarf",
-	"debug/elf",
-	"debug/gosym",
-	"debug/macho",
-	"debug/pe",
-	"debug/plan9obj",
-	"embed",
-	"encoding",
-	"encoding/ascii85",
-	"encoding/asn1",
-	"encoding/base32",
-	"encoding/base64",
-	"encoding/binary",
-	"encoding/csv",
-	"encoding/gob",
-	"encoding/hex",
-	"encoding/json",
-	"encoding/pem",
-	"encoding/xml",
-	"errors",
-	"expvar",
-	"flag",
-	"fmt",
-	"go",
-	"go/ast",
-	"go/build",
-	"go/build/constraint",
-	"go/constant",
-	"go/doc",
-	"go/doc/comment",
-	"go/format",
-	"go/importer",
-	"go/parser",
-	"go/printer",
-	"go/scanner",
-	"go/token",
-	"go/types",
-	"hash",
-	"hash/adler32",
-	"hash/crc32",
-	"hash/crc64",
-	"hash/fnv",
-	"hash/maphash",
-	"html",
-	"html/template",
-	"image",
-	"image/color",
-	"image/color/palette",
-	"image/draw",
-	"image/gif",
-	"image/jpeg",
-	"image/png",
-	"index",
-	"index/suffixarray",
-	"io",
-	"io/fs",
-	"io/ioutil",
-	"log",
-	"log/slog",
-	"log/syslog",
-	"maps",
-	"math",
-	"math/big",
-	"math/bits",
-	"math/cmplx",
-	"math/rand",
-	"mime",
-	"mime/multipart",
-	"mime/quotedprintable",
-	"net",
-	"net/http",
-	"net/http/cgi",
-	"net/http/cookiejar",
-	"net/http/fcgi",
-	"net/http/httptest",
-	"net/http/httptrace",
-	"net/http/httputil",
-	"net/http/pprof",
-	"net/mail",
-	"net/netip",
-	"net/rpc",
-	"net/rpc/jsonrpc",
-	"net/smtp",
-	"net/textproto",
-	"net/url",
-	"os",
-	"os/exec",
-	"os/signal",
-	"os/user",
-	"path",
-	"path/filepath",
-	"plugin",
-	"reflect",
-	"regexp",
-	"regexp/syntax",
-	"slices",
-	"sort",
-	"strconv",
-	"strings",
-	"sync",
-	"sync/atomic",
-	"syscall",
-	"testing",
-	"testing/fstest",
-	"testing/iotest",
-	"testing/quick",
-	"text",
-	"text/scanner",
-	"text/tabwriter",
-	"text/template",
-	"text/template/parse",
-	"time",
-	"time/tzdata",
-	"unicode",
-	"unicode/utf16",
-	"unicode/utf8",
-	"unsafe",
-	// internal 包 (通常不直接使用，但可能出现在调用栈中)
-	"internal",
-}
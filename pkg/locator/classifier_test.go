@@ -224,6 +224,138 @@ func TestClassifier_CustomThirdPartyPrefixes(t *testing.T) {
 	assert.Equal(t, CategoryThirdParty, classifier.Classify("private.repo/utils"))
 }
 
+// TestNormalizeThirdPartyPrefixes tests normalizing empty, duplicate and module-overlapping prefixes
+func TestNormalizeThirdPartyPrefixes(t *testing.T) {
+	tests := []struct {
+		name     string
+		module   string
+		prefixes []string
+		expected []string
+		warnings int
+	}{
+		{"empty entries dropped", "github.com/myorg/app", []string{"", " ", "/"}, nil, 0},
+		{"slashes and whitespace trimmed", "", []string{" corp.example.com/ ", "/private.repo"}, []string{"corp.example.com", "private.repo"}, 0},
+		{"duplicates dropped", "", []string{"corp.example.com", "corp.example.com/", " corp.example.com"}, []string{"corp.example.com"}, 0},
+		{"equal to module", "github.com/myorg/app", []string{"github.com/myorg/app/"}, nil, 1},
+		{"parent of module", "github.com/myorg/app", []string{"github.com/myorg", "corp.example.com"}, []string{"corp.example.com"}, 1},
+		{"inside module", "github.com/myorg/app", []string{"github.com/myorg/app/vendor/lib"}, nil, 1},
+		{"sibling of module kept", "github.com/myorg/app", []string{"github.com/myorg/application"}, []string{"github.com/myorg/application"}, 0},
+		{"no module", "", []string{"github.com/myorg"}, []string{"github.com/myorg"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := LocatorConfig{ModuleName: tt.module, ThirdPartyPrefixes: tt.prefixes}
+			warnings := config.NormalizeThirdPartyPrefixes()
+			assert.Equal(t, tt.expected, config.ThirdPartyPrefixes)
+			assert.Len(t, warnings, tt.warnings)
+		})
+	}
+
+	config := LocatorConfig{ModuleName: "github.com/myorg/app", ThirdPartyPrefixes: []string{"github.com/myorg"}}
+	warnings := config.NormalizeThirdPartyPrefixes()
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "'github.com/myorg'")
+	assert.Contains(t, warnings[0], "shadow business code")
+}
+
+// TestClassifier_ThirdPartyPrefixBoundary tests that prefixes match whole path segments
+func TestClassifier_Overrides(t *testing.T) {
+	classifier := NewClassifier(LocatorConfig{
+		ModuleName: "github.com/myapp",
+		Overrides: map[string]CodeCategory{
+			"github.com/myapp/forked":        CategoryThirdParty,
+			"github.com/myapp/forked/patch/": CategoryBusiness,
+			"github.com/corp/shared":         CategoryBusiness,
+			"internal/shared":                CategoryBusiness,
+			"github.com/bad":                 CodeCategory("vendor"),
+			"  ":                             CategoryRuntime,
+		},
+	})
+
+	tests := []struct {
+		packageName string
+		expected    CodeCategory
+	}{
+		{"github.com/myapp/forked", CategoryThirdParty},
+		{"github.com/myapp/forked/json", CategoryThirdParty},
+		{"github.com/myapp/forked/patch", CategoryBusiness}, // 最长的前缀优先
+		{"github.com/myapp/forkedx", CategoryBusiness},      // 按路径段匹配
+		{"github.com/corp/shared/auth", CategoryBusiness},
+		{"internal/shared", CategoryBusiness},
+		{"github.com/bad/pkg", CategoryThirdParty}, // 无效分类被忽略
+		{"runtime", CategoryRuntime},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, classifier.Classify(tt.packageName), tt.packageName)
+	}
+}
+
+func TestParseClassifyOverrides(t *testing.T) {
+	overrides, err := ParseClassifyOverrides("github.com/x=third_party, internal/shared/=business,,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]CodeCategory{
+		"github.com/x":    CategoryThirdParty,
+		"internal/shared": CategoryBusiness,
+	}, overrides)
+
+	tests := []struct {
+		spec    string
+		errText string
+	}{
+		{"github.com/x", "invalid classify override 'github.com/x', must be prefix=category"},
+		{"=business", "invalid classify override '=business', must be prefix=category"},
+		{"github.com/x=vendor", "unknown category 'vendor', must be one of: business, generated, third_party, stdlib, runtime, unknown"},
+		{"github.com/x=missing_symbol", "unknown category 'missing_symbol', must be one of: business, generated, third_party, stdlib, runtime, unknown"},
+		{"github.com/x=business,github.com/x/=stdlib", "conflicting classify overrides for 'github.com/x': business and stdlib"},
+	}
+	for _, tt := range tests {
+		_, err := ParseClassifyOverrides(tt.spec)
+		require.Error(t, err, tt.spec)
+		assert.Contains(t, err.Error(), tt.errText)
+	}
+}
+
+func TestCodeCategory_Valid(t *testing.T) {
+	for _, category := range Categories() {
+		assert.True(t, category.Valid(), string(category))
+	}
+	assert.False(t, CodeCategory("vendor").Valid())
+	assert.False(t, CodeCategory("").Valid())
+}
+
+func TestClassifier_ThirdPartyPrefixBoundary(t *testing.T) {
+	classifier := NewClassifier(LocatorConfig{
+		ModuleName:         "github.com/myorg/app",
+		ThirdPartyPrefixes: []string{"corp.example.com/shared", "github.com/myorg"},
+	})
+
+	assert.Equal(t, CategoryThirdParty, classifier.Classify("corp.example.com/shared"))
+	assert.Equal(t, CategoryThirdParty, classifier.Classify("corp.example.com/shared/lib"))
+	assert.Equal(t, CategoryUnknown, classifier.Classify("corp.example.com/sharedutil"))
+	// 与模块重叠的前缀被忽略，业务代码不受影响
+	assert.Equal(t, CategoryBusiness, classifier.Classify("github.com/myorg/app/handler"))
+}
+
+// TestClassifier_VendoredPackages 模块 vendor 目录中的依赖归为第三方，即使带有模块路径前缀
+func TestClassifier_VendoredPackages(t *testing.T) {
+	classifier := NewClassifier(LocatorConfig{ModuleName: "github.com/mycompany/myapp"})
+
+	assert.Equal(t, CategoryThirdParty, classifier.Classify("github.com/mycompany/myapp/vendor/github.com/gin-gonic/gin"))
+	assert.Equal(t, CategoryThirdParty, classifier.Classify("github.com/mycompany/myapp/vendor/golang.org/x/sync/errgroup"))
+	assert.Equal(t, CategoryBusiness, classifier.Classify("github.com/mycompany/myapp/internal/service"))
+	assert.Equal(t, CategoryBusiness, classifier.Classify("github.com/mycompany/myapp/vendorapi"))
+	// 标准库自身的 vendor 目录仍是标准库
+	assert.Equal(t, CategoryStdlib, classifier.Classify("vendor/golang.org/x/net/http2/hpack"))
+
+	// 用户覆盖仍然优先
+	overridden := NewClassifier(LocatorConfig{
+		ModuleName: "github.com/mycompany/myapp",
+		Overrides:  map[string]CodeCategory{"github.com/mycompany/myapp/vendor/github.com/mycompany/shared": CategoryBusiness},
+	})
+	assert.Equal(t, CategoryBusiness, overridden.Classify("github.com/mycompany/myapp/vendor/github.com/mycompany/shared/log"))
+}
+
 // TestDetectModuleName tests module name detection from go.mod
 // **Validates: Requirements 2.5**
 func TestDetectModuleName(t *testing.T) {
@@ -661,3 +793,70 @@ func TestCallChain_Property_HasBusinessCodeIntegration(t *testing.T) {
 		})
 	}
 }
+
+func TestClassifier_CategoryFunc(t *testing.T) {
+	classify := NewClassifier(LocatorConfig{ModuleName: "github.com/mycompany/myapp"}).CategoryFunc()
+
+	assert.Equal(t, string(CategoryRuntime), classify("runtime"))
+	assert.Equal(t, string(CategoryStdlib), classify("encoding/json"))
+	assert.Equal(t, string(CategoryBusiness), classify("github.com/mycompany/myapp/handler"))
+	assert.Equal(t, string(CategoryUnknown), classify(""))
+}
+
+// TestClassifier_StdlibTrickyCases 测试标准库列表与启发式判断的边界情况
+func TestClassifier_StdlibTrickyCases(t *testing.T) {
+	classifier := NewClassifier(LocatorConfig{ModuleName: "github.com/mycompany/myapp"})
+
+	tests := []struct {
+		pkg      string
+		expected CodeCategory
+	}{
+		// 标准库内部包和标准库自带的 vendor 副本
+		{"internal/poll", CategoryStdlib},
+		{"internal/abi", CategoryStdlib},
+		{"vendor/golang.org/x/net/http/httpguts", CategoryStdlib},
+		// 列表之外但顶级目录已知的包 (更新的 Go 版本新增的子包)
+		{"net/http/internal/newpkg", CategoryStdlib},
+		{"crypto/internal/fips140/newalg", CategoryStdlib},
+		// 用户模块中的 internal/vendor 目录不是标准库，vendor 中的依赖是第三方
+		{"github.com/mycompany/myapp/internal/cache", CategoryBusiness},
+		{"github.com/mycompany/myapp/vendor/golang.org/x/net/http2", CategoryThirdParty},
+		{"github.com/other/lib/vendor/golang.org/x/text", CategoryThirdParty},
+		// 与标准库无关的本地包
+		{"service", CategoryBusiness},
+		{"service/handler", CategoryUnknown},
+		// golang.org/x 不在标准库列表中，但作为扩展标准库归类
+		{"golang.org/x/sync/errgroup", CategoryStdlib},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pkg, func(t *testing.T) {
+			assert.Equal(t, tt.expected, classifier.Classify(tt.pkg))
+		})
+	}
+}
+
+// TestGoStdlibPackages 测试生成的标准库包列表
+func TestGoStdlibPackages(t *testing.T) {
+	known := make(map[string]bool, len(goStdlibPackages))
+	for _, pkg := range goStdlibPackages {
+		known[pkg] = true
+	}
+
+	for _, pkg := range []string{"fmt", "net/http/httptest", "internal/poll", "unsafe"} {
+		assert.True(t, known[pkg], "%s should be in the stdlib list", pkg)
+	}
+	for _, pkg := range []string{"golang.org/x/net", "cmd/go", "main"} {
+		assert.False(t, known[pkg], "%s should not be in the stdlib list", pkg)
+	}
+}
+
+func TestIsStdlibByHeuristic(t *testing.T) {
+	topLevels := map[string]bool{"net": true, "internal": true, "vendor": true}
+
+	assert.True(t, isStdlibByHeuristic("net/http/newpkg", topLevels))
+	assert.True(t, isStdlibByHeuristic("internal/newpkg", topLevels))
+	assert.False(t, isStdlibByHeuristic("net", topLevels), "single segment packages must be in the list")
+	assert.False(t, isStdlibByHeuristic("service/handler", topLevels))
+	assert.False(t, isStdlibByHeuristic("example.com/net/http", topLevels))
+}
@@ -0,0 +1,151 @@
+package locator
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// 默认的命令模板，生成 "go tool pprof -focus=X profile.pprof" 形式的命令
+const (
+	DefaultPprofBinary     = "go tool pprof"
+	DefaultCommandTemplate = "{{.bin}} {{.flags}} {{.profile}}"
+)
+
+// 命令种类，对应 ExecutableCmd.Kind
+const (
+	CommandKindTop        = "top"
+	CommandKindFocus      = "focus"
+	CommandKindList       = "list"
+	CommandKindWeb        = "web"
+	CommandKindDiff       = "diff"
+	CommandKindAllocSpace = "alloc_space"
+	CommandKindInuseSpace = "inuse_space"
+	CommandKindFlameGraph = "flamegraph"
+)
+
+// commandKinds 所有命令种类，用于校验模板
+var commandKinds = []string{
+	CommandKindTop, CommandKindFocus, CommandKindList, CommandKindWeb,
+	CommandKindDiff, CommandKindAllocSpace, CommandKindInuseSpace, CommandKindFlameGraph,
+}
+
+// FlagStyle pprof 参数的书写方式
+type FlagStyle string
+
+const (
+	FlagStyleSingle   FlagStyle = "single"   // -focus=X (默认，go tool pprof 的写法)
+	FlagStyleDouble   FlagStyle = "double"   // --focus=X
+	FlagStyleSeparate FlagStyle = "separate" // -focus X
+)
+
+// CommandTemplate 生成命令的模板，用于包装脚本、远程 pprof 代理或单独安装的 pprof
+// Template 为 text/template 语法，可用变量：{{.bin}}、{{.flags}}、{{.profile}}、{{.kind}}，
+// diff 命令额外提供 {{.base}}；零值生成 "go tool pprof -focus=X profile.pprof"
+type CommandTemplate struct {
+	Binary    string    // 命令前缀，为空时使用 DefaultPprofBinary
+	FlagStyle FlagStyle // 参数写法，为空时使用 FlagStyleSingle
+	Template  string    // 命令模板，为空时使用 DefaultCommandTemplate
+}
+
+// pprofFlag 单个 pprof 参数，value 为空表示开关参数 (如 -top)
+type pprofFlag struct {
+	name  string
+	value string
+}
+
+// ParseFlagStyle 解析参数写法，空字符串返回默认写法
+func ParseFlagStyle(s string) (FlagStyle, error) {
+	switch style := FlagStyle(s); style {
+	case "":
+		return FlagStyleSingle, nil
+	case FlagStyleSingle, FlagStyleDouble, FlagStyleSeparate:
+		return style, nil
+	default:
+		return "", fmt.Errorf("invalid flag style '%s', must be one of: single, double, separate", s)
+	}
+}
+
+// Validate 检查模板能否解析，并且对每种命令都能渲染出包含 profile 路径的命令
+func (t CommandTemplate) Validate() error {
+	if _, err := ParseFlagStyle(string(t.FlagStyle)); err != nil {
+		return err
+	}
+	tmpl, err := t.parse()
+	if err != nil {
+		return fmt.Errorf("invalid command template: %w", err)
+	}
+	const profile = "/tmp/example.pprof"
+	for _, kind := range commandKinds {
+		command, err := t.execute(tmpl, kind, []pprofFlag{{name: "example", value: "1"}}, profile, "/tmp/base.pprof")
+		if err != nil {
+			return fmt.Errorf("invalid command template for %s command: %w", kind, err)
+		}
+		if !strings.Contains(command, profile) {
+			return fmt.Errorf("invalid command template for %s command: rendered command '%s' does not contain the profile path", kind, command)
+		}
+	}
+	return nil
+}
+
+// parse 解析模板，未定义的变量视为错误
+func (t CommandTemplate) parse() (*template.Template, error) {
+	text := t.Template
+	if text == "" {
+		text = DefaultCommandTemplate
+	}
+	return template.New("command").Option("missingkey=error").Parse(text)
+}
+
+// render 渲染一条命令；模板在运行时出错 (已通过 Validate 的模板不会出错) 时使用默认模板
+func (t CommandTemplate) render(kind string, flags []pprofFlag, profile, base string) string {
+	if tmpl, err := t.parse(); err == nil {
+		if command, err := t.execute(tmpl, kind, flags, profile, base); err == nil {
+			return command
+		}
+	}
+	fallback := CommandTemplate{Binary: t.Binary, FlagStyle: t.FlagStyle}
+	tmpl, _ := fallback.parse()
+	command, _ := fallback.execute(tmpl, kind, flags, profile, base)
+	return command
+}
+
+// execute 使用命令变量执行模板，去掉首尾空白
+func (t CommandTemplate) execute(tmpl *template.Template, kind string, flags []pprofFlag, profile, base string) (string, error) {
+	binary := t.Binary
+	if binary == "" {
+		binary = DefaultPprofBinary
+	}
+	data := map[string]string{
+		"bin":     binary,
+		"flags":   t.formatFlags(flags),
+		"profile": profile,
+		"kind":    kind,
+		"base":    base,
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
+
+// formatFlags 按参数写法拼接参数
+func (t CommandTemplate) formatFlags(flags []pprofFlag) string {
+	parts := make([]string, 0, len(flags))
+	for _, f := range flags {
+		prefix := "-"
+		if t.FlagStyle == FlagStyleDouble {
+			prefix = "--"
+		}
+		switch {
+		case f.value == "":
+			parts = append(parts, prefix+f.name)
+		case t.FlagStyle == FlagStyleSeparate:
+			parts = append(parts, prefix+f.name+" "+f.value)
+		default:
+			parts = append(parts, prefix+f.name+"="+f.value)
+		}
+	}
+	return strings.Join(parts, " ")
+}
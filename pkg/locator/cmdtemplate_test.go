@@ -0,0 +1,104 @@
+package locator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFlagStyle(t *testing.T) {
+	for _, s := range []string{"", "single"} {
+		style, err := ParseFlagStyle(s)
+		require.NoError(t, err)
+		assert.Equal(t, FlagStyleSingle, style)
+	}
+	style, err := ParseFlagStyle("separate")
+	require.NoError(t, err)
+	assert.Equal(t, FlagStyleSeparate, style)
+
+	_, err = ParseFlagStyle("posix")
+	assert.EqualError(t, err, "invalid flag style 'posix', must be one of: single, double, separate")
+}
+
+func TestCommandTemplate_Validate(t *testing.T) {
+	assert.NoError(t, CommandTemplate{}.Validate())
+	assert.NoError(t, CommandTemplate{Binary: "mycli profile", Template: "{{.bin}} {{.kind}} {{.profile}} -- {{.flags}}"}.Validate())
+
+	err := CommandTemplate{Template: "{{.bin"}.Validate()
+	assert.ErrorContains(t, err, "invalid command template:")
+
+	err = CommandTemplate{Template: "{{.bin}} {{.flags}}"}.Validate()
+	assert.EqualError(t, err, "invalid command template for top command: rendered command 'go tool pprof -example=1' does not contain the profile path")
+
+	err = CommandTemplate{Template: "{{.bin}} {{.file}}"}.Validate()
+	assert.ErrorContains(t, err, "invalid command template for top command:")
+
+	assert.Error(t, CommandTemplate{FlagStyle: "posix"}.Validate())
+}
+
+func TestCommandGenerator_Template(t *testing.T) {
+	tests := []struct {
+		name     string
+		template CommandTemplate
+		focus    string
+		diff     string
+		top      string
+	}{
+		{
+			name:     "default",
+			template: CommandTemplate{},
+			focus:    "go tool pprof -focus=HandleOrder cpu.pprof",
+			diff:     "go tool pprof -base=base.pprof cpu.pprof",
+			top:      "go tool pprof -top cpu.pprof",
+		},
+		{
+			name:     "standalone pprof with double dashes",
+			template: CommandTemplate{Binary: "pprof", FlagStyle: FlagStyleDouble},
+			focus:    "pprof --focus=HandleOrder cpu.pprof",
+			diff:     "pprof --base=base.pprof cpu.pprof",
+			top:      "pprof --top cpu.pprof",
+		},
+		{
+			name:     "wrapper with custom template",
+			template: CommandTemplate{Binary: "mycli profile", FlagStyle: FlagStyleSeparate, Template: "{{.bin}} --file {{.profile}} {{.flags}}"},
+			focus:    "mycli profile --file cpu.pprof -focus HandleOrder",
+			diff:     "mycli profile --file cpu.pprof -base base.pprof",
+			top:      "mycli profile --file cpu.pprof -top",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewCommandGeneratorWithOptions(CommandOptions{Template: tt.template})
+			assert.Equal(t, tt.focus, g.GenerateFocusCommand("cpu.pprof", "github.com/myapp/api.HandleOrder").Command)
+			assert.Equal(t, tt.diff, g.GenerateDiffCommand("base.pprof", "cpu.pprof").Command)
+			assert.Equal(t, tt.top, g.GenerateTopCommand("cpu.pprof").Command)
+		})
+	}
+}
+
+func TestCommandGenerator_TemplateFallback(t *testing.T) {
+	// 未经校验的无效模板退回默认模板，保留前缀和参数写法
+	g := NewCommandGeneratorWithOptions(CommandOptions{Template: CommandTemplate{Binary: "pprof", Template: "{{.missing}}"}})
+	assert.Equal(t, "pprof -list=Run cpu.pprof", g.GenerateListCommand("cpu.pprof", "main.Run").Command)
+}
+
+func TestSelectPrimaryCommand_CustomTemplate(t *testing.T) {
+	hotPaths := []HotPath{{
+		Chain:          CallChain{Frames: []StackFrame{{FunctionName: "github.com/myapp/api.HandleOrder", ShortName: "HandleOrder", Category: CategoryBusiness}}},
+		RootCauseIndex: 0,
+	}}
+	g := NewCommandGeneratorWithOptions(CommandOptions{Template: CommandTemplate{Binary: "pprof", FlagStyle: FlagStyleSeparate}})
+
+	commands := g.GenerateCommandsWithContext([]string{"heap1.pprof", "heap2.pprof"}, "heap", hotPaths)
+	primary := SelectPrimaryCommand(commands, "heap", hotPaths)
+	require.NotNil(t, primary)
+	assert.Equal(t, "pprof -list HandleOrder heap1.pprof", primary.Command)
+	assert.Equal(t, CommandKindList, primary.Kind)
+	assert.True(t, containsFocusCommand(commands, "HandleOrder"))
+
+	// 手动构造、没有 Kind 的命令按 go tool pprof 参数推断
+	manual := []ExecutableCmd{{Command: "go tool pprof -top x.pprof"}, {Command: "go tool pprof -alloc_space x.pprof"}}
+	assert.Equal(t, "go tool pprof -alloc_space x.pprof", SelectPrimaryCommand(manual, "heap", nil).Command)
+}
@@ -5,12 +5,44 @@ import (
 	"strings"
 )
 
+// ProfileTypeAllocs 来自 /debug/pprof/allocs 的 heap profile，命令默认查看累计分配 (alloc_space) 而不是存活内存
+const ProfileTypeAllocs = "allocs"
+
 // CommandGenerator 命令生成器
-type CommandGenerator struct{}
+type CommandGenerator struct {
+	opts CommandOptions
+}
+
+// CommandOptions 命令生成选项
+type CommandOptions struct {
+	// TopPathOnly 只为排名第一的热点路径生成 -focus/-list 命令；
+	// 默认 goroutine profile 会在其他热点路径中查找阻塞函数并额外生成 -focus 命令
+	TopPathOnly bool
+	// Template 命令前缀、参数写法和模板，零值生成 go tool pprof 命令
+	Template CommandTemplate
+}
 
 // NewCommandGenerator 创建命令生成器
 func NewCommandGenerator() *CommandGenerator {
-	return &CommandGenerator{}
+	return NewCommandGeneratorWithOptions(CommandOptions{})
+}
+
+// NewCommandGeneratorWithOptions 使用指定选项创建命令生成器
+func NewCommandGeneratorWithOptions(opts CommandOptions) *CommandGenerator {
+	return &CommandGenerator{opts: opts}
+}
+
+// command 按模板渲染一条命令
+func (g *CommandGenerator) command(kind string, profilePath string, flags ...pprofFlag) string {
+	return g.opts.Template.render(kind, flags, profilePath, "")
+}
+
+// focusCandidates 返回可以生成 -focus/-list 命令的热点路径
+func (g *CommandGenerator) focusCandidates(hotPaths []HotPath) []HotPath {
+	if g.opts.TopPathOnly && len(hotPaths) > 1 {
+		return hotPaths[:1]
+	}
+	return hotPaths
 }
 
 // GenerateCommands 根据 profile 类型和热点路径生成命令列表
@@ -34,8 +66,8 @@ func (g *CommandGenerator) GenerateCommands(
 		topPath := hotPaths[0]
 		if topPath.RootCauseIndex >= 0 && topPath.RootCauseIndex < len(topPath.Chain.Frames) {
 			rootCause := topPath.Chain.Frames[topPath.RootCauseIndex]
-			commands = append(commands, g.GenerateFocusCommand(profilePath, rootCause.ShortName))
-			commands = append(commands, g.GenerateListCommand(profilePath, rootCause.ShortName))
+			commands = append(commands, g.GenerateFocusCommand(profilePath, commandFunctionName(rootCause)))
+			commands = append(commands, g.GenerateListCommand(profilePath, commandFunctionName(rootCause)))
 		}
 	}
 
@@ -48,58 +80,93 @@ func (g *CommandGenerator) GenerateCommands(
 // GenerateFocusCommand 生成 -focus 命令，聚焦特定函数
 func (g *CommandGenerator) GenerateFocusCommand(profilePath, functionName string) ExecutableCmd {
 	// 清理函数名，移除可能的包路径前缀，只保留函数名部分
-	shortName := extractShortFunctionName(functionName)
+	shortName := pprofShortName(functionName)
 
 	return ExecutableCmd{
-		Command:     fmt.Sprintf("go tool pprof -focus=%s %s", shortName, profilePath),
+		Command:     g.command(CommandKindFocus, profilePath, pprofFlag{name: "focus", value: shortName}),
 		Description: fmt.Sprintf("聚焦到 %s 函数，只显示包含该函数的调用路径", shortName),
 		OutputHint:  "输出将只显示经过指定函数的调用路径，帮助你理解该函数的调用上下文",
+		Kind:        CommandKindFocus,
+		Function:    shortName,
 	}
 }
 
 // GenerateTopCommand 生成 -top 命令，查看热点函数列表
 func (g *CommandGenerator) GenerateTopCommand(profilePath string) ExecutableCmd {
 	return ExecutableCmd{
-		Command:     fmt.Sprintf("go tool pprof -top %s", profilePath),
+		Command:     g.command(CommandKindTop, profilePath, pprofFlag{name: "top"}),
 		Description: "查看消耗最多资源的函数列表",
 		OutputHint:  "flat 列显示函数自身消耗，cum 列显示函数及其调用的所有函数的总消耗",
+		Kind:        CommandKindTop,
 	}
 }
 
 // GenerateListCommand 生成 -list 命令，查看源码级别分析
 func (g *CommandGenerator) GenerateListCommand(profilePath, functionName string) ExecutableCmd {
-	shortName := extractShortFunctionName(functionName)
+	shortName := pprofShortName(functionName)
 
 	return ExecutableCmd{
-		Command:     fmt.Sprintf("go tool pprof -list=%s %s", shortName, profilePath),
+		Command:     g.command(CommandKindList, profilePath, pprofFlag{name: "list", value: shortName}),
 		Description: fmt.Sprintf("查看 %s 函数的源码级别分析", shortName),
 		OutputHint:  "显示函数源码及每行的资源消耗，帮助定位具体的问题代码行",
+		Kind:        CommandKindList,
+		Function:    shortName,
 	}
 }
 
 // GenerateWebCommand 生成 -http 命令，启动 Web 可视化界面
 func (g *CommandGenerator) GenerateWebCommand(profilePath string) ExecutableCmd {
 	return ExecutableCmd{
-		Command:     fmt.Sprintf("go tool pprof -http=:8080 %s", profilePath),
+		Command:     g.command(CommandKindWeb, profilePath, pprofFlag{name: "http", value: ":8080"}),
 		Description: "在浏览器中打开交互式可视化界面",
 		OutputHint:  "提供火焰图、调用图等多种可视化方式，支持交互式探索",
+		Kind:        CommandKindWeb,
 	}
 }
 
+// GenerateFlameGraphCommand 生成火焰图命令，在随机端口启动 Web 界面
+// pprof 没有直接输出火焰图的命令行参数，火焰图在 Web 界面的 VIEW → Flame Graph 中查看
+func (g *CommandGenerator) GenerateFlameGraphCommand(profilePath string) ExecutableCmd {
+	return g.flameGraphCommand(profilePath)
+}
+
+// flameGraphCommand 生成火焰图命令，extra 为附加的 sample 选择参数 (如 heap 的 -inuse_space)
+func (g *CommandGenerator) flameGraphCommand(profilePath string, extra ...pprofFlag) ExecutableCmd {
+	flags := append([]pprofFlag{{name: "http", value: ":0"}}, extra...)
+	return ExecutableCmd{
+		Command:     g.command(CommandKindFlameGraph, profilePath, flags...),
+		Description: "以火焰图查看调用栈的资源分布",
+		OutputHint:  "浏览器打开后选择 VIEW → Flame Graph (或访问 /ui/flamegraph)，越宽的函数消耗越多，自上而下是调用关系",
+		Kind:        CommandKindFlameGraph,
+	}
+}
+
+// profileFlameGraphCommand 按 profile 类型生成火焰图命令，heap 展示当前使用中的内存，allocs 展示累计分配
+func (g *CommandGenerator) profileFlameGraphCommand(profilePath, profileType string) ExecutableCmd {
+	switch profileType {
+	case "heap":
+		return g.flameGraphCommand(profilePath, pprofFlag{name: "inuse_space"})
+	case ProfileTypeAllocs:
+		return g.flameGraphCommand(profilePath, pprofFlag{name: "alloc_space"})
+	}
+	return g.GenerateFlameGraphCommand(profilePath)
+}
+
 // GenerateDiffCommand 生成差异对比命令
 // basePath: 基准 profile 文件路径
 // targetPath: 目标 profile 文件路径
 func (g *CommandGenerator) GenerateDiffCommand(basePath, targetPath string) ExecutableCmd {
 	return ExecutableCmd{
-		Command:     fmt.Sprintf("go tool pprof -base=%s %s", basePath, targetPath),
+		Command:     g.opts.Template.render(CommandKindDiff, []pprofFlag{{name: "base", value: basePath}}, targetPath, basePath),
 		Description: "对比两个 profile 文件的差异，查看资源消耗的变化",
 		OutputHint:  "正值表示目标 profile 比基准 profile 消耗更多，负值表示消耗减少",
+		Kind:        CommandKindDiff,
 	}
 }
 
 // GenerateCommandsWithContext 根据完整上下文生成命令
 // profilePaths: profile 文件路径列表
-// profileType: profile 类型 (cpu/heap/goroutine)
+// profileType: profile 类型 (cpu/heap/allocs/goroutine)
 // hotPaths: 热点路径列表
 // 返回针对性的 pprof 命令列表
 func (g *CommandGenerator) GenerateCommandsWithContext(
@@ -122,18 +189,18 @@ func (g *CommandGenerator) GenerateCommandsWithContext(
 
 	// 根据 profile 类型添加特定命令
 	switch profileType {
-	case "heap":
+	case "heap", ProfileTypeAllocs:
 		commands = append(commands, g.GenerateAllocSpaceCommand(primaryPath))
 		commands = append(commands, g.GenerateInuseSpaceCommand(primaryPath))
 	case "goroutine":
 		// goroutine profile 特定命令 - 聚焦阻塞函数
 		if len(hotPaths) > 0 {
-			for _, hp := range hotPaths {
+			for _, hp := range g.focusCandidates(hotPaths) {
 				if hp.RootCauseIndex >= 0 && hp.RootCauseIndex < len(hp.Chain.Frames) {
 					rootCause := hp.Chain.Frames[hp.RootCauseIndex]
 					// 检查是否是阻塞相关函数
 					if isBlockingFunction(rootCause.FunctionName) {
-						commands = append(commands, g.GenerateFocusCommand(primaryPath, rootCause.ShortName))
+						commands = append(commands, g.GenerateFocusCommand(primaryPath, commandFunctionName(rootCause)))
 						break
 					}
 				}
@@ -147,10 +214,11 @@ func (g *CommandGenerator) GenerateCommandsWithContext(
 		if topPath.RootCauseIndex >= 0 && topPath.RootCauseIndex < len(topPath.Chain.Frames) {
 			rootCause := topPath.Chain.Frames[topPath.RootCauseIndex]
 			// 避免重复添加 focus 命令
-			if !containsFocusCommand(commands, rootCause.ShortName) {
-				commands = append(commands, g.GenerateFocusCommand(primaryPath, rootCause.ShortName))
+			functionName := commandFunctionName(rootCause)
+			if !containsFocusCommand(commands, pprofShortName(functionName)) {
+				commands = append(commands, g.GenerateFocusCommand(primaryPath, functionName))
 			}
-			commands = append(commands, g.GenerateListCommand(primaryPath, rootCause.ShortName))
+			commands = append(commands, g.GenerateListCommand(primaryPath, functionName))
 		}
 	}
 
@@ -164,10 +232,76 @@ func (g *CommandGenerator) GenerateCommandsWithContext(
 
 	// Web 可视化命令
 	commands = append(commands, g.GenerateWebCommand(primaryPath))
+	commands = append(commands, g.profileFlameGraphCommand(primaryPath, profileType))
 
 	return commands
 }
 
+// SelectPrimaryCommand 从命令列表中选出推荐最先执行的命令
+// 有根因帧时：goroutine 使用 -focus 查看阻塞调用上下文，其他类型使用 -list 查看源码行；
+// 没有根因帧时：heap/allocs 使用 -alloc_space 查看分配热点，其他类型使用 -top；
+// allocs 的累计分配即问题本身，-alloc_space 优先于根因帧的 -list/-focus
+func SelectPrimaryCommand(commands []ExecutableCmd, profileType string, hotPaths []HotPath) *ExecutableCmd {
+	if len(commands) == 0 {
+		return nil
+	}
+
+	var preferred []string
+	if profileType == ProfileTypeAllocs {
+		preferred = append(preferred, CommandKindAllocSpace)
+	}
+	if len(hotPaths) > 0 && hotPaths[0].RootCauseIndex >= 0 && hotPaths[0].RootCauseIndex < len(hotPaths[0].Chain.Frames) {
+		if profileType == "goroutine" {
+			preferred = append(preferred, CommandKindFocus, CommandKindList)
+		} else {
+			preferred = append(preferred, CommandKindList, CommandKindFocus)
+		}
+	}
+	if profileType == "heap" {
+		preferred = append(preferred, CommandKindAllocSpace)
+	}
+	preferred = append(preferred, CommandKindTop)
+
+	for _, kind := range preferred {
+		for _, cmd := range commands {
+			if commandKind(cmd) == kind {
+				primary := cmd
+				return &primary
+			}
+		}
+	}
+
+	primary := commands[0]
+	return &primary
+}
+
+// commandKindFlags 未设置 Kind 的命令按 go tool pprof 参数推断种类
+var commandKindFlags = []struct {
+	flag string
+	kind string
+}{
+	{"-focus=", CommandKindFocus},
+	{"-list=", CommandKindList},
+	{"-alloc_space ", CommandKindAllocSpace},
+	{"-inuse_space ", CommandKindInuseSpace},
+	{"-base=", CommandKindDiff},
+	{"-http=", CommandKindWeb},
+	{"-top ", CommandKindTop},
+}
+
+// commandKind 返回命令种类，手动构造的命令从命令内容推断
+func commandKind(cmd ExecutableCmd) string {
+	if cmd.Kind != "" {
+		return cmd.Kind
+	}
+	for _, f := range commandKindFlags {
+		if strings.Contains(cmd.Command, f.flag) {
+			return f.kind
+		}
+	}
+	return ""
+}
+
 // isBlockingFunction 检查是否是阻塞相关函数
 func isBlockingFunction(functionName string) bool {
 	blockingPatterns := []string{
@@ -184,9 +318,8 @@ func isBlockingFunction(functionName string) bool {
 
 // containsFocusCommand 检查命令列表中是否已包含指定函数的 focus 命令
 func containsFocusCommand(commands []ExecutableCmd, functionName string) bool {
-	focusPattern := fmt.Sprintf("-focus=%s", functionName)
 	for _, cmd := range commands {
-		if strings.Contains(cmd.Command, focusPattern) {
+		if cmd.Kind == CommandKindFocus && cmd.Function == functionName {
 			return true
 		}
 	}
@@ -196,18 +329,20 @@ func containsFocusCommand(commands []ExecutableCmd, functionName string) bool {
 // GenerateAllocSpaceCommand 生成内存分配分析命令（仅用于 heap profile）
 func (g *CommandGenerator) GenerateAllocSpaceCommand(profilePath string) ExecutableCmd {
 	return ExecutableCmd{
-		Command:     fmt.Sprintf("go tool pprof -alloc_space %s", profilePath),
+		Command:     g.command(CommandKindAllocSpace, profilePath, pprofFlag{name: "alloc_space"}),
 		Description: "查看累计分配的内存，找出分配最多的函数",
 		OutputHint:  "显示程序运行期间累计分配的内存量，帮助发现内存分配热点",
+		Kind:        CommandKindAllocSpace,
 	}
 }
 
 // GenerateInuseSpaceCommand 生成内存使用分析命令（仅用于 heap profile）
 func (g *CommandGenerator) GenerateInuseSpaceCommand(profilePath string) ExecutableCmd {
 	return ExecutableCmd{
-		Command:     fmt.Sprintf("go tool pprof -inuse_space %s", profilePath),
+		Command:     g.command(CommandKindInuseSpace, profilePath, pprofFlag{name: "inuse_space"}),
 		Description: "查看当前正在使用的内存",
 		OutputHint:  "显示当前仍在使用的内存量，帮助发现内存泄漏",
+		Kind:        CommandKindInuseSpace,
 	}
 }
 
@@ -221,7 +356,7 @@ func (g *CommandGenerator) GenerateCommandsForProfileType(
 
 	// 根据 profile 类型添加特定命令
 	switch profileType {
-	case "heap":
+	case "heap", ProfileTypeAllocs:
 		// 在 top 命令后插入内存特定命令
 		heapCommands := []ExecutableCmd{
 			g.GenerateAllocSpaceCommand(profilePath),
@@ -239,137 +374,6 @@ func (g *CommandGenerator) GenerateCommandsForProfileType(
 		}
 	}
 
+	commands = append(commands, g.profileFlameGraphCommand(profilePath, profileType))
 	return commands
 }
-
-// extractShortFunctionName 从完整函数名中提取短函数名
-func extractShortFunctionName(functionName string) string {
-	if functionName == "" {
-		return functionName
-	}
-
-	// 尝试提取最后一个 . 后面的部分（方法名）
-	// 例如: "github.com/user/pkg.(*Type).Method" -> "Method"
-	// 或者: "main.handleRequest" -> "handleRequest"
-	// 或者: "github.com/user/pkg.HandleRequest" -> "HandleRequest"
-	// 特殊处理匿名函数:
-	// "main.init.0.func1.1" -> "init.0.func1.1" (保留匿名函数的完整标识)
-	// "main.createWorker.func1" -> "createWorker.func1"
-
-	// 首先检查是否是匿名函数（包含 .func 或以数字结尾）
-	if isAnonymousFunction(functionName) {
-		return extractAnonymousFunctionName(functionName)
-	}
-
-	lastDot := -1
-	parenDepth := 0
-	for i := len(functionName) - 1; i >= 0; i-- {
-		c := functionName[i]
-		if c == ')' {
-			parenDepth++
-		} else if c == '(' {
-			parenDepth--
-		} else if c == '.' && parenDepth == 0 {
-			lastDot = i
-			break
-		}
-	}
-
-	if lastDot >= 0 && lastDot < len(functionName)-1 {
-		return functionName[lastDot+1:]
-	}
-
-	return functionName
-}
-
-// isAnonymousFunction 检查是否是匿名函数
-func isAnonymousFunction(functionName string) bool {
-	// 匿名函数的特征：
-	// 1. 包含 ".func" 模式（如 main.init.func1, createWorker.func1）
-	// 2. 以数字结尾（如 init.0.func1.1）
-	if strings.Contains(functionName, ".func") {
-		return true
-	}
-	// 检查是否以数字结尾
-	if len(functionName) > 0 {
-		lastChar := functionName[len(functionName)-1]
-		if lastChar >= '0' && lastChar <= '9' {
-			// 进一步检查是否是 .数字 模式
-			for i := len(functionName) - 1; i >= 0; i-- {
-				c := functionName[i]
-				if c == '.' {
-					// 找到了 .数字 模式，可能是匿名函数
-					return true
-				}
-				if c < '0' || c > '9' {
-					break
-				}
-			}
-		}
-	}
-	return false
-}
-
-// extractAnonymousFunctionName 提取匿名函数的有意义名称
-func extractAnonymousFunctionName(functionName string) string {
-	// 对于匿名函数，我们需要保留足够的上下文
-	// 例如: "main.init.0.func1.1" -> "init.0.func1.1"
-	// 例如: "main.createWorker.func1" -> "createWorker.func1"
-	// 例如: "github.com/user/pkg.(*Server).handleRequest.func1" -> "handleRequest.func1"
-
-	// 找到 .func 的位置
-	funcIndex := strings.Index(functionName, ".func")
-	if funcIndex == -1 {
-		// 没有 .func，可能是 init.0 这样的模式
-		// 尝试找到包名后的部分
-		return extractAfterPackage(functionName)
-	}
-
-	// 从 .func 往前找，找到父函数名
-	// 跳过可能的 (*Type) 部分
-	startIndex := 0
-	parenDepth := 0
-	for i := funcIndex - 1; i >= 0; i-- {
-		c := functionName[i]
-		if c == ')' {
-			parenDepth++
-		} else if c == '(' {
-			parenDepth--
-		} else if c == '.' && parenDepth == 0 {
-			// 检查这个点之前是否是包路径
-			prefix := functionName[:i]
-			if strings.Contains(prefix, "/") || prefix == "main" || prefix == "runtime" {
-				startIndex = i + 1
-				break
-			}
-		}
-	}
-
-	result := functionName[startIndex:]
-	if result == "" {
-		return functionName
-	}
-	return result
-}
-
-// extractAfterPackage 提取包名之后的部分
-func extractAfterPackage(functionName string) string {
-	// 找到最后一个 / 之后的第一个 .
-	lastSlash := strings.LastIndex(functionName, "/")
-	if lastSlash >= 0 {
-		remainder := functionName[lastSlash+1:]
-		dotIndex := strings.Index(remainder, ".")
-		if dotIndex >= 0 {
-			return remainder[dotIndex+1:]
-		}
-		return remainder
-	}
-
-	// 没有 /，可能是 main.xxx 或 runtime.xxx
-	dotIndex := strings.Index(functionName, ".")
-	if dotIndex >= 0 {
-		return functionName[dotIndex+1:]
-	}
-
-	return functionName
-}
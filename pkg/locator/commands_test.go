@@ -7,6 +7,7 @@ import (
 	"testing/quick"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Feature: problem-locator, Property 7: Command Generation Validity
@@ -214,6 +215,70 @@ func TestGenerateInuseSpaceCommand(t *testing.T) {
 	assert.NotEmpty(t, cmd.OutputHint)
 }
 
+// TestGenerateFlameGraphCommand tests flame graph command generation
+func TestGenerateFlameGraphCommand(t *testing.T) {
+	generator := NewCommandGenerator()
+	cmd := generator.GenerateFlameGraphCommand("./cpu.pprof")
+
+	assert.True(t, strings.HasPrefix(cmd.Command, "go tool pprof"))
+	assert.Equal(t, "go tool pprof -http=:0 ./cpu.pprof", cmd.Command)
+	assert.Equal(t, CommandKindFlameGraph, cmd.Kind)
+	assert.Contains(t, cmd.Description, "火焰图")
+	assert.Contains(t, cmd.OutputHint, "Flame Graph")
+}
+
+// TestGenerateCommandsForProfileType_FlameGraph tests that every profile type gets a flame graph command
+func TestGenerateCommandsForProfileType_FlameGraph(t *testing.T) {
+	generator := NewCommandGenerator()
+	flameGraph := func(commands []ExecutableCmd) *ExecutableCmd {
+		for i := range commands {
+			if commands[i].Kind == CommandKindFlameGraph {
+				return &commands[i]
+			}
+		}
+		return nil
+	}
+
+	cpu := flameGraph(generator.GenerateCommandsForProfileType("./cpu.pprof", "cpu", nil))
+	require.NotNil(t, cpu)
+	assert.Equal(t, "go tool pprof -http=:0 ./cpu.pprof", cpu.Command)
+
+	// heap 火焰图展示当前使用中的内存
+	heap := flameGraph(generator.GenerateCommandsForProfileType("./heap.pprof", "heap", nil))
+	require.NotNil(t, heap)
+	assert.Equal(t, "go tool pprof -http=:0 -inuse_space ./heap.pprof", heap.Command)
+
+	withContext := flameGraph(generator.GenerateCommandsWithContext([]string{"./heap1.pprof", "./heap2.pprof"}, "heap", nil))
+	require.NotNil(t, withContext)
+	assert.Equal(t, "go tool pprof -http=:0 -inuse_space ./heap1.pprof", withContext.Command)
+}
+
+// TestGenerateCommandsForProfileType_Allocs allocs profile 默认查看累计分配
+func TestGenerateCommandsForProfileType_Allocs(t *testing.T) {
+	generator := NewCommandGenerator()
+	commands := generator.GenerateCommandsForProfileType("./allocs.pprof", ProfileTypeAllocs, nil)
+
+	var kinds []string
+	for _, cmd := range commands {
+		kinds = append(kinds, cmd.Kind)
+	}
+	assert.Equal(t, []string{CommandKindTop, CommandKindAllocSpace, CommandKindInuseSpace, CommandKindWeb, CommandKindFlameGraph}, kinds)
+	assert.Equal(t, "go tool pprof -http=:0 -alloc_space ./allocs.pprof", commands[len(commands)-1].Command)
+
+	// 有根因帧时 allocs 仍优先 -alloc_space，heap 优先 -list
+	hotPaths := []HotPath{{
+		Chain:          CallChain{Frames: []StackFrame{{FunctionName: "main.handler", PackageName: "main"}}},
+		RootCauseIndex: 0,
+	}}
+	commands = generator.GenerateCommandsWithContext([]string{"./allocs.pprof"}, ProfileTypeAllocs, hotPaths)
+	primary := SelectPrimaryCommand(commands, ProfileTypeAllocs, hotPaths)
+	require.NotNil(t, primary)
+	assert.Equal(t, "go tool pprof -alloc_space ./allocs.pprof", primary.Command)
+
+	heapCommands := generator.GenerateCommandsWithContext([]string{"./heap.pprof"}, "heap", hotPaths)
+	assert.Equal(t, CommandKindList, SelectPrimaryCommand(heapCommands, "heap", hotPaths).Kind)
+}
+
 // TestGenerateCommandsForProfileType_Heap tests heap-specific commands
 func TestGenerateCommandsForProfileType_Heap(t *testing.T) {
 	generator := NewCommandGenerator()
@@ -234,82 +299,6 @@ func TestGenerateCommandsForProfileType_Heap(t *testing.T) {
 	assert.True(t, hasInuseSpace, "Should have inuse_space command for heap profile")
 }
 
-// TestExtractShortFunctionName tests function name extraction
-func TestExtractShortFunctionName(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected string
-	}{
-		{"HandleRequest", "HandleRequest"},
-		{"main.HandleRequest", "HandleRequest"},
-		{"github.com/user/pkg.HandleRequest", "HandleRequest"},
-		{"github.com/user/pkg.(*Type).Method", "Method"},
-		{"", ""},
-		// 匿名函数测试用例
-		{"main.init.0.func1.1", "init.0.func1.1"},
-		{"main.createWorker.func1", "createWorker.func1"},
-		{"main.init.func1", "init.func1"},
-		{"github.com/user/pkg.(*Server).handleRequest.func1", "handleRequest.func1"},
-		{"main.main.func1", "main.func1"},
-		{"runtime.main.func1", "main.func1"},
-		// 带数字后缀的匿名函数
-		{"main.init.0.func1.2", "init.0.func1.2"},
-		{"main.TestFunc.func1.1.1", "TestFunc.func1.1.1"},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.input, func(t *testing.T) {
-			result := extractShortFunctionName(tt.input)
-			assert.Equal(t, tt.expected, result)
-		})
-	}
-}
-
-// TestIsAnonymousFunction tests anonymous function detection
-func TestIsAnonymousFunction(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected bool
-	}{
-		{"main.HandleRequest", false},
-		{"main.init.func1", true},
-		{"main.createWorker.func1", true},
-		{"main.init.0.func1.1", true},
-		{"github.com/user/pkg.(*Server).handleRequest.func1", true},
-		{"runtime.mallocgc", false},
-		{"main.main", false},
-		{"", false},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.input, func(t *testing.T) {
-			result := isAnonymousFunction(tt.input)
-			assert.Equal(t, tt.expected, result)
-		})
-	}
-}
-
-// TestExtractAnonymousFunctionName tests anonymous function name extraction
-func TestExtractAnonymousFunctionName(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected string
-	}{
-		{"main.init.func1", "init.func1"},
-		{"main.createWorker.func1", "createWorker.func1"},
-		{"main.init.0.func1.1", "init.0.func1.1"},
-		{"github.com/user/pkg.(*Server).handleRequest.func1", "handleRequest.func1"},
-		{"main.main.func1", "main.func1"},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.input, func(t *testing.T) {
-			result := extractAnonymousFunctionName(tt.input)
-			assert.Equal(t, tt.expected, result)
-		})
-	}
-}
-
 // TestCommandGenerationValidity_Property is a property-based test for command generation
 // **Property 7: Command Generation Validity**
 // **Validates: Requirements 6.1, 6.2, 6.3, 6.4**
@@ -385,7 +374,7 @@ func TestCommandGenerationValidity_Property(t *testing.T) {
 			{
 				Chain: CallChain{
 					Frames: []StackFrame{
-						{FunctionName: functionName, ShortName: extractShortFunctionName(functionName), Category: CategoryBusiness},
+						{FunctionName: functionName, ShortName: ExtractShortName(functionName), Category: CategoryBusiness},
 					},
 				},
 				RootCauseIndex: 0,
@@ -560,7 +549,7 @@ func TestProperty7_HotPathGeneratesFocusCommand(t *testing.T) {
 
 	f := func(functionNameSeed, profileTypeSeed uint8) bool {
 		// Use function names that are already short names (no package prefix)
-		// to avoid confusion with extractShortFunctionName behavior
+		// to avoid confusion with pprofShortName behavior
 		functionNames := []string{
 			"HandleRequest",
 			"ProcessData",
@@ -819,3 +808,118 @@ func TestGenerateCommandsWithContext(t *testing.T) {
 		assert.True(t, hasFocus, "Should have focus command for hot path")
 	})
 }
+
+// TestSelectPrimaryCommand tests choosing the "start here" command
+func TestSelectPrimaryCommand(t *testing.T) {
+	rootCausePaths := []HotPath{
+		{
+			Chain: CallChain{
+				Frames: []StackFrame{
+					{FunctionName: "main.main", ShortName: "main", Category: CategoryBusiness},
+					{FunctionName: "github.com/myapp/worker.(*Pool).Start", ShortName: "Start", Category: CategoryBusiness},
+				},
+			},
+			RootCauseIndex: 1,
+		},
+	}
+	noRootCause := []HotPath{
+		{
+			Chain:          CallChain{Frames: []StackFrame{{FunctionName: "runtime.gcBgMarkWorker", Category: CategoryRuntime}}},
+			RootCauseIndex: -1,
+		},
+	}
+	paths := []string{"heap1.pprof", "heap2.pprof"}
+
+	tests := []struct {
+		name        string
+		profileType string
+		hotPaths    []HotPath
+		expected    string
+	}{
+		{"cpu with root cause lists source", "cpu", rootCausePaths, "go tool pprof -list=Start heap1.pprof"},
+		{"heap with root cause lists source", "heap", rootCausePaths, "go tool pprof -list=Start heap1.pprof"},
+		{"goroutine with root cause focuses", "goroutine", rootCausePaths, "go tool pprof -focus=Start heap1.pprof"},
+		{"heap without root cause uses alloc_space", "heap", noRootCause, "go tool pprof -alloc_space heap1.pprof"},
+		{"cpu without root cause uses top", "cpu", noRootCause, "go tool pprof -top heap1.pprof"},
+		{"no hot paths uses top", "goroutine", nil, "go tool pprof -top heap1.pprof"},
+	}
+
+	generator := NewCommandGenerator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commands := generator.GenerateCommandsWithContext(paths, tt.profileType, tt.hotPaths)
+			primary := SelectPrimaryCommand(commands, tt.profileType, tt.hotPaths)
+			if assert.NotNil(t, primary) {
+				assert.Equal(t, tt.expected, primary.Command)
+			}
+		})
+	}
+
+	t.Run("empty command list", func(t *testing.T) {
+		assert.Nil(t, SelectPrimaryCommand(nil, "cpu", rootCausePaths))
+	})
+
+	t.Run("returns a copy", func(t *testing.T) {
+		commands := generator.GenerateCommandsWithContext(paths, "cpu", nil)
+		primary := SelectPrimaryCommand(commands, "cpu", nil)
+		primary.Command = "changed"
+		assert.NotEqual(t, "changed", commands[0].Command)
+	})
+}
+
+// TestGenerateCommands_PrimaryCommand tests that generateCommands picks a primary command from its list
+func TestGenerateCommands_PrimaryCommand(t *testing.T) {
+	commands, primary := generateCommands("heap", nil, nil, CommandOptions{})
+	if assert.NotNil(t, primary) {
+		assert.Equal(t, "go tool pprof -alloc_space ./heap.pprof", primary.Command)
+		assert.Contains(t, commands, *primary)
+	}
+}
+
+// TestGenerateCommandsWithContext_TopPathOnly tests restricting focus commands to the top hot path
+func TestGenerateCommandsWithContext_TopPathOnly(t *testing.T) {
+	hotPaths := []HotPath{
+		{
+			Chain:          CallChain{Frames: []StackFrame{{FunctionName: "main.handleRequest", ShortName: "handleRequest", Category: CategoryBusiness}}},
+			RootCauseIndex: 0,
+		},
+		{
+			Chain:          CallChain{Frames: []StackFrame{{FunctionName: "main.(*Pool).Wait", ShortName: "Pool.Wait", Category: CategoryBusiness}}},
+			RootCauseIndex: 0,
+		},
+	}
+	focused := func(commands []ExecutableCmd) []string {
+		var names []string
+		for _, cmd := range commands {
+			if strings.Contains(cmd.Command, "-focus=") || strings.Contains(cmd.Command, "-list=") {
+				names = append(names, cmd.Command)
+			}
+		}
+		return names
+	}
+
+	// 默认在其他热点路径中查找阻塞函数
+	commands := NewCommandGenerator().GenerateCommandsWithContext([]string{"./goroutine.pprof"}, "goroutine", hotPaths)
+	assert.Len(t, focused(commands), 3)
+	assert.True(t, containsFocusCommand(commands, "Wait"))
+
+	commands = NewCommandGeneratorWithOptions(CommandOptions{TopPathOnly: true}).GenerateCommandsWithContext(
+		[]string{"./goroutine1.pprof", "./goroutine2.pprof"}, "goroutine", hotPaths)
+	assert.Len(t, focused(commands), 2)
+	for _, cmd := range focused(commands) {
+		assert.Contains(t, cmd, "handleRequest")
+	}
+	// 通用命令保持不变
+	assert.Contains(t, commands[0].Command, "-top")
+	assert.Contains(t, commands[len(commands)-2].Command, "-http=")
+	assert.Equal(t, CommandKindFlameGraph, commands[len(commands)-1].Kind)
+	assert.True(t, strings.Contains(commands[len(commands)-3].Command, "-base="))
+}
+
+// TestGenerateContext_CommandsTopOnly tests that the locator config reaches the command generator
+func TestGenerateContext_CommandsTopOnly(t *testing.T) {
+	assert.Equal(t, CommandOptions{}, (&ContextGenerator{}).commandOptions())
+
+	config := LocatorConfig{ModuleName: "github.com/myapp", CommandsTopOnly: true}
+	assert.Equal(t, CommandOptions{TopPathOnly: true}, NewContextGeneratorFromConfig(config).commandOptions())
+}
@@ -1,10 +1,12 @@
 package locator
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
 	"github.com/google/pprof/profile"
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
 	"github.com/songzhibin97/perfinspector/pkg/rules"
 )
 
@@ -20,8 +22,17 @@ func NewContextGenerator(analyzer *PathAnalyzer) *ContextGenerator {
 	}
 }
 
+// NewContextGeneratorFromConfig 根据定位器配置创建生成器，依次组装 Classifier、Extractor 和 PathAnalyzer
+func NewContextGeneratorFromConfig(config LocatorConfig) *ContextGenerator {
+	classifier := NewClassifier(config)
+	extractor := NewExtractorWithConfig(classifier, config)
+	return NewContextGenerator(NewPathAnalyzer(extractor, config))
+}
+
 // GenerateContext 生成问题上下文
-// 从 Finding 和 profiles 生成完整的 ProblemContext
+// 从 Finding 和 profiles 生成完整的 ProblemContext；profiles 的键为 profile 类型，
+// 匹配 DetermineProfileType 的 profile 用于热点分析，没有匹配时 HotPaths 为空。
+// 生成器未设置 PathAnalyzer 时返回 nil
 func (g *ContextGenerator) GenerateContext(
 	finding rules.Finding,
 	profiles map[string]*profile.Profile,
@@ -47,53 +58,105 @@ func (g *ContextGenerator) GenerateContextWithAllProfiles(
 	allProfiles map[string][]*profile.Profile,
 	profilePaths []string,
 ) *ProblemContext {
+	problem, _ := g.GenerateContextCtx(context.Background(), finding, profiles, allProfiles, profilePaths)
+	return problem
+}
+
+// GenerateContextCtx 与 GenerateContextWithAllProfiles 相同，但热点路径和归属分析在逐样本循环中检查 ctx
+// ctx 取消或超时时返回 nil 和 ctx.Err()，适合在带超时的请求处理中调用
+func (g *ContextGenerator) GenerateContextCtx(
+	ctx context.Context,
+	finding rules.Finding,
+	profiles map[string]*profile.Profile,
+	allProfiles map[string][]*profile.Profile,
+	profilePaths []string,
+) (*ProblemContext, error) {
 	if g.analyzer == nil {
-		return nil
+		return nil, nil
 	}
 
 	// 确定 profile 类型
-	profileType := determineProfileType(finding)
+	profileType := DetermineProfileType(finding)
 
 	// 分析热点路径
 	var hotPaths []HotPath
+	var hidden HiddenHotPaths
+	var latest *profile.Profile   // 最新的快照，用于 heap 归属分析
+	var series []*profile.Profile // 参与综合分析的全部快照，用于前后窗口对比
+	var err error
 
 	// 优先使用所有 profiles 进行综合分析（特别是 CPU 类型）
 	if allProfiles != nil {
 		for pType, profs := range allProfiles {
 			if strings.Contains(strings.ToLower(pType), profileType) && len(profs) > 0 {
 				// 使用多 profile 综合分析
-				hotPaths = g.analyzer.AnalyzeMultipleProfiles(profs, profileType)
+				if hotPaths, hidden, err = g.analyzer.analyzeMultipleProfiles(ctx, profs, profileType); err != nil {
+					return nil, err
+				}
+				latest = profs[len(profs)-1]
+				series = profs
 				break
 			}
 		}
 	}
 
-	// 如果没有使用多 profile 分析，回退到单个 profile
-	if len(hotPaths) == 0 && profiles != nil {
+	// 如果没有使用多 profile 分析，回退到单个 profile；路径全部被排除时不回退
+	if len(hotPaths) == 0 && hidden.Count == 0 && profiles != nil {
 		for pType, prof := range profiles {
 			if strings.Contains(strings.ToLower(pType), profileType) {
-				hotPaths = g.analyzer.AnalyzeHotPaths(prof, profileType)
+				if hotPaths, hidden, err = g.analyzer.analyzeHotPaths(ctx, prof, profileType); err != nil {
+					return nil, err
+				}
+				latest = prof
 				break
 			}
 		}
 	}
 
+	commands, primary := generateCommands(commandProfileType(profileType, latest), hotPaths, profilePaths, g.commandOptions())
+
 	// 生成问题上下文
-	ctx := &ProblemContext{
-		Title:       finding.Title,
-		Severity:    normalizeSeverity(finding.Severity),
-		Explanation: GenerateExplanation(finding, hotPaths),
-		Impact:      GenerateImpact(hotPaths, profileType),
-		HotPaths:    hotPaths,
-		Commands:    generateCommands(profileType, hotPaths, profilePaths),
-		Suggestions: GenerateSuggestions(finding, hotPaths),
+	problem := &ProblemContext{
+		Title:          finding.Title,
+		Severity:       normalizeSeverity(finding.Severity),
+		Explanation:    GenerateExplanation(finding, hotPaths),
+		Impact:         GenerateImpact(hotPaths, profileType),
+		HotPaths:       hotPaths,
+		Commands:       commands,
+		PrimaryCommand: primary,
+		Suggestions:    GenerateSuggestions(finding, hotPaths),
+		HiddenHotPaths: hidden,
+	}
+
+	// 内存问题附带最新快照的存活内存归属
+	if profileType == "heap" {
+		if problem.Ownership, err = g.analyzer.AnalyzeOwnershipCtx(ctx, latest); err != nil {
+			return nil, err
+		}
+	}
+
+	// 多个快照时对比前后两个窗口的热点路径迁移
+	if len(series) >= 2 {
+		shift, err := g.analyzer.CompareWindowsCtx(ctx, series, profileType, g.analyzer.config.WindowPivot)
+		if err != nil {
+			return nil, err
+		}
+		if !shift.Empty() {
+			problem.WindowShift = shift
+		}
 	}
 
-	return ctx
+	return problem, nil
 }
 
-// determineProfileType 从 Finding 确定 profile 类型
-func determineProfileType(finding rules.Finding) string {
+// DetermineProfileType 确定 Finding 对应的热点分析 profile 类型 (cpu/heap/goroutine/threadcreate/block)
+// 单一类型的规则直接使用 Finding.ProfileTypes；联合规则或未记录类型时根据标题和规则 ID 推断，
+// 无法推断时返回 cpu
+func DetermineProfileType(finding rules.Finding) string {
+	if len(finding.ProfileTypes) == 1 {
+		return finding.ProfileTypes[0]
+	}
+
 	title := strings.ToLower(finding.Title)
 	ruleID := strings.ToLower(finding.RuleID)
 
@@ -110,6 +173,15 @@ func determineProfileType(finding rules.Finding) string {
 		strings.Contains(title, "协程") {
 		return "goroutine"
 	}
+	if strings.Contains(title, "线程") || strings.Contains(title, "thread") || strings.Contains(ruleID, "thread") {
+		return "threadcreate"
+	}
+	// goroutine 阻塞已在上面归入 goroutine，这里只剩 block profile 相关的阻塞和锁竞争
+	if strings.Contains(title, "阻塞") || strings.Contains(title, "block") ||
+		strings.Contains(title, "contention") || strings.Contains(title, "锁竞争") ||
+		strings.Contains(ruleID, "block") || strings.Contains(ruleID, "contention") {
+		return "block"
+	}
 
 	// 默认返回 cpu
 	return "cpu"
@@ -132,6 +204,30 @@ func normalizeSeverity(severity string) string {
 	}
 }
 
+// ParseSeverity 解析用户指定的严重程度，中英文等价 (如 "高" 与 "high")，返回标准化的英文名
+// 与 normalizeSeverity 不同，无法识别的值返回错误而不是按 medium 处理
+func ParseSeverity(severity string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(severity)) {
+	case "critical", "严重", "high", "高", "medium", "中", "low", "低":
+		return normalizeSeverity(strings.TrimSpace(severity)), nil
+	}
+	return "", fmt.Errorf("invalid severity '%s', must be one of: low, medium, high, critical", severity)
+}
+
+// SeverityRank 返回严重程度的权重，low 为 1 到 critical 为 4；按 normalizeSeverity 标准化，无法识别的值按 medium 处理
+func SeverityRank(severity string) int {
+	switch normalizeSeverity(severity) {
+	case "critical":
+		return 4
+	case "high":
+		return 3
+	case "low":
+		return 1
+	default:
+		return 2
+	}
+}
+
 // GenerateExplanation 生成通俗易懂的问题解释
 func GenerateExplanation(finding rules.Finding, hotPaths []HotPath) string {
 	if len(hotPaths) == 0 {
@@ -150,15 +246,15 @@ func GenerateExplanation(finding rules.Finding, hotPaths []HotPath) string {
 		// 检查是否有业务代码
 		if topPath.RootCauseIndex >= 0 && topPath.RootCauseIndex < len(topPath.Chain.Frames) {
 			rootCause := topPath.Chain.Frames[topPath.RootCauseIndex]
-			sb.WriteString(fmt.Sprintf(" 主要问题出现在业务代码 %s 函数（%s）",
-				rootCause.ShortName, rootCause.Location()))
+			sb.WriteString(fmt.Sprintf(" 主要问题出现在%s %s 函数（%s）",
+				getCategoryDescription(rootCause.Category), rootCause.ShortName, rootCause.Location()))
 
 			// 分析业务代码调用了什么
 			if topPath.RootCauseIndex < len(topPath.Chain.Frames)-1 {
-				// 找到业务代码之后的第一个非业务代码帧
+				// 找到根因之后的第一个其他类别的帧
 				for i := topPath.RootCauseIndex + 1; i < len(topPath.Chain.Frames); i++ {
 					frame := topPath.Chain.Frames[i]
-					if frame.Category != CategoryBusiness {
+					if frame.Category != rootCause.Category {
 						sb.WriteString(fmt.Sprintf("，该函数调用了 %s (%s)",
 							getCategoryDescription(frame.Category), frame.ShortName))
 						break
@@ -173,7 +269,7 @@ func GenerateExplanation(finding rules.Finding, hotPaths []HotPath) string {
 			// 分析调用链的组成
 			breakdown := topPath.Chain.CategoryBreakdown
 			if breakdown[CategoryRuntime] > 0 && breakdown[CategoryRuntime] == len(topPath.Chain.Frames) {
-				sb.WriteString("全部是 Go 运行时代码，通常是 GC 或内存管理开销。")
+				sb.WriteString(describeRuntimeChain(topPath.Chain.Frames))
 			} else if breakdown[CategoryThirdParty] > 0 {
 				sb.WriteString("主要是第三方库调用，可能是业务代码通过第三方库间接触发的。")
 			} else if breakdown[CategoryStdlib] > 0 {
@@ -187,6 +283,27 @@ func GenerateExplanation(finding rules.Finding, hotPaths []HotPath) string {
 	return sb.String()
 }
 
+// describeRuntimeChain 描述全部由运行时帧组成的调用链，按识别到的 runtime 帧用途给出说明
+func describeRuntimeChain(frames []StackFrame) string {
+	kinds := make(map[analyzer.RuntimeFrameKind]string)
+	for _, frame := range frames {
+		kind := analyzer.ClassifyRuntimeFrame(frame.FunctionName)
+		if _, ok := kinds[kind]; !ok && kind != analyzer.RuntimeFrameNone {
+			kinds[kind] = frame.ShortName
+		}
+	}
+
+	switch {
+	case kinds[analyzer.RuntimeFrameGC] != "":
+		return fmt.Sprintf("全部是 Go 运行时代码，包含 GC 帧 (%s)，是垃圾回收开销。", kinds[analyzer.RuntimeFrameGC])
+	case kinds[analyzer.RuntimeFrameAlloc] != "":
+		return fmt.Sprintf("全部是 Go 运行时代码，包含内存分配帧 (%s)，是内存管理开销。", kinds[analyzer.RuntimeFrameAlloc])
+	case kinds[analyzer.RuntimeFrameScheduler] != "":
+		return fmt.Sprintf("全部是 Go 运行时代码，包含调度帧 (%s)，是调度或阻塞等待开销。", kinds[analyzer.RuntimeFrameScheduler])
+	}
+	return "全部是 Go 运行时代码，通常是 GC 或内存管理开销。"
+}
+
 // getCategoryDescription 获取代码类别的描述
 func getCategoryDescription(category CodeCategory) string {
 	switch category {
@@ -198,6 +315,8 @@ func getCategoryDescription(category CodeCategory) string {
 		return "第三方库"
 	case CategoryBusiness:
 		return "业务代码"
+	case CategoryGenerated:
+		return "生成代码"
 	default:
 		return "未知代码"
 	}
@@ -268,6 +387,7 @@ func generateGoroutineExplanation(finding rules.Finding) string {
 }
 
 // GenerateImpact 生成影响评估字符串
+// 热点路径的单位已知时在百分比后附带绝对值，如 "主要消耗点占用 45.0% (13.2s / 29.3s) 的 CPU 时间"
 func GenerateImpact(hotPaths []HotPath, profileType string) string {
 	if len(hotPaths) == 0 {
 		return "无法评估影响 - 没有找到热点路径"
@@ -277,32 +397,55 @@ func GenerateImpact(hotPaths []HotPath, profileType string) string {
 
 	// 计算总消耗
 	totalPct := 0.0
+	totalValue := int64(0)
 	for _, hp := range hotPaths {
 		totalPct += hp.Chain.TotalPct
+		totalValue += hp.Chain.TotalValue
 	}
 
 	// 主要消耗点
 	topPath := hotPaths[0]
 	topPct := topPath.Chain.TotalPct
+	unit := topPath.Unit
+
+	// 百分比后附带的绝对值，单位未知时为空
+	topValue, sumValue := "", ""
+	if value := unit.Format(topPath.Chain.TotalValue); value != "" {
+		topValue = " (" + value + ")"
+		if unit.Total > 0 {
+			topValue = " (" + value + " / " + unit.FormatTotal() + ")"
+		}
+		sumValue = " (" + unit.Format(totalValue) + ")"
+	}
 
 	switch profileType {
 	case "cpu":
-		sb.WriteString(fmt.Sprintf("主要消耗点占用 %.1f%% 的 CPU 时间", topPct))
+		sb.WriteString(fmt.Sprintf("主要消耗点占用 %.1f%%%s 的 CPU 时间", topPct, topValue))
 		if len(hotPaths) > 1 {
-			sb.WriteString(fmt.Sprintf("，前 %d 个热点路径共占用 %.1f%% 的 CPU 时间", len(hotPaths), totalPct))
+			sb.WriteString(fmt.Sprintf("，前 %d 个热点路径共占用 %.1f%%%s 的 CPU 时间", len(hotPaths), totalPct, sumValue))
 		}
 	case "heap":
-		sb.WriteString(fmt.Sprintf("主要消耗点占用 %.1f%% 的内存分配", topPct))
+		sb.WriteString(fmt.Sprintf("主要消耗点占用 %.1f%%%s 的内存分配", topPct, topValue))
 		if len(hotPaths) > 1 {
-			sb.WriteString(fmt.Sprintf("，前 %d 个热点路径共占用 %.1f%% 的内存", len(hotPaths), totalPct))
+			sb.WriteString(fmt.Sprintf("，前 %d 个热点路径共占用 %.1f%%%s 的内存", len(hotPaths), totalPct, sumValue))
 		}
 	case "goroutine":
-		sb.WriteString(fmt.Sprintf("主要消耗点占用 %.1f%% 的 goroutine", topPct))
+		sb.WriteString(fmt.Sprintf("主要消耗点占用 %.1f%%%s 的 goroutine", topPct, topValue))
 		if len(hotPaths) > 1 {
-			sb.WriteString(fmt.Sprintf("，前 %d 个热点路径共占用 %.1f%% 的 goroutine", len(hotPaths), totalPct))
+			sb.WriteString(fmt.Sprintf("，前 %d 个热点路径共占用 %.1f%%%s 的 goroutine", len(hotPaths), totalPct, sumValue))
+		}
+	case "threadcreate":
+		sb.WriteString(fmt.Sprintf("主要创建点占用 %.1f%%%s 的线程创建", topPct, topValue))
+		if len(hotPaths) > 1 {
+			sb.WriteString(fmt.Sprintf("，前 %d 个热点路径共占用 %.1f%%%s 的线程创建", len(hotPaths), totalPct, sumValue))
+		}
+	case "block":
+		sb.WriteString(fmt.Sprintf("主要阻塞点占用 %.1f%%%s 的阻塞时间", topPct, topValue))
+		if len(hotPaths) > 1 {
+			sb.WriteString(fmt.Sprintf("，前 %d 个热点路径共占用 %.1f%%%s 的阻塞时间", len(hotPaths), totalPct, sumValue))
 		}
 	default:
-		sb.WriteString(fmt.Sprintf("主要消耗点占用 %.1f%%", topPct))
+		sb.WriteString(fmt.Sprintf("主要消耗点占用 %.1f%%%s", topPct, topValue))
 	}
 
 	// 添加根因信息
@@ -392,6 +535,15 @@ func generateNoBusinessCodeSuggestions(profileType string) []Suggestion {
 			Category: "immediate",
 			Content:  "检查是否有未关闭的 channel 或无限等待的 select",
 		})
+	case "threadcreate":
+		suggestions = append(suggestions, Suggestion{
+			Category: "immediate",
+			Content:  "热点路径中没有业务代码，线程可能由阻塞的系统调用或 cgo 调用触发运行时创建",
+		})
+		suggestions = append(suggestions, Suggestion{
+			Category: "immediate",
+			Content:  "检查并发的 cgo 调用、阻塞文件 IO 和 runtime.LockOSThread 的使用",
+		})
 	case "cpu":
 		suggestions = append(suggestions, Suggestion{
 			Category: "immediate",
@@ -426,23 +578,52 @@ func generateLongTermSuggestions(profileType string) []Suggestion {
 			Category: "long_term",
 			Content:  "添加 goroutine 数量监控，确保所有 goroutine 都有退出机制",
 		})
+	case "threadcreate":
+		suggestions = append(suggestions, Suggestion{
+			Category: "long_term",
+			Content:  "限制 cgo 和阻塞系统调用的并发数，必要时用 debug.SetMaxThreads 设置线程数上限",
+		})
+	case "block":
+		suggestions = append(suggestions, Suggestion{
+			Category: "long_term",
+			Content:  "缩小锁的临界区，考虑分片锁、读写锁或带缓冲的 channel 减少阻塞",
+		})
 	}
 
 	return suggestions
 }
 
-// generateCommands 生成可执行命令列表
+// commandOptions 返回生成命令使用的选项，没有分析器时使用默认选项
+func (g *ContextGenerator) commandOptions() CommandOptions {
+	if g.analyzer == nil {
+		return CommandOptions{}
+	}
+	return g.analyzer.config.commandOptions()
+}
+
+// commandProfileType 返回生成命令使用的 profile 类型，heap 快照来自 /debug/pprof/allocs 时为 allocs
+func commandProfileType(profileType string, latest *profile.Profile) string {
+	if profileType == "heap" && analyzer.IsAllocsProfile(latest) {
+		return ProfileTypeAllocs
+	}
+	return profileType
+}
+
+// generateCommands 生成可执行命令列表和推荐优先执行的命令
 // 使用 CommandGenerator 生成命令
 // profilePaths: 实际的 profile 文件路径列表
-func generateCommands(profileType string, hotPaths []HotPath, profilePaths []string) []ExecutableCmd {
-	generator := NewCommandGenerator()
+func generateCommands(profileType string, hotPaths []HotPath, profilePaths []string, opts CommandOptions) ([]ExecutableCmd, *ExecutableCmd) {
+	generator := NewCommandGeneratorWithOptions(opts)
 
-	// 如果没有提供实际路径，使用默认路径
+	var commands []ExecutableCmd
 	if len(profilePaths) == 0 {
+		// 如果没有提供实际路径，使用默认路径
 		profilePath := fmt.Sprintf("./%s.pprof", profileType)
-		return generator.GenerateCommandsForProfileType(profilePath, profileType, hotPaths)
+		commands = generator.GenerateCommandsForProfileType(profilePath, profileType, hotPaths)
+	} else {
+		// 使用新的 GenerateCommandsWithContext 方法
+		commands = generator.GenerateCommandsWithContext(profilePaths, profileType, hotPaths)
 	}
 
-	// 使用新的 GenerateCommandsWithContext 方法
-	return generator.GenerateCommandsWithContext(profilePaths, profileType, hotPaths)
+	return commands, SelectPrimaryCommand(commands, profileType, hotPaths)
 }
@@ -1,6 +1,7 @@
 package locator
 
 import (
+	"context"
 	"strings"
 	"testing"
 	"testing/quick"
@@ -8,6 +9,7 @@ import (
 	"github.com/google/pprof/profile"
 	"github.com/songzhibin97/perfinspector/pkg/rules"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Feature: problem-locator, Property 6: Problem Context Completeness
@@ -20,7 +22,7 @@ func createTestFinding(title, severity string, suggestions []string) rules.Findi
 		RuleName:    "Test Rule",
 		Severity:    severity,
 		Title:       title,
-		Evidence:    map[string]string{"key": "value"},
+		Evidence:    rules.Evidence{{Name: "key", Display: "value"}},
 		Suggestions: suggestions,
 	}
 }
@@ -72,6 +74,77 @@ func TestNewContextGenerator(t *testing.T) {
 	assert.Equal(t, analyzer, generator.analyzer)
 }
 
+// TestNewContextGeneratorFromConfig tests constructing the full pipeline from config
+func TestNewContextGeneratorFromConfig(t *testing.T) {
+	config := LocatorConfig{
+		ModuleName:    "github.com/myapp",
+		ReadableNames: true,
+	}
+	generator := NewContextGeneratorFromConfig(config)
+	require.NotNil(t, generator)
+	require.NotNil(t, generator.analyzer)
+
+	finding := createTestFinding("CPU 热点问题", "high", nil)
+	p := createTestProfileWithSamples([]string{
+		"main.main",
+		"github.com/myapp/handler.ProcessRequest",
+		"runtime.mallocgc",
+	}, 1000)
+
+	ctx := generator.GenerateContext(finding, map[string]*profile.Profile{"cpu": p})
+	require.NotNil(t, ctx)
+	require.Len(t, ctx.HotPaths, 1)
+
+	frames := ctx.HotPaths[0].Chain.Frames
+	require.Len(t, frames, 3)
+	assert.Equal(t, "main.main", frames[0].FunctionName, "frames should be ordered entry-first")
+	assert.Equal(t, "runtime.mallocgc", frames[2].FunctionName)
+	assert.Equal(t, 1, ctx.HotPaths[0].RootCauseIndex)
+}
+
+// TestGenerateContext_HideRuntimeOnly tests that hidden runtime-only paths are summarized
+func TestGenerateContext_HideRuntimeOnly(t *testing.T) {
+	config := LocatorConfig{ModuleName: "github.com/myapp", HideRuntimeOnly: true}
+	generator := NewContextGeneratorFromConfig(config)
+
+	finding := createTestFinding("CPU 热点问题", "high", nil)
+	gcOnly := createTestProfileWithSamples([]string{"runtime.gcBgMarkWorker", "runtime.gcDrain"}, 1000)
+
+	ctx := generator.GenerateContextWithAllProfiles(finding,
+		map[string]*profile.Profile{"cpu": gcOnly},
+		map[string][]*profile.Profile{"cpu": {gcOnly}},
+		nil)
+	require.NotNil(t, ctx)
+	assert.Empty(t, ctx.HotPaths)
+	assert.Equal(t, 1, ctx.HiddenHotPaths.Count)
+	assert.InDelta(t, 100.0, ctx.HiddenHotPaths.Pct, 0.001)
+}
+
+// TestGenerateContextCtx 测试上下文生成在 ctx 取消时返回错误
+func TestGenerateContextCtx(t *testing.T) {
+	generator := NewContextGeneratorFromConfig(LocatorConfig{ModuleName: "github.com/myapp"})
+	finding := createTestFinding("CPU 热点问题", "high", nil)
+	p := createTestProfileWithSamples([]string{"github.com/myapp/handler.ProcessRequest", "runtime.mallocgc"}, 1000)
+	profiles := map[string]*profile.Profile{"cpu": p}
+	allProfiles := map[string][]*profile.Profile{"cpu": {p}}
+
+	problem, err := generator.GenerateContextCtx(context.Background(), finding, profiles, allProfiles, nil)
+	require.NoError(t, err)
+	require.NotNil(t, problem)
+	assert.NotEmpty(t, problem.HotPaths)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	problem, err = generator.GenerateContextCtx(canceled, finding, profiles, allProfiles, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, problem)
+
+	// 没有 PathAnalyzer 时与 GenerateContextWithAllProfiles 一致返回 nil
+	problem, err = (&ContextGenerator{}).GenerateContextCtx(canceled, finding, profiles, allProfiles, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, problem)
+}
+
 // TestGenerateContext_Basic tests basic context generation
 func TestGenerateContext_Basic(t *testing.T) {
 	config := LocatorConfig{
@@ -172,16 +245,54 @@ func TestDetermineProfileType(t *testing.T) {
 			finding:  createTestFinding("协程数量增长", "high", nil),
 			expected: "goroutine",
 		},
+		{
+			name:     "线程 in title",
+			finding:  createTestFinding("线程数量暴涨", "high", nil),
+			expected: "threadcreate",
+		},
+		{
+			name:     "block in title",
+			finding:  createTestFinding("锁竞争阻塞", "high", nil),
+			expected: "block",
+		},
+		{
+			name:     "goroutine block stays goroutine",
+			finding:  createTestFinding("Goroutine 阻塞", "high", nil),
+			expected: "goroutine",
+		},
+		{
+			name:     "block in rule id",
+			finding:  rules.Finding{RuleID: "mutex_contention", Title: "热点"},
+			expected: "block",
+		},
 		{
 			name:     "default to cpu",
 			finding:  createTestFinding("性能问题", "high", nil),
 			expected: "cpu",
 		},
+		{
+			name: "single profile type wins over title",
+			finding: rules.Finding{
+				RuleID:       "custom-rule",
+				Title:        "CPU 相关的内存问题",
+				ProfileTypes: []string{"heap"},
+			},
+			expected: "heap",
+		},
+		{
+			name: "cross rule falls back to title",
+			finding: rules.Finding{
+				RuleID:       "cross-rule",
+				Title:        "Goroutine 泄漏",
+				ProfileTypes: []string{"goroutine", "heap"},
+			},
+			expected: "goroutine",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := determineProfileType(tt.finding)
+			result := DetermineProfileType(tt.finding)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
@@ -217,6 +328,29 @@ func TestNormalizeSeverity(t *testing.T) {
 	}
 }
 
+// TestParseSeverity 中英文严重程度等价，无法识别的值报错
+func TestParseSeverity(t *testing.T) {
+	for input, expected := range map[string]string{"高": "high", "HIGH": "high", " low ": "low", "严重": "critical", "中": "medium"} {
+		severity, err := ParseSeverity(input)
+		require.NoError(t, err)
+		assert.Equal(t, expected, severity)
+	}
+	_, err := ParseSeverity("urgent")
+	assert.ErrorContains(t, err, "must be one of: low, medium, high, critical")
+	_, err = ParseSeverity("")
+	assert.Error(t, err)
+}
+
+func TestSeverityRank(t *testing.T) {
+	assert.Equal(t, 4, SeverityRank("严重"))
+	assert.Equal(t, 3, SeverityRank("high"))
+	assert.Equal(t, 3, SeverityRank("高"))
+	assert.Equal(t, 2, SeverityRank("medium"))
+	assert.Equal(t, 1, SeverityRank("LOW"))
+	// 无法识别的严重程度与报告一致按 medium 处理
+	assert.Equal(t, 2, SeverityRank("unknown"))
+}
+
 // TestGenerateExplanation tests explanation generation
 func TestGenerateExplanation(t *testing.T) {
 	t.Run("with hot paths and root cause", func(t *testing.T) {
@@ -383,7 +517,7 @@ func TestGenerateSuggestions(t *testing.T) {
 // TestGenerateCommands tests command generation
 func TestGenerateCommands(t *testing.T) {
 	t.Run("basic commands", func(t *testing.T) {
-		commands := generateCommands("cpu", nil, nil)
+		commands, _ := generateCommands("cpu", nil, nil, CommandOptions{})
 
 		assert.True(t, len(commands) >= 2) // top and web commands
 	})
@@ -400,7 +534,7 @@ func TestGenerateCommands(t *testing.T) {
 			},
 		}
 
-		commands := generateCommands("cpu", hotPaths, nil)
+		commands, _ := generateCommands("cpu", hotPaths, nil, CommandOptions{})
 
 		assert.True(t, len(commands) >= 3) // top, focus, list, web commands
 	})
@@ -418,7 +552,7 @@ func TestGenerateCommands(t *testing.T) {
 		}
 		profilePaths := []string{"./testdata/heap1.pprof", "./testdata/heap2.pprof"}
 
-		commands := generateCommands("heap", hotPaths, profilePaths)
+		commands, _ := generateCommands("heap", hotPaths, profilePaths, CommandOptions{})
 
 		// Should have commands with actual paths
 		hasActualPath := false
@@ -667,3 +801,37 @@ func TestProblemContextCompleteness_Property_EmptyProfile(t *testing.T) {
 		t.Errorf("Property test failed: %v", err)
 	}
 }
+
+// TestDescribeRuntimeChain tests runtime-only chain descriptions use the runtime frame matcher
+func TestDescribeRuntimeChain(t *testing.T) {
+	gc := []StackFrame{
+		{FunctionName: "runtime.gcBgMarkWorker", ShortName: "gcBgMarkWorker"},
+		{FunctionName: "runtime.gcDrainMarkWorkerIdle", ShortName: "gcDrainMarkWorkerIdle"},
+		{FunctionName: "runtime.scanobject", ShortName: "scanobject"},
+	}
+	assert.Contains(t, describeRuntimeChain(gc), "GC 帧 (gcBgMarkWorker)")
+
+	alloc := []StackFrame{{FunctionName: "runtime.mallocgcSmallNoscan", ShortName: "mallocgcSmallNoscan"}}
+	assert.Contains(t, describeRuntimeChain(alloc), "内存分配帧 (mallocgcSmallNoscan)")
+
+	sched := []StackFrame{{FunctionName: "runtime.findRunnable", ShortName: "findRunnable"}}
+	assert.Contains(t, describeRuntimeChain(sched), "调度帧 (findRunnable)")
+
+	other := []StackFrame{{FunctionName: "runtime.memmove", ShortName: "memmove"}}
+	assert.Equal(t, "全部是 Go 运行时代码，通常是 GC 或内存管理开销。", describeRuntimeChain(other))
+}
+
+func TestCommandProfileType(t *testing.T) {
+	heapTypes := []*profile.ValueType{
+		{Type: "alloc_objects", Unit: "count"},
+		{Type: "alloc_space", Unit: "bytes"},
+		{Type: "inuse_objects", Unit: "count"},
+		{Type: "inuse_space", Unit: "bytes"},
+	}
+	allocs := &profile.Profile{SampleType: heapTypes, DefaultSampleType: "alloc_space"}
+
+	assert.Equal(t, ProfileTypeAllocs, commandProfileType("heap", allocs))
+	assert.Equal(t, "heap", commandProfileType("heap", &profile.Profile{SampleType: heapTypes}))
+	assert.Equal(t, "heap", commandProfileType("heap", nil))
+	assert.Equal(t, "cpu", commandProfileType("cpu", allocs))
+}
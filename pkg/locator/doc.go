@@ -0,0 +1,19 @@
+// Package locator 将规则引擎的发现定位到具体代码
+//
+// 定位流程由四个组件组成，依次组装：
+//
+//	classifier := locator.NewClassifier(config)                     // 按包路径分类 (业务/第三方/标准库/运行时)
+//	extractor := locator.NewExtractorWithConfig(classifier, config) // 将 pprof 样本转换为 CallChain
+//	pathAnalyzer := locator.NewPathAnalyzer(extractor, config)      // 合并调用链并选出热点路径
+//	generator := locator.NewContextGenerator(pathAnalyzer)          // 为 Finding 生成 ProblemContext
+//
+// 只需要 ProblemContext 时可以直接使用 NewContextGeneratorFromConfig。
+//
+// 约定：
+//   - CallChain.Frames 始终按从入口到叶子排列，所有帧索引都指向该切片
+//   - 百分比字段的取值范围为 0-100
+//   - 组件创建后不再修改内部状态，可以在多个 goroutine 中并发使用
+//
+// FindBusinessFrames、FindBoundaryPoints、SelectRootCause 等函数只依赖栈帧切片，
+// 可以对自行构造的调用链复用相同的分析规则。
+package locator
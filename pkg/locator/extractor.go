@@ -1,6 +1,7 @@
 package locator
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/google/pprof/profile"
@@ -8,7 +9,9 @@ import (
 
 // Extractor 调用栈提取器
 type Extractor struct {
-	classifier *Classifier
+	classifier        *Classifier
+	readableNames     bool // ShortName 是否使用 FormatDisplayName 格式化
+	collapseRecursion bool // 是否折叠连续重复的递归帧
 }
 
 // NewExtractor 创建提取器
@@ -18,6 +21,15 @@ func NewExtractor(classifier *Classifier) *Extractor {
 	}
 }
 
+// NewExtractorWithConfig 根据定位器配置创建提取器
+func NewExtractorWithConfig(classifier *Classifier, config LocatorConfig) *Extractor {
+	return &Extractor{
+		classifier:        classifier,
+		readableNames:     config.ReadableNames,
+		collapseRecursion: config.CollapseRecursion,
+	}
+}
+
 // ExtractPackageName 从函数全名提取包名
 // 例如: "github.com/user/repo/pkg.(*Type).Method" -> "github.com/user/repo/pkg"
 // 例如: "runtime.mallocgc" -> "runtime"
@@ -75,14 +87,16 @@ func ExtractShortName(functionName string) string {
 
 // ExtractStackFrame 从 pprof Location/Line 提取栈帧
 // 如果 line 为 nil，则使用 location 的第一个 line（如果有）
+// 缺少函数名时返回 CategoryMissingSymbol 的栈帧，函数名使用 UnsymbolizedName
 func (e *Extractor) ExtractStackFrame(loc *profile.Location, line *profile.Line) StackFrame {
+	name := UnsymbolizedName(loc)
 	frame := StackFrame{
-		FunctionName: "unknown",
-		ShortName:    "unknown",
+		FunctionName: name,
+		ShortName:    name,
 		PackageName:  "",
 		FilePath:     "unknown",
 		LineNumber:   0,
-		Category:     CategoryUnknown,
+		Category:     CategoryMissingSymbol,
 		Flat:         0,
 		FlatPct:      0,
 		Cum:          0,
@@ -99,13 +113,19 @@ func (e *Extractor) ExtractStackFrame(loc *profile.Location, line *profile.Line)
 	}
 
 	fn := line.Function
+	if fn.Name == "" {
+		return frame
+	}
 
 	// 提取函数名
-	if fn.Name != "" {
-		frame.FunctionName = fn.Name
+	frame.FunctionName = fn.Name
+	if e.readableNames {
+		frame.ShortName = FormatDisplayName(fn.Name)
+	} else {
 		frame.ShortName = ExtractShortName(fn.Name)
-		frame.PackageName = ExtractPackageName(fn.Name)
 	}
+	frame.ClosureLabel = ClosureLabel(fn.Name)
+	frame.PackageName = ExtractPackageName(fn.Name)
 
 	// 提取文件路径
 	if fn.Filename != "" {
@@ -118,16 +138,40 @@ func (e *Extractor) ExtractStackFrame(loc *profile.Location, line *profile.Line)
 	}
 
 	// 分类
+	frame.Category = CategoryUnknown
 	if e.classifier != nil {
 		frame.Category = e.classifier.Classify(frame.PackageName)
 	}
+	// 模块内的生成代码单独归类，避免 pb/mock 帧被当作业务根因
+	if frame.Category == CategoryBusiness && IsGeneratedFile(frame.FilePath) {
+		frame.Category = CategoryGenerated
+	}
 
 	return frame
 }
 
+// IsGeneratedFile 按文件名判断是否是生成代码：protobuf (*.pb.go)、代码生成器 (*_gen.go) 和 mock (mock_*.go)
+// profile 可能来自 Windows，路径分隔符兼容 / 和 \
+func IsGeneratedFile(filePath string) bool {
+	name := filePath[strings.LastIndexAny(filePath, `/\`)+1:]
+	return strings.HasSuffix(name, ".pb.go") ||
+		strings.HasSuffix(name, "_gen.go") ||
+		(strings.HasPrefix(name, "mock_") && strings.HasSuffix(name, ".go"))
+}
+
+// UnsymbolizedName 返回缺少符号的栈帧的展示名，如 "<unknown 0x4a3f20>"，地址未知时为 "<unknown>"
+func UnsymbolizedName(loc *profile.Location) string {
+	if loc == nil || loc.Address == 0 {
+		return "<unknown>"
+	}
+	return fmt.Sprintf("<unknown 0x%x>", loc.Address)
+}
+
 // ExtractCallChain 从 Sample 提取完整调用链
-// valueIndex 指定使用哪个 value（通常 0 是 flat，1 是 cum，取决于 profile 类型）
-// totalValue 是所有样本的总值，用于计算百分比
+// 返回的 Frames 按从入口到叶子排列 (与 pprof Location 的顺序相反)，内联函数按从外到内展开；
+// valueIndex 指定使用 sample.Value 的哪个值，可用 SelectValueIndex 选择，越界时 TotalValue 为 0；
+// totalValue 是所有样本的总值，用于计算 TotalPct (0-100)，不大于 0 时 TotalPct 为 0。
+// sample 为 nil 时返回没有栈帧的调用链
 func (e *Extractor) ExtractCallChain(sample *profile.Sample, valueIndex int, totalValue int64) CallChain {
 	chain := CallChain{
 		Frames:            make([]StackFrame, 0),
@@ -166,9 +210,13 @@ func (e *Extractor) ExtractCallChain(sample *profile.Sample, valueIndex int, tot
 		}
 
 		// 一个 Location 可能有多个 Line（内联函数）
-		// 按照从外到内的顺序处理
-		for j := len(loc.Line) - 1; j >= 0; j-- {
-			line := &loc.Line[j]
+		// 按照从外到内的顺序处理；没有 Line 的 Location (缺少符号) 作为一个缺少符号的帧保留，避免调用链断开
+		lines := loc.Line
+		if len(lines) == 0 {
+			lines = []profile.Line{{}}
+		}
+		for j := len(lines) - 1; j >= 0; j-- {
+			line := &lines[j]
 			frame := e.ExtractStackFrame(loc, line)
 
 			// 更新类别统计
@@ -185,6 +233,17 @@ func (e *Extractor) ExtractCallChain(sample *profile.Sample, valueIndex int, tot
 		}
 	}
 
+	if e.collapseRecursion {
+		if collapsed := CollapseRecursion(chain.Frames, DefaultRecursionMaxCycle); len(collapsed) < len(chain.Frames) {
+			chain.Frames = collapsed
+			chain.BoundaryPoints = FindBoundaryPoints(collapsed)
+			if chain.BoundaryPoints == nil {
+				chain.BoundaryPoints = make([]int, 0)
+			}
+			chain.CategoryBreakdown = CalculateCategoryBreakdown(collapsed)
+		}
+	}
+
 	return chain
 }
 
@@ -205,6 +264,8 @@ func (e *Extractor) ExtractCallChainWithCumValue(sample *profile.Sample, totalVa
 
 // ExtractCallChainWithValues 从 Sample 提取完整调用链，并设置每帧的 flat/cum 值
 // 这个方法用于需要显示每帧消耗值的场景
+//
+// Deprecated: flatValues 和 cumValues 目前未被使用，结果与 ExtractCallChain 相同，请直接使用 ExtractCallChain
 func (e *Extractor) ExtractCallChainWithValues(sample *profile.Sample, valueIndex int, totalValue int64, flatValues, cumValues map[uint64]int64) CallChain {
 	chain := e.ExtractCallChain(sample, valueIndex, totalValue)
 
@@ -6,6 +6,7 @@ import (
 
 	"github.com/google/pprof/profile"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Feature: problem-locator, Property 1: Stack Frame Extraction Completeness
@@ -127,11 +128,50 @@ func TestExtractStackFrame_Complete(t *testing.T) {
 
 	// Property: Category is correctly classified
 	assert.Equal(t, CategoryBusiness, frame.Category)
+	assert.Empty(t, frame.ClosureLabel)
+}
+
+// TestExtractStackFrame_Closure 匿名函数帧的 ShortName 保留原始后缀，ClosureLabel 带外层函数
+func TestExtractStackFrame_Closure(t *testing.T) {
+	extractor := NewExtractor(NewClassifier(LocatorConfig{ModuleName: "github.com/myapp"}))
+	fn := &profile.Function{ID: 1, Name: "github.com/myapp/worker.init.0.func1.1", Filename: "/src/myapp/worker/pool.go"}
+	loc := &profile.Location{ID: 1, Line: []profile.Line{{Function: fn, Line: 12}}}
+
+	frame := extractor.ExtractStackFrame(loc, nil)
+	assert.Equal(t, "init.0.func1.1", frame.ShortName)
+	assert.Equal(t, "init#0()内的闭包#1.1", frame.ClosureLabel)
 }
 
 // TestExtractStackFrame_MissingInfo tests fallback behavior for missing info
 // **Property 1: Stack Frame Extraction Completeness**
 // **Validates: Requirements 1.4**
+// TestExtractStackFrame_GeneratedCode 模块内生成代码文件中的帧归为生成代码，第三方的生成代码不受影响
+func TestExtractStackFrame_GeneratedCode(t *testing.T) {
+	extractor := NewExtractor(NewClassifier(LocatorConfig{ModuleName: "github.com/myapp"}))
+	frameOf := func(name, file string) StackFrame {
+		fn := &profile.Function{ID: 1, Name: name, Filename: file}
+		loc := &profile.Location{ID: 1, Line: []profile.Line{{Function: fn, Line: 10}}}
+		return extractor.ExtractStackFrame(loc, nil)
+	}
+
+	assert.Equal(t, CategoryGenerated, frameOf("github.com/myapp/api.(*UserServiceClient).Get", "/src/myapp/api/user_service.pb.go").Category)
+	assert.Equal(t, CategoryBusiness, frameOf("github.com/myapp/service.(*UserService).Get", "/src/myapp/service/user_service.go").Category)
+	assert.Equal(t, CategoryThirdParty, frameOf("google.golang.org/grpc.(*Server).handleStream", "/go/pkg/mod/google.golang.org/grpc/server_gen.go").Category)
+}
+
+// TestIsGeneratedFile 测试生成代码文件名的识别
+func TestIsGeneratedFile(t *testing.T) {
+	assert.True(t, IsGeneratedFile("/src/myapp/api/user_service.pb.go"))
+	assert.True(t, IsGeneratedFile("/src/myapp/api/user_service_grpc.pb.go"))
+	assert.True(t, IsGeneratedFile("/src/myapp/store/queries_gen.go"))
+	assert.True(t, IsGeneratedFile("/src/myapp/store/mock_store.go"))
+	assert.True(t, IsGeneratedFile(`C:\src\myapp\api\user_service.pb.go`))
+	assert.False(t, IsGeneratedFile("/src/myapp/service/user_service.go"))
+	assert.False(t, IsGeneratedFile("/src/myapp/mock_store/store.go"))
+	assert.False(t, IsGeneratedFile("/src/myapp/codegen.go"))
+	assert.False(t, IsGeneratedFile("unknown"))
+}
+
 func TestExtractStackFrame_MissingInfo(t *testing.T) {
 	config := LocatorConfig{
 		ModuleName: "github.com/myapp",
@@ -143,10 +183,12 @@ func TestExtractStackFrame_MissingInfo(t *testing.T) {
 		loc := &profile.Location{ID: 1}
 		frame := extractor.ExtractStackFrame(loc, nil)
 
-		// Should return unknown values
-		assert.Equal(t, "unknown", frame.FunctionName)
+		// Should return a missing symbol frame
+		assert.Equal(t, "<unknown>", frame.FunctionName)
 		assert.Equal(t, "unknown", frame.FilePath)
 		assert.Equal(t, int64(0), frame.LineNumber)
+		assert.Equal(t, CategoryMissingSymbol, frame.Category)
+		assert.True(t, frame.Unsymbolized())
 	})
 
 	t.Run("nil function", func(t *testing.T) {
@@ -160,9 +202,29 @@ func TestExtractStackFrame_MissingInfo(t *testing.T) {
 		}
 		frame := extractor.ExtractStackFrame(loc, line)
 
-		// Should return unknown values
-		assert.Equal(t, "unknown", frame.FunctionName)
+		// Should return a missing symbol frame
+		assert.Equal(t, "<unknown>", frame.FunctionName)
 		assert.Equal(t, "unknown", frame.FilePath)
+		assert.Equal(t, CategoryMissingSymbol, frame.Category)
+	})
+
+	t.Run("address without symbol", func(t *testing.T) {
+		loc := &profile.Location{ID: 1, Address: 0x4a3f20}
+		frame := extractor.ExtractStackFrame(loc, nil)
+
+		assert.Equal(t, "<unknown 0x4a3f20>", frame.FunctionName)
+		assert.Equal(t, "<unknown 0x4a3f20>", frame.ShortName)
+		assert.Equal(t, "", frame.PackageName)
+		assert.Equal(t, CategoryMissingSymbol, frame.Category)
+	})
+
+	t.Run("empty function name", func(t *testing.T) {
+		line := profile.Line{Function: &profile.Function{ID: 1, Filename: "/app/main.go"}, Line: 5}
+		loc := &profile.Location{ID: 1, Address: 0x1000, Line: []profile.Line{line}}
+		frame := extractor.ExtractStackFrame(loc, &loc.Line[0])
+
+		assert.Equal(t, "<unknown 0x1000>", frame.FunctionName)
+		assert.True(t, frame.Unsymbolized())
 	})
 
 	t.Run("empty filename", func(t *testing.T) {
@@ -269,6 +331,34 @@ func TestExtractCallChain(t *testing.T) {
 	assert.Contains(t, chain.BoundaryPoints, 2) // stdlib -> runtime
 }
 
+// TestExtractCallChain_UnsymbolizedFrames 缺少符号的 Location 保留为缺少符号的帧，调用链不会断开
+func TestExtractCallChain_UnsymbolizedFrames(t *testing.T) {
+	extractor := NewExtractor(NewClassifier(LocatorConfig{ModuleName: "github.com/myapp"}))
+
+	fnLeaf := &profile.Function{ID: 1, Name: "runtime.mallocgc", Filename: "runtime/malloc.go"}
+	fnRoot := &profile.Function{ID: 2, Name: "github.com/myapp/handler.ProcessRequest", Filename: "handler/request.go"}
+	sample := &profile.Sample{
+		Location: []*profile.Location{
+			{ID: 1, Line: []profile.Line{{Function: fnLeaf, Line: 100}}},
+			{ID: 2, Address: 0x4a3f20},
+			{ID: 3, Address: 0x4a4000, Line: []profile.Line{{Function: &profile.Function{ID: 3}}}},
+			{ID: 4, Line: []profile.Line{{Function: fnRoot, Line: 42}}},
+		},
+		Value: []int64{1000},
+	}
+
+	chain := extractor.ExtractCallChain(sample, 0, 10000)
+
+	require.Len(t, chain.Frames, 4)
+	assert.Equal(t, "<unknown 0x4a4000>", chain.Frames[1].FunctionName)
+	assert.Equal(t, "<unknown 0x4a3f20>", chain.Frames[2].FunctionName)
+	assert.Equal(t, CategoryMissingSymbol, chain.Frames[2].Category)
+	assert.Equal(t, 2, chain.UnsymbolizedFrames())
+	assert.Equal(t, 2, chain.CategoryBreakdown[CategoryMissingSymbol])
+	assert.Equal(t, []int{1, 3}, chain.BoundaryPoints)
+	assert.Equal(t, "1 业务 → 2 缺少符号 → 1 运行时", chain.Summary())
+}
+
 // TestExtractCallChain_EmptySample tests call chain extraction with empty sample
 // **Property 1: Stack Frame Extraction Completeness**
 // **Validates: Requirements 1.4**
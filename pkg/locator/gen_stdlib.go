@@ -0,0 +1,46 @@
+//go:build ignore
+
+// gen_stdlib 根据当前 Go 工具链生成标准库包列表 (stdlib_list.go)
+//
+// 用法: go generate ./pkg/locator
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+func main() {
+	out, err := exec.Command("go", "list", "std").Output()
+	if err != nil {
+		log.Fatalf("go list std: %v", err)
+	}
+
+	pkgs := strings.Fields(string(out))
+	sort.Strings(pkgs)
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gen_stdlib.go; DO NOT EDIT.\n\n")
+	buf.WriteString("package locator\n\n")
+	fmt.Fprintf(&buf, "// goStdlibPackages Go 标准库包列表 (%s go list std)\n", runtime.Version())
+	buf.WriteString("var goStdlibPackages = []string{\n")
+	for _, pkg := range pkgs {
+		fmt.Fprintf(&buf, "\t%q,\n", pkg)
+	}
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("format: %v", err)
+	}
+	if err := os.WriteFile("stdlib_list.go", src, 0o644); err != nil {
+		log.Fatalf("write: %v", err)
+	}
+}
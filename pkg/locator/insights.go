@@ -0,0 +1,239 @@
+package locator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/songzhibin97/perfinspector/pkg/rules"
+)
+
+// RootCauseRuleIDPrefix 共同根因发现的 RuleID 前缀，后接根因所在的包名
+const RootCauseRuleIDPrefix = "shared_root_cause:"
+
+// DefaultRootCauseMinPct 根因位于同一个包的热点路径在每种 profile 中至少占 5% 才视为共同根因
+const DefaultRootCauseMinPct = 5.0
+
+// RootCauseInsight 不同 profile 类型的发现指向同一个业务包
+// 例如 CPU 热点和内存分配热点的根因都在序列化包中，很可能是同一个问题
+type RootCauseInsight struct {
+	Package      string             // 根因所在的业务包
+	ProfileTypes []string           // 涉及的 profile 类型，按名称排序
+	RuleIDs      []string           // 指向该包的原始发现，按 findings 顺序
+	Pct          map[string]float64 // 每种 profile 类型中根因位于该包的热点路径占比之和 (0-100)
+	Severity     string             // 原始发现中最高的严重程度
+	HotPaths     []HotPath          // 根因位于该包的热点路径，按 ProfileTypes 顺序排列
+}
+
+// rootCauseSource 单个发现中根因位于某个包的热点路径
+type rootCauseSource struct {
+	finding     rules.Finding
+	profileType string
+	paths       []HotPath
+	pct         float64
+}
+
+// CorrelateRootCauses 在问题上下文生成之后，找出不同 profile 类型的发现共享的根因业务包
+// 只考虑单类型发现；同一类型的多个发现取占比最大的一个，每种类型的占比需达到 minPct
+// (<= 0 时使用 DefaultRootCauseMinPct)。结果按涉及的类型数降序、包名升序排列
+func CorrelateRootCauses(findings []rules.Finding, contexts map[string]*ProblemContext, minPct float64) []RootCauseInsight {
+	if minPct <= 0 {
+		minPct = DefaultRootCauseMinPct
+	}
+
+	// 包名 -> profile 类型 -> 占比最大的来源
+	byPackage := make(map[string]map[string]rootCauseSource)
+	// 包名 -> 指向该包的全部来源，按 findings 顺序
+	related := make(map[string][]rootCauseSource)
+	for _, finding := range findings {
+		ctx := contexts[finding.RuleID]
+		if finding.IsCrossAnalysis || ctx == nil {
+			continue
+		}
+		profileType := DetermineProfileType(finding)
+		for pkg, source := range rootCausePackages(ctx.HotPaths) {
+			source.finding, source.profileType = finding, profileType
+			related[pkg] = append(related[pkg], source)
+			if byPackage[pkg] == nil {
+				byPackage[pkg] = make(map[string]rootCauseSource)
+			}
+			if current, ok := byPackage[pkg][profileType]; !ok || source.pct > current.pct {
+				byPackage[pkg][profileType] = source
+			}
+		}
+	}
+
+	var insights []RootCauseInsight
+	for pkg, sources := range byPackage {
+		insight := RootCauseInsight{Package: pkg, Pct: make(map[string]float64)}
+		for profileType, source := range sources {
+			if source.pct >= minPct {
+				insight.ProfileTypes = append(insight.ProfileTypes, profileType)
+			}
+		}
+		if len(insight.ProfileTypes) < 2 {
+			continue
+		}
+		sort.Strings(insight.ProfileTypes)
+
+		for _, profileType := range insight.ProfileTypes {
+			source := sources[profileType]
+			insight.Pct[profileType] = source.pct
+			insight.HotPaths = append(insight.HotPaths, source.paths...)
+		}
+		for _, source := range related[pkg] {
+			if !containsString(insight.ProfileTypes, source.profileType) || containsString(insight.RuleIDs, source.finding.RuleID) {
+				continue
+			}
+			insight.RuleIDs = append(insight.RuleIDs, source.finding.RuleID)
+			if insight.Severity == "" || severityOrder(source.finding.Severity) > severityOrder(insight.Severity) {
+				insight.Severity = normalizeSeverity(source.finding.Severity)
+			}
+		}
+		insights = append(insights, insight)
+	}
+
+	sort.Slice(insights, func(i, j int) bool {
+		if len(insights[i].ProfileTypes) != len(insights[j].ProfileTypes) {
+			return len(insights[i].ProfileTypes) > len(insights[j].ProfileTypes)
+		}
+		return insights[i].Package < insights[j].Package
+	})
+	return insights
+}
+
+// rootCausePackages 按根因帧所在的包汇总热点路径，没有根因帧或包名未知的路径被忽略
+func rootCausePackages(hotPaths []HotPath) map[string]rootCauseSource {
+	result := make(map[string]rootCauseSource)
+	for _, hp := range hotPaths {
+		root := hp.GetRootCause()
+		if root == nil || root.PackageName == "" {
+			continue
+		}
+		source := result[root.PackageName]
+		source.paths = append(source.paths, hp)
+		source.pct += hp.Chain.TotalPct
+		result[root.PackageName] = source
+	}
+	return result
+}
+
+// RuleID 返回共同根因发现的 RuleID
+func (i RootCauseInsight) RuleID() string {
+	return RootCauseRuleIDPrefix + i.Package
+}
+
+// Finding 将共同根因转换为联合分析发现，与规则引擎产生的发现一起渲染
+func (i RootCauseInsight) Finding() rules.Finding {
+	labels := i.labels()
+	evidence := rules.Evidence{{Name: "根因包", Display: i.Package}}
+	for idx, profileType := range i.ProfileTypes {
+		pct := i.Pct[profileType]
+		evidence = append(evidence, rules.EvidenceItem{
+			Name:     labels[idx] + " 占比",
+			Display:  fmt.Sprintf("%.1f%%", pct),
+			Value:    pct / 100,
+			HasValue: true,
+			Unit:     "ratio",
+		})
+	}
+	evidence = append(evidence, rules.EvidenceItem{Name: "相关发现", Display: strings.Join(i.RuleIDs, ", ")})
+
+	return rules.Finding{
+		RuleID:   i.RuleID(),
+		RuleName: "共同根因",
+		Severity: i.Severity,
+		Title:    fmt.Sprintf("🧩 共同根因: %s (%s)", i.Package, strings.Join(labels, " + ")),
+		Evidence: evidence,
+		Suggestions: []string{
+			fmt.Sprintf("优先优化 %s：同一处代码同时消耗 %s，一次修改可以同时改善这些指标", i.Package, strings.Join(labels, "和")),
+			"对照各类型热点路径中的根因函数，确认它们是否来自同一段逻辑 (如序列化、日志格式化)",
+			"使用 -explain-func 查看根因函数在所有 profile 中的消耗",
+		},
+		IsCrossAnalysis: true,
+		ProfileTypes:    i.ProfileTypes,
+	}
+}
+
+// Context 生成共同根因发现的问题上下文，热点路径和命令来自原始发现
+func (i RootCauseInsight) Context(contexts map[string]*ProblemContext) *ProblemContext {
+	finding := i.Finding()
+	parts := make([]string, 0, len(i.ProfileTypes))
+	for idx, profileType := range i.ProfileTypes {
+		parts = append(parts, fmt.Sprintf("%s 热点路径的 %.1f%%", i.labels()[idx], i.Pct[profileType]))
+	}
+
+	problem := &ProblemContext{
+		Title:    finding.Title,
+		Severity: normalizeSeverity(i.Severity),
+		Explanation: fmt.Sprintf("%s 同时是多种 profile 中热点的根因 (%s)。这些发现 (%s) 很可能是同一个问题，应作为一个整体处理。",
+			i.Package, strings.Join(parts, "，"), strings.Join(i.RuleIDs, ", ")),
+		Impact:      fmt.Sprintf("涉及 %d 种资源: %s", len(i.ProfileTypes), strings.Join(i.labels(), "、")),
+		HotPaths:    i.HotPaths,
+		Suggestions: GenerateSuggestions(finding, i.HotPaths),
+	}
+
+	seen := make(map[string]bool)
+	for _, id := range i.RuleIDs {
+		ctx := contexts[id]
+		if ctx == nil {
+			continue
+		}
+		for _, cmd := range ctx.Commands {
+			if !seen[cmd.Command] {
+				seen[cmd.Command] = true
+				problem.Commands = append(problem.Commands, cmd)
+			}
+		}
+	}
+	return problem
+}
+
+// labels 返回 ProfileTypes 对应的资源名称
+func (i RootCauseInsight) labels() []string {
+	labels := make([]string, 0, len(i.ProfileTypes))
+	for _, profileType := range i.ProfileTypes {
+		labels = append(labels, profileTypeLabel(profileType))
+	}
+	return labels
+}
+
+// profileTypeLabel 返回 profile 类型对应的资源名称
+func profileTypeLabel(profileType string) string {
+	switch profileType {
+	case "cpu":
+		return "CPU"
+	case "heap":
+		return "内存"
+	case "mutex":
+		return "锁竞争"
+	case "block":
+		return "阻塞"
+	default:
+		return profileType
+	}
+}
+
+// severityOrder 返回严重程度的排序权重，越严重越大
+func severityOrder(severity string) int {
+	switch normalizeSeverity(severity) {
+	case "critical":
+		return 4
+	case "high":
+		return 3
+	case "medium":
+		return 2
+	default:
+		return 1
+	}
+}
+
+// containsString 判断切片中是否包含指定字符串
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
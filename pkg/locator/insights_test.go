@@ -0,0 +1,138 @@
+package locator
+
+import (
+	"testing"
+
+	"github.com/songzhibin97/perfinspector/pkg/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRootCausePath 创建根因位于 pkg.fn 的热点路径
+func newRootCausePath(profileType, pkg, fn string, pct float64) HotPath {
+	return HotPath{
+		Chain: CallChain{
+			TotalPct: pct,
+			Frames: []StackFrame{
+				{FunctionName: "main.main", ShortName: "main", PackageName: "main", Category: CategoryBusiness},
+				{FunctionName: pkg + "." + fn, ShortName: fn, PackageName: pkg, Category: CategoryBusiness},
+				{FunctionName: "runtime.mallocgc", ShortName: "mallocgc", PackageName: "runtime", Category: CategoryRuntime},
+			},
+		},
+		BusinessFrames: []int{0, 1},
+		RootCauseIndex: 1,
+		ProfileType:    profileType,
+	}
+}
+
+func newRootCauseFixture() ([]rules.Finding, map[string]*ProblemContext) {
+	const codec = "github.com/myapp/codec"
+	findings := []rules.Finding{
+		{RuleID: "cpu_hotspot", Severity: "medium", Title: "CPU 热点", ProfileTypes: []string{"cpu"}},
+		{RuleID: "heap_growth", Severity: "high", Title: "内存增长", ProfileTypes: []string{"heap"}},
+		{RuleID: "heap_conversion", Severity: "low", Title: "转换分配", ProfileTypes: []string{"heap"}},
+		{RuleID: "goroutine_leak", Severity: "critical", Title: "goroutine 泄漏", ProfileTypes: []string{"goroutine"}},
+		{RuleID: "cross_rule", Severity: "critical", Title: "联合发现", IsCrossAnalysis: true, ProfileTypes: []string{"cpu", "heap"}},
+	}
+	contexts := map[string]*ProblemContext{
+		"cpu_hotspot": {
+			Severity: "medium",
+			HotPaths: []HotPath{
+				newRootCausePath("cpu", codec, "Encode", 30),
+				newRootCausePath("cpu", codec, "Decode", 10),
+				newRootCausePath("cpu", "github.com/myapp/api", "Handle", 20),
+			},
+			Commands: []ExecutableCmd{{Command: "go tool pprof -list=Encode cpu.pprof"}},
+		},
+		"heap_growth": {
+			Severity: "high",
+			HotPaths: []HotPath{newRootCausePath("heap", codec, "Encode", 25)},
+			Commands: []ExecutableCmd{{Command: "go tool pprof -list=Encode heap.pprof"}},
+		},
+		"heap_conversion": {
+			Severity: "low",
+			HotPaths: []HotPath{newRootCausePath("heap", codec, "Encode", 8)},
+			Commands: []ExecutableCmd{{Command: "go tool pprof -list=Encode heap.pprof"}},
+		},
+		// 占比低于门槛，不构成共同根因
+		"goroutine_leak": {
+			Severity: "critical",
+			HotPaths: []HotPath{
+				newRootCausePath("goroutine", codec, "Stream", 2),
+				newRootCausePath("goroutine", "github.com/myapp/api", "Handle", 50),
+			},
+		},
+		"cross_rule": {HotPaths: []HotPath{newRootCausePath("cpu", "github.com/myapp/cross", "Run", 90)}},
+	}
+	return findings, contexts
+}
+
+func TestCorrelateRootCauses(t *testing.T) {
+	findings, contexts := newRootCauseFixture()
+	insights := CorrelateRootCauses(findings, contexts, 0)
+	require.Len(t, insights, 2)
+
+	// 包名排序：api 在 codec 之前
+	api := insights[0]
+	assert.Equal(t, "github.com/myapp/api", api.Package)
+	assert.Equal(t, []string{"cpu", "goroutine"}, api.ProfileTypes)
+	assert.Equal(t, []string{"cpu_hotspot", "goroutine_leak"}, api.RuleIDs)
+	assert.Equal(t, "critical", api.Severity)
+
+	codec := insights[1]
+	assert.Equal(t, "github.com/myapp/codec", codec.Package)
+	assert.Equal(t, []string{"cpu", "heap"}, codec.ProfileTypes)
+	// 同一类型取占比最大的发现，两个 heap 发现都列为相关发现
+	assert.InDelta(t, 40.0, codec.Pct["cpu"], 0.001)
+	assert.InDelta(t, 25.0, codec.Pct["heap"], 0.001)
+	assert.Equal(t, []string{"cpu_hotspot", "heap_growth", "heap_conversion"}, codec.RuleIDs)
+	assert.Equal(t, "high", codec.Severity)
+	assert.Len(t, codec.HotPaths, 3)
+	assert.Equal(t, "cpu", codec.HotPaths[0].ProfileType)
+	assert.Equal(t, "heap", codec.HotPaths[2].ProfileType)
+}
+
+func TestCorrelateRootCauses_MinPct(t *testing.T) {
+	findings, contexts := newRootCauseFixture()
+	insights := CorrelateRootCauses(findings, contexts, 30)
+	assert.Empty(t, insights, "codec heap share 25% and api cpu share 20% are below 30%")
+
+	assert.Empty(t, CorrelateRootCauses(findings, nil, 0))
+	assert.Empty(t, CorrelateRootCauses(findings[:1], contexts, 0))
+}
+
+func TestRootCauseInsight_Finding(t *testing.T) {
+	findings, contexts := newRootCauseFixture()
+	insight := CorrelateRootCauses(findings, contexts, 0)[1]
+
+	finding := insight.Finding()
+	assert.Equal(t, "shared_root_cause:github.com/myapp/codec", finding.RuleID)
+	assert.Equal(t, "🧩 共同根因: github.com/myapp/codec (CPU + 内存)", finding.Title)
+	assert.True(t, finding.IsCrossAnalysis)
+	assert.Equal(t, []string{"cpu", "heap"}, finding.ProfileTypes)
+	assert.Equal(t, "high", finding.Severity)
+
+	require.Len(t, finding.Evidence, 4)
+	assert.Equal(t, rules.EvidenceItem{Name: "根因包", Display: "github.com/myapp/codec"}, finding.Evidence[0])
+	assert.Equal(t, rules.EvidenceItem{Name: "CPU 占比", Display: "40.0%", Value: 0.4, HasValue: true, Unit: "ratio"}, finding.Evidence[1])
+	assert.Equal(t, "25.0%", finding.Evidence[2].Display)
+	assert.Equal(t, "cpu_hotspot, heap_growth, heap_conversion", finding.Evidence.Map()["相关发现"])
+	assert.Contains(t, finding.Suggestions[0], "CPU和内存")
+}
+
+func TestRootCauseInsight_Context(t *testing.T) {
+	findings, contexts := newRootCauseFixture()
+	insight := CorrelateRootCauses(findings, contexts, 0)[1]
+
+	ctx := insight.Context(contexts)
+	assert.Equal(t, "high", ctx.Severity)
+	assert.Contains(t, ctx.Explanation, "CPU 热点路径的 40.0%，内存 热点路径的 25.0%")
+	assert.Equal(t, "涉及 2 种资源: CPU、内存", ctx.Impact)
+	assert.Len(t, ctx.HotPaths, 3)
+	// 来源发现的命令去重合并
+	assert.Equal(t, []ExecutableCmd{
+		{Command: "go tool pprof -list=Encode cpu.pprof"},
+		{Command: "go tool pprof -list=Encode heap.pprof"},
+	}, ctx.Commands)
+	assert.NotEmpty(t, ctx.Suggestions)
+}
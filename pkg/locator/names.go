@@ -0,0 +1,181 @@
+package locator
+
+import (
+	"strings"
+)
+
+// FormatDisplayName 将 pprof 原始函数名格式化为便于阅读的展示名
+// 去掉包路径、泛型参数和接收者的指针/括号，并把匿名函数后缀改写为 closure#N
+// 例如: "main.(*Server).handleRequest.func1.2" -> "Server.handleRequest closure#1.2"
+// 例如: "main.init.0.func1" -> "init#0 closure#1"
+// 例如: "github.com/user/pkg.(*Cache[...]).Get" -> "Cache.Get"
+// 展示名仅用于报告输出，pprof 命令仍应使用原始函数名
+func FormatDisplayName(functionName string) string {
+	if functionName == "" {
+		return ""
+	}
+
+	result, suffixes := splitDisplayName(functionName)
+	if len(suffixes) > 0 {
+		labels := make([]string, len(suffixes))
+		for i, suffix := range suffixes {
+			labels[i] = suffix.kind + "#" + suffix.index
+		}
+		if result == "" {
+			return strings.Join(labels, " ")
+		}
+		result += " " + strings.Join(labels, " ")
+	}
+	if result == "" {
+		return functionName
+	}
+	return result
+}
+
+// ClosureLabel 返回匿名函数带外层函数的展示名，不是匿名函数时返回空字符串
+// 嵌套的匿名函数序号用 "." 连接；go/defer 语句生成的包装函数不视为匿名函数
+// 例如: "main.createWorker.func1" -> "createWorker()内的闭包#1"
+// 例如: "main.init.0.func1.1" -> "init#0()内的闭包#1.1"
+func ClosureLabel(functionName string) string {
+	if functionName == "" {
+		return ""
+	}
+
+	base, suffixes := splitDisplayName(functionName)
+	if len(suffixes) == 0 {
+		return ""
+	}
+	indexes := make([]string, len(suffixes))
+	for i, suffix := range suffixes {
+		if suffix.kind != "closure" {
+			return ""
+		}
+		indexes[i] = suffix.index
+	}
+	label := "闭包#" + strings.Join(indexes, ".")
+	if base == "" {
+		return label
+	}
+	return base + "()内的" + label
+}
+
+// nameSuffix 编译器生成的函数名后缀，如 func1.2 对应 {closure, 1.2}
+type nameSuffix struct {
+	kind  string
+	index string
+}
+
+// splitDisplayName 将函数名拆分为格式化后的外层函数名和编译器生成的后缀
+func splitDisplayName(functionName string) (string, []nameSuffix) {
+	name := ExtractShortName(stripTypeParams(functionName))
+	parts := strings.Split(name, ".")
+
+	var base []string
+	var suffixes []nameSuffix
+	for i := 0; i < len(parts); i++ {
+		part := parts[i]
+
+		if kind, index, ok := parseCompilerSuffix(part); ok {
+			// 紧随其后的纯数字段表示嵌套的匿名函数序号
+			for i+1 < len(parts) && isDigits(parts[i+1]) {
+				i++
+				index += "." + parts[i]
+			}
+			suffixes = append(suffixes, nameSuffix{kind: kind, index: index})
+			continue
+		}
+
+		// init.0 这类由编译器生成的序号
+		if isDigits(part) && len(base) > 0 {
+			base[len(base)-1] += "#" + part
+			continue
+		}
+
+		part = strings.TrimPrefix(part, "(")
+		part = strings.TrimPrefix(part, "*")
+		part = strings.TrimSuffix(part, ")")
+		if part != "" {
+			base = append(base, part)
+		}
+	}
+	return strings.Join(base, "."), suffixes
+}
+
+// parseCompilerSuffix 识别编译器生成的函数名片段
+// funcN 为匿名函数，gowrapN/deferwrapN 为 go/defer 语句生成的包装函数
+func parseCompilerSuffix(part string) (kind, index string, ok bool) {
+	prefixes := []struct {
+		prefix string
+		kind   string
+	}{
+		{"deferwrap", "defer"},
+		{"gowrap", "go"},
+		{"func", "closure"},
+	}
+	for _, p := range prefixes {
+		if strings.HasPrefix(part, p.prefix) && isDigits(part[len(p.prefix):]) {
+			return p.kind, part[len(p.prefix):], true
+		}
+	}
+	return "", "", false
+}
+
+// stripTypeParams 去掉泛型实例化的类型参数，如 "[...]" 或 "[go.shape.int]"
+func stripTypeParams(functionName string) string {
+	if !strings.Contains(functionName, "[") {
+		return functionName
+	}
+
+	var sb strings.Builder
+	depth := 0
+	for _, c := range functionName {
+		switch {
+		case c == '[':
+			depth++
+		case c == ']':
+			if depth > 0 {
+				depth--
+			}
+		case depth == 0:
+			sb.WriteRune(c)
+		}
+	}
+	return sb.String()
+}
+
+// isDigits 检查字符串是否非空且只包含数字
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// commandFunctionName 返回用于 pprof 命令的函数名
+// 命令需要匹配 profile 中的原始符号，因此优先使用完整函数名而不是展示名
+func commandFunctionName(frame StackFrame) string {
+	if frame.FunctionName != "" && frame.FunctionName != "unknown" && !frame.Unsymbolized() {
+		return frame.FunctionName
+	}
+	return frame.ShortName
+}
+
+// pprofShortName 返回 pprof -focus/-list 使用的短函数名
+// 与 FormatDisplayName 一样由 ExtractShortName 去掉包路径并去掉泛型参数，但保留匿名函数的 funcN 后缀以匹配原始符号；
+// 指针接收者 (*T) 含正则元字符，只保留其后的方法名
+// 例如: "github.com/user/pkg.(*Server).handleRequest.func1" -> "handleRequest.func1"
+// 例如: "main.init.0.func1.1" -> "init.0.func1.1"
+func pprofShortName(functionName string) string {
+	name := ExtractShortName(stripTypeParams(functionName))
+	if strings.HasPrefix(name, "(") {
+		if end := strings.Index(name, ")."); end >= 0 {
+			name = name[end+2:]
+		}
+	}
+	return name
+}
@@ -0,0 +1,147 @@
+package locator
+
+import (
+	"testing"
+
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFormatDisplayName tests the human-friendly function name formatter
+func TestFormatDisplayName(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"", ""},
+		{"main.main", "main"},
+		{"runtime.mallocgc", "mallocgc"},
+		{"github.com/user/repo/pkg.HandleRequest", "HandleRequest"},
+		{"github.com/user/repo/pkg.(*Server).handleRequest", "Server.handleRequest"},
+		{"github.com/user/repo/pkg.Server.handleRequest", "Server.handleRequest"},
+		// 匿名函数
+		{"main.createWorker.func1", "createWorker closure#1"},
+		{"main.(*Server).handleRequest.func1.2", "Server.handleRequest closure#1.2"},
+		{"main.init.0.func1", "init#0 closure#1"},
+		{"main.process.func1.func2", "process closure#1 closure#2"},
+		// go/defer 包装函数
+		{"main.startWorkers.gowrap1", "startWorkers go#1"},
+		{"main.run.deferwrap2", "run defer#2"},
+		// 泛型
+		{"github.com/user/repo/cache.(*Cache[...]).Get", "Cache.Get"},
+		{"github.com/user/repo/cache.Map[go.shape.string,go.shape.int]", "Map"},
+		// 普通名字中包含 func 前缀但不是匿名函数
+		{"main.function", "function"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.expected, FormatDisplayName(tt.input))
+		})
+	}
+}
+
+// TestClosureLabel 匿名函数的展示名带外层函数，其他函数返回空字符串
+func TestClosureLabel(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"", ""},
+		{"main.main", ""},
+		{"github.com/user/repo/pkg.(*Server).handleRequest", ""},
+		{"main.function", ""},
+		{"main.createWorker.func1", "createWorker()内的闭包#1"},
+		{"github.com/user/repo/pkg.(*Server).handleRequest.func2", "Server.handleRequest()内的闭包#2"},
+		// 嵌套的匿名函数
+		{"main.init.0.func1.1", "init#0()内的闭包#1.1"},
+		{"main.(*Server).handleRequest.func1.2.3", "Server.handleRequest()内的闭包#1.2.3"},
+		{"main.process.func1.func2", "process()内的闭包#1.2"},
+		{"github.com/user/repo/cache.(*Cache[...]).Get.func1", "Cache.Get()内的闭包#1"},
+		// go/defer 包装函数不是匿名函数
+		{"main.startWorkers.gowrap1", ""},
+		{"main.run.func1.deferwrap1", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ClosureLabel(tt.input))
+		})
+	}
+}
+
+// TestStackFrameLabel 匿名函数帧使用 ClosureLabel，折叠的递归帧附带调用次数
+func TestStackFrameLabel(t *testing.T) {
+	assert.Equal(t, "HandleNode", StackFrame{ShortName: "HandleNode"}.Label())
+	assert.Equal(t, "walk()内的闭包#1", StackFrame{ShortName: "walk.func1", ClosureLabel: "walk()内的闭包#1"}.Label())
+	assert.Equal(t, "walk()内的闭包#1 ×3", StackFrame{ShortName: "walk.func1", ClosureLabel: "walk()内的闭包#1", Recursion: 3}.Label())
+}
+
+// TestPprofShortName tests the short names used in pprof -focus/-list commands
+func TestPprofShortName(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"", ""},
+		{"HandleRequest", "HandleRequest"},
+		{"main.HandleRequest", "HandleRequest"},
+		{"github.com/user/pkg.HandleRequest", "HandleRequest"},
+		{"github.com/user/pkg.(*Type).Method", "Method"},
+		{"github.com/user/pkg.Type.Method", "Type.Method"},
+		{"github.com/user/pkg.(*Cache[...]).Get", "Get"},
+		// 匿名函数保留 funcN 后缀
+		{"main.init.0.func1.1", "init.0.func1.1"},
+		{"main.createWorker.func1", "createWorker.func1"},
+		{"main.init.func1", "init.func1"},
+		{"github.com/user/pkg.(*Server).handleRequest.func1", "handleRequest.func1"},
+		{"main.main.func1", "main.func1"},
+		{"runtime.main.func1", "main.func1"},
+		{"main.TestFunc.func1.1.1", "TestFunc.func1.1.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.expected, pprofShortName(tt.input))
+		})
+	}
+}
+
+// TestCommandFunctionName tests that commands prefer the raw function name
+func TestCommandFunctionName(t *testing.T) {
+	frame := StackFrame{FunctionName: "main.(*Server).handleRequest", ShortName: "Server.handleRequest"}
+	assert.Equal(t, "main.(*Server).handleRequest", commandFunctionName(frame))
+
+	frame = StackFrame{FunctionName: "unknown", ShortName: "handleRequest"}
+	assert.Equal(t, "handleRequest", commandFunctionName(frame))
+
+	frame = StackFrame{ShortName: "handleRequest"}
+	assert.Equal(t, "handleRequest", commandFunctionName(frame))
+}
+
+// TestReadableNames_CommandsKeepRawName tests that display names do not leak into pprof commands
+func TestReadableNames_CommandsKeepRawName(t *testing.T) {
+	config := DefaultConfig()
+	config.ReadableNames = true
+	extractor := NewExtractorWithConfig(NewClassifier(config), config)
+
+	fn := &profile.Function{ID: 1, Name: "main.(*Server).handleRequest", Filename: "/app/server.go"}
+	loc := &profile.Location{ID: 1, Line: []profile.Line{{Function: fn, Line: 42}}}
+	frame := extractor.ExtractStackFrame(loc, nil)
+	assert.Equal(t, "Server.handleRequest", frame.ShortName)
+	assert.Equal(t, "main.(*Server).handleRequest", frame.FunctionName)
+
+	hotPaths := []HotPath{
+		{Chain: CallChain{Frames: []StackFrame{frame}}, RootCauseIndex: 0},
+	}
+	commands := NewCommandGenerator().GenerateCommands("./cpu.pprof", "cpu", hotPaths)
+
+	var hasFocus bool
+	for _, cmd := range commands {
+		assert.NotContains(t, cmd.Command, "Server.handleRequest")
+		if cmd.Command == "go tool pprof -focus=handleRequest ./cpu.pprof" {
+			hasFocus = true
+		}
+	}
+	assert.True(t, hasFocus)
+}
@@ -0,0 +1,163 @@
+package locator
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/pprof/profile"
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+)
+
+// NoBusinessOwner 没有业务代码帧的分配在归属树中的顶层节点名
+const NoBusinessOwner = "(无业务代码)"
+
+// OwnershipTree 堆内存归属树
+// 按业务代码对存活内存 (inuse_space) 的责任汇总：
+// 第一层为业务包，第二层为该包中持有分配路径的业务函数，第三层为实际执行分配的包
+type OwnershipTree struct {
+	SampleType string          // 汇总使用的 sample type
+	Total      int64           // profile 中该 sample type 的总值
+	Owners     []OwnershipNode // 按 Value 降序
+}
+
+// OwnershipNode 归属树节点
+type OwnershipNode struct {
+	Name     string          // 包名或函数名
+	Category CodeCategory    // 节点的代码分类
+	Location string          // 业务函数节点的 "文件:行号"，其他节点为空
+	Value    int64           // 归属于该节点的存活字节数
+	Pct      float64         // 占 Total 的百分比 (0-100)
+	Children []OwnershipNode // 按 Value 降序
+}
+
+// AnalyzeOwnership 按业务代码归属汇总 heap profile 的存活内存
+// 每个样本归属于调用链中最深的业务代码帧 (最接近分配点的业务代码)，
+// 可以近似看出哪些业务包通过自己的调用路径持有了存活内存；
+// 没有业务代码帧的样本归入 NoBusinessOwner。profile 没有 inuse_space 时返回 nil
+func (a *PathAnalyzer) AnalyzeOwnership(p *profile.Profile) *OwnershipTree {
+	tree, _ := a.AnalyzeOwnershipCtx(context.Background(), p)
+	return tree
+}
+
+// AnalyzeOwnershipCtx 与 AnalyzeOwnership 相同，但在逐样本循环中检查 ctx
+// ctx 取消或超时时返回 nil 和 ctx.Err()
+func (a *PathAnalyzer) AnalyzeOwnershipCtx(ctx context.Context, p *profile.Profile) (*OwnershipTree, error) {
+	if p == nil {
+		return nil, nil
+	}
+	valueIndex := -1
+	for i, st := range p.SampleType {
+		if st.Type == "inuse_space" {
+			valueIndex = i
+			break
+		}
+	}
+	if valueIndex < 0 {
+		return nil, nil
+	}
+
+	var total int64
+	for i, sample := range p.Sample {
+		if err := analyzer.CheckCanceled(ctx, i); err != nil {
+			return nil, err
+		}
+		if len(sample.Value) > valueIndex {
+			total += sample.Value[valueIndex]
+		}
+	}
+	if total <= 0 {
+		return nil, nil
+	}
+
+	root := newOwnershipBuilder(OwnershipNode{})
+	for i, sample := range p.Sample {
+		if err := analyzer.CheckCanceled(ctx, i); err != nil {
+			return nil, err
+		}
+		chain := a.extractor.ExtractCallChain(sample, valueIndex, total)
+		if chain.TotalValue <= 0 || len(chain.Frames) == 0 {
+			continue
+		}
+
+		leaf := chain.Frames[len(chain.Frames)-1]
+		businessFrames := FindBusinessFrames(chain.Frames)
+		if len(businessFrames) == 0 {
+			owner := root.child(NoBusinessOwner, OwnershipNode{Name: NoBusinessOwner, Category: CategoryUnknown})
+			owner.add(chain.TotalValue)
+			owner.packageChild(leaf).add(chain.TotalValue)
+			continue
+		}
+
+		frame := chain.Frames[businessFrames[len(businessFrames)-1]]
+		owner := root.packageChild(frame)
+		owner.add(chain.TotalValue)
+		fn := owner.child(frame.FunctionName, OwnershipNode{Name: frame.ShortName, Category: frame.Category, Location: frame.Location()})
+		fn.add(chain.TotalValue)
+		fn.packageChild(leaf).add(chain.TotalValue)
+	}
+
+	return &OwnershipTree{
+		SampleType: "inuse_space",
+		Total:      total,
+		Owners:     root.nodes(total),
+	}, nil
+}
+
+// ownershipBuilder 构建归属树时使用的可变节点
+type ownershipBuilder struct {
+	node     OwnershipNode
+	children map[string]*ownershipBuilder
+	order    []string
+}
+
+// newOwnershipBuilder 创建构建节点
+func newOwnershipBuilder(node OwnershipNode) *ownershipBuilder {
+	return &ownershipBuilder{
+		node:     node,
+		children: make(map[string]*ownershipBuilder),
+	}
+}
+
+// child 返回 key 对应的子节点，不存在时以 node 为模板创建
+func (b *ownershipBuilder) child(key string, node OwnershipNode) *ownershipBuilder {
+	c, ok := b.children[key]
+	if !ok {
+		c = newOwnershipBuilder(node)
+		b.children[key] = c
+		b.order = append(b.order, key)
+	}
+	return c
+}
+
+// packageChild 返回栈帧所在包的子节点，包名未知时使用 "unknown"
+func (b *ownershipBuilder) packageChild(frame StackFrame) *ownershipBuilder {
+	name := frame.PackageName
+	if name == "" {
+		name = "unknown"
+	}
+	return b.child(name, OwnershipNode{Name: name, Category: frame.Category})
+}
+
+// add 累加节点的值
+func (b *ownershipBuilder) add(value int64) {
+	b.node.Value += value
+}
+
+// nodes 返回按值降序排列的子节点，值相同时保持首次出现的顺序
+func (b *ownershipBuilder) nodes(total int64) []OwnershipNode {
+	if len(b.order) == 0 {
+		return nil
+	}
+	nodes := make([]OwnershipNode, 0, len(b.order))
+	for _, name := range b.order {
+		c := b.children[name]
+		node := c.node
+		node.Pct = float64(node.Value) / float64(total) * 100
+		node.Children = c.nodes(total)
+		nodes = append(nodes, node)
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].Value > nodes[j].Value
+	})
+	return nodes
+}
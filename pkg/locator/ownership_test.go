@@ -0,0 +1,110 @@
+package locator
+
+import (
+	"testing"
+
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createHeapSample creates a heap sample with [inuse_objects, inuse_space] values
+func createHeapSample(funcNames []string, objects, bytes int64) *profile.Sample {
+	sample := createTestSample(funcNames, 0, nil)
+	sample.Value = []int64{objects, bytes}
+	return sample
+}
+
+// newOwnershipAnalyzer creates a path analyzer for the ownership tests
+func newOwnershipAnalyzer() *PathAnalyzer {
+	config := LocatorConfig{ModuleName: "github.com/myapp"}
+	return NewPathAnalyzer(NewExtractor(NewClassifier(config)), config)
+}
+
+// TestAnalyzeOwnership tests grouping live memory by owning business frame
+func TestAnalyzeOwnership(t *testing.T) {
+	p := &profile.Profile{
+		SampleType: []*profile.ValueType{
+			{Type: "inuse_objects", Unit: "count"},
+			{Type: "inuse_space", Unit: "bytes"},
+		},
+		Sample: []*profile.Sample{
+			createHeapSample([]string{"main.main", "github.com/myapp/cache.(*Cache).Set", "bytes.growSlice"}, 1, 600),
+			createHeapSample([]string{"main.main", "github.com/myapp/cache.(*Cache).Set", "github.com/myapp/cache.newEntry"}, 1, 200),
+			createHeapSample([]string{"main.main", "github.com/myapp/api.Handle", "encoding/json.Marshal"}, 1, 150),
+			createHeapSample([]string{"runtime.main", "runtime.malg"}, 1, 50),
+		},
+	}
+
+	tree := newOwnershipAnalyzer().AnalyzeOwnership(p)
+	require.NotNil(t, tree)
+	assert.Equal(t, "inuse_space", tree.SampleType)
+	assert.Equal(t, int64(1000), tree.Total)
+	require.Len(t, tree.Owners, 3)
+
+	cache := tree.Owners[0]
+	assert.Equal(t, "github.com/myapp/cache", cache.Name)
+	assert.Equal(t, CategoryBusiness, cache.Category)
+	assert.Equal(t, int64(800), cache.Value)
+	assert.InDelta(t, 80.0, cache.Pct, 0.001)
+
+	// 最深的业务帧是 newEntry 时归属于 newEntry，而不是调用它的 Set
+	require.Len(t, cache.Children, 2)
+	assert.Equal(t, "(*Cache).Set", cache.Children[0].Name)
+	assert.Equal(t, int64(600), cache.Children[0].Value)
+	assert.NotEmpty(t, cache.Children[0].Location)
+	require.Len(t, cache.Children[0].Children, 1)
+	assert.Equal(t, "bytes", cache.Children[0].Children[0].Name)
+	assert.Equal(t, CategoryStdlib, cache.Children[0].Children[0].Category)
+	assert.Equal(t, "newEntry", cache.Children[1].Name)
+
+	assert.Equal(t, "github.com/myapp/api", tree.Owners[1].Name)
+	assert.Equal(t, int64(150), tree.Owners[1].Value)
+
+	noBusiness := tree.Owners[2]
+	assert.Equal(t, NoBusinessOwner, noBusiness.Name)
+	assert.Equal(t, int64(50), noBusiness.Value)
+	require.Len(t, noBusiness.Children, 1)
+	assert.Equal(t, "runtime", noBusiness.Children[0].Name)
+	assert.Empty(t, noBusiness.Children[0].Children)
+}
+
+// TestAnalyzeOwnership_Unavailable tests profiles without inuse_space data
+func TestAnalyzeOwnership_Unavailable(t *testing.T) {
+	analyzer := newOwnershipAnalyzer()
+
+	assert.Nil(t, analyzer.AnalyzeOwnership(nil))
+	assert.Nil(t, analyzer.AnalyzeOwnership(&profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "cpu", Unit: "nanoseconds"}},
+		Sample:     []*profile.Sample{createTestSample([]string{"main.main"}, 100, nil)},
+	}))
+	assert.Nil(t, analyzer.AnalyzeOwnership(&profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "inuse_space", Unit: "bytes"}},
+		Sample:     []*profile.Sample{createTestSample([]string{"main.main"}, 0, nil)},
+	}), "empty heap should not produce a tree")
+}
+
+// TestGenerateContext_Ownership tests that heap contexts carry the ownership tree
+func TestGenerateContext_Ownership(t *testing.T) {
+	generator := NewContextGeneratorFromConfig(LocatorConfig{ModuleName: "github.com/myapp"})
+	older := &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "inuse_space", Unit: "bytes"}},
+		Sample:     []*profile.Sample{createTestSample([]string{"main.main", "github.com/myapp/cache.Load"}, 100, nil)},
+	}
+	latest := &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "inuse_space", Unit: "bytes"}},
+		Sample:     []*profile.Sample{createTestSample([]string{"main.main", "github.com/myapp/cache.Load"}, 300, nil)},
+	}
+
+	heapFinding := createTestFinding("内存泄漏", "high", nil)
+	ctx := generator.GenerateContextWithAllProfiles(heapFinding, nil,
+		map[string][]*profile.Profile{"heap": {older, latest}}, nil)
+	require.NotNil(t, ctx)
+	require.NotNil(t, ctx.Ownership)
+	assert.Equal(t, int64(300), ctx.Ownership.Total, "ownership should use the latest snapshot")
+
+	cpuFinding := createTestFinding("CPU 热点", "high", nil)
+	ctx = generator.GenerateContext(cpuFinding, map[string]*profile.Profile{"cpu": createTestProfileWithSamples([]string{"main.main"}, 10)})
+	require.NotNil(t, ctx)
+	assert.Nil(t, ctx.Ownership)
+}
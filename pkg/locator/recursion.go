@@ -0,0 +1,52 @@
+package locator
+
+// DefaultRecursionMaxCycle 识别的递归环最多包含 3 个函数，如 A → B → C → A
+const DefaultRecursionMaxCycle = 3
+
+// CollapseRecursion 将连续重复的栈帧折叠为一份，并在 Recursion 中记录重复次数
+// 既处理直接递归 (A → A → A)，也处理长度不超过 maxCycle 的间接递归 (A → B → A → B)；
+// 同一位置有多种折叠方式时取覆盖帧数最多的，覆盖相同时取更短的环。
+// 保留的是最深一次重复的栈帧，其行号最接近热点。frames 需按从入口到叶子排列，不会被修改
+func CollapseRecursion(frames []StackFrame, maxCycle int) []StackFrame {
+	if maxCycle <= 0 {
+		maxCycle = DefaultRecursionMaxCycle
+	}
+
+	result := make([]StackFrame, 0, len(frames))
+	for i := 0; i < len(frames); {
+		bestCycle, bestRepeats := 0, 1
+		for cycle := 1; cycle <= maxCycle && i+2*cycle <= len(frames); cycle++ {
+			repeats := 1
+			for i+(repeats+1)*cycle <= len(frames) && sameFunctions(frames[i:i+cycle], frames[i+repeats*cycle:i+(repeats+1)*cycle]) {
+				repeats++
+			}
+			if repeats > 1 && cycle*repeats > bestCycle*bestRepeats {
+				bestCycle, bestRepeats = cycle, repeats
+			}
+		}
+
+		if bestCycle == 0 {
+			result = append(result, frames[i])
+			i++
+			continue
+		}
+
+		last := i + (bestRepeats-1)*bestCycle
+		for _, frame := range frames[last : last+bestCycle] {
+			frame.Recursion = bestRepeats
+			result = append(result, frame)
+		}
+		i += bestCycle * bestRepeats
+	}
+	return result
+}
+
+// sameFunctions 判断两段栈帧是否依次是同一函数
+func sameFunctions(a, b []StackFrame) bool {
+	for i := range a {
+		if a[i].FunctionName != b[i].FunctionName {
+			return false
+		}
+	}
+	return true
+}
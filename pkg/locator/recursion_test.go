@@ -0,0 +1,142 @@
+package locator
+
+import (
+	"testing"
+
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/assert"
+)
+
+// recursionFrames 按从入口到叶子的顺序构造栈帧，行号依次递增
+func recursionFrames(names ...string) []StackFrame {
+	frames := make([]StackFrame, len(names))
+	for i, name := range names {
+		frames[i] = StackFrame{FunctionName: name, ShortName: name, LineNumber: int64(i + 1)}
+	}
+	return frames
+}
+
+func frameNames(frames []StackFrame) []string {
+	names := make([]string, len(frames))
+	for i, frame := range frames {
+		names[i] = frame.DisplayName()
+	}
+	return names
+}
+
+func TestCollapseRecursion(t *testing.T) {
+	tests := []struct {
+		name     string
+		frames   []string
+		expected []string
+	}{
+		{
+			name:     "direct recursion",
+			frames:   []string{"main", "walk", "walk", "walk", "visit"},
+			expected: []string{"main", "walk ×3", "visit"},
+		},
+		{
+			name:     "mutual recursion",
+			frames:   []string{"main", "a", "b", "a", "b", "a", "b", "leaf"},
+			expected: []string{"main", "a ×3", "b ×3", "leaf"},
+		},
+		{
+			name:     "cycle of three",
+			frames:   []string{"a", "b", "c", "a", "b", "c"},
+			expected: []string{"a ×2", "b ×2", "c ×2"},
+		},
+		{
+			name:     "cycle longer than limit is kept",
+			frames:   []string{"a", "b", "c", "d", "a", "b", "c", "d"},
+			expected: []string{"a", "b", "c", "d", "a", "b", "c", "d"},
+		},
+		{
+			name:     "no recursion",
+			frames:   []string{"main", "a", "b"},
+			expected: []string{"main", "a", "b"},
+		},
+		{
+			name:     "several recursive sections",
+			frames:   []string{"a", "a", "b", "c", "c", "c"},
+			expected: []string{"a ×2", "b", "c ×3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collapsed := CollapseRecursion(recursionFrames(tt.frames...), DefaultRecursionMaxCycle)
+			assert.Equal(t, tt.expected, frameNames(collapsed))
+		})
+	}
+}
+
+func TestCollapseRecursion_KeepsDeepestFrame(t *testing.T) {
+	frames := recursionFrames("main", "walk", "walk", "walk", "visit")
+
+	collapsed := CollapseRecursion(frames, DefaultRecursionMaxCycle)
+
+	assert.Len(t, collapsed, 3)
+	assert.Equal(t, int64(4), collapsed[1].LineNumber, "should keep the innermost walk frame")
+	assert.Equal(t, 0, frames[3].Recursion, "input frames should not be modified")
+}
+
+func TestCollapseRecursion_Empty(t *testing.T) {
+	assert.Empty(t, CollapseRecursion(nil, DefaultRecursionMaxCycle))
+}
+
+func TestStackFrame_DisplayName(t *testing.T) {
+	assert.Equal(t, "HandleNode", StackFrame{ShortName: "HandleNode"}.DisplayName())
+	assert.Equal(t, "HandleNode", StackFrame{ShortName: "HandleNode", Recursion: 1}.DisplayName())
+	assert.Equal(t, "HandleNode ×14", StackFrame{ShortName: "HandleNode", Recursion: 14}.DisplayName())
+}
+
+// recursiveSample 构造 main.run → main.walk × depth → runtime.mallocgc 的样本
+func recursiveSample(depth int, value int64) *profile.Sample {
+	runFn := &profile.Function{ID: 1, Name: "github.com/myapp/main.run", Filename: "main.go"}
+	walkFn := &profile.Function{ID: 2, Name: "github.com/myapp/main.walk", Filename: "walk.go"}
+	mallocFn := &profile.Function{ID: 3, Name: "runtime.mallocgc", Filename: "runtime/malloc.go"}
+
+	locations := []*profile.Location{{ID: 1, Line: []profile.Line{{Function: mallocFn, Line: 100}}}}
+	for i := 0; i < depth; i++ {
+		locations = append(locations, &profile.Location{ID: uint64(2 + i), Line: []profile.Line{{Function: walkFn, Line: 20}}})
+	}
+	locations = append(locations, &profile.Location{ID: uint64(2 + depth), Line: []profile.Line{{Function: runFn, Line: 10}}})
+	return &profile.Sample{Location: locations, Value: []int64{value}}
+}
+
+func TestExtractCallChain_CollapseRecursion(t *testing.T) {
+	config := LocatorConfig{ModuleName: "github.com/myapp", CollapseRecursion: true}
+	extractor := NewExtractorWithConfig(NewClassifier(config), config)
+
+	chain := extractor.ExtractCallChain(recursiveSample(14, 1000), 0, 10000)
+
+	assert.Len(t, chain.Frames, 3)
+	assert.Equal(t, 14, chain.Frames[1].Recursion)
+	assert.Equal(t, "walk ×14", chain.Frames[1].DisplayName())
+	assert.Equal(t, []int{2}, chain.BoundaryPoints)
+	assert.Equal(t, 2, chain.CategoryBreakdown[CategoryBusiness])
+	assert.Equal(t, 1, chain.CategoryBreakdown[CategoryRuntime])
+
+	// 未开启时保留全部帧
+	config.CollapseRecursion = false
+	extractor = NewExtractorWithConfig(NewClassifier(config), config)
+	chain = extractor.ExtractCallChain(recursiveSample(14, 1000), 0, 10000)
+	assert.Len(t, chain.Frames, 16)
+}
+
+func TestAggregateCallChains_MergesRecursionDepth(t *testing.T) {
+	config := LocatorConfig{ModuleName: "github.com/myapp", CollapseRecursion: true}
+	extractor := NewExtractorWithConfig(NewClassifier(config), config)
+	analyzer := NewPathAnalyzer(extractor, config)
+
+	chains := []CallChain{
+		extractor.ExtractCallChain(recursiveSample(3, 400), 0, 1000),
+		extractor.ExtractCallChain(recursiveSample(9, 600), 0, 1000),
+	}
+
+	aggregated := analyzer.AggregateCallChains(chains)
+
+	assert.Len(t, aggregated, 1, "chains differing only in recursion depth should aggregate")
+	assert.Equal(t, int64(1000), aggregated[0].TotalValue)
+	assert.Equal(t, 9, aggregated[0].Frames[1].Recursion)
+}
@@ -0,0 +1,159 @@
+package locator
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sync/atomic"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CategoryStyle 代码分类的展示样式
+type CategoryStyle struct {
+	Icon  string `yaml:"icon"`  // 文本报告、TUI 和 HTML 中的图标 (emoji 或文字)
+	Label string `yaml:"label"` // 展示名称
+	Color string `yaml:"color"` // HTML 报告和 DOT 调用图中的颜色 (#rgb 或 #rrggbb)
+}
+
+// CategoryTheme 各代码分类的展示样式
+type CategoryTheme map[CodeCategory]CategoryStyle
+
+// categoryThemeFile 分类样式配置文件格式，只需写出要覆盖的分类和字段
+type categoryThemeFile struct {
+	Categories map[CodeCategory]CategoryStyle `yaml:"categories"`
+}
+
+// hexColorPattern 允许的颜色格式，颜色会写入 HTML 报告的 CSS
+var hexColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// categoryTheme 当前生效的分类样式，由 SetCategoryTheme 替换
+var categoryTheme atomic.Pointer[CategoryTheme]
+
+func init() {
+	theme := DefaultCategoryTheme()
+	categoryTheme.Store(&theme)
+}
+
+// Categories 返回全部代码分类，按报告中的展示顺序
+func Categories() []CodeCategory {
+	return []CodeCategory{CategoryBusiness, CategoryGenerated, CategoryThirdParty, CategoryStdlib, CategoryRuntime, CategoryUnknown, CategoryMissingSymbol}
+}
+
+// Valid 判断是否是 Categories 中的一个分类
+func (c CodeCategory) Valid() bool {
+	for _, category := range Categories() {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultCategoryTheme 返回内置的分类样式
+func DefaultCategoryTheme() CategoryTheme {
+	return CategoryTheme{
+		CategoryRuntime:       {Icon: "⚙️", Label: "运行时", Color: "#6c757d"},
+		CategoryStdlib:        {Icon: "📚", Label: "标准库", Color: "#17a2b8"},
+		CategoryThirdParty:    {Icon: "📦", Label: "第三方", Color: "#6f42c1"},
+		CategoryBusiness:      {Icon: "💼", Label: "业务", Color: "#28a745"},
+		CategoryGenerated:     {Icon: "🧬", Label: "生成代码", Color: "#20c997"},
+		CategoryUnknown:       {Icon: "❓", Label: "未知", Color: "#adb5bd"},
+		CategoryMissingSymbol: {Icon: "🚫", Label: "缺少符号", Color: "#e0a800"},
+	}
+}
+
+// plainCategoryIcons 不支持 emoji 的终端使用的 ASCII 分类图标
+var plainCategoryIcons = map[CodeCategory]string{
+	CategoryRuntime:       "[runtime]",
+	CategoryStdlib:        "[stdlib]",
+	CategoryThirdParty:    "[third-party]",
+	CategoryBusiness:      "[business]",
+	CategoryGenerated:     "[generated]",
+	CategoryUnknown:       "[unknown]",
+	CategoryMissingSymbol: "[no-symbol]",
+}
+
+// PlainCategoryTheme 返回使用 ASCII 图标的内置分类样式，用于不支持 emoji 的终端
+func PlainCategoryTheme() CategoryTheme {
+	return PlainIcons(DefaultCategoryTheme())
+}
+
+// PlainIcons 将仍为内置 emoji 的分类图标替换为 ASCII 图标，配置文件中自定义的图标保持不变
+func PlainIcons(theme CategoryTheme) CategoryTheme {
+	defaults := DefaultCategoryTheme()
+	plain := make(CategoryTheme, len(theme))
+	for category, style := range theme {
+		if icon, ok := plainCategoryIcons[category]; ok && style.Icon == defaults[category].Icon {
+			style.Icon = icon
+		}
+		plain[category] = style
+	}
+	return plain
+}
+
+// LoadCategoryTheme 读取分类样式配置文件，未配置的分类和字段使用内置样式
+// 文件格式:
+//
+//	categories:
+//	  business:
+//	    icon: "🏢"
+//	    label: "Business"
+//	    color: "#2e7d32"
+func LoadCategoryTheme(path string) (CategoryTheme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read category config: %w", err)
+	}
+
+	var file categoryThemeFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse category config: %w", err)
+	}
+
+	theme := DefaultCategoryTheme()
+	for category, style := range file.Categories {
+		base, ok := theme[category]
+		if !ok {
+			return nil, fmt.Errorf("category config: unknown category '%s'", string(category))
+		}
+		if style.Color != "" && !hexColorPattern.MatchString(style.Color) {
+			return nil, fmt.Errorf("category config: invalid color '%s' for %s, must be #rgb or #rrggbb", style.Color, string(category))
+		}
+		if style.Icon != "" {
+			base.Icon = style.Icon
+		}
+		if style.Label != "" {
+			base.Label = style.Label
+		}
+		if style.Color != "" {
+			base.Color = style.Color
+		}
+		theme[category] = base
+	}
+	return theme, nil
+}
+
+// SetCategoryTheme 替换全局生效的分类样式，通常在启动时调用一次
+// 缺少的分类使用内置样式；nil 恢复内置样式
+func SetCategoryTheme(theme CategoryTheme) {
+	merged := DefaultCategoryTheme()
+	for category, style := range theme {
+		merged[category] = style
+	}
+	categoryTheme.Store(&merged)
+}
+
+// Style 返回分类当前的展示样式，不认识的分类按 CategoryUnknown 展示
+func (c CodeCategory) Style() CategoryStyle {
+	theme := *categoryTheme.Load()
+	if style, ok := theme[c]; ok {
+		return style
+	}
+	return theme[CategoryUnknown]
+}
+
+// Color 返回分类在 HTML 报告和 DOT 调用图中的颜色
+func (c CodeCategory) Color() string {
+	return c.Style().Color
+}
@@ -0,0 +1,116 @@
+package locator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCategoryConfig writes a category config file into a temp dir
+func writeCategoryConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "categories.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+// TestDefaultCategoryTheme tests that every category has a complete built-in style
+func TestDefaultCategoryTheme(t *testing.T) {
+	theme := DefaultCategoryTheme()
+	require.Len(t, theme, len(Categories()))
+	for _, category := range Categories() {
+		style := theme[category]
+		assert.NotEmpty(t, style.Icon, category)
+		assert.NotEmpty(t, style.Label, category)
+		assert.Regexp(t, hexColorPattern, style.Color, category)
+	}
+}
+
+// TestLoadCategoryTheme tests merging a partial config over the built-in styles
+func TestLoadCategoryTheme(t *testing.T) {
+	path := writeCategoryConfig(t, `
+categories:
+  business:
+    icon: "🏢"
+    label: "Business"
+  third_party:
+    color: "#f60"
+`)
+	theme, err := LoadCategoryTheme(path)
+	require.NoError(t, err)
+
+	defaults := DefaultCategoryTheme()
+	assert.Equal(t, CategoryStyle{Icon: "🏢", Label: "Business", Color: defaults[CategoryBusiness].Color}, theme[CategoryBusiness])
+	assert.Equal(t, "#f60", theme[CategoryThirdParty].Color)
+	assert.Equal(t, defaults[CategoryThirdParty].Label, theme[CategoryThirdParty].Label)
+	assert.Equal(t, defaults[CategoryRuntime], theme[CategoryRuntime])
+}
+
+// TestLoadCategoryTheme_Errors tests rejecting unknown categories, bad colors and unreadable files
+func TestLoadCategoryTheme_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		errText string
+	}{
+		{name: "unknown category", content: "categories:\n  vendor:\n    label: x\n", errText: "unknown category 'vendor'"},
+		{name: "invalid color", content: "categories:\n  business:\n    color: \"red;}\"\n", errText: "invalid color"},
+		{name: "invalid yaml", content: "categories: [", errText: "failed to parse"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadCategoryTheme(writeCategoryConfig(t, tt.content))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errText)
+		})
+	}
+
+	_, err := LoadCategoryTheme(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+// TestSetCategoryTheme tests that the category methods follow the active theme
+func TestSetCategoryTheme(t *testing.T) {
+	t.Cleanup(func() { SetCategoryTheme(nil) })
+
+	assert.Equal(t, "业务", CategoryBusiness.String())
+	assert.Equal(t, "💼", CategoryBusiness.Icon())
+	assert.Equal(t, "#28a745", CategoryBusiness.Color())
+	assert.Equal(t, "未知", CodeCategory("other").String())
+
+	SetCategoryTheme(CategoryTheme{
+		CategoryBusiness: {Icon: "B", Label: "Business", Color: "#000000"},
+		CategoryUnknown:  {Icon: "?", Label: "Unknown", Color: "#ffffff"},
+	})
+	assert.Equal(t, "Business", CategoryBusiness.String())
+	assert.Equal(t, "B", CategoryBusiness.Icon())
+	assert.Equal(t, "#000000", CategoryBusiness.Color())
+	assert.Equal(t, "Unknown", CodeCategory("other").String())
+	// 未配置的分类保留内置样式
+	assert.Equal(t, "运行时", CategoryRuntime.String())
+
+	SetCategoryTheme(nil)
+	assert.Equal(t, "业务", CategoryBusiness.String())
+}
+
+func TestPlainCategoryTheme(t *testing.T) {
+	theme := PlainCategoryTheme()
+	assert.Equal(t, "[business]", theme[CategoryBusiness].Icon)
+	assert.Equal(t, "[third-party]", theme[CategoryThirdParty].Icon)
+	assert.Equal(t, "[stdlib]", theme[CategoryStdlib].Icon)
+	assert.Equal(t, "[runtime]", theme[CategoryRuntime].Icon)
+	assert.Equal(t, "[unknown]", theme[CategoryUnknown].Icon)
+	// 只替换图标
+	assert.Equal(t, DefaultCategoryTheme()[CategoryBusiness].Label, theme[CategoryBusiness].Label)
+
+	// 自定义图标保持不变
+	custom := DefaultCategoryTheme()
+	custom[CategoryBusiness] = CategoryStyle{Icon: "B", Label: "Business", Color: "#000000"}
+	plain := PlainIcons(custom)
+	assert.Equal(t, "B", plain[CategoryBusiness].Icon)
+	assert.Equal(t, "[stdlib]", plain[CategoryStdlib].Icon)
+	// 不修改传入的样式
+	assert.Equal(t, "📚", custom[CategoryStdlib].Icon)
+}
@@ -1,5 +1,10 @@
 package locator
 
+import (
+	"fmt"
+	"time"
+)
+
 // CodeCategory 代码分类
 type CodeCategory string
 
@@ -8,53 +13,62 @@ const (
 	CategoryStdlib     CodeCategory = "stdlib"      // 标准库
 	CategoryThirdParty CodeCategory = "third_party" // 第三方库
 	CategoryBusiness   CodeCategory = "business"    // 业务代码
+	CategoryGenerated  CodeCategory = "generated"   // 模块内的生成代码 (protobuf、代码生成器、mock)
 	CategoryUnknown    CodeCategory = "unknown"     // 未知
+	// CategoryMissingSymbol 缺少符号的栈帧 (如缺少调试信息的地址)，与能解析出包名但无法归类的 CategoryUnknown 区分
+	CategoryMissingSymbol CodeCategory = "missing_symbol"
 )
 
-// String 返回分类的中文名称
+// String 返回分类的展示名称，默认为中文，可通过 SetCategoryTheme 修改
 func (c CodeCategory) String() string {
-	switch c {
-	case CategoryRuntime:
-		return "运行时"
-	case CategoryStdlib:
-		return "标准库"
-	case CategoryThirdParty:
-		return "第三方"
-	case CategoryBusiness:
-		return "业务"
-	default:
-		return "未知"
-	}
+	return c.Style().Label
 }
 
-// Icon 返回分类的图标
+// Icon 返回分类的图标，可通过 SetCategoryTheme 修改
 func (c CodeCategory) Icon() string {
-	switch c {
-	case CategoryRuntime:
-		return "⚙️"
-	case CategoryStdlib:
-		return "📚"
-	case CategoryThirdParty:
-		return "📦"
-	case CategoryBusiness:
-		return "💼"
-	default:
-		return "❓"
-	}
+	return c.Style().Icon
 }
 
 // StackFrame 增强的栈帧信息
+// 缺少符号信息时 FunctionName 和 ShortName 为 "<unknown 0x地址>" (地址未知时为 "<unknown>")，
+// FilePath 为 "unknown"，Category 为 CategoryMissingSymbol
 type StackFrame struct {
-	FunctionName string       // 完整函数名 (包含包路径)
-	ShortName    string       // 短函数名 (仅函数名)
-	PackageName  string       // 包名
+	FunctionName string       // 完整函数名 (包含包路径)，在同一 profile 中唯一标识函数
+	ShortName    string       // 短函数名 (仅函数名，ReadableNames 开启时为展示名)
+	ClosureLabel string       // 匿名函数带外层函数的展示名 (见 ClosureLabel)，不是匿名函数时为空
+	PackageName  string       // 包名，无法解析时为空
 	FilePath     string       // 文件路径
-	LineNumber   int64        // 行号
+	LineNumber   int64        // 行号，未知时为 0
 	Category     CodeCategory // 代码分类
-	Flat         int64        // 自身消耗
-	FlatPct      float64      // 自身消耗百分比
-	Cum          int64        // 累计消耗（包含调用的函数）
-	CumPct       float64      // 累计消耗百分比
+	Flat         int64        // 自身消耗，未计算时为 0
+	FlatPct      float64      // 自身消耗百分比 (0-100)
+	Cum          int64        // 累计消耗（包含调用的函数），未计算时为 0
+	CumPct       float64      // 累计消耗百分比 (0-100)
+	Recursion    int          // 折叠的连续递归调用次数 (见 CollapseRecursion)，未折叠时为 0
+}
+
+// DisplayName 返回报告中展示的函数名，折叠的递归帧附带调用次数，如 "HandleNode ×14"
+func (f StackFrame) DisplayName() string {
+	if f.Recursion > 1 {
+		return f.ShortName + " ×" + itoa(int64(f.Recursion))
+	}
+	return f.ShortName
+}
+
+// Label 返回调用链中展示的名称，匿名函数使用 ClosureLabel，其余同 DisplayName
+func (f StackFrame) Label() string {
+	if f.ClosureLabel == "" {
+		return f.DisplayName()
+	}
+	if f.Recursion > 1 {
+		return f.ClosureLabel + " ×" + itoa(int64(f.Recursion))
+	}
+	return f.ClosureLabel
+}
+
+// Unsymbolized 判断栈帧是否缺少符号
+func (f StackFrame) Unsymbolized() bool {
+	return f.Category == CategoryMissingSymbol
 }
 
 // Location 返回 "文件:行号" 格式的位置字符串
@@ -85,13 +99,15 @@ func itoa(n int64) string {
 }
 
 // CallChain 完整调用链
+// Frames 始终按从入口 (如 main.main 或 goroutine 起点) 到叶子 (实际消耗资源的函数) 排列，
+// 包内所有帧索引 (BoundaryPoints、HotPath.BusinessFrames、HotPath.RootCauseIndex) 都指向 Frames
 type CallChain struct {
 	Frames            []StackFrame         // 所有栈帧 (从入口到叶子)
-	TotalValue        int64                // 总消耗值
-	TotalPct          float64              // 总消耗百分比
-	SampleCount       int                  // 样本数量
+	TotalValue        int64                // 总消耗值，单位取决于 profile 的 sample type
+	TotalPct          float64              // 占 profile 总值的百分比 (0-100)
+	SampleCount       int                  // 合并到该调用链的样本数量
 	CategoryBreakdown map[CodeCategory]int // 各类别帧数统计
-	BoundaryPoints    []int                // 类别边界索引 (类别发生变化的位置)
+	BoundaryPoints    []int                // 类别边界索引 (类别发生变化的位置，即与前一帧类别不同的帧)
 }
 
 // Summary 返回类别分布摘要字符串，如 "2 业务 → 1 第三方 → 2 标准库 → 3 运行时"
@@ -136,6 +152,17 @@ func (c CallChain) Summary() string {
 	return result
 }
 
+// UnsymbolizedFrames 返回调用链中缺少符号的栈帧数，大于 0 时基于该调用链的分析只是部分结果
+func (c CallChain) UnsymbolizedFrames() int {
+	count := 0
+	for _, frame := range c.Frames {
+		if frame.Unsymbolized() {
+			count++
+		}
+	}
+	return count
+}
+
 // HasBusinessCode 检查调用链是否包含业务代码
 func (c CallChain) HasBusinessCode() bool {
 	for _, frame := range c.Frames {
@@ -149,9 +176,15 @@ func (c CallChain) HasBusinessCode() bool {
 // HotPath 热点路径
 type HotPath struct {
 	Chain          CallChain // 调用链
-	BusinessFrames []int     // 业务代码帧索引
-	RootCauseIndex int       // 根因帧索引 (-1 表示无业务代码)
+	BusinessFrames []int     // 业务代码帧在 Chain.Frames 中的索引，升序
+	RootCauseIndex int       // 根因帧在 Chain.Frames 中的索引，由 RootCausePolicy 决定；没有业务代码时取生成代码帧 (两者都没有时为 -1)
 	ProfileType    string    // profile 类型 (cpu/heap/goroutine)
+	Unit           ValueUnit // 消耗值的单位和 profile 总值，用于展示绝对值，单位未知时为零值
+}
+
+// FormatValue 返回热点路径的绝对消耗值，如 "13.2s"，单位未知时返回空字符串
+func (h HotPath) FormatValue() string {
+	return h.Unit.Format(h.Chain.TotalValue)
 }
 
 // GetRootCause 获取根因栈帧，如果没有业务代码则返回 nil
@@ -167,6 +200,8 @@ type ExecutableCmd struct {
 	Command     string // 命令内容
 	Description string // 命令说明
 	OutputHint  string // 输出解读提示
+	Kind        string // 命令种类，如 CommandKindFocus，手动构造的命令可以为空
+	Function    string // focus/list 命令针对的函数 (短名称)，其他命令为空
 }
 
 // Suggestion 建议
@@ -176,22 +211,91 @@ type Suggestion struct {
 }
 
 // ProblemContext 问题上下文
+// 由 ContextGenerator 为每个 Finding 生成，报告按 Finding.RuleID 关联
 type ProblemContext struct {
 	Title       string          // 问题标题
 	Severity    string          // 严重程度 (critical/high/medium/low)
 	Explanation string          // 通俗解释
 	Impact      string          // 影响评估
-	HotPaths    []HotPath       // 热点路径列表
+	HotPaths    []HotPath       // 热点路径列表，按消耗降序
 	Commands    []ExecutableCmd // 可执行命令
 	Suggestions []Suggestion    // 建议列表
+
+	// PrimaryCommand 推荐最先执行的命令，指向 Commands 中的一项的副本；没有合适命令时为 nil
+	PrimaryCommand *ExecutableCmd
+
+	// HiddenHotPaths 开启 HideRuntimeOnly 时被排除的没有业务代码的热点路径
+	HiddenHotPaths HiddenHotPaths
+
+	// Ownership 最新 heap 快照的存活内存归属树，仅 heap 问题且 profile 含 inuse_space 时非空
+	Ownership *OwnershipTree
+
+	// WindowShift 前后两个时间窗口间占比明显变化的热点路径，快照不足两个或没有明显变化时为 nil
+	WindowShift *WindowComparison
+}
+
+// HiddenHotPaths 被排除的没有业务代码的热点路径统计
+type HiddenHotPaths struct {
+	Count int       // 被排除的 (合并后) 调用链数
+	Value int64     // 被排除调用链的总消耗值
+	Pct   float64   // 被排除调用链的总消耗百分比 (0-100)
+	Unit  ValueUnit // Value 的单位，单位未知时为零值
 }
 
 // LocatorConfig 定位器配置
+// 零值可用，数值字段不大于 0 时使用 DefaultConfig 中的默认值
 type LocatorConfig struct {
 	ModuleName         string   // 用户模块名 (从 go.mod 读取或手动指定)
 	ThirdPartyPrefixes []string // 额外的第三方包前缀
 	MaxCallStackDepth  int      // 最大调用栈深度 (默认 10)
 	MaxHotPaths        int      // 最大热点路径数 (默认 5)
+	ReadableNames      bool     // 报告中使用格式化的函数展示名 (默认 false)
+	HideRuntimeOnly    bool     // 排除没有业务代码帧的热点路径，在剩余路径中取 top N (默认 false)
+
+	RootCausePolicy RootCausePolicy // 根因帧选择策略 (默认 deepest)
+
+	WindowPivot time.Time // 前后窗口对比的切分时间点，零值表示按快照数对半切分
+
+	CommandsTopOnly bool // 只为排名第一的热点路径生成 -focus/-list 命令 (默认 false)
+
+	CommandTemplate CommandTemplate // 生成命令的前缀、参数写法和模板，零值为 go tool pprof
+
+	ValueType string // 无法识别类型 (unknown) 的 profile 使用的 sample type，为空时使用第一个
+
+	CollapseRecursion bool // 将连续重复的递归帧折叠为一帧并标注次数，节省调用栈深度 (默认 false)
+
+	// Overrides 包路径前缀到分类的覆盖，优先于所有启发式判断，多条匹配时取最长的前缀；
+	// 可用 ParseClassifyOverrides 从 "前缀=分类,..." 解析，无效分类会被忽略
+	Overrides map[string]CodeCategory
+}
+
+// commandOptions 返回配置对应的命令生成选项
+func (c LocatorConfig) commandOptions() CommandOptions {
+	return CommandOptions{TopPathOnly: c.CommandsTopOnly, Template: c.CommandTemplate}
+}
+
+// RootCausePolicy 根因帧选择策略
+type RootCausePolicy string
+
+const (
+	RootCauseDeepest   RootCausePolicy = "deepest"   // 最深的业务代码帧（最接近热点）
+	RootCauseCostliest RootCausePolicy = "costliest" // 累计消耗最大的业务代码帧
+	RootCauseBoundary  RootCausePolicy = "boundary"  // 直接调用标准库/运行时的业务代码帧 (类别边界前的一帧)
+)
+
+// ParseRootCausePolicy 解析根因策略名称，空字符串视为 deepest
+func ParseRootCausePolicy(name string) (RootCausePolicy, error) {
+	switch RootCausePolicy(name) {
+	case "", RootCauseDeepest:
+		return RootCauseDeepest, nil
+	case RootCauseCostliest:
+		return RootCauseCostliest, nil
+	case RootCauseBoundary:
+		return RootCauseBoundary, nil
+	default:
+		return "", fmt.Errorf("invalid root cause policy '%s', must be '%s', '%s' or '%s'",
+			name, RootCauseDeepest, RootCauseCostliest, RootCauseBoundary)
+	}
 }
 
 // DefaultConfig 返回默认配置
@@ -201,5 +305,6 @@ func DefaultConfig() LocatorConfig {
 		ThirdPartyPrefixes: nil,
 		MaxCallStackDepth:  10,
 		MaxHotPaths:        5,
+		RootCausePolicy:    RootCauseDeepest,
 	}
 }
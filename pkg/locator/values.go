@@ -0,0 +1,83 @@
+package locator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/pprof/profile"
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+)
+
+// ValueUnit 热点路径消耗值的单位，用于在百分比旁展示绝对值，如 "45.0% = 13.2s / 29.3s"
+// 零值表示单位未知，此时只展示百分比
+type ValueUnit struct {
+	Unit  string // sample type 的单位，如 "nanoseconds"、"bytes"、"count"
+	Scale int64  // 样本值换算到 Unit 的倍数，只有按采样周期换算的 CPU profile 大于 1，0 视为 1
+	Total int64  // 参与分析的所有 profile 的样本值总和 (换算前)，百分比相对于该值
+}
+
+// Format 返回换算后的绝对值，如 "13.2s"、"412.0 MB"；单位未知时返回空字符串
+func (u ValueUnit) Format(value int64) string {
+	if u.Unit == "" {
+		return ""
+	}
+	if u.Scale > 1 {
+		value *= u.Scale
+	}
+	return FormatValue(value, u.Unit)
+}
+
+// FormatTotal 返回换算后的总值，单位未知时返回空字符串
+func (u ValueUnit) FormatTotal() string {
+	return u.Format(u.Total)
+}
+
+// FormatValue 按 sample type 的单位格式化样本值：纳秒格式化为时长，字节使用 analyzer.FormatBytes，
+// count 为千分位整数，其他单位附在数值后面
+func FormatValue(value int64, unit string) string {
+	switch unit {
+	case "nanoseconds":
+		return formatNanoseconds(value)
+	case "bytes":
+		return analyzer.FormatBytes(value)
+	case "count", "":
+		return analyzer.FormatInt(value)
+	default:
+		return analyzer.FormatInt(value) + " " + unit
+	}
+}
+
+// formatNanoseconds 将纳秒格式化为保留一位小数的时长，如 "13.2s"、"850.0ms"
+func formatNanoseconds(value int64) string {
+	d := time.Duration(value)
+	if d < 0 {
+		return "-" + formatNanoseconds(-value)
+	}
+	switch {
+	case d >= time.Second:
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	case d >= time.Millisecond:
+		return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+	case d >= time.Microsecond:
+		return fmt.Sprintf("%.1fµs", float64(d)/float64(time.Microsecond))
+	default:
+		return fmt.Sprintf("%dns", value)
+	}
+}
+
+// sampleValueUnit 返回 profile 第 valueIndex 个 sample type 的单位
+// 只有 samples/count 一列的 CPU profile 按 PeriodType (cpu/nanoseconds) 和 Period 换算为纳秒；
+// 与 analyzer.ProfileMetrics.NoDuration 一致，缺少采集时长的 CPU profile 不展示绝对 CPU 时间
+func sampleValueUnit(p *profile.Profile, valueIndex int, total int64) ValueUnit {
+	if p == nil || valueIndex < 0 || valueIndex >= len(p.SampleType) {
+		return ValueUnit{}
+	}
+	unit := ValueUnit{Unit: p.SampleType[valueIndex].Unit, Scale: 1, Total: total}
+	if pt := p.PeriodType; unit.Unit == "count" && pt != nil && pt.Type == "cpu" && pt.Unit == "nanoseconds" && p.Period > 0 {
+		unit.Unit, unit.Scale = "nanoseconds", p.Period
+	}
+	if unit.Unit == "nanoseconds" && p.DurationNanos == 0 {
+		return ValueUnit{}
+	}
+	return unit
+}
@@ -0,0 +1,114 @@
+package locator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		value    int64
+		unit     string
+		expected string
+	}{
+		{int64(13200 * time.Millisecond), "nanoseconds", "13.2s"},
+		{int64(850 * time.Millisecond), "nanoseconds", "850.0ms"},
+		{int64(12 * time.Microsecond), "nanoseconds", "12.0µs"},
+		{500, "nanoseconds", "500ns"},
+		{-int64(2 * time.Second), "nanoseconds", "-2.0s"},
+		{412 * 1024 * 1024, "bytes", "412 MB"},
+		{12345, "count", "12,345"},
+		{900, "cents", "900 cents"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, FormatValue(tt.value, tt.unit), "%d %s", tt.value, tt.unit)
+	}
+}
+
+func TestValueUnit_Format(t *testing.T) {
+	assert.Empty(t, ValueUnit{}.Format(100), "unknown unit shows only percentages")
+
+	unit := ValueUnit{Unit: "nanoseconds", Scale: int64(10 * time.Millisecond), Total: 2930}
+	assert.Equal(t, "13.2s", unit.Format(1320))
+	assert.Equal(t, "29.3s", unit.FormatTotal())
+
+	assert.Equal(t, "1.00 KB", ValueUnit{Unit: "bytes"}.Format(1024), "zero scale is treated as 1")
+}
+
+func TestSampleValueUnit(t *testing.T) {
+	cpu := &profile.Profile{
+		SampleType:    []*profile.ValueType{{Type: "samples", Unit: "count"}, {Type: "cpu", Unit: "nanoseconds"}},
+		DurationNanos: int64(30 * time.Second),
+	}
+	assert.Equal(t, ValueUnit{Unit: "nanoseconds", Scale: 1, Total: 42}, sampleValueUnit(cpu, 1, 42))
+
+	// 只有 samples/count 一列的 CPU profile 按采样周期换算
+	counts := &profile.Profile{
+		SampleType:    []*profile.ValueType{{Type: "samples", Unit: "count"}},
+		PeriodType:    &profile.ValueType{Type: "cpu", Unit: "nanoseconds"},
+		Period:        10000000,
+		DurationNanos: int64(30 * time.Second),
+	}
+	assert.Equal(t, ValueUnit{Unit: "nanoseconds", Scale: 10000000, Total: 42}, sampleValueUnit(counts, 0, 42))
+
+	// 缺少采集时长时不展示绝对 CPU 时间
+	cpu.DurationNanos = 0
+	assert.Equal(t, ValueUnit{}, sampleValueUnit(cpu, 1, 42))
+
+	goroutines := &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "goroutine", Unit: "count"}},
+		PeriodType: &profile.ValueType{Type: "goroutine", Unit: "count"},
+		Period:     1,
+	}
+	assert.Equal(t, ValueUnit{Unit: "count", Scale: 1, Total: 7}, sampleValueUnit(goroutines, 0, 7))
+
+	assert.Equal(t, ValueUnit{}, sampleValueUnit(goroutines, 3, 7), "out of range index")
+	assert.Equal(t, ValueUnit{}, sampleValueUnit(nil, 0, 7))
+}
+
+func TestAnalyzeHotPaths_Unit(t *testing.T) {
+	config := LocatorConfig{ModuleName: "github.com/myapp", MaxCallStackDepth: 10, MaxHotPaths: 1, HideRuntimeOnly: true}
+	pa := NewPathAnalyzer(NewExtractor(NewClassifier(config)), config)
+
+	p := createTestProfile([]*profile.Sample{
+		createTestSample([]string{"github.com/myapp/cache.Load", "runtime.mallocgc"}, 3<<20, nil),
+		createTestSample([]string{"github.com/myapp/cache.Store", "runtime.mallocgc"}, 1<<20, nil),
+		createTestSample([]string{"runtime.gcBgMarkWorker"}, 4<<20, nil),
+	})
+	p.SampleType = []*profile.ValueType{{Type: "inuse_space", Unit: "bytes"}}
+
+	hotPaths, hidden, err := pa.analyzeHotPaths(context.Background(), p, "heap")
+	require.NoError(t, err)
+	require.Len(t, hotPaths, 1)
+	assert.Equal(t, ValueUnit{Unit: "bytes", Scale: 1, Total: 8 << 20}, hotPaths[0].Unit)
+	assert.Equal(t, "3.00 MB", hotPaths[0].FormatValue())
+	assert.Equal(t, "4.00 MB", hidden.Unit.Format(hidden.Value))
+
+	// 多个 profile 的总值相加
+	hotPaths, _, err = pa.analyzeMultipleProfiles(context.Background(), []*profile.Profile{p, p}, "heap")
+	require.NoError(t, err)
+	require.Len(t, hotPaths, 1)
+	assert.Equal(t, int64(16<<20), hotPaths[0].Unit.Total)
+	assert.Equal(t, "6.00 MB", hotPaths[0].FormatValue())
+}
+
+func TestGenerateImpact_AbsoluteValues(t *testing.T) {
+	unit := ValueUnit{Unit: "nanoseconds", Scale: 1, Total: int64(29300 * time.Millisecond)}
+	hotPaths := []HotPath{
+		{Chain: CallChain{TotalValue: int64(13200 * time.Millisecond), TotalPct: 45.05}, RootCauseIndex: -1, Unit: unit},
+		{Chain: CallChain{TotalValue: int64(2 * time.Second), TotalPct: 6.83}, RootCauseIndex: -1, Unit: unit},
+	}
+
+	impact := GenerateImpact(hotPaths, "cpu")
+	assert.Contains(t, impact, "主要消耗点占用 45.0% (13.2s / 29.3s) 的 CPU 时间")
+	assert.Contains(t, impact, "前 2 个热点路径共占用 51.9% (15.2s) 的 CPU 时间")
+
+	// 单位未知时只展示百分比
+	hotPaths[0].Unit = ValueUnit{}
+	assert.Contains(t, GenerateImpact(hotPaths[:1], "cpu"), "主要消耗点占用 45.0% 的 CPU 时间")
+}
@@ -0,0 +1,155 @@
+package locator
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/pprof/profile"
+)
+
+// DefaultWindowShiftMinPct 路径占比在前后两个窗口间变化至少 5 个百分点才视为迁移
+const DefaultWindowShiftMinPct = 5.0
+
+// PathShift 同一条 (合并后) 调用路径在前后两个时间窗口中的占比变化
+type PathShift struct {
+	Chain     CallChain // 调用路径，取后一窗口中的样本；后一窗口中消失的路径取前一窗口
+	BeforePct float64   // 在前一窗口总消耗中的占比 (0-100)
+	AfterPct  float64   // 在后一窗口总消耗中的占比 (0-100)
+	DeltaPct  float64   // AfterPct - BeforePct，单位为百分点
+}
+
+// Frame 返回用于展示的代表帧：最深的业务代码帧，没有业务代码时为叶子帧
+func (s PathShift) Frame() StackFrame {
+	for i := len(s.Chain.Frames) - 1; i >= 0; i-- {
+		if s.Chain.Frames[i].Category == CategoryBusiness {
+			return s.Chain.Frames[i]
+		}
+	}
+	if len(s.Chain.Frames) == 0 {
+		return StackFrame{}
+	}
+	return s.Chain.Frames[len(s.Chain.Frames)-1]
+}
+
+// WindowComparison 同一组快照前后两个时间窗口的热点路径对比
+type WindowComparison struct {
+	Pivot  time.Time   // 切分时间点，后一窗口的快照采集时间不早于它；快照没有时间戳时为零值
+	Before int         // 前一窗口的快照数
+	After  int         // 后一窗口的快照数
+	Grew   []PathShift // 占比上升的路径，按 DeltaPct 降序
+	Shrank []PathShift // 占比下降的路径，按 DeltaPct 升序 (下降最多的在前)
+}
+
+// Empty 是否没有发生迁移的路径
+func (c *WindowComparison) Empty() bool {
+	return c == nil || (len(c.Grew) == 0 && len(c.Shrank) == 0)
+}
+
+// SplitWindows 在 pivot 处把按时间排序的快照分成前后两个窗口
+// pivot 为零值时按快照数对半切分 (奇数个时后一窗口多一个)，返回的切分时间点为后一窗口第一个快照的采集时间；
+// 否则采集时间早于 pivot 的快照归入前一窗口。nil 快照被忽略
+func SplitWindows(profiles []*profile.Profile, pivot time.Time) (before, after []*profile.Profile, split time.Time) {
+	var valid []*profile.Profile
+	for _, p := range profiles {
+		if p != nil {
+			valid = append(valid, p)
+		}
+	}
+
+	if pivot.IsZero() {
+		mid := len(valid) / 2
+		before, after = valid[:mid], valid[mid:]
+		if len(after) > 0 && after[0].TimeNanos > 0 {
+			split = time.Unix(0, after[0].TimeNanos)
+		}
+		return before, after, split
+	}
+
+	for _, p := range valid {
+		if time.Unix(0, p.TimeNanos).Before(pivot) {
+			before = append(before, p)
+		} else {
+			after = append(after, p)
+		}
+	}
+	return before, after, pivot
+}
+
+// CompareWindows 对比同一组快照前后两个时间窗口的合并热点路径
+// 趋势线只能看出总量变化，单个快照只能看到某一时刻的热点；这里按窗口分别合并调用链，
+// 比较每条路径在各自窗口总消耗中的占比，找出 "热点从 A 转移到了 B"。
+// 占比变化不足 DefaultWindowShiftMinPct 个百分点的路径被忽略，Grew 和 Shrank 各最多 MaxHotPaths 条。
+// 切分见 SplitWindows；任一窗口为空时返回 nil
+func (a *PathAnalyzer) CompareWindows(profiles []*profile.Profile, profileType string, pivot time.Time) *WindowComparison {
+	comparison, _ := a.CompareWindowsCtx(context.Background(), profiles, profileType, pivot)
+	return comparison
+}
+
+// CompareWindowsCtx 与 CompareWindows 相同，但在逐样本循环中检查 ctx
+// ctx 取消或超时时返回 nil 和 ctx.Err()
+func (a *PathAnalyzer) CompareWindowsCtx(ctx context.Context, profiles []*profile.Profile, profileType string, pivot time.Time) (*WindowComparison, error) {
+	before, after, split := SplitWindows(profiles, pivot)
+	if len(before) == 0 || len(after) == 0 {
+		return nil, nil
+	}
+
+	beforeChains, _, _, err := a.aggregateProfiles(ctx, before, profileType)
+	if err != nil {
+		return nil, err
+	}
+	afterChains, _, _, err := a.aggregateProfiles(ctx, after, profileType)
+	if err != nil {
+		return nil, err
+	}
+
+	shifts := make(map[string]*PathShift)
+	for _, chain := range beforeChains {
+		shifts[generateSmartCallChainKey(chain.Frames)] = &PathShift{Chain: chain, BeforePct: chain.TotalPct}
+	}
+	for _, chain := range afterChains {
+		key := generateSmartCallChainKey(chain.Frames)
+		shift, ok := shifts[key]
+		if !ok {
+			shift = &PathShift{}
+			shifts[key] = shift
+		}
+		shift.Chain = chain
+		shift.AfterPct = chain.TotalPct
+	}
+
+	comparison := &WindowComparison{Pivot: split, Before: len(before), After: len(after)}
+	for _, shift := range shifts {
+		shift.DeltaPct = shift.AfterPct - shift.BeforePct
+		if math.Abs(shift.DeltaPct) < DefaultWindowShiftMinPct {
+			continue
+		}
+		if shift.DeltaPct > 0 {
+			comparison.Grew = append(comparison.Grew, *shift)
+		} else {
+			comparison.Shrank = append(comparison.Shrank, *shift)
+		}
+	}
+
+	sortShifts(comparison.Grew, func(s PathShift) float64 { return -s.DeltaPct })
+	sortShifts(comparison.Shrank, func(s PathShift) float64 { return s.DeltaPct })
+	if len(comparison.Grew) > a.config.MaxHotPaths {
+		comparison.Grew = comparison.Grew[:a.config.MaxHotPaths]
+	}
+	if len(comparison.Shrank) > a.config.MaxHotPaths {
+		comparison.Shrank = comparison.Shrank[:a.config.MaxHotPaths]
+	}
+	return comparison, nil
+}
+
+// sortShifts 按 key 升序排列，key 相同时按调用路径标识排序保证输出稳定
+func sortShifts(shifts []PathShift, key func(PathShift) float64) {
+	sort.Slice(shifts, func(i, j int) bool {
+		ki, kj := key(shifts[i]), key(shifts[j])
+		if ki != kj {
+			return ki < kj
+		}
+		return generateSmartCallChainKey(shifts[i].Chain.Frames) < generateSmartCallChainKey(shifts[j].Chain.Frames)
+	})
+}
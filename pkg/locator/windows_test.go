@@ -0,0 +1,175 @@
+package locator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/pprof/profile"
+	"github.com/songzhibin97/perfinspector/pkg/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var windowBase = time.Date(2023, 11, 15, 14, 0, 0, 0, time.UTC)
+
+// newWindowProfile creates a snapshot at windowBase+minute splitting its samples between two handlers
+func newWindowProfile(minute int, orders, reports int64) *profile.Profile {
+	p := createTestProfile([]*profile.Sample{
+		createTestSample([]string{"main.main", "github.com/myapp/orders.Handle", "runtime.mallocgc"}, orders, nil),
+		createTestSample([]string{"main.main", "github.com/myapp/reports.Build", "runtime.mallocgc"}, reports, nil),
+	})
+	p.TimeNanos = windowBase.Add(time.Duration(minute) * time.Minute).UnixNano()
+	return p
+}
+
+// newWindowAnalyzer creates a path analyzer for the window comparison tests
+func newWindowAnalyzer() *PathAnalyzer {
+	config := LocatorConfig{ModuleName: "github.com/myapp"}
+	return NewPathAnalyzer(NewExtractor(NewClassifier(config)), config)
+}
+
+// TestSplitWindows tests splitting snapshots at the midpoint or at a supplied pivot
+func TestSplitWindows(t *testing.T) {
+	profiles := []*profile.Profile{newWindowProfile(0, 1, 1), nil, newWindowProfile(1, 1, 1), newWindowProfile(2, 1, 1)}
+
+	before, after, split := SplitWindows(profiles, time.Time{})
+	assert.Len(t, before, 1)
+	assert.Len(t, after, 2)
+	assert.Equal(t, windowBase.Add(time.Minute), split.UTC())
+
+	pivot := windowBase.Add(90 * time.Second)
+	before, after, split = SplitWindows(profiles, pivot)
+	assert.Len(t, before, 2)
+	assert.Len(t, after, 1)
+	assert.Equal(t, pivot, split)
+
+	// 没有时间戳时对半切分，不给出切分时间
+	untimed := []*profile.Profile{createTestProfile(nil), createTestProfile(nil)}
+	before, after, split = SplitWindows(untimed, time.Time{})
+	assert.Len(t, before, 1)
+	assert.Len(t, after, 1)
+	assert.True(t, split.IsZero())
+}
+
+// TestCompareWindows tests detecting a hotspot that moved between windows
+func TestCompareWindows(t *testing.T) {
+	profiles := []*profile.Profile{
+		newWindowProfile(0, 80, 20),
+		newWindowProfile(1, 80, 20),
+		newWindowProfile(2, 30, 70),
+		newWindowProfile(3, 10, 90),
+	}
+
+	comparison := newWindowAnalyzer().CompareWindows(profiles, "cpu", time.Time{})
+	require.NotNil(t, comparison)
+	assert.Equal(t, 2, comparison.Before)
+	assert.Equal(t, 2, comparison.After)
+	assert.Equal(t, windowBase.Add(2*time.Minute), comparison.Pivot.UTC())
+
+	require.Len(t, comparison.Grew, 1)
+	grew := comparison.Grew[0]
+	assert.Equal(t, "github.com/myapp/reports.Build", grew.Frame().FunctionName)
+	assert.InDelta(t, 20, grew.BeforePct, 0.01)
+	assert.InDelta(t, 80, grew.AfterPct, 0.01)
+	assert.InDelta(t, 60, grew.DeltaPct, 0.01)
+
+	require.Len(t, comparison.Shrank, 1)
+	assert.Equal(t, "github.com/myapp/orders.Handle", comparison.Shrank[0].Frame().FunctionName)
+	assert.InDelta(t, -60, comparison.Shrank[0].DeltaPct, 0.01)
+
+	// 在第一个快照之后切分，前一窗口只有一个快照
+	comparison = newWindowAnalyzer().CompareWindows(profiles, "cpu", windowBase.Add(30*time.Second))
+	require.NotNil(t, comparison)
+	assert.Equal(t, 1, comparison.Before)
+	assert.Equal(t, 3, comparison.After)
+	assert.False(t, comparison.Empty())
+}
+
+// TestCompareWindows_NewAndVanishedPaths tests paths present in only one window
+func TestCompareWindows_NewAndVanishedPaths(t *testing.T) {
+	before := createTestProfile([]*profile.Sample{
+		createTestSample([]string{"main.main", "github.com/myapp/orders.Handle"}, 100, nil),
+	})
+	after := createTestProfile([]*profile.Sample{
+		createTestSample([]string{"main.main", "github.com/myapp/reports.Build"}, 100, nil),
+	})
+
+	comparison := newWindowAnalyzer().CompareWindows([]*profile.Profile{before, after}, "cpu", time.Time{})
+	require.NotNil(t, comparison)
+	require.Len(t, comparison.Grew, 1)
+	require.Len(t, comparison.Shrank, 1)
+	assert.Equal(t, 0.0, comparison.Grew[0].BeforePct)
+	assert.Equal(t, 0.0, comparison.Shrank[0].AfterPct)
+	assert.Equal(t, "github.com/myapp/orders.Handle", comparison.Shrank[0].Frame().FunctionName)
+}
+
+// TestCompareWindows_NoShift tests that small changes and empty windows are ignored
+func TestCompareWindows_NoShift(t *testing.T) {
+	pa := newWindowAnalyzer()
+	stable := []*profile.Profile{newWindowProfile(0, 50, 50), newWindowProfile(1, 52, 48)}
+
+	comparison := pa.CompareWindows(stable, "cpu", time.Time{})
+	require.NotNil(t, comparison)
+	assert.True(t, comparison.Empty())
+
+	// pivot 早于所有快照时前一窗口为空
+	assert.Nil(t, pa.CompareWindows(stable, "cpu", windowBase.Add(-time.Hour)))
+	assert.Nil(t, pa.CompareWindows(stable[:1], "cpu", time.Time{}))
+	assert.True(t, (*WindowComparison)(nil).Empty())
+}
+
+// TestCompareWindows_Limit tests that each direction keeps at most MaxHotPaths paths
+func TestCompareWindows_Limit(t *testing.T) {
+	config := LocatorConfig{ModuleName: "github.com/myapp", MaxHotPaths: 1}
+	pa := NewPathAnalyzer(NewExtractor(NewClassifier(config)), config)
+
+	before := createTestProfile([]*profile.Sample{
+		createTestSample([]string{"main.main", "github.com/myapp/a.Run"}, 60, nil),
+		createTestSample([]string{"main.main", "github.com/myapp/b.Run"}, 40, nil),
+	})
+	after := createTestProfile([]*profile.Sample{
+		createTestSample([]string{"main.main", "github.com/myapp/c.Run"}, 70, nil),
+		createTestSample([]string{"main.main", "github.com/myapp/d.Run"}, 30, nil),
+	})
+
+	comparison := pa.CompareWindows([]*profile.Profile{before, after}, "cpu", time.Time{})
+	require.NotNil(t, comparison)
+	require.Len(t, comparison.Grew, 1)
+	require.Len(t, comparison.Shrank, 1)
+	assert.Equal(t, "github.com/myapp/c.Run", comparison.Grew[0].Frame().FunctionName)
+	assert.Equal(t, "github.com/myapp/a.Run", comparison.Shrank[0].Frame().FunctionName)
+}
+
+// TestCompareWindowsCtx tests that a canceled context aborts the comparison
+func TestCompareWindowsCtx(t *testing.T) {
+	profiles := []*profile.Profile{newWindowProfile(0, 80, 20), newWindowProfile(1, 20, 80)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	comparison, err := newWindowAnalyzer().CompareWindowsCtx(ctx, profiles, "cpu", time.Time{})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, comparison)
+}
+
+// TestGenerateContext_WindowShift tests that multi-snapshot contexts include the window comparison
+func TestGenerateContext_WindowShift(t *testing.T) {
+	config := LocatorConfig{ModuleName: "github.com/myapp"}
+	generator := NewContextGeneratorFromConfig(config)
+	finding := rules.Finding{RuleID: "cpu_rule", Title: "CPU 热点", Severity: "high", ProfileTypes: []string{"cpu"}}
+
+	shifted := map[string][]*profile.Profile{
+		"cpu": {newWindowProfile(0, 80, 20), newWindowProfile(1, 20, 80)},
+	}
+	problem := generator.GenerateContextWithAllProfiles(finding, nil, shifted, nil)
+	require.NotNil(t, problem)
+	require.NotNil(t, problem.WindowShift)
+	assert.Len(t, problem.WindowShift.Grew, 1)
+
+	stable := map[string][]*profile.Profile{
+		"cpu": {newWindowProfile(0, 50, 50), newWindowProfile(1, 50, 50)},
+	}
+	problem = generator.GenerateContextWithAllProfiles(finding, nil, stable, nil)
+	require.NotNil(t, problem)
+	assert.Nil(t, problem.WindowShift)
+}
@@ -0,0 +1,136 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+)
+
+// sniffSize 嗅探时最多检查的 (解压后) 字节数
+const sniffSize = 4096
+
+// gzipMagic gzip 文件头
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// profileWireTypes pprof Profile 消息各字段允许的 wire type
+// 0 为 varint，2 为 length-delimited；comment (13) 可能是 packed 编码
+var profileWireTypes = map[uint64][]uint64{
+	1:  {2},    // sample_type
+	2:  {2},    // sample
+	3:  {2},    // mapping
+	4:  {2},    // location
+	5:  {2},    // function
+	6:  {2},    // string_table
+	7:  {0},    // drop_frames
+	8:  {0},    // keep_frames
+	9:  {0},    // time_nanos
+	10: {0},    // duration_nanos
+	11: {2},    // period_type
+	12: {0},    // period
+	13: {0, 2}, // comment
+	14: {0},    // default_sample_type
+}
+
+// SniffProfileFile 通过文件头判断文件是否像 pprof profile，用于识别没有扩展名的文件
+// 无法打开或读取的文件返回 false
+func SniffProfileFile(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	return SniffProfile(f)
+}
+
+// SniffProfile 检查数据开头是否为 pprof 格式 (可选 gzip 压缩的 protobuf)
+// 只检查开头 sniffSize 字节内的字段编号和 wire type 是否符合 Profile 消息定义，不做完整解析
+func SniffProfile(r io.Reader) bool {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(len(gzipMagic))
+	if err != nil {
+		return false
+	}
+
+	var data io.Reader = br
+	if bytes.Equal(header, gzipMagic) {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return false
+		}
+		defer zr.Close()
+		data = zr
+	}
+
+	buf := make([]byte, sniffSize)
+	n, err := io.ReadFull(data, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return false
+	}
+	// 读满缓冲区说明数据可能更长，字段在结尾截断是正常的
+	return sniffProfileFields(buf[:n], n < sniffSize)
+}
+
+// sniffProfileFields 依次检查 protobuf 字段
+// complete 表示 data 是完整的数据，此时字段不允许截断；否则字段内容超出嗅探范围时以已检查的部分为准
+// 至少需要两个合法字段，避免偶然以合法字节开头的文本被误判
+func sniffProfileFields(data []byte, complete bool) bool {
+	fields := 0
+	for len(data) > 0 {
+		tag, n := readVarint(data)
+		if n == 0 {
+			return !complete && fields >= 2
+		}
+		data = data[n:]
+
+		wireTypes, ok := profileWireTypes[tag>>3]
+		if !ok || !containsWireType(wireTypes, tag&0x7) {
+			return false
+		}
+
+		switch tag & 0x7 {
+		case 0:
+			_, n = readVarint(data)
+			if n == 0 {
+				return !complete
+			}
+			data = data[n:]
+		case 2:
+			length, n := readVarint(data)
+			if n == 0 {
+				return !complete
+			}
+			data = data[n:]
+			if length > uint64(len(data)) {
+				// 字段内容超出嗅探范围，标签合法即可
+				return !complete
+			}
+			data = data[length:]
+		}
+		fields++
+	}
+	return fields >= 2
+}
+
+// readVarint 解码 varint，返回值和消耗的字节数；数据不完整或溢出时字节数为 0
+func readVarint(data []byte) (uint64, int) {
+	var value uint64
+	for i := 0; i < len(data) && i < 10; i++ {
+		value |= uint64(data[i]&0x7f) << (7 * uint(i))
+		if data[i] < 0x80 {
+			return value, i + 1
+		}
+	}
+	return 0, 0
+}
+
+// containsWireType 检查 wire type 是否在允许列表中
+func containsWireType(allowed []uint64, wireType uint64) bool {
+	for _, t := range allowed {
+		if t == wireType {
+			return true
+		}
+	}
+	return false
+}
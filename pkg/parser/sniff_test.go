@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeRuntimeProfile 输出当前进程的 profile (gzip 压缩)
+func writeRuntimeProfile(t *testing.T, name string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, pprof.Lookup(name).WriteTo(&buf, 0))
+	return buf.Bytes()
+}
+
+func TestSniffProfile(t *testing.T) {
+	for _, name := range []string{"heap", "goroutine", "block"} {
+		t.Run(name, func(t *testing.T) {
+			data := writeRuntimeProfile(t, name)
+			assert.True(t, SniffProfile(bytes.NewReader(data)), "gzip")
+
+			// 未压缩的 protobuf (google/pprof 先编码 sample_type)
+			p, err := profile.ParseData(data)
+			require.NoError(t, err)
+			var raw bytes.Buffer
+			require.NoError(t, p.WriteUncompressed(&raw))
+			assert.True(t, SniffProfile(&raw), "uncompressed")
+		})
+	}
+}
+
+func TestSniffProfile_Rejects(t *testing.T) {
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	_, err := zw.Write([]byte("2024-01-01 INFO server started\n"))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	tests := map[string][]byte{
+		"empty":       nil,
+		"text":        []byte("hello world, this is not a profile"),
+		"json":        []byte(`{"type":"heap"}`),
+		"gzip text":   gz.Bytes(),
+		"broken gzip": {0x1f, 0x8b, 0x00},
+		"elf":         {0x7f, 'E', 'L', 'F', 2, 1, 1, 0},
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.False(t, SniffProfile(bytes.NewReader(data)))
+		})
+	}
+}
+
+func TestSniffProfileFile(t *testing.T) {
+	dir := t.TempDir()
+	profilePath := filepath.Join(dir, "3f9a2c")
+	require.NoError(t, os.WriteFile(profilePath, writeRuntimeProfile(t, "goroutine"), 0644))
+	textPath := filepath.Join(dir, "README")
+	require.NoError(t, os.WriteFile(textPath, []byte("notes"), 0644))
+
+	assert.True(t, SniffProfileFile(profilePath))
+	assert.False(t, SniffProfileFile(textPath))
+	assert.False(t, SniffProfileFile(filepath.Join(dir, "missing")))
+}
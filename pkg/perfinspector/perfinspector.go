@@ -0,0 +1,358 @@
+// Package perfinspector 提供 PerfInspector 的库接口，便于在其他服务中嵌入分析流程
+// 命令行工具是这些函数的薄封装，两者的分析结果一致
+package perfinspector
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/google/pprof/profile"
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+	"github.com/songzhibin97/perfinspector/pkg/locator"
+	"github.com/songzhibin97/perfinspector/pkg/rules"
+)
+
+// Options 分析选项，与命令行参数一一对应
+type Options struct {
+	// 解析
+	Concurrency     int    // 并行解析文件、提取指标和定位问题的最大 goroutine 数，小于 1 时按 1 处理
+	OrderByFilename bool   // 组内文件按文件名序号而不是采集时间排序
+	ValueType       string // 无法识别类型的 profile 使用的 sample type，为空时使用第一个
+	Dedup           bool   // 标记组内指标相同的连续快照，只保留最早的一个参与趋势计算
+	// Window 只分析采集时间在窗口内的快照，零值表示不限制
+	Window analyzer.TimeWindow
+
+	// 基准测试模式
+	Bench  bool  // 过滤 testing 框架帧，按每次操作展示消耗
+	BenchN int64 // 基准测试迭代次数，0 表示未知
+
+	// 问题定位
+	ModuleName         string                          // 用户模块名，为空时从当前目录的 go.mod 检测
+	ThirdPartyPrefixes []string                        // 额外的第三方包前缀
+	ClassifyOverrides  map[string]locator.CodeCategory // 包路径前缀到分类的覆盖
+	StackDepth         int                             // 最大调用栈深度
+	HotPaths           int                             // 最大热点路径数
+	ReadableNames      bool                            // 使用格式化的函数展示名
+	HideRuntimeOnly    bool                            // 排除没有业务代码的热点路径
+	CollapseRecursion  bool                            // 折叠调用链中连续重复的递归帧
+	RootCausePolicy    locator.RootCausePolicy         // 根因帧选择策略，为空时使用 deepest
+	WindowPivot        time.Time                       // 热点迁移对比的切分时间点，零值表示按快照数对半切分
+	CommandsTopOnly    bool                            // 只为排名第一的热点路径生成 -focus/-list 命令
+	CommandTemplate    locator.CommandTemplate         // 生成命令的前缀、参数写法和模板
+
+	// 趋势和规则
+	HeapSampleType string // heap 趋势使用的 sample type，为空时使用 inuse_space
+	MinTrendPoints int    // 计算趋势和评估趋势规则所需的最少快照数，<= 0 时使用 analyzer.DefaultMinTrendPoints
+	RulesPath      string // 规则文件路径，多个文件用逗号分隔，为空时不评估规则
+}
+
+// DefaultOptions 返回与命令行默认参数一致的选项 (规则文件路径除外)
+func DefaultOptions() Options {
+	defaults := locator.DefaultConfig()
+	return Options{
+		Concurrency:     runtime.GOMAXPROCS(0),
+		StackDepth:      defaults.MaxCallStackDepth,
+		HotPaths:        defaults.MaxHotPaths,
+		RootCausePolicy: defaults.RootCausePolicy,
+		HeapSampleType:  analyzer.HeapSampleInuseSpace,
+		MinTrendPoints:  analyzer.DefaultMinTrendPoints,
+	}
+}
+
+// LocatorConfig 返回问题定位使用的配置
+// 未指定模块名时尝试从当前目录的 go.mod 检测；第三方包前缀未经规范化，调用方可以用 NormalizeThirdPartyPrefixes 获取警告
+func (o Options) LocatorConfig() locator.LocatorConfig {
+	config := locator.DefaultConfig()
+
+	if o.ModuleName != "" {
+		config.ModuleName = o.ModuleName
+	} else if moduleName, err := locator.DetectModuleName("."); err == nil {
+		config.ModuleName = moduleName
+	}
+
+	if len(o.ThirdPartyPrefixes) > 0 {
+		config.ThirdPartyPrefixes = o.ThirdPartyPrefixes
+	}
+	config.Overrides = o.ClassifyOverrides
+
+	config.MaxCallStackDepth = o.StackDepth
+	config.MaxHotPaths = o.HotPaths
+	config.ReadableNames = o.ReadableNames
+	config.HideRuntimeOnly = o.HideRuntimeOnly
+	config.CollapseRecursion = o.CollapseRecursion
+	if o.RootCausePolicy != "" {
+		config.RootCausePolicy = o.RootCausePolicy
+	}
+	config.WindowPivot = o.WindowPivot
+	config.CommandsTopOnly = o.CommandsTopOnly
+	config.CommandTemplate = o.CommandTemplate
+	config.ValueType = o.ValueType
+
+	return config
+}
+
+// Result 一次分析的结果
+type Result struct {
+	Groups    []analyzer.ProfileGroup
+	Trends    map[string]*analyzer.GroupTrends
+	Findings  []rules.Finding
+	Contexts  map[string]*locator.ProblemContext // 按 Finding.RuleID 索引的问题上下文，超时或定位失败的发现没有上下文
+	RuleStats rules.EvaluationStats
+	Warnings  []string // 不影响分析结果的警告，如时钟偏差、规则加载失败
+}
+
+// ValueTypeError 自定义 profile 中缺少 Options.ValueType 指定的 sample type
+type ValueTypeError struct {
+	ValueType string
+	Err       error // 缺少该 sample type 的文件和可用的 sample type
+}
+
+func (e *ValueTypeError) Error() string {
+	return fmt.Sprintf("value type: %v", e.Err)
+}
+
+func (e *ValueTypeError) Unwrap() error {
+	return e.Err
+}
+
+// Context 返回发现对应的问题上下文，没有时返回 nil
+func (r *Result) Context(finding rules.Finding) *locator.ProblemContext {
+	if r == nil || r.Contexts == nil {
+		return nil
+	}
+	return r.Contexts[finding.RuleID]
+}
+
+// Analyze 解析 paths 指定的 profile 文件，计算趋势、评估规则并定位问题
+func Analyze(paths []string, opts Options) (*Result, error) {
+	return AnalyzeCtx(context.Background(), paths, opts)
+}
+
+// AnalyzeCtx 同 Analyze，ctx 取消或超时后解析失败返回错误；定位问题阶段超时则保留已完成的上下文并记录警告
+func AnalyzeCtx(ctx context.Context, paths []string, opts Options) (*Result, error) {
+	loaded, err := LoadCtx(ctx, paths, opts)
+	if err != nil {
+		return nil, err
+	}
+	result := AnalyzeGroupsCtx(ctx, loaded.Groups, opts)
+	result.Warnings = append(loaded.Warnings, result.Warnings...)
+	return result, nil
+}
+
+// Load 解析 profile 文件并按类型分组，应用文件排序、sample type 和基准测试模式，不计算趋势和评估规则
+// 返回的 Result 只填充 Groups 和 Warnings
+func Load(paths []string, opts Options) (*Result, error) {
+	return LoadCtx(context.Background(), paths, opts)
+}
+
+// LoadCtx 同 Load，ctx 取消或超时后返回错误
+func LoadCtx(ctx context.Context, paths []string, opts Options) (*Result, error) {
+	groups, err := analyzer.GroupProfilesCtx(ctx, paths, opts.Concurrency)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{Groups: groups}
+
+	// 长时间采集时只关心事故窗口，过滤后趋势只反映窗口内的快照
+	if !opts.Window.IsZero() {
+		before := make(map[string]int, len(groups))
+		for _, group := range groups {
+			before[group.Type] = len(group.Files)
+		}
+		var excluded int
+		groups, excluded = analyzer.FilterTimeWindow(groups, opts.Window)
+		if len(groups) == 0 {
+			return nil, fmt.Errorf("no profiles in window %s (%d profiles outside the window)", opts.Window, excluded)
+		}
+		result.Groups = groups
+		if excluded > 0 {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("时间窗口 %s 之外的 %d 个快照已排除", opts.Window, excluded))
+		}
+		// 只提示因窗口过滤而不足以计算趋势的组
+		minPoints := analyzer.MinTrendPoints(opts.MinTrendPoints)
+		for _, group := range groups {
+			if len(group.Files) < minPoints && before[group.Type] >= minPoints {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("%s 组在时间窗口内只有 %d 个快照，少于 %d 个，不计算趋势", group.Type, len(group.Files), minPoints))
+			}
+		}
+	}
+
+	// 采集主机时钟偏差会让按时间排序的趋势颠倒，可以改为按文件名序号排序
+	if opts.OrderByFilename {
+		for _, groupType := range analyzer.OrderByFilename(groups) {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s 组的文件名没有完整且不重复的序号，仍按采集时间排序", groupType))
+		}
+	} else {
+		for _, skew := range analyzer.DetectClockSkew(groups) {
+			result.Warnings = append(result.Warnings, clockSkewWarning(skew))
+		}
+	}
+
+	// 自定义 profile 按指定的 sample type 计算指标
+	if err := analyzer.ApplyValueType(groups, opts.ValueType); err != nil {
+		return nil, &ValueTypeError{ValueType: opts.ValueType, Err: err}
+	}
+	if opts.ValueType != "" && !hasGroup(groups, "unknown") {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("value type %q 只作用于无法识别类型的 profile，当前输入中没有这类 profile", opts.ValueType))
+	}
+
+	// 基准测试模式：去掉 testing 框架开销，换算为每次操作的消耗
+	if opts.Bench {
+		analyzer.ApplyBenchMode(groups, opts.BenchN)
+	}
+
+	// 重复快照仍在报告中列出，只是不参与趋势计算
+	if opts.Dedup {
+		for _, group := range groups {
+			if n := analyzer.MarkDuplicateFiles(group.Files, analyzer.DefaultDedupTolerance); n > 0 {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("%s 组有 %d 个快照与之前的快照指标相同，已从趋势计算中排除", group.Type, n))
+			}
+		}
+	}
+	return result, nil
+}
+
+// AnalyzeGroups 对已加载的分组计算趋势、评估规则并定位问题
+func AnalyzeGroups(groups []analyzer.ProfileGroup, opts Options) *Result {
+	return AnalyzeGroupsCtx(context.Background(), groups, opts)
+}
+
+// AnalyzeGroupsCtx 同 AnalyzeGroups，ctx 取消或超时后停止定位问题，已完成的上下文保留在结果中
+// 规则文件加载失败和定位未完成只记录警告，其余分析照常进行
+func AnalyzeGroupsCtx(ctx context.Context, groups []analyzer.ProfileGroup, opts Options) *Result {
+	result := &Result{Groups: groups, Trends: make(map[string]*analyzer.GroupTrends)}
+
+	locatorConfig := opts.LocatorConfig()
+	result.Warnings = append(result.Warnings, locatorConfig.NormalizeThirdPartyPrefixes()...)
+
+	// 按代码分类汇总每个文件的样本值，用于 HTML 报告的分类堆叠图
+	analyzer.ComputeCategoryTotalsWithConcurrency(groups, locator.NewClassifier(locatorConfig).CategoryFunc(), opts.HeapSampleType, opts.Concurrency)
+
+	// 计算趋势
+	for _, group := range groups {
+		if t := analyzer.CalculateTrendsWithConfig(group, analyzer.TrendConfig{HeapSampleType: opts.HeapSampleType, MinPoints: opts.MinTrendPoints}); t != nil {
+			result.Trends[group.Type] = t
+		}
+	}
+
+	// 加载规则引擎
+	engine, err := rules.NewEngine(opts.RulesPath)
+	if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("规则加载失败: %v", err))
+	}
+	result.Warnings = append(result.Warnings, engine.Warnings()...)
+	engine.SetMinTrendPoints(opts.MinTrendPoints)
+	findings, ruleStats := engine.EvaluateWithStats(groups, result.Trends)
+	result.RuleStats = ruleStats
+
+	// 定位问题上下文
+	contexts, err := generateProblemContextsCtx(ctx, findings, groups, locatorConfig, opts.Concurrency)
+	if err != nil {
+		// 超时后保留已生成的上下文，报告其余部分不受影响
+		result.Warnings = append(result.Warnings, fmt.Sprintf("问题定位未完成: %v", err))
+	}
+	result.Findings, result.Contexts = appendRootCauseInsights(findings, contexts)
+	return result
+}
+
+// hasGroup 判断是否存在指定类型的分组
+func hasGroup(groups []analyzer.ProfileGroup, profileType string) bool {
+	for _, group := range groups {
+		if group.Type == profileType {
+			return true
+		}
+	}
+	return false
+}
+
+// clockSkewWarning 生成时钟偏差警告，列出第一个逆序对
+func clockSkewWarning(skew analyzer.ClockSkew) string {
+	first := skew.Inversions[0]
+	return fmt.Sprintf("%s 组可能存在时钟偏差: %d 处文件名序号与采集时间顺序不一致 (如 %s 的采集时间 %s 早于 %s 的 %s)，趋势可能颠倒；可使用 -order-by-filename 按文件名序号排序",
+		skew.Type, len(skew.Inversions),
+		filepath.Base(first.Later), first.LaterTime.Format(time.RFC3339),
+		filepath.Base(first.Earlier), first.EarlierTime.Format(time.RFC3339))
+}
+
+// generateProblemContexts 为每个 Finding 生成 ProblemContext
+func generateProblemContexts(findings []rules.Finding, groups []analyzer.ProfileGroup, config locator.LocatorConfig) map[string]*locator.ProblemContext {
+	return generateProblemContextsWithConcurrency(findings, groups, config, 1)
+}
+
+// generateProblemContextsWithConcurrency 使用最多 concurrency 个 goroutine 为各 Finding 并行生成 ProblemContext
+// 生成器和 profile 只被读取，可以在 goroutine 间共享；结果按 findings 顺序写入 map，
+// RuleID 重复时与顺序执行一样保留后面的发现
+func generateProblemContextsWithConcurrency(findings []rules.Finding, groups []analyzer.ProfileGroup, config locator.LocatorConfig, concurrency int) map[string]*locator.ProblemContext {
+	contexts, _ := generateProblemContextsCtx(context.Background(), findings, groups, config, concurrency)
+	return contexts
+}
+
+// appendRootCauseInsights 不同 profile 类型的发现指向同一个业务包时，追加合并后的共同根因发现及其上下文
+func appendRootCauseInsights(findings []rules.Finding, contexts map[string]*locator.ProblemContext) ([]rules.Finding, map[string]*locator.ProblemContext) {
+	insights := locator.CorrelateRootCauses(findings, contexts, locator.DefaultRootCauseMinPct)
+	if len(insights) == 0 {
+		return findings, contexts
+	}
+	for _, insight := range insights {
+		contexts[insight.RuleID()] = insight.Context(contexts)
+		findings = append(findings, insight.Finding())
+	}
+	return findings, contexts
+}
+
+// generateProblemContextsCtx 同 generateProblemContextsWithConcurrency，ctx 取消或超时后停止生成，
+// 返回已完成的上下文和 ctx 错误
+func generateProblemContextsCtx(ctx context.Context, findings []rules.Finding, groups []analyzer.ProfileGroup, config locator.LocatorConfig, concurrency int) (map[string]*locator.ProblemContext, error) {
+	if len(findings) == 0 {
+		return nil, nil
+	}
+
+	contextGenerator := locator.NewContextGeneratorFromConfig(config)
+
+	// 收集所有 profiles，按类型组织（用于向后兼容，保留最新的单个 profile）
+	profiles := make(map[string]*profile.Profile)
+	// 收集所有 profiles，按类型组织（用于综合分析）
+	allProfiles := make(map[string][]*profile.Profile)
+	// 收集所有 profile 文件路径，按类型组织
+	profilePaths := make(map[string][]string)
+
+	for _, group := range groups {
+		if len(group.Files) > 0 {
+			// 使用最新的 profile（最后一个）- 向后兼容
+			profiles[group.Type] = group.Files[len(group.Files)-1].Profile
+
+			// 收集该类型的所有 profiles（用于综合分析）
+			for _, file := range group.Files {
+				if file.Profile != nil {
+					allProfiles[group.Type] = append(allProfiles[group.Type], file.Profile)
+				}
+				profilePaths[group.Type] = append(profilePaths[group.Type], file.Path)
+			}
+		}
+	}
+
+	// 为每个 Finding 生成 ProblemContext
+	results := make([]*locator.ProblemContext, len(findings))
+	err := analyzer.ParallelForCtx(ctx, len(findings), concurrency, func(i int) {
+		finding := findings[i]
+		// 确定该 finding 对应的 profile 类型
+		profileType := locator.DetermineProfileType(finding)
+		// 获取对应类型的 profile 路径
+		paths := profilePaths[profileType]
+		// 使用新的综合分析方法
+		// 取消时丢弃未完成的上下文，错误由 ParallelForCtx 统一返回
+		results[i], _ = contextGenerator.GenerateContextCtx(ctx, finding, profiles, allProfiles, paths)
+	})
+
+	contexts := make(map[string]*locator.ProblemContext)
+	for i, problem := range results {
+		if problem != nil {
+			contexts[findings[i].RuleID] = problem
+		}
+	}
+
+	return contexts, err
+}
@@ -0,0 +1,395 @@
+package perfinspector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/pprof/profile"
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+	"github.com/songzhibin97/perfinspector/pkg/locator"
+	"github.com/songzhibin97/perfinspector/pkg/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// defaultRulesPath 仓库自带的规则文件
+const defaultRulesPath = "../../assets/default_rules.yaml"
+
+// createTestProfile creates a test profile with the given samples
+func createTestProfile(samples []*profile.Sample) *profile.Profile {
+	return &profile.Profile{
+		Sample: samples,
+	}
+}
+
+// createTestSample creates a test sample with the given function names (root first) and value
+func createTestSample(funcNames []string, value int64) *profile.Sample {
+	locations := make([]*profile.Location, len(funcNames))
+	// pprof stores locations from leaf to root
+	for i, name := range funcNames {
+		fn := &profile.Function{
+			ID:       uint64(i + 1),
+			Name:     name,
+			Filename: name + ".go",
+		}
+		locations[len(funcNames)-1-i] = &profile.Location{
+			ID:   uint64(i + 1),
+			Line: []profile.Line{{Function: fn, Line: int64(i + 1)}},
+		}
+	}
+	return &profile.Sample{
+		Location: locations,
+		Value:    []int64{value},
+	}
+}
+
+// writeHeapProfiles 在 dir 中写入 n 个每分钟一次、使用中内存线性增长的 heap profile
+func writeHeapProfiles(t *testing.T, dir string, n int) []string {
+	start := time.Date(2023, 11, 15, 14, 0, 0, 0, time.UTC)
+	var paths []string
+	for i := 0; i < n; i++ {
+		fn := &profile.Function{ID: 1, Name: "github.com/myapp/cache.(*Store).Put", Filename: "/src/myapp/cache/store.go"}
+		loc := &profile.Location{ID: 1, Line: []profile.Line{{Function: fn, Line: 42}}}
+		size := int64(i+1) * 64 << 20
+		p := &profile.Profile{
+			TimeNanos: start.Add(time.Duration(i) * time.Minute).UnixNano(),
+			SampleType: []*profile.ValueType{
+				{Type: "alloc_objects", Unit: "count"},
+				{Type: "alloc_space", Unit: "bytes"},
+				{Type: "inuse_objects", Unit: "count"},
+				{Type: "inuse_space", Unit: "bytes"},
+			},
+			Sample:   []*profile.Sample{{Location: []*profile.Location{loc}, Value: []int64{int64(i + 1), size, int64(i + 1), size}}},
+			Location: []*profile.Location{loc},
+			Function: []*profile.Function{fn},
+		}
+
+		path := filepath.Join(dir, fmt.Sprintf("heap.%03d.pprof", i+1))
+		f, err := os.Create(path)
+		require.NoError(t, err)
+		require.NoError(t, p.Write(f))
+		require.NoError(t, f.Close())
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// TestDefaultOptions tests that the defaults match the locator defaults
+func TestDefaultOptions(t *testing.T) {
+	opts := DefaultOptions()
+	defaults := locator.DefaultConfig()
+	assert.Equal(t, defaults.MaxCallStackDepth, opts.StackDepth)
+	assert.Equal(t, defaults.MaxHotPaths, opts.HotPaths)
+	assert.Equal(t, analyzer.HeapSampleInuseSpace, opts.HeapSampleType)
+	assert.Equal(t, analyzer.DefaultMinTrendPoints, opts.MinTrendPoints)
+	assert.GreaterOrEqual(t, opts.Concurrency, 1)
+	assert.Empty(t, opts.RulesPath)
+}
+
+// TestOptionsLocatorConfig tests that options are copied into the locator config
+func TestOptionsLocatorConfig(t *testing.T) {
+	opts := DefaultOptions()
+	opts.ModuleName = "github.com/myapp"
+	opts.ThirdPartyPrefixes = []string{"github.com/vendor/"}
+	opts.StackDepth = 15
+	opts.HotPaths = 3
+	opts.ReadableNames = true
+	opts.RootCausePolicy = locator.RootCauseCostliest
+	opts.ValueType = "orders"
+
+	config := opts.LocatorConfig()
+	assert.Equal(t, "github.com/myapp", config.ModuleName)
+	assert.Equal(t, []string{"github.com/vendor/"}, config.ThirdPartyPrefixes)
+	assert.Equal(t, 15, config.MaxCallStackDepth)
+	assert.Equal(t, 3, config.MaxHotPaths)
+	assert.True(t, config.ReadableNames)
+	assert.Equal(t, locator.RootCauseCostliest, config.RootCausePolicy)
+	assert.Equal(t, "orders", config.ValueType)
+
+	// 未指定策略时保留默认策略
+	opts.RootCausePolicy = ""
+	assert.Equal(t, locator.DefaultConfig().RootCausePolicy, opts.LocatorConfig().RootCausePolicy)
+}
+
+// TestAnalyze tests the full pipeline on heap profiles with steady growth
+func TestAnalyze(t *testing.T) {
+	paths := writeHeapProfiles(t, t.TempDir(), 5)
+
+	opts := DefaultOptions()
+	opts.ModuleName = "github.com/myapp"
+	opts.RulesPath = defaultRulesPath
+	result, err := Analyze(paths, opts)
+	require.NoError(t, err)
+
+	require.Len(t, result.Groups, 1)
+	assert.Equal(t, "heap", result.Groups[0].Type)
+	assert.Len(t, result.Groups[0].Files, 5)
+	require.NotNil(t, result.Trends["heap"])
+	assert.Greater(t, result.Trends["heap"].HeapInuse.Slope, 0.0)
+	assert.Greater(t, result.RuleStats.Rules, 0)
+
+	var growth *rules.Finding
+	for i := range result.Findings {
+		if result.Findings[i].RuleID == "memory_growth_trend" {
+			growth = &result.Findings[i]
+		}
+	}
+	require.NotNil(t, growth, "steady heap growth should match memory_growth_trend")
+	problem := result.Context(*growth)
+	require.NotNil(t, problem)
+	require.NotEmpty(t, problem.HotPaths)
+	assert.Equal(t, "github.com/myapp/cache.(*Store).Put", problem.HotPaths[0].Chain.Frames[0].FunctionName)
+}
+
+// TestAnalyze_NoRules tests that an empty rules path only computes trends
+func TestAnalyze_NoRules(t *testing.T) {
+	paths := writeHeapProfiles(t, t.TempDir(), 3)
+
+	result, err := Analyze(paths, DefaultOptions())
+	require.NoError(t, err)
+	assert.NotNil(t, result.Trends["heap"])
+	assert.Empty(t, result.Findings)
+	assert.Empty(t, result.Warnings)
+	assert.Nil(t, result.Context(rules.Finding{RuleID: "memory_growth_trend"}))
+}
+
+// TestAnalyze_Warnings tests that missing files are skipped and rule loading failures only warn
+func TestAnalyze_Warnings(t *testing.T) {
+	result, err := Analyze([]string{filepath.Join(t.TempDir(), "missing.pprof")}, DefaultOptions())
+	require.NoError(t, err)
+	assert.Empty(t, result.Groups)
+
+	paths := writeHeapProfiles(t, t.TempDir(), 3)
+	opts := DefaultOptions()
+	opts.RulesPath = filepath.Join(t.TempDir(), "missing.yaml")
+	result, err = Analyze(paths, opts)
+	require.NoError(t, err)
+	require.Len(t, result.Warnings, 1)
+	assert.Contains(t, result.Warnings[0], "规则加载失败")
+
+	// ValueType 只作用于无法识别类型的 profile
+	opts = DefaultOptions()
+	opts.ValueType = "orders"
+	result, err = Load(paths, opts)
+	require.NoError(t, err)
+	assert.Nil(t, result.Trends)
+	require.Len(t, result.Warnings, 1)
+	assert.Contains(t, result.Warnings[0], `value type "orders"`)
+	assert.NotContains(t, result.Warnings[0], "-value-type")
+}
+
+// TestLoad_ValueTypeError 自定义 profile 缺少指定的 sample type 时返回 ValueTypeError
+func TestLoad_ValueTypeError(t *testing.T) {
+	fn := &profile.Function{ID: 1, Name: "github.com/myapp/shop.Checkout"}
+	loc := &profile.Location{ID: 1, Line: []profile.Line{{Function: fn}}}
+	p := &profile.Profile{
+		TimeNanos:  time.Date(2023, 11, 15, 14, 0, 0, 0, time.UTC).UnixNano(),
+		SampleType: []*profile.ValueType{{Type: "orders", Unit: "count"}},
+		Sample:     []*profile.Sample{{Location: []*profile.Location{loc}, Value: []int64{10}}},
+		Location:   []*profile.Location{loc},
+		Function:   []*profile.Function{fn},
+	}
+	path := filepath.Join(t.TempDir(), "orders.pprof")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, p.Write(f))
+	require.NoError(t, f.Close())
+
+	opts := DefaultOptions()
+	opts.ValueType = "latency"
+	_, err = Load([]string{path}, opts)
+	var valueTypeErr *ValueTypeError
+	require.True(t, errors.As(err, &valueTypeErr))
+	assert.Equal(t, "latency", valueTypeErr.ValueType)
+	assert.EqualError(t, err, fmt.Sprintf(`value type: %s: sample type "latency" not found, available: orders/count`, path))
+}
+
+// TestAnalyze_Dedup 指标相同的连续快照只有最早的一个参与趋势计算，分组中仍保留所有文件
+func TestAnalyze_Dedup(t *testing.T) {
+	dir := t.TempDir()
+	paths := writeHeapProfiles(t, dir, 3)
+	// heap.001 之后追加两个指标相同的快照，共三个相同的快照
+	data, err := os.ReadFile(paths[0])
+	require.NoError(t, err)
+	p, err := profile.ParseData(data)
+	require.NoError(t, err)
+	for i := 1; i <= 2; i++ {
+		p.TimeNanos += int64(10 * time.Second)
+		path := filepath.Join(dir, fmt.Sprintf("heap.001.dup%d.pprof", i))
+		f, err := os.Create(path)
+		require.NoError(t, err)
+		require.NoError(t, p.Write(f))
+		require.NoError(t, f.Close())
+		paths = append(paths, path)
+	}
+
+	opts := DefaultOptions()
+	result, err := Analyze(paths, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 5, result.Trends["heap"].HeapInuse.Points)
+
+	opts.Dedup = true
+	result, err = Analyze(paths, opts)
+	require.NoError(t, err)
+	require.Len(t, result.Groups, 1)
+	assert.Len(t, result.Groups[0].Files, 5)
+	assert.Equal(t, 3, result.Trends["heap"].HeapInuse.Points)
+	assert.Contains(t, result.Warnings, "heap 组有 2 个快照与之前的快照指标相同，已从趋势计算中排除")
+	for _, file := range result.Groups[0].Files[1:3] {
+		assert.Equal(t, paths[0], file.DuplicateOf)
+	}
+}
+
+// TestAnalyze_Window 只有窗口内的快照参与趋势计算
+func TestAnalyze_Window(t *testing.T) {
+	paths := writeHeapProfiles(t, t.TempDir(), 6)
+	start := time.Date(2023, 11, 15, 14, 0, 0, 0, time.UTC)
+
+	opts := DefaultOptions()
+	opts.Window = analyzer.TimeWindow{Since: start.Add(2 * time.Minute), Until: start.Add(5 * time.Minute)}
+	result, err := Analyze(paths, opts)
+	require.NoError(t, err)
+	require.Len(t, result.Groups, 1)
+	assert.Len(t, result.Groups[0].Files, 4)
+	assert.Equal(t, paths[2], result.Groups[0].Files[0].Path)
+	assert.Equal(t, 4, result.Trends["heap"].HeapInuse.Points)
+	assert.Contains(t, result.Warnings, "时间窗口 [2023-11-15T14:02:00Z, 2023-11-15T14:05:00Z] 之外的 2 个快照已排除")
+
+	// 窗口内快照不足时跳过趋势计算
+	opts.Window = analyzer.TimeWindow{Since: start.Add(4 * time.Minute)}
+	result, err = Analyze(paths, opts)
+	require.NoError(t, err)
+	assert.Len(t, result.Groups[0].Files, 2)
+	assert.Nil(t, result.Trends["heap"])
+	assert.Contains(t, result.Warnings, "heap 组在时间窗口内只有 2 个快照，少于 3 个，不计算趋势")
+
+	// 窗口内没有快照
+	opts.Window = analyzer.TimeWindow{Until: start.Add(-time.Minute)}
+	_, err = Analyze(paths, opts)
+	assert.EqualError(t, err, "no profiles in window [-, 2023-11-15T13:59:00Z] (6 profiles outside the window)")
+}
+
+// TestAnalyzeCtx tests that a canceled context fails while parsing
+func TestAnalyzeCtx(t *testing.T) {
+	paths := writeHeapProfiles(t, t.TempDir(), 3)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := AnalyzeCtx(ctx, paths, DefaultOptions())
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// TestGenerateProblemContextsWithConcurrency tests that concurrent context generation matches sequential output
+func TestGenerateProblemContextsWithConcurrency(t *testing.T) {
+	newGroup := func(profileType string, leaf string) analyzer.ProfileGroup {
+		var files []analyzer.ProfileFile
+		for i := 0; i < 3; i++ {
+			p := createTestProfile([]*profile.Sample{
+				createTestSample([]string{"main.main", "github.com/myapp/handler.Serve", leaf}, int64(100*(i+1))),
+				createTestSample([]string{"main.main", "github.com/myapp/worker.Run", "runtime.gopark"}, int64(50*(i+1))),
+			})
+			files = append(files, analyzer.ProfileFile{Path: profileType + ".pprof", Profile: p})
+		}
+		return analyzer.ProfileGroup{Type: profileType, Files: files}
+	}
+	groups := []analyzer.ProfileGroup{
+		newGroup("cpu", "runtime.memmove"),
+		newGroup("goroutine", "runtime.gopark"),
+		newGroup("heap", "runtime.mallocgc"),
+	}
+
+	var findings []rules.Finding
+	for _, profileType := range []string{"cpu", "goroutine", "heap"} {
+		for i := 0; i < 4; i++ {
+			findings = append(findings, rules.Finding{
+				RuleID:       fmt.Sprintf("%s_rule_%d", profileType, i),
+				Severity:     "high",
+				Title:        profileType + " finding",
+				ProfileTypes: []string{profileType},
+			})
+		}
+	}
+	// 重复的 RuleID 保留后面的发现
+	findings = append(findings, rules.Finding{RuleID: "cpu_rule_0", Severity: "low", Title: "duplicate", ProfileTypes: []string{"goroutine"}})
+
+	config := locator.LocatorConfig{ModuleName: "github.com/myapp", MaxCallStackDepth: 10, MaxHotPaths: 5}
+	sequential := generateProblemContexts(findings, groups, config)
+	require.Len(t, sequential, 12)
+	assert.Equal(t, "duplicate", sequential["cpu_rule_0"].Title)
+
+	for _, concurrency := range []int{2, 8} {
+		contexts := generateProblemContextsWithConcurrency(findings, groups, config, concurrency)
+		assert.Equal(t, sequential, contexts, "concurrency %d", concurrency)
+	}
+}
+
+// TestAppendRootCauseInsights tests that cpu and heap findings rooted in the same package are merged
+func TestAppendRootCauseInsights(t *testing.T) {
+	newGroup := func(profileType string, leaf string) analyzer.ProfileGroup {
+		p := createTestProfile([]*profile.Sample{
+			createTestSample([]string{"main.main", "github.com/myapp/codec.Encode", leaf}, 300),
+			createTestSample([]string{"main.main", "github.com/myapp/worker.Run", leaf}, 100),
+		})
+		return analyzer.ProfileGroup{Type: profileType, Files: []analyzer.ProfileFile{{Path: profileType + ".pprof", Profile: p}}}
+	}
+	groups := []analyzer.ProfileGroup{newGroup("cpu", "runtime.memmove"), newGroup("heap", "runtime.mallocgc")}
+	findings := []rules.Finding{
+		{RuleID: "cpu_rule", Severity: "medium", Title: "cpu finding", ProfileTypes: []string{"cpu"}},
+		{RuleID: "heap_rule", Severity: "high", Title: "heap finding", ProfileTypes: []string{"heap"}},
+	}
+	config := locator.LocatorConfig{ModuleName: "github.com/myapp", MaxCallStackDepth: 10, MaxHotPaths: 5}
+	contexts := generateProblemContexts(findings, groups, config)
+
+	merged, mergedContexts := appendRootCauseInsights(findings, contexts)
+	require.Len(t, merged, 4)
+	ids := []string{merged[2].RuleID, merged[3].RuleID}
+	assert.Equal(t, []string{"shared_root_cause:github.com/myapp/codec", "shared_root_cause:github.com/myapp/worker"}, ids)
+	assert.True(t, merged[2].IsCrossAnalysis)
+	assert.Equal(t, "high", merged[2].Severity)
+	require.NotNil(t, mergedContexts[merged[2].RuleID])
+	assert.NotEmpty(t, mergedContexts[merged[2].RuleID].HotPaths)
+
+	// 只有一种 profile 类型时不产生共同根因
+	single, _ := appendRootCauseInsights(findings[:1], generateProblemContexts(findings[:1], groups, config))
+	assert.Len(t, single, 1)
+}
+
+// TestGenerateProblemContextsCtx tests that a canceled context stops context generation
+func TestGenerateProblemContextsCtx(t *testing.T) {
+	p := createTestProfile([]*profile.Sample{
+		createTestSample([]string{"main.main", "github.com/myapp/handler.Serve"}, 100),
+	})
+	groups := []analyzer.ProfileGroup{{Type: "cpu", Files: []analyzer.ProfileFile{{Path: "cpu.pprof", Profile: p}}}}
+	findings := []rules.Finding{{RuleID: "cpu_rule", Severity: "high", Title: "cpu finding", ProfileTypes: []string{"cpu"}}}
+	config := locator.LocatorConfig{ModuleName: "github.com/myapp", MaxCallStackDepth: 10, MaxHotPaths: 5}
+
+	contexts, err := generateProblemContextsCtx(context.Background(), findings, groups, config, 2)
+	require.NoError(t, err)
+	assert.Len(t, contexts, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	contexts, err = generateProblemContextsCtx(ctx, findings, groups, config, 2)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, contexts)
+}
+
+// TestClockSkewWarning tests the clock skew warning message
+func TestClockSkewWarning(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	warning := clockSkewWarning(analyzer.ClockSkew{
+		Type: "heap",
+		Inversions: []analyzer.SkewInversion{
+			{Earlier: "host-a/heap.2.pprof", EarlierTime: start, Later: "host-b/heap.3.pprof", LaterTime: start.Add(-time.Minute)},
+			{Earlier: "host-b/heap.5.pprof", EarlierTime: start, Later: "host-a/heap.6.pprof", LaterTime: start.Add(-time.Minute)},
+		},
+	})
+	assert.Contains(t, warning, "heap 组可能存在时钟偏差: 2 处")
+	assert.Contains(t, warning, "heap.3.pprof 的采集时间 2024-01-01T09:59:00Z 早于 heap.2.pprof 的 2024-01-01T10:00:00Z")
+	assert.Contains(t, warning, "-order-by-filename")
+}
@@ -0,0 +1,87 @@
+package reporter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+)
+
+// csvHeader CSV 报告的列，时间为 RFC3339，时长为纳秒，内存为字节
+var csvHeader = []string{
+	"type", "file", "timestamp", "size_bytes",
+	"cpu_time_ns", "duration_ns", "total_samples",
+	"alloc_space", "alloc_objects", "inuse_space", "inuse_objects",
+	"goroutine_count",
+}
+
+// GenerateCSVReport 生成每个 profile 文件一行的 CSV 报告，便于导入表格做容量规划
+func GenerateCSVReport(groups []analyzer.ProfileGroup, outputPath string) error {
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create output file '%s': %w", outputPath, err)
+	}
+	if err := WriteCSVReport(file, &Report{Groups: groups, Options: DefaultOptions()}); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write CSV report '%s': %w", outputPath, err)
+	}
+	return nil
+}
+
+// WriteCSVReport 将每个 profile 文件的原始指标以 CSV 写入 w，分组和文件保持输入顺序
+// 不适用于该 profile 类型的指标 (如 cpu profile 的 inuse_space) 留空而不是写 0，避免透视表把缺失当作零值
+func WriteCSVReport(w io.Writer, report *Report) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, group := range report.Groups {
+		for _, file := range group.Files {
+			if err := cw.Write(csvRow(group.Type, file)); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvRow 生成一个文件的 CSV 行，列顺序与 csvHeader 一致
+func csvRow(profileType string, file analyzer.ProfileFile) []string {
+	row := make([]string, len(csvHeader))
+	row[0] = profileType
+	row[1] = file.Path
+	if !file.Time.IsZero() {
+		row[2] = file.Time.UTC().Format(time.RFC3339)
+	}
+	row[3] = strconv.FormatInt(file.Size, 10)
+
+	m := file.Metrics
+	if m == nil {
+		return row
+	}
+	if profileType == "cpu" && !m.NoDuration {
+		row[4] = strconv.FormatInt(int64(m.CPUTime), 10)
+	}
+	if m.Duration > 0 {
+		row[5] = strconv.FormatInt(int64(m.Duration), 10)
+	}
+	row[6] = strconv.FormatInt(m.TotalSamples, 10)
+	if profileType == "heap" {
+		row[7] = strconv.FormatInt(m.AllocSpace, 10)
+		row[8] = strconv.FormatInt(m.AllocObjects, 10)
+		row[9] = strconv.FormatInt(m.InuseSpace, 10)
+		row[10] = strconv.FormatInt(m.InuseObjects, 10)
+	}
+	if profileType == "goroutine" {
+		row[11] = strconv.FormatInt(m.GoroutineCount, 10)
+	}
+	return row
+}
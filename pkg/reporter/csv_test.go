@@ -0,0 +1,77 @@
+package reporter
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateCSVReport(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 10, 0, 0, 0, time.FixedZone("CST", 8*3600))
+	groups := []analyzer.ProfileGroup{
+		{
+			Type: "cpu",
+			Files: []analyzer.ProfileFile{
+				{Path: "/p/cpu1.pprof", Time: ts, Size: 2048, Metrics: &analyzer.ProfileMetrics{
+					CPUTime: 3 * time.Second, Duration: 30 * time.Second, TotalSamples: 300,
+				}},
+				{Path: "/p/cpu2.pprof", Time: ts, Size: 1024, Metrics: &analyzer.ProfileMetrics{NoDuration: true, TotalSamples: 10}},
+			},
+		},
+		{
+			Type: "heap",
+			Files: []analyzer.ProfileFile{
+				{Path: "/p/heap1.pprof", Time: ts, Size: 4096, Metrics: &analyzer.ProfileMetrics{
+					TotalSamples: 5, AllocSpace: 1000, AllocObjects: 10, InuseSpace: 0, InuseObjects: 0,
+				}},
+			},
+		},
+		{
+			Type:  "goroutine",
+			Files: []analyzer.ProfileFile{{Path: "/p/goroutine1.pprof", Size: 512, Metrics: &analyzer.ProfileMetrics{GoroutineCount: 42}}},
+		},
+		{
+			Type:  "block",
+			Files: []analyzer.ProfileFile{{Path: "/p/block1.pprof", Time: ts, Size: 10}},
+		},
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "metrics.csv")
+	require.NoError(t, GenerateCSVReport(groups, outputPath))
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+
+	records, err := csv.NewReader(strings.NewReader(string(content))).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 6)
+	assert.Equal(t, csvHeader, records[0])
+	assert.Equal(t, []string{"cpu", "/p/cpu1.pprof", "2024-05-01T02:00:00Z", "2048", "3000000000", "30000000000", "300", "", "", "", "", ""}, records[1])
+	// 缺少采集时长的 CPU profile 不给出 CPU 时间
+	assert.Equal(t, []string{"cpu", "/p/cpu2.pprof", "2024-05-01T02:00:00Z", "1024", "", "", "10", "", "", "", "", ""}, records[2])
+	// heap 的零值是真实的 0，不留空
+	assert.Equal(t, []string{"heap", "/p/heap1.pprof", "2024-05-01T02:00:00Z", "4096", "", "", "5", "1000", "10", "0", "0", ""}, records[3])
+	assert.Equal(t, []string{"goroutine", "/p/goroutine1.pprof", "", "512", "", "", "0", "", "", "", "", "42"}, records[4])
+	// 没有指标的文件只有基本信息
+	assert.Equal(t, []string{"block", "/p/block1.pprof", "2024-05-01T02:00:00Z", "10", "", "", "", "", "", "", "", ""}, records[5])
+}
+
+func TestGenerateCSVReport_InvalidPath(t *testing.T) {
+	err := GenerateCSVReport(nil, filepath.Join(t.TempDir(), "missing", "metrics.csv"))
+	assert.ErrorContains(t, err, "failed to create output file")
+}
+
+func TestCSVRenderer(t *testing.T) {
+	renderer, ok := LookupRenderer("csv")
+	require.True(t, ok)
+
+	var buf strings.Builder
+	require.NoError(t, renderer.Render(&Report{}, &buf))
+	assert.Equal(t, strings.Join(csvHeader, ",")+"\n", buf.String())
+}
@@ -0,0 +1,283 @@
+package reporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/songzhibin97/perfinspector/pkg/locator"
+	"github.com/songzhibin97/perfinspector/pkg/rules"
+)
+
+// dotRootCauseColor 根因节点的边框颜色
+const dotRootCauseColor = "#dc3545"
+
+// dotNode 聚合后的调用图节点
+type dotNode struct {
+	id        string
+	frame     locator.StackFrame
+	value     int64
+	pct       float64
+	rootCause bool
+}
+
+// dotEdge 聚合后的调用图边 (调用方 → 被调用方)
+type dotEdge struct {
+	from, to string
+	value    int64
+	pct      float64
+}
+
+// dotGraph 单个 profile 类型的聚合调用图
+type dotGraph struct {
+	profileType string
+	unit        locator.ValueUnit // 第一条热点路径的单位，未知时按 profileType 格式化
+	nodes       map[string]*dotNode
+	nodeOrder   []string
+	edges       map[[2]string]*dotEdge
+	edgeOrder   [][2]string
+}
+
+// GenerateDOTGraph 将发现的热点路径输出为 Graphviz DOT 格式
+// outputPath 为空时写入标准输出，便于直接管道给 dot -Tsvg
+func GenerateDOTGraph(findings []rules.Finding, contexts map[string]*locator.ProblemContext, outputPath string, opts Options) error {
+	if outputPath == "" {
+		return WriteDOTGraph(os.Stdout, findings, contexts, opts)
+	}
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	if err := WriteDOTGraph(file, findings, contexts, opts); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write DOT file '%s': %w", outputPath, err)
+	}
+	return nil
+}
+
+// WriteDOTGraph 输出热点路径的聚合调用图
+// 只包含分析选出的热点路径，同一 profile 类型的路径合并为一个子图；
+// 节点和边的权重为经过它们的热点路径的样本值之和，根因节点加粗标红
+func WriteDOTGraph(w io.Writer, findings []rules.Finding, contexts map[string]*locator.ProblemContext, opts Options) error {
+	if opts.NoEmoji {
+		opts.NoEmoji = false
+		return writePlain(w, func(buf io.Writer) error { return WriteDOTGraph(buf, findings, contexts, opts) })
+	}
+
+	graphs := buildDOTGraphs(findings, contexts, opts)
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph perfinspector {")
+	fmt.Fprintf(bw, "  label=%s;\n", dotQuote("PerfInspector "+opts.version()+" 热点路径"))
+	fmt.Fprintln(bw, "  labelloc=t;")
+	fmt.Fprintln(bw, "  rankdir=TB;")
+	fmt.Fprintln(bw, "  node [shape=box, style=\"rounded,filled\", fontcolor=white, fontname=\"Helvetica\"];")
+	fmt.Fprintln(bw, "  edge [color=\"#495057\"];")
+
+	for i, g := range graphs {
+		writeDOTSubgraph(bw, i, g)
+	}
+
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// buildDOTGraphs 按发现顺序聚合热点路径，返回按 profile 类型排序的调用图
+func buildDOTGraphs(findings []rules.Finding, contexts map[string]*locator.ProblemContext, opts Options) []*dotGraph {
+	byType := make(map[string]*dotGraph)
+	kept, _ := opts.Limits.Findings(findings)
+	for _, finding := range kept {
+		ctx := contexts[finding.RuleID]
+		if ctx == nil {
+			continue
+		}
+		hotPaths, _ := opts.Limits.HotPaths(ctx.HotPaths)
+		for _, hp := range hotPaths {
+			profileType := hp.ProfileType
+			if profileType == "" {
+				profileType = "unknown"
+			}
+			g, ok := byType[profileType]
+			if !ok {
+				g = &dotGraph{
+					profileType: profileType,
+					nodes:       make(map[string]*dotNode),
+					edges:       make(map[[2]string]*dotEdge),
+				}
+				byType[profileType] = g
+			}
+			g.addHotPath(hp, opts.Limits)
+		}
+	}
+
+	types := make([]string, 0, len(byType))
+	for profileType := range byType {
+		types = append(types, profileType)
+	}
+	sort.Strings(types)
+
+	graphs := make([]*dotGraph, 0, len(types))
+	for _, profileType := range types {
+		graphs = append(graphs, byType[profileType])
+	}
+	return graphs
+}
+
+// addHotPath 将一条热点路径合并到调用图中
+// 同一函数在一条路径中多次出现 (递归) 时只计一次权重
+func (g *dotGraph) addHotPath(hp locator.HotPath, limits Limits) {
+	frames, _ := limits.Frames(hp.Chain.Frames)
+	value := hp.Chain.TotalValue
+	pct := hp.Chain.TotalPct
+	if g.unit.Unit == "" {
+		g.unit = hp.Unit
+	}
+
+	counted := make(map[string]bool)
+	for i, frame := range frames {
+		id := g.profileType + ":" + frame.FunctionName
+		node, ok := g.nodes[id]
+		if !ok {
+			node = &dotNode{id: id, frame: frame}
+			g.nodes[id] = node
+			g.nodeOrder = append(g.nodeOrder, id)
+		}
+		if !counted[id] {
+			node.value += value
+			node.pct += pct
+			counted[id] = true
+		}
+		if i == hp.RootCauseIndex {
+			node.rootCause = true
+		}
+
+		if i == 0 {
+			continue
+		}
+		key := [2]string{g.profileType + ":" + frames[i-1].FunctionName, id}
+		edge, ok := g.edges[key]
+		if !ok {
+			edge = &dotEdge{from: key[0], to: key[1]}
+			g.edges[key] = edge
+			g.edgeOrder = append(g.edgeOrder, key)
+		}
+		edge.value += value
+		edge.pct += pct
+	}
+}
+
+// writeDOTSubgraph 输出单个 profile 类型的子图
+func writeDOTSubgraph(w io.Writer, index int, g *dotGraph) {
+	var maxEdge int64
+	for _, edge := range g.edges {
+		if edge.value > maxEdge {
+			maxEdge = edge.value
+		}
+	}
+
+	fmt.Fprintf(w, "  subgraph cluster_%d {\n", index)
+	fmt.Fprintf(w, "    label=%s;\n", dotQuote(g.profileType))
+	fmt.Fprintln(w, "    style=dashed;")
+
+	for _, id := range g.nodeOrder {
+		node := g.nodes[id]
+		// 节点按代码分类着色，与 HTML 报告的栈帧颜色一致
+		color := node.frame.Category.Color()
+		label := fmt.Sprintf("%s\n%s\n%s (%.1f%%)", node.frame.DisplayName(), node.frame.Category.String(),
+			g.formatValue(node.value), capPct(node.pct))
+		attrs := []string{
+			"label=" + dotQuote(label),
+			"fillcolor=" + dotQuote(color),
+			"tooltip=" + dotQuote(node.frame.FunctionName+" "+node.frame.Location()),
+		}
+		if node.rootCause {
+			attrs = append(attrs, "color="+dotQuote(dotRootCauseColor), "penwidth=3", "peripheries=2")
+		}
+		fmt.Fprintf(w, "    %s [%s];\n", dotQuote(id), strings.Join(attrs, ", "))
+	}
+
+	for _, key := range g.edgeOrder {
+		edge := g.edges[key]
+		fmt.Fprintf(w, "    %s -> %s [label=%s, weight=%d, penwidth=%s];\n",
+			dotQuote(edge.from), dotQuote(edge.to),
+			dotQuote(fmt.Sprintf("%.1f%%", capPct(edge.pct))),
+			edgeWeight(edge.value, maxEdge),
+			strconv.FormatFloat(edgePenWidth(edge.value, maxEdge), 'f', 2, 64))
+	}
+
+	fmt.Fprintln(w, "  }")
+}
+
+// formatDOTValue 按 profile 类型格式化样本值
+func formatDOTValue(value int64, profileType string) string {
+	switch profileType {
+	case "cpu":
+		// CPU 热点路径使用 cpu/nanoseconds 样本值
+		return time.Duration(value).String()
+	case "goroutine":
+		return strconv.FormatInt(value, 10) + " goroutines"
+	default:
+		return strconv.FormatInt(value, 10)
+	}
+}
+
+// formatValue 格式化节点的消耗值，热点路径的单位已知时按单位换算
+func (g *dotGraph) formatValue(value int64) string {
+	if formatted := g.unit.Format(value); formatted != "" {
+		return formatted
+	}
+	return formatDOTValue(value, g.profileType)
+}
+
+// capPct 多条路径累加的百分比不超过 100%
+func capPct(pct float64) float64 {
+	if pct > 100 {
+		return 100
+	}
+	return pct
+}
+
+// edgeWeight 将样本值映射为 Graphviz 布局权重 (1-100)，权重大的边更短更直
+func edgeWeight(value, maxValue int64) int {
+	if maxValue <= 0 {
+		return 1
+	}
+	return 1 + int(99*value/maxValue)
+}
+
+// edgePenWidth 将样本值映射为边的线宽 (1-6)
+func edgePenWidth(value, maxValue int64) float64 {
+	if maxValue <= 0 {
+		return 1
+	}
+	return 1 + 5*float64(value)/float64(maxValue)
+}
+
+// dotQuote 将字符串转义为 DOT 双引号字符串
+func dotQuote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,165 @@
+package reporter
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/songzhibin97/perfinspector/pkg/locator"
+	"github.com/songzhibin97/perfinspector/pkg/rules"
+)
+
+// dotTestFrame 构造测试用栈帧
+func dotTestFrame(name string, category locator.CodeCategory) locator.StackFrame {
+	short := name[strings.LastIndex(name, ".")+1:]
+	return locator.StackFrame{FunctionName: name, ShortName: short, Category: category}
+}
+
+// dotTestInput 两条共享入口的 CPU 热点路径
+func dotTestInput() ([]rules.Finding, map[string]*locator.ProblemContext) {
+	entry := dotTestFrame("main.main", locator.CategoryBusiness)
+	handler := dotTestFrame("github.com/myapp/api.Handle", locator.CategoryBusiness)
+	encode := dotTestFrame("encoding/json.Marshal", locator.CategoryStdlib)
+	gc := dotTestFrame("runtime.mallocgc", locator.CategoryRuntime)
+
+	findings := []rules.Finding{{RuleID: "cpu_hotspot", Title: "CPU 热点"}}
+	contexts := map[string]*locator.ProblemContext{
+		"cpu_hotspot": {
+			HotPaths: []locator.HotPath{
+				{
+					Chain:          locator.CallChain{Frames: []locator.StackFrame{entry, handler, encode}, TotalValue: 3e9, TotalPct: 60},
+					RootCauseIndex: 1,
+					ProfileType:    "cpu",
+				},
+				{
+					Chain:          locator.CallChain{Frames: []locator.StackFrame{entry, gc}, TotalValue: 1e9, TotalPct: 20},
+					RootCauseIndex: 0,
+					ProfileType:    "cpu",
+				},
+			},
+		},
+	}
+	return findings, contexts
+}
+
+func TestWriteDOTGraph(t *testing.T) {
+	findings, contexts := dotTestInput()
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteDOTGraph(&buf, findings, contexts, DefaultOptions()))
+	out := buf.String()
+
+	assert.True(t, strings.HasPrefix(out, "digraph perfinspector {"))
+	assert.True(t, strings.HasSuffix(out, "}\n"))
+	assert.Contains(t, out, `label="cpu";`)
+
+	t.Run("shared frames are aggregated", func(t *testing.T) {
+		assert.Equal(t, 1, strings.Count(out, `"cpu:main.main" [`))
+		assert.Contains(t, out, `label="main\n业务\n4s (80.0%)"`)
+		assert.Contains(t, out, `label="Handle\n业务\n3s (60.0%)"`)
+	})
+
+	t.Run("nodes colored by category", func(t *testing.T) {
+		assert.Contains(t, out, `"cpu:encoding/json.Marshal" [label="Marshal\n标准库\n3s (60.0%)", fillcolor="#17a2b8"`)
+		assert.Contains(t, out, `"cpu:runtime.mallocgc" [label="mallocgc\n运行时\n1s (20.0%)", fillcolor="#6c757d"`)
+	})
+
+	t.Run("root cause emphasized", func(t *testing.T) {
+		for _, line := range strings.Split(out, "\n") {
+			line = strings.TrimSpace(line)
+			if strings.HasPrefix(line, `"cpu:github.com/myapp/api.Handle" [`) || strings.HasPrefix(line, `"cpu:main.main" [`) {
+				assert.Contains(t, line, "penwidth=3")
+			}
+			if strings.HasPrefix(line, `"cpu:encoding/json.Marshal" [`) {
+				assert.NotContains(t, line, "penwidth=3")
+			}
+		}
+	})
+
+	t.Run("edges weighted by sample value", func(t *testing.T) {
+		assert.Contains(t, out, `"cpu:main.main" -> "cpu:github.com/myapp/api.Handle" [label="60.0%", weight=100, penwidth=6.00];`)
+		assert.Contains(t, out, `"cpu:main.main" -> "cpu:runtime.mallocgc" [label="20.0%", weight=34, penwidth=2.67];`)
+	})
+}
+
+func TestWriteDOTGraph_CategoryTheme(t *testing.T) {
+	t.Cleanup(func() { locator.SetCategoryTheme(nil) })
+	locator.SetCategoryTheme(locator.CategoryTheme{
+		locator.CategoryStdlib: {Icon: "S", Label: "stdlib", Color: "#123456"},
+	})
+
+	findings, contexts := dotTestInput()
+	var buf bytes.Buffer
+	require.NoError(t, WriteDOTGraph(&buf, findings, contexts, DefaultOptions()))
+	assert.Contains(t, buf.String(), `"cpu:encoding/json.Marshal" [label="Marshal\nstdlib\n3s (60.0%)", fillcolor="#123456"`)
+}
+
+func TestWriteDOTGraph_Limits(t *testing.T) {
+	findings, contexts := dotTestInput()
+	opts := DefaultOptions()
+	opts.Limits.MaxHotPaths = 1
+	opts.Limits.MaxFrames = 2
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteDOTGraph(&buf, findings, contexts, opts))
+	out := buf.String()
+
+	assert.Contains(t, out, "api.Handle")
+	assert.NotContains(t, out, "json.Marshal")
+	assert.NotContains(t, out, "mallocgc")
+}
+
+func TestWriteDOTGraph_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteDOTGraph(&buf, nil, nil, DefaultOptions()))
+	assert.NotContains(t, buf.String(), "subgraph")
+	assert.True(t, strings.HasSuffix(buf.String(), "}\n"))
+}
+
+func TestGenerateDOTGraph_File(t *testing.T) {
+	findings, contexts := dotTestInput()
+	outputPath := filepath.Join(t.TempDir(), "hotpaths.dot")
+
+	require.NoError(t, GenerateDOTGraph(findings, contexts, outputPath, DefaultOptions()))
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "digraph perfinspector")
+}
+
+func TestDotQuote(t *testing.T) {
+	assert.Equal(t, `"plain"`, dotQuote("plain"))
+	assert.Equal(t, `"a\"b\\c\nd"`, dotQuote("a\"b\\c\nd"))
+}
+
+func TestFormatDOTValue(t *testing.T) {
+	assert.Equal(t, "1.5s", formatDOTValue(1500000000, "cpu"))
+	assert.Equal(t, "12 goroutines", formatDOTValue(12, "goroutine"))
+	assert.Equal(t, "4096", formatDOTValue(4096, "heap"))
+}
+
+func TestWriteDOTGraph_ValueUnit(t *testing.T) {
+	findings := []rules.Finding{{RuleID: "heap_growth", Title: "内存增长"}}
+	contexts := map[string]*locator.ProblemContext{
+		"heap_growth": {
+			HotPaths: []locator.HotPath{{
+				Chain: locator.CallChain{
+					Frames:     []locator.StackFrame{dotTestFrame("github.com/myapp/cache.Load", locator.CategoryBusiness)},
+					TotalValue: 3 << 20,
+					TotalPct:   75,
+				},
+				RootCauseIndex: 0,
+				ProfileType:    "heap",
+				Unit:           locator.ValueUnit{Unit: "bytes", Scale: 1, Total: 4 << 20},
+			}},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteDOTGraph(&buf, findings, contexts, DefaultOptions()))
+	assert.Contains(t, buf.String(), `label="Load\n业务\n3.00 MB (75.0%)"`)
+}
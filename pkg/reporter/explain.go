@@ -0,0 +1,118 @@
+package reporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+	"github.com/songzhibin97/perfinspector/pkg/locator"
+)
+
+// FunctionReport 单个函数的视图 (-explain-func)：各 profile 中的消耗、趋势和经过它的热点路径
+type FunctionReport struct {
+	Explanation *analyzer.FunctionExplanation
+	Category    locator.CodeCategory         // 函数所在包的代码分类
+	HotPaths    map[string][]locator.HotPath // 经过该函数的热点路径，键为 profile 类型
+	Commands    []locator.ExecutableCmd      // 进一步查看该函数的 pprof 命令
+}
+
+// GenerateFunctionReport 输出单个函数的文本报告
+func GenerateFunctionReport(report FunctionReport, opts Options) {
+	WriteFunctionReport(os.Stdout, report, opts)
+}
+
+// WriteFunctionReport 将单个函数的文本报告写入 w
+func WriteFunctionReport(w io.Writer, report FunctionReport, opts Options) error {
+	if opts.NoEmoji {
+		opts.NoEmoji = false
+		return writePlain(w, func(buf io.Writer) error { return WriteFunctionReport(buf, report, opts) })
+	}
+
+	bw := bufio.NewWriter(w)
+	writeFunctionReport(bw, report, opts)
+	return bw.Flush()
+}
+
+// writeFunctionReport 输出函数视图的各个部分
+func writeFunctionReport(w io.Writer, report FunctionReport, opts Options) {
+	explanation := report.Explanation
+	if explanation == nil {
+		return
+	}
+
+	fmt.Fprintln(w, "\n"+"═══════════════════════════════════════════════════════════")
+	fmt.Fprintf(w, "                    PerfInspector %s 函数分析\n", opts.version())
+	fmt.Fprintln(w, "═══════════════════════════════════════════════════════════")
+	fmt.Fprintf(w, "\n🎯 %s\n", explanation.Function)
+
+	if !explanation.Found() {
+		fmt.Fprintln(w, "\n❓ 没有在任何 profile 中找到该函数")
+		fmt.Fprintln(w, "   函数名需要与 pprof 中的完整名称一致，如 github.com/myorg/app.(*Server).HandleOrder")
+		return
+	}
+
+	fmt.Fprintf(w, "   分类: %s %s\n", report.Category.Icon(), report.Category.String())
+	if len(explanation.Names) > 1 || explanation.Names[0] != explanation.Function {
+		fmt.Fprintf(w, "   匹配: %s\n", strings.Join(explanation.Names, ", "))
+	}
+
+	// heap 的两种 sample type 共用同一组热点路径，只在第一次出现时打印
+	printedPaths := make(map[string]bool)
+	for _, usage := range explanation.Profiles {
+		fmt.Fprintf(w, "\n📁 %s (%s):\n", usage.Type, usage.SampleType)
+		fmt.Fprintln(w, "───────────────────────────────────────────────────────────")
+		if !usage.Found() {
+			fmt.Fprintln(w, "   未出现在该类 profile 的调用栈中")
+			continue
+		}
+
+		fmt.Fprintf(w, "   最新快照: %s\n", functionUsageSummary(usage.Type, usage.Latest(), usage.Unit))
+		if len(usage.Snapshots) > 1 {
+			for i, s := range usage.Snapshots {
+				fmt.Fprintf(w, "     %d. %s  %s\n", i+1, filepath.Base(s.Path), functionUsageSummary(usage.Type, s, usage.Unit))
+			}
+		}
+		if trend := usage.Trend; trend != nil {
+			fmt.Fprintf(w, "   %s 趋势: %s/快照, R²=%.2f%s (%s)\n", getDirectionIcon(trend.Direction),
+				formatSampleValue(int64(trend.Slope), usage.Unit), trend.R2, trendPoints(trend), trend.Direction)
+		}
+
+		if hotPaths := report.HotPaths[usage.Type]; len(hotPaths) > 0 && !printedPaths[usage.Type] {
+			printedPaths[usage.Type] = true
+			printHotPathsWithLimits(w, hotPaths, opts.Limits)
+		}
+	}
+
+	printCommands(w, report.Commands)
+}
+
+// functionUsageSummary 格式化单个快照中函数的消耗
+// goroutine profile 的 cum 为调用栈包含该函数的 goroutine 数
+func functionUsageSummary(profileType string, s analyzer.FunctionUsage, unit string) string {
+	if profileType == "goroutine" {
+		return fmt.Sprintf("%s 个 goroutine (%.1f%%)", analyzer.FormatInt(s.Cum), s.CumPct)
+	}
+	return fmt.Sprintf("flat %s (%.1f%%), cum %s (%.1f%%)",
+		formatSampleValue(s.Flat, unit), s.FlatPct, formatSampleValue(s.Cum, unit), s.CumPct)
+}
+
+// formatSampleValue 按 sample type 的单位格式化样本值
+func formatSampleValue(value int64, unit string) string {
+	sign := ""
+	if value < 0 {
+		sign, value = "-", -value
+	}
+	switch unit {
+	case "nanoseconds":
+		return sign + time.Duration(value).String()
+	case "bytes":
+		return sign + analyzer.FormatBytes(value)
+	default:
+		return sign + analyzer.FormatInt(value)
+	}
+}
@@ -0,0 +1,107 @@
+package reporter
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+	"github.com/songzhibin97/perfinspector/pkg/locator"
+	"github.com/stretchr/testify/assert"
+)
+
+// newFunctionReport 创建 cpu 有趋势和热点路径、heap 未出现的函数视图
+func newFunctionReport() FunctionReport {
+	target := "github.com/myapp/orders.Handle"
+	cpu := analyzer.FunctionProfileUsage{
+		Type: "cpu", SampleType: "cpu", Unit: "nanoseconds",
+		Snapshots: []analyzer.FunctionUsage{
+			{Path: "/data/cpu.1.pprof", Flat: int64(10 * time.Millisecond), FlatPct: 1, Cum: int64(100 * time.Millisecond), CumPct: 10},
+			{Path: "/data/cpu.2.pprof", Flat: int64(20 * time.Millisecond), FlatPct: 2, Cum: int64(300 * time.Millisecond), CumPct: 30},
+		},
+		Trend: &analyzer.TrendMetrics{Slope: float64(200 * time.Millisecond), R2: 1, Direction: "increasing", Points: 2},
+	}
+	goroutine := analyzer.FunctionProfileUsage{
+		Type: "goroutine", SampleType: "goroutine", Unit: "count",
+		Snapshots: []analyzer.FunctionUsage{{Path: "/data/goroutine.pprof", Cum: 1200, CumPct: 40}},
+	}
+	heap := analyzer.FunctionProfileUsage{
+		Type: "heap", SampleType: "alloc_space", Unit: "bytes",
+		Snapshots: []analyzer.FunctionUsage{{Path: "/data/heap.pprof"}},
+	}
+
+	return FunctionReport{
+		Explanation: &analyzer.FunctionExplanation{
+			Function: target,
+			Names:    []string{target, target + ".func1"},
+			Profiles: []analyzer.FunctionProfileUsage{cpu, goroutine, heap},
+		},
+		Category: locator.CategoryBusiness,
+		HotPaths: map[string][]locator.HotPath{
+			"cpu": {{
+				Chain: locator.CallChain{
+					TotalPct: 30,
+					Frames: []locator.StackFrame{
+						{FunctionName: "main.main", ShortName: "main", Category: locator.CategoryBusiness},
+						{FunctionName: target, ShortName: "Handle", Category: locator.CategoryBusiness},
+					},
+				},
+				BusinessFrames: []int{0, 1},
+				RootCauseIndex: 1,
+			}},
+		},
+		Commands: []locator.ExecutableCmd{{Command: "go tool pprof -list=Handle /data/cpu.2.pprof", Description: "查看 Handle 函数的源码级别分析"}},
+	}
+}
+
+func TestGenerateFunctionReport(t *testing.T) {
+	output := captureOutput(func() {
+		GenerateFunctionReport(newFunctionReport(), Options{})
+	})
+
+	assert.Contains(t, output, "函数分析")
+	assert.Contains(t, output, "🎯 github.com/myapp/orders.Handle")
+	assert.Contains(t, output, "分类: 💼 业务")
+	assert.Contains(t, output, "匹配: github.com/myapp/orders.Handle, github.com/myapp/orders.Handle.func1")
+
+	assert.Contains(t, output, "📁 cpu (cpu):")
+	assert.Contains(t, output, "最新快照: flat 20ms (2.0%), cum 300ms (30.0%)")
+	assert.Contains(t, output, "1. cpu.1.pprof  flat 10ms (1.0%), cum 100ms (10.0%)")
+	assert.Contains(t, output, "📈 趋势: 200ms/快照, R²=1.00, N=2 (increasing)")
+	assert.Contains(t, output, "热点 #1 (30.0%)")
+
+	assert.Contains(t, output, "最新快照: 1,200 个 goroutine (40.0%)")
+	assert.Contains(t, output, "📁 heap (alloc_space):\n───────────────────────────────────────────────────────────\n   未出现在该类 profile 的调用栈中")
+	assert.Contains(t, output, "$ go tool pprof -list=Handle /data/cpu.2.pprof")
+
+	// 热点路径只在 cpu 下出现一次
+	assert.Equal(t, 1, strings.Count(output, "热点调用链"))
+}
+
+func TestGenerateFunctionReport_NotFound(t *testing.T) {
+	output := captureOutput(func() {
+		GenerateFunctionReport(FunctionReport{
+			Explanation: &analyzer.FunctionExplanation{Function: "main.missing"},
+		}, Options{})
+	})
+
+	assert.Contains(t, output, "🎯 main.missing")
+	assert.Contains(t, output, "没有在任何 profile 中找到该函数")
+	assert.NotContains(t, output, "📁")
+}
+
+func TestGenerateFunctionReport_NoEmoji(t *testing.T) {
+	output := captureOutput(func() {
+		GenerateFunctionReport(newFunctionReport(), Options{NoEmoji: true})
+	})
+
+	assert.False(t, hasEmoji(output))
+	assert.Contains(t, output, "[TARGET] github.com/myapp/orders.Handle")
+}
+
+func TestFormatSampleValue(t *testing.T) {
+	assert.Equal(t, "1.5s", formatSampleValue(int64(1500*time.Millisecond), "nanoseconds"))
+	assert.Equal(t, "2.00 MB", formatSampleValue(2*1024*1024, "bytes"))
+	assert.Equal(t, "-2.00 MB", formatSampleValue(-2*1024*1024, "bytes"))
+	assert.Equal(t, "12,345", formatSampleValue(12345, "count"))
+}
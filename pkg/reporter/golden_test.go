@@ -0,0 +1,307 @@
+package reporter
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+	"github.com/songzhibin97/perfinspector/pkg/locator"
+	"github.com/songzhibin97/perfinspector/pkg/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// updateGolden 重新生成 golden 文件: go test ./pkg/reporter -run TestGolden -update
+var updateGolden = flag.Bool("update", false, "update golden report files")
+
+// goldenTime golden 报告使用的固定生成时间
+var goldenTime = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// goldenFixture golden 报告的固定输入
+type goldenFixture struct {
+	groups   []analyzer.ProfileGroup
+	trends   map[string]*analyzer.GroupTrends
+	findings []rules.Finding
+	contexts map[string]*locator.ProblemContext
+}
+
+// newGoldenFixture 创建覆盖各报告区块的合成数据
+func newGoldenFixture() goldenFixture {
+	const mb = 1024 * 1024
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	heap := analyzer.ProfileGroup{Type: "heap"}
+	// 第二个 heap 快照采样不足
+	heapSamples := []int64{1500, 300, 1600}
+	for i := 0; i < 3; i++ {
+		inuse := int64(100+i*50) * mb
+		heap.Files = append(heap.Files, analyzer.ProfileFile{
+			Path: fmt.Sprintf("/profiles/heap%d.pprof", i+1),
+			Time: base.Add(time.Duration(i) * 10 * time.Minute),
+			Size: 2048 * int64(i+1),
+			Metrics: &analyzer.ProfileMetrics{
+				TotalSamples: heapSamples[i],
+				AllocObjects: 5000, AllocSpace: 400 * mb, InuseObjects: 1200, InuseSpace: inuse,
+				CategoryTotals: map[string]int64{
+					"business": inuse / 2, "third_party": 20 * mb * int64(i+1), "runtime": inuse/2 - 20*mb*int64(i+1),
+				},
+				TopFunctions: []analyzer.FunctionStat{
+					{Name: "github.com/myapp/cache.(*LRU).Add", Flat: inuse / 2, FlatPct: 50, Cum: inuse / 2, CumPct: 50},
+					{Name: "encoding/json.Marshal", Flat: inuse / 4, FlatPct: 25, Cum: inuse / 4, CumPct: 25},
+				},
+				TopAllocFunctions: []analyzer.FunctionStat{
+					{Name: "bytes.growSlice", Flat: 200 * mb, FlatPct: 50, Cum: 200 * mb, CumPct: 50},
+				},
+			},
+		})
+	}
+
+	goroutine := analyzer.ProfileGroup{Type: "goroutine"}
+	for i := 0; i < 3; i++ {
+		goroutine.Files = append(goroutine.Files, analyzer.ProfileFile{
+			Path: fmt.Sprintf("/profiles/goroutine%d.pprof", i+1),
+			Time: base.Add(time.Duration(i) * 10 * time.Minute),
+			Size: 512,
+			Metrics: &analyzer.ProfileMetrics{
+				TotalSamples:   int64(12 + i*4),
+				GoroutineCount: int64(100 + i*100),
+				TopFunctions: []analyzer.FunctionStat{
+					{Name: "runtime.gopark", Flat: int64(100 + i*100), FlatPct: 100, Cum: int64(100 + i*100), CumPct: 100},
+				},
+			},
+		})
+	}
+
+	groups := []analyzer.ProfileGroup{goroutine, heap}
+	trends := make(map[string]*analyzer.GroupTrends)
+	for _, g := range groups {
+		trends[g.Type] = analyzer.CalculateTrends(g)
+	}
+
+	findings := []rules.Finding{
+		{
+			RuleID: "memory_leak", RuleName: "内存持续增长", Severity: "high", Title: "📈 持续内存增长趋势",
+			Evidence: rules.EvidenceFromMap(map[string]string{
+				"增长速率": "5.00 MB/min", "置信度": "1.00", "文件数": "3", "方向": "increasing", "总增长": "100.00 MB",
+			}),
+			Suggestions:  []string{"检查缓存是否有上限"},
+			ProfileTypes: []string{"heap"},
+		},
+		{
+			RuleID: "goroutine_leak", RuleName: "Goroutine 泄漏", Severity: "critical", Title: "🔄 Goroutine 持续增长",
+			Evidence:     rules.EvidenceFromMap(map[string]string{"增长": "100/采样", "置信度": "1.00"}),
+			Suggestions:  []string{"检查 goroutine 是否有退出条件"},
+			ProfileTypes: []string{"goroutine"},
+		},
+		{
+			RuleID: "memory_goroutine_leak", RuleName: "联合泄漏", Severity: "critical", Title: "🚨 内存与 goroutine 同步增长",
+			Evidence:        rules.EvidenceFromMap(map[string]string{"heap 斜率": "50.00 MB", "goroutine 斜率": "100"}),
+			IsCrossAnalysis: true,
+			ProfileTypes:    []string{"goroutine", "heap"},
+		},
+	}
+
+	contexts := map[string]*locator.ProblemContext{
+		"memory_leak": {
+			Title:       "📈 持续内存增长趋势",
+			Severity:    "high",
+			Explanation: "检测到内存使用持续增长，可能存在内存泄漏。",
+			Ownership: &locator.OwnershipTree{
+				SampleType: "inuse_space",
+				Total:      4 << 20,
+				Owners: []locator.OwnershipNode{
+					{Name: "github.com/myapp/cache", Category: locator.CategoryBusiness, Value: 3 << 20, Pct: 75, Children: []locator.OwnershipNode{
+						{Name: "(*Cache).Set", Category: locator.CategoryBusiness, Location: "/src/cache/cache.go:27", Value: 3 << 20, Pct: 75, Children: []locator.OwnershipNode{
+							{Name: "github.com/myapp/cache", Category: locator.CategoryBusiness, Value: 2 << 20, Pct: 50},
+							{Name: "bytes", Category: locator.CategoryStdlib, Value: 1 << 20, Pct: 25},
+						}},
+					}},
+					{Name: locator.NoBusinessOwner, Category: locator.CategoryUnknown, Value: 1 << 20, Pct: 25, Children: []locator.OwnershipNode{
+						{Name: "runtime", Category: locator.CategoryRuntime, Value: 1 << 20, Pct: 25},
+					}},
+				},
+			},
+		},
+		"goroutine_leak": {
+			Title:       "🔄 Goroutine 持续增长",
+			Severity:    "critical",
+			Explanation: "检测到 goroutine 数量持续增长，可能存在 goroutine 泄漏。",
+			Impact:      "热点路径占 goroutine 总数的 90.0%",
+			HotPaths: []locator.HotPath{
+				{
+					Chain: locator.CallChain{
+						TotalValue: 270,
+						TotalPct:   90,
+						Frames: []locator.StackFrame{
+							{FunctionName: "main.main", ShortName: "main", PackageName: "main", FilePath: "/src/main.go", LineNumber: 10, Category: locator.CategoryBusiness},
+							{FunctionName: "github.com/myapp/worker.(*Pool).Start", ShortName: "Start", PackageName: "github.com/myapp/worker",
+								FilePath: "/src/worker/pool.go", LineNumber: 42, Category: locator.CategoryBusiness},
+							{FunctionName: "runtime.gopark", ShortName: "gopark", PackageName: "runtime", Category: locator.CategoryRuntime},
+						},
+					},
+					BusinessFrames: []int{0, 1},
+					RootCauseIndex: 1,
+					ProfileType:    "goroutine",
+				},
+			},
+			Commands: []locator.ExecutableCmd{
+				{Command: "go tool pprof -top /profiles/goroutine3.pprof", Description: "查看 goroutine 分布", OutputHint: "关注数量最多的栈"},
+				{Command: "go tool pprof -focus=Start /profiles/goroutine3.pprof", Description: "聚焦到 Start 函数"},
+			},
+			PrimaryCommand: &locator.ExecutableCmd{Command: "go tool pprof -focus=Start /profiles/goroutine3.pprof", Description: "聚焦到 Start 函数"},
+			Suggestions: []locator.Suggestion{
+				{Category: "immediate", Content: "为 Pool.Start 启动的 goroutine 增加退出条件"},
+				{Category: "long_term", Content: "使用 context 管理 goroutine 生命周期"},
+			},
+			WindowShift: &locator.WindowComparison{
+				Pivot:  base.Add(10 * time.Minute),
+				Before: 1,
+				After:  2,
+				Grew: []locator.PathShift{{
+					Chain: locator.CallChain{Frames: []locator.StackFrame{
+						{FunctionName: "github.com/myapp/worker.(*Pool).Start", ShortName: "Start", FilePath: "/src/worker/pool.go", LineNumber: 42, Category: locator.CategoryBusiness},
+					}},
+					BeforePct: 60, AfterPct: 90, DeltaPct: 30,
+				}},
+				Shrank: []locator.PathShift{{
+					Chain: locator.CallChain{Frames: []locator.StackFrame{
+						{FunctionName: "net/http.(*conn).serve", ShortName: "serve", Category: locator.CategoryStdlib},
+					}},
+					BeforePct: 40, AfterPct: 10, DeltaPct: -30,
+				}},
+			},
+		},
+	}
+
+	return goldenFixture{groups: groups, trends: trends, findings: findings, contexts: contexts}
+}
+
+// goldenOptions golden 报告使用的渲染选项
+func goldenOptions() Options {
+	opts := DefaultOptions()
+	opts.GeneratedAt = goldenTime
+	opts.Version = "v0.1"
+	return opts
+}
+
+// captureStdout 捕获 f 写到标准输出的内容，边写边读避免管道缓冲区写满
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+	old := os.Stdout
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stdout = w
+
+	done := make(chan []byte)
+	go func() {
+		var buf bytes.Buffer
+		io.Copy(&buf, r)
+		done <- buf.Bytes()
+	}()
+
+	defer func() { os.Stdout = old }()
+	f()
+	w.Close()
+	return string(<-done)
+}
+
+// renderGolden 按格式渲染 golden fixture
+func renderGolden(t *testing.T, format string) string {
+	t.Helper()
+	return renderGoldenWithOptions(t, format, goldenOptions())
+}
+
+// renderGoldenWithOptions 使用指定选项按格式渲染 golden fixture
+func renderGoldenWithOptions(t *testing.T, format string, opts Options) string {
+	t.Helper()
+	fx := newGoldenFixture()
+
+	switch format {
+	case "text":
+		return captureStdout(t, func() {
+			GenerateTextReportWithOptions(fx.groups, fx.trends, fx.findings, fx.contexts, opts)
+		})
+	case "html":
+		outputPath := filepath.Join(t.TempDir(), "report.html")
+		require.NoError(t, GenerateHTMLReportWithOptions(fx.groups, fx.trends, fx.findings, fx.contexts, outputPath, opts))
+		content, err := os.ReadFile(outputPath)
+		require.NoError(t, err)
+		return string(content)
+	case "json":
+		outputPath := filepath.Join(t.TempDir(), "report.json")
+		require.NoError(t, GenerateJSONReportWithOptions(fx.groups, fx.trends, fx.findings, fx.contexts, outputPath, opts))
+		content, err := os.ReadFile(outputPath)
+		require.NoError(t, err)
+		return string(content)
+	case "markdown":
+		outputPath := filepath.Join(t.TempDir(), "report.md")
+		require.NoError(t, GenerateMarkdownReportWithOptions(fx.groups, fx.trends, fx.findings, fx.contexts, outputPath, opts))
+		content, err := os.ReadFile(outputPath)
+		require.NoError(t, err)
+		return string(content)
+	case "dot":
+		var buf bytes.Buffer
+		require.NoError(t, WriteDOTGraph(&buf, fx.findings, fx.contexts, opts))
+		return buf.String()
+	}
+	t.Fatalf("unknown golden format %q", format)
+	return ""
+}
+
+// assertGolden 将输出与 testdata/golden 下的文件比较，-update 时重新生成
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", "golden", name)
+	if *updateGolden {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(got), 0644))
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	require.NoError(t, err, "golden file missing, run: go test ./pkg/reporter -run TestGolden -update")
+	assert.Equal(t, string(want), got, "report differs from %s, run with -update if the change is intended", path)
+}
+
+func TestGoldenReports(t *testing.T) {
+	for _, tc := range []struct {
+		format string
+		file   string
+	}{
+		{"text", "report.txt.golden"},
+		{"html", "report.html.golden"},
+		{"json", "report.json.golden"},
+		{"markdown", "report.md.golden"},
+		{"dot", "report.dot.golden"},
+	} {
+		t.Run(tc.format, func(t *testing.T) {
+			assertGolden(t, tc.file, renderGolden(t, tc.format))
+		})
+	}
+}
+
+// TestGoldenReports_Deterministic 多次渲染同一输入应得到完全相同的输出
+func TestGoldenReports_Deterministic(t *testing.T) {
+	for _, format := range []string{"text", "html", "json", "markdown", "dot"} {
+		t.Run(format, func(t *testing.T) {
+			first := renderGolden(t, format)
+			for i := 0; i < 5; i++ {
+				assert.Equal(t, first, renderGolden(t, format))
+			}
+		})
+	}
+}
+
+func TestOptions_GeneratedAt(t *testing.T) {
+	opts := DefaultOptions()
+	assert.WithinDuration(t, time.Now(), opts.generatedAt(), time.Minute)
+
+	opts.GeneratedAt = time.Date(2024, 1, 2, 11, 4, 5, 0, time.FixedZone("CST", 8*3600))
+	assert.Equal(t, goldenTime, opts.generatedAt())
+}
@@ -0,0 +1,209 @@
+package reporter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+	"github.com/songzhibin97/perfinspector/pkg/rules"
+)
+
+// 运行历史中记录的关键指标名称，写入历史文件，保持稳定
+const (
+	HistoryHeapInuse  = "heap_inuse_bytes"
+	HistoryHeapAlloc  = "heap_alloc_bytes"
+	HistoryGoroutines = "goroutine_count"
+	HistoryCPUTime    = "cpu_seconds"
+	HistoryFindings   = "findings_total"
+)
+
+// historyFileVersion 历史文件格式版本
+const historyFileVersion = 1
+
+// historyMetric 历史对比中展示的指标
+type historyMetric struct {
+	name   string
+	label  string
+	format func(float64) string
+}
+
+// historyMetrics 按报告中的展示顺序列出可对比的指标
+var historyMetrics = []historyMetric{
+	{HistoryHeapInuse, "heap 使用中内存", func(v float64) string { return formatSize(int64(v)) }},
+	{HistoryHeapAlloc, "heap 累计分配", func(v float64) string { return formatSize(int64(v)) }},
+	{HistoryGoroutines, "goroutine 数", formatHistoryCount},
+	{HistoryCPUTime, "CPU 时间", func(v float64) string {
+		return time.Duration(v * float64(time.Second)).Round(time.Millisecond).String()
+	}},
+	{HistoryFindings, "发现数", formatHistoryCount},
+}
+
+// RunSummary 一次分析运行的关键指标摘要
+type RunSummary struct {
+	Time     time.Time          `json:"time"`
+	Metrics  map[string]float64 `json:"metrics"`
+	Findings []FindingRecord    `json:"findings,omitempty"` // 本次运行的发现，用于判断发现是否新增、恶化或已解决
+}
+
+// History 按输入目录保存的上一次运行摘要
+type History struct {
+	Version int                   `json:"version"`
+	Runs    map[string]RunSummary `json:"runs"` // 输入路径标识 -> 上一次运行
+}
+
+// HistoryDelta 单个指标与上一次运行相比的变化
+type HistoryDelta struct {
+	Metric    string
+	Label     string
+	Previous  float64
+	Current   float64
+	ChangePct float64 // 相对变化百分比，上一次为 0 时为 0
+}
+
+// HistoryComparison 本次运行与上一次运行的对比
+type HistoryComparison struct {
+	PreviousTime time.Time
+	Deltas       []HistoryDelta
+}
+
+// SummarizeRun 提取本次运行的关键指标：heap/goroutine/cpu 取组内最新快照，发现数为总数
+// 没有对应 profile 类型的指标不记录，下次运行也就不会对比它
+func SummarizeRun(groups []analyzer.ProfileGroup, findings []rules.Finding, at time.Time) RunSummary {
+	summary := RunSummary{Time: at.UTC(), Metrics: map[string]float64{HistoryFindings: float64(len(findings))}}
+	for _, group := range groups {
+		latest := latestMetrics(group)
+		if latest == nil {
+			continue
+		}
+		switch group.Type {
+		case "heap":
+			summary.Metrics[HistoryHeapInuse] = float64(latest.InuseSpace)
+			summary.Metrics[HistoryHeapAlloc] = float64(latest.AllocSpace)
+		case "goroutine":
+			summary.Metrics[HistoryGoroutines] = float64(latest.GoroutineCount)
+		case "cpu":
+			// 缺少采集时长时没有可对比的 CPU 时间
+			if !latest.NoDuration {
+				summary.Metrics[HistoryCPUTime] = latest.CPUTime.Seconds()
+			}
+		}
+	}
+	return summary
+}
+
+// LoadHistory 读取历史文件，文件不存在时返回空历史
+func LoadHistory(path string) (*History, error) {
+	history := &History{Version: historyFileVersion, Runs: make(map[string]RunSummary)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return history, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+	if err := json.Unmarshal(data, history); err != nil {
+		return nil, fmt.Errorf("failed to parse history file '%s': %w", path, err)
+	}
+	if history.Runs == nil {
+		history.Runs = make(map[string]RunSummary)
+	}
+	return history, nil
+}
+
+// Compare 对比 key 对应的上一次运行与本次运行，没有上一次运行或没有共同指标时返回 nil
+func (h *History) Compare(key string, current RunSummary) *HistoryComparison {
+	previous, ok := h.Runs[key]
+	if !ok {
+		return nil
+	}
+
+	comparison := &HistoryComparison{PreviousTime: previous.Time}
+	for _, metric := range historyMetrics {
+		before, ok := previous.Metrics[metric.name]
+		if !ok {
+			continue
+		}
+		after, ok := current.Metrics[metric.name]
+		if !ok {
+			continue
+		}
+		delta := HistoryDelta{Metric: metric.name, Label: metric.label, Previous: before, Current: after}
+		if before != 0 {
+			delta.ChangePct = (after - before) / math.Abs(before) * 100
+		}
+		comparison.Deltas = append(comparison.Deltas, delta)
+	}
+	if len(comparison.Deltas) == 0 {
+		return nil
+	}
+	return comparison
+}
+
+// Record 用本次运行替换 key 对应的上一次运行
+func (h *History) Record(key string, summary RunSummary) {
+	h.Runs[key] = summary
+}
+
+// SaveHistory 写入历史文件，先写临时文件再重命名，中断时不会留下不完整的文件
+func SaveHistory(path string, h *History) error {
+	h.Version = historyFileVersion
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode history: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create history file for '%s': %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write history file '%s': %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write history file '%s': %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write history file '%s': %w", path, err)
+	}
+	return nil
+}
+
+// String 返回变化的展示文本，如 "heap 使用中内存 +12.0% (1.00 MB → 1.12 MB)"
+func (d HistoryDelta) String() string {
+	format := formatHistoryCount
+	for _, metric := range historyMetrics {
+		if metric.name == d.Metric {
+			format = metric.format
+		}
+	}
+	change := "持平"
+	switch {
+	case d.Previous == 0 && d.Current != 0:
+		change = "新增"
+	case d.Current != d.Previous:
+		change = fmt.Sprintf("%+.1f%%", d.ChangePct)
+	}
+	return fmt.Sprintf("%s %s (%s → %s)", d.Label, change, format(d.Previous), format(d.Current))
+}
+
+// Header 返回对比的标题，如 "与上次运行对比 (2024-01-01T10:00:00Z)"
+func (c *HistoryComparison) Header() string {
+	if c.PreviousTime.IsZero() {
+		return "与上次运行对比"
+	}
+	return fmt.Sprintf("与上次运行对比 (%s)", c.PreviousTime.UTC().Format(time.RFC3339))
+}
+
+// formatHistoryCount 格式化计数类指标
+func formatHistoryCount(v float64) string {
+	return analyzer.FormatInt(int64(math.Round(v)))
+}
@@ -0,0 +1,192 @@
+package reporter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/songzhibin97/perfinspector/pkg/locator"
+	"github.com/songzhibin97/perfinspector/pkg/rules"
+)
+
+// 发现相对上一次运行的变化状态
+const (
+	FindingNew      = "new"      // 上一次运行没有该发现
+	FindingWorse    = "worse"    // 严重程度升高或根因热点占比明显增大
+	FindingSame     = "same"     // 与上一次运行相比没有明显变化
+	FindingResolved = "resolved" // 上一次运行有、本次运行没有
+)
+
+// DefaultFindingWorsePct 根因热点路径的占比增加 5 个百分点以上视为恶化
+const DefaultFindingWorsePct = 5.0
+
+// FindingRecord 运行历史中记录的单个发现，按规则 ID + 根因函数识别
+type FindingRecord struct {
+	RuleID    string  `json:"rule_id"`
+	RootCause string  `json:"root_cause,omitempty"` // 排名第一的热点路径的根因函数，没有时为空
+	Title     string  `json:"title"`
+	Severity  string  `json:"severity"`
+	Pct       float64 `json:"pct,omitempty"` // 排名第一的热点路径占比 (0-100)
+}
+
+// FindingChange 单个发现相对上一次运行的变化
+// Status 为 FindingResolved 时 Current 为 nil，为 FindingNew 时 Previous 为 nil
+type FindingChange struct {
+	Status   string
+	Current  *FindingRecord
+	Previous *FindingRecord
+}
+
+// FindingComparison 本次运行的发现与上一次运行的对比
+type FindingComparison struct {
+	PreviousTime time.Time
+	Changes      []FindingChange // 本次运行的发现按 findings 顺序在前，已解决的发现在后
+	Hidden       int             // -only-new 隐藏的未变化发现数
+}
+
+// Key 返回发现在运行历史中的标识：规则 ID 和根因函数
+func (r FindingRecord) Key() string {
+	if r.RootCause == "" {
+		return r.RuleID
+	}
+	return r.RuleID + "@" + r.RootCause
+}
+
+// label 返回发现的展示文本，如 "🔥 CPU 热点 (根因: main.work)"
+func (r FindingRecord) label() string {
+	if r.RootCause == "" {
+		return r.Title
+	}
+	return fmt.Sprintf("%s (根因: %s)", r.Title, r.RootCause)
+}
+
+// SummarizeFindings 提取每个发现的记录，根因和占比取自问题上下文中排名第一的热点路径
+func SummarizeFindings(findings []rules.Finding, contexts map[string]*locator.ProblemContext) []FindingRecord {
+	records := make([]FindingRecord, 0, len(findings))
+	for _, finding := range findings {
+		record := FindingRecord{RuleID: finding.RuleID, Title: finding.Title, Severity: finding.Severity}
+		if ctx := contexts[finding.RuleID]; ctx != nil && len(ctx.HotPaths) > 0 {
+			top := ctx.HotPaths[0]
+			record.Pct = top.Chain.TotalPct
+			if root := top.GetRootCause(); root != nil {
+				record.RootCause = root.FunctionName
+			}
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// CompareFindings 将本次运行的发现与 key 对应的上一次运行逐个对比
+// 没有上一次运行时返回 nil，此时所有发现都视为新增
+func (h *History) CompareFindings(key string, current RunSummary) *FindingComparison {
+	previous, ok := h.Runs[key]
+	if !ok {
+		return nil
+	}
+
+	before := make(map[string]*FindingRecord, len(previous.Findings))
+	for i := range previous.Findings {
+		before[previous.Findings[i].Key()] = &previous.Findings[i]
+	}
+
+	comparison := &FindingComparison{PreviousTime: previous.Time}
+	seen := make(map[string]bool, len(current.Findings))
+	for i := range current.Findings {
+		record := &current.Findings[i]
+		seen[record.Key()] = true
+		change := FindingChange{Status: FindingNew, Current: record, Previous: before[record.Key()]}
+		if change.Previous != nil {
+			change.Status = compareFindingRecords(*change.Previous, *record)
+		}
+		comparison.Changes = append(comparison.Changes, change)
+	}
+	for i := range previous.Findings {
+		record := &previous.Findings[i]
+		if !seen[record.Key()] {
+			seen[record.Key()] = true
+			comparison.Changes = append(comparison.Changes, FindingChange{Status: FindingResolved, Previous: record})
+		}
+	}
+	return comparison
+}
+
+// compareFindingRecords 判断同一发现是否恶化：严重程度升高，或占比增加 DefaultFindingWorsePct 个百分点以上
+func compareFindingRecords(previous, current FindingRecord) string {
+	if severityRank(current.Severity) > severityRank(previous.Severity) {
+		return FindingWorse
+	}
+	if current.Pct-previous.Pct >= DefaultFindingWorsePct {
+		return FindingWorse
+	}
+	return FindingSame
+}
+
+// Status 返回规则 ID 对应的发现的变化状态，c 为 nil (没有上一次运行) 时为 FindingNew
+func (c *FindingComparison) Status(ruleID string) string {
+	if c == nil {
+		return FindingNew
+	}
+	for _, change := range c.Changes {
+		if change.Current != nil && change.Current.RuleID == ruleID {
+			return change.Status
+		}
+	}
+	return FindingNew
+}
+
+// Count 返回指定状态的发现数
+func (c *FindingComparison) Count(status string) int {
+	if c == nil {
+		return 0
+	}
+	count := 0
+	for _, change := range c.Changes {
+		if change.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// OnlyChanged 只保留新增和恶化的发现，返回保留的发现和隐藏的发现数
+func (c *FindingComparison) OnlyChanged(findings []rules.Finding) ([]rules.Finding, int) {
+	kept := make([]rules.Finding, 0, len(findings))
+	for _, finding := range findings {
+		switch c.Status(finding.RuleID) {
+		case FindingNew, FindingWorse:
+			kept = append(kept, finding)
+		}
+	}
+	return kept, len(findings) - len(kept)
+}
+
+// String 返回变化的展示文本，如 "恶化: 🔥 CPU 热点 (根因: main.work) 12.0% → 30.0%"
+func (c FindingChange) String() string {
+	switch c.Status {
+	case FindingNew:
+		return "新增: " + c.Current.label()
+	case FindingResolved:
+		return "已解决: " + c.Previous.label()
+	case FindingWorse:
+		text := "恶化: " + c.Current.label()
+		if severityRank(c.Current.Severity) > severityRank(c.Previous.Severity) {
+			text += fmt.Sprintf(" 严重程度 %s → %s", c.Previous.Severity, c.Current.Severity)
+		}
+		if c.Current.Pct != c.Previous.Pct {
+			text += fmt.Sprintf(" %.1f%% → %.1f%%", c.Previous.Pct, c.Current.Pct)
+		}
+		return text
+	default:
+		return "持平: " + c.Current.label()
+	}
+}
+
+// Summary 返回各状态的发现数，如 "新增 1, 恶化 1, 持平 2, 已解决 1"
+func (c *FindingComparison) Summary() string {
+	text := fmt.Sprintf("新增 %d, 恶化 %d, 持平 %d, 已解决 %d",
+		c.Count(FindingNew), c.Count(FindingWorse), c.Count(FindingSame), c.Count(FindingResolved))
+	if c.Hidden > 0 {
+		text += fmt.Sprintf(" (已隐藏 %d 个未变化的发现)", c.Hidden)
+	}
+	return text
+}
@@ -0,0 +1,143 @@
+package reporter
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/songzhibin97/perfinspector/pkg/locator"
+	"github.com/songzhibin97/perfinspector/pkg/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRootCauseContext 创建排名第一的热点路径根因为 root、占比为 pct 的问题上下文
+func newRootCauseContext(root string, pct float64) *locator.ProblemContext {
+	return &locator.ProblemContext{
+		HotPaths: []locator.HotPath{{
+			Chain: locator.CallChain{
+				TotalPct: pct,
+				Frames:   []locator.StackFrame{{FunctionName: "main.main"}, {FunctionName: root}},
+			},
+			RootCauseIndex: 1,
+		}},
+	}
+}
+
+func TestSummarizeFindings(t *testing.T) {
+	findings := []rules.Finding{
+		{RuleID: "cpu_hotspot", Title: "CPU 热点", Severity: "high"},
+		{RuleID: "goroutine_leak", Title: "goroutine 泄漏", Severity: "critical"},
+	}
+	contexts := map[string]*locator.ProblemContext{"cpu_hotspot": newRootCauseContext("main.work", 42)}
+
+	records := SummarizeFindings(findings, contexts)
+	assert.Equal(t, []FindingRecord{
+		{RuleID: "cpu_hotspot", RootCause: "main.work", Title: "CPU 热点", Severity: "high", Pct: 42},
+		{RuleID: "goroutine_leak", Title: "goroutine 泄漏", Severity: "critical"},
+	}, records)
+	assert.Equal(t, "cpu_hotspot@main.work", records[0].Key())
+	assert.Equal(t, "goroutine_leak", records[1].Key())
+}
+
+func TestHistory_CompareFindings(t *testing.T) {
+	history := &History{Runs: map[string]RunSummary{
+		"/data": {Time: historyRunTime, Findings: []FindingRecord{
+			{RuleID: "cpu_hotspot", RootCause: "main.work", Title: "CPU 热点", Severity: "medium", Pct: 20},
+			{RuleID: "heap_growth", RootCause: "main.cache", Title: "内存增长", Severity: "high", Pct: 30},
+			{RuleID: "mutex", RootCause: "main.lock", Title: "锁竞争", Severity: "high", Pct: 10},
+			{RuleID: "gc", Title: "GC 压力", Severity: "medium"},
+		}},
+	}}
+	current := RunSummary{Findings: []FindingRecord{
+		{RuleID: "cpu_hotspot", RootCause: "main.work", Title: "CPU 热点", Severity: "medium", Pct: 32},
+		{RuleID: "heap_growth", RootCause: "main.cache", Title: "内存增长", Severity: "high", Pct: 31},
+		// 根因变化视为新的发现，原根因的发现已解决
+		{RuleID: "mutex", RootCause: "main.other", Title: "锁竞争", Severity: "high", Pct: 10},
+		{RuleID: "gc", Title: "GC 压力", Severity: "critical"},
+		{RuleID: "goroutine_leak", Title: "goroutine 泄漏", Severity: "high"},
+	}}
+
+	comparison := history.CompareFindings("/data", current)
+	require.NotNil(t, comparison)
+	assert.Equal(t, historyRunTime, comparison.PreviousTime)
+
+	statuses := make([]string, 0, len(comparison.Changes))
+	for _, change := range comparison.Changes {
+		statuses = append(statuses, change.Status)
+	}
+	assert.Equal(t, []string{FindingWorse, FindingSame, FindingNew, FindingWorse, FindingNew, FindingResolved}, statuses)
+	assert.Equal(t, "main.lock", comparison.Changes[5].Previous.RootCause)
+	assert.Nil(t, comparison.Changes[5].Current)
+
+	assert.Equal(t, FindingWorse, comparison.Status("cpu_hotspot"))
+	assert.Equal(t, FindingSame, comparison.Status("heap_growth"))
+	assert.Equal(t, FindingNew, comparison.Status("goroutine_leak"))
+	assert.Equal(t, "新增 2, 恶化 2, 持平 1, 已解决 1", comparison.Summary())
+
+	// 没有上一次运行时不对比，所有发现视为新增
+	assert.Nil(t, history.CompareFindings("/other", current))
+	var none *FindingComparison
+	assert.Equal(t, FindingNew, none.Status("cpu_hotspot"))
+	assert.Zero(t, none.Count(FindingNew))
+}
+
+func TestFindingComparison_OnlyChanged(t *testing.T) {
+	history := &History{Runs: map[string]RunSummary{
+		"/data": {Findings: []FindingRecord{{RuleID: "stable", Severity: "high"}}},
+	}}
+	comparison := history.CompareFindings("/data", RunSummary{Findings: []FindingRecord{
+		{RuleID: "stable", Severity: "high"},
+		{RuleID: "fresh", Severity: "low"},
+	}})
+	require.NotNil(t, comparison)
+
+	kept, hidden := comparison.OnlyChanged([]rules.Finding{{RuleID: "stable"}, {RuleID: "fresh"}})
+	assert.Equal(t, []rules.Finding{{RuleID: "fresh"}}, kept)
+	assert.Equal(t, 1, hidden)
+
+	comparison.Hidden = hidden
+	assert.Equal(t, "新增 1, 恶化 0, 持平 1, 已解决 0 (已隐藏 1 个未变化的发现)", comparison.Summary())
+}
+
+func TestFindingChange_String(t *testing.T) {
+	previous := &FindingRecord{RuleID: "cpu_hotspot", RootCause: "main.work", Title: "CPU 热点", Severity: "medium", Pct: 12}
+	current := &FindingRecord{RuleID: "cpu_hotspot", RootCause: "main.work", Title: "CPU 热点", Severity: "high", Pct: 30}
+
+	assert.Equal(t, "新增: CPU 热点 (根因: main.work)", FindingChange{Status: FindingNew, Current: current}.String())
+	assert.Equal(t, "已解决: CPU 热点 (根因: main.work)", FindingChange{Status: FindingResolved, Previous: previous}.String())
+	assert.Equal(t, "恶化: CPU 热点 (根因: main.work) 严重程度 medium → high 12.0% → 30.0%",
+		FindingChange{Status: FindingWorse, Current: current, Previous: previous}.String())
+}
+
+func TestRunSummary_FindingsRoundTrip(t *testing.T) {
+	summary := RunSummary{Time: historyRunTime, Metrics: map[string]float64{HistoryFindings: 1},
+		Findings: []FindingRecord{{RuleID: "cpu_hotspot", RootCause: "main.work", Title: "CPU 热点", Severity: "high", Pct: 42}}}
+	data, err := json.Marshal(summary)
+	require.NoError(t, err)
+
+	var decoded RunSummary
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, summary, decoded)
+
+	// 旧版本的历史文件没有 findings 字段
+	data, err = json.Marshal(RunSummary{Time: historyRunTime, Metrics: map[string]float64{}})
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "findings")
+}
+
+func TestPrintFindingChanges(t *testing.T) {
+	comparison := &FindingComparison{Changes: []FindingChange{
+		{Status: FindingNew, Current: &FindingRecord{RuleID: "a", Title: "新问题"}},
+		{Status: FindingSame, Current: &FindingRecord{RuleID: "b", Title: "老问题"}},
+		{Status: FindingResolved, Previous: &FindingRecord{RuleID: "c", Title: "已修复"}},
+	}}
+
+	output := captureOutput(func() { printFindingChanges(os.Stdout, comparison) })
+	assert.Contains(t, output, "📋 发现变化: 新增 1, 恶化 0, 持平 1, 已解决 1")
+	assert.Contains(t, output, "🆕 新增: 新问题")
+	assert.Contains(t, output, "✅ 已解决: 已修复")
+	assert.NotContains(t, output, "老问题")
+
+	assert.Empty(t, captureOutput(func() { printFindingChanges(os.Stdout, nil) }))
+}
@@ -0,0 +1,147 @@
+package reporter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+	"github.com/songzhibin97/perfinspector/pkg/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var historyRunTime = time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+// newHistoryGroups 创建 heap 使用中内存为 inuse、goroutine 数为 goroutines 的分组
+func newHistoryGroups(inuse, goroutines int64) []analyzer.ProfileGroup {
+	return []analyzer.ProfileGroup{
+		{
+			Type: "heap",
+			Files: []analyzer.ProfileFile{
+				{Path: "/heap1.pprof", Metrics: &analyzer.ProfileMetrics{InuseSpace: 1, AllocSpace: 1}},
+				{Path: "/heap2.pprof", Metrics: &analyzer.ProfileMetrics{InuseSpace: inuse, AllocSpace: 2 * inuse}},
+			},
+		},
+		{
+			Type:  "goroutine",
+			Files: []analyzer.ProfileFile{{Path: "/goroutine.pprof", Metrics: &analyzer.ProfileMetrics{GoroutineCount: goroutines}}},
+		},
+	}
+}
+
+func TestSummarizeRun(t *testing.T) {
+	groups := append(newHistoryGroups(4096, 120), analyzer.ProfileGroup{
+		Type:  "cpu",
+		Files: []analyzer.ProfileFile{{Path: "/cpu.pprof", Metrics: &analyzer.ProfileMetrics{CPUTime: 1500 * time.Millisecond}}},
+	})
+	findings := []rules.Finding{{RuleID: "a"}, {RuleID: "b"}}
+
+	summary := SummarizeRun(groups, findings, historyRunTime)
+	assert.Equal(t, historyRunTime, summary.Time)
+	assert.Equal(t, map[string]float64{
+		HistoryHeapInuse:  4096,
+		HistoryHeapAlloc:  8192,
+		HistoryGoroutines: 120,
+		HistoryCPUTime:    1.5,
+		HistoryFindings:   2,
+	}, summary.Metrics)
+
+	// 缺少采集时长的 CPU profile 不记录 CPU 时间
+	summary = SummarizeRun([]analyzer.ProfileGroup{{
+		Type:  "cpu",
+		Files: []analyzer.ProfileFile{{Path: "/cpu.pprof", Metrics: &analyzer.ProfileMetrics{NoDuration: true}}},
+	}}, nil, historyRunTime)
+	assert.NotContains(t, summary.Metrics, HistoryCPUTime)
+
+	// 没有 profile 的类型不记录
+	summary = SummarizeRun(nil, nil, historyRunTime)
+	assert.Equal(t, map[string]float64{HistoryFindings: 0}, summary.Metrics)
+}
+
+func TestHistory_Compare(t *testing.T) {
+	history := &History{Runs: make(map[string]RunSummary)}
+	current := SummarizeRun(newHistoryGroups(1120*1024, 120), nil, historyRunTime.Add(time.Hour))
+	assert.Nil(t, history.Compare("/profiles", current))
+
+	history.Record("/profiles", SummarizeRun(newHistoryGroups(1000*1024, 120), []rules.Finding{{RuleID: "a"}}, historyRunTime))
+	comparison := history.Compare("/profiles", current)
+	require.NotNil(t, comparison)
+	assert.Equal(t, historyRunTime, comparison.PreviousTime)
+	assert.Equal(t, "与上次运行对比 (2024-01-01T10:00:00Z)", comparison.Header())
+
+	// 按固定顺序展示：heap 使用中内存、heap 累计分配、goroutine 数、发现数
+	require.Len(t, comparison.Deltas, 4)
+	assert.Equal(t, HistoryHeapInuse, comparison.Deltas[0].Metric)
+	assert.InDelta(t, 12.0, comparison.Deltas[0].ChangePct, 0.001)
+	assert.Equal(t, "heap 使用中内存 +12.0% (1000.00 KB → 1.09 MB)", comparison.Deltas[0].String())
+	assert.Equal(t, "goroutine 数 持平 (120 → 120)", comparison.Deltas[2].String())
+	assert.Equal(t, "发现数 -100.0% (1 → 0)", comparison.Deltas[3].String())
+
+	// 其他目录的历史互不影响
+	assert.Nil(t, history.Compare("/other", current))
+}
+
+func TestHistoryDelta_String(t *testing.T) {
+	assert.Equal(t, "发现数 新增 (0 → 3)", HistoryDelta{Metric: HistoryFindings, Label: "发现数", Current: 3}.String())
+	assert.Equal(t, "CPU 时间 +50.0% (2s → 3s)", HistoryDelta{Metric: HistoryCPUTime, Label: "CPU 时间", Previous: 2, Current: 3, ChangePct: 50}.String())
+}
+
+func TestLoadSaveHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+
+	// 文件不存在时返回空历史
+	history, err := LoadHistory(path)
+	require.NoError(t, err)
+	assert.Empty(t, history.Runs)
+
+	summary := SummarizeRun(newHistoryGroups(4096, 120), nil, historyRunTime)
+	history.Record("/profiles", summary)
+	require.NoError(t, SaveHistory(path, history))
+
+	loaded, err := LoadHistory(path)
+	require.NoError(t, err)
+	assert.Equal(t, historyFileVersion, loaded.Version)
+	assert.Equal(t, summary, loaded.Runs["/profiles"])
+
+	require.NoError(t, os.WriteFile(path, []byte("{not json"), 0o644))
+	_, err = LoadHistory(path)
+	assert.Error(t, err)
+
+	assert.Error(t, SaveHistory(filepath.Join(t.TempDir(), "missing", "history.json"), history))
+}
+
+func TestGenerateTextReport_History(t *testing.T) {
+	opts := DefaultOptions()
+	opts.History = &HistoryComparison{
+		PreviousTime: historyRunTime,
+		Deltas:       []HistoryDelta{{Metric: HistoryGoroutines, Label: "goroutine 数", Previous: 100, Current: 150, ChangePct: 50}},
+	}
+
+	output := captureOutput(func() {
+		GenerateTextReportWithOptions(newHistoryGroups(4096, 150), nil, nil, nil, opts)
+	})
+	assert.Contains(t, output, "🕘 与上次运行对比 (2024-01-01T10:00:00Z):")
+	assert.Contains(t, output, "• goroutine 数 +50.0% (100 → 150)")
+
+	output = captureOutput(func() {
+		GenerateTextReportWithOptions(newHistoryGroups(4096, 150), nil, nil, nil, DefaultOptions())
+	})
+	assert.NotContains(t, output, "与上次运行对比")
+}
+
+func TestGenerateHTMLReport_History(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "report.html")
+	opts := DefaultOptions()
+	opts.History = &HistoryComparison{
+		PreviousTime: historyRunTime,
+		Deltas:       []HistoryDelta{{Metric: HistoryFindings, Label: "发现数", Previous: 2, Current: 3, ChangePct: 50}},
+	}
+
+	require.NoError(t, GenerateHTMLReportWithOptions(newHistoryGroups(4096, 150), nil, nil, nil, outputPath, opts))
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "🕘 与上次运行对比 (2024-01-01T10:00:00Z)")
+	assert.Contains(t, string(content), `<span class="history-delta">发现数 &#43;50.0% (2 → 3)</span>`)
+}
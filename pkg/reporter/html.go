@@ -3,8 +3,10 @@ package reporter
 import (
 	"fmt"
 	"html/template"
+	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,25 +20,46 @@ type HTMLReportData struct {
 	Title           string
 	Version         string
 	Generated       string
+	History         *HistoryComparison // 与上一次运行的对比，nil 表示不展示
 	Groups          []HTMLGroupData
 	Findings        []rules.Finding
+	OmittedFindings int                            // 超出规模上限未渲染的发现数
 	ProblemContexts map[string]*HTMLProblemContext // 问题上下文映射 (RuleID -> HTMLProblemContext)
 }
 
 // HTMLGroupData HTML 报告中的分组数据
 type HTMLGroupData struct {
-	Type      string
-	Files     []HTMLFileData
-	TimeRange string
-	Duration  string
-	HasTrends bool
-	Trends    *analyzer.GroupTrends
-	ChartData []HTMLChartPoint       // 图表数据点
-	ChartType string                 // "heap" 或 "goroutine"
-	ChartUnit string                 // 单位显示
-	ChartMax  float64                // Y轴最大值
-	ChartMin  float64                // Y轴最小值
-	Insights  []analyzer.HeapInsight // 智能洞察
+	Type         string
+	Title        string // 分组标题，allocs profile 组为 "heap (allocs)"
+	Files        []HTMLFileData
+	TotalSamples string // 组内样本总数 (已格式化)
+	TimeRange    string
+	Duration     string
+	HasTrends    bool
+	Trends       *analyzer.GroupTrends
+	// 各指标趋势是否达到展示阈值
+	ShowHeapTrend      bool
+	ShowGoroutineTrend bool
+	HeapTrend          *analyzer.TrendMetrics // 报告展示的 heap 趋势（按配置的 sample type）
+	HeapTrendLabel     string                 // heap 趋势展示名
+	HeapSawtooth       bool                   // heap 趋势是正常的 GC 锯齿
+	HeapTrendUnit      string                 // heap 趋势斜率单位
+	HeapTrendStats     string                 // heap 趋势序列的峰值 / 均值 / 波动，数据点不足时为空
+	GoroutineStats     string                 // goroutine 数序列的峰值 / 均值 / 波动，数据点不足时为空
+	ChartData          []HTMLChartPoint       // 图表数据点
+	ChartType          string                 // "heap" 或 "goroutine"
+	ChartUnit          string                 // 单位显示
+	ChartMax           float64                // Y轴最大值
+	ChartMin           float64                // Y轴最小值
+	CategoryChart      *HTMLCategoryChart     // 按代码分类堆叠的样本值变化图
+	Insights           []analyzer.HeapInsight // 智能洞察
+	PackageMemory      *HTMLPackageMemory     // 最新 heap 快照按包汇总的内存占用
+}
+
+// HTMLPackageMemory HTML 报告中按包汇总的内存占用表
+type HTMLPackageMemory struct {
+	File     string // 快照文件名
+	Packages []analyzer.PackageMemory
 }
 
 // HTMLChartPoint 图表数据点
@@ -48,23 +71,109 @@ type HTMLChartPoint struct {
 	Time       string  // 时间标签
 }
 
+// trendOutliersTemplate 趋势的 GC 阶段偏斜快照、离群快照和排除后的拟合结果，参数为 *analyzer.TrendMetrics
+const trendOutliersTemplate = `{{define "trend-outliers"}}{{range .GCPhase}}
+                        <div class="trend-outlier">🧹 {{gcPhaseSkewNote .}}</div>{{end}}{{if .GCPhaseSensitive}}
+                        <div class="trend-outlier">⚠️ {{gcPhaseSensitiveNote}}</div>{{end}}{{range .Outliers}}
+                        <div class="trend-outlier">⚠️ {{outlierTime .}} 的快照是离群点: 值 {{printf "%.2f" .Value}}，预期 {{printf "%.2f" .Expected}}</div>{{end}}{{with .WithoutOutliers}}
+                        <div class="trend-outlier">↳ 排除离群快照后: 变化率 {{printf "%.2f" .Slope}}/采样 | 置信度: {{printf "%.0f" (mul .R2 100)}}%{{if .Points}} | 数据点: {{.Points}}{{end}}</div>{{end}}{{end}}`
+
+// ownershipTemplate 存活内存归属树的一层节点，参数为 HTMLOwnershipLevel，递归渲染子节点
+const ownershipTemplate = `{{define "ownership-level"}}
+                        <ul class="ownership-tree">{{range .Nodes}}
+                            <li>
+                                <span class="frame-category {{categoryClass .Category}}">{{.CategoryIcon}}</span>
+                                <span class="ownership-name">{{if not .Children.Nodes}}分配于 {{end}}{{.Name}}</span>
+                                <span class="ownership-value">{{.Value}} ({{printf "%.1f" .Pct}}%)</span>{{if .Location}}
+                                <span class="frame-location">{{.Location}}</span>{{end}}{{if .Children.Nodes}}{{template "ownership-level" .Children}}{{end}}
+                            </li>{{end}}{{if .Omitted}}
+                            <li class="truncated-note">… {{truncated .Omitted}}</li>{{end}}
+                        </ul>{{end}}`
+
+// HTMLOwnership HTML 报告中的存活内存归属树
+type HTMLOwnership struct {
+	SampleType string
+	Total      string // 格式化后的总字节数
+	Owners     HTMLOwnershipLevel
+}
+
+// HTMLOwnershipLevel 归属树的一层节点
+type HTMLOwnershipLevel struct {
+	Nodes   []HTMLOwnershipNode
+	Omitted int // 超出规模上限未渲染的节点数
+}
+
+// HTMLOwnershipNode 归属树节点
+type HTMLOwnershipNode struct {
+	Name         string
+	Category     string
+	CategoryIcon string
+	Location     string
+	Value        string // 格式化后的字节数
+	Pct          float64
+	Children     HTMLOwnershipLevel
+}
+
+// HTMLCategoryChart 按代码分类堆叠的样本值变化图
+type HTMLCategoryChart struct {
+	Unit      string               // 单位显示
+	Max       string               // Y 轴最大值标签
+	FirstTime string               // 首个快照时间
+	LastTime  string               // 最新快照时间
+	Series    []HTMLCategorySeries // 堆叠序列，从下到上
+}
+
+// HTMLCategorySeries 单个代码分类的堆叠面积
+type HTMLCategorySeries struct {
+	Category    string  // 分类名称
+	Color       string  // 填充颜色
+	Points      string  // SVG polygon 顶点
+	First       string  // 首个快照的值标签
+	Latest      string  // 最新快照的值标签
+	LatestShare float64 // 最新快照中的占比 (%)
+}
+
 // HTMLFileData HTML 报告中的文件数据
 type HTMLFileData struct {
-	Name        string
-	Time        string
-	Size        string
-	Metrics     *analyzer.ProfileMetrics
-	ProfileType string
+	Name    string
+	Time    string
+	Size    string
+	Samples string // 格式化后的样本数，没有指标时为空
+	// 样本数明显低于组内其他快照
+	Undersampled bool
+	Duplicate    string // -dedup 标记的重复快照的说明，其他快照为空
+	Bench        string // 基准测试模式下每次操作的消耗，其他模式为空
+	// CPU profile 缺少采集时长时的提示，其他情况为空
+	NoDurationNote string
+	Metrics        *analyzer.ProfileMetrics
+	ProfileType    string
+	// 按规模上限截断后的 Top 函数列表
+	TopFunctions             []analyzer.FunctionStat
+	OmittedTopFunctions      int
+	TopAllocFunctions        []analyzer.FunctionStat
+	OmittedTopAllocFunctions int
+	// 按数量降序的 goroutine 状态分布 (仅 goroutine profile)，没有状态信息时为空
+	GoroutineStates []HTMLGoroutineState
+}
+
+// HTMLGoroutineState HTML 报告中一种状态的 goroutine 数
+type HTMLGoroutineState struct {
+	State string
+	Count int
+	Pct   float64 // 占 goroutine 总数的百分比
 }
 
 // HTMLHotPath HTML 报告中的热点路径数据
 type HTMLHotPath struct {
 	Index          int
 	TotalPct       float64
+	Value          string // 绝对消耗值，如 "13.2s"，单位未知时为空
 	Summary        string
 	Frames         []HTMLStackFrame
 	HasBusiness    bool
 	RootCauseIndex int
+	OmittedFrames  int // 超出规模上限未渲染的栈帧数
+	Unsymbolized   int // 缺少符号的栈帧数
 }
 
 // HTMLStackFrame HTML 报告中的栈帧数据
@@ -72,7 +181,8 @@ type HTMLStackFrame struct {
 	Index        int
 	Category     string
 	CategoryIcon string
-	ShortName    string
+	ShortName    string // 展示名，匿名函数为带外层函数的 ClosureLabel
+	FunctionName string // 原始函数名，鼠标悬停时显示
 	Location     string
 	FileLink     template.URL // Use template.URL to allow file:// protocol
 	IsHighlight  bool
@@ -89,6 +199,24 @@ type HTMLExecutableCmd struct {
 }
 
 // HTMLSuggestion HTML 报告中的建议
+// HTMLWindowShift HTML 报告中前后窗口的热点迁移
+type HTMLWindowShift struct {
+	Header string
+	Paths  []HTMLPathShift
+}
+
+// HTMLPathShift 一条迁移的路径
+type HTMLPathShift struct {
+	Grew         bool
+	Name         string
+	Category     string
+	CategoryIcon string
+	Location     string
+	BeforePct    float64
+	AfterPct     float64
+	DeltaPct     float64
+}
+
 type HTMLSuggestion struct {
 	Category string
 	Content  string
@@ -101,7 +229,12 @@ type HTMLProblemContext struct {
 	Explanation          string
 	Impact               string
 	HotPaths             []HTMLHotPath
+	OmittedHotPaths      int              // 超出规模上限未渲染的热点路径数
+	HiddenHotPathsNote   string           // -hide-runtime-only 排除的热点路径说明，没有排除时为空
+	Ownership            *HTMLOwnership   // 存活内存归属树，仅 heap 问题
+	WindowShift          *HTMLWindowShift // 前后窗口的热点迁移，没有明显变化时为 nil
 	Commands             []HTMLExecutableCmd
+	PrimaryCommand       *HTMLExecutableCmd // 推荐最先执行的命令
 	ImmediateSuggestions []HTMLSuggestion
 	LongTermSuggestions  []HTMLSuggestion
 }
@@ -132,6 +265,9 @@ const htmlTemplate = `<!DOCTYPE html>
         .header h1 { color: #333; font-size: 2em; margin-bottom: 10px; }
         .header .version { color: #667eea; font-weight: 600; }
         .header .generated { color: #666; font-size: 0.9em; margin-top: 10px; }
+        .history { margin-top: 15px; font-size: 0.9em; color: #555; }
+        .history-title { font-weight: 600; margin-bottom: 6px; }
+        .history-delta { display: inline-block; background: #f8f9fa; border-radius: 6px; padding: 4px 10px; margin: 3px; }
         .group {
             background: white;
             border-radius: 16px;
@@ -188,6 +324,7 @@ const htmlTemplate = `<!DOCTYPE html>
             color: #666;
             margin-bottom: 15px;
         }
+        .file-meta .undersampled { color: #e67e22; font-weight: 600; }
         .metrics-grid {
             display: grid;
             grid-template-columns: repeat(auto-fit, minmax(200px, 1fr));
@@ -303,6 +440,19 @@ const htmlTemplate = `<!DOCTYPE html>
             margin-bottom: 10px;
             line-height: 1.5;
         }
+        .package-memory {
+            border-collapse: collapse;
+            font-size: 0.9em;
+        }
+        .package-memory th, .package-memory td {
+            padding: 6px 12px;
+            border-bottom: 1px solid #eee;
+            text-align: left;
+        }
+        .package-memory td.num {
+            text-align: right;
+            font-variant-numeric: tabular-nums;
+        }
         .insight-suggestions {
             background: rgba(255, 255, 255, 0.7);
             padding: 10px;
@@ -362,6 +512,20 @@ const htmlTemplate = `<!DOCTYPE html>
         .trend-details { flex: 1; }
         .trend-label { font-weight: 600; color: #333; }
         .trend-stats { font-size: 0.85em; color: #666; margin-top: 5px; }
+        .trend-outlier { font-size: 0.85em; color: #856404; margin-top: 5px; }
+        .ownership { margin-top: 20px; }
+        .ownership h5 { color: #28a745; margin-bottom: 10px; font-size: 1em; }
+        .ownership-tree { list-style: none; margin-left: 18px; font-size: 0.9em; }
+        .ownership > .ownership-tree { margin-left: 0; }
+        .ownership-tree li { margin: 4px 0; }
+        .ownership-name { font-family: 'Monaco', 'Menlo', monospace; }
+        .ownership-value { color: #666; margin-left: 6px; }
+        .window-shift { margin-top: 20px; }
+        .window-shift h5 { color: #6f42c1; margin-bottom: 10px; font-size: 1em; }
+        .window-shift-list { list-style: none; font-size: 0.9em; }
+        .window-shift-list li { margin: 4px 0; }
+        .shift-grew .shift-arrow { color: #dc3545; font-weight: bold; }
+        .shift-shrank .shift-arrow { color: #28a745; font-weight: bold; }
         .findings {
             background: white;
             border-radius: 16px;
@@ -393,27 +557,11 @@ const htmlTemplate = `<!DOCTYPE html>
         .suggestions ul { margin-left: 20px; font-size: 0.9em; color: #555; }
         .suggestions li { margin-bottom: 5px; }
 
-        /* Problem Locator 样式 - 代码分类颜色 */
-        .frame-runtime { 
-            background: linear-gradient(135deg, #6c757d 0%, #5a6268 100%);
-            color: white;
-        }
-        .frame-stdlib { 
-            background: linear-gradient(135deg, #17a2b8 0%, #138496 100%);
+        /* Problem Locator 样式 - 代码分类颜色 */{{range categoryStyles}}
+        .{{.Class}} {
+            background: linear-gradient(135deg, {{.Color}} 0%, {{.Shade}} 100%);
             color: white;
-        }
-        .frame-third-party { 
-            background: linear-gradient(135deg, #6f42c1 0%, #5a32a3 100%);
-            color: white;
-        }
-        .frame-business { 
-            background: linear-gradient(135deg, #28a745 0%, #1e7e34 100%);
-            color: white;
-        }
-        .frame-unknown { 
-            background: linear-gradient(135deg, #adb5bd 0%, #868e96 100%);
-            color: white;
-        }
+        }{{end}}
 
         /* 问题上下文样式 */
         .problem-context {
@@ -474,6 +622,12 @@ const htmlTemplate = `<!DOCTYPE html>
             color: #666;
             border-bottom: 1px solid #e9ecef;
         }
+        .truncated-note {
+            padding: 6px 15px;
+            font-size: 0.85em;
+            color: #999;
+            font-style: italic;
+        }
         .call-chain {
             padding: 15px;
             font-family: 'Monaco', 'Menlo', 'Consolas', monospace;
@@ -593,6 +747,15 @@ const htmlTemplate = `<!DOCTYPE html>
             border-top: 1px solid #3c3c3c;
         }
 
+        .primary-command {
+            background: #1e1e1e;
+            border: 2px solid #667eea;
+            border-radius: 8px;
+            margin-bottom: 20px;
+            overflow: hidden;
+        }
+        .primary-command-label { color: #fff; font-weight: bold; }
+
         /* 建议样式 */
         .suggestions-section {
             margin-top: 20px;
@@ -721,6 +884,16 @@ const htmlTemplate = `<!DOCTYPE html>
         .chart-legend-color.increasing { background: #dc3545; }
         .chart-legend-color.decreasing { background: #28a745; }
         .chart-legend-color.stable { background: #6c757d; }
+        .category-area {
+            opacity: 0.85;
+            stroke: white;
+            stroke-width: 0.5;
+        }
+        .category-legend-color {
+            width: 12px;
+            height: 12px;
+            border-radius: 2px;
+        }
     </style>
 </head>
 <body>
@@ -729,6 +902,12 @@ const htmlTemplate = `<!DOCTYPE html>
             <h1>🔍 {{.Title}}</h1>
             <div class="version">{{.Version}}</div>
             <div class="generated">生成时间: {{.Generated}}</div>
+            {{if .History}}
+            <div class="history">
+                <div class="history-title">🕘 {{.History.Header}}</div>
+                {{range .History.Deltas}}<span class="history-delta">{{.}}</span>{{end}}
+            </div>
+            {{end}}
         </div>
 
         {{if .Findings}}
@@ -736,19 +915,29 @@ const htmlTemplate = `<!DOCTYPE html>
             <div class="findings-header">
                 <span class="group-icon">🚨</span>
                 <span class="group-title">问题发现</span>
-                <span class="group-count">{{len .Findings}} 个发现</span>
+                <span class="group-count">{{len .Findings}} 个发现{{if .OmittedFindings}} {{truncated .OmittedFindings}}{{end}}</span>
             </div>
 
             {{range .Findings}}
             <div class="finding-item finding-{{.Severity}}">
                 <div class="finding-title">{{.Title}}</div>
                 <div class="finding-meta">
-                    规则: {{.RuleName}} ({{.RuleID}}) | 严重程度: {{.Severity}}
+                    规则: {{.RuleName}} ({{.RuleID}}) | 严重程度: {{.Severity}}{{if .File}} | 文件: {{.File}}{{end}}
                 </div>
 
                 {{$ctx := index $.ProblemContexts .RuleID}}
                 {{if $ctx}}
                 <div class="problem-context">
+                    {{if $ctx.PrimaryCommand}}
+                    <div class="primary-command">
+                        <div class="command-header">
+                            <span class="primary-command-label">👉 从这里开始: {{$ctx.PrimaryCommand.Description}}</span>
+                            <button class="copy-btn" onclick="copyCommand(this, '{{escapeJS $ctx.PrimaryCommand.Command}}')">复制</button>
+                        </div>
+                        <div class="command-code">$ {{$ctx.PrimaryCommand.Command}}</div>
+                    </div>
+                    {{end}}
+
                     {{if $ctx.Explanation}}
                     <div class="problem-explanation">
                         <h5>📝 问题解释</h5>
@@ -772,20 +961,23 @@ const htmlTemplate = `<!DOCTYPE html>
                                 <div class="hot-path-item">
                                     <div class="hot-path-header">
                                         <span class="hot-path-title">热点 #{{$hp.Index}}</span>
-                                        <span class="hot-path-pct">{{printf "%.1f" $hp.TotalPct}}%</span>
+                                        <span class="hot-path-pct">{{printf "%.1f" $hp.TotalPct}}%{{if $hp.Value}} ({{$hp.Value}}){{end}}</span>
                                     </div>
                                 </div>
                             </summary>
                             <div class="hot-path-summary">调用链: {{$hp.Summary}}</div>
+                            {{if $hp.Unsymbolized}}
+                            <div class="truncated-note">⚠️ {{$hp.Unsymbolized}} 个栈帧缺少符号，分析结果不完整</div>
+                            {{end}}
                             <div class="call-chain">
                                 {{range $hp.Frames}}
                                 {{if .IsNewSection}}
                                 <div class="section-divider">─────────────────────────────</div>
                                 {{end}}
                                 <div class="call-chain-frame {{if .IsHighlight}}highlight{{end}}">
-                                    <span class="frame-category frame-{{.Category}}">{{.CategoryIcon}} {{.Category}}</span>
+                                    <span class="frame-category {{categoryClass .Category}}">{{.CategoryIcon}} {{categoryLabel .Category}}</span>
                                     <div class="frame-info">
-                                        <div class="frame-name">{{.ShortName}}</div>
+                                        <div class="frame-name" title="{{.FunctionName}}">{{.ShortName}}</div>
                                         <div class="frame-location">
                                             {{if .FileLink}}
                                             <a href="{{.FileLink}}">{{.Location}}</a>
@@ -799,6 +991,9 @@ const htmlTemplate = `<!DOCTYPE html>
                                     {{end}}
                                 </div>
                                 {{end}}
+                                {{if $hp.OmittedFrames}}
+                                <div class="truncated-note">… {{truncated $hp.OmittedFrames}}</div>
+                                {{end}}
                                 {{if not $hp.HasBusiness}}
                                 <div class="no-business-warning">
                                     <strong>⚠️ 该路径中没有业务代码</strong>
@@ -819,6 +1014,34 @@ const htmlTemplate = `<!DOCTYPE html>
                             </div>
                         </details>
                         {{end}}
+                        {{if $ctx.OmittedHotPaths}}
+                        <div class="truncated-note">… {{truncated $ctx.OmittedHotPaths}}</div>
+                        {{end}}
+                    </div>
+                    {{end}}
+                    {{if $ctx.HiddenHotPathsNote}}
+                    <div class="truncated-note">ℹ️ {{$ctx.HiddenHotPathsNote}}</div>
+                    {{end}}
+
+                    {{with $ctx.Ownership}}
+                    <div class="ownership">
+                        <h5>🌳 存活内存归属 ({{.SampleType}}, 共 {{.Total}})</h5>
+                        {{template "ownership-level" .Owners}}
+                    </div>
+                    {{end}}
+
+                    {{with $ctx.WindowShift}}
+                    <div class="window-shift">
+                        <h5>🔀 {{.Header}}</h5>
+                        <ul class="window-shift-list">{{range .Paths}}
+                            <li class="{{if .Grew}}shift-grew{{else}}shift-shrank{{end}}">
+                                <span class="shift-arrow">{{if .Grew}}↑{{else}}↓{{end}}</span>
+                                <span class="frame-category {{categoryClass .Category}}">{{.CategoryIcon}}</span>
+                                <span class="ownership-name">{{.Name}}</span>
+                                <span class="ownership-value">{{printf "%.1f" .BeforePct}}% → {{printf "%.1f" .AfterPct}}% ({{printf "%+.1f" .DeltaPct}} 个百分点)</span>{{if .Location}}
+                                <span class="frame-location">{{.Location}}</span>{{end}}
+                            </li>{{end}}
+                        </ul>
                     </div>
                     {{end}}
 
@@ -867,15 +1090,18 @@ const htmlTemplate = `<!DOCTYPE html>
                 {{end}}
             </div>
             {{end}}
+            {{if .OmittedFindings}}
+            <div class="truncated-note">… {{truncated .OmittedFindings}}</div>
+            {{end}}
         </div>
         {{end}}
 
         {{range .Groups}}
         <div class="group">
             <div class="group-header">
-                <span class="group-icon">{{if eq .Type "cpu"}}⚡{{else if eq .Type "heap"}}💾{{else if eq .Type "goroutine"}}🔄{{else}}📁{{end}}</span>
-                <span class="group-title">{{.Type}} 分析</span>
-                <span class="group-count">{{len .Files}} 个文件</span>
+                <span class="group-icon">{{if eq .Type "cpu"}}⚡{{else if eq .Type "heap"}}💾{{else if eq .Type "goroutine"}}🔄{{else if eq .Type "block"}}⏳{{else if eq .Type "threadcreate"}}🧵{{else}}📁{{end}}</span>
+                <span class="group-title">{{.Title}} 分析</span>
+                <span class="group-count">{{len .Files}} 个文件 · {{.TotalSamples}} 个样本</span>
             </div>
 
             {{range $index, $file := .Files}}
@@ -887,6 +1113,9 @@ const htmlTemplate = `<!DOCTYPE html>
                 <div class="file-meta">
                     <span>🕐 {{$file.Time}}</span>
                     <span>📦 {{$file.Size}}</span>
+                    {{if $file.Samples}}<span{{if $file.Undersampled}} class="undersampled" title="样本数低于组内中位数的一半"{{end}}>🔢 {{$file.Samples}} 样本{{if $file.Undersampled}} ⚠️ 样本偏少{{end}}</span>{{end}}
+                    {{if $file.Bench}}<span>🏁 {{$file.Bench}}</span>{{end}}
+                    {{if $file.Duplicate}}<span>📝 {{$file.Duplicate}}</span>{{end}}
                 </div>
 
                 {{if $file.Metrics}}
@@ -904,10 +1133,12 @@ const htmlTemplate = `<!DOCTYPE html>
                         <div class="metric-value">{{$file.Metrics.Duration}}</div>
                     </div>
                     {{end}}
-                    <div class="metric-card">
-                        <div class="metric-label">样本数</div>
-                        <div class="metric-value">{{$file.Metrics.TotalSamples}}</div>
+                    {{if $file.NoDurationNote}}
+                    <div class="metric-card" title="{{$file.NoDurationNote}}">
+                        <div class="metric-label">采样时长</div>
+                        <div class="metric-value">⚠️ 未知</div>
                     </div>
+                    {{end}}
                     {{else if eq $file.ProfileType "heap"}}
                     <div class="metric-card">
                         <div class="metric-label">已分配内存</div>
@@ -936,45 +1167,89 @@ const htmlTemplate = `<!DOCTYPE html>
                         <div class="metric-label">Goroutine 数量</div>
                         <div class="metric-value highlight">{{$file.Metrics.GoroutineCount}}</div>
                     </div>
+                    {{else if eq $file.ProfileType "threadcreate"}}
+                    <div class="metric-card">
+                        <div class="metric-label">线程数</div>
+                        <div class="metric-value highlight">{{$file.Metrics.ThreadCount}}</div>
+                    </div>
+                    {{else if eq $file.ProfileType "block"}}
+                    <div class="metric-card">
+                        <div class="metric-label">阻塞时间</div>
+                        <div class="metric-value highlight">{{$file.Metrics.BlockDelay}}</div>
+                    </div>
+                    <div class="metric-card">
+                        <div class="metric-label">阻塞次数</div>
+                        <div class="metric-value">{{$file.Metrics.Contentions}}</div>
+                    </div>
                     {{end}}
                 </div>
 
-                {{if $file.Metrics.TopFunctions}}
+                {{if $file.GoroutineStates}}
                 <div class="top-functions">
-                    <h4>Top {{if eq $file.ProfileType "heap"}}当前内存占用 (inuse_space){{else if eq $file.ProfileType "goroutine"}}调用路径{{else}}热点函数{{end}}</h4>
-                    {{range $i, $fn := $file.Metrics.TopFunctions}}
-                    {{if lt $i 5}}
-                    {{if or (ne $file.ProfileType "heap") (gt $fn.Flat 0)}}
+                    <h4>Goroutine 状态分布</h4>
+                    {{range $i, $state := $file.GoroutineStates}}
                     <div class="func-item">
                         <span class="func-rank {{if eq $i 0}}top1{{else if eq $i 1}}top2{{else if eq $i 2}}top3{{end}}">{{add $i 1}}</span>
-                        <span class="func-name" title="{{$fn.Name}}">{{$fn.Name}}</span>
+                        <span class="func-name">{{$state.State}}</span>
+                        <span class="func-pct">{{printf "%.1f" $state.Pct}}% ({{$state.Count}})</span>
+                    </div>
+                    {{end}}
+                </div>
+                {{end}}
+
+                {{if $file.TopFunctions}}
+                <div class="top-functions">
+                    <h4>Top {{if eq $file.ProfileType "heap"}}当前内存占用 (inuse_space){{else if eq $file.ProfileType "goroutine"}}调用路径{{else if eq $file.ProfileType "threadcreate"}}线程创建调用点{{else if eq $file.ProfileType "block"}}阻塞调用路径{{else if eq $file.ProfileType "cpu"}}热点函数 (flat% / cum%){{else}}热点函数{{end}}</h4>
+                    {{range $i, $fn := $file.TopFunctions}}
+                    <div class="func-item">
+                        <span class="func-rank {{if eq $i 0}}top1{{else if eq $i 1}}top2{{else if eq $i 2}}top3{{end}}">{{add $i 1}}</span>
+                        <span class="func-name" title="{{$fn.Name}}">{{displayName $fn.Name}}</span>
                         {{if eq $file.ProfileType "heap"}}
                         <span class="func-pct">{{printf "%.1f" $fn.FlatPct}}% ({{formatBytes $fn.Flat}})</span>
-                        {{else if eq $file.ProfileType "goroutine"}}
-                        <span class="func-pct">{{printf "%.1f" $fn.CumPct}}%</span>
+                        {{else if or (eq $file.ProfileType "goroutine") (eq $file.ProfileType "threadcreate")}}
+                        <span class="func-pct">{{printf "%.1f" $fn.CumPct}}% ({{formatValue $fn.Cum "count"}})</span>
+                        {{else if eq $file.ProfileType "block"}}
+                        <span class="func-pct">{{printf "%.1f" $fn.CumPct}}% ({{formatValue $fn.Cum "nanoseconds"}})</span>
+                        {{else if and (eq $file.ProfileType "cpu") (not $file.NoDurationNote)}}
+                        <span class="func-pct">{{printf "%.1f" $fn.FlatPct}}% / {{printf "%.1f" $fn.CumPct}}% ({{formatValue $fn.Flat "nanoseconds"}})</span>
+                        {{else if eq $file.ProfileType "cpu"}}
+                        <span class="func-pct">{{printf "%.1f" $fn.FlatPct}}% / {{printf "%.1f" $fn.CumPct}}%</span>
                         {{else}}
                         <span class="func-pct">{{printf "%.1f" $fn.FlatPct}}%</span>
                         {{end}}
                     </div>
                     {{end}}
-                    {{end}}
+                    {{if $file.OmittedTopFunctions}}
+                    <div class="truncated-note">… {{truncated $file.OmittedTopFunctions}}</div>
                     {{end}}
                 </div>
                 {{end}}
                 
-                {{if and (eq $file.ProfileType "heap") $file.Metrics.TopAllocFunctions}}
+                {{if and (eq $file.ProfileType "heap") $file.TopAllocFunctions}}
                 <div class="top-functions">
                     <h4>Top 累计内存分配 (alloc_space)</h4>
-                    {{range $i, $fn := $file.Metrics.TopAllocFunctions}}
-                    {{if lt $i 5}}
-                    {{if gt $fn.Flat 0}}
+                    {{range $i, $fn := $file.TopAllocFunctions}}
                     <div class="func-item">
                         <span class="func-rank {{if eq $i 0}}top1{{else if eq $i 1}}top2{{else if eq $i 2}}top3{{end}}">{{add $i 1}}</span>
-                        <span class="func-name" title="{{$fn.Name}}">{{$fn.Name}}</span>
+                        <span class="func-name" title="{{$fn.Name}}">{{displayName $fn.Name}}</span>
                         <span class="func-pct">{{printf "%.1f" $fn.FlatPct}}% ({{formatBytes $fn.Flat}})</span>
                     </div>
                     {{end}}
+                    {{if $file.OmittedTopAllocFunctions}}
+                    <div class="truncated-note">… {{truncated $file.OmittedTopAllocFunctions}}</div>
                     {{end}}
+                </div>
+                {{end}}
+
+                {{if and (eq $file.ProfileType "cpu") $file.Metrics.TopPackages}}
+                <div class="top-functions">
+                    <h4>Top 包 (flat%)</h4>
+                    {{range $i, $pkg := $file.Metrics.TopPackages}}
+                    <div class="func-item">
+                        <span class="func-rank {{if eq $i 0}}top1{{else if eq $i 1}}top2{{else if eq $i 2}}top3{{end}}">{{add $i 1}}</span>
+                        <span class="func-name" title="{{$pkg.Package}}">{{$pkg.Package}}</span>
+                        <span class="func-pct">{{printf "%.1f" $pkg.FlatPct}}%{{if not $file.NoDurationNote}} ({{formatValue $pkg.Flat "nanoseconds"}}){{end}}</span>
+                    </div>
                     {{end}}
                 </div>
                 {{end}}
@@ -989,7 +1264,7 @@ const htmlTemplate = `<!DOCTYPE html>
                 <div class="insight-card {{.Level}}">
                     <div class="insight-header">
                         <span class="insight-icon">
-                            {{if eq .Level "critical"}}🔴{{else if eq .Level "warning"}}🟡{{else}}🔵{{end}}
+                            {{insightIcon .Level}}
                         </span>
                         <span class="insight-title">{{.Title}}</span>
                     </div>
@@ -999,6 +1274,23 @@ const htmlTemplate = `<!DOCTYPE html>
             </div>
             {{end}}
 
+            {{with .PackageMemory}}
+            <div class="insights-section">
+                <h3>📦 包内存占用 Top {{len .Packages}} ({{.File}})</h3>
+                <table class="package-memory">
+                    <thead><tr><th>包</th><th>分类</th><th>累计分配</th><th>使用中</th></tr></thead>
+                    <tbody>{{range .Packages}}
+                        <tr>
+                            <td>{{.Package}}</td>
+                            <td>{{if .Category}}<span class="frame-category {{categoryClass .Category}}">{{categoryLabel .Category}}</span>{{else}}-{{end}}</td>
+                            <td class="num">{{formatBytes .AllocSpace}} ({{printf "%.1f" .AllocPct}}%)</td>
+                            <td class="num">{{formatBytes .InuseSpace}} ({{printf "%.1f" .InusePct}}%)</td>
+                        </tr>{{end}}
+                    </tbody>
+                </table>
+            </div>
+            {{end}}
+
             {{if .TimeRange}}
             <div class="stats">
                 <div class="stat-item">
@@ -1017,28 +1309,28 @@ const htmlTemplate = `<!DOCTYPE html>
             {{if .HasTrends}}
             <div class="trends">
                 <h4>📈 趋势分析</h4>
-                {{if and .Trends .Trends.HeapInuse}}
-                {{if gt .Trends.HeapInuse.R2 0.7}}
+                {{if .ShowHeapTrend}}
                 <div class="trend-item">
-                    <span class="trend-icon">{{if eq .Trends.HeapInuse.Direction "increasing"}}📈{{else if eq .Trends.HeapInuse.Direction "decreasing"}}📉{{else}}➡️{{end}}</span>
+                    <span class="trend-icon">{{if eq .HeapTrend.Direction "increasing"}}📈{{else if eq .HeapTrend.Direction "decreasing"}}📉{{else}}➡️{{end}}</span>
                     <div class="trend-details">
-                        <div class="trend-label">堆内存趋势: {{if eq .Trends.HeapInuse.Direction "increasing"}}持续增长 ⚠️{{else if eq .Trends.HeapInuse.Direction "decreasing"}}下降中{{else}}稳定{{end}}</div>
-                        <div class="trend-stats">变化率: {{printf "%.2f" .Trends.HeapInuse.Slope}} bytes/采样 | 置信度: {{printf "%.0f" (mul .Trends.HeapInuse.R2 100)}}%</div>
+                        <div class="trend-label">{{.HeapTrendLabel}}趋势: {{if .HeapSawtooth}}正常 GC 波动{{else if eq .HeapTrend.Direction "increasing"}}持续增长 ⚠️{{else if eq .HeapTrend.Direction "decreasing"}}下降中{{else}}稳定{{end}}</div>
+                        <div class="trend-stats">变化率: {{printf "%.2f" .HeapTrend.Slope}} {{.HeapTrendUnit}}/采样 | 置信度: {{printf "%.0f" (mul .HeapTrend.R2 100)}}%{{if .HeapTrend.Points}} | 数据点: {{.HeapTrend.Points}}{{end}}</div>
+                        {{if .HeapTrendStats}}<div class="trend-stats">峰值 / 均值 / 波动: {{.HeapTrendStats}}</div>{{end}}
+                        {{template "trend-outliers" .HeapTrend}}
                     </div>
                 </div>
                 {{end}}
-                {{end}}
-                {{if and .Trends .Trends.GoroutineCount}}
-                {{if gt .Trends.GoroutineCount.R2 0.7}}
+                {{if .ShowGoroutineTrend}}
                 <div class="trend-item">
                     <span class="trend-icon">{{if eq .Trends.GoroutineCount.Direction "increasing"}}📈{{else if eq .Trends.GoroutineCount.Direction "decreasing"}}📉{{else}}➡️{{end}}</span>
                     <div class="trend-details">
-                        <div class="trend-label">Goroutine 趋势: {{if eq .Trends.GoroutineCount.Direction "increasing"}}持续增长 ⚠️{{else if eq .Trends.GoroutineCount.Direction "decreasing"}}下降中{{else}}稳定{{end}}</div>
-                        <div class="trend-stats">变化率: {{printf "%.2f" .Trends.GoroutineCount.Slope}}/采样 | 置信度: {{printf "%.0f" (mul .Trends.GoroutineCount.R2 100)}}%</div>
+                        <div class="trend-label">Goroutine 趋势: {{if eq .Trends.GoroutineGrowthKind "plateau"}}阶跃后趋于平稳{{else if eq .Trends.GoroutineCount.Direction "increasing"}}持续增长 ⚠️{{else if eq .Trends.GoroutineCount.Direction "decreasing"}}下降中{{else}}稳定{{end}}</div>
+                        <div class="trend-stats">变化率: {{printf "%.2f" .Trends.GoroutineCount.Slope}}/采样 | 置信度: {{printf "%.0f" (mul .Trends.GoroutineCount.R2 100)}}%{{if .Trends.GoroutineCount.Points}} | 数据点: {{.Trends.GoroutineCount.Points}}{{end}}</div>
+                        {{if .GoroutineStats}}<div class="trend-stats">峰值 / 均值 / 波动: {{.GoroutineStats}}</div>{{end}}
+                        {{template "trend-outliers" .Trends.GoroutineCount}}
                     </div>
                 </div>
                 {{end}}
-                {{end}}
 
                 {{if .ChartData}}
                 <div class="trend-chart">
@@ -1132,7 +1424,7 @@ const htmlTemplate = `<!DOCTYPE html>
                     </div>
                     <div class="chart-legend">
                         <div class="chart-legend-item">
-                            <span class="chart-legend-color {{if and .Trends .Trends.HeapInuse}}{{.Trends.HeapInuse.Direction}}{{else if and .Trends .Trends.GoroutineCount}}{{.Trends.GoroutineCount.Direction}}{{end}}"></span>
+                            <span class="chart-legend-color {{if .HeapTrend}}{{.HeapTrend.Direction}}{{else if and .Trends .Trends.GoroutineCount}}{{.Trends.GoroutineCount.Direction}}{{end}}"></span>
                             <span>{{.ChartUnit}}使用量</span>
                         </div>
                         <div class="chart-legend-item">
@@ -1146,6 +1438,34 @@ const htmlTemplate = `<!DOCTYPE html>
                 {{end}}
             </div>
             {{end}}
+
+            {{with .CategoryChart}}
+            <div class="trend-chart">
+                <h5>🧩 {{.Unit}}分类构成变化图</h5>
+                <div class="chart-container">
+                    <svg class="chart-svg" viewBox="0 0 400 120" preserveAspectRatio="xMidYMid meet">
+                        <line class="chart-grid-line" x1="40" y1="10" x2="390" y2="10"/>
+                        <line class="chart-grid-line" x1="40" y1="60" x2="390" y2="60"/>
+                        <line class="chart-grid-line" x1="40" y1="110" x2="390" y2="110"/>
+                        <text class="chart-axis-label" x="35" y="14" text-anchor="end">{{.Max}}</text>
+                        <text class="chart-axis-label" x="35" y="114" text-anchor="end">0</text>
+                        {{range .Series}}
+                        <polygon class="category-area" points="{{.Points}}" fill="{{.Color}}"><title>{{.Category}}: {{.First}} → {{.Latest}}</title></polygon>
+                        {{end}}
+                        <text class="chart-axis-label" x="40" y="120" text-anchor="start">{{.FirstTime}}</text>
+                        <text class="chart-axis-label" x="390" y="120" text-anchor="end">{{.LastTime}}</text>
+                    </svg>
+                </div>
+                <div class="chart-legend">
+                    {{range .Series}}
+                    <div class="chart-legend-item">
+                        <span class="category-legend-color" style="background: {{.Color}}"></span>
+                        <span>{{.Category}}: {{.First}} → {{.Latest}} ({{printf "%.0f" .LatestShare}}%)</span>
+                    </div>
+                    {{end}}
+                </div>
+            </div>
+            {{end}}
         </div>
         {{end}}
     </div>
@@ -1189,36 +1509,86 @@ func GenerateHTMLReport(groups []analyzer.ProfileGroup, trends map[string]*analy
 
 // GenerateHTMLReportWithContext 生成带问题上下文的 HTML 格式分析报告
 func GenerateHTMLReportWithContext(groups []analyzer.ProfileGroup, trends map[string]*analyzer.GroupTrends, findings []rules.Finding, contexts map[string]*locator.ProblemContext, outputPath string) error {
+	return GenerateHTMLReportWithOptions(groups, trends, findings, contexts, outputPath, DefaultOptions())
+}
+
+// GenerateHTMLReportWithOptions 使用指定渲染选项生成 HTML 格式分析报告
+func GenerateHTMLReportWithOptions(groups []analyzer.ProfileGroup, trends map[string]*analyzer.GroupTrends, findings []rules.Finding, contexts map[string]*locator.ProblemContext, outputPath string, opts Options) error {
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create output file '%s': %w", outputPath, err)
+	}
+	report := &Report{Groups: groups, Trends: trends, Findings: findings, Contexts: contexts, Options: opts}
+	if err := WriteHTMLReport(file, report); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write HTML report '%s': %w", outputPath, err)
+	}
+	return nil
+}
+
+// WriteHTMLReport 将 HTML 格式的分析报告写入 w
+func WriteHTMLReport(w io.Writer, report *Report) error {
+	groups, trends, findings, contexts, opts := report.Groups, report.Trends, report.Findings, report.Contexts, report.Options
 	data := HTMLReportData{
 		Title:           "PerfInspector 分析报告",
-		Version:         "v0.1",
-		Generated:       time.Now().UTC().Format(time.RFC3339),
-		Findings:        findings,
+		Version:         opts.version(),
+		Generated:       opts.generatedAt().Format(time.RFC3339),
+		History:         opts.History,
 		ProblemContexts: make(map[string]*HTMLProblemContext),
 	}
+	data.Findings, data.OmittedFindings = opts.Limits.Findings(findings)
 
 	// 转换 ProblemContexts 为 HTML 友好格式
 	for ruleID, ctx := range contexts {
-		data.ProblemContexts[ruleID] = convertProblemContextToHTML(ctx)
+		data.ProblemContexts[ruleID] = convertProblemContextWithLimits(ctx, opts.Limits, opts.SourceLinks)
 	}
 
-	for _, group := range groups {
+	for _, group := range orderGroups(groups, findings, opts.Sort) {
 		if len(group.Files) == 0 {
 			continue
 		}
 
 		htmlGroup := HTMLGroupData{
-			Type: group.Type,
+			Type:         group.Type,
+			Title:        groupTitle(group),
+			TotalSamples: analyzer.FormatInt(group.TotalSamples()),
 		}
 
-		for _, file := range group.Files {
-			htmlGroup.Files = append(htmlGroup.Files, HTMLFileData{
+		undersampled := analyzer.UndersampledFiles(group.Files, analyzer.DefaultUndersampledRatio)
+		for _, file := range orderFiles(group.Files, opts.Sort) {
+			htmlFile := HTMLFileData{
 				Name:        filepath.Base(file.Path),
 				Time:        file.Time.UTC().Format(time.RFC3339),
 				Size:        formatSize(file.Size),
 				Metrics:     file.Metrics,
 				ProfileType: group.Type,
-			})
+			}
+			if file.DuplicateOf != "" {
+				htmlFile.Duplicate = duplicateNote(file)
+			}
+			if file.Metrics != nil {
+				htmlFile.Samples = analyzer.FormatInt(file.Metrics.TotalSamples)
+				htmlFile.Undersampled = undersampled[file.Path]
+				if file.Metrics.NoDuration {
+					htmlFile.NoDurationNote = noDurationNote
+				}
+				if file.Metrics.Bench != nil {
+					htmlFile.Bench = benchSummary(file.Metrics.Bench, group.Type)
+				}
+				htmlFile.TopFunctions, htmlFile.OmittedTopFunctions = opts.Limits.Functions(file.Metrics.TopFunctions, group.Type)
+				htmlFile.TopAllocFunctions, htmlFile.OmittedTopAllocFunctions = opts.Limits.Functions(file.Metrics.TopAllocFunctions, group.Type)
+				for _, state := range analyzer.SortedGoroutineStates(file.Metrics.GoroutineStates) {
+					htmlFile.GoroutineStates = append(htmlFile.GoroutineStates, HTMLGoroutineState{
+						State: state.State,
+						Count: state.Count,
+						Pct:   goroutineStatePct(state.Count, file.Metrics.GoroutineCount),
+					})
+				}
+			}
+			htmlGroup.Files = append(htmlGroup.Files, htmlFile)
 		}
 
 		if len(group.Files) > 1 {
@@ -1233,25 +1603,66 @@ func GenerateHTMLReportWithContext(groups []analyzer.ProfileGroup, trends map[st
 
 		if groupTrends, ok := trends[group.Type]; ok && groupTrends != nil {
 			htmlGroup.Trends = groupTrends
-			if (groupTrends.HeapInuse != nil && groupTrends.HeapInuse.R2 > 0.7) ||
-				(groupTrends.GoroutineCount != nil && groupTrends.GoroutineCount.R2 > 0.7) {
+			htmlGroup.HeapTrend = groupTrends.SelectedHeapTrend()
+			htmlGroup.HeapTrendLabel = heapTrendLabel(groupTrends)
+			htmlGroup.HeapSawtooth = isHeapSawtooth(groupTrends)
+			htmlGroup.HeapTrendUnit = "bytes"
+			if analyzer.IsHeapObjectSampleType(groupTrends.HeapSampleType) {
+				htmlGroup.HeapTrendUnit = "对象"
+			}
+			htmlGroup.ShowHeapTrend = showTrend(opts.TrendThresholds, analyzer.MetricHeapInuse, htmlGroup.HeapTrend)
+			htmlGroup.ShowGoroutineTrend = showTrend(opts.TrendThresholds, analyzer.MetricGoroutineCount, groupTrends.GoroutineCount)
+			htmlGroup.HeapTrendStats = trendStats(htmlGroup.HeapTrend, heapTrendValue(groupTrends.HeapSampleType))
+			htmlGroup.GoroutineStats = trendStats(groupTrends.GoroutineCount, countTrendValue)
+			if htmlGroup.ShowHeapTrend || htmlGroup.ShowGoroutineTrend {
 				htmlGroup.HasTrends = true
 
 				// 生成图表数据点
-				htmlGroup.ChartData, htmlGroup.ChartType, htmlGroup.ChartUnit, htmlGroup.ChartMax, htmlGroup.ChartMin = generateChartData(group)
+				htmlGroup.ChartData, htmlGroup.ChartType, htmlGroup.ChartUnit, htmlGroup.ChartMax, htmlGroup.ChartMin = generateChartData(group, groupTrends.HeapSampleType)
 			}
 		}
 
+		heapSampleType := analyzer.HeapSampleInuseSpace
+		if groupTrends, ok := trends[group.Type]; ok && groupTrends != nil && groupTrends.HeapSampleType != "" {
+			heapSampleType = groupTrends.HeapSampleType
+		}
+		htmlGroup.CategoryChart = generateCategoryChartData(group, heapSampleType)
+
 		// 对于 heap profile，生成智能洞察
 		if group.Type == "heap" && len(group.Files) > 0 && group.Files[0].Metrics != nil {
 			htmlGroup.Insights = analyzer.AnalyzeHeapInsights(group.Files[0].Metrics)
 		}
+		if file, packages := latestPackageMemory(group); len(packages) > 0 {
+			htmlGroup.PackageMemory = &HTMLPackageMemory{File: filepath.Base(file.Path), Packages: packages}
+		}
 
 		data.Groups = append(data.Groups, htmlGroup)
 	}
 
-	funcMap := template.FuncMap{
-		"add": func(a, b int) int { return a + b },
+	tmpl, err := parseHTMLTemplate(opts)
+	if err != nil {
+		return err
+	}
+
+	execute := func(w io.Writer) error { return tmpl.Execute(w, data) }
+	if opts.NoEmoji {
+		err = writePlain(w, execute)
+	} else {
+		err = execute(w)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return nil
+}
+
+// htmlFuncMap 返回 HTML 报告模板可用的辅助函数，内置模板和自定义模板共用
+func htmlFuncMap(opts Options) template.FuncMap {
+	return template.FuncMap{
+		"add":         func(a, b int) int { return a + b },
+		"outlierTime": outlierTime,
+		"insightIcon": getInsightLevelIcon,
 		"sub": func(a, b interface{}) interface{} {
 			switch va := a.(type) {
 			case int:
@@ -1300,41 +1711,63 @@ func GenerateHTMLReportWithContext(groups []analyzer.ProfileGroup, trends map[st
 			}
 			return fa / fb
 		},
-		"formatBytes": analyzer.FormatBytes,
-		"escapeJS":    escapeJSString,
-	}
-
-	tmpl, err := template.New("report").Funcs(funcMap).Parse(htmlTemplate)
-	if err != nil {
-		return fmt.Errorf("failed to parse template: %w", err)
-	}
-
-	file, err := os.Create(outputPath)
-	if err != nil {
-		return fmt.Errorf("failed to create output file '%s': %w", outputPath, err)
-	}
-	defer file.Close()
-
-	if err := tmpl.Execute(file, data); err != nil {
-		return fmt.Errorf("failed to execute template: %w", err)
+		"formatBytes":          analyzer.FormatBytes,
+		"formatValue":          locator.FormatValue,
+		"escapeJS":             escapeJSString,
+		"displayName":          opts.displayName,
+		"truncated":            truncatedNote,
+		"gcPhaseSkewNote":      gcPhaseSkewNote,
+		"gcPhaseSensitiveNote": func() string { return gcPhaseSensitiveNote },
+		"categoryClass": func(category string) string {
+			return GetCategoryClass(locator.CodeCategory(category))
+		},
+		"categoryLabel": func(category string) string {
+			return locator.CodeCategory(category).String()
+		},
+		"categoryStyles": categoryStyles,
 	}
-
-	return nil
 }
 
-// convertProblemContextToHTML 转换 ProblemContext 为 HTML 模板友好格式
+// convertProblemContextToHTML 转换 ProblemContext 为 HTML 模板友好格式（不限制规模）
 func convertProblemContextToHTML(ctx *locator.ProblemContext) *HTMLProblemContext {
+	return convertProblemContextWithLimits(ctx, Limits{}, SourceLinks{})
+}
+
+// convertProblemContextWithLimits 转换 ProblemContext，热点路径和调用链按规模上限截断，源码位置按 links 生成链接
+func convertProblemContextWithLimits(ctx *locator.ProblemContext, limits Limits, links SourceLinks) *HTMLProblemContext {
 	if ctx == nil {
 		return nil
 	}
 
+	hotPaths, omittedHotPaths := limits.HotPaths(ctx.HotPaths)
 	htmlCtx := &HTMLProblemContext{
-		Title:       ctx.Title,
-		Severity:    ctx.Severity,
-		Explanation: ctx.Explanation,
-		Impact:      ctx.Impact,
-		HotPaths:    ConvertHotPathsForHTML(ctx.HotPaths),
-		Commands:    ConvertCommandsForHTML(ctx.Commands),
+		Title:           ctx.Title,
+		Severity:        ctx.Severity,
+		Explanation:     ctx.Explanation,
+		Impact:          ctx.Impact,
+		HotPaths:        convertHotPathsWithLimits(hotPaths, limits, links),
+		OmittedHotPaths: omittedHotPaths,
+		Commands:        ConvertCommandsForHTML(ctx.Commands),
+	}
+	if ctx.HiddenHotPaths.Count > 0 {
+		htmlCtx.HiddenHotPathsNote = hiddenHotPathsNote(ctx.HiddenHotPaths)
+	}
+	if ctx.Ownership != nil && len(ctx.Ownership.Owners) > 0 {
+		htmlCtx.Ownership = &HTMLOwnership{
+			SampleType: ctx.Ownership.SampleType,
+			Total:      analyzer.FormatBytes(ctx.Ownership.Total),
+			Owners:     convertOwnershipLevel(ctx.Ownership.Owners, limits),
+		}
+	}
+	if !ctx.WindowShift.Empty() {
+		htmlCtx.WindowShift = convertWindowShift(ctx.WindowShift)
+	}
+	if ctx.PrimaryCommand != nil {
+		htmlCtx.PrimaryCommand = &HTMLExecutableCmd{
+			Command:     ctx.PrimaryCommand.Command,
+			Description: ctx.PrimaryCommand.Description,
+			OutputHint:  ctx.PrimaryCommand.OutputHint,
+		}
 	}
 
 	// 分离立即和长期建议
@@ -1343,6 +1776,53 @@ func convertProblemContextToHTML(ctx *locator.ProblemContext) *HTMLProblemContex
 	return htmlCtx
 }
 
+// convertWindowShift 转换前后窗口的热点迁移，上升的路径在前
+func convertWindowShift(shift *locator.WindowComparison) *HTMLWindowShift {
+	result := &HTMLWindowShift{Header: windowShiftHeader(shift)}
+	for _, group := range [][]locator.PathShift{shift.Grew, shift.Shrank} {
+		for _, s := range group {
+			frame := s.Frame()
+			location := frame.Location()
+			if location == "unknown" {
+				location = ""
+			}
+			result.Paths = append(result.Paths, HTMLPathShift{
+				Grew:         s.DeltaPct > 0,
+				Name:         frame.DisplayName(),
+				Category:     string(frame.Category),
+				CategoryIcon: frame.Category.Icon(),
+				Location:     location,
+				BeforePct:    s.BeforePct,
+				AfterPct:     s.AfterPct,
+				DeltaPct:     s.DeltaPct,
+			})
+		}
+	}
+	return result
+}
+
+// convertOwnershipLevel 转换归属树的一层节点，每层按规模上限截断
+func convertOwnershipLevel(nodes []locator.OwnershipNode, limits Limits) HTMLOwnershipLevel {
+	nodes, omitted := limits.OwnershipNodes(nodes)
+	level := HTMLOwnershipLevel{Omitted: omitted}
+	for _, node := range nodes {
+		location := node.Location
+		if location == "unknown" {
+			location = ""
+		}
+		level.Nodes = append(level.Nodes, HTMLOwnershipNode{
+			Name:         node.Name,
+			Category:     string(node.Category),
+			CategoryIcon: node.Category.Icon(),
+			Location:     location,
+			Value:        analyzer.FormatBytes(node.Value),
+			Pct:          node.Pct,
+			Children:     convertOwnershipLevel(node.Children, limits),
+		})
+	}
+	return level
+}
+
 // escapeJSString 转义 JavaScript 字符串
 func escapeJSString(s string) string {
 	s = strings.ReplaceAll(s, "\\", "\\\\")
@@ -1355,15 +1835,24 @@ func escapeJSString(s string) string {
 
 // ConvertHotPathsForHTML 将 HotPath 列表转换为 HTML 友好格式
 func ConvertHotPathsForHTML(hotPaths []locator.HotPath) []HTMLHotPath {
+	return convertHotPathsWithLimits(hotPaths, Limits{}, SourceLinks{})
+}
+
+// convertHotPathsWithLimits 将 HotPath 列表转换为 HTML 友好格式，调用链按规模上限截断
+func convertHotPathsWithLimits(hotPaths []locator.HotPath, limits Limits, links SourceLinks) []HTMLHotPath {
 	result := make([]HTMLHotPath, 0, len(hotPaths))
 	for i, hp := range hotPaths {
 		htmlHP := HTMLHotPath{
 			Index:          i + 1,
 			TotalPct:       hp.Chain.TotalPct,
+			Value:          hp.FormatValue(),
 			Summary:        hp.Chain.Summary(),
 			HasBusiness:    hp.Chain.HasBusinessCode(),
 			RootCauseIndex: hp.RootCauseIndex,
+			Unsymbolized:   hp.Chain.UnsymbolizedFrames(),
 		}
+		frames, omittedFrames := limits.Frames(hp.Chain.Frames)
+		htmlHP.OmittedFrames = omittedFrames
 
 		// 创建业务帧索引集合
 		businessFrameSet := make(map[int]bool)
@@ -1373,14 +1862,15 @@ func ConvertHotPathsForHTML(hotPaths []locator.HotPath) []HTMLHotPath {
 
 		// 转换栈帧
 		var lastCategory locator.CodeCategory
-		for j, frame := range hp.Chain.Frames {
+		for j, frame := range frames {
 			htmlFrame := HTMLStackFrame{
 				Index:        j,
 				Category:     string(frame.Category),
 				CategoryIcon: frame.Category.Icon(),
-				ShortName:    frame.ShortName,
+				ShortName:    frame.Label(),
+				FunctionName: frame.FunctionName,
 				Location:     frame.Location(),
-				FileLink:     template.URL(generateFileLink(frame.FilePath, frame.LineNumber)),
+				FileLink:     template.URL(generateFileLink(frame.FilePath, frame.LineNumber, links)),
 				IsHighlight:  businessFrameSet[j],
 				IsNewSection: j > 0 && frame.Category != lastCategory,
 			}
@@ -1433,11 +1923,14 @@ func ConvertSuggestionsForHTML(suggestions []locator.Suggestion) (immediate, lon
 	return
 }
 
-// generateFileLink 生成 file:// 协议链接
-func generateFileLink(filePath string, lineNumber int64) string {
+// generateFileLink 生成源码链接：配置了 links.Template 时按模板替换 {file} 和 {line}，否则为绝对路径生成 file:// 链接
+func generateFileLink(filePath string, lineNumber int64, links SourceLinks) string {
 	if filePath == "" || filePath == "unknown" {
 		return ""
 	}
+	if links.Template != "" {
+		return links.link(filePath, lineNumber)
+	}
 	// 对于本地文件，生成 file:// 链接
 	// 注意：这在大多数浏览器中可能不会直接打开，但提供了路径信息
 	if strings.HasPrefix(filePath, "/") {
@@ -1449,24 +1942,65 @@ func generateFileLink(filePath string, lineNumber int64) string {
 	return ""
 }
 
-// GetCategoryClass 返回类别对应的 CSS 类名
+// GetCategoryClass 返回类别对应的 CSS 类名，不认识的类别按 unknown 处理
 func GetCategoryClass(category locator.CodeCategory) string {
-	switch category {
-	case locator.CategoryRuntime:
-		return "frame-runtime"
-	case locator.CategoryStdlib:
-		return "frame-stdlib"
-	case locator.CategoryThirdParty:
-		return "frame-third-party"
-	case locator.CategoryBusiness:
-		return "frame-business"
-	default:
-		return "frame-unknown"
+	for _, known := range locator.Categories() {
+		if category == known {
+			return "frame-" + strings.ReplaceAll(string(category), "_", "-")
+		}
+	}
+	return "frame-unknown"
+}
+
+// HTMLCategoryStyle 代码分类的 CSS 样式
+type HTMLCategoryStyle struct {
+	Class string       // CSS 类名
+	Color template.CSS // 渐变起始颜色
+	Shade template.CSS // 渐变结束颜色 (加深后的 Color)
+}
+
+// categoryStyles 按当前分类样式生成栈帧分类的 CSS
+// 颜色已在 locator.LoadCategoryTheme 中校验为十六进制格式，可以直接写入样式表
+func categoryStyles() []HTMLCategoryStyle {
+	styles := make([]HTMLCategoryStyle, 0, len(locator.Categories()))
+	for _, category := range locator.Categories() {
+		color := category.Color()
+		styles = append(styles, HTMLCategoryStyle{
+			Class: GetCategoryClass(category),
+			Color: template.CSS(color),
+			Shade: template.CSS(shadeColor(color, 0.83)),
+		})
+	}
+	return styles
+}
+
+// shadeColor 按比例加深 #rgb 或 #rrggbb 颜色，无法解析时原样返回
+func shadeColor(color string, factor float64) string {
+	hex := strings.TrimPrefix(color, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 6 || err != nil {
+		return color
 	}
+	shade := func(shift uint) uint64 {
+		return uint64(float64((value>>shift)&0xff) * factor)
+	}
+	return fmt.Sprintf("#%02x%02x%02x", shade(16), shade(8), shade(0))
+}
+
+// heapChartLabel 格式化 heap 图表数据点标签
+func heapChartLabel(value int64, heapSampleType string) string {
+	if analyzer.IsHeapObjectSampleType(heapSampleType) {
+		return analyzer.FormatInt(value)
+	}
+	return analyzer.FormatBytes(value)
 }
 
 // generateChartData 从 ProfileGroup 生成图表数据点
-func generateChartData(group analyzer.ProfileGroup) ([]HTMLChartPoint, string, string, float64, float64) {
+// heap 图表使用 heapSampleType 指定的数据序列，与展示的趋势保持一致
+func generateChartData(group analyzer.ProfileGroup, heapSampleType string) ([]HTMLChartPoint, string, string, float64, float64) {
 	if len(group.Files) < 2 {
 		return nil, "", "", 0, 0
 	}
@@ -1479,10 +2013,14 @@ func generateChartData(group analyzer.ProfileGroup) ([]HTMLChartPoint, string, s
 	case "heap":
 		chartType = "heap"
 		chartUnit = "内存"
+		if analyzer.IsHeapObjectSampleType(heapSampleType) {
+			chartUnit = "对象"
+		}
 		// 提取堆内存数据
 		for i, file := range group.Files {
 			if file.Metrics != nil {
-				val := float64(file.Metrics.InuseSpace)
+				raw := analyzer.HeapSampleValue(file.Metrics, heapSampleType)
+				val := float64(raw)
 				if i == 0 || val < minVal {
 					minVal = val
 				}
@@ -1492,7 +2030,7 @@ func generateChartData(group analyzer.ProfileGroup) ([]HTMLChartPoint, string, s
 				points = append(points, HTMLChartPoint{
 					Index: i,
 					Value: val,
-					Label: analyzer.FormatBytes(file.Metrics.InuseSpace),
+					Label: heapChartLabel(raw, heapSampleType),
 					Time:  file.Time.UTC().Format("15:04:05"),
 				})
 			}
@@ -1532,3 +2070,118 @@ func generateChartData(group analyzer.ProfileGroup) ([]HTMLChartPoint, string, s
 
 	return points, chartType, chartUnit, maxVal, minVal
 }
+
+// generateCategoryChartData 从 ProfileMetrics.CategoryTotals 生成分类堆叠面积图
+// 至少需要两个已计算分类汇总的文件，否则返回 nil
+func generateCategoryChartData(group analyzer.ProfileGroup, heapSampleType string) *HTMLCategoryChart {
+	var files []analyzer.ProfileFile
+	for _, file := range group.Files {
+		if file.Metrics != nil && file.Metrics.CategoryTotals != nil {
+			files = append(files, file)
+		}
+	}
+	if len(files) < 2 {
+		return nil
+	}
+
+	// 每个快照的总值，用于确定 Y 轴范围和占比
+	totals := make([]int64, len(files))
+	var maxTotal int64
+	for i, file := range files {
+		for _, value := range file.Metrics.CategoryTotals {
+			totals[i] += value
+		}
+		if totals[i] > maxTotal {
+			maxTotal = totals[i]
+		}
+	}
+	if maxTotal <= 0 {
+		return nil
+	}
+
+	chart := &HTMLCategoryChart{
+		Unit:      categoryChartUnit(group.Type, heapSampleType),
+		Max:       formatCategoryValue(maxTotal, group.Type, heapSampleType),
+		FirstTime: files[0].Time.UTC().Format("15:04:05"),
+		LastTime:  files[len(files)-1].Time.UTC().Format("15:04:05"),
+	}
+
+	x := func(i int) float64 { return 40 + 350*float64(i)/float64(len(files)-1) }
+	y := func(v int64) float64 { return 110 - 100*float64(v)/float64(maxTotal) }
+
+	lower := make([]int64, len(files))
+	// 按 locator.Categories 的顺序从下到上堆叠，业务代码在最底部便于对比
+	for _, category := range locator.Categories() {
+		upper := make([]int64, len(files))
+		var present bool
+		for i, file := range files {
+			value := file.Metrics.CategoryTotals[string(category)]
+			if value != 0 {
+				present = true
+			}
+			upper[i] = lower[i] + value
+		}
+		if !present {
+			continue
+		}
+
+		// 上边界从左到右，下边界从右到左，围成闭合面积
+		points := make([]string, 0, 2*len(files))
+		for i := range files {
+			points = append(points, fmt.Sprintf("%.1f,%.1f", x(i), y(upper[i])))
+		}
+		for i := len(files) - 1; i >= 0; i-- {
+			points = append(points, fmt.Sprintf("%.1f,%.1f", x(i), y(lower[i])))
+		}
+
+		last := len(files) - 1
+		latest := upper[last] - lower[last]
+		series := HTMLCategorySeries{
+			Category: category.String(),
+			Color:    category.Color(),
+			Points:   strings.Join(points, " "),
+			First:    formatCategoryValue(upper[0]-lower[0], group.Type, heapSampleType),
+			Latest:   formatCategoryValue(latest, group.Type, heapSampleType),
+		}
+		if totals[last] > 0 {
+			series.LatestShare = float64(latest) / float64(totals[last]) * 100
+		}
+		chart.Series = append(chart.Series, series)
+		lower = upper
+	}
+
+	return chart
+}
+
+// categoryChartUnit 分类图表的单位显示
+func categoryChartUnit(profileType, heapSampleType string) string {
+	switch profileType {
+	case "cpu":
+		return "CPU 时间"
+	case "heap":
+		if analyzer.IsHeapObjectSampleType(heapSampleType) {
+			return "对象"
+		}
+		return "内存"
+	case "goroutine":
+		return "Goroutine"
+	case "threadcreate":
+		return "线程"
+	case "block":
+		return "阻塞时间"
+	default:
+		return "样本"
+	}
+}
+
+// formatCategoryValue 按 profile 类型格式化分类汇总值
+func formatCategoryValue(value int64, profileType, heapSampleType string) string {
+	switch profileType {
+	case "cpu", "block":
+		return time.Duration(value).Round(time.Millisecond).String()
+	case "heap":
+		return heapChartLabel(value, heapSampleType)
+	default:
+		return analyzer.FormatInt(value)
+	}
+}
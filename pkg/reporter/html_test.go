@@ -73,6 +73,155 @@ func TestGenerateHTMLReport_Basic(t *testing.T) {
 	assert.Contains(t, html, "2.00 KB")
 }
 
+// TestGenerateHTMLReport_Bench 测试基准测试模式下展示每次操作的消耗
+func TestGenerateHTMLReport_Bench(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "report.html")
+
+	groups := []analyzer.ProfileGroup{
+		{
+			Type: "cpu",
+			Files: []analyzer.ProfileFile{
+				{
+					Path:    "/path/to/cpu.pprof",
+					Time:    time.Date(2023, 11, 15, 14, 30, 0, 0, time.UTC),
+					Metrics: &analyzer.ProfileMetrics{TotalSamples: 10, Bench: &analyzer.BenchStats{N: 1000, NsPerOp: 250}},
+				},
+				{
+					Path:    "/path/to/cpu2.pprof",
+					Time:    time.Date(2023, 11, 15, 14, 35, 0, 0, time.UTC),
+					Metrics: &analyzer.ProfileMetrics{TotalSamples: 10},
+				},
+			},
+		},
+	}
+
+	require.NoError(t, GenerateHTMLReport(groups, nil, nil, outputPath))
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	html := string(content)
+
+	assert.Contains(t, html, "🏁 250 ns/op (N=1,000，已过滤 0 个 testing 框架样本)")
+	assert.Equal(t, 1, strings.Count(html, "🏁"))
+}
+
+func TestGenerateHTMLReport_NoDuration(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "report.html")
+
+	groups := []analyzer.ProfileGroup{
+		{
+			Type: "cpu",
+			Files: []analyzer.ProfileFile{
+				{Path: "/path/to/cpu.pprof", Metrics: &analyzer.ProfileMetrics{TotalSamples: 10, NoDuration: true}},
+				{Path: "/path/to/cpu2.pprof", Metrics: &analyzer.ProfileMetrics{TotalSamples: 10, CPUTime: time.Second, Duration: 10 * time.Second}},
+			},
+		},
+	}
+
+	require.NoError(t, GenerateHTMLReport(groups, nil, nil, outputPath))
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	html := string(content)
+
+	assert.Equal(t, 1, strings.Count(html, "⚠️ 未知"))
+	assert.Contains(t, html, `title="缺少采样时长 (duration 为 0)，不展示绝对 CPU 时间，百分比仍然有效"`)
+	assert.Equal(t, 1, strings.Count(html, `<div class="metric-label">CPU 时间</div>`))
+}
+
+// TestGenerateHTMLReport_CPUFlatAndCum CPU Top 函数同时展示 flat% 和 cum%
+func TestGenerateHTMLReport_CPUFlatAndCum(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "report.html")
+	groups := []analyzer.ProfileGroup{
+		{
+			Type:  "cpu",
+			Files: []analyzer.ProfileFile{{Path: "/path/to/cpu.pprof", Metrics: analyzer.ExtractMetrics(newCPUWrapperProfile(), "cpu")}},
+		},
+	}
+
+	require.NoError(t, GenerateHTMLReport(groups, nil, nil, outputPath))
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	html := string(content)
+
+	assert.Contains(t, html, "Top 热点函数 (flat% / cum%)")
+	assert.Contains(t, html, `<span class="func-pct">10.0% / 100.0% (100.0ms)</span>`)
+	assert.Contains(t, html, `<span class="func-pct">90.0% / 90.0% (900.0ms)</span>`)
+}
+
+// TestGenerateHTMLReport_GoroutineStates 测试 goroutine 快照的状态分布
+func TestGenerateHTMLReport_GoroutineStates(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "report.html")
+	groups := []analyzer.ProfileGroup{
+		{
+			Type: "goroutine",
+			Files: []analyzer.ProfileFile{
+				{Path: "/path/to/goroutine1.pprof", Metrics: &analyzer.ProfileMetrics{GoroutineCount: 3}},
+				{Path: "/path/to/goroutine2.pprof", Metrics: &analyzer.ProfileMetrics{
+					GoroutineCount:  100,
+					GoroutineStates: map[string]int{"chan receive": 80, "running": 20},
+				}},
+			},
+		},
+	}
+
+	require.NoError(t, GenerateHTMLReport(groups, nil, nil, outputPath))
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	html := string(content)
+
+	// 没有状态信息的快照不展示
+	assert.Equal(t, 1, strings.Count(html, "Goroutine 状态分布"))
+	assert.Contains(t, html, `<span class="func-name">chan receive</span>`)
+	assert.Contains(t, html, `<span class="func-pct">80.0% (80)</span>`)
+	assert.Less(t, strings.Index(html, "chan receive"), strings.Index(html, `<span class="func-name">running</span>`))
+}
+
+// TestGenerateHTMLReport_PackageMemory 测试 heap 分组的包内存占用表
+func TestGenerateHTMLReport_PackageMemory(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "report.html")
+
+	groups := []analyzer.ProfileGroup{
+		{
+			Type: "heap",
+			Files: []analyzer.ProfileFile{
+				{Path: "/path/to/heap1.pprof", Metrics: &analyzer.ProfileMetrics{}},
+				{Path: "/path/to/heap2.pprof", Metrics: &analyzer.ProfileMetrics{PackageMemory: []analyzer.PackageMemory{
+					{Package: "github.com/myapp/cache", Category: "business", AllocSpace: 3 << 20, InuseSpace: 1 << 20, AllocPct: 75, InusePct: 50},
+					{Package: "encoding/json", AllocSpace: 1 << 20, InuseSpace: 1 << 20, AllocPct: 25, InusePct: 50},
+				}}},
+			},
+		},
+	}
+
+	require.NoError(t, GenerateHTMLReport(groups, nil, nil, outputPath))
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	html := string(content)
+
+	assert.Contains(t, html, "📦 包内存占用 Top 2 (heap2.pprof)")
+	assert.Contains(t, html, "<td>github.com/myapp/cache</td>")
+	assert.Contains(t, html, `<td class="num">3.00 MB (75.0%)</td>`)
+	assert.Contains(t, html, locator.CategoryBusiness.String())
+}
+
+// TestGenerateHTMLReport_AllocChurnInsight 测试分配周转过高时在关键发现中提示
+func TestGenerateHTMLReport_AllocChurnInsight(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "report.html")
+
+	metrics := &analyzer.ProfileMetrics{AllocSpace: 2000 << 20, InuseSpace: 50 << 20}
+	metrics.AllocRate = analyzer.AllocChurnRatio(metrics.AllocSpace, metrics.InuseSpace)
+	groups := []analyzer.ProfileGroup{
+		{Type: "heap", Files: []analyzer.ProfileFile{{Path: "/path/to/heap1.pprof", Metrics: metrics}}},
+	}
+
+	require.NoError(t, GenerateHTMLReport(groups, nil, nil, outputPath))
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	html := string(content)
+
+	assert.Contains(t, html, "高频短生命周期分配")
+	assert.Contains(t, html, "累计分配是使用中内存的 40.0 倍")
+}
+
 // TestGenerateHTMLReport_WithTimeRange 测试包含时间范围的报告
 // **Property 1: HTML Report Content Completeness**
 // **Validates: Requirements 1.3**
@@ -458,7 +607,9 @@ func TestHTMLReport_Property_CategoryMarkersConsistency(t *testing.T) {
 		{locator.CategoryStdlib, "frame-stdlib"},
 		{locator.CategoryThirdParty, "frame-third-party"},
 		{locator.CategoryBusiness, "frame-business"},
+		{locator.CategoryGenerated, "frame-generated"},
 		{locator.CategoryUnknown, "frame-unknown"},
+		{locator.CodeCategory("vendored"), "frame-unknown"},
 	}
 
 	for _, tc := range testCases {
@@ -513,7 +664,7 @@ func TestHTMLReport_Property_FileLinkGeneration(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			link := generateFileLink(tc.filePath, tc.lineNumber)
+			link := generateFileLink(tc.filePath, tc.lineNumber, SourceLinks{})
 			assert.Equal(t, tc.expectedLink, link)
 		})
 	}
@@ -709,6 +860,93 @@ func TestHTMLReport_WithProblemContext_NoBusinessFrames(t *testing.T) {
 	assert.Contains(t, html, "没有业务代码", "Should show warning when no business frames")
 }
 
+// TestHTMLReport_HiddenHotPaths 测试 -hide-runtime-only 隐藏路径的汇总说明
+func TestHTMLReport_HiddenHotPaths(t *testing.T) {
+	tempDir := t.TempDir()
+	outputPath := filepath.Join(tempDir, "report.html")
+
+	groups := []analyzer.ProfileGroup{
+		{
+			Type:  "cpu",
+			Files: []analyzer.ProfileFile{{Path: "/test.pprof", Time: time.Now(), Size: 100}},
+		},
+	}
+	findings := []rules.Finding{{RuleID: "gc_rule", RuleName: "GC Rule", Severity: "medium", Title: "GC Issue"}}
+	contexts := map[string]*locator.ProblemContext{
+		"gc_rule": {
+			Title:          "GC Problem",
+			Severity:       "medium",
+			HiddenHotPaths: locator.HiddenHotPaths{Count: 3, Value: 750, Pct: 75},
+		},
+	}
+
+	err := GenerateHTMLReportWithContext(groups, nil, findings, contexts, outputPath)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "已隐藏 3 条没有业务代码的热点路径，合计占 75.0%")
+
+	htmlCtx := convertProblemContextToHTML(&locator.ProblemContext{Title: "t"})
+	assert.Empty(t, htmlCtx.HiddenHotPathsNote)
+}
+
+// TestConvertHotPathsForHTML_Value 测试热点路径的绝对消耗值
+func TestConvertHotPathsForHTML_Value(t *testing.T) {
+	hotPaths := []locator.HotPath{
+		{
+			Chain:          locator.CallChain{TotalValue: int64(13200 * time.Millisecond), TotalPct: 45},
+			RootCauseIndex: -1,
+			Unit:           locator.ValueUnit{Unit: "nanoseconds", Scale: 1},
+		},
+		{Chain: locator.CallChain{TotalValue: 100, TotalPct: 10}, RootCauseIndex: -1},
+	}
+
+	htmlHotPaths := ConvertHotPathsForHTML(hotPaths)
+	assert.Equal(t, "13.2s", htmlHotPaths[0].Value)
+	assert.Empty(t, htmlHotPaths[1].Value)
+}
+
+// TestConvertHotPathsForHTML_Unsymbolized 测试缺少符号的栈帧计数
+func TestConvertHotPathsForHTML_Unsymbolized(t *testing.T) {
+	hotPaths := []locator.HotPath{{
+		Chain: locator.CallChain{Frames: []locator.StackFrame{
+			{ShortName: "main", Category: locator.CategoryBusiness},
+			{ShortName: "<unknown 0x4a3f20>", Category: locator.CategoryMissingSymbol},
+		}},
+		RootCauseIndex: -1,
+	}}
+
+	htmlHotPaths := ConvertHotPathsForHTML(hotPaths)
+	require.Len(t, htmlHotPaths, 1)
+	assert.Equal(t, 1, htmlHotPaths[0].Unsymbolized)
+	assert.Equal(t, "missing_symbol", htmlHotPaths[0].Frames[1].Category)
+}
+
+// TestConvertHotPathsForHTML_ClosureLabel 匿名函数帧展示外层函数，原始函数名用于悬停提示
+func TestConvertHotPathsForHTML_ClosureLabel(t *testing.T) {
+	hotPaths := []locator.HotPath{{
+		Chain: locator.CallChain{Frames: []locator.StackFrame{
+			{FunctionName: "main.main", ShortName: "main", Category: locator.CategoryBusiness},
+			{FunctionName: "main.init.0.func1.1", ShortName: "init.0.func1.1", ClosureLabel: "init#0()内的闭包#1.1", Category: locator.CategoryBusiness},
+		}},
+		RootCauseIndex: -1,
+	}}
+
+	frames := ConvertHotPathsForHTML(hotPaths)[0].Frames
+	assert.Equal(t, "main", frames[0].ShortName)
+	assert.Equal(t, "init#0()内的闭包#1.1", frames[1].ShortName)
+	assert.Equal(t, "main.init.0.func1.1", frames[1].FunctionName)
+
+	var buf strings.Builder
+	require.NoError(t, WriteHTMLReport(&buf, &Report{
+		Findings: []rules.Finding{{RuleID: "r1", Title: "t", Severity: "high"}},
+		Contexts: map[string]*locator.ProblemContext{"r1": {Title: "t", HotPaths: hotPaths}},
+		Options:  DefaultOptions(),
+	}))
+	assert.Contains(t, buf.String(), `<div class="frame-name" title="main.init.0.func1.1">init#0()内的闭包#1.1</div>`)
+}
+
 // TestConvertSuggestionsForHTML tests the suggestion conversion
 func TestConvertSuggestionsForHTML(t *testing.T) {
 	suggestions := []locator.Suggestion{
@@ -865,3 +1103,237 @@ func TestHTMLReport_WithBusinessCode_NoWarning(t *testing.T) {
 	assert.Contains(t, html, "frame-business", "Should show business frame")
 	assert.Contains(t, html, "handler", "Should show business function name")
 }
+
+// TestGenerateHTMLReport_CustomTrendThreshold 测试自定义趋势展示阈值
+func TestGenerateHTMLReport_CustomTrendThreshold(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "report.html")
+
+	groups := []analyzer.ProfileGroup{
+		{
+			Type: "heap",
+			Files: []analyzer.ProfileFile{
+				{Path: "/test1.pprof", Time: time.Now(), Size: 100},
+				{Path: "/test2.pprof", Time: time.Now().Add(time.Hour), Size: 200},
+			},
+		},
+	}
+	trends := map[string]*analyzer.GroupTrends{
+		"heap": {
+			HeapInuse: &analyzer.TrendMetrics{Slope: 15.5, R2: 0.5, Direction: "increasing"},
+		},
+	}
+
+	opts := DefaultOptions()
+	opts.TrendThresholds.Metrics = map[string]float64{analyzer.MetricHeapInuse: 0.4}
+	err := GenerateHTMLReportWithOptions(groups, trends, nil, nil, outputPath, opts)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "堆内存趋势")
+}
+
+// TestGenerateHTMLReport_HeapSampleType 测试按配置的 heap sample type 展示趋势和图表
+func TestGenerateHTMLReport_HeapSampleType(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "report.html")
+
+	base := time.Now()
+	groups := []analyzer.ProfileGroup{
+		{
+			Type: "heap",
+			Files: []analyzer.ProfileFile{
+				{Path: "/test1.pprof", Time: base, Metrics: &analyzer.ProfileMetrics{InuseObjects: 1000, InuseSpace: 100}},
+				{Path: "/test2.pprof", Time: base.Add(time.Minute), Metrics: &analyzer.ProfileMetrics{InuseObjects: 2000, InuseSpace: 100}},
+				{Path: "/test3.pprof", Time: base.Add(2 * time.Minute), Metrics: &analyzer.ProfileMetrics{InuseObjects: 3000, InuseSpace: 100}},
+			},
+		},
+	}
+	trends := map[string]*analyzer.GroupTrends{
+		"heap": analyzer.CalculateTrendsWithConfig(groups[0], analyzer.TrendConfig{HeapSampleType: analyzer.HeapSampleInuseObjects}),
+	}
+
+	err := GenerateHTMLReportWithOptions(groups, trends, nil, nil, outputPath, DefaultOptions())
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	html := string(content)
+	assert.Contains(t, html, "堆内存 (inuse_objects)趋势: 持续增长")
+	assert.Contains(t, html, "1000.00 对象/采样")
+	assert.Contains(t, html, "首次: 1,000")
+}
+
+func TestGenerateChartData_HeapSampleType(t *testing.T) {
+	group := analyzer.ProfileGroup{
+		Type: "heap",
+		Files: []analyzer.ProfileFile{
+			{Metrics: &analyzer.ProfileMetrics{AllocSpace: 1024, InuseSpace: 10}},
+			{Metrics: &analyzer.ProfileMetrics{AllocSpace: 2048, InuseSpace: 10}},
+		},
+	}
+
+	points, chartType, chartUnit, maxVal, minVal := generateChartData(group, analyzer.HeapSampleAllocSpace)
+	require.Len(t, points, 2)
+	assert.Equal(t, "heap", chartType)
+	assert.Equal(t, "内存", chartUnit)
+	assert.Equal(t, 2048.0, maxVal)
+	assert.Equal(t, 1024.0, minVal)
+	assert.Equal(t, "1.00 KB", points[0].Label)
+
+	points, _, _, _, _ = generateChartData(group, "")
+	assert.Equal(t, 10.0, points[1].Value, "empty sample type uses inuse_space")
+}
+
+func TestGenerateCategoryChartData(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	group := analyzer.ProfileGroup{
+		Type: "heap",
+		Files: []analyzer.ProfileFile{
+			{Time: base, Metrics: &analyzer.ProfileMetrics{CategoryTotals: map[string]int64{"business": 1024, "third_party": 1024}}},
+			{Time: base.Add(time.Minute), Metrics: &analyzer.ProfileMetrics{}},
+			{Time: base.Add(2 * time.Minute), Metrics: &analyzer.ProfileMetrics{CategoryTotals: map[string]int64{"business": 1024, "third_party": 3072}}},
+		},
+	}
+
+	chart := generateCategoryChartData(group, analyzer.HeapSampleInuseSpace)
+	require.NotNil(t, chart)
+	assert.Equal(t, "内存", chart.Unit)
+	assert.Equal(t, "4.00 KB", chart.Max)
+	assert.Equal(t, "10:00:00", chart.FirstTime)
+	assert.Equal(t, "10:02:00", chart.LastTime)
+
+	// 未计算分类汇总的文件被跳过，没有样本的分类不生成序列，业务代码堆叠在最底部
+	require.Len(t, chart.Series, 2)
+	business, thirdParty := chart.Series[0], chart.Series[1]
+	assert.Equal(t, "业务", business.Category)
+	assert.Equal(t, "#28a745", business.Color)
+	assert.Equal(t, "40.0,85.0 390.0,85.0 390.0,110.0 40.0,110.0", business.Points)
+	assert.InDelta(t, 25.0, business.LatestShare, 0.001)
+
+	assert.Equal(t, "第三方", thirdParty.Category)
+	assert.Equal(t, "40.0,60.0 390.0,10.0 390.0,85.0 40.0,85.0", thirdParty.Points)
+	assert.Equal(t, "1.00 KB", thirdParty.First)
+	assert.Equal(t, "3.00 KB", thirdParty.Latest)
+	assert.InDelta(t, 75.0, thirdParty.LatestShare, 0.001)
+}
+
+func TestGenerateCategoryChartData_NotEnoughData(t *testing.T) {
+	single := analyzer.ProfileGroup{Type: "cpu", Files: []analyzer.ProfileFile{
+		{Metrics: &analyzer.ProfileMetrics{CategoryTotals: map[string]int64{"business": 1}}},
+		{Metrics: &analyzer.ProfileMetrics{}},
+	}}
+	assert.Nil(t, generateCategoryChartData(single, ""))
+
+	empty := analyzer.ProfileGroup{Type: "cpu", Files: []analyzer.ProfileFile{
+		{Metrics: &analyzer.ProfileMetrics{CategoryTotals: map[string]int64{}}},
+		{Metrics: &analyzer.ProfileMetrics{CategoryTotals: map[string]int64{}}},
+	}}
+	assert.Nil(t, generateCategoryChartData(empty, ""))
+}
+
+func TestFormatCategoryValue(t *testing.T) {
+	assert.Equal(t, "1.5s", formatCategoryValue(1500*int64(time.Millisecond), "cpu", ""))
+	assert.Equal(t, "2.00 KB", formatCategoryValue(2048, "heap", analyzer.HeapSampleInuseSpace))
+	assert.Equal(t, "1,200", formatCategoryValue(1200, "heap", analyzer.HeapSampleInuseObjects))
+	assert.Equal(t, "1,200", formatCategoryValue(1200, "goroutine", ""))
+}
+
+// TestShadeColor 测试分类渐变色的加深计算
+func TestShadeColor(t *testing.T) {
+	assert.Equal(t, "#000000", shadeColor("#000000", 0.83))
+	assert.Equal(t, "#7f7f7f", shadeColor("#ffffff", 0.5))
+	assert.Equal(t, "#7f0000", shadeColor("#f00", 0.5))
+	assert.Equal(t, "red", shadeColor("red", 0.5))
+}
+
+// TestCategoryStyles_Theme 测试栈帧分类的 CSS 和展示名跟随分类样式配置
+func TestCategoryStyles_Theme(t *testing.T) {
+	t.Cleanup(func() { locator.SetCategoryTheme(nil) })
+
+	theme := locator.DefaultCategoryTheme()
+	theme[locator.CategoryBusiness] = locator.CategoryStyle{Icon: "🏢", Label: "Business", Color: "#ff0000"}
+	locator.SetCategoryTheme(theme)
+
+	styles := categoryStyles()
+	require.Len(t, styles, len(locator.Categories()))
+	assert.Equal(t, HTMLCategoryStyle{Class: "frame-business", Color: "#ff0000", Shade: "#d30000"}, styles[0])
+
+	ctx := &locator.ProblemContext{
+		Title: "CPU 热点",
+		HotPaths: []locator.HotPath{{
+			Chain: locator.CallChain{Frames: []locator.StackFrame{
+				{FunctionName: "github.com/myapp/api.Handle", ShortName: "Handle", Category: locator.CategoryBusiness},
+			}},
+			BusinessFrames: []int{0},
+			RootCauseIndex: 0,
+		}},
+	}
+	finding := rules.Finding{RuleID: "cpu_hotspot", Title: "CPU 热点", Severity: "medium"}
+	outputPath := filepath.Join(t.TempDir(), "report.html")
+	require.NoError(t, GenerateHTMLReportWithOptions(nil, nil, []rules.Finding{finding},
+		map[string]*locator.ProblemContext{"cpu_hotspot": ctx}, outputPath, DefaultOptions()))
+
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	html := string(content)
+	assert.Contains(t, html, "linear-gradient(135deg, #ff0000 0%, #d30000 100%)")
+	assert.Contains(t, html, `<span class="frame-category frame-business">🏢 Business</span>`)
+}
+
+// TestWriteHTMLReport_CustomTemplate 自定义模板使用与内置模板相同的数据和辅助函数
+func TestWriteHTMLReport_CustomTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "brand.html")
+	custom := `<h1 class="brand">{{.Title}}</h1>{{range .Groups}}<p>{{.Title}}: {{len .Files}} {{formatBytes 2048}}</p>{{end}}`
+	require.NoError(t, os.WriteFile(path, []byte(custom), 0644))
+
+	groups := []analyzer.ProfileGroup{{
+		Type:  "cpu",
+		Files: []analyzer.ProfileFile{{Path: "/cpu.pprof", Time: time.Now(), Metrics: &analyzer.ProfileMetrics{}}},
+	}}
+	opts := DefaultOptions()
+	opts.HTMLTemplatePath = path
+	var buf strings.Builder
+	require.NoError(t, WriteHTMLReport(&buf, &Report{Groups: groups, Options: opts}))
+	assert.Equal(t, `<h1 class="brand">PerfInspector 分析报告</h1><p>cpu: 1 2.00 KB</p>`, buf.String())
+}
+
+// TestValidateHTMLTemplate 模板无法读取或解析时返回包含路径的错误
+func TestValidateHTMLTemplate(t *testing.T) {
+	assert.NoError(t, ValidateHTMLTemplate(""))
+
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.html")
+	require.NoError(t, os.WriteFile(valid, []byte(`{{template "trend-outliers" .}}`), 0644))
+	assert.NoError(t, ValidateHTMLTemplate(valid))
+
+	invalid := filepath.Join(dir, "invalid.html")
+	require.NoError(t, os.WriteFile(invalid, []byte(`{{range .Groups}}`), 0644))
+	err := ValidateHTMLTemplate(invalid)
+	assert.ErrorContains(t, err, "invalid HTML template '"+invalid+"'")
+
+	err = ValidateHTMLTemplate(filepath.Join(dir, "missing.html"))
+	assert.ErrorContains(t, err, "failed to read HTML template")
+}
+
+// TestGenerateHTMLReport_Threadcreate threadcreate 分组展示线程数和线程创建调用点
+func TestGenerateHTMLReport_Threadcreate(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "report.html")
+	groups := []analyzer.ProfileGroup{{
+		Type: "threadcreate",
+		Files: []analyzer.ProfileFile{{Path: "/path/to/threadcreate.pprof", Metrics: &analyzer.ProfileMetrics{
+			ThreadCount:  12,
+			TopFunctions: []analyzer.FunctionStat{{Name: "github.com/myapp/codec.Encode", Cum: 10, CumPct: 83.3}},
+		}}},
+	}}
+
+	require.NoError(t, GenerateHTMLReport(groups, nil, nil, outputPath))
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	html := string(content)
+
+	assert.Contains(t, html, "🧵")
+	assert.Contains(t, html, `<div class="metric-label">线程数</div>`)
+	assert.Contains(t, html, `<div class="metric-value highlight">12</div>`)
+	assert.Contains(t, html, "Top 线程创建调用点")
+	assert.Contains(t, html, `<span class="func-pct">83.3% (10)</span>`)
+}
@@ -0,0 +1,45 @@
+package reporter
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+)
+
+// parseHTMLTemplate 解析 HTML 报告模板，opts.HTMLTemplatePath 为空时使用内置模板
+// 自定义模板与内置模板使用相同的数据 (HTMLReportData) 和辅助函数 (htmlFuncMap)，
+// 也可以通过 {{template "trend-outliers" .}} 等引用内置子模板；自定义模板中同名的 define 优先
+func parseHTMLTemplate(opts Options) (*template.Template, error) {
+	tmpl, err := template.New("report").Funcs(htmlFuncMap(opts)).Parse(trendOutliersTemplate + ownershipTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	path := opts.HTMLTemplatePath
+	if path == "" {
+		if _, err := tmpl.Parse(htmlTemplate); err != nil {
+			return nil, fmt.Errorf("failed to parse template: %w", err)
+		}
+		return tmpl, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HTML template '%s': %w", path, err)
+	}
+	if _, err := tmpl.Parse(string(content)); err != nil {
+		return nil, fmt.Errorf("invalid HTML template '%s': %w", path, err)
+	}
+	return tmpl, nil
+}
+
+// ValidateHTMLTemplate 校验自定义 HTML 报告模板可以读取并解析，空路径表示使用内置模板
+func ValidateHTMLTemplate(path string) error {
+	if path == "" {
+		return nil
+	}
+	opts := DefaultOptions()
+	opts.HTMLTemplatePath = path
+	_, err := parseHTMLTemplate(opts)
+	return err
+}
@@ -0,0 +1,215 @@
+package reporter
+
+import (
+	"bufio"
+	"fmt"
+	"html/template"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+	"github.com/songzhibin97/perfinspector/pkg/locator"
+)
+
+// IndexRow 文件索引 (-index) 中的一行，对应一个 profile 文件
+type IndexRow struct {
+	Path    string
+	Type    string
+	Time    time.Time
+	Size    int64
+	Samples int64
+	Metric  string // 该类型的关键指标，如 "CPU 13.2s"、"inuse 412 MB"、"goroutine 1,234"、"阻塞 2.5s (120 次)"
+}
+
+// BuildIndex 按分组顺序汇总每个 profile 文件的基本信息和关键指标，复用已提取的 ProfileMetrics
+func BuildIndex(groups []analyzer.ProfileGroup) []IndexRow {
+	var rows []IndexRow
+	for _, group := range groups {
+		for _, file := range group.Files {
+			row := IndexRow{Path: file.Path, Type: group.Type, Time: file.Time, Size: file.Size, Metric: "-"}
+			if m := file.Metrics; m != nil {
+				row.Samples = m.TotalSamples
+				row.Metric = indexMetric(group.Type, m)
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// indexMetric 返回 profile 类型对应的关键指标
+func indexMetric(profileType string, m *analyzer.ProfileMetrics) string {
+	switch profileType {
+	case "cpu":
+		if m.NoDuration {
+			return "CPU 未知 (缺少采样时长)"
+		}
+		return "CPU " + locator.FormatValue(int64(m.CPUTime), "nanoseconds")
+	case "heap":
+		return "inuse " + analyzer.FormatBytes(m.InuseSpace)
+	case "goroutine":
+		return "goroutine " + analyzer.FormatInt(m.GoroutineCount)
+	case "threadcreate":
+		return "线程 " + analyzer.FormatInt(m.ThreadCount)
+	case "block":
+		return "阻塞 " + locator.FormatValue(int64(m.BlockDelay), "nanoseconds") + " (" + analyzer.FormatInt(m.Contentions) + " 次)"
+	default:
+		if m.ValueType == "" {
+			return "-"
+		}
+		return m.ValueType + " " + formatSampleValue(m.TotalValue, m.ValueUnit)
+	}
+}
+
+// indexTime 格式化快照时间，未知时返回 "-"
+func indexTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// indexHeader 文件索引的表头
+var indexHeader = []string{"文件", "类型", "时间", "大小", "样本数", "关键指标"}
+
+// indexCells 返回一行的各列文本，文件只展示文件名
+func indexCells(row IndexRow) []string {
+	return []string{
+		filepath.Base(row.Path),
+		row.Type,
+		indexTime(row.Time),
+		formatSize(row.Size),
+		analyzer.FormatInt(row.Samples),
+		row.Metric,
+	}
+}
+
+// WriteTextIndex 将文件索引以对齐的表格写入 w
+func WriteTextIndex(w io.Writer, rows []IndexRow, opts Options) error {
+	if opts.NoEmoji {
+		opts.NoEmoji = false
+		return writePlain(w, func(buf io.Writer) error { return WriteTextIndex(buf, rows, opts) })
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "\n"+"═══════════════════════════════════════════════════════════")
+	fmt.Fprintf(bw, "                    PerfInspector %s 文件索引\n", opts.version())
+	fmt.Fprintln(bw, "═══════════════════════════════════════════════════════════")
+	if len(rows) == 0 {
+		fmt.Fprintln(bw, "\n📋 没有可用的 profile 文件")
+		return bw.Flush()
+	}
+	fmt.Fprintf(bw, "\n📋 共 %d 个 profile 文件:\n\n", len(rows))
+
+	table := [][]string{indexHeader}
+	for _, row := range rows {
+		table = append(table, indexCells(row))
+	}
+	writeAlignedTable(bw, table, 3, 4)
+	return bw.Flush()
+}
+
+// writeAlignedTable 按显示宽度对齐各列，中文等宽字符按两列计算；rightAligned 中的列 (如数值列) 右对齐
+func writeAlignedTable(w io.Writer, table [][]string, rightAligned ...int) {
+	right := make(map[int]bool, len(rightAligned))
+	for _, i := range rightAligned {
+		right[i] = true
+	}
+	widths := make([]int, len(table[0]))
+	for _, cells := range table {
+		for i, cell := range cells {
+			if width := displayWidth(cell); width > widths[i] {
+				widths[i] = width
+			}
+		}
+	}
+
+	for _, cells := range table {
+		var line strings.Builder
+		line.WriteString("   ")
+		for i, cell := range cells {
+			padding := strings.Repeat(" ", widths[i]-displayWidth(cell))
+			if right[i] {
+				line.WriteString(padding + cell)
+			} else {
+				line.WriteString(cell + padding)
+			}
+			if i < len(cells)-1 {
+				line.WriteString("  ")
+			}
+		}
+		fmt.Fprintln(w, strings.TrimRight(line.String(), " "))
+	}
+}
+
+// displayWidth 返回字符串在终端中的显示宽度，中日韩文字和全角符号占两列
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		switch {
+		case unicode.Is(unicode.Han, r), r >= 0x3000 && r <= 0x303F, r >= 0xFF01 && r <= 0xFF60:
+			width += 2
+		default:
+			width++
+		}
+	}
+	return width
+}
+
+// htmlIndexData 文件索引 HTML 模板数据
+type htmlIndexData struct {
+	Version string
+	Header  []string
+	Rows    [][]string
+}
+
+// WriteHTMLIndex 将文件索引以 HTML 表格写入 w
+func WriteHTMLIndex(w io.Writer, rows []IndexRow, opts Options) error {
+	data := htmlIndexData{Version: opts.version(), Header: indexHeader}
+	for _, row := range rows {
+		data.Rows = append(data.Rows, indexCells(row))
+	}
+
+	tmpl, err := template.New("index").Parse(htmlIndexTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+	return nil
+}
+
+// htmlIndexTemplate 文件索引的 HTML 模板
+const htmlIndexTemplate = `<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+    <meta charset="UTF-8">
+    <title>PerfInspector {{.Version}} 文件索引</title>
+    <style>
+        body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; margin: 24px; color: #333; }
+        table { border-collapse: collapse; }
+        th, td { border: 1px solid #ddd; padding: 6px 12px; text-align: left; white-space: nowrap; }
+        th { background: #f5f5f5; }
+        td.num { text-align: right; font-variant-numeric: tabular-nums; }
+    </style>
+</head>
+<body>
+    <h1>PerfInspector {{.Version}} 文件索引</h1>
+    {{if .Rows}}
+    <p>共 {{len .Rows}} 个 profile 文件</p>
+    <table>
+        <thead><tr>{{range .Header}}<th>{{.}}</th>{{end}}</tr></thead>
+        <tbody>
+        {{range .Rows}}<tr>{{range $i, $cell := .}}<td{{if or (eq $i 3) (eq $i 4)}} class="num"{{end}}>{{$cell}}</td>{{end}}</tr>
+        {{end}}</tbody>
+    </table>
+    {{else}}
+    <p>没有可用的 profile 文件</p>
+    {{end}}
+</body>
+</html>
+`
@@ -0,0 +1,110 @@
+package reporter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func indexGroups() []analyzer.ProfileGroup {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	return []analyzer.ProfileGroup{
+		{Type: "cpu", Files: []analyzer.ProfileFile{
+			{Path: "/tmp/profiles/cpu1.pprof", Time: base, Size: 2048, Metrics: &analyzer.ProfileMetrics{TotalSamples: 1500, CPUTime: 13200 * time.Millisecond}},
+			{Path: "/tmp/profiles/cpu2.pprof", Time: base.Add(time.Minute), Size: 512, Metrics: &analyzer.ProfileMetrics{TotalSamples: 20, NoDuration: true}},
+		}},
+		{Type: "heap", Files: []analyzer.ProfileFile{
+			{Path: "/tmp/profiles/heap.pprof", Time: base, Size: 3 << 20, Metrics: &analyzer.ProfileMetrics{TotalSamples: 12345, InuseSpace: 412 << 20}},
+		}},
+		{Type: "goroutine", Files: []analyzer.ProfileFile{
+			{Path: "/tmp/profiles/goroutine.pprof", Size: 100, Metrics: &analyzer.ProfileMetrics{TotalSamples: 3, GoroutineCount: 1234}},
+		}},
+		{Type: "unknown", Files: []analyzer.ProfileFile{
+			{Path: "/tmp/profiles/custom.pprof", Size: 100, Metrics: &analyzer.ProfileMetrics{ValueType: "wait", TotalValue: 2048, ValueUnit: "bytes"}},
+			{Path: "/tmp/profiles/broken.pprof", Size: 10},
+		}},
+	}
+}
+
+func TestBuildIndex(t *testing.T) {
+	rows := BuildIndex(indexGroups())
+	require.Len(t, rows, 6)
+
+	metrics := make([]string, len(rows))
+	for i, row := range rows {
+		metrics[i] = row.Metric
+	}
+	assert.Equal(t, []string{
+		"CPU 13.2s",
+		"CPU 未知 (缺少采样时长)",
+		"inuse 412 MB",
+		"goroutine 1,234",
+		"wait 2.00 KB",
+		"-",
+	}, metrics)
+
+	assert.Equal(t, "heap", rows[2].Type)
+	assert.Equal(t, int64(12345), rows[2].Samples)
+	assert.Equal(t, int64(3<<20), rows[2].Size)
+	assert.Empty(t, BuildIndex(nil))
+}
+
+func TestIndexMetric_Block(t *testing.T) {
+	m := &analyzer.ProfileMetrics{Contentions: 1500, BlockDelay: 2500 * time.Millisecond}
+	assert.Equal(t, "阻塞 2.5s (1,500 次)", indexMetric("block", m))
+}
+
+func TestWriteTextIndex(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteTextIndex(&buf, BuildIndex(indexGroups()), Options{Version: "v1.0.0"}))
+	output := buf.String()
+
+	assert.Contains(t, output, "PerfInspector v1.0.0 文件索引")
+	assert.Contains(t, output, "📋 共 6 个 profile 文件")
+	assert.Contains(t, output, "2024-01-01 10:00:00")
+	assert.NotContains(t, output, "/tmp/profiles", "only file names are shown")
+
+	// 各列按显示宽度对齐：每行 "关键指标" 列的起始位置相同
+	var columns []int
+	for _, line := range strings.Split(output, "\n") {
+		if !strings.Contains(line, ".pprof") && !strings.Contains(line, "关键指标") {
+			continue
+		}
+		idx := strings.LastIndex(line, "  ")
+		columns = append(columns, displayWidth(line[:idx]))
+	}
+	require.Len(t, columns, 7)
+	for _, c := range columns[1:] {
+		assert.Equal(t, columns[0], c, output)
+	}
+}
+
+func TestWriteTextIndex_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteTextIndex(&buf, nil, Options{NoEmoji: true}))
+	assert.Contains(t, buf.String(), "[LIST] 没有可用的 profile 文件")
+}
+
+func TestWriteHTMLIndex(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteHTMLIndex(&buf, BuildIndex(indexGroups()), Options{Version: "v1.0.0"}))
+	output := buf.String()
+
+	assert.Contains(t, output, "<title>PerfInspector v1.0.0 文件索引</title>")
+	assert.Contains(t, output, "<th>关键指标</th>")
+	assert.Contains(t, output, "<td>cpu1.pprof</td>")
+	assert.Contains(t, output, `<td class="num">12,345</td>`)
+	assert.Contains(t, output, "<td>inuse 412 MB</td>")
+	assert.Equal(t, 6, strings.Count(output, "<tr><td"))
+}
+
+func TestDisplayWidth(t *testing.T) {
+	assert.Equal(t, 4, displayWidth("cpu1"))
+	assert.Equal(t, 8, displayWidth("关键指标"))
+	assert.Equal(t, 6, displayWidth("CPU 未"))
+}
@@ -0,0 +1,802 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+	"github.com/songzhibin97/perfinspector/pkg/locator"
+	"github.com/songzhibin97/perfinspector/pkg/rules"
+)
+
+// JSONSchemaVersion JSON 报告的格式版本，字段含义或结构发生不兼容变化时递增
+// 新增字段不改变版本号，消费方应忽略不认识的字段
+const JSONSchemaVersion = 1
+
+// JSONReport JSON 报告的顶层结构
+// 字段名使用 snake_case；列表字段没有数据时为 []，不会是 null；
+// 时长以纳秒整数表示 (字段名以 _ns 结尾)，时间为 RFC 3339 字符串，未知时省略
+type JSONReport struct {
+	SchemaVersion  int                        `json:"schema_version"`
+	Version        string                     `json:"version"`
+	GeneratedAt    string                     `json:"generated_at"`
+	Groups         []JSONGroup                `json:"groups"`
+	Trends         map[string]JSONGroupTrends `json:"trends"` // profile 类型 -> 趋势，没有趋势的分组不出现
+	Findings       []JSONFinding              `json:"findings"`
+	History        *JSONHistory               `json:"history,omitempty"`
+	FindingChanges *JSONFindingChanges        `json:"finding_changes,omitempty"`
+}
+
+// JSONGroup 同类型 profile 的分组
+type JSONGroup struct {
+	Type         string     `json:"type"`
+	Allocs       bool       `json:"allocs,omitempty"` // heap 组的 profile 全部来自 /debug/pprof/allocs
+	TotalSamples int64      `json:"total_samples"`
+	Files        []JSONFile `json:"files"`
+}
+
+// JSONFile 单个 profile 文件，Metrics 在指标提取失败时为 null
+type JSONFile struct {
+	Path string `json:"path"`
+	Time string `json:"time,omitempty"`
+	Size int64  `json:"size"`
+	// DuplicateOf -dedup 时与该快照指标相同的较早快照，不参与趋势计算
+	DuplicateOf string       `json:"duplicate_of,omitempty"`
+	Metrics     *JSONMetrics `json:"metrics"`
+}
+
+// JSONMetrics 对应 analyzer.ProfileMetrics 的全部字段
+// 只适用于某种 profile 类型的列表 (如 gc_assist_sites 只有 cpu) 在没有数据时省略
+type JSONMetrics struct {
+	TotalSamples   int64            `json:"total_samples"`
+	TotalValue     int64            `json:"total_value"`
+	DurationNs     int64            `json:"duration_ns"`
+	NumLocations   int              `json:"num_locations"`
+	NumFunctions   int              `json:"num_functions"`
+	GoVersion      string           `json:"go_version,omitempty"`
+	CategoryTotals map[string]int64 `json:"category_totals,omitempty"`
+	Bench          *JSONBench       `json:"bench,omitempty"`
+	ValueType      string           `json:"value_type,omitempty"`
+	ValueUnit      string           `json:"value_unit,omitempty"`
+
+	CPUTimeNs     int64              `json:"cpu_time_ns"`
+	GCFraction    float64            `json:"gc_fraction"` // 0-1
+	GCAssistSites []JSONGCAssistSite `json:"gc_assist_sites,omitempty"`
+	TopPackages   []JSONPackageStat  `json:"top_packages,omitempty"`
+	NoDuration    bool               `json:"no_duration"`
+
+	AllocObjects       int64                   `json:"alloc_objects"`
+	AllocSpace         int64                   `json:"alloc_space"`
+	InuseObjects       int64                   `json:"inuse_objects"`
+	InuseSpace         int64                   `json:"inuse_space"`
+	AllocRate          float64                 `json:"alloc_rate,omitempty"` // alloc_space / inuse_space
+	PackageRetention   []JSONPackageRetention  `json:"package_retention,omitempty"`
+	PackageMemory      []JSONPackageMemory     `json:"package_memory,omitempty"`
+	ConversionHotspots []JSONConversionHotspot `json:"conversion_hotspots,omitempty"`
+	ReflectionHotspots []JSONReflectionHotspot `json:"reflection_hotspots,omitempty"`
+	AllocationSites    []JSONAllocationSite    `json:"allocation_sites,omitempty"`
+	ChannelAllocations []JSONChannelAllocation `json:"channel_allocations,omitempty"`
+
+	GoroutineCount  int64              `json:"goroutine_count"`
+	GoroutineStates map[string]int     `json:"goroutine_states,omitempty"` // 状态 -> goroutine 数
+	ChannelBlocks   []JSONChannelBlock `json:"channel_blocks,omitempty"`
+
+	ThreadCount int64 `json:"thread_count,omitempty"`
+
+	Contentions  int64 `json:"contentions"`
+	BlockDelayNs int64 `json:"block_delay_ns"`
+
+	TopFunctions      []JSONFunctionStat `json:"top_functions"`
+	TopAllocFunctions []JSONFunctionStat `json:"top_alloc_functions,omitempty"`
+	TopFlatFunctions  []JSONFunctionStat `json:"top_flat_functions,omitempty"`
+}
+
+// JSONBench 基准测试模式下每次操作的消耗
+type JSONBench struct {
+	N              int64   `json:"n"`
+	HarnessSamples int64   `json:"harness_samples"`
+	NsPerOp        float64 `json:"ns_per_op"`
+	NoDuration     bool    `json:"no_duration"`
+	BytesPerOp     float64 `json:"bytes_per_op"`
+	AllocsPerOp    float64 `json:"allocs_per_op"`
+}
+
+// JSONGCAssistSite GC 辅助标记的分配点
+type JSONGCAssistSite struct {
+	Site    string  `json:"site"`
+	ValueNs int64   `json:"value_ns"`
+	Share   float64 `json:"share"` // 0-1
+}
+
+// JSONPackageStat 包的 CPU flat 时间
+type JSONPackageStat struct {
+	Package string  `json:"package"`
+	FlatNs  int64   `json:"flat_ns"`
+	FlatPct float64 `json:"flat_pct"` // 0-100
+}
+
+// JSONPackageRetention 包的内存保留情况
+type JSONPackageRetention struct {
+	Package    string  `json:"package"`
+	AllocSpace int64   `json:"alloc_space"`
+	InuseSpace int64   `json:"inuse_space"`
+	Ratio      float64 `json:"ratio"`
+}
+
+// JSONPackageMemory 包的内存占用
+type JSONPackageMemory struct {
+	Package    string  `json:"package"`
+	Category   string  `json:"category,omitempty"`
+	AllocSpace int64   `json:"alloc_space"`
+	InuseSpace int64   `json:"inuse_space"`
+	AllocPct   float64 `json:"alloc_pct"` // 0-100
+	InusePct   float64 `json:"inuse_pct"` // 0-100
+}
+
+// JSONConversionHotspot string/[]byte 转换分配的调用点
+type JSONConversionHotspot struct {
+	RuntimeFunc string  `json:"runtime_func"`
+	Caller      string  `json:"caller"`
+	AllocSpace  int64   `json:"alloc_space"`
+	Share       float64 `json:"share"` // 0-1
+}
+
+// JSONReflectionHotspot 反射开销的调用点，Value 对 cpu 为纳秒，对 heap 为字节数
+type JSONReflectionHotspot struct {
+	Caller string  `json:"caller"`
+	Value  int64   `json:"value"`
+	Share  float64 `json:"share"` // 0-1
+}
+
+// JSONAllocationSite 分配点
+type JSONAllocationSite struct {
+	Function      string `json:"function"`
+	BusinessFrame string `json:"business_frame,omitempty"`
+	AllocObjects  int64  `json:"alloc_objects"`
+	AllocSpace    int64  `json:"alloc_space"`
+	AvgSize       int64  `json:"avg_size"`
+}
+
+// JSONChannelAllocation 创建 channel 的调用点
+type JSONChannelAllocation struct {
+	Caller     string `json:"caller"`
+	InuseSpace int64  `json:"inuse_space"`
+	AllocSpace int64  `json:"alloc_space"`
+	Growth     int64  `json:"growth"`
+}
+
+// JSONChannelBlock 阻塞在 channel 操作上的 goroutine
+type JSONChannelBlock struct {
+	Op     string `json:"op"`
+	Caller string `json:"caller"`
+	Count  int64  `json:"count"`
+}
+
+// JSONFunctionStat 函数统计
+type JSONFunctionStat struct {
+	Name    string  `json:"name"`
+	Flat    int64   `json:"flat"`
+	FlatPct float64 `json:"flat_pct"` // 0-100
+	Cum     int64   `json:"cum"`
+	CumPct  float64 `json:"cum_pct"` // 0-100
+}
+
+// JSONGroupTrends 分组的趋势，没有计算的趋势省略
+type JSONGroupTrends struct {
+	HeapInuse        *JSONTrend           `json:"heap_inuse,omitempty"`
+	HeapInuseObjects *JSONTrend           `json:"heap_inuse_objects,omitempty"`
+	GoroutineCount   *JSONTrend           `json:"goroutine_count,omitempty"`
+	HeapTrends       map[string]JSONTrend `json:"heap_trends,omitempty"` // heap sample type -> 趋势
+	HeapSampleType   string               `json:"heap_sample_type,omitempty"`
+	// goroutine 数的增长形态: sustained、plateau 或 stable
+	GoroutineGrowthKind string `json:"goroutine_growth_kind,omitempty"`
+	// heap 存活内存呈正常的 GC 锯齿，此时 heap_inuse 的方向记为 stable
+	HeapSawtooth bool `json:"heap_sawtooth,omitempty"`
+}
+
+// JSONTrend 线性回归趋势
+type JSONTrend struct {
+	Slope            float64            `json:"slope"`
+	R2               float64            `json:"r2"`
+	Direction        string             `json:"direction"` // increasing、decreasing 或 stable
+	Points           int                `json:"points"`
+	Mean             float64            `json:"mean"`
+	Min              float64            `json:"min"`
+	Max              float64            `json:"max"`
+	StdDev           float64            `json:"stddev"`
+	Weighting        string             `json:"weighting,omitempty"`
+	Weights          []float64          `json:"weights,omitempty"`
+	Outliers         []JSONTrendOutlier `json:"outliers,omitempty"`
+	WithoutOutliers  *JSONTrend         `json:"without_outliers,omitempty"`
+	GCPhase          []JSONGCPhaseSkew  `json:"gc_phase,omitempty"`
+	GCPhaseSensitive bool               `json:"gc_phase_sensitive"`
+}
+
+// JSONTrendOutlier 离群快照
+type JSONTrendOutlier struct {
+	Index    int     `json:"index"`
+	Time     string  `json:"time,omitempty"`
+	Value    float64 `json:"value"`
+	Expected float64 `json:"expected"`
+}
+
+// JSONGCPhaseSkew 疑似在 GC 前后极值处采集的快照
+type JSONGCPhaseSkew struct {
+	Index     int     `json:"index"`
+	Time      string  `json:"time,omitempty"`
+	Value     float64 `json:"value"`
+	Reference float64 `json:"reference"`
+	Low       bool    `json:"low"`
+}
+
+// JSONFinding 规则发现，Context 为该规则的问题上下文，没有时为 null
+type JSONFinding struct {
+	RuleID          string              `json:"rule_id"`
+	RuleName        string              `json:"rule_name"`
+	Severity        string              `json:"severity"`
+	Title           string              `json:"title"`
+	Evidence        []JSONEvidence      `json:"evidence"`
+	Suggestions     []string            `json:"suggestions"`
+	IsCrossAnalysis bool                `json:"is_cross_analysis"`
+	ProfileTypes    []string            `json:"profile_types"`
+	File            string              `json:"file,omitempty"`
+	Context         *JSONProblemContext `json:"context"`
+}
+
+// JSONEvidence 单条证据，Value 为数值形式，没有数值时省略
+type JSONEvidence struct {
+	Name    string   `json:"name"`
+	Display string   `json:"display"`
+	Value   *float64 `json:"value,omitempty"`
+	Unit    string   `json:"unit,omitempty"`
+}
+
+// JSONProblemContext 问题上下文
+type JSONProblemContext struct {
+	Title          string              `json:"title"`
+	Severity       string              `json:"severity"`
+	Explanation    string              `json:"explanation"`
+	Impact         string              `json:"impact"`
+	HotPaths       []JSONHotPath       `json:"hot_paths"`
+	Commands       []JSONCommand       `json:"commands"`
+	PrimaryCommand *JSONCommand        `json:"primary_command,omitempty"`
+	Suggestions    []JSONSuggestion    `json:"suggestions"`
+	HiddenHotPaths *JSONHiddenHotPaths `json:"hidden_hot_paths,omitempty"`
+	Ownership      *JSONOwnershipTree  `json:"ownership,omitempty"`
+	WindowShift    *JSONWindowShift    `json:"window_shift,omitempty"`
+}
+
+// JSONHotPath 热点调用链
+// Frames 从入口到叶子排列，BoundaryPoints、BusinessFrames 和 RootCauseIndex 都是 Frames 的索引
+type JSONHotPath struct {
+	ProfileType        string         `json:"profile_type,omitempty"`
+	TotalValue         int64          `json:"total_value"`
+	TotalPct           float64        `json:"total_pct"` // 0-100
+	Value              string         `json:"value,omitempty"`
+	Unit               string         `json:"unit,omitempty"`
+	SampleCount        int            `json:"sample_count"`
+	Summary            string         `json:"summary"`
+	CategoryBreakdown  map[string]int `json:"category_breakdown"`
+	BoundaryPoints     []int          `json:"boundary_points"`
+	BusinessFrames     []int          `json:"business_frames"`
+	RootCauseIndex     int            `json:"root_cause_index"` // -1 表示没有业务代码
+	UnsymbolizedFrames int            `json:"unsymbolized_frames"`
+	Frames             []JSONFrame    `json:"frames"`
+}
+
+// JSONFrame 栈帧
+type JSONFrame struct {
+	Function  string `json:"function"`
+	ShortName string `json:"short_name"`
+	// ClosureLabel 匿名函数带外层函数的展示名，如 "createWorker()内的闭包#1"
+	ClosureLabel string  `json:"closure_label,omitempty"`
+	Package      string  `json:"package,omitempty"`
+	File         string  `json:"file"`
+	Line         int64   `json:"line"`
+	Category     string  `json:"category"`
+	Flat         int64   `json:"flat"`
+	FlatPct      float64 `json:"flat_pct"`
+	Cum          int64   `json:"cum"`
+	CumPct       float64 `json:"cum_pct"`
+	Recursion    int     `json:"recursion,omitempty"`
+}
+
+// JSONCommand 调试命令
+type JSONCommand struct {
+	Command     string `json:"command"`
+	Description string `json:"description"`
+	OutputHint  string `json:"output_hint,omitempty"`
+	Kind        string `json:"kind,omitempty"`
+	Function    string `json:"function,omitempty"`
+}
+
+// JSONSuggestion 建议，Category 为 immediate 或 long_term
+type JSONSuggestion struct {
+	Category string `json:"category"`
+	Content  string `json:"content"`
+}
+
+// JSONHiddenHotPaths 被排除的没有业务代码的热点路径
+type JSONHiddenHotPaths struct {
+	Count int     `json:"count"`
+	Value int64   `json:"value"`
+	Pct   float64 `json:"pct"`
+}
+
+// JSONOwnershipTree 存活内存归属树
+type JSONOwnershipTree struct {
+	SampleType string              `json:"sample_type"`
+	Total      int64               `json:"total"`
+	Owners     []JSONOwnershipNode `json:"owners"`
+}
+
+// JSONOwnershipNode 归属树节点
+type JSONOwnershipNode struct {
+	Name     string              `json:"name"`
+	Category string              `json:"category"`
+	Location string              `json:"location,omitempty"`
+	Value    int64               `json:"value"`
+	Pct      float64             `json:"pct"`
+	Children []JSONOwnershipNode `json:"children,omitempty"`
+}
+
+// JSONWindowShift 前后两个时间窗口间占比变化的热点路径
+type JSONWindowShift struct {
+	Pivot  string          `json:"pivot,omitempty"`
+	Before int             `json:"before"`
+	After  int             `json:"after"`
+	Grew   []JSONPathShift `json:"grew"`
+	Shrank []JSONPathShift `json:"shrank"`
+}
+
+// JSONPathShift 单条路径的占比变化
+type JSONPathShift struct {
+	Frame     string      `json:"frame"`
+	BeforePct float64     `json:"before_pct"`
+	AfterPct  float64     `json:"after_pct"`
+	DeltaPct  float64     `json:"delta_pct"`
+	Frames    []JSONFrame `json:"frames"`
+}
+
+// JSONHistory 与上一次运行的关键指标对比
+type JSONHistory struct {
+	PreviousTime string             `json:"previous_time,omitempty"`
+	Deltas       []JSONHistoryDelta `json:"deltas"`
+}
+
+// JSONHistoryDelta 单个指标的变化
+type JSONHistoryDelta struct {
+	Metric    string  `json:"metric"`
+	Label     string  `json:"label"`
+	Previous  float64 `json:"previous"`
+	Current   float64 `json:"current"`
+	ChangePct float64 `json:"change_pct"`
+}
+
+// JSONFindingChanges 发现相对上一次运行的变化
+type JSONFindingChanges struct {
+	PreviousTime string              `json:"previous_time,omitempty"`
+	Changes      []JSONFindingChange `json:"changes"`
+	Hidden       int                 `json:"hidden"`
+}
+
+// JSONFindingChange 单个发现的变化，Status 为 new、worse、same 或 resolved
+type JSONFindingChange struct {
+	Status   string         `json:"status"`
+	Current  *FindingRecord `json:"current,omitempty"`
+	Previous *FindingRecord `json:"previous,omitempty"`
+}
+
+// GenerateJSONReport 生成 JSON 格式的分析报告
+func GenerateJSONReport(groups []analyzer.ProfileGroup, trends map[string]*analyzer.GroupTrends, findings []rules.Finding, contexts map[string]*locator.ProblemContext, outputPath string) error {
+	return GenerateJSONReportWithOptions(groups, trends, findings, contexts, outputPath, DefaultOptions())
+}
+
+// GenerateJSONReportWithOptions 使用指定渲染选项生成 JSON 格式的分析报告
+func GenerateJSONReportWithOptions(groups []analyzer.ProfileGroup, trends map[string]*analyzer.GroupTrends, findings []rules.Finding, contexts map[string]*locator.ProblemContext, outputPath string, opts Options) error {
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create output file '%s': %w", outputPath, err)
+	}
+	report := &Report{Groups: groups, Trends: trends, Findings: findings, Contexts: contexts, Options: opts}
+	if err := WriteJSONReport(file, report); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write JSON report '%s': %w", outputPath, err)
+	}
+	return nil
+}
+
+// WriteJSONReport 将 JSON 格式的分析报告写入 w
+// 与文本和 HTML 报告不同，JSON 报告供程序消费，不受 Limits 截断，分组和文件保持输入顺序
+func WriteJSONReport(w io.Writer, report *Report) error {
+	if report.Options.NoEmoji {
+		plain := *report
+		plain.Options.NoEmoji = false
+		return writePlain(w, func(buf io.Writer) error { return WriteJSONReport(buf, &plain) })
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(BuildJSONReport(report)); err != nil {
+		return fmt.Errorf("failed to encode JSON report: %w", err)
+	}
+	return nil
+}
+
+// BuildJSONReport 将分析结果转换为 JSON 报告结构
+func BuildJSONReport(report *Report) JSONReport {
+	opts := report.Options
+	result := JSONReport{
+		SchemaVersion: JSONSchemaVersion,
+		Version:       opts.version(),
+		GeneratedAt:   opts.generatedAt().Format(time.RFC3339),
+		Groups:        make([]JSONGroup, 0, len(report.Groups)),
+		Trends:        make(map[string]JSONGroupTrends, len(report.Trends)),
+		Findings:      make([]JSONFinding, 0, len(report.Findings)),
+	}
+
+	for _, group := range report.Groups {
+		jsonGroup := JSONGroup{Type: group.Type, Allocs: group.Allocs, TotalSamples: group.TotalSamples(), Files: make([]JSONFile, 0, len(group.Files))}
+		for _, file := range group.Files {
+			jsonGroup.Files = append(jsonGroup.Files, JSONFile{
+				Path:        file.Path,
+				Time:        jsonTime(file.Time),
+				Size:        file.Size,
+				DuplicateOf: file.DuplicateOf,
+				Metrics:     convertMetricsForJSON(file.Metrics),
+			})
+		}
+		result.Groups = append(result.Groups, jsonGroup)
+	}
+
+	for groupType, trends := range report.Trends {
+		if trends != nil {
+			result.Trends[groupType] = convertGroupTrendsForJSON(trends)
+		}
+	}
+
+	for _, finding := range report.Findings {
+		result.Findings = append(result.Findings, convertFindingForJSON(finding, report.Contexts[finding.RuleID]))
+	}
+
+	if history := opts.History; history != nil {
+		result.History = &JSONHistory{PreviousTime: jsonTime(history.PreviousTime), Deltas: make([]JSONHistoryDelta, 0, len(history.Deltas))}
+		for _, d := range history.Deltas {
+			result.History.Deltas = append(result.History.Deltas, JSONHistoryDelta{
+				Metric: d.Metric, Label: d.Label, Previous: d.Previous, Current: d.Current, ChangePct: d.ChangePct,
+			})
+		}
+	}
+	if changes := opts.FindingChanges; changes != nil {
+		result.FindingChanges = &JSONFindingChanges{
+			PreviousTime: jsonTime(changes.PreviousTime),
+			Changes:      make([]JSONFindingChange, 0, len(changes.Changes)),
+			Hidden:       changes.Hidden,
+		}
+		for _, c := range changes.Changes {
+			result.FindingChanges.Changes = append(result.FindingChanges.Changes, JSONFindingChange{Status: c.Status, Current: c.Current, Previous: c.Previous})
+		}
+	}
+	return result
+}
+
+// jsonTime 将时间格式化为 RFC 3339，零值返回空字符串
+func jsonTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// convertMetricsForJSON 转换 ProfileMetrics，nil 返回 nil
+func convertMetricsForJSON(m *analyzer.ProfileMetrics) *JSONMetrics {
+	if m == nil {
+		return nil
+	}
+	result := &JSONMetrics{
+		TotalSamples:      m.TotalSamples,
+		TotalValue:        m.TotalValue,
+		DurationNs:        int64(m.Duration),
+		NumLocations:      m.NumLocations,
+		NumFunctions:      m.NumFunctions,
+		GoVersion:         m.GoVersion,
+		CategoryTotals:    m.CategoryTotals,
+		ValueType:         m.ValueType,
+		ValueUnit:         m.ValueUnit,
+		CPUTimeNs:         int64(m.CPUTime),
+		GCFraction:        m.GCFraction,
+		NoDuration:        m.NoDuration,
+		AllocObjects:      m.AllocObjects,
+		AllocSpace:        m.AllocSpace,
+		InuseObjects:      m.InuseObjects,
+		InuseSpace:        m.InuseSpace,
+		AllocRate:         m.AllocRate,
+		GoroutineCount:    m.GoroutineCount,
+		GoroutineStates:   m.GoroutineStates,
+		ThreadCount:       m.ThreadCount,
+		Contentions:       m.Contentions,
+		BlockDelayNs:      int64(m.BlockDelay),
+		TopFunctions:      convertFunctionStatsForJSON(m.TopFunctions),
+		TopAllocFunctions: convertFunctionStatsForJSON(m.TopAllocFunctions),
+		TopFlatFunctions:  convertFunctionStatsForJSON(m.TopFlatFunctions),
+	}
+	if m.Bench != nil {
+		b := m.Bench
+		result.Bench = &JSONBench{
+			N: b.N, HarnessSamples: b.HarnessSamples, NsPerOp: b.NsPerOp,
+			NoDuration: b.NoDuration, BytesPerOp: b.BytesPerOp, AllocsPerOp: b.AllocsPerOp,
+		}
+	}
+	for _, s := range m.GCAssistSites {
+		result.GCAssistSites = append(result.GCAssistSites, JSONGCAssistSite{Site: s.Site, ValueNs: s.Value, Share: s.Share})
+	}
+	for _, p := range m.TopPackages {
+		result.TopPackages = append(result.TopPackages, JSONPackageStat{Package: p.Package, FlatNs: p.Flat, FlatPct: p.FlatPct})
+	}
+	for _, p := range m.PackageRetention {
+		result.PackageRetention = append(result.PackageRetention, JSONPackageRetention{
+			Package: p.Package, AllocSpace: p.AllocSpace, InuseSpace: p.InuseSpace, Ratio: p.Ratio,
+		})
+	}
+	for _, p := range m.PackageMemory {
+		result.PackageMemory = append(result.PackageMemory, JSONPackageMemory{
+			Package: p.Package, Category: p.Category, AllocSpace: p.AllocSpace, InuseSpace: p.InuseSpace,
+			AllocPct: p.AllocPct, InusePct: p.InusePct,
+		})
+	}
+	for _, c := range m.ConversionHotspots {
+		result.ConversionHotspots = append(result.ConversionHotspots, JSONConversionHotspot{
+			RuntimeFunc: c.RuntimeFunc, Caller: c.Caller, AllocSpace: c.AllocSpace, Share: c.Share,
+		})
+	}
+	for _, r := range m.ReflectionHotspots {
+		result.ReflectionHotspots = append(result.ReflectionHotspots, JSONReflectionHotspot{Caller: r.Caller, Value: r.Value, Share: r.Share})
+	}
+	for _, a := range m.AllocationSites {
+		result.AllocationSites = append(result.AllocationSites, JSONAllocationSite{
+			Function: a.Function, BusinessFrame: a.BusinessFrame, AllocObjects: a.AllocObjects,
+			AllocSpace: a.AllocSpace, AvgSize: a.AvgSize,
+		})
+	}
+	for _, c := range m.ChannelAllocations {
+		result.ChannelAllocations = append(result.ChannelAllocations, JSONChannelAllocation{
+			Caller: c.Caller, InuseSpace: c.InuseSpace, AllocSpace: c.AllocSpace, Growth: c.Growth,
+		})
+	}
+	for _, c := range m.ChannelBlocks {
+		result.ChannelBlocks = append(result.ChannelBlocks, JSONChannelBlock{Op: c.Op, Caller: c.Caller, Count: c.Count})
+	}
+	if result.TopFunctions == nil {
+		result.TopFunctions = []JSONFunctionStat{}
+	}
+	return result
+}
+
+// convertFunctionStatsForJSON 转换函数统计列表，空列表返回 nil
+func convertFunctionStatsForJSON(stats []analyzer.FunctionStat) []JSONFunctionStat {
+	var result []JSONFunctionStat
+	for _, s := range stats {
+		result = append(result, JSONFunctionStat{Name: s.Name, Flat: s.Flat, FlatPct: s.FlatPct, Cum: s.Cum, CumPct: s.CumPct})
+	}
+	return result
+}
+
+// convertGroupTrendsForJSON 转换分组趋势
+func convertGroupTrendsForJSON(trends *analyzer.GroupTrends) JSONGroupTrends {
+	result := JSONGroupTrends{
+		HeapInuse:        convertTrendForJSON(trends.HeapInuse),
+		HeapInuseObjects: convertTrendForJSON(trends.HeapInuseObjects),
+		GoroutineCount:   convertTrendForJSON(trends.GoroutineCount),
+		HeapSampleType:   trends.HeapSampleType,
+
+		GoroutineGrowthKind: trends.GoroutineGrowthKind,
+		HeapSawtooth:        trends.HeapSawtooth,
+	}
+	for sampleType, trend := range trends.HeapTrends {
+		if trend == nil {
+			continue
+		}
+		if result.HeapTrends == nil {
+			result.HeapTrends = make(map[string]JSONTrend, len(trends.HeapTrends))
+		}
+		result.HeapTrends[sampleType] = *convertTrendForJSON(trend)
+	}
+	return result
+}
+
+// convertTrendForJSON 转换单个趋势，nil 返回 nil
+func convertTrendForJSON(trend *analyzer.TrendMetrics) *JSONTrend {
+	if trend == nil {
+		return nil
+	}
+	result := &JSONTrend{
+		Slope:            trend.Slope,
+		R2:               trend.R2,
+		Direction:        trend.Direction,
+		Points:           trend.Points,
+		Mean:             trend.Mean,
+		Min:              trend.Min,
+		Max:              trend.Max,
+		StdDev:           trend.StdDev,
+		Weighting:        trend.Weighting,
+		Weights:          trend.Weights,
+		WithoutOutliers:  convertTrendForJSON(trend.WithoutOutliers),
+		GCPhaseSensitive: trend.GCPhaseSensitive,
+	}
+	for _, o := range trend.Outliers {
+		result.Outliers = append(result.Outliers, JSONTrendOutlier{Index: o.Index, Time: jsonTime(o.Time), Value: o.Value, Expected: o.Expected})
+	}
+	for _, s := range trend.GCPhase {
+		result.GCPhase = append(result.GCPhase, JSONGCPhaseSkew{
+			Index: s.Index, Time: jsonTime(s.Time), Value: s.Value, Reference: s.Reference, Low: s.Low,
+		})
+	}
+	return result
+}
+
+// convertFindingForJSON 转换规则发现及其问题上下文
+func convertFindingForJSON(finding rules.Finding, ctx *locator.ProblemContext) JSONFinding {
+	result := JSONFinding{
+		RuleID:          finding.RuleID,
+		RuleName:        finding.RuleName,
+		Severity:        finding.Severity,
+		Title:           finding.Title,
+		Evidence:        make([]JSONEvidence, 0, len(finding.Evidence)),
+		Suggestions:     append(make([]string, 0, len(finding.Suggestions)), finding.Suggestions...),
+		IsCrossAnalysis: finding.IsCrossAnalysis,
+		ProfileTypes:    append(make([]string, 0, len(finding.ProfileTypes)), finding.ProfileTypes...),
+		File:            finding.File,
+		Context:         convertProblemContextForJSON(ctx),
+	}
+	for _, item := range finding.Evidence {
+		evidence := JSONEvidence{Name: item.Name, Display: item.Display, Unit: item.Unit}
+		if item.HasValue {
+			value := item.Value
+			evidence.Value = &value
+		}
+		result.Evidence = append(result.Evidence, evidence)
+	}
+	return result
+}
+
+// convertProblemContextForJSON 转换问题上下文，nil 返回 nil
+func convertProblemContextForJSON(ctx *locator.ProblemContext) *JSONProblemContext {
+	if ctx == nil {
+		return nil
+	}
+	result := &JSONProblemContext{
+		Title:       ctx.Title,
+		Severity:    ctx.Severity,
+		Explanation: ctx.Explanation,
+		Impact:      ctx.Impact,
+		HotPaths:    make([]JSONHotPath, 0, len(ctx.HotPaths)),
+		Commands:    make([]JSONCommand, 0, len(ctx.Commands)),
+		Suggestions: make([]JSONSuggestion, 0, len(ctx.Suggestions)),
+	}
+	for _, hp := range ctx.HotPaths {
+		result.HotPaths = append(result.HotPaths, convertHotPathForJSON(hp))
+	}
+	for _, cmd := range ctx.Commands {
+		result.Commands = append(result.Commands, convertCommandForJSON(cmd))
+	}
+	if ctx.PrimaryCommand != nil {
+		primary := convertCommandForJSON(*ctx.PrimaryCommand)
+		result.PrimaryCommand = &primary
+	}
+	for _, s := range ctx.Suggestions {
+		result.Suggestions = append(result.Suggestions, JSONSuggestion{Category: s.Category, Content: s.Content})
+	}
+	if hidden := ctx.HiddenHotPaths; hidden.Count > 0 {
+		result.HiddenHotPaths = &JSONHiddenHotPaths{Count: hidden.Count, Value: hidden.Value, Pct: hidden.Pct}
+	}
+	if tree := ctx.Ownership; tree != nil {
+		result.Ownership = &JSONOwnershipTree{SampleType: tree.SampleType, Total: tree.Total, Owners: convertOwnershipNodesForJSON(tree.Owners)}
+		if result.Ownership.Owners == nil {
+			result.Ownership.Owners = []JSONOwnershipNode{}
+		}
+	}
+	if shift := ctx.WindowShift; shift != nil {
+		result.WindowShift = &JSONWindowShift{
+			Pivot:  jsonTime(shift.Pivot),
+			Before: shift.Before,
+			After:  shift.After,
+			Grew:   convertPathShiftsForJSON(shift.Grew),
+			Shrank: convertPathShiftsForJSON(shift.Shrank),
+		}
+	}
+	return result
+}
+
+// convertHotPathForJSON 转换热点调用链，CategoryBreakdown 的键为分类名
+func convertHotPathForJSON(hp locator.HotPath) JSONHotPath {
+	result := JSONHotPath{
+		ProfileType:        hp.ProfileType,
+		TotalValue:         hp.Chain.TotalValue,
+		TotalPct:           hp.Chain.TotalPct,
+		Value:              hp.FormatValue(),
+		Unit:               hp.Unit.Unit,
+		SampleCount:        hp.Chain.SampleCount,
+		Summary:            hp.Chain.Summary(),
+		CategoryBreakdown:  make(map[string]int, len(hp.Chain.CategoryBreakdown)),
+		BoundaryPoints:     append(make([]int, 0, len(hp.Chain.BoundaryPoints)), hp.Chain.BoundaryPoints...),
+		BusinessFrames:     append(make([]int, 0, len(hp.BusinessFrames)), hp.BusinessFrames...),
+		RootCauseIndex:     hp.RootCauseIndex,
+		UnsymbolizedFrames: hp.Chain.UnsymbolizedFrames(),
+		Frames:             convertFramesForJSON(hp.Chain.Frames),
+	}
+	for category, count := range hp.Chain.CategoryBreakdown {
+		result.CategoryBreakdown[string(category)] = count
+	}
+	return result
+}
+
+// convertFramesForJSON 转换栈帧列表，空列表返回 []
+func convertFramesForJSON(frames []locator.StackFrame) []JSONFrame {
+	result := make([]JSONFrame, 0, len(frames))
+	for _, f := range frames {
+		result = append(result, JSONFrame{
+			Function:     f.FunctionName,
+			ShortName:    f.ShortName,
+			ClosureLabel: f.ClosureLabel,
+			Package:      f.PackageName,
+			File:         f.FilePath,
+			Line:         f.LineNumber,
+			Category:     string(f.Category),
+			Flat:         f.Flat,
+			FlatPct:      f.FlatPct,
+			Cum:          f.Cum,
+			CumPct:       f.CumPct,
+			Recursion:    f.Recursion,
+		})
+	}
+	return result
+}
+
+// convertCommandForJSON 转换调试命令
+func convertCommandForJSON(cmd locator.ExecutableCmd) JSONCommand {
+	return JSONCommand{
+		Command:     cmd.Command,
+		Description: cmd.Description,
+		OutputHint:  cmd.OutputHint,
+		Kind:        cmd.Kind,
+		Function:    cmd.Function,
+	}
+}
+
+// convertOwnershipNodesForJSON 递归转换归属树节点
+func convertOwnershipNodesForJSON(nodes []locator.OwnershipNode) []JSONOwnershipNode {
+	var result []JSONOwnershipNode
+	for _, node := range nodes {
+		result = append(result, JSONOwnershipNode{
+			Name:     node.Name,
+			Category: string(node.Category),
+			Location: node.Location,
+			Value:    node.Value,
+			Pct:      node.Pct,
+			Children: convertOwnershipNodesForJSON(node.Children),
+		})
+	}
+	return result
+}
+
+// convertPathShiftsForJSON 转换窗口间的路径变化，空列表返回 []
+func convertPathShiftsForJSON(shifts []locator.PathShift) []JSONPathShift {
+	result := make([]JSONPathShift, 0, len(shifts))
+	for _, s := range shifts {
+		result = append(result, JSONPathShift{
+			Frame:     s.Frame().FunctionName,
+			BeforePct: s.BeforePct,
+			AfterPct:  s.AfterPct,
+			DeltaPct:  s.DeltaPct,
+			Frames:    convertFramesForJSON(s.Chain.Frames),
+		})
+	}
+	return result
+}
@@ -0,0 +1,217 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+	"github.com/songzhibin97/perfinspector/pkg/locator"
+	"github.com/songzhibin97/perfinspector/pkg/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeJSONReport 渲染 JSON 报告并解码为通用结构，便于检查 null 和字段名
+func decodeJSONReport(t *testing.T, report *Report) map[string]interface{} {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, WriteJSONReport(&buf, report))
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	return decoded
+}
+
+func TestWriteJSONReport_EmptyArrays(t *testing.T) {
+	decoded := decodeJSONReport(t, &Report{Options: goldenOptions()})
+
+	assert.Equal(t, float64(JSONSchemaVersion), decoded["schema_version"])
+	assert.Equal(t, "v0.1", decoded["version"])
+	assert.Equal(t, "2024-01-02T03:04:05Z", decoded["generated_at"])
+	assert.Equal(t, []interface{}{}, decoded["groups"])
+	assert.Equal(t, []interface{}{}, decoded["findings"])
+	assert.Equal(t, map[string]interface{}{}, decoded["trends"])
+	assert.NotContains(t, decoded, "history")
+	assert.NotContains(t, decoded, "finding_changes")
+
+	decoded = decodeJSONReport(t, &Report{Groups: []analyzer.ProfileGroup{{Type: "cpu"}}, Options: goldenOptions()})
+	groups := decoded["groups"].([]interface{})
+	require.Len(t, groups, 1)
+	assert.Equal(t, []interface{}{}, groups[0].(map[string]interface{})["files"])
+}
+
+func TestBuildJSONReport_Metrics(t *testing.T) {
+	at := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	report := &Report{
+		Groups: []analyzer.ProfileGroup{{
+			Type: "cpu",
+			Files: []analyzer.ProfileFile{
+				{
+					Path: "/profiles/cpu1.pprof", Time: at, Size: 1024,
+					Metrics: &analyzer.ProfileMetrics{
+						TotalSamples:   300,
+						Duration:       30 * time.Second,
+						CPUTime:        13200 * time.Millisecond,
+						GCFraction:     0.25,
+						CategoryTotals: map[string]int64{"business": 10, "runtime": 5},
+						GCAssistSites:  []analyzer.GCAssistSite{{Site: "main.alloc", Value: 1000, Share: 0.1}},
+						TopFunctions:   []analyzer.FunctionStat{{Name: "main.work", Flat: 5, FlatPct: 50, Cum: 8, CumPct: 80}},
+					},
+				},
+				{Path: "/profiles/cpu2.pprof"},
+			},
+		}},
+		Options: goldenOptions(),
+	}
+
+	result := BuildJSONReport(report)
+	require.Len(t, result.Groups, 1)
+	assert.Equal(t, int64(300), result.Groups[0].TotalSamples)
+	files := result.Groups[0].Files
+	require.Len(t, files, 2)
+
+	m := files[0].Metrics
+	require.NotNil(t, m)
+	assert.Equal(t, "2024-01-01T10:00:00Z", files[0].Time)
+	assert.Equal(t, int64(30*time.Second), m.DurationNs)
+	assert.Equal(t, int64(13200*time.Millisecond), m.CPUTimeNs)
+	assert.Equal(t, 0.25, m.GCFraction)
+	assert.Equal(t, map[string]int64{"business": 10, "runtime": 5}, m.CategoryTotals)
+	assert.Equal(t, []JSONGCAssistSite{{Site: "main.alloc", ValueNs: 1000, Share: 0.1}}, m.GCAssistSites)
+	assert.Equal(t, []JSONFunctionStat{{Name: "main.work", Flat: 5, FlatPct: 50, Cum: 8, CumPct: 80}}, m.TopFunctions)
+
+	// 缺少指标和时间的文件
+	assert.Nil(t, files[1].Metrics)
+	assert.Empty(t, files[1].Time)
+}
+
+func TestBuildJSONReport_Trends(t *testing.T) {
+	report := &Report{
+		Trends: map[string]*analyzer.GroupTrends{
+			"heap": {
+				HeapInuse:      &analyzer.TrendMetrics{Slope: 1.5, R2: 0.9, Direction: "increasing", Points: 3},
+				HeapTrends:     map[string]*analyzer.TrendMetrics{"inuse_space": {Slope: 1.5, R2: 0.9, Direction: "increasing", Points: 3}},
+				HeapSampleType: "inuse_space",
+			},
+			"cpu": nil,
+		},
+		Options: goldenOptions(),
+	}
+
+	result := BuildJSONReport(report)
+	require.Contains(t, result.Trends, "heap")
+	assert.NotContains(t, result.Trends, "cpu")
+	heap := result.Trends["heap"]
+	require.NotNil(t, heap.HeapInuse)
+	assert.Equal(t, 1.5, heap.HeapInuse.Slope)
+	assert.Equal(t, 0.9, heap.HeapInuse.R2)
+	assert.Equal(t, "increasing", heap.HeapInuse.Direction)
+	assert.Nil(t, heap.GoroutineCount)
+	assert.Equal(t, "inuse_space", heap.HeapSampleType)
+	assert.Equal(t, 3, heap.HeapTrends["inuse_space"].Points)
+}
+
+func TestBuildJSONReport_FindingWithContext(t *testing.T) {
+	report := &Report{
+		Findings: []rules.Finding{
+			{
+				RuleID: "cpu_hotspot", RuleName: "CPU 热点", Severity: "high", Title: "CPU 热点",
+				Evidence: rules.Evidence{
+					{Name: "pct", Display: "45.0%", Value: 0.45, HasValue: true, Unit: "ratio"},
+					{Name: "function", Display: "main.work"},
+				},
+				ProfileTypes: []string{"cpu"},
+			},
+			{RuleID: "no_context", Severity: "low"},
+		},
+		Contexts: map[string]*locator.ProblemContext{
+			"cpu_hotspot": {
+				Title: "CPU 热点",
+				HotPaths: []locator.HotPath{{
+					Chain: locator.CallChain{
+						Frames: []locator.StackFrame{
+							{FunctionName: "main.main", ShortName: "main", Category: locator.CategoryBusiness},
+							{FunctionName: "<unknown 0x1000>", ShortName: "<unknown 0x1000>", Category: locator.CategoryMissingSymbol},
+						},
+						TotalValue:        100,
+						TotalPct:          45,
+						CategoryBreakdown: map[locator.CodeCategory]int{locator.CategoryBusiness: 1, locator.CategoryMissingSymbol: 1},
+						BoundaryPoints:    []int{1},
+					},
+					BusinessFrames: []int{0},
+					RootCauseIndex: 0,
+					ProfileType:    "cpu",
+				}},
+				Commands:       []locator.ExecutableCmd{{Command: "go tool pprof -top cpu.pprof", Kind: locator.CommandKindTop}},
+				PrimaryCommand: &locator.ExecutableCmd{Command: "go tool pprof -top cpu.pprof", Kind: locator.CommandKindTop},
+			},
+		},
+		Options: goldenOptions(),
+	}
+
+	result := BuildJSONReport(report)
+	require.Len(t, result.Findings, 2)
+
+	finding := result.Findings[0]
+	require.Len(t, finding.Evidence, 2)
+	require.NotNil(t, finding.Evidence[0].Value)
+	assert.Equal(t, 0.45, *finding.Evidence[0].Value)
+	assert.Equal(t, "ratio", finding.Evidence[0].Unit)
+	assert.Nil(t, finding.Evidence[1].Value)
+
+	ctx := finding.Context
+	require.NotNil(t, ctx)
+	require.Len(t, ctx.HotPaths, 1)
+	hp := ctx.HotPaths[0]
+	assert.Equal(t, map[string]int{"business": 1, "missing_symbol": 1}, hp.CategoryBreakdown)
+	assert.Equal(t, 1, hp.UnsymbolizedFrames)
+	assert.Equal(t, "missing_symbol", hp.Frames[1].Category)
+	assert.Equal(t, []JSONCommand{{Command: "go tool pprof -top cpu.pprof", Kind: "top"}}, ctx.Commands)
+	require.NotNil(t, ctx.PrimaryCommand)
+	assert.Equal(t, []JSONSuggestion{}, ctx.Suggestions)
+
+	assert.Nil(t, result.Findings[1].Context)
+	assert.Equal(t, []string{}, result.Findings[1].Suggestions)
+	assert.Equal(t, []string{}, result.Findings[1].ProfileTypes)
+}
+
+func TestBuildJSONReport_History(t *testing.T) {
+	previous := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	opts := goldenOptions()
+	opts.History = &HistoryComparison{
+		PreviousTime: previous,
+		Deltas:       []HistoryDelta{{Metric: HistoryHeapInuse, Label: "heap 使用中内存", Previous: 100, Current: 150, ChangePct: 50}},
+	}
+	opts.FindingChanges = &FindingComparison{
+		PreviousTime: previous,
+		Changes:      []FindingChange{{Status: FindingNew, Current: &FindingRecord{RuleID: "cpu_hotspot", Severity: "high"}}},
+	}
+
+	result := BuildJSONReport(&Report{Options: opts})
+	require.NotNil(t, result.History)
+	assert.Equal(t, "2024-01-01T00:00:00Z", result.History.PreviousTime)
+	assert.Equal(t, []JSONHistoryDelta{{Metric: HistoryHeapInuse, Label: "heap 使用中内存", Previous: 100, Current: 150, ChangePct: 50}}, result.History.Deltas)
+	require.NotNil(t, result.FindingChanges)
+	require.Len(t, result.FindingChanges.Changes, 1)
+	assert.Equal(t, FindingNew, result.FindingChanges.Changes[0].Status)
+	assert.Nil(t, result.FindingChanges.Changes[0].Previous)
+}
+
+func TestGenerateJSONReport(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "report.json")
+	groups := []analyzer.ProfileGroup{{Type: "heap", Files: []analyzer.ProfileFile{{Path: "heap.pprof", Metrics: &analyzer.ProfileMetrics{InuseSpace: 1024}}}}}
+	require.NoError(t, GenerateJSONReport(groups, nil, nil, nil, outputPath))
+
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	var result JSONReport
+	require.NoError(t, json.Unmarshal(content, &result))
+	require.Len(t, result.Groups, 1)
+	assert.Equal(t, int64(1024), result.Groups[0].Files[0].Metrics.InuseSpace)
+
+	err = GenerateJSONReport(groups, nil, nil, nil, filepath.Join(t.TempDir(), "missing", "report.json"))
+	assert.Error(t, err)
+}
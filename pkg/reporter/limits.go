@@ -0,0 +1,89 @@
+package reporter
+
+import (
+	"fmt"
+
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+	"github.com/songzhibin97/perfinspector/pkg/locator"
+	"github.com/songzhibin97/perfinspector/pkg/rules"
+)
+
+// 报告规模的默认上限
+const (
+	DefaultMaxFindings  = 50 // 最多渲染的发现数
+	DefaultMaxHotPaths  = 10 // 每个发现最多渲染的热点路径数
+	DefaultMaxFrames    = 30 // 每条调用链最多渲染的栈帧数
+	DefaultMaxFunctions = 5  // 每个 profile 文件的 Top 函数列表最多渲染的函数数
+)
+
+// Limits 报告规模上限，避免异常输入生成超大报告
+// 各字段为 0 表示不限制；所有格式都通过这里的方法截断，保证行为一致
+type Limits struct {
+	MaxFindings  int
+	MaxHotPaths  int
+	MaxFrames    int
+	MaxFunctions int
+}
+
+// DefaultLimits 返回默认的报告规模上限
+func DefaultLimits() Limits {
+	return Limits{
+		MaxFindings:  DefaultMaxFindings,
+		MaxHotPaths:  DefaultMaxHotPaths,
+		MaxFrames:    DefaultMaxFrames,
+		MaxFunctions: DefaultMaxFunctions,
+	}
+}
+
+// Findings 截断发现列表，返回保留的发现和被省略的数量
+func (l Limits) Findings(findings []rules.Finding) ([]rules.Finding, int) {
+	keep := keepCount(len(findings), l.MaxFindings)
+	return findings[:keep], len(findings) - keep
+}
+
+// HotPaths 截断单个发现的热点路径列表
+func (l Limits) HotPaths(hotPaths []locator.HotPath) ([]locator.HotPath, int) {
+	keep := keepCount(len(hotPaths), l.MaxHotPaths)
+	return hotPaths[:keep], len(hotPaths) - keep
+}
+
+// OwnershipNodes 截断内存归属树的一层节点，每层最多保留 MaxHotPaths 个
+func (l Limits) OwnershipNodes(nodes []locator.OwnershipNode) ([]locator.OwnershipNode, int) {
+	keep := keepCount(len(nodes), l.MaxHotPaths)
+	return nodes[:keep], len(nodes) - keep
+}
+
+// Frames 截断调用链，保留从入口开始的栈帧
+func (l Limits) Frames(frames []locator.StackFrame) ([]locator.StackFrame, int) {
+	keep := keepCount(len(frames), l.MaxFrames)
+	return frames[:keep], len(frames) - keep
+}
+
+// Functions 截断 Top 函数列表
+// heap profile 先跳过 flat 为 0 的函数（它们只出现在调用栈中间）
+func (l Limits) Functions(functions []analyzer.FunctionStat, profileType string) ([]analyzer.FunctionStat, int) {
+	if profileType == "heap" {
+		filtered := make([]analyzer.FunctionStat, 0, len(functions))
+		for _, fn := range functions {
+			if fn.Flat != 0 {
+				filtered = append(filtered, fn)
+			}
+		}
+		functions = filtered
+	}
+	keep := keepCount(len(functions), l.MaxFunctions)
+	return functions[:keep], len(functions) - keep
+}
+
+// keepCount 返回在上限 max 下保留的元素数，max <= 0 表示不限制
+func keepCount(total, max int) int {
+	if max <= 0 || total <= max {
+		return total
+	}
+	return max
+}
+
+// truncatedNote 返回截断提示文本
+func truncatedNote(omitted int) string {
+	return fmt.Sprintf("(truncated, %d more)", omitted)
+}
@@ -0,0 +1,241 @@
+package reporter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+	"github.com/songzhibin97/perfinspector/pkg/locator"
+	"github.com/songzhibin97/perfinspector/pkg/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLimitTestFrames 创建 n 个业务栈帧
+func newLimitTestFrames(n int) []locator.StackFrame {
+	frames := make([]locator.StackFrame, n)
+	for i := range frames {
+		frames[i] = locator.StackFrame{
+			FunctionName: fmt.Sprintf("github.com/myapp/pkg.Frame%d", i),
+			ShortName:    fmt.Sprintf("Frame%d", i),
+			Category:     locator.CategoryBusiness,
+		}
+	}
+	return frames
+}
+
+// newLimitTestContext 创建包含 paths 条热点路径、每条 frames 个栈帧的问题上下文
+func newLimitTestContext(paths, frames int) *locator.ProblemContext {
+	ctx := &locator.ProblemContext{Title: "CPU 热点"}
+	for i := 0; i < paths; i++ {
+		ctx.HotPaths = append(ctx.HotPaths, locator.HotPath{
+			Chain:          locator.CallChain{Frames: newLimitTestFrames(frames), TotalPct: 10},
+			RootCauseIndex: -1,
+		})
+	}
+	return ctx
+}
+
+// newLimitTestFindings 创建 n 个发现
+func newLimitTestFindings(n int) []rules.Finding {
+	findings := make([]rules.Finding, n)
+	for i := range findings {
+		findings[i] = rules.Finding{
+			RuleID:   fmt.Sprintf("rule_%d", i),
+			RuleName: fmt.Sprintf("规则 %d", i),
+			Severity: "medium",
+			Title:    fmt.Sprintf("发现 %d", i),
+		}
+	}
+	return findings
+}
+
+func TestLimits_Findings(t *testing.T) {
+	findings := newLimitTestFindings(5)
+
+	kept, omitted := Limits{MaxFindings: 3}.Findings(findings)
+	assert.Len(t, kept, 3)
+	assert.Equal(t, 2, omitted)
+	assert.Equal(t, "rule_0", kept[0].RuleID)
+
+	kept, omitted = Limits{}.Findings(findings)
+	assert.Len(t, kept, 5, "0 表示不限制")
+	assert.Equal(t, 0, omitted)
+
+	kept, omitted = Limits{MaxFindings: 10}.Findings(findings)
+	assert.Len(t, kept, 5)
+	assert.Equal(t, 0, omitted)
+}
+
+func TestLimits_HotPathsAndFrames(t *testing.T) {
+	ctx := newLimitTestContext(4, 8)
+	limits := Limits{MaxHotPaths: 2, MaxFrames: 3}
+
+	hotPaths, omitted := limits.HotPaths(ctx.HotPaths)
+	assert.Len(t, hotPaths, 2)
+	assert.Equal(t, 2, omitted)
+
+	frames, omitted := limits.Frames(ctx.HotPaths[0].Chain.Frames)
+	require.Len(t, frames, 3)
+	assert.Equal(t, 5, omitted)
+	// 保留从入口开始的栈帧
+	assert.Equal(t, "Frame0", frames[0].ShortName)
+	// 不修改原始数据
+	assert.Len(t, ctx.HotPaths[0].Chain.Frames, 8)
+}
+
+func TestLimits_Functions(t *testing.T) {
+	functions := []analyzer.FunctionStat{
+		{Name: "a", Flat: 10},
+		{Name: "b", Flat: 0},
+		{Name: "c", Flat: 5},
+		{Name: "d", Flat: 1},
+	}
+
+	t.Run("heap skips zero flat", func(t *testing.T) {
+		kept, omitted := Limits{MaxFunctions: 2}.Functions(functions, "heap")
+		require.Len(t, kept, 2)
+		assert.Equal(t, "a", kept[0].Name)
+		assert.Equal(t, "c", kept[1].Name)
+		assert.Equal(t, 1, omitted)
+	})
+
+	t.Run("cpu keeps zero flat", func(t *testing.T) {
+		kept, omitted := Limits{MaxFunctions: 2}.Functions(functions, "cpu")
+		require.Len(t, kept, 2)
+		assert.Equal(t, "b", kept[1].Name)
+		assert.Equal(t, 2, omitted)
+	})
+
+	t.Run("unlimited", func(t *testing.T) {
+		kept, omitted := Limits{}.Functions(functions, "cpu")
+		assert.Len(t, kept, 4)
+		assert.Equal(t, 0, omitted)
+	})
+}
+
+func TestDefaultOptions_Limits(t *testing.T) {
+	assert.Equal(t, DefaultLimits(), DefaultOptions().Limits)
+	assert.Equal(t, DefaultMaxFunctions, DefaultLimits().MaxFunctions)
+}
+
+// TestTextReport_Truncation 测试文本报告的截断提示
+func TestTextReport_Truncation(t *testing.T) {
+	groups := []analyzer.ProfileGroup{
+		{
+			Type: "cpu",
+			Files: []analyzer.ProfileFile{
+				{
+					Path: "/cpu.pprof",
+					Time: time.Now(),
+					Metrics: &analyzer.ProfileMetrics{
+						TopFunctions: []analyzer.FunctionStat{
+							{Name: "main.a", Flat: 3}, {Name: "main.b", Flat: 2}, {Name: "main.c", Flat: 1},
+						},
+					},
+				},
+			},
+		},
+	}
+	findings := newLimitTestFindings(3)
+	contexts := map[string]*locator.ProblemContext{"rule_0": newLimitTestContext(3, 6)}
+
+	opts := DefaultOptions()
+	opts.Limits = Limits{MaxFindings: 1, MaxHotPaths: 1, MaxFrames: 2, MaxFunctions: 1}
+
+	output := captureOutput(func() {
+		GenerateTextReportWithOptions(groups, nil, findings, contexts, opts)
+	})
+
+	assert.Contains(t, output, "发现 0")
+	assert.NotContains(t, output, "发现 1")
+	assert.Contains(t, output, "(truncated, 2 more)", "findings and functions should be truncated")
+	assert.Contains(t, output, "Frame1")
+	assert.NotContains(t, output, "Frame2")
+	assert.Contains(t, output, "(truncated, 4 more)", "frames should be truncated")
+	assert.NotContains(t, output, "热点 #2")
+	assert.NotContains(t, output, "main.b")
+}
+
+// TestGenerateHTMLReport_Truncation 测试 HTML 报告的截断提示
+func TestGenerateHTMLReport_Truncation(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "report.html")
+
+	groups := []analyzer.ProfileGroup{
+		{
+			Type: "heap",
+			Files: []analyzer.ProfileFile{
+				{
+					Path: "/heap.pprof",
+					Time: time.Now(),
+					Metrics: &analyzer.ProfileMetrics{
+						TopFunctions: []analyzer.FunctionStat{
+							{Name: "main.keep", Flat: 3}, {Name: "main.middle", Flat: 0}, {Name: "main.drop", Flat: 1},
+						},
+					},
+				},
+			},
+		},
+	}
+	findings := newLimitTestFindings(4)
+	contexts := map[string]*locator.ProblemContext{"rule_0": newLimitTestContext(3, 5)}
+
+	opts := DefaultOptions()
+	opts.Limits = Limits{MaxFindings: 2, MaxHotPaths: 1, MaxFrames: 2, MaxFunctions: 1}
+	require.NoError(t, GenerateHTMLReportWithOptions(groups, nil, findings, contexts, outputPath, opts))
+
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	html := string(content)
+
+	assert.Contains(t, html, "发现 1")
+	assert.NotContains(t, html, "发现 2")
+	assert.Contains(t, html, "(truncated, 2 more)", "findings and hot paths should be truncated")
+	assert.Contains(t, html, "(truncated, 3 more)", "frames should be truncated")
+	assert.Contains(t, html, "(truncated, 1 more)", "functions should be truncated")
+	assert.Contains(t, html, "main.keep")
+	assert.NotContains(t, html, "main.drop")
+	assert.NotContains(t, html, "Frame2")
+}
+
+// TestLimits_OwnershipNodes 测试内存归属树每层节点的截断
+func TestLimits_OwnershipNodes(t *testing.T) {
+	nodes := make([]locator.OwnershipNode, 4)
+	for i := range nodes {
+		nodes[i] = locator.OwnershipNode{Name: fmt.Sprintf("pkg%d", i), Value: int64(4 - i)}
+	}
+
+	kept, omitted := Limits{MaxHotPaths: 3}.OwnershipNodes(nodes)
+	assert.Len(t, kept, 3)
+	assert.Equal(t, 1, omitted)
+
+	kept, omitted = Limits{}.OwnershipNodes(nodes)
+	assert.Len(t, kept, 4)
+	assert.Equal(t, 0, omitted)
+}
+
+// TestPrintOwnership_Truncation 测试归属树按规模上限截断后的文本输出
+func TestPrintOwnership_Truncation(t *testing.T) {
+	tree := &locator.OwnershipTree{SampleType: "inuse_space", Total: 1000}
+	for i := 0; i < 3; i++ {
+		tree.Owners = append(tree.Owners, locator.OwnershipNode{
+			Name:     fmt.Sprintf("github.com/myapp/pkg%d", i),
+			Category: locator.CategoryBusiness,
+			Value:    int64(300 - i),
+			Pct:      30,
+			Children: []locator.OwnershipNode{{Name: "bytes", Category: locator.CategoryStdlib, Value: int64(300 - i), Pct: 30}},
+		})
+	}
+
+	output := captureOutput(func() { printOwnershipWithLimits(os.Stdout, tree, Limits{MaxHotPaths: 2}) })
+	assert.Contains(t, output, "存活内存归属 (inuse_space, 共 1,000 B)")
+	assert.Contains(t, output, "github.com/myapp/pkg1")
+	assert.NotContains(t, output, "github.com/myapp/pkg2")
+	assert.Contains(t, output, "(truncated, 1 more)")
+	assert.Contains(t, output, "分配于 📚 bytes")
+
+	assert.Empty(t, captureOutput(func() { printOwnershipWithLimits(os.Stdout, nil, Limits{}) }))
+}
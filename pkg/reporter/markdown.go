@@ -0,0 +1,459 @@
+package reporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+	"github.com/songzhibin97/perfinspector/pkg/locator"
+	"github.com/songzhibin97/perfinspector/pkg/rules"
+)
+
+// GenerateMarkdownReport 生成 GitHub 风格 Markdown 格式的分析报告，便于粘贴到 issue 中
+func GenerateMarkdownReport(groups []analyzer.ProfileGroup, trends map[string]*analyzer.GroupTrends, findings []rules.Finding, contexts map[string]*locator.ProblemContext, outputPath string) error {
+	return GenerateMarkdownReportWithOptions(groups, trends, findings, contexts, outputPath, DefaultOptions())
+}
+
+// GenerateMarkdownReportWithOptions 使用指定渲染选项生成 Markdown 格式的分析报告
+func GenerateMarkdownReportWithOptions(groups []analyzer.ProfileGroup, trends map[string]*analyzer.GroupTrends, findings []rules.Finding, contexts map[string]*locator.ProblemContext, outputPath string, opts Options) error {
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create output file '%s': %w", outputPath, err)
+	}
+	report := &Report{Groups: groups, Trends: trends, Findings: findings, Contexts: contexts, Options: opts}
+	if err := WriteMarkdownReport(file, report); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write Markdown report '%s': %w", outputPath, err)
+	}
+	return nil
+}
+
+// WriteMarkdownReport 将 Markdown 格式的分析报告写入 w
+// 每个分组一节，文件指标为表格，命令放在代码块中，热点路径折叠在 <details> 中
+func WriteMarkdownReport(w io.Writer, report *Report) error {
+	opts := report.Options
+	if opts.NoEmoji {
+		plain := *report
+		plain.Options.NoEmoji = false
+		return writePlain(w, func(buf io.Writer) error { return WriteMarkdownReport(buf, &plain) })
+	}
+
+	bw := bufio.NewWriter(w)
+	writeMarkdownReport(bw, report)
+	return bw.Flush()
+}
+
+// writeMarkdownReport 输出 Markdown 报告的全部内容
+func writeMarkdownReport(w io.Writer, report *Report) {
+	opts := report.Options
+	fmt.Fprintf(w, "# PerfInspector %s 分析报告\n", markdownEscape(opts.version()))
+
+	if len(report.Groups) == 0 {
+		fmt.Fprintln(w, "\n📭 没有找到可分析的 profile 文件")
+		return
+	}
+
+	for _, group := range orderGroups(report.Groups, report.Findings, opts.Sort) {
+		if len(group.Files) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n## 📁 %s 分析 (%d 个文件, 共 %s 个样本)\n", markdownEscape(groupTitle(group)), len(group.Files), analyzer.FormatInt(group.TotalSamples()))
+
+		undersampled := analyzer.UndersampledFiles(group.Files, analyzer.DefaultUndersampledRatio)
+		for _, file := range orderFiles(group.Files, opts.Sort) {
+			writeMarkdownFile(w, file, group.Type, undersampled[file.Path], opts)
+		}
+
+		if groupTrends, ok := report.Trends[group.Type]; ok && groupTrends != nil {
+			writeMarkdownTrends(w, groupTrends, opts.TrendThresholds)
+		}
+	}
+
+	findings, omittedFindings := opts.Limits.Findings(report.Findings)
+	var singleFindings, crossFindings []rules.Finding
+	for _, f := range findings {
+		if f.IsCrossAnalysis {
+			crossFindings = append(crossFindings, f)
+		} else {
+			singleFindings = append(singleFindings, f)
+		}
+	}
+
+	writeMarkdownFindings(w, "🔍 规则发现", singleFindings, report.Contexts, opts)
+	writeMarkdownFindings(w, "🔗 联合分析发现", crossFindings, report.Contexts, opts)
+	if omittedFindings > 0 {
+		fmt.Fprintf(w, "\n%s\n", truncatedNote(omittedFindings))
+	}
+}
+
+// writeMarkdownFile 输出单个文件的指标表和 Top 函数表
+func writeMarkdownFile(w io.Writer, file analyzer.ProfileFile, profileType string, undersampled bool, opts Options) {
+	fmt.Fprintf(w, "\n### %s\n\n", markdownEscape(filepath.Base(file.Path)))
+
+	rows := [][]string{{"时间", markdownTime(file.Time)}, {"大小", formatSize(file.Size)}}
+	if m := file.Metrics; m != nil {
+		rows = append(rows, []string{"样本数", analyzer.FormatInt(m.TotalSamples) + undersampledNote(undersampled)})
+		if m.Bench != nil {
+			rows = append(rows, []string{"基准测试", benchSummary(m.Bench, profileType)})
+		}
+		rows = append(rows, markdownMetricRows(m, profileType)...)
+	}
+	writeMarkdownTable(w, []string{"指标", "值"}, rows)
+
+	if file.Metrics == nil {
+		return
+	}
+	m := file.Metrics
+	switch profileType {
+	case "cpu":
+		writeMarkdownFunctions(w, "Top 热点函数", m.TopFunctions, profileType, opts, func(fn analyzer.FunctionStat) (float64, string) {
+			// 缺少采样时长时不展示绝对 CPU 时间
+			if m.NoDuration {
+				return fn.FlatPct, "-"
+			}
+			return fn.FlatPct, locator.FormatValue(fn.Flat, "nanoseconds")
+		})
+	case "heap":
+		flatBytes := func(fn analyzer.FunctionStat) (float64, string) { return fn.FlatPct, analyzer.FormatBytes(fn.Flat) }
+		writeMarkdownFunctions(w, "Top 当前内存占用 (inuse_space)", m.TopFunctions, profileType, opts, flatBytes)
+		writeMarkdownFunctions(w, "Top 累计内存分配 (alloc_space)", m.TopAllocFunctions, profileType, opts, flatBytes)
+	case "goroutine":
+		writeMarkdownFunctions(w, "Top 调用路径", m.TopFunctions, profileType, opts, func(fn analyzer.FunctionStat) (float64, string) {
+			return fn.CumPct, analyzer.FormatInt(fn.Cum)
+		})
+	case "threadcreate":
+		writeMarkdownFunctions(w, "Top 线程创建调用点", m.TopFunctions, profileType, opts, func(fn analyzer.FunctionStat) (float64, string) {
+			return fn.CumPct, analyzer.FormatInt(fn.Cum)
+		})
+	case "block":
+		writeMarkdownFunctions(w, "Top 阻塞调用路径", m.TopFunctions, profileType, opts, func(fn analyzer.FunctionStat) (float64, string) {
+			return fn.CumPct, locator.FormatValue(fn.Cum, "nanoseconds")
+		})
+	default:
+		if m.ValueType != "" {
+			writeMarkdownFunctions(w, fmt.Sprintf("Top 函数 (%s)", m.ValueType), m.TopFunctions, profileType, opts, func(fn analyzer.FunctionStat) (float64, string) {
+				return fn.CumPct, formatSampleValue(fn.Cum, m.ValueUnit)
+			})
+		}
+	}
+}
+
+// markdownMetricRows 返回 profile 类型对应的指标行，与文本报告展示的指标一致
+func markdownMetricRows(m *analyzer.ProfileMetrics, profileType string) [][]string {
+	var rows [][]string
+	switch profileType {
+	case "cpu":
+		if m.CPUTime > 0 {
+			rows = append(rows, []string{"CPU时间", m.CPUTime.String()})
+		}
+		if m.Duration > 0 {
+			rows = append(rows, []string{"采样时长", m.Duration.String()})
+		}
+		if m.NoDuration {
+			rows = append(rows, []string{"采样时长", "⚠️ " + noDurationNote})
+		}
+	case "heap":
+		rows = append(rows,
+			[]string{"已分配", fmt.Sprintf("%s (%s 对象)", analyzer.FormatBytes(m.AllocSpace), analyzer.FormatInt(m.AllocObjects))},
+			[]string{"使用中", fmt.Sprintf("%s (%s 对象)", analyzer.FormatBytes(m.InuseSpace), analyzer.FormatInt(m.InuseObjects))},
+		)
+		if m.AllocSpace > 0 {
+			gcRate := float64(m.AllocSpace-m.InuseSpace) / float64(m.AllocSpace) * 100
+			rows = append(rows, []string{"GC回收率", fmt.Sprintf("%.1f%%", gcRate)})
+		}
+	case "goroutine":
+		rows = append(rows, []string{"Goroutine数", analyzer.FormatInt(m.GoroutineCount)})
+		for _, state := range analyzer.SortedGoroutineStates(m.GoroutineStates) {
+			rows = append(rows, []string{"状态: " + markdownEscape(state.State),
+				fmt.Sprintf("%d (%.1f%%)", state.Count, goroutineStatePct(state.Count, m.GoroutineCount))})
+		}
+	case "threadcreate":
+		rows = append(rows, []string{"线程数", analyzer.FormatInt(m.ThreadCount)})
+	case "block":
+		rows = append(rows,
+			[]string{"阻塞次数", analyzer.FormatInt(m.Contentions)},
+			[]string{"阻塞时间", locator.FormatValue(int64(m.BlockDelay), "nanoseconds")},
+		)
+	default:
+		rows = append(rows, []string{"函数数", fmt.Sprintf("%d", m.NumFunctions)})
+		if m.ValueType != "" {
+			rows = append(rows, []string{fmt.Sprintf("样本值 (%s)", m.ValueType), formatSampleValue(m.TotalValue, m.ValueUnit)})
+		}
+	}
+	return rows
+}
+
+// writeMarkdownFunctions 输出 Top 函数表，value 返回每个函数的占比和展示值
+func writeMarkdownFunctions(w io.Writer, title string, functions []analyzer.FunctionStat, profileType string, opts Options, value func(analyzer.FunctionStat) (float64, string)) {
+	functions, omitted := opts.Limits.Functions(functions, profileType)
+	if len(functions) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "\n**%s**\n\n", markdownEscape(title))
+	rows := make([][]string, 0, len(functions))
+	for i, fn := range functions {
+		pct, display := value(fn)
+		rows = append(rows, []string{fmt.Sprintf("%d", i+1), markdownCode(opts.displayName(fn.Name)), fmt.Sprintf("%.1f%%", pct), display})
+	}
+	writeMarkdownTable(w, []string{"#", "函数", "占比", "值"}, rows)
+	if omitted > 0 {
+		fmt.Fprintf(w, "\n%s\n", truncatedNote(omitted))
+	}
+}
+
+// writeMarkdownTrends 输出达到展示阈值的趋势表
+func writeMarkdownTrends(w io.Writer, trends *analyzer.GroupTrends, thresholds analyzer.TrendThresholds) {
+	var rows [][]string
+	addRow := func(label string, trend *analyzer.TrendMetrics, format func(float64) string) {
+		points := "-"
+		if trend.Points > 0 {
+			points = fmt.Sprintf("%d", trend.Points)
+		}
+		stats := trendStats(trend, format)
+		if stats == "" {
+			stats = "-"
+		}
+		rows = append(rows, []string{getDirectionIcon(trend.Direction) + " " + markdownEscape(label), trend.Direction,
+			fmt.Sprintf("%.2f", trend.Slope), fmt.Sprintf("%.2f", trend.R2), points, stats})
+	}
+
+	if heapTrend := trends.SelectedHeapTrend(); showTrend(thresholds, analyzer.MetricHeapInuse, heapTrend) {
+		addRow(annotatedHeapTrendLabel(trends), heapTrend, heapTrendValue(trends.HeapSampleType))
+	}
+	if objects := trends.HeapInuseObjects; trends.HeapSampleType != analyzer.HeapSampleInuseObjects &&
+		showTrend(thresholds, analyzer.MetricHeapInuseObjects, objects) && objects.Direction == "increasing" {
+		addRow("堆对象数 (inuse_objects)", objects, countTrendValue)
+	}
+	if showTrend(thresholds, analyzer.MetricGoroutineCount, trends.GoroutineCount) {
+		addRow(goroutineTrendLabel(trends), trends.GoroutineCount, countTrendValue)
+	}
+	if len(rows) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w, "\n### 📈 趋势分析")
+	fmt.Fprintln(w)
+	writeMarkdownTable(w, []string{"指标", "方向", "斜率", "R²", "N", "峰值 / 均值 / 波动"}, rows)
+}
+
+// writeMarkdownFindings 输出一类发现，没有发现时不输出标题
+func writeMarkdownFindings(w io.Writer, title string, findings []rules.Finding, contexts map[string]*locator.ProblemContext, opts Options) {
+	if len(findings) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n## %s\n", title)
+	for i, finding := range findings {
+		var ctx *locator.ProblemContext
+		if contexts != nil {
+			ctx = contexts[finding.RuleID]
+		}
+		writeMarkdownFinding(w, i+1, finding, ctx, opts.Limits)
+	}
+}
+
+// writeMarkdownFinding 输出单个发现及其问题上下文
+func writeMarkdownFinding(w io.Writer, index int, finding rules.Finding, ctx *locator.ProblemContext, limits Limits) {
+	fmt.Fprintf(w, "\n### %d. %s %s\n\n", index, getSeverityIcon(finding.Severity), markdownEscape(finding.Title))
+	fmt.Fprintf(w, "- 规则: %s (%s)\n", markdownEscape(finding.RuleName), markdownCode(finding.RuleID))
+	fmt.Fprintf(w, "- 严重程度: %s\n", markdownEscape(finding.Severity))
+	if finding.File != "" {
+		fmt.Fprintf(w, "- 文件: %s\n", markdownCode(finding.File))
+	}
+
+	if ctx == nil {
+		if len(finding.Evidence) > 0 {
+			fmt.Fprintln(w, "\n**证据**")
+			fmt.Fprintln(w)
+			for _, item := range finding.Evidence {
+				fmt.Fprintf(w, "- %s: %s\n", markdownEscape(item.Name), markdownEscape(item.Display))
+			}
+		}
+		if len(finding.Suggestions) > 0 {
+			fmt.Fprintln(w, "\n**建议**")
+			fmt.Fprintln(w)
+			for _, suggestion := range finding.Suggestions {
+				fmt.Fprintf(w, "- %s\n", markdownEscape(suggestion))
+			}
+		}
+		return
+	}
+
+	if cmd := ctx.PrimaryCommand; cmd != nil {
+		fmt.Fprintf(w, "\n**👉 从这里开始:** %s\n\n", markdownEscape(cmd.Description))
+		writeMarkdownCodeBlock(w, cmd.Command)
+	}
+	if ctx.Explanation != "" {
+		fmt.Fprintf(w, "\n**📝 问题解释:** %s\n", markdownEscape(ctx.Explanation))
+	}
+	if ctx.Impact != "" {
+		fmt.Fprintf(w, "\n**📊 影响评估:** %s\n", markdownEscape(ctx.Impact))
+	}
+
+	writeMarkdownHotPaths(w, ctx.HotPaths, limits)
+	if ctx.HiddenHotPaths.Count > 0 {
+		fmt.Fprintf(w, "\n%s\n", markdownEscape(hiddenHotPathsNote(ctx.HiddenHotPaths)))
+	}
+
+	if len(ctx.Commands) > 0 {
+		fmt.Fprintln(w, "\n**💻 调试命令**")
+		for i, cmd := range ctx.Commands {
+			fmt.Fprintf(w, "\n%d. %s\n\n", i+1, markdownEscape(cmd.Description))
+			writeMarkdownCodeBlock(w, cmd.Command)
+			if cmd.OutputHint != "" {
+				fmt.Fprintf(w, "\n说明: %s\n", markdownEscape(cmd.OutputHint))
+			}
+		}
+	}
+
+	if len(ctx.Suggestions) > 0 {
+		fmt.Fprintln(w, "\n**💡 建议**")
+		fmt.Fprintln(w)
+		for _, s := range ctx.Suggestions {
+			label := "立即"
+			if s.Category == "long_term" {
+				label = "长期"
+			}
+			fmt.Fprintf(w, "- [%s] %s\n", label, markdownEscape(s.Content))
+		}
+	}
+}
+
+// writeMarkdownHotPaths 输出热点路径，每条路径折叠在 <details> 中
+func writeMarkdownHotPaths(w io.Writer, hotPaths []locator.HotPath, limits Limits) {
+	if len(hotPaths) == 0 {
+		return
+	}
+	hotPaths, omittedPaths := limits.HotPaths(hotPaths)
+
+	fmt.Fprintln(w, "\n**🔥 热点调用链**")
+	for i, hp := range hotPaths {
+		fmt.Fprintf(w, "\n<details>\n<summary>热点 #%d (%s)</summary>\n\n", i+1, markdownEscape(formatPctValue(hp.Chain.TotalPct, hp.FormatValue())))
+
+		if summary := hp.Chain.Summary(); summary != "" {
+			fmt.Fprintf(w, "调用链: %s\n\n", markdownEscape(summary))
+		}
+		if n := hp.Chain.UnsymbolizedFrames(); n > 0 {
+			fmt.Fprintf(w, "⚠️ %d 个栈帧缺少符号，分析结果不完整\n\n", n)
+		}
+
+		businessFrameSet := make(map[int]bool, len(hp.BusinessFrames))
+		for _, idx := range hp.BusinessFrames {
+			businessFrameSet[idx] = true
+		}
+		frames, omittedFrames := limits.Frames(hp.Chain.Frames)
+		rows := make([][]string, 0, len(frames))
+		for j, frame := range frames {
+			mark := ""
+			if businessFrameSet[j] {
+				mark = "关注"
+				if j == hp.RootCauseIndex {
+					mark = "**根因**"
+				}
+			}
+			rows = append(rows, []string{frame.Category.Icon() + " " + frame.Category.String(), markdownCode(frame.DisplayName()), markdownCode(frame.Location()), mark})
+		}
+		writeMarkdownTable(w, []string{"分类", "函数", "位置", "标记"}, rows)
+		if omittedFrames > 0 {
+			fmt.Fprintf(w, "\n%s\n", truncatedNote(omittedFrames))
+		}
+		if !hp.Chain.HasBusinessCode() {
+			fmt.Fprintln(w, "\n⚠️ 该路径中没有业务代码 - 可能是运行时/GC 问题或间接调用")
+		}
+		fmt.Fprintln(w, "\n</details>")
+	}
+
+	if omittedPaths > 0 {
+		fmt.Fprintf(w, "\n%s\n", truncatedNote(omittedPaths))
+	}
+}
+
+// writeMarkdownTable 输出 GFM 表格，单元格内容需已转义
+func writeMarkdownTable(w io.Writer, header []string, rows [][]string) {
+	fmt.Fprintf(w, "| %s |\n", strings.Join(header, " | "))
+	separators := make([]string, len(header))
+	for i := range separators {
+		separators[i] = "---"
+	}
+	fmt.Fprintf(w, "| %s |\n", strings.Join(separators, " | "))
+	for _, row := range rows {
+		fmt.Fprintf(w, "| %s |\n", strings.Join(row, " | "))
+	}
+}
+
+// writeMarkdownCodeBlock 将命令输出为 shell 代码块
+// 围栏比命令中最长的连续反引号更长，避免命令提前结束代码块
+func writeMarkdownCodeBlock(w io.Writer, command string) {
+	fence := strings.Repeat("`", maxBacktickRun(command)+1)
+	if len(fence) < 3 {
+		fence = "```"
+	}
+	fmt.Fprintf(w, "%ssh\n%s\n%s\n", fence, command, fence)
+}
+
+// markdownEscapeReplacer 转义会被解释为 Markdown 语法或破坏表格的字符
+var markdownEscapeReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	`*`, `\*`,
+	`_`, `\_`,
+	`|`, `\|`,
+	`[`, `\[`,
+	`]`, `\]`,
+	`<`, `&lt;`,
+	`>`, `&gt;`,
+	"\r\n", " ",
+	"\n", " ",
+)
+
+// markdownEscape 转义普通文本，结果可以安全地放入段落和表格单元格
+func markdownEscape(s string) string {
+	return markdownEscapeReplacer.Replace(s)
+}
+
+// markdownCode 将文本放入行内代码，如函数名和文件路径
+// 行内代码中反斜杠不起转义作用，因此按最长的连续反引号选择分隔符；竖线仍需转义以免破坏表格
+func markdownCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	s = strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", " "), "\n", " ")
+	s = strings.ReplaceAll(s, "|", `\|`)
+	delimiter := strings.Repeat("`", maxBacktickRun(s)+1)
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return delimiter + s + delimiter
+}
+
+// maxBacktickRun 返回 s 中最长的连续反引号数
+func maxBacktickRun(s string) int {
+	longest, current := 0, 0
+	for _, r := range s {
+		if r == '`' {
+			current++
+			if current > longest {
+				longest = current
+			}
+			continue
+		}
+		current = 0
+	}
+	return longest
+}
+
+// markdownTime 格式化文件时间，未知时返回 "-"
+func markdownTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,143 @@
+package reporter
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+	"github.com/songzhibin97/perfinspector/pkg/locator"
+	"github.com/songzhibin97/perfinspector/pkg/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// renderMarkdown 渲染 Markdown 报告并返回文本
+func renderMarkdown(t *testing.T, report *Report) string {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, WriteMarkdownReport(&buf, report))
+	return buf.String()
+}
+
+func TestMarkdownEscape(t *testing.T) {
+	assert.Equal(t, `(\*Server).handle\_request`, markdownEscape("(*Server).handle_request"))
+	assert.Equal(t, `a \| b`, markdownEscape("a | b"))
+	assert.Equal(t, "\\`x\\`", markdownEscape("`x`"))
+	assert.Equal(t, `&lt;details&gt;`, markdownEscape("<details>"))
+	assert.Equal(t, `\[link\]`, markdownEscape("[link]"))
+	assert.Equal(t, `a b`, markdownEscape("a\nb"))
+}
+
+func TestMarkdownCode(t *testing.T) {
+	assert.Equal(t, "`(*Server).handle_request`", markdownCode("(*Server).handle_request"))
+	assert.Equal(t, "`a \\| b`", markdownCode("a | b"))
+	assert.Equal(t, "``func`1``", markdownCode("func`1"))
+	assert.Equal(t, "`` `quoted` ``", markdownCode("`quoted`"))
+	assert.Empty(t, markdownCode(""))
+}
+
+func TestWriteMarkdownCodeBlock(t *testing.T) {
+	var buf bytes.Buffer
+	writeMarkdownCodeBlock(&buf, "go tool pprof -top cpu.pprof")
+	assert.Equal(t, "```sh\ngo tool pprof -top cpu.pprof\n```\n", buf.String())
+
+	buf.Reset()
+	writeMarkdownCodeBlock(&buf, "echo ````")
+	assert.Equal(t, "`````sh\necho ````\n`````\n", buf.String())
+}
+
+// TestWriteMarkdownReport_TableEscaping 含 *、_、反引号和竖线的函数名不应破坏表格
+func TestWriteMarkdownReport_TableEscaping(t *testing.T) {
+	report := &Report{
+		Groups: []analyzer.ProfileGroup{{
+			Type: "cpu",
+			Files: []analyzer.ProfileFile{{
+				Path: "/profiles/cpu.pprof",
+				Metrics: &analyzer.ProfileMetrics{
+					CPUTime:  1e9,
+					Duration: 1e9,
+					TopFunctions: []analyzer.FunctionStat{
+						{Name: "main.(*Server).handle_request", Flat: 1e8, FlatPct: 10},
+						{Name: "main.weird`name|x", Flat: 5e7, FlatPct: 5},
+					},
+				},
+			}},
+		}},
+		Findings: []rules.Finding{{RuleID: "cpu_hotspot", RuleName: "CPU_热点", Severity: "high", Title: "CPU *热点*"}},
+		Contexts: map[string]*locator.ProblemContext{
+			"cpu_hotspot": {
+				HotPaths: []locator.HotPath{{
+					Chain: locator.CallChain{
+						Frames: []locator.StackFrame{
+							{FunctionName: "main.(*Server).handle_request", ShortName: "(*Server).handle_request", FilePath: "/src/server.go", LineNumber: 12, Category: locator.CategoryBusiness},
+						},
+						TotalPct: 10,
+					},
+					BusinessFrames: []int{0},
+				}},
+				Commands: []locator.ExecutableCmd{{Command: "go tool pprof -list='handle_request' cpu.pprof", Description: "查看 handle_request"}},
+			},
+		},
+		Options: goldenOptions(),
+	}
+
+	output := renderMarkdown(t, report)
+	assert.Contains(t, output, "| 1 | `main.(*Server).handle_request` | 10.0% | 100.0ms |")
+	assert.Contains(t, output, "| 2 | ``main.weird`name\\|x`` | 5.0% | 50.0ms |")
+	assert.Contains(t, output, "### 1. 🔴 CPU \\*热点\\*")
+	assert.Contains(t, output, "- 规则: CPU\\_热点 (`cpu_hotspot`)")
+	assert.Contains(t, output, "<details>\n<summary>热点 #1 (10.0%)</summary>")
+	assert.Contains(t, output, "| 💼 业务 | `(*Server).handle_request` | `/src/server.go:12` | **根因** |")
+	assert.Contains(t, output, "```sh\ngo tool pprof -list='handle_request' cpu.pprof\n```")
+
+	// 表格的每一行列数一致
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "| 1 |") || strings.HasPrefix(line, "| 2 |") {
+			unescaped := strings.Count(line, "|") - strings.Count(line, `\|`)
+			assert.Equal(t, 5, unescaped, line)
+		}
+	}
+}
+
+func TestWriteMarkdownReport_Empty(t *testing.T) {
+	output := renderMarkdown(t, &Report{Options: goldenOptions()})
+	assert.Equal(t, "# PerfInspector v0.1 分析报告\n\n📭 没有找到可分析的 profile 文件\n", output)
+
+	opts := goldenOptions()
+	opts.NoEmoji = true
+	output = renderMarkdown(t, &Report{Options: opts})
+	assert.NotContains(t, output, "📭")
+}
+
+func TestWriteMarkdownReport_Limits(t *testing.T) {
+	opts := goldenOptions()
+	opts.Limits = Limits{MaxFindings: 1}
+	report := &Report{
+		Groups:   []analyzer.ProfileGroup{{Type: "goroutine", Files: []analyzer.ProfileFile{{Path: "g.pprof", Metrics: &analyzer.ProfileMetrics{GoroutineCount: 3}}}}},
+		Findings: []rules.Finding{{RuleID: "a", Title: "A"}, {RuleID: "b", Title: "B"}},
+		Options:  opts,
+	}
+
+	output := renderMarkdown(t, report)
+	assert.Contains(t, output, "| Goroutine数 | 3 |")
+	assert.Contains(t, output, "### 1. ⚪ A")
+	assert.NotContains(t, output, "### 2.")
+	assert.Contains(t, output, truncatedNote(1))
+}
+
+func TestGenerateMarkdownReport(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "report.md")
+	groups := []analyzer.ProfileGroup{{Type: "heap", Files: []analyzer.ProfileFile{{Path: "heap.pprof", Metrics: &analyzer.ProfileMetrics{InuseSpace: 1024}}}}}
+	require.NoError(t, GenerateMarkdownReport(groups, nil, nil, nil, outputPath))
+
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "## 📁 heap 分析")
+	assert.Contains(t, string(content), "| 使用中 | 1.00 KB (0 对象) |")
+
+	err = GenerateMarkdownReport(groups, nil, nil, nil, filepath.Join(t.TempDir(), "missing", "report.md"))
+	assert.Error(t, err)
+}
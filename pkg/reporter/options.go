@@ -0,0 +1,67 @@
+package reporter
+
+import (
+	"time"
+
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+	"github.com/songzhibin97/perfinspector/pkg/locator"
+)
+
+// Options 报告渲染选项
+type Options struct {
+	// TrendThresholds 趋势展示的 R² 阈值，低于阈值的趋势不显示
+	TrendThresholds analyzer.TrendThresholds
+	// ReadableNames Top 函数列表使用 locator.FormatDisplayName 格式化函数名
+	ReadableNames bool
+	// Limits 报告规模上限，超出部分以 "(truncated, N more)" 提示
+	Limits Limits
+	// GeneratedAt 报告生成时间，零值表示使用当前时间；固定后相同输入生成相同的报告
+	GeneratedAt time.Time
+	// Version 报告中显示的版本号，为空时使用 BuildVersion
+	Version string
+	// Sort 分组和文件的展示顺序
+	Sort SortOptions
+	// History 与上一次运行的关键指标对比，nil 表示不展示
+	History *HistoryComparison
+	// FindingChanges 发现相对上一次运行的变化 (新增、恶化、已解决)，nil 表示不展示
+	FindingChanges *FindingComparison
+	// NoEmoji 将报告中的 emoji 替换为 ASCII 符号 (见 PlainText)，用于不支持 emoji 的终端和日志系统
+	NoEmoji bool
+	// SourceLinks HTML 报告中源码位置的链接，零值为绝对路径生成 file:// 链接
+	SourceLinks SourceLinks
+	// HTMLTemplatePath 自定义 HTML 报告模板文件，为空时使用内置模板
+	HTMLTemplatePath string
+}
+
+// DefaultOptions 返回默认的报告渲染选项
+func DefaultOptions() Options {
+	return Options{
+		TrendThresholds: analyzer.DefaultTrendThresholds(),
+		Limits:          DefaultLimits(),
+		Sort:            DefaultSortOptions(),
+	}
+}
+
+// generatedAt 返回报告生成时间 (UTC)
+func (o Options) generatedAt() time.Time {
+	if o.GeneratedAt.IsZero() {
+		return time.Now().UTC()
+	}
+	return o.GeneratedAt.UTC()
+}
+
+// version 返回报告中显示的版本号
+func (o Options) version() string {
+	if o.Version == "" {
+		return BuildVersion()
+	}
+	return o.Version
+}
+
+// displayName 根据选项返回函数的展示名
+func (o Options) displayName(functionName string) string {
+	if o.ReadableNames {
+		return locator.FormatDisplayName(functionName)
+	}
+	return functionName
+}
@@ -0,0 +1,177 @@
+package reporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+	"github.com/songzhibin97/perfinspector/pkg/rules"
+)
+
+// Prometheus 指标名称，作为对外接口保持稳定
+const (
+	MetricFindingsTotal           = "perfinspector_findings_total"
+	MetricProfilesAnalyzed        = "perfinspector_profiles_analyzed"
+	MetricHeapInuseBytes          = "perfinspector_heap_inuse_bytes"
+	MetricHeapInuseSlope          = "perfinspector_heap_inuse_slope_bytes"
+	MetricHeapInuseR2             = "perfinspector_heap_inuse_r2"
+	MetricGoroutineCount          = "perfinspector_goroutine_count"
+	MetricGoroutineSlope          = "perfinspector_goroutine_slope"
+	MetricGoroutineR2             = "perfinspector_goroutine_r2"
+	MetricAnalysisDurationSeconds = "perfinspector_analysis_duration_seconds"
+)
+
+// prometheusSeverities 始终输出的严重程度标签，缺失的严重程度输出 0，便于告警规则引用
+var prometheusSeverities = []string{"critical", "high", "medium", "low"}
+
+// GeneratePrometheusMetrics 以 Prometheus 文本格式将分析结果写入文件
+// 先写临时文件再重命名，避免 node_exporter textfile collector 读到不完整的文件
+func GeneratePrometheusMetrics(groups []analyzer.ProfileGroup, trends map[string]*analyzer.GroupTrends, findings []rules.Finding, duration time.Duration, outputPath string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(outputPath), filepath.Base(outputPath)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create metrics file for '%s': %w", outputPath, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := WritePrometheusMetrics(tmp, groups, trends, findings, duration); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write metrics file '%s': %w", outputPath, err)
+	}
+	// CreateTemp 使用 0600 权限，collector 通常以其他用户运行
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to write metrics file '%s': %w", outputPath, err)
+	}
+	if err := os.Rename(tmp.Name(), outputPath); err != nil {
+		return fmt.Errorf("failed to write metrics file '%s': %w", outputPath, err)
+	}
+	return nil
+}
+
+// WritePrometheusMetrics 以 Prometheus 文本格式输出分析结果
+func WritePrometheusMetrics(w io.Writer, groups []analyzer.ProfileGroup, trends map[string]*analyzer.GroupTrends, findings []rules.Finding, duration time.Duration) error {
+	bw := bufio.NewWriter(w)
+
+	// 1. 按严重程度统计发现数
+	bySeverity := make(map[string]int)
+	for _, f := range findings {
+		bySeverity[f.Severity]++
+	}
+	severities := append([]string(nil), prometheusSeverities...)
+	for severity := range bySeverity {
+		if !containsSeverity(severities, severity) {
+			severities = append(severities, severity)
+		}
+	}
+	sort.Strings(severities[len(prometheusSeverities):])
+
+	writeMetricHeader(bw, MetricFindingsTotal, "Number of findings reported by the last analysis run, by severity.")
+	for _, severity := range severities {
+		fmt.Fprintf(bw, "%s{severity=\"%s\"} %d\n", MetricFindingsTotal, escapeLabelValue(severity), bySeverity[severity])
+	}
+
+	// 2. 各类型 profile 文件数
+	writeMetricHeader(bw, MetricProfilesAnalyzed, "Number of profile files analyzed, by profile type.")
+	for _, group := range sortedGroups(groups) {
+		fmt.Fprintf(bw, "%s{type=\"%s\"} %d\n", MetricProfilesAnalyzed, escapeLabelValue(group.Type), len(group.Files))
+	}
+
+	// 3. 堆内存和 goroutine 的最新值与趋势
+	for _, group := range sortedGroups(groups) {
+		latest := latestMetrics(group)
+		groupTrends := trends[group.Type]
+
+		switch group.Type {
+		case "heap":
+			if latest != nil {
+				writeGauge(bw, MetricHeapInuseBytes, "In-use heap bytes in the latest heap profile.", float64(latest.InuseSpace))
+			}
+			if groupTrends != nil && groupTrends.HeapInuse != nil {
+				writeGauge(bw, MetricHeapInuseSlope, "Linear regression slope of in-use heap bytes per profile.", groupTrends.HeapInuse.Slope)
+				writeGauge(bw, MetricHeapInuseR2, "R squared of the in-use heap trend.", groupTrends.HeapInuse.R2)
+			}
+		case "goroutine":
+			if latest != nil {
+				writeGauge(bw, MetricGoroutineCount, "Goroutine count in the latest goroutine profile.", float64(latest.GoroutineCount))
+			}
+			if groupTrends != nil && groupTrends.GoroutineCount != nil {
+				writeGauge(bw, MetricGoroutineSlope, "Linear regression slope of goroutine count per profile.", groupTrends.GoroutineCount.Slope)
+				writeGauge(bw, MetricGoroutineR2, "R squared of the goroutine count trend.", groupTrends.GoroutineCount.R2)
+			}
+		}
+	}
+
+	// 4. 分析耗时
+	writeGauge(bw, MetricAnalysisDurationSeconds, "Wall-clock duration of the analysis run in seconds.", duration.Seconds())
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	return nil
+}
+
+// writeMetricHeader 输出指标的 HELP 和 TYPE 行
+func writeMetricHeader(w io.Writer, name, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s gauge\n", name)
+}
+
+// writeGauge 输出无标签的 gauge 指标
+func writeGauge(w io.Writer, name, help string, value float64) {
+	writeMetricHeader(w, name, help)
+	fmt.Fprintf(w, "%s %g\n", name, value)
+}
+
+// latestMetrics 返回组内最后一个带指标的文件的指标
+func latestMetrics(group analyzer.ProfileGroup) *analyzer.ProfileMetrics {
+	for i := len(group.Files) - 1; i >= 0; i-- {
+		if group.Files[i].Metrics != nil {
+			return group.Files[i].Metrics
+		}
+	}
+	return nil
+}
+
+// sortedGroups 返回按类型排序的分组副本，保证输出稳定
+func sortedGroups(groups []analyzer.ProfileGroup) []analyzer.ProfileGroup {
+	sorted := append([]analyzer.ProfileGroup(nil), groups...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Type < sorted[j].Type
+	})
+	return sorted
+}
+
+// containsSeverity 检查严重程度是否已在列表中
+func containsSeverity(severities []string, severity string) bool {
+	for _, s := range severities {
+		if s == severity {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeLabelValue 转义 Prometheus 标签值中的反斜杠、双引号和换行
+func escapeLabelValue(value string) string {
+	escaped := make([]rune, 0, len(value))
+	for _, r := range value {
+		switch r {
+		case '\\':
+			escaped = append(escaped, '\\', '\\')
+		case '"':
+			escaped = append(escaped, '\\', '"')
+		case '\n':
+			escaped = append(escaped, '\\', 'n')
+		default:
+			escaped = append(escaped, r)
+		}
+	}
+	return string(escaped)
+}
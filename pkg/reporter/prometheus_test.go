@@ -0,0 +1,125 @@
+package reporter
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+	"github.com/songzhibin97/perfinspector/pkg/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPrometheusTestData 创建包含 heap 和 goroutine 分组的测试数据
+func newPrometheusTestData() ([]analyzer.ProfileGroup, map[string]*analyzer.GroupTrends, []rules.Finding) {
+	groups := []analyzer.ProfileGroup{
+		{
+			Type: "heap",
+			Files: []analyzer.ProfileFile{
+				{Path: "/heap1.pprof", Metrics: &analyzer.ProfileMetrics{InuseSpace: 1024}},
+				{Path: "/heap2.pprof", Metrics: &analyzer.ProfileMetrics{InuseSpace: 4096}},
+			},
+		},
+		{
+			Type: "goroutine",
+			Files: []analyzer.ProfileFile{
+				{Path: "/goroutine.pprof", Metrics: &analyzer.ProfileMetrics{GoroutineCount: 120}},
+			},
+		},
+	}
+	trends := map[string]*analyzer.GroupTrends{
+		"heap": {HeapInuse: &analyzer.TrendMetrics{Slope: 3072, R2: 0.95, Direction: "increasing"}},
+	}
+	findings := []rules.Finding{
+		{RuleID: "a", Severity: "high"},
+		{RuleID: "b", Severity: "high"},
+		{RuleID: "c", Severity: "medium"},
+		{RuleID: "d", Severity: "info"},
+	}
+	return groups, trends, findings
+}
+
+func TestWritePrometheusMetrics(t *testing.T) {
+	groups, trends, findings := newPrometheusTestData()
+
+	var buf bytes.Buffer
+	require.NoError(t, WritePrometheusMetrics(&buf, groups, trends, findings, 1500*time.Millisecond))
+	output := buf.String()
+
+	// 所有已知严重程度都会输出，缺失的为 0
+	assert.Contains(t, output, `perfinspector_findings_total{severity="critical"} 0`)
+	assert.Contains(t, output, `perfinspector_findings_total{severity="high"} 2`)
+	assert.Contains(t, output, `perfinspector_findings_total{severity="medium"} 1`)
+	assert.Contains(t, output, `perfinspector_findings_total{severity="info"} 1`)
+
+	assert.Contains(t, output, `perfinspector_profiles_analyzed{type="goroutine"} 1`)
+	assert.Contains(t, output, `perfinspector_profiles_analyzed{type="heap"} 2`)
+
+	assert.Contains(t, output, "perfinspector_heap_inuse_bytes 4096\n")
+	assert.Contains(t, output, "perfinspector_heap_inuse_slope_bytes 3072\n")
+	assert.Contains(t, output, "perfinspector_heap_inuse_r2 0.95\n")
+	assert.Contains(t, output, "perfinspector_goroutine_count 120\n")
+	assert.NotContains(t, output, "perfinspector_goroutine_slope", "no goroutine trend")
+	assert.Contains(t, output, "perfinspector_analysis_duration_seconds 1.5\n")
+
+	assert.Contains(t, output, "# TYPE perfinspector_findings_total gauge")
+	assert.Contains(t, output, "# HELP perfinspector_analysis_duration_seconds")
+
+	// 每个指标只声明一次 TYPE
+	assert.Equal(t, 1, strings.Count(output, "# TYPE perfinspector_findings_total "))
+}
+
+func TestWritePrometheusMetrics_Stable(t *testing.T) {
+	groups, trends, findings := newPrometheusTestData()
+
+	var first, second bytes.Buffer
+	require.NoError(t, WritePrometheusMetrics(&first, groups, trends, findings, time.Second))
+	// 分组顺序不影响输出
+	reversed := []analyzer.ProfileGroup{groups[1], groups[0]}
+	require.NoError(t, WritePrometheusMetrics(&second, reversed, trends, findings, time.Second))
+	assert.Equal(t, first.String(), second.String())
+}
+
+func TestWritePrometheusMetrics_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WritePrometheusMetrics(&buf, nil, nil, nil, 0))
+	output := buf.String()
+
+	assert.Contains(t, output, `perfinspector_findings_total{severity="high"} 0`)
+	assert.Contains(t, output, "perfinspector_analysis_duration_seconds 0\n")
+	assert.NotContains(t, output, "perfinspector_heap_inuse_bytes")
+}
+
+func TestGeneratePrometheusMetrics(t *testing.T) {
+	groups, trends, findings := newPrometheusTestData()
+	outputPath := filepath.Join(t.TempDir(), "perfinspector.prom")
+
+	require.NoError(t, GeneratePrometheusMetrics(groups, trends, findings, time.Second, outputPath))
+
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `perfinspector_findings_total{severity="high"} 2`)
+
+	info, err := os.Stat(outputPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+
+	// 不残留临时文件
+	entries, err := os.ReadDir(filepath.Dir(outputPath))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestGeneratePrometheusMetrics_InvalidPath(t *testing.T) {
+	err := GeneratePrometheusMetrics(nil, nil, nil, 0, "/nonexistent/dir/perfinspector.prom")
+	assert.Error(t, err)
+}
+
+func TestEscapeLabelValue(t *testing.T) {
+	assert.Equal(t, `a\"b\\c\nd`, escapeLabelValue("a\"b\\c\nd"))
+	assert.Equal(t, "high", escapeLabelValue("high"))
+}
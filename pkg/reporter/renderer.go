@@ -0,0 +1,96 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+	"github.com/songzhibin97/perfinspector/pkg/locator"
+	"github.com/songzhibin97/perfinspector/pkg/rules"
+)
+
+// Report 一次分析的完整结果，是各渲染器的输入
+type Report struct {
+	Groups   []analyzer.ProfileGroup
+	Trends   map[string]*analyzer.GroupTrends
+	Findings []rules.Finding
+	Contexts map[string]*locator.ProblemContext
+	Options  Options
+}
+
+// Renderer 将分析结果渲染为某种输出格式
+// 内置 text、html、json、markdown、dot、csv、summary 七种格式，嵌入方可以通过 RegisterRenderer 注册自定义格式，命令行通过 -format 选择
+type Renderer interface {
+	Render(report *Report, w io.Writer) error
+}
+
+// RendererFunc 将普通函数适配为 Renderer
+type RendererFunc func(report *Report, w io.Writer) error
+
+// Render 实现 Renderer
+func (f RendererFunc) Render(report *Report, w io.Writer) error {
+	return f(report, w)
+}
+
+var (
+	renderersMu sync.RWMutex
+	renderers   = map[string]Renderer{
+		"text":     RendererFunc(func(report *Report, w io.Writer) error { return WriteTextReport(w, report) }),
+		"html":     RendererFunc(func(report *Report, w io.Writer) error { return WriteHTMLReport(w, report) }),
+		"json":     RendererFunc(func(report *Report, w io.Writer) error { return WriteJSONReport(w, report) }),
+		"markdown": RendererFunc(func(report *Report, w io.Writer) error { return WriteMarkdownReport(w, report) }),
+		"dot": RendererFunc(func(report *Report, w io.Writer) error {
+			return WriteDOTGraph(w, report.Findings, report.Contexts, report.Options)
+		}),
+		"csv":     RendererFunc(func(report *Report, w io.Writer) error { return WriteCSVReport(w, report) }),
+		"summary": RendererFunc(func(report *Report, w io.Writer) error { return WriteSummaryReport(w, report) }),
+	}
+)
+
+// RegisterRenderer 注册 format 对应的渲染器
+// format 为空、renderer 为 nil 或格式已注册 (包括内置格式) 时返回错误
+func RegisterRenderer(format string, renderer Renderer) error {
+	if format == "" {
+		return fmt.Errorf("renderer format must not be empty")
+	}
+	if renderer == nil {
+		return fmt.Errorf("renderer for format '%s' is nil", format)
+	}
+
+	renderersMu.Lock()
+	defer renderersMu.Unlock()
+	if _, ok := renderers[format]; ok {
+		return fmt.Errorf("renderer for format '%s' is already registered", format)
+	}
+	renderers[format] = renderer
+	return nil
+}
+
+// LookupRenderer 返回 format 对应的渲染器
+func LookupRenderer(format string) (Renderer, bool) {
+	renderersMu.RLock()
+	defer renderersMu.RUnlock()
+	renderer, ok := renderers[format]
+	return renderer, ok
+}
+
+// RendererFormats 返回已注册的全部格式，按名称排序
+func RendererFormats() []string {
+	renderersMu.RLock()
+	defer renderersMu.RUnlock()
+	formats := make([]string, 0, len(renderers))
+	for format := range renderers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// unregisterRenderer 移除 format 对应的渲染器，仅用于测试
+func unregisterRenderer(format string) {
+	renderersMu.Lock()
+	defer renderersMu.Unlock()
+	delete(renderers, format)
+}
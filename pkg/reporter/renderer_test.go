@@ -0,0 +1,58 @@
+package reporter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// renderRegistered 使用注册表中的渲染器渲染 golden fixture
+func renderRegistered(t *testing.T, format string, opts Options) string {
+	t.Helper()
+	fx := newGoldenFixture()
+	renderer, ok := LookupRenderer(format)
+	require.True(t, ok, format)
+
+	var buf bytes.Buffer
+	require.NoError(t, renderer.Render(&Report{Groups: fx.groups, Trends: fx.trends, Findings: fx.findings, Contexts: fx.contexts, Options: opts}, &buf))
+	return buf.String()
+}
+
+// TestBuiltinRenderers 内置渲染器的输出与原有的生成函数一致
+func TestBuiltinRenderers(t *testing.T) {
+	assert.Equal(t, []string{"csv", "dot", "html", "json", "markdown", "summary", "text"}, RendererFormats())
+
+	for _, format := range []string{"text", "html", "json", "markdown", "dot"} {
+		t.Run(format, func(t *testing.T) {
+			assert.Equal(t, renderGolden(t, format), renderRegistered(t, format, goldenOptions()))
+
+			opts := goldenOptions()
+			opts.NoEmoji = true
+			assert.False(t, hasEmoji(renderRegistered(t, format, opts)))
+		})
+	}
+}
+
+func TestRegisterRenderer(t *testing.T) {
+	dashboard := RendererFunc(func(report *Report, w io.Writer) error {
+		_, err := fmt.Fprintf(w, "findings=%d\n", len(report.Findings))
+		return err
+	})
+	require.NoError(t, RegisterRenderer("dashboard", dashboard))
+	defer unregisterRenderer("dashboard")
+
+	assert.Contains(t, RendererFormats(), "dashboard")
+	assert.Equal(t, "findings=3\n", renderRegistered(t, "dashboard", goldenOptions()))
+
+	assert.EqualError(t, RegisterRenderer("dashboard", dashboard), "renderer for format 'dashboard' is already registered")
+	assert.EqualError(t, RegisterRenderer("text", dashboard), "renderer for format 'text' is already registered")
+	assert.EqualError(t, RegisterRenderer("", dashboard), "renderer format must not be empty")
+	assert.EqualError(t, RegisterRenderer("empty", nil), "renderer for format 'empty' is nil")
+
+	_, ok := LookupRenderer("missing")
+	assert.False(t, ok)
+}
@@ -0,0 +1,143 @@
+package reporter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+	"github.com/songzhibin97/perfinspector/pkg/rules"
+)
+
+// 分组排序方式
+const (
+	GroupOrderType     = "type"     // 按 profile 类型排列 (默认，与分组结果一致)
+	GroupOrderSeverity = "severity" // 最严重发现所在的分组排在最前
+)
+
+// 文件排序方式
+const (
+	FileOrderAsc  = "asc"  // 按采集时间从早到晚 (默认)
+	FileOrderDesc = "desc" // 按采集时间从晚到早
+)
+
+// SortOptions 报告中分组和文件的排列顺序
+// 只影响展示顺序，趋势计算和图表始终按时间正序
+type SortOptions struct {
+	Groups string
+	Files  string
+}
+
+// DefaultSortOptions 返回默认的排序选项
+func DefaultSortOptions() SortOptions {
+	return SortOptions{
+		Groups: GroupOrderType,
+		Files:  FileOrderAsc,
+	}
+}
+
+// ParseSortOptions 解析排序选项，格式为 "groups=severity,files=desc"
+// 未指定的部分保持默认值
+func ParseSortOptions(spec string) (SortOptions, error) {
+	return parseSortOptionsInto(DefaultSortOptions(), spec)
+}
+
+// parseSortOptionsInto 在已有选项的基础上解析排序选项，用于合并多个 -sort 参数
+func parseSortOptionsInto(opts SortOptions, spec string) (SortOptions, error) {
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return opts, fmt.Errorf("invalid sort option %q: expected key=value", part)
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.ToLower(strings.TrimSpace(value))
+		switch key {
+		case "groups":
+			if value != GroupOrderType && value != GroupOrderSeverity {
+				return opts, fmt.Errorf("invalid groups order %q: must be %s or %s", value, GroupOrderType, GroupOrderSeverity)
+			}
+			opts.Groups = value
+		case "files":
+			if value != FileOrderAsc && value != FileOrderDesc {
+				return opts, fmt.Errorf("invalid files order %q: must be %s or %s", value, FileOrderAsc, FileOrderDesc)
+			}
+			opts.Files = value
+		default:
+			return opts, fmt.Errorf("unknown sort key %q: must be groups or files", key)
+		}
+	}
+	return opts, nil
+}
+
+// MergeSortOptions 依次解析多个排序选项，后出现的设置覆盖先出现的
+func MergeSortOptions(specs []string) (SortOptions, error) {
+	opts := DefaultSortOptions()
+	for _, spec := range specs {
+		var err error
+		if opts, err = parseSortOptionsInto(opts, spec); err != nil {
+			return opts, err
+		}
+	}
+	return opts, nil
+}
+
+// severityRank 返回严重程度的排序权重，越严重越大
+func severityRank(severity string) int {
+	switch strings.ToLower(severity) {
+	case "critical":
+		return 4
+	case "high":
+		return 3
+	case "medium":
+		return 2
+	case "low":
+		return 1
+	default:
+		return 0
+	}
+}
+
+// groupSeverityRanks 计算每个 profile 类型下最严重发现的排序权重
+func groupSeverityRanks(findings []rules.Finding) map[string]int {
+	ranks := make(map[string]int)
+	for _, f := range findings {
+		rank := severityRank(f.Severity)
+		for _, profileType := range f.ProfileTypes {
+			if rank > ranks[profileType] {
+				ranks[profileType] = rank
+			}
+		}
+	}
+	return ranks
+}
+
+// orderGroups 按排序选项返回分组的展示顺序，不修改原切片
+// 严重程度相同的分组保持原有顺序
+func orderGroups(groups []analyzer.ProfileGroup, findings []rules.Finding, opts SortOptions) []analyzer.ProfileGroup {
+	if opts.Groups != GroupOrderSeverity {
+		return groups
+	}
+	ranks := groupSeverityRanks(findings)
+	ordered := append([]analyzer.ProfileGroup(nil), groups...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ranks[ordered[i].Type] > ranks[ordered[j].Type]
+	})
+	return ordered
+}
+
+// orderFiles 按排序选项返回文件的展示顺序，不修改原切片
+// 采集时间相同的文件保持原有顺序
+func orderFiles(files []analyzer.ProfileFile, opts SortOptions) []analyzer.ProfileFile {
+	if opts.Files != FileOrderDesc {
+		return files
+	}
+	ordered := append([]analyzer.ProfileFile(nil), files...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Time.After(ordered[j].Time)
+	})
+	return ordered
+}
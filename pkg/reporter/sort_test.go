@@ -0,0 +1,138 @@
+package reporter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+	"github.com/songzhibin97/perfinspector/pkg/rules"
+)
+
+func TestParseSortOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    SortOptions
+		wantErr bool
+	}{
+		{name: "empty", spec: "", want: DefaultSortOptions()},
+		{name: "groups only", spec: "groups=severity", want: SortOptions{Groups: GroupOrderSeverity, Files: FileOrderAsc}},
+		{name: "files only", spec: "files=desc", want: SortOptions{Groups: GroupOrderType, Files: FileOrderDesc}},
+		{name: "both", spec: " groups=SEVERITY , files=desc ", want: SortOptions{Groups: GroupOrderSeverity, Files: FileOrderDesc}},
+		{name: "missing value", spec: "groups", wantErr: true},
+		{name: "unknown key", spec: "findings=severity", wantErr: true},
+		{name: "invalid groups", spec: "groups=name", wantErr: true},
+		{name: "invalid files", spec: "files=newest", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSortOptions(tt.spec)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMergeSortOptions(t *testing.T) {
+	got, err := MergeSortOptions([]string{"groups=severity", "files=desc", "groups=type"})
+	require.NoError(t, err)
+	assert.Equal(t, SortOptions{Groups: GroupOrderType, Files: FileOrderDesc}, got)
+
+	_, err = MergeSortOptions([]string{"files=desc", "files=sideways"})
+	assert.Error(t, err)
+}
+
+func TestOrderGroups(t *testing.T) {
+	groups := []analyzer.ProfileGroup{{Type: "cpu"}, {Type: "goroutine"}, {Type: "heap"}, {Type: "mutex"}}
+	findings := []rules.Finding{
+		{Severity: "medium", ProfileTypes: []string{"cpu"}},
+		{Severity: "high", ProfileTypes: []string{"heap"}},
+		{Severity: "critical", ProfileTypes: []string{"goroutine", "heap"}},
+		{Severity: "low", ProfileTypes: []string{"mutex"}},
+	}
+
+	types := func(groups []analyzer.ProfileGroup) []string {
+		var result []string
+		for _, g := range groups {
+			result = append(result, g.Type)
+		}
+		return result
+	}
+
+	t.Run("default keeps input order", func(t *testing.T) {
+		assert.Equal(t, []string{"cpu", "goroutine", "heap", "mutex"}, types(orderGroups(groups, findings, DefaultSortOptions())))
+	})
+
+	t.Run("severity puts worst first and is stable", func(t *testing.T) {
+		ordered := orderGroups(groups, findings, SortOptions{Groups: GroupOrderSeverity})
+		assert.Equal(t, []string{"goroutine", "heap", "cpu", "mutex"}, types(ordered))
+		// 原切片不被修改
+		assert.Equal(t, []string{"cpu", "goroutine", "heap", "mutex"}, types(groups))
+	})
+
+	t.Run("groups without findings go last", func(t *testing.T) {
+		ordered := orderGroups(groups, findings[:1], SortOptions{Groups: GroupOrderSeverity})
+		assert.Equal(t, []string{"cpu", "goroutine", "heap", "mutex"}, types(ordered))
+	})
+}
+
+func TestOrderFiles(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	files := []analyzer.ProfileFile{
+		{Path: "a", Time: base},
+		{Path: "b", Time: base.Add(time.Minute)},
+		{Path: "c", Time: base.Add(time.Minute)},
+		{Path: "d", Time: base.Add(2 * time.Minute)},
+	}
+
+	paths := func(files []analyzer.ProfileFile) string {
+		var result []string
+		for _, f := range files {
+			result = append(result, f.Path)
+		}
+		return strings.Join(result, ",")
+	}
+
+	assert.Equal(t, "a,b,c,d", paths(orderFiles(files, DefaultSortOptions())))
+	assert.Equal(t, "d,b,c,a", paths(orderFiles(files, SortOptions{Files: FileOrderDesc})))
+	assert.Equal(t, "a,b,c,d", paths(files))
+}
+
+func TestReports_SortOptions(t *testing.T) {
+	fx := newGoldenFixture()
+	opts := goldenOptions()
+	opts.Sort = SortOptions{Groups: GroupOrderSeverity, Files: FileOrderDesc}
+
+	// 只保留 heap 的发现，heap 分组应排到 goroutine 之前
+	findings := []rules.Finding{fx.findings[0]}
+
+	output := captureOutput(func() {
+		GenerateTextReportWithOptions(fx.groups, fx.trends, findings, nil, opts)
+	})
+	heapIdx := strings.Index(output, "📁 heap")
+	goroutineIdx := strings.Index(output, "📁 goroutine")
+	require.True(t, heapIdx >= 0 && goroutineIdx >= 0)
+	assert.Less(t, heapIdx, goroutineIdx)
+	assert.Less(t, strings.Index(output, "heap3.pprof"), strings.Index(output, "heap1.pprof"))
+	// 时间跨度仍按时间正序计算
+	assert.Contains(t, output, "2024-01-01 10:00:00 → 2024-01-01 10:20:00")
+
+	outputPath := filepath.Join(t.TempDir(), "report.html")
+	require.NoError(t, GenerateHTMLReportWithOptions(fx.groups, fx.trends, findings, nil, outputPath, opts))
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	html := string(content)
+	assert.Less(t, strings.Index(html, "heap3.pprof"), strings.Index(html, "heap1.pprof"))
+	assert.Less(t, strings.Index(html, "heap1.pprof"), strings.Index(html, "goroutine3.pprof"))
+}
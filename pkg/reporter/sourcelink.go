@@ -0,0 +1,77 @@
+package reporter
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// 源码链接模板中的占位符
+const (
+	SourceLinkFile = "{file}" // 相对模块根目录的文件路径
+	SourceLinkLine = "{line}" // 行号
+)
+
+// SourceLinks HTML 报告中源码位置的链接配置，Template 为空时为绝对路径生成 file:// 链接
+// 报告在采集 profile 以外的机器上查看时，file:// 链接打不开，可以改为指向代码托管平台的 URL
+type SourceLinks struct {
+	Template string // URL 模板，如 "https://git.example.com/src/{file}#L{line}"
+	Root     string // 采集环境中的模块根目录，{file} 为相对它的路径；为空时按 Module 在路径中定位
+	Module   string // 模块路径，用于定位 -trimpath 构建 (路径以模块路径开头) 或 GOPATH 布局中的模块根目录
+}
+
+// ValidateSourceLinkTemplate 校验源码链接模板，空模板表示使用 file:// 链接
+func ValidateSourceLinkTemplate(template string) error {
+	if template == "" {
+		return nil
+	}
+	if !strings.Contains(template, SourceLinkFile) {
+		return fmt.Errorf("invalid source link template '%s': missing %s placeholder", template, SourceLinkFile)
+	}
+	return nil
+}
+
+// link 按模板生成源码链接，filePath 为空或未知时返回空字符串
+// 行号未知时去掉包含 {line} 的 URL 片段 (如 "#L{line}")，其他位置的 {line} 替换为空
+func (s SourceLinks) link(filePath string, lineNumber int64) string {
+	if filePath == "" || filePath == "unknown" {
+		return ""
+	}
+
+	link := s.Template
+	line := ""
+	if lineNumber > 0 {
+		line = strconv.FormatInt(lineNumber, 10)
+	} else if i := strings.LastIndex(link, "#"); i >= 0 && strings.Contains(link[i:], SourceLinkLine) {
+		link = link[:i]
+	}
+	link = strings.ReplaceAll(link, SourceLinkLine, line)
+	return strings.ReplaceAll(link, SourceLinkFile, escapeSourcePath(s.relativePath(filePath)))
+}
+
+// relativePath 返回文件相对模块根目录的路径，无法定位模块根目录时去掉开头的 "/" 原样使用
+func (s SourceLinks) relativePath(filePath string) string {
+	path := strings.ReplaceAll(filePath, "\\", "/")
+	if root := strings.TrimSuffix(strings.ReplaceAll(s.Root, "\\", "/"), "/"); root != "" && strings.HasPrefix(path, root+"/") {
+		return path[len(root)+1:]
+	}
+	if module := strings.Trim(s.Module, "/"); module != "" {
+		if strings.HasPrefix(path, module+"/") {
+			return path[len(module)+1:]
+		}
+		if i := strings.Index(path, "/"+module+"/"); i >= 0 {
+			return path[i+len(module)+2:]
+		}
+	}
+	return strings.TrimLeft(path, "/")
+}
+
+// escapeSourcePath 逐段转义路径，保留分隔符
+func escapeSourcePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
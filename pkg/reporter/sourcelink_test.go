@@ -0,0 +1,44 @@
+package reporter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSourceLinkTemplate(t *testing.T) {
+	assert.NoError(t, ValidateSourceLinkTemplate(""))
+	assert.NoError(t, ValidateSourceLinkTemplate("https://git.example.com/src/{file}#L{line}"))
+	assert.ErrorContains(t, ValidateSourceLinkTemplate("https://git.example.com/src#L{line}"), "missing {file}")
+}
+
+// TestGenerateFileLink_Template 配置模板时按模块根目录的相对路径和行号生成链接
+func TestGenerateFileLink_Template(t *testing.T) {
+	links := SourceLinks{Template: "https://git.example.com/myapp/src/{file}#L{line}", Root: "/build/myapp", Module: "github.com/myapp"}
+
+	tests := []struct {
+		name       string
+		filePath   string
+		lineNumber int64
+		want       string
+	}{
+		{"under source root", "/build/myapp/cache/store.go", 42, "https://git.example.com/myapp/src/cache/store.go#L42"},
+		{"trimpath build", "github.com/myapp/cache/store.go", 7, "https://git.example.com/myapp/src/cache/store.go#L7"},
+		{"gopath layout", "/go/src/github.com/myapp/cache/store.go", 7, "https://git.example.com/myapp/src/cache/store.go#L7"},
+		{"outside the module", "/usr/local/go/src/net/http/server.go", 10, "https://git.example.com/myapp/src/usr/local/go/src/net/http/server.go#L10"},
+		{"no line number", "/build/myapp/cache/store.go", 0, "https://git.example.com/myapp/src/cache/store.go"},
+		{"escaped segments", "/build/myapp/my dir/a#b.go", 1, "https://git.example.com/myapp/src/my%20dir/a%23b.go#L1"},
+		{"unknown file", "unknown", 10, ""},
+		{"empty file", "", 10, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, generateFileLink(tt.filePath, tt.lineNumber, links))
+		})
+	}
+
+	// {line} 不在 URL 片段中时，行号未知替换为空
+	query := SourceLinks{Template: "https://code.example.com/view?path={file}&line={line}", Root: "/build/myapp"}
+	assert.Equal(t, "https://code.example.com/view?path=cache/store.go&line=", generateFileLink("/build/myapp/cache/store.go", 0, query))
+	assert.Equal(t, "https://code.example.com/view?path=cache/store.go&line=9", generateFileLink("/build/myapp/cache/store.go", 9, query))
+}
@@ -0,0 +1,49 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/songzhibin97/perfinspector/pkg/locator"
+)
+
+// summarySeverities summary 行中按严重程度计数的键，从高到低排列，rank 与 locator.SeverityRank 一致
+var summarySeverities = []struct {
+	key  string
+	rank int
+}{
+	{"critical", 4},
+	{"high", 3},
+	{"medium", 2},
+	{"low", 1},
+}
+
+// WriteSummaryReport 将分析结果写为一行空格分隔的 key=value，供 grep/awk 等脚本使用，如：
+//
+//	findings=3 critical=1 high=1 medium=1 low=0 groups=cpu,goroutine,heap
+//
+// 键的集合和顺序固定，计数为 0 的严重程度同样输出；严重程度按 locator.SeverityRank 归类 (中英文等价)，
+// groups 为有 profile 文件的分组类型，按报告中的分组顺序以逗号连接，没有分组时为空
+func WriteSummaryReport(w io.Writer, report *Report) error {
+	counts := make(map[int]int)
+	for _, finding := range report.Findings {
+		counts[locator.SeverityRank(finding.Severity)]++
+	}
+
+	var groups []string
+	for _, group := range report.Groups {
+		if len(group.Files) > 0 {
+			groups = append(groups, group.Type)
+		}
+	}
+
+	fields := []string{fmt.Sprintf("findings=%d", len(report.Findings))}
+	for _, severity := range summarySeverities {
+		fields = append(fields, fmt.Sprintf("%s=%d", severity.key, counts[severity.rank]))
+	}
+	fields = append(fields, "groups="+strings.Join(groups, ","))
+
+	_, err := fmt.Fprintln(w, strings.Join(fields, " "))
+	return err
+}
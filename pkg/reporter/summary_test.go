@@ -0,0 +1,42 @@
+package reporter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+	"github.com/songzhibin97/perfinspector/pkg/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteSummaryReport(t *testing.T) {
+	report := &Report{
+		Groups: []analyzer.ProfileGroup{
+			{Type: "cpu", Files: []analyzer.ProfileFile{{Path: "/p/cpu.pprof"}}},
+			{Type: "goroutine", Files: []analyzer.ProfileFile{{Path: "/p/goroutine.pprof"}}},
+			{Type: "heap", Files: []analyzer.ProfileFile{{Path: "/p/heap.pprof"}}},
+			{Type: "block"},
+		},
+		Findings: []rules.Finding{
+			{RuleID: "cpu_spike", Severity: "critical"},
+			{RuleID: "heap_growth", Severity: "高"},
+			{RuleID: "goroutine_leak", Severity: "medium"},
+		},
+	}
+
+	var buf strings.Builder
+	require.NoError(t, WriteSummaryReport(&buf, report))
+	assert.Equal(t, "findings=3 critical=1 high=1 medium=1 low=0 groups=cpu,goroutine,heap\n", buf.String())
+}
+
+// TestWriteSummaryReport_Empty 没有发现和分组时同样输出固定的键
+func TestWriteSummaryReport_Empty(t *testing.T) {
+	renderer, ok := LookupRenderer("summary")
+	require.True(t, ok)
+
+	var buf strings.Builder
+	require.NoError(t, renderer.Render(&Report{}, &buf))
+	assert.Equal(t, "findings=0 critical=0 high=0 medium=0 low=0 groups=\n", buf.String())
+	assert.True(t, strings.HasPrefix(buf.String(), "findings=0 "))
+}
@@ -0,0 +1,174 @@
+package reporter
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// plainSymbols 报告中使用的 emoji 及其 ASCII 替代，-no-emoji 时按此表替换
+// 新增 emoji 时在这里补充对应的 ASCII 符号，未登记的 emoji 会被替换为 "[*]"
+var plainSymbols = []string{
+	// 严重程度和级别
+	"🔥", "[CRITICAL]",
+	"🔴", "[HIGH]",
+	"🟡", "[MEDIUM]",
+	"🟢", "[LOW]",
+	"🔵", "[INFO]",
+	"⚪", "[-]",
+	"🚨", "[ALERT]",
+	"⚠️", "[!]",
+	"⚠", "[!]",
+	"✅", "[OK]",
+	"❌", "[X]",
+	"ℹ️", "[i]",
+	"ℹ", "[i]",
+
+	// profile 类型
+	"⚡", "[CPU]",
+	"💾", "[HEAP]",
+	"🔄", "[GOROUTINE]",
+	"⏳", "[BLOCK]",
+	"🧵", "[THREAD]",
+
+	// 代码分类 (locator 内置图标)
+	"💼", "[business]",
+	"🧬", "[generated]",
+	"📚", "[stdlib]",
+	"⚙️", "[runtime]",
+	"⚙", "[runtime]",
+	"❓", "[unknown]",
+	"🚫", "[no-symbol]",
+
+	// 趋势方向
+	"📈", "[UP]",
+	"📉", "[DOWN]",
+	"➡️", "[FLAT]",
+	"➡", "[FLAT]",
+
+	// 章节标题和元信息
+	"📁", "[GROUP]",
+	"📭", "[EMPTY]",
+	"📊", "[STATS]",
+	"📋", "[LIST]",
+	"📝", "[NOTE]",
+	"💡", "[TIP]",
+	"💻", "[CMD]",
+	"🔗", "[CROSS]",
+	"🔍", "[FIND]",
+	"📍", "[FRAME]",
+	"🌳", "[TREE]",
+	"🔀", "[SHIFT]",
+	"🧩", "[PARTS]",
+	"🎯", "[TARGET]",
+	"👉", "[>]",
+	"🚀", "[NOW]",
+	"🏁", "[BENCH]",
+	"🕐", "[TIME]",
+	"🕘", "[HISTORY]",
+	"⏱️", "[DURATION]",
+	"⏱", "[DURATION]",
+	"⏰", "[TIME]",
+	"🔢", "[SAMPLES]",
+	"📦", "[SIZE]",
+	"🌐", "[SERVE]",
+	"📄", "[FILE]",
+
+	// 默认规则标题
+	"🐘", "[LARGE]",
+	"🧷", "[RETAIN]",
+	"🔁", "[CONV]",
+	"🪞", "[REFLECT]",
+	"🐢", "[STALL]",
+	"🧹", "[GC]",
+	"🆕", "[NEW]",
+	"👥", "[FANOUT]",
+	"📮", "[CHAN]",
+	"♻️", "[CHURN]",
+	"♻", "[CHURN]",
+}
+
+// plainReplacer 按 plainSymbols 替换 emoji
+var plainReplacer = strings.NewReplacer(plainSymbols...)
+
+// PlainText 将文本中的 emoji 替换为 ASCII 符号，用于不支持 emoji 的终端和日志系统
+// 未登记的 emoji (如自定义规则标题中的) 替换为 "[*]"，emoji 变体选择符和连接符被移除
+func PlainText(s string) string {
+	s = plainReplacer.Replace(s)
+	if !hasEmoji(s) {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		switch {
+		case r == '\uFE0F' || r == '\u200D':
+			// 变体选择符和零宽连接符
+		case isEmoji(r):
+			b.WriteString("[*]")
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// hasEmoji 判断文本是否还包含 emoji
+func hasEmoji(s string) bool {
+	for _, r := range s {
+		if r == '\uFE0F' || r == '\u200D' || isEmoji(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmoji 判断字符是否属于 emoji 区段
+// 只覆盖图形符号区段，保留报告中的制表符 (├─)、箭头 (→) 和项目符号 (•)
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF: // 表情、符号和象形文字
+		return true
+	case r >= 0x2600 && r <= 0x27BF: // 杂项符号和装饰符号
+		return true
+	case r >= 0x2B00 && r <= 0x2BFF: // 杂项符号和箭头 (⭐ ⬆)
+		return true
+	case r >= 0x231A && r <= 0x23FF: // 杂项技术符号中的 emoji (⌛ ⏰ ⏱)
+		return true
+	}
+	return false
+}
+
+// plainWriter 替换每次写入中的 emoji
+type plainWriter struct {
+	w io.Writer
+}
+
+// NewPlainWriter 返回写入前替换 emoji 的 Writer
+// 每次 Write 需要包含完整的文本 (如 log 的一行)，跨两次写入的 emoji 不会被替换
+func NewPlainWriter(w io.Writer) io.Writer {
+	return plainWriter{w: w}
+}
+
+// Write 实现 io.Writer，返回值按原始字节数计算
+func (p plainWriter) Write(data []byte) (int, error) {
+	if !utf8.Valid(data) {
+		return p.w.Write(data)
+	}
+	if _, err := io.WriteString(p.w, PlainText(string(data))); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// writePlain 渲染到缓冲区后替换 emoji 再写入 w，保证不会在 emoji 中间截断
+func writePlain(w io.Writer, render func(io.Writer) error) error {
+	var buf bytes.Buffer
+	if err := render(&buf); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, PlainText(buf.String()))
+	return err
+}
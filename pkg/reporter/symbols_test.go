@@ -0,0 +1,87 @@
+package reporter
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlainText(t *testing.T) {
+	assert.Equal(t, "[CRITICAL] 内存泄漏", PlainText("🔥 内存泄漏"))
+	assert.Equal(t, "[!] 样本偏少", PlainText("⚠️ 样本偏少"))
+	assert.Equal(t, "[!] 样本偏少", PlainText("⚠ 样本偏少"))
+	assert.Equal(t, "[DURATION]  持续时间", PlainText("⏱️  持续时间"))
+	assert.Equal(t, "[CPU] [HEAP] [GOROUTINE]", PlainText("⚡ 💾 🔄"))
+	assert.Equal(t, "[i]  已隐藏", PlainText("ℹ️  已隐藏"))
+
+	// 未登记的 emoji 统一替换，变体选择符和连接符被移除
+	assert.Equal(t, "[*] 自定义规则", PlainText("🎉 自定义规则"))
+	assert.Equal(t, "[*]", PlainText("❤️"))
+	assert.Equal(t, "[*][*]", PlainText("👨‍👩"))
+
+	// 制表符、箭头和项目符号保留
+	plain := "     ├─ 时间: 1s → 2s • ↳ …"
+	assert.Equal(t, plain, PlainText(plain))
+}
+
+// TestPlainSymbols_CoverSources 检查源码和默认规则中的每个 emoji 都在 plainSymbols 中登记了 ASCII 替代
+// 新增 emoji 时这个测试会失败，提示在 plainSymbols 中补充
+func TestPlainSymbols_CoverSources(t *testing.T) {
+	var files []string
+	for _, pattern := range []string{"../*/*.go", "../../*.go", "../../assets/*.yaml"} {
+		matches, err := filepath.Glob(pattern)
+		require.NoError(t, err)
+		files = append(files, matches...)
+	}
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") || filepath.Base(file) == "symbols.go" {
+			continue
+		}
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+
+		for i, line := range strings.Split(string(content), "\n") {
+			if strings.HasPrefix(strings.TrimSpace(line), "//") {
+				continue
+			}
+			if replaced := plainReplacer.Replace(line); hasEmoji(replaced) {
+				t.Errorf("%s:%d: emoji without an ASCII symbol in plainSymbols: %s", file, i+1, strings.TrimSpace(line))
+			}
+		}
+	}
+}
+
+// TestGoldenReports_NoEmoji 测试 NoEmoji 时各格式的报告都不含 emoji，且只替换了 emoji
+func TestGoldenReports_NoEmoji(t *testing.T) {
+	opts := goldenOptions()
+	opts.NoEmoji = true
+
+	for _, format := range []string{"text", "html", "dot"} {
+		t.Run(format, func(t *testing.T) {
+			plain := renderGoldenWithOptions(t, format, opts)
+			assert.False(t, hasEmoji(plain), "report still contains emoji")
+			assert.Equal(t, PlainText(renderGolden(t, format)), plain)
+		})
+	}
+
+	text := renderGoldenWithOptions(t, "text", opts)
+	assert.Contains(t, text, "[CRITICAL]")
+	assert.Contains(t, text, "[business]")
+}
+
+func TestNewPlainWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewPlainWriter(&buf)
+
+	n, err := w.Write([]byte("✅ cpu.pprof\n"))
+	require.NoError(t, err)
+	assert.Equal(t, len("✅ cpu.pprof\n"), n)
+	assert.Equal(t, "[OK] cpu.pprof\n", buf.String())
+}
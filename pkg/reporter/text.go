@@ -1,7 +1,11 @@
 package reporter
 
 import (
+	"bufio"
 	"fmt"
+	"io"
+	"math"
+	"os"
 	"path/filepath"
 	"strings"
 	"time"
@@ -18,54 +22,79 @@ func GenerateTextReport(groups []analyzer.ProfileGroup, trends map[string]*analy
 
 // GenerateTextReportWithContext 生成带问题上下文的文本格式分析报告
 func GenerateTextReportWithContext(groups []analyzer.ProfileGroup, trends map[string]*analyzer.GroupTrends, findings []rules.Finding, contexts map[string]*locator.ProblemContext) {
+	GenerateTextReportWithOptions(groups, trends, findings, contexts, DefaultOptions())
+}
+
+// GenerateTextReportWithOptions 使用指定渲染选项生成文本格式分析报告
+func GenerateTextReportWithOptions(groups []analyzer.ProfileGroup, trends map[string]*analyzer.GroupTrends, findings []rules.Finding, contexts map[string]*locator.ProblemContext, opts Options) {
+	WriteTextReport(os.Stdout, &Report{Groups: groups, Trends: trends, Findings: findings, Contexts: contexts, Options: opts})
+}
+
+// WriteTextReport 将文本格式的分析报告写入 w
+func WriteTextReport(w io.Writer, report *Report) error {
+	opts := report.Options
+	if opts.NoEmoji {
+		plain := *report
+		plain.Options.NoEmoji = false
+		return writePlain(w, func(buf io.Writer) error { return WriteTextReport(buf, &plain) })
+	}
+
+	bw := bufio.NewWriter(w)
+	writeTextReport(bw, report.Groups, report.Trends, report.Findings, report.Contexts, opts)
+	return bw.Flush()
+}
+
+// writeTextReport 输出文本报告的各个部分
+func writeTextReport(w io.Writer, groups []analyzer.ProfileGroup, trends map[string]*analyzer.GroupTrends, findings []rules.Finding, contexts map[string]*locator.ProblemContext, opts Options) {
 	if len(groups) == 0 {
-		fmt.Println("📭 没有找到可分析的 profile 文件")
+		fmt.Fprintln(w, "📭 没有找到可分析的 profile 文件")
 		return
 	}
 
-	fmt.Println("\n" + "═══════════════════════════════════════════════════════════")
-	fmt.Println("                    PerfInspector v0.1 分析报告")
-	fmt.Println("═══════════════════════════════════════════════════════════")
+	fmt.Fprintln(w, "\n"+"═══════════════════════════════════════════════════════════")
+	fmt.Fprintf(w, "                    PerfInspector %s 分析报告\n", opts.version())
+	fmt.Fprintln(w, "═══════════════════════════════════════════════════════════")
+
+	printHistory(w, opts.History)
+	printFindingChanges(w, opts.FindingChanges)
 
-	for _, group := range groups {
+	for _, group := range orderGroups(groups, findings, opts.Sort) {
 		if len(group.Files) == 0 {
 			continue
 		}
 
-		fmt.Printf("\n📁 %s 分析 (%d 个文件):\n", group.Type, len(group.Files))
-		fmt.Println("───────────────────────────────────────────────────────────")
+		fmt.Fprintf(w, "\n📁 %s 分析 (%d 个文件, 共 %s 个样本):\n", groupTitle(group), len(group.Files), analyzer.FormatInt(group.TotalSamples()))
+		fmt.Fprintln(w, "───────────────────────────────────────────────────────────")
 
-		for i, file := range group.Files {
-			fmt.Printf("  %d. %s\n", i+1, filepath.Base(file.Path))
-			fmt.Printf("     ├─ 时间: %s\n", file.Time.UTC().Format(time.RFC3339))
-			fmt.Printf("     ├─ 大小: %s\n", formatSize(file.Size))
+		undersampled := analyzer.UndersampledFiles(group.Files, analyzer.DefaultUndersampledRatio)
+		for i, file := range orderFiles(group.Files, opts.Sort) {
+			fmt.Fprintf(w, "  %d. %s\n", i+1, filepath.Base(file.Path))
+			fmt.Fprintf(w, "     ├─ 时间: %s\n", file.Time.UTC().Format(time.RFC3339))
+			fmt.Fprintf(w, "     ├─ 大小: %s\n", formatSize(file.Size))
+			if file.DuplicateOf != "" {
+				fmt.Fprintf(w, "     ├─ %s\n", duplicateNote(file))
+			}
+			if file.Metrics != nil {
+				fmt.Fprintf(w, "     ├─ 样本数: %s%s\n", analyzer.FormatInt(file.Metrics.TotalSamples), undersampledNote(undersampled[file.Path]))
+				if file.Metrics.Bench != nil {
+					fmt.Fprintf(w, "     ├─ 基准测试: %s\n", benchSummary(file.Metrics.Bench, group.Type))
+				}
+			}
 
 			// 显示性能指标
 			if file.Metrics != nil {
-				printMetrics(file.Metrics, group.Type)
+				printMetrics(w, file.Metrics, group.Type, opts)
 			}
 		}
 
 		// 对于 heap profile，显示智能洞察
-		if group.Type == "heap" && len(group.Files) > 0 && group.Files[0].Metrics != nil {
-			insights := analyzer.AnalyzeHeapInsights(group.Files[0].Metrics)
-			if len(insights) > 0 {
-				fmt.Println("\n  💡 关键发现:")
-				fmt.Println("  ───────────────────────────────────────────────────────────")
-				for _, insight := range insights {
-					levelIcon := ""
-					switch insight.Level {
-					case "critical":
-						levelIcon = "🔴"
-					case "warning":
-						levelIcon = "🟡"
-					case "info":
-						levelIcon = "🔵"
-					}
-					fmt.Printf("\n  %s %s\n", levelIcon, insight.Title)
-					fmt.Printf("     %s\n", insight.Description)
-				}
-			}
+		if group.Type == "heap" {
+			printHeapInsights(w, group)
+		}
+
+		// 对于 heap profile，显示按包汇总的内存占用
+		if group.Type == "heap" {
+			printPackageMemory(w, group)
 		}
 
 		// 显示时间范围
@@ -73,18 +102,21 @@ func GenerateTextReportWithContext(groups []analyzer.ProfileGroup, trends map[st
 			first := group.Files[0].Time.UTC()
 			last := group.Files[len(group.Files)-1].Time.UTC()
 			duration := last.Sub(first)
-			fmt.Printf("\n  📊 时间范围: %s → %s\n",
+			fmt.Fprintf(w, "\n  📊 时间范围: %s → %s\n",
 				first.Format("2006-01-02 15:04:05"),
 				last.Format("2006-01-02 15:04:05"))
-			fmt.Printf("  ⏱️  持续时间: %s\n", formatDuration(duration))
+			fmt.Fprintf(w, "  ⏱️  持续时间: %s\n", formatDuration(duration))
 		}
 
-		// 显示趋势（仅 R² > 0.7）
+		// 显示趋势（仅 R² 超过展示阈值）
 		if groupTrends, ok := trends[group.Type]; ok && groupTrends != nil {
-			printTrends(groupTrends)
+			printTrends(w, groupTrends, opts.TrendThresholds)
 		}
 	}
 
+	// 按规模上限截断发现
+	findings, omittedFindings := opts.Limits.Findings(findings)
+
 	// 分离单类型发现和联合分析发现
 	var singleFindings, crossFindings []rules.Finding
 	for _, f := range findings {
@@ -97,9 +129,9 @@ func GenerateTextReportWithContext(groups []analyzer.ProfileGroup, trends map[st
 
 	// 显示单类型规则发现
 	if len(singleFindings) > 0 {
-		fmt.Println("\n═══════════════════════════════════════════════════════════")
-		fmt.Println("                        🔍 规则发现")
-		fmt.Println("═══════════════════════════════════════════════════════════")
+		fmt.Fprintln(w, "\n═══════════════════════════════════════════════════════════")
+		fmt.Fprintln(w, "                        🔍 规则发现")
+		fmt.Fprintln(w, "═══════════════════════════════════════════════════════════")
 
 		for i, finding := range singleFindings {
 			// 查找对应的 ProblemContext
@@ -107,15 +139,15 @@ func GenerateTextReportWithContext(groups []analyzer.ProfileGroup, trends map[st
 			if contexts != nil {
 				ctx = contexts[finding.RuleID]
 			}
-			printFindingWithContext(i+1, finding, ctx)
+			printFindingWithLimits(w, i+1, finding, ctx, opts.Limits)
 		}
 	}
 
 	// 显示联合分析发现
 	if len(crossFindings) > 0 {
-		fmt.Println("\n═══════════════════════════════════════════════════════════")
-		fmt.Println("                     🔗 联合分析发现")
-		fmt.Println("═══════════════════════════════════════════════════════════")
+		fmt.Fprintln(w, "\n═══════════════════════════════════════════════════════════")
+		fmt.Fprintln(w, "                     🔗 联合分析发现")
+		fmt.Fprintln(w, "═══════════════════════════════════════════════════════════")
 
 		for i, finding := range crossFindings {
 			// 查找对应的 ProblemContext
@@ -123,93 +155,349 @@ func GenerateTextReportWithContext(groups []analyzer.ProfileGroup, trends map[st
 			if contexts != nil {
 				ctx = contexts[finding.RuleID]
 			}
-			printFindingWithContext(i+1, finding, ctx)
+			printFindingWithLimits(w, i+1, finding, ctx, opts.Limits)
 		}
 	}
 
-	fmt.Println("\n═══════════════════════════════════════════════════════════")
+	if omittedFindings > 0 {
+		fmt.Fprintf(w, "\n   ... %s\n", truncatedNote(omittedFindings))
+	}
+
+	fmt.Fprintln(w, "\n═══════════════════════════════════════════════════════════")
 }
 
 // printFinding 打印单个发现（向后兼容）
-func printFinding(index int, finding rules.Finding) {
-	printFindingWithContext(index, finding, nil)
+func printFinding(w io.Writer, index int, finding rules.Finding) {
+	printFindingWithContext(w, index, finding, nil)
+}
+
+// printFindingWithContext 打印单个发现，包含问题上下文（不限制规模）
+func printFindingWithContext(w io.Writer, index int, finding rules.Finding, ctx *locator.ProblemContext) {
+	printFindingWithLimits(w, index, finding, ctx, Limits{})
 }
 
-// printFindingWithContext 打印单个发现，包含问题上下文
-func printFindingWithContext(index int, finding rules.Finding, ctx *locator.ProblemContext) {
+// printFindingWithLimits 打印单个发现，热点路径和调用链按规模上限截断
+func printFindingWithLimits(w io.Writer, index int, finding rules.Finding, ctx *locator.ProblemContext, limits Limits) {
 	severityIcon := getSeverityIcon(finding.Severity)
-	fmt.Printf("\n%d. %s %s\n", index, severityIcon, finding.Title)
-	fmt.Printf("   规则: %s (%s)\n", finding.RuleName, finding.RuleID)
-	fmt.Printf("   严重程度: %s\n", finding.Severity)
+	fmt.Fprintf(w, "\n%d. %s %s\n", index, severityIcon, finding.Title)
+	fmt.Fprintf(w, "   规则: %s (%s)\n", finding.RuleName, finding.RuleID)
+	fmt.Fprintf(w, "   严重程度: %s\n", finding.Severity)
+	if finding.File != "" {
+		fmt.Fprintf(w, "   文件: %s\n", finding.File)
+	}
 
 	// 如果有 ProblemContext，显示增强信息
 	if ctx != nil {
+		// 显示推荐优先执行的命令
+		printPrimaryCommand(w, ctx.PrimaryCommand)
+
 		// 显示问题解释
 		if ctx.Explanation != "" {
-			fmt.Println("\n   📝 问题解释:")
-			printWrappedText(ctx.Explanation, "      ", 70)
+			fmt.Fprintln(w, "\n   📝 问题解释:")
+			printWrappedText(w, ctx.Explanation, "      ", 70)
 		}
 
 		// 显示影响评估
 		if ctx.Impact != "" {
-			fmt.Println("\n   📊 影响评估:")
-			fmt.Printf("      %s\n", ctx.Impact)
+			fmt.Fprintln(w, "\n   📊 影响评估:")
+			fmt.Fprintf(w, "      %s\n", ctx.Impact)
 		}
 
 		// 显示热点路径
 		if len(ctx.HotPaths) > 0 {
-			printHotPaths(ctx.HotPaths)
+			printHotPathsWithLimits(w, ctx.HotPaths, limits)
 		}
+		printHiddenHotPaths(w, ctx.HiddenHotPaths)
+
+		// 显示存活内存归属
+		printOwnershipWithLimits(w, ctx.Ownership, limits)
+
+		// 显示前后窗口的热点迁移
+		printWindowShift(w, ctx.WindowShift)
 
 		// 显示可执行命令
 		if len(ctx.Commands) > 0 {
-			printCommands(ctx.Commands)
+			printCommands(w, ctx.Commands)
 		}
 
 		// 显示建议和代码示例
 		if len(ctx.Suggestions) > 0 {
-			printSuggestions(ctx.Suggestions)
+			printSuggestions(w, ctx.Suggestions)
 		}
 	} else {
 		// 没有 ProblemContext 时，使用原有的显示方式
 		if len(finding.Evidence) > 0 {
-			fmt.Println("   证据:")
-			for key, value := range finding.Evidence {
-				fmt.Printf("     - %s: %s\n", key, value)
+			fmt.Fprintln(w, "   证据:")
+			for _, item := range finding.Evidence {
+				fmt.Fprintf(w, "     - %s: %s\n", item.Name, item.Display)
 			}
 		}
 
 		if len(finding.Suggestions) > 0 {
-			fmt.Println("   建议:")
+			fmt.Fprintln(w, "   建议:")
 			for _, suggestion := range finding.Suggestions {
-				fmt.Printf("     • %s\n", suggestion)
+				fmt.Fprintf(w, "     • %s\n", suggestion)
 			}
 		}
 	}
 }
 
-// printTrends 打印趋势信息（仅 R² > 0.7）
-func printTrends(trends *analyzer.GroupTrends) {
+// printPackageMemory 打印最新 heap 快照按包汇总的内存占用
+func printPackageMemory(w io.Writer, group analyzer.ProfileGroup) {
+	file, packages := latestPackageMemory(group)
+	if len(packages) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "\n  📦 包内存占用 Top %d (%s):\n", len(packages), filepath.Base(file.Path))
+	fmt.Fprintln(w, "  ───────────────────────────────────────────────────────────")
+	table := [][]string{{"包", "分类", "累计分配", "使用中"}}
+	for _, pkg := range packages {
+		table = append(table, []string{
+			pkg.Package,
+			packageCategoryLabel(pkg.Category),
+			fmt.Sprintf("%s (%.1f%%)", analyzer.FormatBytes(pkg.AllocSpace), pkg.AllocPct),
+			fmt.Sprintf("%s (%.1f%%)", analyzer.FormatBytes(pkg.InuseSpace), pkg.InusePct),
+		})
+	}
+	writeAlignedTable(w, table, 2, 3)
+}
+
+// latestPackageMemory 返回组内最新的带有包内存汇总的快照
+func latestPackageMemory(group analyzer.ProfileGroup) (analyzer.ProfileFile, []analyzer.PackageMemory) {
+	for i := len(group.Files) - 1; i >= 0; i-- {
+		if m := group.Files[i].Metrics; m != nil && len(m.PackageMemory) > 0 {
+			return group.Files[i], m.PackageMemory
+		}
+	}
+	return analyzer.ProfileFile{}, nil
+}
+
+// packageCategoryLabel 返回包分类的展示名，未分类时返回 "-"
+func packageCategoryLabel(category string) string {
+	if category == "" {
+		return "-"
+	}
+	return locator.CodeCategory(category).String()
+}
+
+// printTrends 打印趋势信息（仅 R² 超过展示阈值，或排除离群快照后超过阈值）
+func printTrends(w io.Writer, trends *analyzer.GroupTrends, thresholds analyzer.TrendThresholds) {
 	printed := false
 
-	if trends.HeapInuse != nil && trends.HeapInuse.R2 > 0.7 {
+	if heapTrend := trends.SelectedHeapTrend(); showTrend(thresholds, analyzer.MetricHeapInuse, heapTrend) {
 		if !printed {
-			fmt.Println("\n  📈 趋势分析:")
+			fmt.Fprintln(w, "\n  📈 趋势分析:")
 			printed = true
 		}
-		dirIcon := getDirectionIcon(trends.HeapInuse.Direction)
-		fmt.Printf("     %s 堆内存: 斜率=%.2f, R²=%.2f (%s)\n",
-			dirIcon, trends.HeapInuse.Slope, trends.HeapInuse.R2, trends.HeapInuse.Direction)
+		dirIcon := getDirectionIcon(heapTrend.Direction)
+		fmt.Fprintf(w, "     %s %s: 斜率=%.2f, R²=%.2f%s (%s)\n",
+			dirIcon, annotatedHeapTrendLabel(trends), heapTrend.Slope, heapTrend.R2, trendPoints(heapTrend), heapTrend.Direction)
+		printTrendStats(w, heapTrend, heapTrendValue(trends.HeapSampleType))
+		printTrendOutliers(w, heapTrend)
 	}
 
-	if trends.GoroutineCount != nil && trends.GoroutineCount.R2 > 0.7 {
+	// 对象数增长时单独展示：小对象泄漏时空间趋势可能平稳
+	if objects := trends.HeapInuseObjects; trends.HeapSampleType != analyzer.HeapSampleInuseObjects &&
+		showTrend(thresholds, analyzer.MetricHeapInuseObjects, objects) && objects.Direction == "increasing" {
 		if !printed {
-			fmt.Println("\n  📈 趋势分析:")
+			fmt.Fprintln(w, "\n  📈 趋势分析:")
+			printed = true
+		}
+		fmt.Fprintf(w, "     %s 堆对象数 (inuse_objects): 斜率=%.2f, R²=%.2f%s (%s)\n",
+			getDirectionIcon(objects.Direction), objects.Slope, objects.R2, trendPoints(objects), objects.Direction)
+		printTrendStats(w, objects, countTrendValue)
+		printTrendOutliers(w, objects)
+	}
+
+	if showTrend(thresholds, analyzer.MetricGoroutineCount, trends.GoroutineCount) {
+		if !printed {
+			fmt.Fprintln(w, "\n  📈 趋势分析:")
 			printed = true
 		}
 		dirIcon := getDirectionIcon(trends.GoroutineCount.Direction)
-		fmt.Printf("     %s Goroutine: 斜率=%.2f, R²=%.2f (%s)\n",
-			dirIcon, trends.GoroutineCount.Slope, trends.GoroutineCount.R2, trends.GoroutineCount.Direction)
+		fmt.Fprintf(w, "     %s %s: 斜率=%.2f, R²=%.2f%s (%s)\n",
+			dirIcon, goroutineTrendLabel(trends), trends.GoroutineCount.Slope, trends.GoroutineCount.R2, trendPoints(trends.GoroutineCount), trends.GoroutineCount.Direction)
+		printTrendStats(w, trends.GoroutineCount, countTrendValue)
+		printTrendOutliers(w, trends.GoroutineCount)
+	}
+}
+
+// printTrendStats 打印趋势序列的峰值、均值和波动
+func printTrendStats(w io.Writer, trend *analyzer.TrendMetrics, format func(float64) string) {
+	if stats := trendStats(trend, format); stats != "" {
+		fmt.Fprintf(w, "        📊 峰值 / 均值 / 波动: %s\n", stats)
+	}
+}
+
+// trendStats 返回趋势序列的 "峰值 / 均值 / 波动"，波动为标准差及其占均值的比例
+// 斜率相同的序列，波动大说明是剧烈振荡而不是平稳增长；数据点少于 2 个时返回空字符串
+func trendStats(trend *analyzer.TrendMetrics, format func(float64) string) string {
+	if trend == nil || trend.Points < 2 {
+		return ""
+	}
+	stats := fmt.Sprintf("%s / %s / ±%s", format(trend.Max), format(trend.Mean), format(trend.StdDev))
+	if trend.Mean != 0 {
+		stats += fmt.Sprintf(" (%.1f%%)", trend.StdDev/math.Abs(trend.Mean)*100)
+	}
+	return stats
+}
+
+// heapTrendValue 返回 heap 趋势序列值的格式化函数，对象数按整数、内存按字节格式化
+func heapTrendValue(heapSampleType string) func(float64) string {
+	return func(value float64) string { return heapChartLabel(int64(math.Round(value)), heapSampleType) }
+}
+
+// countTrendValue 格式化 goroutine 数、对象数等计数序列的值
+func countTrendValue(value float64) string {
+	return analyzer.FormatInt(int64(math.Round(value)))
+}
+
+// showTrend 判断趋势是否展示：R² 达到阈值，或离群快照拉低了 R² 而排除后达到阈值
+func showTrend(thresholds analyzer.TrendThresholds, metric string, trend *analyzer.TrendMetrics) bool {
+	if thresholds.IsSignificant(metric, trend) {
+		return true
+	}
+	return trend != nil && len(trend.Outliers) > 0 && thresholds.IsSignificant(metric, trend.WithoutOutliers)
+}
+
+// trendPoints 返回趋势的数据点数标注，如 ", N=5"，数据点数未知时为空
+func trendPoints(trend *analyzer.TrendMetrics) string {
+	if trend.Points <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(", N=%d", trend.Points)
+}
+
+// printTrendOutliers 打印离群快照和排除它们后的拟合结果，以及疑似在 GC 极值处采集的快照
+func printTrendOutliers(w io.Writer, trend *analyzer.TrendMetrics) {
+	printGCPhase(w, trend)
+	if len(trend.Outliers) == 0 {
+		return
+	}
+	for _, outlier := range trend.Outliers {
+		fmt.Fprintf(w, "        ⚠️  %s 的快照是离群点: 值=%.2f, 预期=%.2f\n",
+			outlierTime(outlier), outlier.Value, outlier.Expected)
+	}
+	if without := trend.WithoutOutliers; without != nil {
+		fmt.Fprintf(w, "        ↳ 排除离群快照后: 斜率=%.2f, R²=%.2f%s (%s)\n", without.Slope, without.R2, trendPoints(without), without.Direction)
+	}
+}
+
+// printGCPhase 打印疑似在 GC 前后采集的 heap 快照，它们已在拟合中降权
+func printGCPhase(w io.Writer, trend *analyzer.TrendMetrics) {
+	if len(trend.GCPhase) == 0 {
+		return
+	}
+	for _, skew := range trend.GCPhase {
+		fmt.Fprintf(w, "        🧹 %s\n", gcPhaseSkewNote(skew))
+	}
+	if trend.GCPhaseSensitive {
+		fmt.Fprintf(w, "        ⚠️  %s\n", gcPhaseSensitiveNote)
+	}
+}
+
+// gcPhaseSensitiveNote 序列对 GC 阶段敏感时的提示
+const gcPhaseSensitiveNote = "该序列对 GC 阶段敏感，趋势可能只是 GC 锯齿；建议采集前先调用 runtime.GC()，或使用 inuse 的多次采集中位数"
+
+// gcPhaseSkewNote 返回单个偏斜快照的说明，如 "#4 的快照疑似在 GC 刚结束时采集: 值=60.00, 比相邻快照低 50%，已降权"
+func gcPhaseSkewNote(skew analyzer.GCPhaseSkew) string {
+	phase, direction := "GC 刚结束时", "低"
+	if !skew.Low {
+		phase, direction = "GC 之前", "高"
+	}
+	return fmt.Sprintf("%s 的快照疑似在 %s采集: 值=%.2f, 比相邻快照%s %.0f%%，已降权",
+		outlierTime(analyzer.TrendOutlier{Index: skew.Index, Time: skew.Time}), phase, skew.Value, direction, math.Abs(skew.Deviation())*100)
+}
+
+// outlierTime 返回离群快照的时间，未知时使用序号
+func outlierTime(outlier analyzer.TrendOutlier) string {
+	if outlier.Time.IsZero() {
+		return fmt.Sprintf("#%d", outlier.Index+1)
+	}
+	return outlier.Time.UTC().Format(time.RFC3339)
+}
+
+// heapTrendLabel 返回 heap 趋势的展示名，非默认 sample type 时附带类型
+func heapTrendLabel(trends *analyzer.GroupTrends) string {
+	if trends.HeapSampleType == "" || trends.HeapSampleType == analyzer.HeapSampleInuseSpace {
+		return "堆内存"
+	}
+	return fmt.Sprintf("堆内存 (%s)", trends.HeapSampleType)
+}
+
+// heapSawtoothNote heap 存活内存呈 GC 锯齿时的标注
+const heapSawtoothNote = "正常 GC 波动"
+
+// groupTitle 返回分组标题，profile 来自 /debug/pprof/allocs 的 heap 组标注 allocs
+func groupTitle(group analyzer.ProfileGroup) string {
+	if group.Allocs {
+		return group.Type + " (allocs)"
+	}
+	return group.Type
+}
+
+// isHeapSawtooth 判断报告展示的 heap 趋势是否为 GC 锯齿，只有 inuse_space 检测锯齿
+func isHeapSawtooth(trends *analyzer.GroupTrends) bool {
+	return trends.HeapSawtooth && (trends.HeapSampleType == "" || trends.HeapSampleType == analyzer.HeapSampleInuseSpace)
+}
+
+// annotatedHeapTrendLabel 返回带 GC 锯齿标注的 heap 趋势展示名，避免把锯齿误读为持续增长
+func annotatedHeapTrendLabel(trends *analyzer.GroupTrends) string {
+	if isHeapSawtooth(trends) {
+		return fmt.Sprintf("%s (%s)", heapTrendLabel(trends), heapSawtoothNote)
+	}
+	return heapTrendLabel(trends)
+}
+
+// goroutineTrendLabel 返回 goroutine 趋势的展示名，整体增长时附带增长形态
+// 阶跃后趋于平稳的序列线性拟合仍是 increasing，标出平台期避免误判为泄漏
+func goroutineTrendLabel(trends *analyzer.GroupTrends) string {
+	switch trends.GoroutineGrowthKind {
+	case analyzer.GoroutineGrowthSustained:
+		return "Goroutine (持续增长)"
+	case analyzer.GoroutineGrowthPlateau:
+		return "Goroutine (阶跃后趋于平稳)"
+	default:
+		return "Goroutine"
+	}
+}
+
+// goroutineStatePct 返回某个状态的 goroutine 占总数的百分比，总数未知时为 0
+func goroutineStatePct(count int, total int64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return float64(count) / float64(total) * 100
+}
+
+// printHeapInsights 打印 heap 分组的关键发现，与 HTML 报告一样基于分组的第一个快照
+func printHeapInsights(w io.Writer, group analyzer.ProfileGroup) {
+	if len(group.Files) == 0 || group.Files[0].Metrics == nil {
+		return
+	}
+	insights := analyzer.AnalyzeHeapInsights(group.Files[0].Metrics)
+	if len(insights) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w, "\n  💡 关键发现:")
+	fmt.Fprintln(w, "  ───────────────────────────────────────────────────────────")
+	for _, insight := range insights {
+		fmt.Fprintf(w, "\n  %s %s\n", getInsightLevelIcon(insight.Level), insight.Title)
+		fmt.Fprintf(w, "     %s\n", insight.Description)
+	}
+}
+
+// getInsightLevelIcon 获取洞察级别图标，文本和 HTML 报告共用
+func getInsightLevelIcon(level string) string {
+	switch level {
+	case "critical":
+		return "🔴"
+	case "warning":
+		return "🟡"
+	default:
+		return "🔵"
 	}
 }
 
@@ -273,87 +561,209 @@ func formatDuration(d time.Duration) string {
 }
 
 // printMetrics 打印性能指标
-func printMetrics(m *analyzer.ProfileMetrics, profileType string) {
+func printMetrics(w io.Writer, m *analyzer.ProfileMetrics, profileType string, opts Options) {
 	switch profileType {
 	case "cpu":
 		if m.CPUTime > 0 {
-			fmt.Printf("     ├─ CPU时间: %v\n", m.CPUTime)
+			fmt.Fprintf(w, "     ├─ CPU时间: %v\n", m.CPUTime)
 		}
 		if m.Duration > 0 {
-			fmt.Printf("     ├─ 采样时长: %v\n", m.Duration)
-		}
-		fmt.Printf("     ├─ 样本数: %d\n", m.TotalSamples)
-		if len(m.TopFunctions) > 0 {
-			fmt.Println("     ├─ Top 热点函数:")
-			for i, fn := range m.TopFunctions {
-				if i >= 5 {
-					break
+			fmt.Fprintf(w, "     ├─ 采样时长: %v\n", m.Duration)
+		}
+		if m.NoDuration {
+			fmt.Fprintf(w, "     ├─ ⚠️ %s\n", noDurationNote)
+		}
+		if functions, omitted := opts.Limits.Functions(m.TopFunctions, profileType); len(functions) > 0 {
+			fmt.Fprintln(w, "     ├─ Top 热点函数 (flat% / cum%):")
+			for i, fn := range functions {
+				// 缺少采样时长时不展示绝对 CPU 时间
+				if m.NoDuration {
+					fmt.Fprintf(w, "     │  %d. %s (%.1f%% / %.1f%%)\n", i+1, truncateName(opts.displayName(fn.Name), 50), fn.FlatPct, fn.CumPct)
+					continue
 				}
-				fmt.Printf("     │  %d. %s (%.1f%%)\n", i+1, truncateName(fn.Name, 50), fn.FlatPct)
+				fmt.Fprintf(w, "     │  %d. %s (%.1f%% / %.1f%%, %s)\n", i+1, truncateName(opts.displayName(fn.Name), 45), fn.FlatPct, fn.CumPct, locator.FormatValue(fn.Flat, "nanoseconds"))
 			}
+			printOmittedFunctions(w, omitted)
 		}
-		fmt.Println("     └─")
+		if len(m.TopPackages) > 0 {
+			fmt.Fprintln(w, "     ├─ Top 包 (flat%):")
+			for i, pkg := range m.TopPackages {
+				if m.NoDuration {
+					fmt.Fprintf(w, "     │  %d. %s (%.1f%%)\n", i+1, truncateName(pkg.Package, 50), pkg.FlatPct)
+					continue
+				}
+				fmt.Fprintf(w, "     │  %d. %s (%.1f%%, %s)\n", i+1, truncateName(pkg.Package, 45), pkg.FlatPct, locator.FormatValue(pkg.Flat, "nanoseconds"))
+			}
+		}
+		fmt.Fprintln(w, "     └─")
 
 	case "heap":
-		fmt.Printf("     ├─ 已分配: %s (%s 对象)\n", analyzer.FormatBytes(m.AllocSpace), analyzer.FormatInt(m.AllocObjects))
-		fmt.Printf("     ├─ 使用中: %s (%s 对象)\n", analyzer.FormatBytes(m.InuseSpace), analyzer.FormatInt(m.InuseObjects))
+		fmt.Fprintf(w, "     ├─ 已分配: %s (%s 对象)\n", analyzer.FormatBytes(m.AllocSpace), analyzer.FormatInt(m.AllocObjects))
+		fmt.Fprintf(w, "     ├─ 使用中: %s (%s 对象)\n", analyzer.FormatBytes(m.InuseSpace), analyzer.FormatInt(m.InuseObjects))
 
 		// 计算内存回收率
 		if m.AllocSpace > 0 {
 			gcRate := float64(m.AllocSpace-m.InuseSpace) / float64(m.AllocSpace) * 100
-			fmt.Printf("     ├─ GC回收率: %.1f%%\n", gcRate)
+			fmt.Fprintf(w, "     ├─ GC回收率: %.1f%%\n", gcRate)
 		}
 
-		if len(m.TopFunctions) > 0 {
-			fmt.Println("     ├─ Top 当前内存占用 (inuse_space):")
-			count := 0
-			for _, fn := range m.TopFunctions {
-				if count >= 5 {
-					break
-				}
-				// 跳过 flat 为 0 的函数（它们只在调用栈中间）
-				if fn.Flat == 0 {
-					continue
-				}
-				count++
-				fmt.Printf("     │  %d. %s (%.1f%%, %s)\n", count, truncateName(fn.Name, 45), fn.FlatPct, analyzer.FormatBytes(fn.Flat))
+		// Functions 会跳过 flat 为 0 的函数（它们只在调用栈中间）
+		if functions, omitted := opts.Limits.Functions(m.TopFunctions, profileType); len(functions) > 0 {
+			fmt.Fprintln(w, "     ├─ Top 当前内存占用 (inuse_space):")
+			for i, fn := range functions {
+				fmt.Fprintf(w, "     │  %d. %s (%.1f%%, %s)\n", i+1, truncateName(opts.displayName(fn.Name), 45), fn.FlatPct, analyzer.FormatBytes(fn.Flat))
 			}
+			printOmittedFunctions(w, omitted)
 		}
 
-		if len(m.TopAllocFunctions) > 0 {
-			fmt.Println("     ├─ Top 累计内存分配 (alloc_space):")
-			count := 0
-			for _, fn := range m.TopAllocFunctions {
-				if count >= 5 {
-					break
-				}
-				// 跳过 flat 为 0 的函数
-				if fn.Flat == 0 {
-					continue
-				}
-				count++
-				fmt.Printf("     │  %d. %s (%.1f%%, %s)\n", count, truncateName(fn.Name, 45), fn.FlatPct, analyzer.FormatBytes(fn.Flat))
+		if functions, omitted := opts.Limits.Functions(m.TopAllocFunctions, profileType); len(functions) > 0 {
+			fmt.Fprintln(w, "     ├─ Top 累计内存分配 (alloc_space):")
+			for i, fn := range functions {
+				fmt.Fprintf(w, "     │  %d. %s (%.1f%%, %s)\n", i+1, truncateName(opts.displayName(fn.Name), 45), fn.FlatPct, analyzer.FormatBytes(fn.Flat))
 			}
+			printOmittedFunctions(w, omitted)
 		}
-		fmt.Println("     └─")
+		fmt.Fprintln(w, "     └─")
 
 	case "goroutine":
-		fmt.Printf("     ├─ Goroutine数: %d\n", m.GoroutineCount)
-		if len(m.TopFunctions) > 0 {
-			fmt.Println("     ├─ Top 调用路径:")
-			for i, fn := range m.TopFunctions {
-				if i >= 5 {
-					break
+		fmt.Fprintf(w, "     ├─ Goroutine数: %d\n", m.GoroutineCount)
+		if states := analyzer.SortedGoroutineStates(m.GoroutineStates); len(states) > 0 {
+			fmt.Fprintln(w, "     ├─ 状态分布:")
+			for _, state := range states {
+				fmt.Fprintf(w, "     │  %s: %d (%.1f%%)\n", state.State, state.Count, goroutineStatePct(state.Count, m.GoroutineCount))
+			}
+		}
+		if functions, omitted := opts.Limits.Functions(m.TopFunctions, profileType); len(functions) > 0 {
+			fmt.Fprintln(w, "     ├─ Top 调用路径:")
+			for i, fn := range functions {
+				fmt.Fprintf(w, "     │  %d. %s (%d, %.1f%%)\n", i+1, truncateName(opts.displayName(fn.Name), 50), fn.Cum, fn.CumPct)
+			}
+			printOmittedFunctions(w, omitted)
+		}
+		fmt.Fprintln(w, "     └─")
+
+	case "threadcreate":
+		fmt.Fprintf(w, "     ├─ 线程数: %s\n", analyzer.FormatInt(m.ThreadCount))
+		if functions, omitted := opts.Limits.Functions(m.TopFunctions, profileType); len(functions) > 0 {
+			fmt.Fprintln(w, "     ├─ Top 线程创建调用点:")
+			for i, fn := range functions {
+				fmt.Fprintf(w, "     │  %d. %s (%d, %.1f%%)\n", i+1, truncateName(opts.displayName(fn.Name), 50), fn.Cum, fn.CumPct)
+			}
+			printOmittedFunctions(w, omitted)
+		}
+		fmt.Fprintln(w, "     └─")
+
+	case "block":
+		fmt.Fprintf(w, "     ├─ 阻塞次数: %s\n", analyzer.FormatInt(m.Contentions))
+		fmt.Fprintf(w, "     ├─ 阻塞时间: %s\n", locator.FormatValue(int64(m.BlockDelay), "nanoseconds"))
+		if functions, omitted := opts.Limits.Functions(m.TopFunctions, profileType); len(functions) > 0 {
+			fmt.Fprintln(w, "     ├─ Top 阻塞调用路径:")
+			for i, fn := range functions {
+				fmt.Fprintf(w, "     │  %d. %s (%.1f%%, %s)\n", i+1, truncateName(opts.displayName(fn.Name), 45), fn.CumPct, locator.FormatValue(fn.Cum, "nanoseconds"))
+			}
+			printOmittedFunctions(w, omitted)
+		}
+		fmt.Fprintln(w, "     └─")
+
+	default:
+		fmt.Fprintf(w, "     ├─ 函数数: %d\n", m.NumFunctions)
+		if m.ValueType != "" {
+			fmt.Fprintf(w, "     ├─ 样本值 (%s): %s\n", m.ValueType, formatSampleValue(m.TotalValue, m.ValueUnit))
+			if functions, omitted := opts.Limits.Functions(m.TopFunctions, profileType); len(functions) > 0 {
+				fmt.Fprintf(w, "     ├─ Top 函数 (%s):\n", m.ValueType)
+				for i, fn := range functions {
+					fmt.Fprintf(w, "     │  %d. %s (%s, %.1f%%)\n", i+1, truncateName(opts.displayName(fn.Name), 45), formatSampleValue(fn.Cum, m.ValueUnit), fn.CumPct)
 				}
-				fmt.Printf("     │  %d. %s (%d, %.1f%%)\n", i+1, truncateName(fn.Name, 50), fn.Cum, fn.CumPct)
+				printOmittedFunctions(w, omitted)
 			}
 		}
-		fmt.Println("     └─")
+		fmt.Fprintln(w, "     └─")
+	}
+}
+
+// printHistory 在报告开头打印与上一次运行的关键指标对比
+func printHistory(w io.Writer, history *HistoryComparison) {
+	if history == nil {
+		return
+	}
+	fmt.Fprintf(w, "\n🕘 %s:\n", history.Header())
+	for _, delta := range history.Deltas {
+		fmt.Fprintf(w, "  • %s\n", delta)
+	}
+}
+
+// printFindingChanges 在报告开头打印发现相对上一次运行的变化，持平的发现只计数不逐条列出
+func printFindingChanges(w io.Writer, changes *FindingComparison) {
+	if changes == nil {
+		return
+	}
+	fmt.Fprintf(w, "\n📋 发现变化: %s\n", changes.Summary())
+	for _, change := range changes.Changes {
+		switch change.Status {
+		case FindingNew:
+			fmt.Fprintf(w, "  🆕 %s\n", change)
+		case FindingWorse:
+			fmt.Fprintf(w, "  📈 %s\n", change)
+		case FindingResolved:
+			fmt.Fprintf(w, "  ✅ %s\n", change)
+		}
+	}
+}
 
+// benchSummary 返回基准测试模式下每次操作的消耗，格式与 go test -bench 输出一致
+func benchSummary(b *analyzer.BenchStats, profileType string) string {
+	filtered := fmt.Sprintf("已过滤 %s 个 testing 框架样本", analyzer.FormatInt(b.HarnessSamples))
+	if b.N <= 0 {
+		return "未提供迭代次数，无法换算为每次操作 (" + filtered + ")"
+	}
+
+	var perOp string
+	switch profileType {
+	case "cpu":
+		if b.NoDuration {
+			return "缺少采样时长，无法换算 ns/op (" + filtered + ")"
+		}
+		perOp = formatPerOp(b.NsPerOp) + " ns/op"
+	case "heap":
+		perOp = formatPerOp(b.BytesPerOp) + " B/op  " + formatPerOp(b.AllocsPerOp) + " allocs/op"
 	default:
-		fmt.Printf("     ├─ 样本数: %d\n", m.TotalSamples)
-		fmt.Printf("     ├─ 函数数: %d\n", m.NumFunctions)
-		fmt.Println("     └─")
+		return filtered
+	}
+	return fmt.Sprintf("%s (N=%s，%s)", perOp, analyzer.FormatInt(b.N), filtered)
+}
+
+// formatPerOp 格式化每次操作的数值，较大时取整，较小时保留小数
+func formatPerOp(value float64) string {
+	switch {
+	case value >= 100:
+		return fmt.Sprintf("%.0f", value)
+	case value >= 10:
+		return fmt.Sprintf("%.1f", value)
+	default:
+		return fmt.Sprintf("%.2f", value)
+	}
+}
+
+// noDurationNote 缺少采集时长的 CPU profile 的提示
+const noDurationNote = "缺少采样时长 (duration 为 0)，不展示绝对 CPU 时间，百分比仍然有效"
+
+// undersampledNote 返回采样不足快照的提示后缀
+func undersampledNote(undersampled bool) string {
+	if !undersampled {
+		return ""
+	}
+	return " ⚠️ 样本偏少 (低于组内中位数的一半)"
+}
+
+// duplicateNote 返回重复快照的说明
+func duplicateNote(file analyzer.ProfileFile) string {
+	return fmt.Sprintf("重复快照: 指标与 %s 相同，不参与趋势计算", filepath.Base(file.DuplicateOf))
+}
+
+// printOmittedFunctions 打印 Top 函数列表的截断提示
+func printOmittedFunctions(w io.Writer, omitted int) {
+	if omitted > 0 {
+		fmt.Fprintf(w, "     │  ... %s\n", truncatedNote(omitted))
 	}
 }
 
@@ -365,25 +775,139 @@ func truncateName(name string, maxLen int) string {
 	return "..." + name[len(name)-maxLen+3:]
 }
 
-// printHotPaths 打印热点路径列表
-func printHotPaths(hotPaths []locator.HotPath) {
-	fmt.Println("\n   🔥 热点调用链:")
+// printHiddenHotPaths 打印被 -hide-runtime-only 排除的热点路径汇总
+func printHiddenHotPaths(w io.Writer, hidden locator.HiddenHotPaths) {
+	if hidden.Count == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n   ℹ️  %s\n", hiddenHotPathsNote(hidden))
+}
+
+// hiddenHotPathsNote 返回被排除热点路径的说明
+func hiddenHotPathsNote(hidden locator.HiddenHotPaths) string {
+	pct := fmt.Sprintf("%.1f%%", hidden.Pct)
+	if value := hidden.Unit.Format(hidden.Value); value != "" {
+		pct += " (" + value + ")"
+	}
+	return fmt.Sprintf("已隐藏 %d 条没有业务代码的热点路径，合计占 %s，多为运行时/GC、标准库等开销", hidden.Count, pct)
+}
+
+// formatPctValue 返回百分比和对应的绝对值，如 "45.0%, 13.2s"；value 为空 (单位未知) 时只返回百分比
+func formatPctValue(pct float64, value string) string {
+	if value == "" {
+		return fmt.Sprintf("%.1f%%", pct)
+	}
+	return fmt.Sprintf("%.1f%%, %s", pct, value)
+}
+
+// printWindowShift 打印前后两个时间窗口间占比明显变化的热点路径
+func printWindowShift(w io.Writer, shift *locator.WindowComparison) {
+	if shift.Empty() {
+		return
+	}
+	fmt.Fprintf(w, "\n   🔀 %s:\n", windowShiftHeader(shift))
+	for _, s := range shift.Grew {
+		printPathShift(w, "↑", s)
+	}
+	for _, s := range shift.Shrank {
+		printPathShift(w, "↓", s)
+	}
+}
+
+// printPathShift 打印一条迁移的路径
+func printPathShift(w io.Writer, arrow string, s locator.PathShift) {
+	frame := s.Frame()
+	fmt.Fprintf(w, "      %s %s %s  %.1f%% → %.1f%% (%+.1f 个百分点)", arrow, frame.Category.Icon(), frame.DisplayName(), s.BeforePct, s.AfterPct, s.DeltaPct)
+	if location := frame.Location(); location != "unknown" {
+		fmt.Fprintf(w, "  %s", location)
+	}
+	fmt.Fprintln(w)
+}
+
+// windowShiftHeader 返回热点迁移的标题，说明两个窗口的快照数和切分时间
+func windowShiftHeader(shift *locator.WindowComparison) string {
+	header := fmt.Sprintf("热点迁移 (前 %d 个快照 → 后 %d 个快照", shift.Before, shift.After)
+	if !shift.Pivot.IsZero() {
+		header += "，切分于 " + shift.Pivot.UTC().Format("15:04:05")
+	}
+	return header + ")"
+}
+
+// printOwnershipWithLimits 打印存活内存归属树，每层节点按规模上限截断
+func printOwnershipWithLimits(w io.Writer, tree *locator.OwnershipTree, limits Limits) {
+	if tree == nil || len(tree.Owners) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n   🌳 存活内存归属 (%s, 共 %s):\n", tree.SampleType, analyzer.FormatBytes(tree.Total))
+	printOwnershipNodes(w, tree.Owners, "      ", 0, limits)
+}
+
+// printOwnershipNodes 递归打印归属树的一层节点
+// 第一层为负责的业务包，中间为业务函数，叶子为执行分配的包
+func printOwnershipNodes(w io.Writer, nodes []locator.OwnershipNode, prefix string, depth int, limits Limits) {
+	nodes, omitted := limits.OwnershipNodes(nodes)
+	for i, node := range nodes {
+		last := i == len(nodes)-1 && omitted == 0
+		branch, childPrefix := "├─ ", prefix+"│  "
+		if last {
+			branch, childPrefix = "└─ ", prefix+"   "
+		}
+
+		name := node.Name
+		switch {
+		case len(node.Children) == 0:
+			name = "分配于 " + node.Category.Icon() + " " + name
+		case depth == 0:
+			name = node.Category.Icon() + " " + name
+		}
+		fmt.Fprintf(w, "%s%s%s  %s (%.1f%%)", prefix, branch, name, analyzer.FormatBytes(node.Value), node.Pct)
+		if node.Location != "" && node.Location != "unknown" {
+			fmt.Fprintf(w, "  %s", node.Location)
+		}
+		fmt.Fprintln(w)
+
+		printOwnershipNodes(w, node.Children, childPrefix, depth+1, limits)
+	}
+	if omitted > 0 {
+		fmt.Fprintf(w, "%s└─ … %s\n", prefix, truncatedNote(omitted))
+	}
+}
+
+// printHotPaths 打印热点路径列表（不限制规模）
+func printHotPaths(w io.Writer, hotPaths []locator.HotPath) {
+	printHotPathsWithLimits(w, hotPaths, Limits{})
+}
+
+// printHotPathsWithLimits 打印热点路径列表，超出上限的路径和栈帧以截断提示代替
+func printHotPathsWithLimits(w io.Writer, hotPaths []locator.HotPath, limits Limits) {
+	hotPaths, omittedPaths := limits.HotPaths(hotPaths)
+
+	fmt.Fprintln(w, "\n   🔥 热点调用链:")
 	for i, hp := range hotPaths {
-		fmt.Printf("\n   ─── 热点 #%d (%.1f%%) ───\n", i+1, hp.Chain.TotalPct)
+		fmt.Fprintf(w, "\n   ─── 热点 #%d (%s) ───\n", i+1, formatPctValue(hp.Chain.TotalPct, hp.FormatValue()))
 
 		// 打印类别分布摘要
-		printCategorySummary(hp.Chain)
+		printCategorySummary(w, hp.Chain)
 
 		// 打印调用链
-		printCallChain(hp)
+		var omittedFrames int
+		hp.Chain.Frames, omittedFrames = limits.Frames(hp.Chain.Frames)
+		printCallChain(w, hp)
+		if omittedFrames > 0 {
+			fmt.Fprintf(w, "      ... %s\n", truncatedNote(omittedFrames))
+		}
+	}
+
+	if omittedPaths > 0 {
+		fmt.Fprintf(w, "\n   ... %s\n", truncatedNote(omittedPaths))
 	}
 }
 
 // printCallChain 打印带分类标记的调用链
-func printCallChain(hp locator.HotPath) {
+func printCallChain(w io.Writer, hp locator.HotPath) {
 	frames := hp.Chain.Frames
 	if len(frames) == 0 {
-		fmt.Println("      (空调用链)")
+		fmt.Fprintln(w, "      (空调用链)")
 		return
 	}
 
@@ -397,7 +921,7 @@ func printCallChain(hp locator.HotPath) {
 	for i, frame := range frames {
 		// 检查是否需要打印类别分隔线
 		if i > 0 && frame.Category != lastCategory {
-			fmt.Println("      ─────────────────────────────")
+			fmt.Fprintln(w, "      ─────────────────────────────")
 		}
 
 		// 获取类别图标
@@ -414,15 +938,20 @@ func printCallChain(hp locator.HotPath) {
 		}
 
 		// 打印栈帧
-		fmt.Printf("      %s [%s] %s%s\n", icon, frame.Category.String(), frame.ShortName, highlight)
-		fmt.Printf("             └─ %s\n", frame.Location())
+		// 匿名函数展示外层函数，括号中保留原始短名便于对照 pprof 输出
+		name := frame.Label()
+		if frame.ClosureLabel != "" {
+			name += " (" + frame.ShortName + ")"
+		}
+		fmt.Fprintf(w, "      %s [%s] %s%s\n", icon, frame.Category.String(), name, highlight)
+		fmt.Fprintf(w, "             └─ %s\n", frame.Location())
 
 		lastCategory = frame.Category
 	}
 
 	// 如果没有业务代码，显示提示
 	if !hp.Chain.HasBusinessCode() {
-		fmt.Println("\n      ⚠️  该路径中没有业务代码 - 可能是运行时/GC 问题或间接调用")
+		fmt.Fprintln(w, "\n      ⚠️  该路径中没有业务代码 - 可能是运行时/GC 问题或间接调用")
 	}
 }
 
@@ -432,31 +961,44 @@ func getCategoryIcon(category locator.CodeCategory) string {
 }
 
 // printCategorySummary 打印类别分布摘要
-func printCategorySummary(chain locator.CallChain) {
+func printCategorySummary(w io.Writer, chain locator.CallChain) {
 	summary := chain.Summary()
 	if summary != "" {
-		fmt.Printf("      调用链: %s\n", summary)
+		fmt.Fprintf(w, "      调用链: %s\n", summary)
+	}
+	if n := chain.UnsymbolizedFrames(); n > 0 {
+		fmt.Fprintf(w, "      ⚠️  %d 个栈帧缺少符号，分析结果不完整\n", n)
+	}
+}
+
+// printPrimaryCommand 打印推荐最先执行的命令
+func printPrimaryCommand(w io.Writer, cmd *locator.ExecutableCmd) {
+	if cmd == nil {
+		return
 	}
+	fmt.Fprintln(w, "\n   👉 从这里开始:")
+	fmt.Fprintf(w, "      $ %s\n", cmd.Command)
+	fmt.Fprintf(w, "      %s\n", cmd.Description)
 }
 
 // printCommands 打印可执行命令
-func printCommands(commands []locator.ExecutableCmd) {
+func printCommands(w io.Writer, commands []locator.ExecutableCmd) {
 	if len(commands) == 0 {
 		return
 	}
 
-	fmt.Println("\n   💻 调试命令:")
+	fmt.Fprintln(w, "\n   💻 调试命令:")
 	for i, cmd := range commands {
-		fmt.Printf("\n      %d. %s\n", i+1, cmd.Description)
-		fmt.Printf("         $ %s\n", cmd.Command)
+		fmt.Fprintf(w, "\n      %d. %s\n", i+1, cmd.Description)
+		fmt.Fprintf(w, "         $ %s\n", cmd.Command)
 		if cmd.OutputHint != "" {
-			fmt.Printf("         说明: %s\n", cmd.OutputHint)
+			fmt.Fprintf(w, "         说明: %s\n", cmd.OutputHint)
 		}
 	}
 }
 
 // printSuggestions 打印分类建议
-func printSuggestions(suggestions []locator.Suggestion) {
+func printSuggestions(w io.Writer, suggestions []locator.Suggestion) {
 	if len(suggestions) == 0 {
 		return
 	}
@@ -471,38 +1013,38 @@ func printSuggestions(suggestions []locator.Suggestion) {
 		}
 	}
 
-	fmt.Println("\n   💡 建议:")
+	fmt.Fprintln(w, "\n   💡 建议:")
 
 	if len(immediate) > 0 {
-		fmt.Println("      [立即]")
+		fmt.Fprintln(w, "      [立即]")
 		for _, s := range immediate {
-			fmt.Printf("        • %s\n", s.Content)
+			fmt.Fprintf(w, "        • %s\n", s.Content)
 		}
 	}
 
 	if len(longTerm) > 0 {
-		fmt.Println("      [长期]")
+		fmt.Fprintln(w, "      [长期]")
 		for _, s := range longTerm {
-			fmt.Printf("        • %s\n", s.Content)
+			fmt.Fprintf(w, "        • %s\n", s.Content)
 		}
 	}
 }
 
 // printWrappedText 打印自动换行的文本
-func printWrappedText(text string, prefix string, maxWidth int) {
+func printWrappedText(w io.Writer, text string, prefix string, maxWidth int) {
 	// 按换行符分割
 	paragraphs := strings.Split(text, "\n")
 
 	for _, para := range paragraphs {
 		if para == "" {
-			fmt.Println()
+			fmt.Fprintln(w)
 			continue
 		}
 
 		// 简单的单词换行
 		words := strings.Fields(para)
 		if len(words) == 0 {
-			fmt.Println(prefix)
+			fmt.Fprintln(w, prefix)
 			continue
 		}
 
@@ -512,7 +1054,7 @@ func printWrappedText(text string, prefix string, maxWidth int) {
 		for _, word := range words {
 			wordLen := len(word)
 			if lineLen+wordLen+1 > maxWidth && lineLen > len(prefix) {
-				fmt.Println(line)
+				fmt.Fprintln(w, line)
 				line = prefix + word
 				lineLen = len(prefix) + wordLen
 			} else {
@@ -526,7 +1068,7 @@ func printWrappedText(text string, prefix string, maxWidth int) {
 		}
 
 		if lineLen > len(prefix) {
-			fmt.Println(line)
+			fmt.Fprintln(w, line)
 		}
 	}
 }
@@ -6,10 +6,14 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/pprof/profile"
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
 	"github.com/songzhibin97/perfinspector/pkg/locator"
 	"github.com/songzhibin97/perfinspector/pkg/rules"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // captureOutput 捕获标准输出
@@ -104,7 +108,7 @@ func TestPrintCallChain_WithBusinessFrames(t *testing.T) {
 	}
 
 	output := captureOutput(func() {
-		printCallChain(hp)
+		printCallChain(os.Stdout, hp)
 	})
 
 	// 验证业务帧被标记
@@ -166,7 +170,7 @@ func TestPrintCallChain_NoBusinessCode(t *testing.T) {
 	}
 
 	output := captureOutput(func() {
-		printCallChain(hp)
+		printCallChain(os.Stdout, hp)
 	})
 
 	// 验证显示无业务代码提示
@@ -174,6 +178,25 @@ func TestPrintCallChain_NoBusinessCode(t *testing.T) {
 	assert.Contains(t, output, "运行时/GC 问题")
 }
 
+// TestPrintCallChain_Closure 匿名函数帧展示外层函数，括号中保留原始短名
+func TestPrintCallChain_Closure(t *testing.T) {
+	hp := locator.HotPath{
+		Chain: locator.CallChain{Frames: []locator.StackFrame{
+			{FunctionName: "main.main", ShortName: "main", Category: locator.CategoryBusiness},
+			{FunctionName: "main.init.0.func1.1", ShortName: "init.0.func1.1", ClosureLabel: "init#0()内的闭包#1.1", Category: locator.CategoryBusiness},
+		}},
+		BusinessFrames: []int{0, 1},
+		RootCauseIndex: 1,
+	}
+
+	output := captureOutput(func() {
+		printCallChain(os.Stdout, hp)
+	})
+
+	assert.Contains(t, output, "[业务] main ← 关注")
+	assert.Contains(t, output, "[业务] init#0()内的闭包#1.1 (init.0.func1.1) ← 根因")
+}
+
 // TestPrintCallChain_EmptyChain 测试空调用链
 func TestPrintCallChain_EmptyChain(t *testing.T) {
 	hp := locator.HotPath{
@@ -185,7 +208,7 @@ func TestPrintCallChain_EmptyChain(t *testing.T) {
 	}
 
 	output := captureOutput(func() {
-		printCallChain(hp)
+		printCallChain(os.Stdout, hp)
 	})
 
 	assert.Contains(t, output, "空调用链")
@@ -206,7 +229,7 @@ func TestPrintCategorySummary(t *testing.T) {
 	}
 
 	output := captureOutput(func() {
-		printCategorySummary(chain)
+		printCategorySummary(os.Stdout, chain)
 	})
 
 	// 验证摘要格式
@@ -217,6 +240,30 @@ func TestPrintCategorySummary(t *testing.T) {
 	assert.Contains(t, output, "→")
 }
 
+// TestPrintCategorySummary_Unsymbolized 测试缺少符号的栈帧提示
+func TestPrintCategorySummary_Unsymbolized(t *testing.T) {
+	chain := locator.CallChain{
+		Frames: []locator.StackFrame{
+			{Category: locator.CategoryBusiness},
+			{Category: locator.CategoryMissingSymbol, ShortName: "<unknown 0x4a3f20>"},
+			{Category: locator.CategoryMissingSymbol, ShortName: "<unknown>"},
+			{Category: locator.CategoryRuntime},
+		},
+	}
+
+	output := captureOutput(func() {
+		printCategorySummary(os.Stdout, chain)
+	})
+	assert.Contains(t, output, "1 业务 → 2 缺少符号 → 1 运行时")
+	assert.Contains(t, output, "2 个栈帧缺少符号，分析结果不完整")
+
+	chain.Frames = chain.Frames[:1]
+	output = captureOutput(func() {
+		printCategorySummary(os.Stdout, chain)
+	})
+	assert.NotContains(t, output, "缺少符号")
+}
+
 // TestPrintCommands 测试命令输出
 // **Validates: Requirements 7.6**
 func TestPrintCommands(t *testing.T) {
@@ -234,7 +281,7 @@ func TestPrintCommands(t *testing.T) {
 	}
 
 	output := captureOutput(func() {
-		printCommands(commands)
+		printCommands(os.Stdout, commands)
 	})
 
 	// 验证命令标题
@@ -262,7 +309,7 @@ func TestPrintSuggestions(t *testing.T) {
 	}
 
 	output := captureOutput(func() {
-		printSuggestions(suggestions)
+		printSuggestions(os.Stdout, suggestions)
 	})
 
 	// 验证建议标题
@@ -324,7 +371,7 @@ func TestPrintHotPaths(t *testing.T) {
 	}
 
 	output := captureOutput(func() {
-		printHotPaths(hotPaths)
+		printHotPaths(os.Stdout, hotPaths)
 	})
 
 	// 验证热点标题
@@ -386,7 +433,7 @@ func TestPrintFindingWithContext(t *testing.T) {
 	}
 
 	output := captureOutput(func() {
-		printFindingWithContext(1, finding, ctx)
+		printFindingWithContext(os.Stdout, 1, finding, ctx)
 	})
 
 	// 验证基本信息
@@ -420,9 +467,9 @@ func TestPrintFindingWithoutContext(t *testing.T) {
 		RuleName: "CPU Hotspot Detection",
 		Severity: "medium",
 		Title:    "CPU 热点检测",
-		Evidence: map[string]string{
-			"function": "main.processData",
-			"cpu_pct":  "35.5%",
+		Evidence: rules.Evidence{
+			{Name: "function", Display: "main.processData"},
+			{Name: "cpu_pct", Display: "35.5%", Value: 0.355, HasValue: true, Unit: "ratio"},
 		},
 		Suggestions: []string{
 			"优化算法复杂度",
@@ -431,7 +478,7 @@ func TestPrintFindingWithoutContext(t *testing.T) {
 	}
 
 	output := captureOutput(func() {
-		printFindingWithContext(1, finding, nil)
+		printFindingWithContext(os.Stdout, 1, finding, nil)
 	})
 
 	// 验证基本信息
@@ -443,6 +490,8 @@ func TestPrintFindingWithoutContext(t *testing.T) {
 	assert.Contains(t, output, "证据")
 	assert.Contains(t, output, "function")
 	assert.Contains(t, output, "main.processData")
+	// 证据按规则中的声明顺序展示，不按名称排序
+	assert.Less(t, strings.Index(output, "- function: main.processData"), strings.Index(output, "- cpu_pct: 35.5%"))
 
 	// 验证建议（旧格式）
 	assert.Contains(t, output, "建议")
@@ -450,12 +499,27 @@ func TestPrintFindingWithoutContext(t *testing.T) {
 	assert.Contains(t, output, "考虑使用缓存")
 }
 
+// TestPrintFinding_File 测试单文件规则的发现展示触发的文件
+func TestPrintFinding_File(t *testing.T) {
+	finding := rules.Finding{RuleID: "single_cpu_hotspot", RuleName: "单个快照中的主导函数", Severity: "medium", Title: "单个 CPU 快照被一个函数主导"}
+	output := captureOutput(func() {
+		printFindingWithContext(os.Stdout, 1, finding, nil)
+	})
+	assert.NotContains(t, output, "文件:")
+
+	finding.File = "/profiles/cpu2.pprof"
+	output = captureOutput(func() {
+		printFindingWithContext(os.Stdout, 1, finding, nil)
+	})
+	assert.Contains(t, output, "   文件: /profiles/cpu2.pprof\n")
+}
+
 // TestPrintWrappedText 测试文本换行
 func TestPrintWrappedText(t *testing.T) {
 	longText := "这是一段很长的文本，用于测试自动换行功能。它应该在达到指定宽度时自动换行，以保持输出的可读性。"
 
 	output := captureOutput(func() {
-		printWrappedText(longText, "   ", 40)
+		printWrappedText(os.Stdout, longText, "   ", 40)
 	})
 
 	// 验证输出包含前缀
@@ -471,10 +535,527 @@ func TestPrintWrappedText_WithNewlines(t *testing.T) {
 	text := "第一段内容。\n\n第二段内容。"
 
 	output := captureOutput(func() {
-		printWrappedText(text, "   ", 70)
+		printWrappedText(os.Stdout, text, "   ", 70)
 	})
 
 	// 验证两段都存在
 	assert.Contains(t, output, "第一段")
 	assert.Contains(t, output, "第二段")
 }
+
+// TestPrintTrends_Outliers 测试离群快照的展示
+func TestPrintTrends_Outliers(t *testing.T) {
+	thresholds := analyzer.DefaultTrendThresholds()
+	snapshot := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("noisy trend shown when clean without outlier", func(t *testing.T) {
+		trends := &analyzer.GroupTrends{
+			GoroutineCount: &analyzer.TrendMetrics{
+				Slope:     30,
+				R2:        0.3,
+				Direction: "increasing",
+				Outliers: []analyzer.TrendOutlier{
+					{Index: 2, Time: snapshot, Value: 900, Expected: 120},
+				},
+				WithoutOutliers: &analyzer.TrendMetrics{Slope: 10, R2: 0.99, Direction: "increasing"},
+			},
+		}
+
+		output := captureOutput(func() { printTrends(os.Stdout, trends, thresholds) })
+		assert.Contains(t, output, "Goroutine: 斜率=30.00, R²=0.30")
+		assert.Contains(t, output, "2024-01-01T10:00:00Z 的快照是离群点: 值=900.00, 预期=120.00")
+		assert.Contains(t, output, "排除离群快照后: 斜率=10.00, R²=0.99 (increasing)")
+	})
+
+	t.Run("noisy trend without outliers hidden", func(t *testing.T) {
+		trends := &analyzer.GroupTrends{
+			GoroutineCount: &analyzer.TrendMetrics{Slope: 30, R2: 0.3, Direction: "increasing"},
+		}
+
+		output := captureOutput(func() { printTrends(os.Stdout, trends, thresholds) })
+		assert.Empty(t, output)
+	})
+}
+
+// TestShowTrend 测试趋势是否展示的判断
+func TestShowTrend(t *testing.T) {
+	thresholds := analyzer.DefaultTrendThresholds()
+	metric := analyzer.MetricGoroutineCount
+
+	assert.False(t, showTrend(thresholds, metric, nil))
+	assert.True(t, showTrend(thresholds, metric, &analyzer.TrendMetrics{Slope: 10, R2: 0.9}))
+	assert.False(t, showTrend(thresholds, metric, &analyzer.TrendMetrics{Slope: 10, R2: 0.3}))
+	assert.False(t, showTrend(thresholds, metric, &analyzer.TrendMetrics{
+		Slope:           10,
+		R2:              0.3,
+		Outliers:        []analyzer.TrendOutlier{{Index: 1}},
+		WithoutOutliers: &analyzer.TrendMetrics{Slope: 10, R2: 0.4},
+	}), "refit that is still noisy should stay hidden")
+	assert.True(t, showTrend(thresholds, metric, &analyzer.TrendMetrics{
+		Slope:           10,
+		R2:              0.3,
+		Outliers:        []analyzer.TrendOutlier{{Index: 1}},
+		WithoutOutliers: &analyzer.TrendMetrics{Slope: 10, R2: 0.95},
+	}))
+}
+
+// TestOutlierTime 测试离群快照时间的展示
+func TestOutlierTime(t *testing.T) {
+	assert.Equal(t, "#3", outlierTime(analyzer.TrendOutlier{Index: 2}))
+	ts := time.Date(2024, 1, 1, 10, 0, 0, 0, time.FixedZone("CST", 8*3600))
+	assert.Equal(t, "2024-01-01T02:00:00Z", outlierTime(analyzer.TrendOutlier{Index: 2, Time: ts}))
+}
+
+// TestPrintFindingWithContext_HiddenHotPaths 测试被隐藏的纯运行时热点路径汇总
+func TestPrintFindingWithContext_HiddenHotPaths(t *testing.T) {
+	finding := rules.Finding{RuleID: "cpu_hotspot", RuleName: "CPU Hotspot", Severity: "medium", Title: "CPU 热点"}
+
+	ctx := &locator.ProblemContext{
+		Title:          "CPU 热点",
+		Severity:       "medium",
+		HiddenHotPaths: locator.HiddenHotPaths{Count: 2, Value: 420, Pct: 42.04},
+	}
+	output := captureOutput(func() { printFindingWithContext(os.Stdout, 1, finding, ctx) })
+	assert.Contains(t, output, "已隐藏 2 条没有业务代码的热点路径，合计占 42.0%")
+	assert.NotContains(t, output, "热点调用链")
+
+	ctx.HiddenHotPaths = locator.HiddenHotPaths{}
+	output = captureOutput(func() { printFindingWithContext(os.Stdout, 1, finding, ctx) })
+	assert.NotContains(t, output, "已隐藏")
+}
+
+func TestPrintFindingWithContext_WindowShift(t *testing.T) {
+	finding := rules.Finding{RuleID: "cpu_hotspot", RuleName: "CPU Hotspot", Severity: "medium", Title: "CPU 热点"}
+
+	ctx := &locator.ProblemContext{
+		Title:    "CPU 热点",
+		Severity: "medium",
+		WindowShift: &locator.WindowComparison{
+			Before: 2,
+			After:  3,
+			Grew: []locator.PathShift{{
+				Chain: locator.CallChain{Frames: []locator.StackFrame{
+					{FunctionName: "github.com/myapp/reports.Build", ShortName: "Build", Category: locator.CategoryBusiness},
+					{FunctionName: "runtime.mallocgc", ShortName: "mallocgc", Category: locator.CategoryRuntime},
+				}},
+				BeforePct: 10, AfterPct: 45.5, DeltaPct: 35.5,
+			}},
+		},
+	}
+	output := captureOutput(func() { printFindingWithContext(os.Stdout, 1, finding, ctx) })
+	// 没有切分时间时不显示，代表帧取最深的业务帧
+	assert.Contains(t, output, "热点迁移 (前 2 个快照 → 后 3 个快照)")
+	assert.Contains(t, output, "↑ 💼 Build  10.0% → 45.5% (+35.5 个百分点)")
+
+	ctx.WindowShift = &locator.WindowComparison{Before: 2, After: 3}
+	output = captureOutput(func() { printFindingWithContext(os.Stdout, 1, finding, ctx) })
+	assert.NotContains(t, output, "热点迁移")
+}
+
+func TestBenchSummary(t *testing.T) {
+	cpu := &analyzer.BenchStats{N: 200000, HarnessSamples: 3, NsPerOp: 6012.4}
+	assert.Equal(t, "6012 ns/op (N=200,000，已过滤 3 个 testing 框架样本)", benchSummary(cpu, "cpu"))
+
+	heap := &analyzer.BenchStats{N: 1000, BytesPerOp: 96, AllocsPerOp: 2}
+	assert.Equal(t, "96.0 B/op  2.00 allocs/op (N=1,000，已过滤 0 个 testing 框架样本)", benchSummary(heap, "heap"))
+
+	// 迭代次数未知时只说明过滤结果
+	assert.Contains(t, benchSummary(&analyzer.BenchStats{HarnessSamples: 5}, "cpu"), "未提供迭代次数")
+	assert.Equal(t, "已过滤 5 个 testing 框架样本", benchSummary(&analyzer.BenchStats{N: 10, HarnessSamples: 5}, "goroutine"))
+
+	// 缺少采样时长时无法换算 ns/op
+	assert.Equal(t, "缺少采样时长，无法换算 ns/op (已过滤 0 个 testing 框架样本)", benchSummary(&analyzer.BenchStats{N: 10, NoDuration: true}, "cpu"))
+}
+
+func TestPrintTrends_HeapObjects(t *testing.T) {
+	trends := &analyzer.GroupTrends{
+		HeapInuse:        &analyzer.TrendMetrics{Slope: 0, R2: 0.95, Direction: "stable"},
+		HeapInuseObjects: &analyzer.TrendMetrics{Slope: 5000, R2: 0.98, Direction: "increasing"},
+		HeapSampleType:   analyzer.HeapSampleInuseSpace,
+	}
+	output := captureOutput(func() { printTrends(os.Stdout, trends, analyzer.DefaultTrendThresholds()) })
+	assert.Contains(t, output, "📈 堆对象数 (inuse_objects): 斜率=5000.00, R²=0.98 (increasing)")
+
+	// 已选择 inuse_objects 作为 heap 趋势时不重复展示
+	trends.HeapSampleType = analyzer.HeapSampleInuseObjects
+	trends.HeapTrends = map[string]*analyzer.TrendMetrics{analyzer.HeapSampleInuseObjects: trends.HeapInuseObjects}
+	output = captureOutput(func() { printTrends(os.Stdout, trends, analyzer.DefaultTrendThresholds()) })
+	assert.NotContains(t, output, "堆对象数")
+
+	// 对象数平稳时不展示
+	trends.HeapSampleType = analyzer.HeapSampleInuseSpace
+	trends.HeapInuseObjects = &analyzer.TrendMetrics{R2: 1, Direction: "stable"}
+	output = captureOutput(func() { printTrends(os.Stdout, trends, analyzer.DefaultTrendThresholds()) })
+	assert.NotContains(t, output, "堆对象数")
+}
+
+func TestPrintMetrics_NoDuration(t *testing.T) {
+	m := &analyzer.ProfileMetrics{NoDuration: true, TopFunctions: []analyzer.FunctionStat{{Name: "main.work", Flat: 3, FlatPct: 75, CumPct: 75}}}
+	output := captureOutput(func() { printMetrics(os.Stdout, m, "cpu", DefaultOptions()) })
+	assert.Contains(t, output, "⚠️ 缺少采样时长 (duration 为 0)，不展示绝对 CPU 时间，百分比仍然有效")
+	assert.Contains(t, output, "main.work (75.0% / 75.0%)")
+	assert.NotContains(t, output, "CPU时间")
+
+	m = &analyzer.ProfileMetrics{CPUTime: time.Second, Duration: 10 * time.Second}
+	output = captureOutput(func() { printMetrics(os.Stdout, m, "cpu", DefaultOptions()) })
+	assert.Contains(t, output, "CPU时间: 1s")
+	assert.NotContains(t, output, "缺少采样时长")
+}
+
+func TestPrintMetrics_ValueType(t *testing.T) {
+	m := &analyzer.ProfileMetrics{
+		NumFunctions: 3,
+		TotalValue:   1000,
+		ValueType:    "revenue",
+		ValueUnit:    "cents",
+		TopFunctions: []analyzer.FunctionStat{{Name: "github.com/myapp/shop.Refund", Flat: 900, Cum: 900, CumPct: 90}},
+	}
+	output := captureOutput(func() { printMetrics(os.Stdout, m, "unknown", DefaultOptions()) })
+	assert.Contains(t, output, "样本值 (revenue): 1,000")
+	assert.Contains(t, output, "Top 函数 (revenue):")
+	assert.Contains(t, output, "1. github.com/myapp/shop.Refund (900, 90.0%)")
+
+	// 未指定 -value-type 时只展示函数数
+	output = captureOutput(func() {
+		printMetrics(os.Stdout, &analyzer.ProfileMetrics{NumFunctions: 3}, "unknown", DefaultOptions())
+	})
+	assert.Contains(t, output, "函数数: 3")
+	assert.NotContains(t, output, "样本值")
+}
+
+func TestPrintTrends_Points(t *testing.T) {
+	trends := &analyzer.GroupTrends{
+		GoroutineCount: &analyzer.TrendMetrics{Slope: 5, R2: 0.95, Direction: "increasing", Points: 6},
+	}
+	output := captureOutput(func() { printTrends(os.Stdout, trends, analyzer.DefaultTrendThresholds()) })
+	assert.Contains(t, output, "📈 Goroutine: 斜率=5.00, R²=0.95, N=6 (increasing)")
+
+	// 数据点数未知时不标注
+	trends.GoroutineCount.Points = 0
+	output = captureOutput(func() { printTrends(os.Stdout, trends, analyzer.DefaultTrendThresholds()) })
+	assert.Contains(t, output, "📈 Goroutine: 斜率=5.00, R²=0.95 (increasing)")
+}
+
+// TestPrintTrends_GoroutineGrowthKind 测试 goroutine 趋势标注增长形态
+func TestPrintTrends_GoroutineGrowthKind(t *testing.T) {
+	trends := &analyzer.GroupTrends{
+		GoroutineCount:      &analyzer.TrendMetrics{Slope: 80, R2: 0.75, Direction: "increasing", Points: 6},
+		GoroutineGrowthKind: analyzer.GoroutineGrowthPlateau,
+	}
+	output := captureOutput(func() { printTrends(os.Stdout, trends, analyzer.DefaultTrendThresholds()) })
+	assert.Contains(t, output, "📈 Goroutine (阶跃后趋于平稳): 斜率=80.00, R²=0.75, N=6 (increasing)")
+
+	trends.GoroutineGrowthKind = analyzer.GoroutineGrowthSustained
+	output = captureOutput(func() { printTrends(os.Stdout, trends, analyzer.DefaultTrendThresholds()) })
+	assert.Contains(t, output, "📈 Goroutine (持续增长): ")
+}
+
+// TestPrintMetrics_GoroutineStates 测试 goroutine 指标按数量列出状态分布
+func TestPrintMetrics_GoroutineStates(t *testing.T) {
+	m := &analyzer.ProfileMetrics{
+		GoroutineCount:  100,
+		GoroutineStates: map[string]int{"select": 15, "chan receive": 80, "running": 5},
+	}
+	output := captureOutput(func() { printMetrics(os.Stdout, m, "goroutine", DefaultOptions()) })
+	assert.Contains(t, output, "状态分布:\n     │  chan receive: 80 (80.0%)\n     │  select: 15 (15.0%)\n     │  running: 5 (5.0%)\n")
+
+	// 没有状态信息时不输出
+	m.GoroutineStates = nil
+	output = captureOutput(func() { printMetrics(os.Stdout, m, "goroutine", DefaultOptions()) })
+	assert.NotContains(t, output, "状态分布")
+}
+
+// TestPrintTrends_HeapSawtooth 测试 heap 趋势为 GC 锯齿时标注正常波动
+func TestPrintTrends_HeapSawtooth(t *testing.T) {
+	trend := &analyzer.TrendMetrics{Slope: 20, R2: 0.9, Direction: "stable", Points: 8}
+	trends := &analyzer.GroupTrends{
+		HeapInuse:      trend,
+		HeapTrends:     map[string]*analyzer.TrendMetrics{analyzer.HeapSampleInuseSpace: trend},
+		HeapSampleType: analyzer.HeapSampleInuseSpace,
+		HeapSawtooth:   true,
+	}
+	output := captureOutput(func() { printTrends(os.Stdout, trends, analyzer.DefaultTrendThresholds()) })
+	assert.Contains(t, output, "➡️ 堆内存 (正常 GC 波动): 斜率=20.00, R²=0.90, N=8 (stable)")
+
+	// 只有 inuse_space 检测锯齿，展示其他 sample type 时不标注
+	trends.HeapSampleType = analyzer.HeapSampleAllocSpace
+	trends.HeapTrends[analyzer.HeapSampleAllocSpace] = trend
+	output = captureOutput(func() { printTrends(os.Stdout, trends, analyzer.DefaultTrendThresholds()) })
+	assert.NotContains(t, output, "正常 GC 波动")
+}
+
+// TestPrintHotPaths_AbsoluteValue 测试热点路径标题在百分比旁展示绝对值
+func TestPrintHotPaths_AbsoluteValue(t *testing.T) {
+	unit := locator.ValueUnit{Unit: "bytes", Scale: 1, Total: 1 << 30}
+	hotPaths := []locator.HotPath{
+		{
+			Chain: locator.CallChain{
+				Frames:     []locator.StackFrame{{FunctionName: "main.load", ShortName: "load", Category: locator.CategoryBusiness}},
+				TotalValue: 412 << 20,
+				TotalPct:   40.2,
+			},
+			RootCauseIndex: 0,
+			ProfileType:    "heap",
+			Unit:           unit,
+		},
+		{
+			Chain: locator.CallChain{
+				Frames:     []locator.StackFrame{{FunctionName: "main.save", ShortName: "save", Category: locator.CategoryBusiness}},
+				TotalValue: 100,
+				TotalPct:   10,
+			},
+			RootCauseIndex: 0,
+			ProfileType:    "heap",
+		},
+	}
+
+	output := captureOutput(func() { printHotPaths(os.Stdout, hotPaths) })
+	assert.Contains(t, output, "热点 #1 (40.2%, 412 MB)")
+	assert.Contains(t, output, "热点 #2 (10.0%)", "unknown unit shows only the percentage")
+
+	hidden := locator.HiddenHotPaths{Count: 2, Value: 64 << 20, Pct: 6.25, Unit: unit}
+	assert.Contains(t, hiddenHotPathsNote(hidden), "合计占 6.2% (64.00 MB)")
+}
+
+// TestPrintMetrics_CPUAbsoluteTime 测试 CPU 热点函数在百分比旁展示 CPU 时间
+func TestPrintMetrics_CPUAbsoluteTime(t *testing.T) {
+	m := &analyzer.ProfileMetrics{
+		CPUTime:      29 * time.Second,
+		Duration:     30 * time.Second,
+		TopFunctions: []analyzer.FunctionStat{{Name: "main.work", Flat: int64(13200 * time.Millisecond), FlatPct: 45.5, CumPct: 60}},
+	}
+	output := captureOutput(func() { printMetrics(os.Stdout, m, "cpu", DefaultOptions()) })
+	assert.Contains(t, output, "1. main.work (45.5% / 60.0%, 13.2s)")
+}
+
+// newCPUWrapperProfile 创建包装函数 main.handler 调用 main.encode 的 CPU profile：
+// handler 自身消耗 100ms，encode 消耗 900ms，其中一个样本中 handler 递归出现两次
+func newCPUWrapperProfile() *profile.Profile {
+	handler := &profile.Function{ID: 1, Name: "main.handler"}
+	encode := &profile.Function{ID: 2, Name: "main.encode"}
+	handlerLoc := &profile.Location{ID: 1, Line: []profile.Line{{Function: handler}}}
+	encodeLoc := &profile.Location{ID: 2, Line: []profile.Line{{Function: encode}}}
+	return &profile.Profile{
+		SampleType:    []*profile.ValueType{{Type: "samples", Unit: "count"}, {Type: "cpu", Unit: "nanoseconds"}},
+		DurationNanos: int64(time.Second),
+		Sample: []*profile.Sample{
+			{Location: []*profile.Location{encodeLoc, handlerLoc, handlerLoc}, Value: []int64{90, int64(900 * time.Millisecond)}},
+			{Location: []*profile.Location{handlerLoc}, Value: []int64{10, int64(100 * time.Millisecond)}},
+		},
+	}
+}
+
+// findFunctionStat 按函数名查找函数统计，找不到时返回 nil
+func findFunctionStat(stats []analyzer.FunctionStat, name string) *analyzer.FunctionStat {
+	for i := range stats {
+		if stats[i].Name == name {
+			return &stats[i]
+		}
+	}
+	return nil
+}
+
+// TestPrintMetrics_CPUFlatAndCum 包装函数自身消耗低但累计消耗高，flat% 和 cum% 同时展示
+func TestPrintMetrics_CPUFlatAndCum(t *testing.T) {
+	metrics := analyzer.ExtractMetrics(newCPUWrapperProfile(), "cpu")
+	require.NotNil(t, metrics)
+	wrapper := findFunctionStat(metrics.TopFunctions, "main.handler")
+	require.NotNil(t, wrapper)
+	assert.Equal(t, 10.0, wrapper.FlatPct)
+	assert.Equal(t, 100.0, wrapper.CumPct)
+
+	output := captureOutput(func() { printMetrics(os.Stdout, metrics, "cpu", DefaultOptions()) })
+	assert.Contains(t, output, "Top 热点函数 (flat% / cum%):")
+	assert.Contains(t, output, "main.handler (10.0% / 100.0%, 100.0ms)")
+	assert.Contains(t, output, "main.encode (90.0% / 90.0%, 900.0ms)")
+}
+
+// TestPrintMetrics_Block 测试 block profile 的阻塞指标和阻塞调用路径
+func TestPrintMetrics_Block(t *testing.T) {
+	m := &analyzer.ProfileMetrics{
+		Contentions:  42,
+		BlockDelay:   3 * time.Second,
+		TopFunctions: []analyzer.FunctionStat{{Name: "sync.(*Mutex).Lock", Cum: int64(2400 * time.Millisecond), CumPct: 80}},
+	}
+	output := captureOutput(func() { printMetrics(os.Stdout, m, "block", DefaultOptions()) })
+	assert.Contains(t, output, "阻塞次数")
+	assert.Contains(t, output, "42")
+	assert.Contains(t, output, "阻塞时间")
+	assert.Contains(t, output, "Top 阻塞调用路径:")
+	assert.Contains(t, output, "sync.(*Mutex).Lock (80.0%, 2.4s)")
+}
+
+// TestWriteTextReport_PackageMemory 测试 heap 分组的包内存占用表
+func TestWriteTextReport_PackageMemory(t *testing.T) {
+	groups := []analyzer.ProfileGroup{
+		{
+			Type: "heap",
+			Files: []analyzer.ProfileFile{
+				{Path: "/path/to/heap1.pprof", Metrics: &analyzer.ProfileMetrics{PackageMemory: []analyzer.PackageMemory{
+					{Package: "old/pkg", AllocSpace: 1},
+				}}},
+				{Path: "/path/to/heap2.pprof", Metrics: &analyzer.ProfileMetrics{PackageMemory: []analyzer.PackageMemory{
+					{Package: "github.com/myapp/cache", Category: "business", AllocSpace: 3 << 20, InuseSpace: 1 << 20, AllocPct: 75, InusePct: 50},
+					{Package: "encoding/json", AllocSpace: 1 << 20, InuseSpace: 1 << 20, AllocPct: 25, InusePct: 50},
+				}}},
+			},
+		},
+	}
+
+	output := captureOutput(func() {
+		GenerateTextReportWithOptions(groups, nil, nil, nil, DefaultOptions())
+	})
+
+	assert.Contains(t, output, "📦 包内存占用 Top 2 (heap2.pprof):")
+	assert.NotContains(t, output, "old/pkg", "only the latest snapshot is shown")
+	lines := strings.Split(output, "\n")
+	var rows []string
+	for _, line := range lines {
+		if strings.Contains(line, "github.com/myapp/cache") || strings.Contains(line, "encoding/json") {
+			rows = append(rows, line)
+		}
+	}
+	if !assert.Len(t, rows, 2) {
+		return
+	}
+	assert.Contains(t, rows[0], locator.CategoryBusiness.String())
+	assert.Contains(t, rows[0], "3.00 MB (75.0%)")
+	assert.Contains(t, rows[1], " -  ", "packages without a category show a dash")
+	assert.Equal(t, displayWidth(rows[0]), displayWidth(rows[1]), "columns are aligned")
+
+	// 没有包内存汇总时不展示
+	groups[0].Files = groups[0].Files[:0]
+	assert.NotContains(t, captureOutput(func() { printPackageMemory(os.Stdout, groups[0]) }), "包内存占用")
+}
+
+// TestPrintTrends_GCPhase 测试疑似在 GC 极值处采集的 heap 快照
+func TestPrintTrends_GCPhase(t *testing.T) {
+	trends := &analyzer.GroupTrends{
+		HeapInuse: &analyzer.TrendMetrics{
+			Slope:     10,
+			R2:        0.9,
+			Direction: "increasing",
+			GCPhase: []analyzer.GCPhaseSkew{
+				{Index: 3, Value: 60, Reference: 120, Low: true},
+				{Index: 5, Time: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), Value: 300, Reference: 150},
+			},
+			GCPhaseSensitive: true,
+		},
+	}
+
+	output := captureOutput(func() { printTrends(os.Stdout, trends, analyzer.DefaultTrendThresholds()) })
+	assert.Contains(t, output, "🧹 #4 的快照疑似在 GC 刚结束时采集: 值=60.00, 比相邻快照低 50%，已降权")
+	assert.Contains(t, output, "🧹 2024-01-01T10:00:00Z 的快照疑似在 GC 之前采集: 值=300.00, 比相邻快照高 100%，已降权")
+	assert.Contains(t, output, gcPhaseSensitiveNote)
+
+	trends.HeapInuse.GCPhase = trends.HeapInuse.GCPhase[:1]
+	trends.HeapInuse.GCPhaseSensitive = false
+	output = captureOutput(func() { printTrends(os.Stdout, trends, analyzer.DefaultTrendThresholds()) })
+	assert.NotContains(t, output, "GC 阶段敏感")
+}
+
+// TestGenerateTextReportWithContext_HeapInsights 测试文本报告打印 heap 分组的关键发现
+func TestGenerateTextReportWithContext_HeapInsights(t *testing.T) {
+	groups := []analyzer.ProfileGroup{
+		{
+			Type: "heap",
+			Files: []analyzer.ProfileFile{
+				// GC 回收率 (400-300)/400 = 25%，触发 critical 洞察
+				{Path: "/path/to/heap1.pprof", Metrics: &analyzer.ProfileMetrics{AllocSpace: 400 << 20, InuseSpace: 300 << 20}},
+			},
+		},
+	}
+
+	output := captureOutput(func() {
+		GenerateTextReportWithContext(groups, nil, nil, nil)
+	})
+
+	assert.Contains(t, output, "💡 关键发现:")
+	assert.Contains(t, output, "🔴 ⚠️  GC 回收率过低")
+	assert.Contains(t, output, "GC 回收率仅 25.0%，大量内存无法被回收")
+
+	// 没有洞察时不展示
+	groups[0].Files[0].Metrics = &analyzer.ProfileMetrics{}
+	assert.NotContains(t, captureOutput(func() { printHeapInsights(os.Stdout, groups[0]) }), "关键发现")
+}
+
+func TestGetInsightLevelIcon(t *testing.T) {
+	assert.Equal(t, "🔴", getInsightLevelIcon("critical"))
+	assert.Equal(t, "🟡", getInsightLevelIcon("warning"))
+	assert.Equal(t, "🔵", getInsightLevelIcon("info"))
+}
+
+// TestWriteTextReport_AllocsGroup allocs profile 组的标题标注 allocs
+func TestWriteTextReport_AllocsGroup(t *testing.T) {
+	groups := []analyzer.ProfileGroup{
+		{Type: "heap", Allocs: true, Files: []analyzer.ProfileFile{{Path: "/path/to/allocs.pprof"}}},
+	}
+	output := captureOutput(func() {
+		GenerateTextReportWithOptions(groups, nil, nil, nil, DefaultOptions())
+	})
+	assert.Contains(t, output, "📁 heap (allocs) 分析 (1 个文件")
+
+	groups[0].Allocs = false
+	output = captureOutput(func() {
+		GenerateTextReportWithOptions(groups, nil, nil, nil, DefaultOptions())
+	})
+	assert.Contains(t, output, "📁 heap 分析 (1 个文件")
+}
+
+// TestWriteTextReport_DuplicateFiles -dedup 标记的重复快照仍然列出，并标注不参与趋势计算
+func TestWriteTextReport_DuplicateFiles(t *testing.T) {
+	groups := []analyzer.ProfileGroup{{Type: "heap", Files: []analyzer.ProfileFile{
+		{Path: "/path/to/heap1.pprof"},
+		{Path: "/path/to/heap2.pprof", DuplicateOf: "/path/to/heap1.pprof"},
+		{Path: "/path/to/heap3.pprof", DuplicateOf: "/path/to/heap1.pprof"},
+	}}}
+	output := captureOutput(func() {
+		GenerateTextReportWithOptions(groups, nil, nil, nil, DefaultOptions())
+	})
+	for _, name := range []string{"heap1.pprof", "heap2.pprof", "heap3.pprof"} {
+		assert.Contains(t, output, name)
+	}
+	assert.Equal(t, 2, strings.Count(output, "重复快照: 指标与 heap1.pprof 相同，不参与趋势计算"))
+}
+
+// TestPrintMetrics_TopPackages 测试 CPU profile 按包汇总的 Top 包
+func TestPrintMetrics_TopPackages(t *testing.T) {
+	m := &analyzer.ProfileMetrics{
+		CPUTime:     time.Second,
+		Duration:    10 * time.Second,
+		TopPackages: []analyzer.PackageStat{{Package: "encoding/json", Flat: int64(550 * time.Millisecond), FlatPct: 55}},
+	}
+	output := captureOutput(func() { printMetrics(os.Stdout, m, "cpu", DefaultOptions()) })
+	assert.Contains(t, output, "Top 包 (flat%):")
+	assert.Contains(t, output, "1. encoding/json (55.0%, 550.0ms)")
+
+	m.NoDuration = true
+	output = captureOutput(func() { printMetrics(os.Stdout, m, "cpu", DefaultOptions()) })
+	assert.Contains(t, output, "1. encoding/json (55.0%)")
+}
+
+// TestPrintMetrics_Threadcreate 测试 threadcreate profile 的线程数和线程创建调用点
+func TestPrintMetrics_Threadcreate(t *testing.T) {
+	m := &analyzer.ProfileMetrics{
+		ThreadCount:  1200,
+		TopFunctions: []analyzer.FunctionStat{{Name: "github.com/myapp/codec.Encode", Cum: 1000, CumPct: 83.3}},
+	}
+	output := captureOutput(func() { printMetrics(os.Stdout, m, "threadcreate", DefaultOptions()) })
+	assert.Contains(t, output, "线程数: 1,200")
+	assert.Contains(t, output, "Top 线程创建调用点:")
+	assert.Contains(t, output, "1. github.com/myapp/codec.Encode (1000, 83.3%)")
+}
+
+// TestPrintTrends_SeriesStats 测试趋势下展示序列的峰值、均值和波动
+func TestPrintTrends_SeriesStats(t *testing.T) {
+	trends := &analyzer.GroupTrends{
+		HeapInuse:      &analyzer.TrendMetrics{Slope: 1 << 20, R2: 0.9, Direction: "increasing", Points: 4, Mean: 150 << 20, Max: 200 << 20, StdDev: 30 << 20},
+		GoroutineCount: &analyzer.TrendMetrics{Slope: 5, R2: 0.95, Direction: "increasing", Points: 8, Mean: 5, Min: 2, Max: 9, StdDev: 2},
+	}
+	output := captureOutput(func() { printTrends(os.Stdout, trends, analyzer.DefaultTrendThresholds()) })
+	assert.Contains(t, output, "📊 峰值 / 均值 / 波动: 200 MB / 150 MB / ±30.00 MB (20.0%)")
+	assert.Contains(t, output, "📊 峰值 / 均值 / 波动: 9 / 5 / ±2 (40.0%)")
+
+	// 只有一个数据点时没有波动可言
+	assert.Empty(t, trendStats(&analyzer.TrendMetrics{Points: 1, Mean: 5, Max: 5}, countTrendValue))
+}
@@ -0,0 +1,443 @@
+package reporter
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/songzhibin97/perfinspector/pkg/locator"
+	"github.com/songzhibin97/perfinspector/pkg/rules"
+)
+
+// TUIKey 交互界面的按键
+type TUIKey int
+
+const (
+	KeyNone TUIKey = iota
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyEnter
+	KeyCommands
+	KeyCopy
+	KeyHelp
+	KeyQuit
+)
+
+// tuiLevel 交互界面的浏览层级
+type tuiLevel int
+
+const (
+	levelFindings tuiLevel = iota // 发现列表
+	levelHotPaths                 // 当前发现的热点路径
+	levelFrames                   // 当前热点路径的栈帧
+	levelCommands                 // 当前发现的调试命令
+)
+
+// TUI 交互式浏览器状态，复用已计算好的 ProblemContext
+// 只负责状态迁移和渲染，不涉及终端设置，便于测试
+type TUI struct {
+	findings []rules.Finding
+	contexts map[string]*locator.ProblemContext
+	opts     Options
+
+	level    tuiLevel
+	finding  int          // 当前发现
+	hotPath  int          // 当前热点路径
+	frame    int          // 当前栈帧
+	command  int          // 当前命令
+	expanded map[int]bool // 已展开的热点路径
+	showHelp bool
+	status   string // 状态栏提示
+	quit     bool
+}
+
+// NewTUI 创建交互式浏览器
+func NewTUI(findings []rules.Finding, contexts map[string]*locator.ProblemContext, opts Options) *TUI {
+	return &TUI{
+		findings: findings,
+		contexts: contexts,
+		opts:     opts,
+		expanded: make(map[int]bool),
+	}
+}
+
+// Quit 返回是否已退出
+func (t *TUI) Quit() bool {
+	return t.quit
+}
+
+// context 返回当前发现的问题上下文
+func (t *TUI) context() *locator.ProblemContext {
+	if t.finding >= len(t.findings) || t.contexts == nil {
+		return nil
+	}
+	return t.contexts[t.findings[t.finding].RuleID]
+}
+
+// currentHotPath 返回当前热点路径
+func (t *TUI) currentHotPath() *locator.HotPath {
+	ctx := t.context()
+	if ctx == nil || t.hotPath >= len(ctx.HotPaths) {
+		return nil
+	}
+	return &ctx.HotPaths[t.hotPath]
+}
+
+// HandleKey 处理按键，返回需要写给终端的额外输出（如剪贴板控制序列）
+func (t *TUI) HandleKey(key TUIKey) string {
+	t.status = ""
+	if key == KeyQuit {
+		t.quit = true
+		return ""
+	}
+	if key == KeyHelp {
+		t.showHelp = !t.showHelp
+		return ""
+	}
+
+	ctx := t.context()
+	switch t.level {
+	case levelFindings:
+		switch key {
+		case KeyUp:
+			t.finding = moveCursor(t.finding, -1, len(t.findings))
+		case KeyDown:
+			t.finding = moveCursor(t.finding, 1, len(t.findings))
+		case KeyRight, KeyEnter:
+			if ctx != nil && len(ctx.HotPaths) > 0 {
+				t.level = levelHotPaths
+				t.hotPath = 0
+				t.expanded = map[int]bool{0: true}
+			} else {
+				t.status = "该发现没有热点路径"
+			}
+		case KeyCommands:
+			t.enterCommands(ctx)
+		}
+
+	case levelHotPaths:
+		switch key {
+		case KeyUp:
+			t.hotPath = moveCursor(t.hotPath, -1, len(ctx.HotPaths))
+		case KeyDown:
+			t.hotPath = moveCursor(t.hotPath, 1, len(ctx.HotPaths))
+		case KeyEnter:
+			t.expanded[t.hotPath] = !t.expanded[t.hotPath]
+		case KeyRight:
+			if hp := t.currentHotPath(); hp != nil && len(hp.Chain.Frames) > 0 {
+				t.level = levelFrames
+				t.expanded[t.hotPath] = true
+				t.frame = hp.RootCauseIndex
+				if t.frame < 0 {
+					t.frame = 0
+				}
+			}
+		case KeyLeft:
+			t.level = levelFindings
+		case KeyCommands:
+			t.enterCommands(ctx)
+		}
+
+	case levelFrames:
+		hp := t.currentHotPath()
+		switch key {
+		case KeyUp:
+			t.frame = moveCursor(t.frame, -1, len(hp.Chain.Frames))
+		case KeyDown:
+			t.frame = moveCursor(t.frame, 1, len(hp.Chain.Frames))
+		case KeyLeft:
+			t.level = levelHotPaths
+		case KeyCommands:
+			t.enterCommands(ctx)
+		}
+
+	case levelCommands:
+		switch key {
+		case KeyUp:
+			t.command = moveCursor(t.command, -1, len(ctx.Commands))
+		case KeyDown:
+			t.command = moveCursor(t.command, 1, len(ctx.Commands))
+		case KeyLeft, KeyCommands:
+			t.level = levelFindings
+		case KeyCopy, KeyEnter:
+			cmd := ctx.Commands[t.command].Command
+			t.status = "已复制: " + cmd
+			return clipboardSequence(cmd)
+		}
+	}
+	return ""
+}
+
+// enterCommands 进入当前发现的命令列表
+func (t *TUI) enterCommands(ctx *locator.ProblemContext) {
+	if ctx == nil || len(ctx.Commands) == 0 {
+		t.status = "该发现没有调试命令"
+		return
+	}
+	t.level = levelCommands
+	t.command = primaryCommandIndex(ctx)
+}
+
+// primaryCommandIndex 返回推荐命令在命令列表中的位置，没有时返回 0
+func primaryCommandIndex(ctx *locator.ProblemContext) int {
+	if ctx.PrimaryCommand == nil {
+		return 0
+	}
+	for i, cmd := range ctx.Commands {
+		if cmd.Command == ctx.PrimaryCommand.Command {
+			return i
+		}
+	}
+	return 0
+}
+
+// moveCursor 在 [0, n) 范围内移动光标
+func moveCursor(cursor, delta, n int) int {
+	cursor += delta
+	if cursor < 0 {
+		return 0
+	}
+	if cursor >= n {
+		return n - 1
+	}
+	return cursor
+}
+
+// clipboardSequence 返回 OSC 52 剪贴板控制序列，支持的终端会将文本写入系统剪贴板
+func clipboardSequence(text string) string {
+	return "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+}
+
+// Render 渲染当前界面
+func (t *TUI) Render(w io.Writer) {
+	fmt.Fprintln(w, "PerfInspector 交互式浏览  (↑↓ 移动  → 进入  ← 返回  Enter 展开  c 命令  ? 帮助  q 退出)")
+	fmt.Fprintln(w, strings.Repeat("─", 70))
+
+	if t.showHelp {
+		t.renderHelp(w)
+		return
+	}
+
+	if len(t.findings) == 0 {
+		fmt.Fprintln(w, "没有发现")
+		return
+	}
+
+	// 发现列表
+	for i, f := range t.findings {
+		cursor := "  "
+		if i == t.finding {
+			cursor = "▶ "
+		}
+		fmt.Fprintf(w, "%s%s %s\n", cursor, getSeverityIcon(f.Severity), f.Title)
+	}
+	fmt.Fprintln(w, strings.Repeat("─", 70))
+
+	finding := t.findings[t.finding]
+	ctx := t.context()
+	fmt.Fprintf(w, "规则: %s (%s)  严重程度: %s\n", finding.RuleName, finding.RuleID, finding.Severity)
+	if finding.File != "" {
+		fmt.Fprintf(w, "文件: %s\n", finding.File)
+	}
+	if ctx == nil {
+		for _, item := range finding.Evidence {
+			fmt.Fprintf(w, "  - %s: %s\n", item.Name, item.Display)
+		}
+	} else {
+		if ctx.PrimaryCommand != nil {
+			fmt.Fprintf(w, "👉 从这里开始: $ %s\n", ctx.PrimaryCommand.Command)
+		}
+		if ctx.Explanation != "" {
+			fmt.Fprintf(w, "\n%s\n", ctx.Explanation)
+		}
+		switch t.level {
+		case levelCommands:
+			t.renderCommands(w, ctx)
+		default:
+			t.renderHotPaths(w, ctx)
+		}
+	}
+
+	if t.status != "" {
+		fmt.Fprintf(w, "\n%s\n", t.status)
+	}
+}
+
+// renderHotPaths 渲染热点路径，展开的路径显示栈帧
+func (t *TUI) renderHotPaths(w io.Writer, ctx *locator.ProblemContext) {
+	if len(ctx.HotPaths) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\n🔥 热点调用链:")
+	for i, hp := range ctx.HotPaths {
+		cursor := "  "
+		if t.level >= levelHotPaths && i == t.hotPath {
+			cursor = "▶ "
+		}
+		marker := "+"
+		if t.level >= levelHotPaths && t.expanded[i] {
+			marker = "-"
+		}
+		summary := hp.Chain.Summary()
+		if n := hp.Chain.UnsymbolizedFrames(); n > 0 {
+			summary += fmt.Sprintf(" (%d 帧缺少符号)", n)
+		}
+		fmt.Fprintf(w, "%s[%s] 热点 #%d (%s) %s\n", cursor, marker, i+1, formatPctValue(hp.Chain.TotalPct, hp.FormatValue()), summary)
+
+		if t.level < levelHotPaths || !t.expanded[i] {
+			continue
+		}
+		for j, frame := range hp.Chain.Frames {
+			frameCursor := "   "
+			if t.level == levelFrames && i == t.hotPath && j == t.frame {
+				frameCursor = " ▸ "
+			}
+			tag := ""
+			if j == hp.RootCauseIndex {
+				tag = " ← 根因"
+			}
+			fmt.Fprintf(w, "    %s%s %s%s\n", frameCursor, frame.Category.Icon(), frame.DisplayName(), tag)
+		}
+	}
+
+	if t.level == levelFrames {
+		if hp := t.currentHotPath(); hp != nil && t.frame < len(hp.Chain.Frames) {
+			t.renderFrameDetail(w, hp.Chain.Frames[t.frame])
+		}
+	}
+}
+
+// renderFrameDetail 渲染单个栈帧的详细信息
+func (t *TUI) renderFrameDetail(w io.Writer, frame locator.StackFrame) {
+	fmt.Fprintln(w, "\n📍 栈帧详情:")
+	fmt.Fprintf(w, "  函数: %s\n", frame.FunctionName)
+	if t.opts.ReadableNames {
+		fmt.Fprintf(w, "  展示名: %s\n", t.opts.displayName(frame.FunctionName))
+	}
+	fmt.Fprintf(w, "  包:   %s\n", frame.PackageName)
+	fmt.Fprintf(w, "  位置: %s\n", frame.Location())
+	fmt.Fprintf(w, "  分类: %s %s\n", frame.Category.Icon(), frame.Category.String())
+	if frame.Flat > 0 || frame.Cum > 0 {
+		fmt.Fprintf(w, "  flat: %d (%.1f%%)  cum: %d (%.1f%%)\n", frame.Flat, frame.FlatPct, frame.Cum, frame.CumPct)
+	}
+}
+
+// renderCommands 渲染调试命令列表
+func (t *TUI) renderCommands(w io.Writer, ctx *locator.ProblemContext) {
+	fmt.Fprintln(w, "\n💻 调试命令 (Enter/y 复制):")
+	for i, cmd := range ctx.Commands {
+		cursor := "  "
+		if i == t.command {
+			cursor = "▶ "
+		}
+		marker := ""
+		if ctx.PrimaryCommand != nil && cmd.Command == ctx.PrimaryCommand.Command {
+			marker = " 👉 从这里开始"
+		}
+		fmt.Fprintf(w, "%s%d. %s%s\n", cursor, i+1, cmd.Description, marker)
+		fmt.Fprintf(w, "     $ %s\n", cmd.Command)
+	}
+}
+
+// renderHelp 渲染帮助
+func (t *TUI) renderHelp(w io.Writer) {
+	fmt.Fprintln(w, "  ↑ / k        上一项")
+	fmt.Fprintln(w, "  ↓ / j        下一项")
+	fmt.Fprintln(w, "  → / l        进入热点路径 / 栈帧")
+	fmt.Fprintln(w, "  ← / h        返回上一层")
+	fmt.Fprintln(w, "  Enter / o    展开或折叠热点路径，在命令列表中复制命令")
+	fmt.Fprintln(w, "  c            显示调试命令")
+	fmt.Fprintln(w, "  y            复制当前命令 (OSC 52)")
+	fmt.Fprintln(w, "  ?            显示或关闭帮助")
+	fmt.Fprintln(w, "  q            退出")
+}
+
+// ReadTUIKey 从输入读取一个按键，支持方向键转义序列和 vi 风格按键
+// lineMode 为 true 时（终端未进入 cbreak 模式），忽略换行符，使用 o 展开
+func ReadTUIKey(r *bufio.Reader, lineMode bool) (TUIKey, error) {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return KeyQuit, err
+		}
+
+		switch b {
+		case 0x1b:
+			// 方向键: ESC [ A/B/C/D
+			if next, err := r.Peek(2); err == nil && next[0] == '[' {
+				r.Discard(2)
+				switch next[1] {
+				case 'A':
+					return KeyUp, nil
+				case 'B':
+					return KeyDown, nil
+				case 'C':
+					return KeyRight, nil
+				case 'D':
+					return KeyLeft, nil
+				}
+				continue
+			}
+			return KeyLeft, nil
+		case 'k':
+			return KeyUp, nil
+		case 'j':
+			return KeyDown, nil
+		case 'l':
+			return KeyRight, nil
+		case 'h':
+			return KeyLeft, nil
+		case 'o':
+			return KeyEnter, nil
+		case '\r', '\n':
+			if lineMode {
+				continue
+			}
+			return KeyEnter, nil
+		case 'c':
+			return KeyCommands, nil
+		case 'y':
+			return KeyCopy, nil
+		case '?':
+			return KeyHelp, nil
+		case 'q', 0x03:
+			return KeyQuit, nil
+		}
+	}
+}
+
+// RunTUI 运行交互式浏览器，直到按下退出键或输入结束
+func RunTUI(findings []rules.Finding, contexts map[string]*locator.ProblemContext, in io.Reader, out io.Writer, lineMode bool, opts Options) error {
+	findings, _ = opts.Limits.Findings(findings)
+	tui := NewTUI(findings, contexts, opts)
+	if opts.NoEmoji {
+		// 每行通过一次 Fprintf 写出，逐次替换即可
+		out = NewPlainWriter(out)
+	}
+	reader := bufio.NewReader(in)
+
+	for {
+		// 清屏并重绘
+		fmt.Fprint(out, "\x1b[H\x1b[2J")
+		tui.Render(out)
+
+		key, err := ReadTUIKey(reader, lineMode)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read input: %w", err)
+		}
+
+		if extra := tui.HandleKey(key); extra != "" {
+			fmt.Fprint(out, extra)
+		}
+		if tui.Quit() {
+			return nil
+		}
+	}
+}
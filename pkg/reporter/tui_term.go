@@ -0,0 +1,72 @@
+package reporter
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// EnableTUITerminal 通过 stty 将终端切换到 cbreak 模式（逐键读取、不回显）
+// 返回恢复函数；输入不是终端或 stty 不可用时返回 ok=false，调用方应退回行模式
+// cbreak 模式下 Ctrl-C 仍会发送 SIGINT，收到 SIGINT/SIGTERM 时先恢复终端再退出，避免留下关闭回显的 shell
+func EnableTUITerminal(tty *os.File) (restore func(), ok bool) {
+	info, err := tty.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return func() {}, false
+	}
+
+	saved, err := runStty(tty, "-g")
+	if err != nil {
+		return func() {}, false
+	}
+	if _, err := runStty(tty, "cbreak", "-echo"); err != nil {
+		return func() {}, false
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	stop := restoreOnSignal(signals, func() {
+		runStty(tty, strings.TrimSpace(saved))
+	}, os.Exit)
+
+	return func() {
+		signal.Stop(signals)
+		stop()
+	}, true
+}
+
+// restoreOnSignal 收到 signals 中的信号时执行 restore 并以 128+信号值退出
+// 返回的函数停止监听并执行 restore，restore 最多执行一次
+func restoreOnSignal(signals <-chan os.Signal, restore func(), exit func(int)) func() {
+	var once sync.Once
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case sig := <-signals:
+			once.Do(restore)
+			code := 1
+			if s, ok := sig.(syscall.Signal); ok {
+				code = 128 + int(s)
+			}
+			exit(code)
+		case <-done:
+		}
+	}()
+
+	return func() {
+		close(done)
+		once.Do(restore)
+	}
+}
+
+// runStty 在指定终端上执行 stty
+func runStty(tty *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = tty
+	out, err := cmd.Output()
+	return string(out), err
+}
@@ -0,0 +1,234 @@
+package reporter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/songzhibin97/perfinspector/pkg/locator"
+	"github.com/songzhibin97/perfinspector/pkg/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTUITestData 创建两个发现，第一个带热点路径和命令
+func newTUITestData() ([]rules.Finding, map[string]*locator.ProblemContext) {
+	findings := []rules.Finding{
+		{RuleID: "cpu_hotspot", RuleName: "CPU 热点", Severity: "medium", Title: "🔥 CPU 热点函数分析"},
+		{RuleID: "goroutine_leak", RuleName: "Goroutine 泄漏", Severity: "high", Title: "🔄 Goroutine 持续增长",
+			Evidence: rules.EvidenceFromMap(map[string]string{"b": "2", "a": "1"})},
+	}
+	contexts := map[string]*locator.ProblemContext{
+		"cpu_hotspot": {
+			Explanation: "检测到 CPU 性能问题",
+			HotPaths: []locator.HotPath{
+				{
+					Chain: locator.CallChain{
+						TotalPct: 42,
+						Frames: []locator.StackFrame{
+							{FunctionName: "main.main", ShortName: "main", Category: locator.CategoryBusiness},
+							{FunctionName: "github.com/myapp/handler.Process", ShortName: "Process", PackageName: "github.com/myapp/handler",
+								FilePath: "/src/handler.go", LineNumber: 12, Category: locator.CategoryBusiness},
+							{FunctionName: "runtime.mallocgc", ShortName: "mallocgc", Category: locator.CategoryRuntime},
+						},
+					},
+					BusinessFrames: []int{0, 1},
+					RootCauseIndex: 1,
+				},
+				{
+					Chain: locator.CallChain{
+						TotalPct: 10,
+						Frames:   []locator.StackFrame{{FunctionName: "runtime.gcBgMarkWorker", ShortName: "gcBgMarkWorker", Category: locator.CategoryRuntime}},
+					},
+					RootCauseIndex: -1,
+				},
+			},
+			Commands: []locator.ExecutableCmd{
+				{Command: "go tool pprof -top cpu.pprof", Description: "查看热点"},
+				{Command: "go tool pprof -list=Process cpu.pprof", Description: "查看源码"},
+			},
+			PrimaryCommand: &locator.ExecutableCmd{Command: "go tool pprof -list=Process cpu.pprof", Description: "查看源码"},
+		},
+	}
+	return findings, contexts
+}
+
+func renderTUI(tui *TUI) string {
+	var buf bytes.Buffer
+	tui.Render(&buf)
+	return buf.String()
+}
+
+func TestTUI_NavigateFindings(t *testing.T) {
+	findings, contexts := newTUITestData()
+	tui := NewTUI(findings, contexts, DefaultOptions())
+
+	output := renderTUI(tui)
+	assert.Contains(t, output, "▶ 🟡 🔥 CPU 热点函数分析")
+	assert.Contains(t, output, "[+] 热点 #1 (42.0%)")
+	assert.NotContains(t, output, "Process ←", "hot paths are collapsed on the findings level")
+
+	tui.HandleKey(KeyDown)
+	output = renderTUI(tui)
+	assert.Contains(t, output, "▶ 🔴 🔄 Goroutine 持续增长")
+	// 没有上下文时按排序后的顺序显示证据
+	assert.Less(t, strings.Index(output, "- a: 1"), strings.Index(output, "- b: 2"))
+
+	// 光标不越界
+	tui.HandleKey(KeyDown)
+	assert.Equal(t, 1, tui.finding)
+	tui.HandleKey(KeyRight)
+	assert.Contains(t, renderTUI(tui), "该发现没有热点路径")
+
+	tui.HandleKey(KeyUp)
+	tui.HandleKey(KeyUp)
+	assert.Equal(t, 0, tui.finding)
+}
+
+func TestTUI_HotPathsAndFrames(t *testing.T) {
+	findings, contexts := newTUITestData()
+	tui := NewTUI(findings, contexts, DefaultOptions())
+
+	// 进入热点路径，第一条默认展开
+	tui.HandleKey(KeyRight)
+	output := renderTUI(tui)
+	assert.Contains(t, output, "▶ [-] 热点 #1")
+	assert.Contains(t, output, "Process ← 根因")
+
+	// 折叠和展开
+	tui.HandleKey(KeyEnter)
+	assert.NotContains(t, renderTUI(tui), "Process ←")
+	tui.HandleKey(KeyEnter)
+
+	// 进入栈帧，光标默认停在根因帧
+	tui.HandleKey(KeyRight)
+	output = renderTUI(tui)
+	assert.Contains(t, output, "栈帧详情")
+	assert.Contains(t, output, "函数: github.com/myapp/handler.Process")
+	assert.Contains(t, output, "位置: /src/handler.go:12")
+
+	tui.HandleKey(KeyDown)
+	assert.Contains(t, renderTUI(tui), "函数: runtime.mallocgc")
+
+	// 返回
+	tui.HandleKey(KeyLeft)
+	assert.NotContains(t, renderTUI(tui), "栈帧详情")
+	tui.HandleKey(KeyDown)
+	assert.Contains(t, renderTUI(tui), "▶ [+] 热点 #2")
+	tui.HandleKey(KeyLeft)
+	assert.Equal(t, levelFindings, tui.level)
+}
+
+func TestTUI_CopyCommand(t *testing.T) {
+	findings, contexts := newTUITestData()
+	tui := NewTUI(findings, contexts, DefaultOptions())
+
+	assert.Contains(t, renderTUI(tui), "👉 从这里开始: $ go tool pprof -list=Process cpu.pprof")
+
+	// 命令列表光标默认停在推荐命令上
+	tui.HandleKey(KeyCommands)
+	output := renderTUI(tui)
+	assert.Contains(t, output, "  1. 查看热点\n")
+	assert.Contains(t, output, "▶ 2. 查看源码 👉 从这里开始")
+	assert.Contains(t, output, "$ go tool pprof -list=Process cpu.pprof")
+
+	extra := tui.HandleKey(KeyCopy)
+	encoded := base64.StdEncoding.EncodeToString([]byte("go tool pprof -list=Process cpu.pprof"))
+	assert.Equal(t, "\x1b]52;c;"+encoded+"\a", extra)
+	assert.Contains(t, renderTUI(tui), "已复制: go tool pprof -list=Process cpu.pprof")
+
+	tui.HandleKey(KeyCommands)
+	assert.Equal(t, levelFindings, tui.level)
+
+	// 没有命令的发现
+	tui.HandleKey(KeyDown)
+	tui.HandleKey(KeyCommands)
+	assert.Equal(t, levelFindings, tui.level)
+	assert.Contains(t, renderTUI(tui), "该发现没有调试命令")
+}
+
+func TestTUI_HelpAndQuit(t *testing.T) {
+	findings, contexts := newTUITestData()
+	tui := NewTUI(findings, contexts, DefaultOptions())
+
+	tui.HandleKey(KeyHelp)
+	assert.Contains(t, renderTUI(tui), "复制当前命令")
+	tui.HandleKey(KeyHelp)
+	assert.NotContains(t, renderTUI(tui), "复制当前命令")
+
+	assert.False(t, tui.Quit())
+	tui.HandleKey(KeyQuit)
+	assert.True(t, tui.Quit())
+}
+
+func TestTUI_NoFindings(t *testing.T) {
+	tui := NewTUI(nil, nil, DefaultOptions())
+	tui.HandleKey(KeyDown)
+	tui.HandleKey(KeyRight)
+	assert.Contains(t, renderTUI(tui), "没有发现")
+}
+
+func TestReadTUIKey(t *testing.T) {
+	readAll := func(input string, lineMode bool) []TUIKey {
+		r := bufio.NewReader(strings.NewReader(input))
+		var keys []TUIKey
+		for {
+			key, err := ReadTUIKey(r, lineMode)
+			if err != nil {
+				return keys
+			}
+			keys = append(keys, key)
+		}
+	}
+
+	assert.Equal(t, []TUIKey{KeyUp, KeyDown, KeyRight, KeyLeft}, readAll("\x1b[A\x1b[B\x1b[C\x1b[D", false))
+	assert.Equal(t, []TUIKey{KeyUp, KeyDown, KeyRight, KeyLeft, KeyEnter}, readAll("kjlho", false))
+	assert.Equal(t, []TUIKey{KeyCommands, KeyCopy, KeyHelp, KeyQuit, KeyQuit}, readAll("cy?q\x03", false))
+	assert.Equal(t, []TUIKey{KeyEnter}, readAll("\n", false))
+	// 行模式忽略换行，未知按键被跳过
+	assert.Equal(t, []TUIKey{KeyDown, KeyEnter}, readAll("j\nxo\n", true))
+}
+
+func TestRunTUI(t *testing.T) {
+	findings, contexts := newTUITestData()
+
+	var out bytes.Buffer
+	err := RunTUI(findings, contexts, strings.NewReader("lly"), &out, true, DefaultOptions())
+	require.NoError(t, err, "EOF ends the session")
+	assert.Contains(t, out.String(), "栈帧详情")
+
+	out.Reset()
+	err = RunTUI(findings, contexts, strings.NewReader("jqj"), &out, true, DefaultOptions())
+	require.NoError(t, err)
+	// q 之后的按键不再处理
+	assert.Equal(t, 2, strings.Count(out.String(), "\x1b[H\x1b[2J"))
+}
+
+// TestRestoreOnSignal tests that the terminal is restored before exiting on a signal, and only once
+func TestRestoreOnSignal(t *testing.T) {
+	signals := make(chan os.Signal, 1)
+	restored := 0
+	exited := make(chan int, 1)
+	stop := restoreOnSignal(signals, func() { restored++ }, func(code int) { exited <- code })
+
+	signals <- syscall.SIGINT
+	select {
+	case code := <-exited:
+		assert.Equal(t, 130, code)
+	case <-time.After(time.Second):
+		t.Fatal("signal was not handled")
+	}
+	stop()
+	assert.Equal(t, 1, restored)
+
+	// 正常退出时停止监听并恢复终端
+	restored = 0
+	stop = restoreOnSignal(make(chan os.Signal, 1), func() { restored++ }, func(int) { t.Error("unexpected exit") })
+	stop()
+	assert.Equal(t, 1, restored)
+}
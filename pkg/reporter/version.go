@@ -0,0 +1,25 @@
+package reporter
+
+import (
+	"runtime/debug"
+)
+
+// FallbackVersion 无法从构建信息获取版本时使用的版本号
+const FallbackVersion = "v0.1"
+
+// readBuildInfo 读取构建信息，测试中可替换
+var readBuildInfo = debug.ReadBuildInfo
+
+// BuildVersion 返回构建信息中的主模块版本
+// go install module@version 构建时为模块版本；本地 go build 得到 "(devel)"，此时使用 FallbackVersion
+func BuildVersion() string {
+	info, ok := readBuildInfo()
+	if !ok || info == nil {
+		return FallbackVersion
+	}
+	version := info.Main.Version
+	if version == "" || version == "(devel)" {
+		return FallbackVersion
+	}
+	return version
+}
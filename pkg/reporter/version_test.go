@@ -0,0 +1,44 @@
+package reporter
+
+import (
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildVersion(t *testing.T) {
+	defer func(orig func() (*debug.BuildInfo, bool)) { readBuildInfo = orig }(readBuildInfo)
+
+	tests := []struct {
+		name     string
+		info     *debug.BuildInfo
+		ok       bool
+		expected string
+	}{
+		{"module version", &debug.BuildInfo{Main: debug.Module{Version: "v1.2.3"}}, true, "v1.2.3"},
+		{"local build", &debug.BuildInfo{Main: debug.Module{Version: "(devel)"}}, true, FallbackVersion},
+		{"empty version", &debug.BuildInfo{}, true, FallbackVersion},
+		{"no build info", nil, false, FallbackVersion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readBuildInfo = func() (*debug.BuildInfo, bool) { return tt.info, tt.ok }
+			assert.Equal(t, tt.expected, BuildVersion())
+		})
+	}
+}
+
+func TestOptions_Version(t *testing.T) {
+	opts := DefaultOptions()
+	assert.Equal(t, BuildVersion(), opts.version())
+
+	opts.Version = "v9.9.9"
+	assert.Equal(t, "v9.9.9", opts.version())
+
+	output := captureOutput(func() {
+		GenerateTextReportWithOptions(newGoldenFixture().groups, nil, nil, nil, opts)
+	})
+	assert.Contains(t, output, "PerfInspector v9.9.9 分析报告")
+}
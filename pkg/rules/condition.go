@@ -0,0 +1,420 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+)
+
+// conditionExpr 解析后的规则条件表达式
+type conditionExpr interface {
+	// eval 对分组和趋势求值
+	eval(env conditionEnv) bool
+	// usesTrends 表达式是否引用了趋势变量
+	usesTrends() bool
+	// trendMetrics 表达式引用的趋势指标，可能重复
+	trendMetrics() []string
+}
+
+// conditionEnv 表达式求值的输入
+type conditionEnv struct {
+	group  analyzer.ProfileGroup
+	trends *analyzer.GroupTrends
+}
+
+// conditionValue 变量或字面量的值，isText 为 true 时使用 text，否则使用 number
+type conditionValue struct {
+	number float64
+	text   string
+	isText bool
+}
+
+// conditionVariable 表达式可以引用的变量
+type conditionVariable struct {
+	isText bool
+	trend  bool                                          // 依赖趋势数据
+	metric string                                        // 所属的趋势指标，非趋势变量为空
+	value  func(env conditionEnv) (conditionValue, bool) // 缺少数据时返回 false
+}
+
+// conditionVariables 表达式可以引用的变量，趋势变量写作 "<指标>.<字段>"
+var conditionVariables = newConditionVariables()
+
+// conditionTrends 表达式可以引用的趋势指标
+var conditionTrends = map[string]func(t *analyzer.GroupTrends) *analyzer.TrendMetrics{
+	analyzer.MetricHeapInuse:        func(t *analyzer.GroupTrends) *analyzer.TrendMetrics { return t.HeapInuse },
+	analyzer.MetricHeapInuseObjects: func(t *analyzer.GroupTrends) *analyzer.TrendMetrics { return t.HeapInuseObjects },
+	analyzer.MetricGoroutineCount:   func(t *analyzer.GroupTrends) *analyzer.TrendMetrics { return t.GoroutineCount },
+}
+
+// conditionTrendsPrefix 旧版规则文件中趋势变量的前缀，如 "trends.heap_inuse.slope"，解析时忽略
+const conditionTrendsPrefix = "trends."
+
+// newConditionVariables 构建变量表：file_count、每个趋势指标的 slope、r2、points 和 direction，
+// heap 指标的 growth (最新快照相对最早快照的增长比例)，goroutine_count.growth_kind (增长形态)，
+// 以及 cpu_usage.growth (最新快照的 CPU 使用率相对最早快照的增长比例)
+func newConditionVariables() map[string]conditionVariable {
+	vars := map[string]conditionVariable{
+		"file_count": {value: func(env conditionEnv) (conditionValue, bool) {
+			return conditionValue{number: float64(len(env.group.Files))}, true
+		}},
+	}
+
+	fields := map[string]func(t *analyzer.TrendMetrics) conditionValue{
+		"slope":     func(t *analyzer.TrendMetrics) conditionValue { return conditionValue{number: t.Slope} },
+		"r2":        func(t *analyzer.TrendMetrics) conditionValue { return conditionValue{number: t.R2} },
+		"points":    func(t *analyzer.TrendMetrics) conditionValue { return conditionValue{number: float64(t.Points)} },
+		"direction": func(t *analyzer.TrendMetrics) conditionValue { return conditionValue{text: t.Direction, isText: true} },
+	}
+	for metric, selectTrend := range conditionTrends {
+		for field, get := range fields {
+			selectTrend, get := selectTrend, get
+			vars[metric+"."+field] = conditionVariable{
+				isText: field == "direction",
+				trend:  true,
+				metric: metric,
+				value: func(env conditionEnv) (conditionValue, bool) {
+					if env.trends == nil {
+						return conditionValue{}, false
+					}
+					trend := selectTrend(env.trends)
+					if trend == nil {
+						return conditionValue{}, false
+					}
+					return get(trend), true
+				},
+			}
+		}
+	}
+
+	growth := map[string]string{
+		analyzer.MetricHeapInuse:        analyzer.HeapSampleInuseSpace,
+		analyzer.MetricHeapInuseObjects: analyzer.HeapSampleInuseObjects,
+	}
+	for metric, sampleType := range growth {
+		metric, sampleType := metric, sampleType
+		vars[metric+".growth"] = conditionVariable{
+			trend:  true,
+			metric: metric,
+			value: func(env conditionEnv) (conditionValue, bool) {
+				if len(env.group.Files) < 2 {
+					return conditionValue{}, false
+				}
+				return conditionValue{number: heapGrowthRatio(env.group, sampleType)}, true
+			},
+		}
+	}
+
+	vars[analyzer.MetricGoroutineCount+".growth_kind"] = conditionVariable{
+		isText: true,
+		trend:  true,
+		metric: analyzer.MetricGoroutineCount,
+		value: func(env conditionEnv) (conditionValue, bool) {
+			if env.trends == nil || env.trends.GoroutineCount == nil {
+				return conditionValue{}, false
+			}
+			return conditionValue{text: env.trends.GoroutineGrowthKind, isText: true}, true
+		},
+	}
+
+	// 不依赖趋势拟合，两个带采集时长的 CPU 快照即可比较
+	vars["cpu_usage.growth"] = conditionVariable{
+		value: func(env conditionEnv) (conditionValue, bool) {
+			growth, ok := cpuUsageGrowth(env.group)
+			return conditionValue{number: growth}, ok
+		},
+	}
+	return vars
+}
+
+// conditionVariableNames 返回排序后的变量名
+func conditionVariableNames() []string {
+	names := make([]string, 0, len(conditionVariables))
+	for name := range conditionVariables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// binaryExpr 由 && 或 || 连接的两个子表达式
+type binaryExpr struct {
+	op          string
+	left, right conditionExpr
+}
+
+func (b binaryExpr) eval(env conditionEnv) bool {
+	if b.op == "&&" {
+		return b.left.eval(env) && b.right.eval(env)
+	}
+	return b.left.eval(env) || b.right.eval(env)
+}
+
+func (b binaryExpr) usesTrends() bool {
+	return b.left.usesTrends() || b.right.usesTrends()
+}
+
+func (b binaryExpr) trendMetrics() []string {
+	return append(b.left.trendMetrics(), b.right.trendMetrics()...)
+}
+
+// comparisonExpr 变量与字面量的比较，如 "heap_inuse.slope > 5"
+type comparisonExpr struct {
+	name     string
+	variable conditionVariable
+	op       string
+	literal  conditionValue
+}
+
+// eval 比较变量与字面量，变量缺少数据 (如分组没有该趋势) 时不成立
+func (c comparisonExpr) eval(env conditionEnv) bool {
+	actual, ok := c.variable.value(env)
+	if !ok {
+		return false
+	}
+	if c.literal.isText {
+		if c.op == "==" {
+			return actual.text == c.literal.text
+		}
+		return actual.text != c.literal.text
+	}
+	return compareNumbers(actual.number, c.op, c.literal.number)
+}
+
+func (c comparisonExpr) usesTrends() bool {
+	return c.variable.trend
+}
+
+func (c comparisonExpr) trendMetrics() []string {
+	if c.variable.metric == "" {
+		return nil
+	}
+	return []string{c.variable.metric}
+}
+
+// compareNumbers 按运算符比较两个数值
+func compareNumbers(actual float64, op string, value float64) bool {
+	switch op {
+	case ">=":
+		return actual >= value
+	case "<=":
+		return actual <= value
+	case "==":
+		return actual == value
+	case "!=":
+		return actual != value
+	case ">":
+		return actual > value
+	default:
+		return actual < value
+	}
+}
+
+// conditionToken 条件表达式的词法单元
+type conditionToken struct {
+	kind  string // ident、number、string 或运算符/括号本身
+	text  string
+	value float64
+}
+
+// conditionOperators 运算符和括号，两个字符的运算符在前
+var conditionOperators = []string{"&&", "||", ">=", "<=", "==", "!=", ">", "<", "(", ")"}
+
+// tokenizeCondition 将条件拆分为词法单元
+func tokenizeCondition(condition string) ([]conditionToken, error) {
+	var tokens []conditionToken
+	for i := 0; i < len(condition); {
+		c := condition[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(condition[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			tokens = append(tokens, conditionToken{kind: "string", text: condition[i+1 : i+1+end]})
+			i += end + 2
+		case isConditionDigit(c) || (c == '-' && i+1 < len(condition) && isConditionDigit(condition[i+1])):
+			start := i
+			i++
+			for i < len(condition) && (isConditionDigit(condition[i]) || condition[i] == '.') {
+				i++
+			}
+			value, err := strconv.ParseFloat(condition[start:i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number '%s'", condition[start:i])
+			}
+			tokens = append(tokens, conditionToken{kind: "number", text: condition[start:i], value: value})
+		case isConditionIdentStart(c):
+			start := i
+			for i < len(condition) && (isConditionIdentStart(condition[i]) || isConditionDigit(condition[i]) || condition[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, conditionToken{kind: "ident", text: condition[start:i]})
+		default:
+			matched := false
+			for _, op := range conditionOperators {
+				if strings.HasPrefix(condition[i:], op) {
+					tokens = append(tokens, conditionToken{kind: op, text: op})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character '%c' at offset %d", c, i)
+			}
+		}
+	}
+	return tokens, nil
+}
+
+func isConditionDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isConditionIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// conditionParser 递归下降解析器，|| 的优先级低于 &&，括号可以改变优先级
+type conditionParser struct {
+	tokens []conditionToken
+	pos    int
+}
+
+// parseCondition 解析规则条件表达式，如 "heap_inuse.slope > 5 && heap_inuse.r2 > 0.9"
+// 引用未知变量、类型不匹配或语法错误时返回错误
+func parseCondition(condition string) (conditionExpr, error) {
+	tokens, err := tokenizeCondition(condition)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty condition")
+	}
+	p := &conditionParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected '%s'", p.tokens[p.pos].text)
+	}
+	return expr, nil
+}
+
+// peek 返回当前词法单元的类型，已到末尾时返回空字符串
+func (p *conditionParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos].kind
+}
+
+// next 返回当前词法单元并前进，已到末尾时返回错误
+func (p *conditionParser) next() (conditionToken, error) {
+	if p.pos >= len(p.tokens) {
+		return conditionToken{}, fmt.Errorf("unexpected end of condition")
+	}
+	token := p.tokens[p.pos]
+	p.pos++
+	return token, nil
+}
+
+func (p *conditionParser) parseOr() (conditionExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "||" {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseAnd() (conditionExpr, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "&&" {
+		p.pos++
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parsePrimary() (conditionExpr, error) {
+	if p.peek() == "(" {
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing ')'")
+		}
+		p.pos++
+		return expr, nil
+	}
+	return p.parseComparison()
+}
+
+// parseComparison 解析 "<变量> <运算符> <字面量>"，字符串变量只支持 == 和 !=
+// 变量可以带 "trends." 前缀，与旧版规则文件的写法兼容
+func (p *conditionParser) parseComparison() (conditionExpr, error) {
+	name, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if name.kind != "ident" {
+		return nil, fmt.Errorf("expected variable, got '%s'", name.text)
+	}
+	name.text = strings.TrimPrefix(name.text, conditionTrendsPrefix)
+	variable, ok := conditionVariables[name.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown variable '%s', must be one of: %s", name.text, strings.Join(conditionVariableNames(), ", "))
+	}
+
+	op, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	switch op.kind {
+	case ">=", "<=", "==", "!=", ">", "<":
+	default:
+		return nil, fmt.Errorf("expected comparison operator after '%s', got '%s'", name.text, op.text)
+	}
+
+	literal, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	expr := comparisonExpr{name: name.text, variable: variable, op: op.kind}
+	switch {
+	case variable.isText && literal.kind == "string":
+		if op.kind != "==" && op.kind != "!=" {
+			return nil, fmt.Errorf("'%s' is a string, only == and != are supported", name.text)
+		}
+		expr.literal = conditionValue{text: literal.text, isText: true}
+	case !variable.isText && literal.kind == "number":
+		expr.literal = conditionValue{number: literal.value}
+	default:
+		return nil, fmt.Errorf("cannot compare '%s' with '%s'", name.text, literal.text)
+	}
+	return expr, nil
+}
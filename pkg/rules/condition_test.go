@@ -0,0 +1,198 @@
+package rules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// conditionTestEnv 返回一个 3 文件的 heap 分组，heap 快速增长，goroutine 平稳
+func conditionTestEnv() conditionEnv {
+	now := time.Now()
+	return conditionEnv{
+		group: analyzer.ProfileGroup{Type: "heap", Files: []analyzer.ProfileFile{
+			{Time: now}, {Time: now.Add(time.Minute)}, {Time: now.Add(2 * time.Minute)},
+		}},
+		trends: &analyzer.GroupTrends{
+			HeapInuse:      &analyzer.TrendMetrics{Slope: 8, R2: 0.95, Direction: "increasing", Points: 3},
+			GoroutineCount: &analyzer.TrendMetrics{Slope: 0, R2: 0.1, Direction: "stable", Points: 3},
+		},
+	}
+}
+
+// evalCondition 解析并求值条件，解析失败时测试失败
+func evalCondition(t *testing.T, condition string, env conditionEnv) bool {
+	t.Helper()
+	expr, err := parseCondition(condition)
+	require.NoError(t, err, condition)
+	return expr.eval(env)
+}
+
+func TestParseCondition_Comparisons(t *testing.T) {
+	env := conditionTestEnv()
+	for condition, want := range map[string]bool{
+		"heap_inuse.slope > 5":                         true,
+		"heap_inuse.slope > 8":                         false,
+		"heap_inuse.slope >= 8":                        true,
+		"heap_inuse.r2 < 0.9":                          false,
+		"heap_inuse.r2 <= 0.95":                        true,
+		"heap_inuse.points == 3":                       true,
+		"heap_inuse.points != 3":                       false,
+		"file_count == 3":                              true,
+		"heap_inuse.slope > -1":                        true,
+		`heap_inuse.direction == "increasing"`:         true,
+		`goroutine_count.direction == 'increasing'`:    false,
+		`goroutine_count.direction != "increasing"`:    true,
+		"heap_inuse.slope > 5 && heap_inuse.r2 > 0.9":  true,
+		"heap_inuse.slope > 5 && heap_inuse.r2 > 0.99": false,
+	} {
+		assert.Equal(t, want, evalCondition(t, condition, env), condition)
+	}
+}
+
+// TestParseCondition_GoroutineGrowthKind 规则可以排除阶跃后趋于平稳的 goroutine 增长
+func TestParseCondition_GoroutineGrowthKind(t *testing.T) {
+	env := conditionTestEnv()
+	env.trends.GoroutineGrowthKind = analyzer.GoroutineGrowthPlateau
+	assert.True(t, evalCondition(t, `goroutine_count.growth_kind == "plateau"`, env))
+	assert.False(t, evalCondition(t, `goroutine_count.growth_kind != "plateau"`, env))
+
+	env.trends.GoroutineGrowthKind = analyzer.GoroutineGrowthSustained
+	assert.True(t, evalCondition(t, `goroutine_count.growth_kind != "plateau"`, env))
+
+	// 没有 goroutine 趋势时比较不成立
+	env.trends.GoroutineCount = nil
+	assert.False(t, evalCondition(t, `goroutine_count.growth_kind != "plateau"`, env))
+}
+
+// TestParseCondition_CPUUsageGrowth 比较最新与最早 CPU 快照的使用率，不需要趋势
+func TestParseCondition_CPUUsageGrowth(t *testing.T) {
+	cpuFile := func(cpuTime time.Duration) analyzer.ProfileFile {
+		return analyzer.ProfileFile{Metrics: &analyzer.ProfileMetrics{Duration: 10 * time.Second, CPUTime: cpuTime}}
+	}
+	env := conditionEnv{group: analyzer.ProfileGroup{Type: "cpu", Files: []analyzer.ProfileFile{
+		cpuFile(2 * time.Second),
+		{Metrics: &analyzer.ProfileMetrics{NoDuration: true}},
+		cpuFile(5 * time.Second),
+	}}}
+	assert.True(t, evalCondition(t, "cpu_usage.growth > 1.0", env))
+	assert.False(t, evalCondition(t, "cpu_usage.growth > 2.0", env))
+
+	expr, err := parseCondition("cpu_usage.growth > 1.0")
+	require.NoError(t, err)
+	assert.False(t, expr.usesTrends())
+
+	// 只有一个可比较的快照时比较不成立
+	env.group.Files = env.group.Files[1:]
+	assert.False(t, evalCondition(t, "cpu_usage.growth > -1", env))
+}
+
+// TestParseCondition_Precedence && 的优先级高于 ||，括号可以改变优先级
+func TestParseCondition_Precedence(t *testing.T) {
+	env := conditionTestEnv()
+
+	// 按 a || (b && c) 求值为 true；若错误地按 (a || b) && c 求值则为 false
+	assert.True(t, evalCondition(t, "file_count == 3 || file_count > 5 && heap_inuse.slope > 100", env))
+	assert.False(t, evalCondition(t, "(file_count == 3 || file_count > 5) && heap_inuse.slope > 100", env))
+
+	// 按 (a && b) || c 求值为 true；若错误地按 a && (b || c) 求值则为 false
+	assert.True(t, evalCondition(t, "file_count > 5 && heap_inuse.slope > 100 || heap_inuse.r2 > 0.9", env))
+	assert.False(t, evalCondition(t, "file_count > 5 && (heap_inuse.slope > 100 || heap_inuse.r2 > 0.9)", env))
+
+	// 同级运算从左到右结合，嵌套括号
+	assert.True(t, evalCondition(t, "((file_count == 3)) && (heap_inuse.r2 > 0.9 || file_count == 0) && heap_inuse.points == 3", env))
+}
+
+// TestParseCondition_MissingTrend 分组没有该趋势时比较不成立
+func TestParseCondition_MissingTrend(t *testing.T) {
+	env := conditionTestEnv()
+	assert.False(t, evalCondition(t, "heap_inuse_objects.slope > 0", env))
+	assert.False(t, evalCondition(t, "heap_inuse_objects.slope != 0", env))
+	assert.True(t, evalCondition(t, "heap_inuse_objects.slope > 0 || file_count == 3", env))
+
+	env.trends = nil
+	assert.False(t, evalCondition(t, "heap_inuse.slope > 5", env))
+	assert.True(t, evalCondition(t, "file_count > 0", env))
+}
+
+func TestParseCondition_UsesTrends(t *testing.T) {
+	expr, err := parseCondition("file_count > 1")
+	require.NoError(t, err)
+	assert.False(t, expr.usesTrends())
+
+	expr, err = parseCondition("file_count > 1 || goroutine_count.slope > 1")
+	require.NoError(t, err)
+	assert.True(t, expr.usesTrends())
+}
+
+func TestParseCondition_Errors(t *testing.T) {
+	for condition, wantErr := range map[string]string{
+		"":                                           "empty condition",
+		"trends.heap_inuse.slop > 10.0":              "unknown variable 'heap_inuse.slop'",
+		"metricsSeries.length > 3":                   "unknown variable",
+		"profile_exists":                             "unknown variable",
+		"heap_inuse.slope":                           "unexpected end of condition",
+		"heap_inuse.slope > ":                        "unexpected end of condition",
+		"heap_inuse.slope 5":                         "expected comparison operator",
+		`heap_inuse.slope > "fast"`:                  "cannot compare",
+		"heap_inuse.direction == 1":                  "cannot compare",
+		`heap_inuse.direction > "a"`:                 "only == and != are supported",
+		"(heap_inuse.slope > 1":                      "missing ')'",
+		"heap_inuse.slope > 1)":                      "unexpected ')'",
+		"heap_inuse.slope > 1 &&":                    "unexpected end of condition",
+		`heap_inuse.direction == "stable`:            "unterminated string",
+		"heap_inuse.slope > 1 & file_count":          "unexpected character '&'",
+		"current.cpu_usage > baseline.cpu_usage * 2": "unexpected character '*'",
+	} {
+		_, err := parseCondition(condition)
+		require.Error(t, err, condition)
+		assert.Contains(t, err.Error(), wantErr, condition)
+	}
+}
+
+// TestEngine_Evaluate_ExpressionCondition 规则可以用表达式声明自己的阈值
+func TestEngine_Evaluate_ExpressionCondition(t *testing.T) {
+	env := conditionTestEnv()
+	newEngine := func(condition string) *Engine {
+		return &Engine{rules: []Rule{compiledRule(Rule{
+			ID:           "custom_growth",
+			Name:         "Custom Growth",
+			ProfileTypes: []string{"heap"},
+			Condition:    condition,
+			Actions:      []Action{{Type: "report", Severity: "medium", Title: "Heap growing"}},
+		})}}
+	}
+	groups := []analyzer.ProfileGroup{env.group}
+	trends := map[string]*analyzer.GroupTrends{"heap": env.trends}
+
+	// 斜率 8 低于内置规则的 10 MB 门槛，表达式条件可以自行降低
+	findings := newEngine("heap_inuse.slope > 5 && heap_inuse.r2 > 0.9").Evaluate(groups, trends)
+	require.Len(t, findings, 1)
+	assert.Equal(t, "custom_growth", findings[0].RuleID)
+
+	assert.Empty(t, newEngine("heap_inuse.slope > 5 && heap_inuse.r2 > 0.99").Evaluate(groups, trends))
+
+	// 旧写法的 trends. 前缀按别名处理
+	assert.Len(t, newEngine("trends.heap_inuse.slope > 5").Evaluate(groups, trends), 1)
+}
+
+// TestHasEnoughData_ExpressionCondition 不引用趋势的表达式只需要一个文件
+func TestHasEnoughData_ExpressionCondition(t *testing.T) {
+	group := analyzer.ProfileGroup{Type: "heap", Files: []analyzer.ProfileFile{{}}}
+
+	fileCount := compiledRule(Rule{Condition: "file_count >= 1"})
+	assert.True(t, hasEnoughData(fileCount, group, nil, 3))
+	assert.False(t, hasEnoughData(fileCount, analyzer.ProfileGroup{Type: "heap"}, nil, 3))
+	assert.False(t, hasEnoughData(compiledRule(Rule{Condition: "heap_inuse.slope > 5"}), group, &analyzer.GroupTrends{}, 3))
+}
+
+// compiledRule 像 NewEngine 一样解析规则的表达式条件
+func compiledRule(rule Rule) Rule {
+	if err := compileCondition(&rule); err != nil {
+		panic(err)
+	}
+	return rule
+}
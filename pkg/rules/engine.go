@@ -2,7 +2,9 @@ package rules
 
 import (
 	"fmt"
+	"math"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -10,33 +12,76 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// DefaultCrossAnalysisMinR2 联合分析规则的默认趋势 R² 门槛，规则未配置 min_r2 时使用
+const DefaultCrossAnalysisMinR2 = 0.7
+
+// ConditionProfileExists 单 profile 条件：分组中有 profile 文件即触发，用于自定义 profile 的热点分析
+const ConditionProfileExists = "profile_exists"
+
+// ConditionInuseAllocDivergence 单 profile 条件：存在 inuse/alloc 保留率异常高的包
+const ConditionInuseAllocDivergence = "inuse_alloc_divergence"
+
+// ConditionConversionHotspot 单 profile 条件：string/[]byte 转换主导了堆分配
+const ConditionConversionHotspot = "string_conversion_hotspot"
+
+// ConditionReflectionHotspot 单 profile 条件：reflect 包占据了显著的 CPU 时间或堆分配
+const ConditionReflectionHotspot = "reflection_hotspot"
+
+// ConditionGCAssistStall 单 profile 条件：goroutine 在分配路径上被迫执行 GC 辅助标记
+const ConditionGCAssistStall = "gc_assist_stall"
+
+// ConditionOversizedAllocation 单 profile 条件：存在平均单次分配超大的分配点
+const ConditionOversizedAllocation = "oversized_allocation"
+
+// ConditionNewTopFunction 时间序列条件：最新快照中有函数新进入 flat Top-N
+const ConditionNewTopFunction = "new_top_function"
+
+// 联合分析关联类型：有方向的趋势 (至少两条) 方向一致，或一升一降
+const (
+	CorrelationSameDirection    = "same_direction"
+	CorrelationInverseDirection = "inverse_direction"
+)
+
+// CorrelationChannelBacklog 联合分析关联类型：goroutine 阻塞在 chan send 上且 channel 内存增长
+const CorrelationChannelBacklog = "channel_backlog"
+
+// 联合分析关联类型：分配速率增长，分别来自 goroutine 变多和每个 goroutine 分配变多
+const (
+	CorrelationGoroutineFanout        = "goroutine_fanout"
+	CorrelationPerGoroutineAllocation = "per_goroutine_allocation"
+)
+
 // Engine 规则引擎
 type Engine struct {
 	rules              []Rule
 	crossAnalysisRules []CrossAnalysisRule
+	minTrendPoints     int      // 趋势条件所需的最少快照数，<= 0 时使用 analyzer.DefaultMinTrendPoints
+	warnings           []string // 加载规则时的警告，如按已弃用的关键字匹配评估的条件
 }
 
 // NewEngine 创建规则引擎，从指定路径加载规则
+// rulesPath 可以是逗号分隔的多个文件 (如 "base.yaml,service.yaml")，按顺序合并：
+// 后面文件中的规则按 ID 整条覆盖前面文件的同名规则 (保留原来的位置)，新的规则追加在末尾
 func NewEngine(rulesPath string) (*Engine, error) {
-	if rulesPath == "" {
+	paths := splitRulesPaths(rulesPath)
+	if len(paths) == 0 {
 		return nil, nil
 	}
 
-	data, err := os.ReadFile(rulesPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("rules file not found: %s", rulesPath)
-		}
-		return nil, fmt.Errorf("failed to read rules file: %w", err)
-	}
-
 	var config RulesConfig
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse rules file: %w", err)
+	for _, path := range paths {
+		fileConfig, err := loadRulesConfig(path)
+		if err != nil {
+			return nil, err
+		}
+		config = mergeRulesConfig(config, fileConfig)
 	}
 
-	// 验证单类型规则结构
-	for i, rule := range config.Rules {
+	// 验证单类型规则结构，整组规则的表达式条件在这里解析一次，
+	// 无法解析的条件按旧版关键字匹配评估并记录弃用警告
+	var warnings []string
+	for i := range config.Rules {
+		rule := &config.Rules[i]
 		if rule.ID == "" {
 			return nil, fmt.Errorf("rule %d: missing id", i)
 		}
@@ -52,6 +97,21 @@ func NewEngine(rulesPath string) (*Engine, error) {
 		if len(rule.Actions) == 0 {
 			return nil, fmt.Errorf("rule %s: missing actions", rule.ID)
 		}
+		if rule.MinR2 < 0 || rule.MinR2 > 1 {
+			return nil, fmt.Errorf("rule %s: min_r2 must be in [0, 1]", rule.ID)
+		}
+		switch rule.Scope {
+		case "", ScopeGroup:
+			if err := compileCondition(rule); err != nil {
+				warnings = append(warnings, legacyConditionWarning(*rule, err))
+			}
+		case ScopeFile:
+			if _, err := parseFileCondition(rule.Condition); err != nil {
+				return nil, fmt.Errorf("rule %s: %w", rule.ID, err)
+			}
+		default:
+			return nil, fmt.Errorf("rule %s: unknown scope '%s', must be group or file", rule.ID, rule.Scope)
+		}
 	}
 
 	// 验证联合分析规则结构
@@ -68,61 +128,231 @@ func NewEngine(rulesPath string) (*Engine, error) {
 		if len(rule.Actions) == 0 {
 			return nil, fmt.Errorf("cross_analysis_rule %s: missing actions", rule.ID)
 		}
+		if rule.MinR2 < 0 || rule.MinR2 > 1 {
+			return nil, fmt.Errorf("cross_analysis_rule %s: min_r2 must be in [0, 1]", rule.ID)
+		}
 	}
 
 	return &Engine{
 		rules:              config.Rules,
 		crossAnalysisRules: config.CrossAnalysisRules,
+		warnings:           warnings,
 	}, nil
 }
 
+// Warnings 返回加载规则时的警告，engine 为 nil 时返回 nil
+func (e *Engine) Warnings() []string {
+	if e == nil {
+		return nil
+	}
+	return e.warnings
+}
+
+// splitRulesPaths 拆分逗号分隔的规则文件列表，忽略空白项
+func splitRulesPaths(rulesPath string) []string {
+	var paths []string
+	for _, path := range strings.Split(rulesPath, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// loadRulesConfig 读取并解析单个规则文件，同一文件中的重复规则 ID 视为错误
+func loadRulesConfig(rulesPath string) (RulesConfig, error) {
+	var config RulesConfig
+	data, err := os.ReadFile(rulesPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return config, fmt.Errorf("rules file not found: %s", rulesPath)
+		}
+		return config, fmt.Errorf("failed to read rules file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("failed to parse rules file: %w", err)
+	}
+
+	seen := make(map[string]bool, len(config.Rules))
+	for _, rule := range config.Rules {
+		if rule.ID != "" && seen[rule.ID] {
+			return config, fmt.Errorf("rules file %s: duplicate rule id '%s'", rulesPath, rule.ID)
+		}
+		seen[rule.ID] = true
+	}
+	seen = make(map[string]bool, len(config.CrossAnalysisRules))
+	for _, rule := range config.CrossAnalysisRules {
+		if rule.ID != "" && seen[rule.ID] {
+			return config, fmt.Errorf("rules file %s: duplicate cross_analysis_rule id '%s'", rulesPath, rule.ID)
+		}
+		seen[rule.ID] = true
+	}
+	return config, nil
+}
+
+// mergeRulesConfig 将 override 合并到 base：同 ID 的规则整条替换，其余追加
+// 缺少 ID 的规则原样追加，由合并后的校验报错
+func mergeRulesConfig(base, override RulesConfig) RulesConfig {
+	for _, rule := range override.Rules {
+		if i := indexRule(base.Rules, rule.ID); i >= 0 {
+			base.Rules[i] = rule
+		} else {
+			base.Rules = append(base.Rules, rule)
+		}
+	}
+	for _, rule := range override.CrossAnalysisRules {
+		if i := indexCrossAnalysisRule(base.CrossAnalysisRules, rule.ID); i >= 0 {
+			base.CrossAnalysisRules[i] = rule
+		} else {
+			base.CrossAnalysisRules = append(base.CrossAnalysisRules, rule)
+		}
+	}
+	return base
+}
+
+// indexRule 返回 ID 对应规则的下标，ID 为空或不存在时返回 -1
+func indexRule(rules []Rule, id string) int {
+	if id == "" {
+		return -1
+	}
+	for i := range rules {
+		if rules[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// indexCrossAnalysisRule 返回 ID 对应联合分析规则的下标，ID 为空或不存在时返回 -1
+func indexCrossAnalysisRule(rules []CrossAnalysisRule, id string) int {
+	if id == "" {
+		return -1
+	}
+	for i := range rules {
+		if rules[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
 // Evaluate 评估规则，返回匹配的发现
 func (e *Engine) Evaluate(groups []analyzer.ProfileGroup, trends map[string]*analyzer.GroupTrends) []Finding {
+	findings, _ := e.EvaluateWithStats(groups, trends)
+	return findings
+}
+
+// EvaluateWithStats 与 Evaluate 相同，同时返回匹配、未匹配和跳过的规则数
+// 用于确认规则文件确实被评估，以及排查 "规则加载了但没有任何发现"
+func (e *Engine) EvaluateWithStats(groups []analyzer.ProfileGroup, trends map[string]*analyzer.GroupTrends) ([]Finding, EvaluationStats) {
+	stats := EvaluationStats{Groups: len(groups)}
 	if e == nil {
-		return nil
+		return nil, stats
 	}
 
 	var findings []Finding
 
 	// 1. 单类型规则评估
 	if len(e.rules) > 0 {
+		outcomes := make([]ruleOutcome, len(e.rules))
 		for _, group := range groups {
 			groupTrends := trends[group.Type]
 
-			for _, rule := range e.rules {
+			for i, rule := range e.rules {
 				// 检查规则是否适用于当前 profile 类型
 				if !e.matchesProfileType(rule, group.Type) {
 					continue
 				}
+				if !hasEnoughData(rule, group, groupTrends, e.minPoints()) {
+					outcomes[i] = maxOutcome(outcomes[i], outcomeSkippedData)
+					continue
+				}
+				outcomes[i] = maxOutcome(outcomes[i], outcomeNotMatched)
+
+				// 单文件规则：每个满足条件的文件产生一个发现
+				if rule.Scope == ScopeFile {
+					for _, file := range e.matchingFiles(rule, group) {
+						outcomes[i] = outcomeMatched
+						for _, action := range rule.Actions {
+							findings = append(findings, Finding{
+								RuleID:       rule.ID,
+								RuleName:     rule.Name,
+								Severity:     action.Severity,
+								Title:        action.Title,
+								Evidence:     e.buildFileEvidence(action, file),
+								Suggestions:  action.Suggestions,
+								ProfileTypes: []string{group.Type},
+								File:         file.Path,
+							})
+						}
+					}
+					continue
+				}
 
 				// 评估条件
-				if e.evaluateCondition(rule.Condition, group, groupTrends) {
+				if e.evaluateCondition(rule, group, groupTrends) {
+					outcomes[i] = outcomeMatched
 					for _, action := range rule.Actions {
+						evidence := e.buildEvidence(action, groupTrends, group)
+						if rule.Condition == ConditionInuseAllocDivergence {
+							// 背离检测不依赖趋势，单独构建证据
+							evidence = e.buildRetentionEvidence(action, group)
+						}
+						if rule.Condition == ConditionConversionHotspot {
+							evidence = e.buildConversionEvidence(action, group)
+						}
+						if rule.Condition == ConditionReflectionHotspot {
+							evidence = e.buildReflectionEvidence(action, group)
+						}
+						if rule.Condition == ConditionGCAssistStall {
+							evidence = e.buildGCAssistEvidence(action, group)
+						}
+						if rule.Condition == ConditionOversizedAllocation {
+							evidence = e.buildOversizedEvidence(action, group)
+						}
+						if rule.Condition == ConditionNewTopFunction {
+							evidence = e.buildNewTopEvidence(action, group)
+						}
 						finding := Finding{
-							RuleID:      rule.ID,
-							RuleName:    rule.Name,
-							Severity:    action.Severity,
-							Title:       action.Title,
-							Evidence:    e.buildEvidence(action.EvidenceTemplate, groupTrends, group),
-							Suggestions: action.Suggestions,
+							RuleID:       rule.ID,
+							RuleName:     rule.Name,
+							Severity:     action.Severity,
+							Title:        action.Title,
+							Evidence:     evidence,
+							Suggestions:  action.Suggestions,
+							ProfileTypes: []string{group.Type},
 						}
 						findings = append(findings, finding)
 					}
 				}
 			}
 		}
+		for i, outcome := range outcomes {
+			stats.record(e.rules[i].ID, outcome)
+		}
 	}
 
 	// 2. 联合分析规则评估
 	if len(e.crossAnalysisRules) > 0 {
-		crossFindings := e.evaluateCrossAnalysis(groups, trends)
+		crossFindings, outcomes := e.evaluateCrossAnalysis(groups, trends)
 		findings = append(findings, crossFindings...)
+		for i, outcome := range outcomes {
+			stats.record(e.crossAnalysisRules[i].ID, outcome)
+		}
 	}
 
 	// 3. 去重：合并相同 RuleID 的发现，避免信息冗余
 	findings = e.deduplicateFindings(findings)
 
-	return findings
+	return findings, stats
+}
+
+// maxOutcome 返回两个评估结果中较好的一个
+func maxOutcome(a, b ruleOutcome) ruleOutcome {
+	if a > b {
+		return a
+	}
+	return b
 }
 
 // deduplicateFindings 去重发现，合并相同或相似的发现
@@ -165,8 +395,10 @@ func (e *Engine) deduplicateFindings(findings []Finding) []Finding {
 	}
 
 	// 然后处理单类型规则
+	// 单文件规则的发现按文件区分，同一规则在其他文件上的发现不参与关键词去重
+	seenFileRules := make(map[string]bool)
 	for _, finding := range singleFindings {
-		key := finding.RuleID + ":" + finding.Title
+		key := finding.RuleID + ":" + finding.Title + ":" + finding.File
 		if seen[key] {
 			continue
 		}
@@ -175,7 +407,7 @@ func (e *Engine) deduplicateFindings(findings []Finding) []Finding {
 		titleKeyword := extractTitleKeyword(finding.Title)
 		// 如果联合分析规则已经覆盖了这个关键词，跳过单类型规则
 		// 但如果是不同类型的问题（如 goroutine 和 memory 分开报告），则保留
-		if titleKeyword != "" && seenTitleKeywords[titleKeyword] {
+		if titleKeyword != "" && seenTitleKeywords[titleKeyword] && !(finding.File != "" && seenFileRules[finding.RuleID]) {
 			// 检查是否有对应的联合分析规则已经覆盖
 			// 如果联合分析规则已经报告了 goroutine+memory 的问题，
 			// 则跳过单独的 goroutine 或 memory 规则
@@ -186,26 +418,33 @@ func (e *Engine) deduplicateFindings(findings []Finding) []Finding {
 		if titleKeyword != "" {
 			seenTitleKeywords[titleKeyword] = true
 		}
+		if finding.File != "" {
+			seenFileRules[finding.RuleID] = true
+		}
 		result = append(result, finding)
 	}
 
 	return result
 }
 
+// titleKeywordPatterns 标题关键词映射，将相似的标题归类
+// 使用有序列表而不是 map，标题匹配多个关键词时结果稳定
+var titleKeywordPatterns = []struct {
+	keyword  string
+	patterns []string
+}{
+	{"memory_leak", []string{"内存增长", "内存泄漏", "memory leak", "memory growth"}},
+	{"goroutine_leak", []string{"goroutine", "协程泄漏", "协程增长"}},
+	{"cpu_hotspot", []string{"cpu", "热点函数", "cpu hotspot"}},
+}
+
 // extractTitleKeyword 提取标题的核心关键词用于相似性检测
 func extractTitleKeyword(title string) string {
-	// 定义关键词映射，将相似的标题归类
-	keywordPatterns := map[string][]string{
-		"memory_leak":    {"内存增长", "内存泄漏", "memory leak", "memory growth"},
-		"goroutine_leak": {"goroutine", "协程泄漏", "协程增长"},
-		"cpu_hotspot":    {"cpu", "热点函数", "cpu hotspot"},
-	}
-
 	titleLower := toLowerString(title)
-	for keyword, patterns := range keywordPatterns {
-		for _, pattern := range patterns {
+	for _, kp := range titleKeywordPatterns {
+		for _, pattern := range kp.patterns {
 			if containsString(titleLower, toLowerString(pattern)) {
-				return keyword
+				return kp.keyword
 			}
 		}
 	}
@@ -214,18 +453,12 @@ func extractTitleKeyword(title string) string {
 
 // extractAllTitleKeywords 提取标题中的所有关键词（用于联合分析规则）
 func extractAllTitleKeywords(title string) []string {
-	keywordPatterns := map[string][]string{
-		"memory_leak":    {"内存增长", "内存泄漏", "memory leak", "memory growth"},
-		"goroutine_leak": {"goroutine", "协程泄漏", "协程增长"},
-		"cpu_hotspot":    {"cpu", "热点函数", "cpu hotspot"},
-	}
-
 	titleLower := toLowerString(title)
 	var keywords []string
-	for keyword, patterns := range keywordPatterns {
-		for _, pattern := range patterns {
+	for _, kp := range titleKeywordPatterns {
+		for _, pattern := range kp.patterns {
 			if containsString(titleLower, toLowerString(pattern)) {
-				keywords = append(keywords, keyword)
+				keywords = append(keywords, kp.keyword)
 				break // 每个关键词只添加一次
 			}
 		}
@@ -263,9 +496,10 @@ func containsString(s, substr string) bool {
 	return false
 }
 
-// evaluateCrossAnalysis 评估联合分析规则
-func (e *Engine) evaluateCrossAnalysis(groups []analyzer.ProfileGroup, trends map[string]*analyzer.GroupTrends) []Finding {
+// evaluateCrossAnalysis 评估联合分析规则，同时返回每条规则的评估结果
+func (e *Engine) evaluateCrossAnalysis(groups []analyzer.ProfileGroup, trends map[string]*analyzer.GroupTrends) ([]Finding, []ruleOutcome) {
 	var findings []Finding
+	outcomes := make([]ruleOutcome, len(e.crossAnalysisRules))
 
 	// 构建 group 类型到 group 的映射
 	groupMap := make(map[string]analyzer.ProfileGroup)
@@ -273,33 +507,38 @@ func (e *Engine) evaluateCrossAnalysis(groups []analyzer.ProfileGroup, trends ma
 		groupMap[g.Type] = g
 	}
 
-	for _, rule := range e.crossAnalysisRules {
+	for i, rule := range e.crossAnalysisRules {
 		// 检查所有需要的 profile 类型是否都存在
-		allTypesPresent := true
+		outcomes[i] = outcomeNotMatched
 		for profileType := range rule.Conditions {
-			if _, exists := groupMap[profileType]; !exists {
-				allTypesPresent = false
+			group, exists := groupMap[profileType]
+			if !exists {
+				outcomes[i] = outcomeSkippedType
 				break
 			}
-			if _, exists := trends[profileType]; !exists {
-				allTypesPresent = false
-				break
+			if _, exists := trends[profileType]; !exists || len(group.Files) < e.minPoints() {
+				outcomes[i] = outcomeSkippedData
 			}
 		}
 
-		if !allTypesPresent {
+		// 缺少类型、趋势或快照数不足时跳过 (见 evaluateCrossCondition)
+		if outcomes[i] != outcomeNotMatched {
 			continue
 		}
 
 		// 评估每个类型的条件
 		allConditionsMet := true
 		matchedTrends := make(map[string]*analyzer.TrendMetrics)
+		minR2 := rule.MinR2
+		if minR2 == 0 {
+			minR2 = DefaultCrossAnalysisMinR2
+		}
 
 		for profileType, condition := range rule.Conditions {
 			group := groupMap[profileType]
 			groupTrends := trends[profileType]
 
-			if !e.evaluateCrossCondition(condition, profileType, group, groupTrends, matchedTrends) {
+			if !e.evaluateCrossCondition(condition, profileType, group, groupTrends, minR2, matchedTrends) {
 				allConditionsMet = false
 				break
 			}
@@ -310,7 +549,7 @@ func (e *Engine) evaluateCrossAnalysis(groups []analyzer.ProfileGroup, trends ma
 		}
 
 		// 检查关联条件
-		if rule.Correlation != "" && !e.checkCorrelation(rule.Correlation, matchedTrends) {
+		if rule.Correlation != "" && !e.checkCorrelation(rule.Correlation, matchedTrends, groupMap) {
 			continue
 		}
 
@@ -321,39 +560,51 @@ func (e *Engine) evaluateCrossAnalysis(groups []analyzer.ProfileGroup, trends ma
 				RuleName:        rule.Name,
 				Severity:        action.Severity,
 				Title:           action.Title,
-				Evidence:        e.buildCrossEvidence(action.EvidenceTemplate, trends, groupMap),
+				Evidence:        e.buildCrossEvidence(action, trends, groupMap),
 				Suggestions:     action.Suggestions,
 				IsCrossAnalysis: true,
+				ProfileTypes:    crossProfileTypes(rule),
 			}
 			findings = append(findings, finding)
 		}
+		outcomes[i] = outcomeMatched
 	}
 
-	return findings
+	return findings, outcomes
+}
+
+// crossProfileTypes 返回联合分析规则涉及的 profile 类型 (有序)
+func crossProfileTypes(rule CrossAnalysisRule) []string {
+	types := make([]string, 0, len(rule.Conditions))
+	for profileType := range rule.Conditions {
+		types = append(types, profileType)
+	}
+	sort.Strings(types)
+	return types
 }
 
 // evaluateCrossCondition 评估联合分析中单个类型的条件
-func (e *Engine) evaluateCrossCondition(condition string, profileType string, group analyzer.ProfileGroup, trends *analyzer.GroupTrends, matchedTrends map[string]*analyzer.TrendMetrics) bool {
+func (e *Engine) evaluateCrossCondition(condition string, profileType string, group analyzer.ProfileGroup, trends *analyzer.GroupTrends, minR2 float64, matchedTrends map[string]*analyzer.TrendMetrics) bool {
 	if trends == nil {
 		return false
 	}
 
-	// 需要至少 3 个文件才能做趋势分析
-	if len(group.Files) < 3 {
+	// 需要足够的文件才能做趋势分析
+	if len(group.Files) < e.minPoints() {
 		return false
 	}
 
 	switch profileType {
 	case "heap":
 		if trends.HeapInuse != nil {
-			if e.evaluateTrendCondition(condition, trends.HeapInuse) {
+			if e.evaluateTrendCondition(condition, trends.HeapInuse, minR2) {
 				matchedTrends["heap"] = trends.HeapInuse
 				return true
 			}
 		}
 	case "goroutine":
 		if trends.GoroutineCount != nil {
-			if e.evaluateTrendCondition(condition, trends.GoroutineCount) {
+			if e.evaluateTrendCondition(condition, trends.GoroutineCount, minR2) {
 				matchedTrends["goroutine"] = trends.GoroutineCount
 				return true
 			}
@@ -371,7 +622,8 @@ func (e *Engine) evaluateCrossCondition(condition string, profileType string, gr
 }
 
 // evaluateTrendCondition 评估趋势条件
-func (e *Engine) evaluateTrendCondition(condition string, trend *analyzer.TrendMetrics) bool {
+// minR2 为判定趋势显著的 R² 门槛
+func (e *Engine) evaluateTrendCondition(condition string, trend *analyzer.TrendMetrics, minR2 float64) bool {
 	// 解析条件中的关键词
 
 	// 检查方向条件
@@ -388,13 +640,13 @@ func (e *Engine) evaluateTrendCondition(condition string, trend *analyzer.TrendM
 
 	// 检查斜率条件
 	if contains(condition, "slope > 0") {
-		if trend.Slope <= 0 || trend.R2 < 0.7 {
+		if trend.Slope <= 0 || trend.R2 < minR2 {
 			return false
 		}
 	}
 	if contains(condition, "slope <= 0") {
 		// 斜率小于等于0，或者 R² 太低（趋势不明显）
-		if trend.Slope > 0 && trend.R2 > 0.7 {
+		if trend.Slope > 0 && trend.R2 > minR2 {
 			return false
 		}
 	}
@@ -406,7 +658,7 @@ func (e *Engine) evaluateTrendCondition(condition string, trend *analyzer.TrendM
 
 	// 如果只是检查 slope 存在（没有比较符号）
 	if contains(condition, "slope") && !contains(condition, "slope >") && !contains(condition, "slope <") && !contains(condition, "slope =") {
-		if trend.R2 < 0.7 {
+		if trend.R2 < minR2 {
 			return false
 		}
 	}
@@ -415,22 +667,39 @@ func (e *Engine) evaluateTrendCondition(condition string, trend *analyzer.TrendM
 }
 
 // checkCorrelation 检查关联条件
-func (e *Engine) checkCorrelation(correlation string, matchedTrends map[string]*analyzer.TrendMetrics) bool {
+func (e *Engine) checkCorrelation(correlation string, matchedTrends map[string]*analyzer.TrendMetrics, groupMap map[string]analyzer.ProfileGroup) bool {
 	switch correlation {
-	case "same_direction":
-		// 检查所有趋势方向是否一致
+	case CorrelationSameDirection:
+		// 至少两条有方向的趋势 (跳过 CPU 等 present)，且方向全部一致；只有一条趋势时无从比较
 		var direction string
+		directional := 0
 		for _, trend := range matchedTrends {
 			if trend.Direction == "present" {
-				continue // 跳过没有方向的（如 CPU）
+				continue
 			}
-			if direction == "" {
-				direction = trend.Direction
-			} else if direction != trend.Direction {
+			if direction != "" && direction != trend.Direction {
 				return false
 			}
+			direction = trend.Direction
+			directional++
 		}
-		return direction != ""
+		return directional >= 2
+
+	case CorrelationInverseDirection:
+		// 一升一降：有方向的趋势中既有增长也有下降，且没有平稳的趋势
+		var increasing, decreasing int
+		for _, trend := range matchedTrends {
+			switch trend.Direction {
+			case "present":
+			case "increasing":
+				increasing++
+			case "decreasing":
+				decreasing++
+			default:
+				return false
+			}
+		}
+		return increasing > 0 && decreasing > 0
 
 	case "both_increasing":
 		// 检查是否都在增长
@@ -445,6 +714,18 @@ func (e *Engine) checkCorrelation(correlation string, matchedTrends map[string]*
 		// 时间相关性检查（简化版：只要同时存在数据就认为相关）
 		return len(matchedTrends) >= 2
 
+	case CorrelationChannelBacklog:
+		// 不只看总量方向，还要求增长集中在 channel 上
+		return channelBacklog(groupMap) != nil
+
+	case CorrelationGoroutineFanout:
+		result := allocsPerGoroutine(groupMap)
+		return result != nil && result.Mode == analyzer.AllocGrowthMoreGoroutines
+
+	case CorrelationPerGoroutineAllocation:
+		result := allocsPerGoroutine(groupMap)
+		return result != nil && result.Mode == analyzer.AllocGrowthPerGoroutine
+
 	default:
 		// 未知关联类型，默认通过
 		return true
@@ -452,51 +733,112 @@ func (e *Engine) checkCorrelation(correlation string, matchedTrends map[string]*
 }
 
 // buildCrossEvidence 构建联合分析的证据
-func (e *Engine) buildCrossEvidence(template map[string]string, trends map[string]*analyzer.GroupTrends, groupMap map[string]analyzer.ProfileGroup) map[string]string {
-	if template == nil {
+func (e *Engine) buildCrossEvidence(action Action, trends map[string]*analyzer.GroupTrends, groupMap map[string]analyzer.ProfileGroup) Evidence {
+	if action.EvidenceTemplate == nil {
 		return nil
 	}
 
-	evidence := make(map[string]string)
-	for key, tmpl := range template {
-		value := tmpl
+	vars := make(map[string]evidenceVar)
 
-		// 替换 heap 相关变量
-		if heapTrends, ok := trends["heap"]; ok && heapTrends != nil && heapTrends.HeapInuse != nil {
-			heapGroup := groupMap["heap"]
-			durationMinutes := e.calculateDurationMinutes(heapGroup)
+	// heap 相关变量
+	if heapTrends, ok := trends["heap"]; ok && heapTrends != nil && heapTrends.HeapInuse != nil {
+		heapGroup := groupMap["heap"]
+		durationMinutes := e.calculateDurationMinutes(heapGroup)
 
-			slopePerMinute := 0.0
-			if durationMinutes > 0 && len(heapGroup.Files) > 1 {
-				totalChange := heapTrends.HeapInuse.Slope * float64(len(heapGroup.Files)-1)
-				slopePerMinute = (totalChange / durationMinutes) / (1024 * 1024)
-			}
-
-			value = strings.ReplaceAll(value, "{{.heap_slope}}", formatMemoryRate(slopePerMinute))
-			value = strings.ReplaceAll(value, "{{.heap_r2}}", fmt.Sprintf("%.2f", heapTrends.HeapInuse.R2))
-			value = strings.ReplaceAll(value, "{{.heap_direction}}", heapTrends.HeapInuse.Direction)
+		bytesPerMinute := 0.0
+		if durationMinutes > 0 && len(heapGroup.Files) > 1 {
+			totalChange := heapTrends.HeapInuse.Slope * float64(len(heapGroup.Files)-1)
+			bytesPerMinute = totalChange / durationMinutes
 		}
 
-		// 替换 goroutine 相关变量
-		if goroutineTrends, ok := trends["goroutine"]; ok && goroutineTrends != nil && goroutineTrends.GoroutineCount != nil {
-			goroutineGroup := groupMap["goroutine"]
-			durationMinutes := e.calculateDurationMinutes(goroutineGroup)
+		vars["heap_slope"] = numberVar(formatMemoryRate(bytesPerMinute/(1024*1024)), bytesPerMinute, "bytes/min")
+		vars["heap_r2"] = numberVar(fmt.Sprintf("%.2f", heapTrends.HeapInuse.R2), heapTrends.HeapInuse.R2, "")
+		vars["heap_direction"] = textVar(heapTrends.HeapInuse.Direction)
+	}
 
-			slopePerMinute := 0.0
-			if durationMinutes > 0 && len(goroutineGroup.Files) > 1 {
-				totalChange := goroutineTrends.GoroutineCount.Slope * float64(len(goroutineGroup.Files)-1)
-				slopePerMinute = totalChange / durationMinutes
-			}
+	// goroutine 相关变量
+	if goroutineTrends, ok := trends["goroutine"]; ok && goroutineTrends != nil && goroutineTrends.GoroutineCount != nil {
+		goroutineGroup := groupMap["goroutine"]
+		durationMinutes := e.calculateDurationMinutes(goroutineGroup)
+
+		slopePerMinute := 0.0
+		if durationMinutes > 0 && len(goroutineGroup.Files) > 1 {
+			totalChange := goroutineTrends.GoroutineCount.Slope * float64(len(goroutineGroup.Files)-1)
+			slopePerMinute = totalChange / durationMinutes
+		}
+
+		vars["goroutine_slope"] = numberVar(fmt.Sprintf("%.2f", slopePerMinute), slopePerMinute, "goroutines/min")
+		vars["goroutine_r2"] = numberVar(fmt.Sprintf("%.2f", goroutineTrends.GoroutineCount.R2), goroutineTrends.GoroutineCount.R2, "")
+		vars["goroutine_direction"] = textVar(goroutineTrends.GoroutineCount.Direction)
+	}
 
-			value = strings.ReplaceAll(value, "{{.goroutine_slope}}", fmt.Sprintf("%.2f", slopePerMinute))
-			value = strings.ReplaceAll(value, "{{.goroutine_r2}}", fmt.Sprintf("%.2f", goroutineTrends.GoroutineCount.R2))
-			value = strings.ReplaceAll(value, "{{.goroutine_direction}}", goroutineTrends.GoroutineCount.Direction)
+	// channel 积压相关变量
+	if backlog := channelBacklog(groupMap); backlog != nil {
+		var sendSites, allocSites []string
+		for _, site := range backlog.SendSites {
+			sendSites = append(sendSites, fmt.Sprintf("%s (%d 个)", site.Caller, site.Count))
 		}
+		for _, site := range backlog.ChannelSites {
+			allocSites = append(allocSites, fmt.Sprintf("%s (+%s)", site.Caller, analyzer.FormatBytes(site.Growth)))
+		}
+		vars["blocked_sends"] = textVar(fmt.Sprintf("%d (+%d)", backlog.BlockedSends, backlog.BlockedSendsGrowth))
+		vars["channel_send_sites"] = textVar(strings.Join(sendSites, ", "))
+		vars["channel_growth"] = numberVar(analyzer.FormatBytes(backlog.ChannelInuseGrowth), float64(backlog.ChannelInuseGrowth), "bytes")
+		vars["channel_alloc_sites"] = textVar(strings.Join(allocSites, ", "))
+	}
 
-		evidence[key] = value
+	// 分配速率与 goroutine 数关联的变量
+	if allocRate := allocsPerGoroutine(groupMap); allocRate != nil {
+		first, last := allocRate.Points[0], allocRate.Points[len(allocRate.Points)-1]
+		vars["alloc_rate"] = textVar(fmt.Sprintf("%s → %s (%s)",
+			formatByteRate(first.AllocRate), formatByteRate(last.AllocRate), formatGrowthRatio(allocRate.RateGrowth)))
+		vars["goroutine_growth"] = textVar(fmt.Sprintf("%d → %d (%s)",
+			first.Goroutines, last.Goroutines, formatGrowthRatio(allocRate.GoroutineGrowth)))
+		vars["allocs_per_goroutine"] = textVar(formatPerGoroutineSeries(allocRate.Points))
+		vars["per_goroutine_growth"] = numberVar(formatGrowthRatio(allocRate.PerGoroutineGrowth), allocRate.PerGoroutineGrowth, "ratio")
+	}
+
+	return renderEvidence(action.EvidenceTemplate, action.EvidenceOrder, vars)
+}
+
+// channelBacklog 返回 goroutine 与 heap 快照中的 channel 积压，任一类型缺失时返回 nil
+func channelBacklog(groupMap map[string]analyzer.ProfileGroup) *analyzer.ChannelBacklog {
+	goroutineGroup, ok := groupMap["goroutine"]
+	if !ok {
+		return nil
+	}
+	heapGroup, ok := groupMap["heap"]
+	if !ok {
+		return nil
 	}
+	return analyzer.DetectChannelBacklog(goroutineGroup.Files, heapGroup.Files, analyzer.DefaultChannelBacklogMinBlocked)
+}
+
+// allocsPerGoroutine 返回 heap 分配速率与 goroutine 数的关联结果，任一类型缺失或速率没有明显增长时返回 nil
+func allocsPerGoroutine(groupMap map[string]analyzer.ProfileGroup) *analyzer.AllocsPerGoroutine {
+	heapGroup, ok := groupMap["heap"]
+	if !ok {
+		return nil
+	}
+	goroutineGroup, ok := groupMap["goroutine"]
+	if !ok {
+		return nil
+	}
+	return analyzer.AnalyzeAllocsPerGoroutine(heapGroup.Files, goroutineGroup.Files, analyzer.DefaultAllocRateMinGrowth)
+}
+
+// formatByteRate 格式化字节速率，如 "1.50 MB/s"
+func formatByteRate(bytesPerSecond float64) string {
+	return analyzer.FormatBytes(int64(math.Round(bytesPerSecond))) + "/s"
+}
 
-	return evidence
+// formatPerGoroutineSeries 格式化每个 goroutine 的分配速率序列，如 "10.00 KB/s → 12.00 KB/s → 20.00 KB/s"
+func formatPerGoroutineSeries(points []analyzer.AllocRatePoint) string {
+	rates := make([]string, len(points))
+	for i, point := range points {
+		rates[i] = formatByteRate(point.PerGoroutine)
+	}
+	return strings.Join(rates, " → ")
 }
 
 // calculateDurationMinutes 计算 profile 组的时间跨度（分钟）
@@ -513,6 +855,19 @@ func (e *Engine) calculateDurationMinutes(group analyzer.ProfileGroup) float64 {
 	return minutes
 }
 
+// SetMinTrendPoints 设置趋势条件所需的最少快照数，应与计算趋势时的 TrendConfig.MinPoints 一致
+// n <= 0 时恢复默认值 analyzer.DefaultMinTrendPoints
+func (e *Engine) SetMinTrendPoints(n int) {
+	if e != nil {
+		e.minTrendPoints = n
+	}
+}
+
+// minPoints 返回趋势条件所需的最少快照数
+func (e *Engine) minPoints() int {
+	return analyzer.MinTrendPoints(e.minTrendPoints)
+}
+
 // matchesProfileType 检查规则是否匹配指定的 profile 类型
 func (e *Engine) matchesProfileType(rule Rule, profileType string) bool {
 	for _, pt := range rule.ProfileTypes {
@@ -523,46 +878,103 @@ func (e *Engine) matchesProfileType(rule Rule, profileType string) bool {
 	return false
 }
 
-// evaluateCondition 评估规则条件（简化版实现）
-func (e *Engine) evaluateCondition(condition string, group analyzer.ProfileGroup, trends *analyzer.GroupTrends) bool {
-	// 简化版条件评估：检查趋势是否存在且显著
-	// 完整版应该实现表达式解析器
+// evaluateCondition 评估规则条件
+// 命名条件 (如 profile_exists) 直接计算，其余条件为加载时解析的表达式 (如 "heap_inuse.slope > 5 && heap_inuse.r2 > 0.9")，
+// 无法解析的条件按旧版关键字匹配评估
+func (e *Engine) evaluateCondition(rule Rule, group analyzer.ProfileGroup, trends *analyzer.GroupTrends) bool {
+	condition := rule.Condition
 
 	// CPU 热点分析：只要有 CPU profile 文件就触发
 	if condition == "cpu_profile_exists" && group.Type == "cpu" {
 		return len(group.Files) > 0
 	}
 
-	if trends == nil {
-		return false
+	// 任意类型的热点分析：只要有 profile 文件就触发，适用类型由 profile_types 限定
+	if condition == ConditionProfileExists {
+		return len(group.Files) > 0
 	}
 
-	// 检查内存增长趋势
-	if trends.HeapInuse != nil && trends.HeapInuse.R2 > 0.85 && trends.HeapInuse.Slope > 10.0 {
-		if contains(condition, "heap_inuse") && contains(condition, "slope") {
-			// 额外检查：确保有足够的文件数量进行趋势分析
-			if len(group.Files) >= 3 {
-				return true
-			}
-		}
+	// inuse/alloc 背离：单个 heap profile 即可判断，不需要时间序列
+	if condition == ConditionInuseAllocDivergence && group.Type == "heap" {
+		return len(retainedPackages(group)) > 0
 	}
 
-	// 检查 goroutine 增长趋势
-	if trends.GoroutineCount != nil && trends.GoroutineCount.R2 > 0.9 && trends.GoroutineCount.Slope > 1.0 {
-		if contains(condition, "goroutine_count") && contains(condition, "slope") {
-			// 额外检查：确保有足够的文件数量进行趋势分析
-			if len(group.Files) >= 3 {
-				return true
-			}
+	// string/[]byte 转换热点：单个 heap profile 即可判断
+	if condition == ConditionConversionHotspot && group.Type == "heap" {
+		return len(conversionHotspots(group)) > 0
+	}
+
+	// 反射热点：单个 cpu 或 heap profile 即可判断
+	if condition == ConditionReflectionHotspot && (group.Type == "cpu" || group.Type == "heap") {
+		return len(reflectionHotspots(group)) > 0
+	}
+
+	// GC 辅助标记停顿：单个 cpu profile 即可判断
+	if condition == ConditionGCAssistStall && group.Type == "cpu" {
+		return len(gcAssistStalls(group)) > 0
+	}
+
+	// 超大单次分配：单个 heap profile 即可判断
+	if condition == ConditionOversizedAllocation && group.Type == "heap" {
+		return len(oversizedAllocations(group)) > 0
+	}
+
+	// 新进入 Top-N：比较最新快照与更早快照的函数排名，不依赖趋势拟合
+	if condition == ConditionNewTopFunction {
+		return len(newTopFunctions(group)) > 0
+	}
+
+	// 表达式条件：加载规则时已解析，无法解析的旧版条件按关键字匹配
+	if rule.expr == nil {
+		return e.evaluateLegacyCondition(rule, group, trends)
+	}
+	return meetsMinR2(rule, trends) && rule.expr.eval(conditionEnv{group: group, trends: trends})
+}
+
+// meetsMinR2 规则配置了 min_r2 时，表达式引用的每个趋势的 R² 都必须高于门槛
+func meetsMinR2(rule Rule, trends *analyzer.GroupTrends) bool {
+	if rule.MinR2 == 0 {
+		return true
+	}
+	for _, metric := range rule.expr.trendMetrics() {
+		if trends == nil {
+			return false
+		}
+		trend := conditionTrends[metric](trends)
+		if trend == nil || trend.R2 <= rule.MinR2 {
+			return false
 		}
 	}
+	return true
+}
 
+// compileCondition 解析规则的表达式条件并缓存到规则上，命名条件不需要解析
+// 解析失败时 expr 保持为 nil，规则按旧版关键字匹配评估
+func compileCondition(rule *Rule) error {
+	if isNamedCondition(rule.Condition) {
+		return nil
+	}
+	expr, err := parseCondition(rule.Condition)
+	if err != nil {
+		return fmt.Errorf("invalid condition '%s': %w", rule.Condition, err)
+	}
+	rule.expr = expr
+	return nil
+}
+
+// isNamedCondition 条件是否为内置的命名条件 (如 profile_exists)
+func isNamedCondition(condition string) bool {
+	switch condition {
+	case "cpu_profile_exists", ConditionProfileExists, ConditionInuseAllocDivergence, ConditionConversionHotspot,
+		ConditionReflectionHotspot, ConditionGCAssistStall, ConditionOversizedAllocation, ConditionNewTopFunction:
+		return true
+	}
 	return false
 }
 
 // buildEvidence 构建证据数据，替换模板变量
-func (e *Engine) buildEvidence(template map[string]string, trends *analyzer.GroupTrends, group analyzer.ProfileGroup) map[string]string {
-	if template == nil || trends == nil {
+func (e *Engine) buildEvidence(action Action, trends *analyzer.GroupTrends, group analyzer.ProfileGroup) Evidence {
+	if action.EvidenceTemplate == nil || trends == nil {
 		return nil
 	}
 
@@ -577,55 +989,291 @@ func (e *Engine) buildEvidence(template map[string]string, trends *analyzer.Grou
 		}
 	}
 
-	evidence := make(map[string]string)
-	for key, tmpl := range template {
-		value := tmpl
+	// perMinute 将每个样本点的斜率换算为每分钟的变化量
+	// 计算方式：斜率 * (样本数-1) / 时间(分钟)
+	perMinute := func(slope float64) float64 {
+		if durationMinutes > 0 && len(group.Files) > 1 {
+			return slope * float64(len(group.Files)-1) / durationMinutes
+		}
+		return 0
+	}
 
-		// 替换堆内存趋势相关变量
-		if trends.HeapInuse != nil {
-			// 斜率单位是 bytes/样本点，转换为 MB/分钟
-			// 计算方式：(斜率 * 样本数) / 时间(分钟) / (1024*1024)
-			slopePerMinute := 0.0
-			if durationMinutes > 0 && len(group.Files) > 1 {
-				// 总变化量 = 斜率 * (样本数-1)
-				totalChange := trends.HeapInuse.Slope * float64(len(group.Files)-1)
-				// 转换为 MB/分钟
-				slopePerMinute = (totalChange / durationMinutes) / (1024 * 1024)
-			}
-			value = strings.ReplaceAll(value, "{{.slope}}", formatMemoryRate(slopePerMinute))
-			value = strings.ReplaceAll(value, "{{.r2}}", fmt.Sprintf("%.2f", trends.HeapInuse.R2))
-			value = strings.ReplaceAll(value, "{{.direction}}", trends.HeapInuse.Direction)
+	vars := map[string]evidenceVar{
+		"file_count": countVar(len(group.Files), "files"),
+	}
+
+	// 堆内存趋势相关变量，展示时转换为 MB/分钟 等合适的单位
+	if trends.HeapInuse != nil {
+		bytesPerMinute := perMinute(trends.HeapInuse.Slope)
+		vars["slope"] = numberVar(formatMemoryRate(bytesPerMinute/(1024*1024)), bytesPerMinute, "bytes/min")
+		vars["r2"] = numberVar(fmt.Sprintf("%.2f", trends.HeapInuse.R2), trends.HeapInuse.R2, "")
+		vars["direction"] = textVar(trends.HeapInuse.Direction)
+	}
+
+	// 堆对象数趋势相关变量
+	if trends.HeapInuseObjects != nil {
+		objectsPerMinute := perMinute(trends.HeapInuseObjects.Slope)
+		objectGrowth := heapGrowthRatio(group, analyzer.HeapSampleInuseObjects)
+		spaceGrowth := heapGrowthRatio(group, analyzer.HeapSampleInuseSpace)
+		vars["object_slope"] = numberVar(fmt.Sprintf("%.0f", objectsPerMinute), objectsPerMinute, "objects/min")
+		vars["object_r2"] = numberVar(fmt.Sprintf("%.2f", trends.HeapInuseObjects.R2), trends.HeapInuseObjects.R2, "")
+		vars["object_growth"] = numberVar(formatGrowthRatio(objectGrowth), objectGrowth, "ratio")
+		vars["space_growth"] = numberVar(formatGrowthRatio(spaceGrowth), spaceGrowth, "ratio")
+	}
+
+	// Goroutine 趋势相关变量，斜率转换为 个/分钟
+	if trends.GoroutineCount != nil {
+		goroutinesPerMinute := perMinute(trends.GoroutineCount.Slope)
+		vars["goroutine_slope"] = numberVar(fmt.Sprintf("%.2f", goroutinesPerMinute), goroutinesPerMinute, "goroutines/min")
+		vars["goroutine_r2"] = numberVar(fmt.Sprintf("%.2f", trends.GoroutineCount.R2), trends.GoroutineCount.R2, "")
+		vars["goroutine_direction"] = textVar(trends.GoroutineCount.Direction)
+	}
+
+	// 时间范围相关变量
+	if len(group.Files) > 1 {
+		first := group.Files[0].Time
+		last := group.Files[len(group.Files)-1].Time
+		duration := last.Sub(first)
+		vars["duration"] = numberVar(formatDuration(duration), duration.Seconds(), "s")
+		vars["start_time"] = textVar(first.Format(time.RFC3339))
+		vars["end_time"] = textVar(last.Format(time.RFC3339))
+	}
+
+	return renderEvidence(action.EvidenceTemplate, action.EvidenceOrder, vars)
+}
+
+// heapGrowthRatio 返回组内最新快照相对最早快照的相对增长 ((最新-最早)/最早)，最早值为 0 时返回 0
+func heapGrowthRatio(group analyzer.ProfileGroup, sampleType string) float64 {
+	var first, last *analyzer.ProfileMetrics
+	for _, file := range group.Files {
+		if file.Metrics == nil {
+			continue
 		}
+		if first == nil {
+			first = file.Metrics
+		}
+		last = file.Metrics
+	}
+	if first == nil {
+		return 0
+	}
+	base := analyzer.HeapSampleValue(first, sampleType)
+	if base == 0 {
+		return 0
+	}
+	return float64(analyzer.HeapSampleValue(last, sampleType)-base) / float64(base)
+}
 
-		// 替换 Goroutine 趋势相关变量
-		if trends.GoroutineCount != nil {
-			// Goroutine 斜率转换为 个/分钟
-			slopePerMinute := 0.0
-			if durationMinutes > 0 && len(group.Files) > 1 {
-				totalChange := trends.GoroutineCount.Slope * float64(len(group.Files)-1)
-				slopePerMinute = totalChange / durationMinutes
-			}
-			value = strings.ReplaceAll(value, "{{.goroutine_slope}}", fmt.Sprintf("%.2f", slopePerMinute))
-			value = strings.ReplaceAll(value, "{{.goroutine_r2}}", fmt.Sprintf("%.2f", trends.GoroutineCount.R2))
-			value = strings.ReplaceAll(value, "{{.goroutine_direction}}", trends.GoroutineCount.Direction)
+// cpuUsageGrowth 计算最新快照的 CPU 使用率 (CPU 时间 / 采集时长) 相对最早快照的增长比例
+// 跳过缺少采集时长的快照，少于两个可比较的快照或最早快照的使用率为 0 时返回 false
+func cpuUsageGrowth(group analyzer.ProfileGroup) (float64, bool) {
+	var usages []float64
+	for _, file := range group.Files {
+		m := file.Metrics
+		if m == nil || m.NoDuration || m.Duration <= 0 {
+			continue
+		}
+		usages = append(usages, float64(m.CPUTime)/float64(m.Duration))
+	}
+	if len(usages) < 2 || usages[0] == 0 {
+		return 0, false
+	}
+	return (usages[len(usages)-1] - usages[0]) / usages[0], true
+}
+
+// formatGrowthRatio 将相对增长格式化为带符号的百分比
+func formatGrowthRatio(ratio float64) string {
+	return fmt.Sprintf("%+.1f%%", ratio*100)
+}
+
+// retainedPackages 返回组内最新 heap profile 中保留率异常高的包
+func retainedPackages(group analyzer.ProfileGroup) []analyzer.PackageRetention {
+	if len(group.Files) == 0 {
+		return nil
+	}
+	latest := group.Files[len(group.Files)-1]
+	return analyzer.DetectRetainedPackages(latest.Metrics, analyzer.DefaultRetentionConfig())
+}
+
+// buildRetentionEvidence 构建 inuse/alloc 背离的证据数据
+// 支持 {{.retained_packages}}、{{.retained_count}} 和 {{.file_count}}
+func (e *Engine) buildRetentionEvidence(action Action, group analyzer.ProfileGroup) Evidence {
+	if action.EvidenceTemplate == nil {
+		return nil
+	}
+
+	retained := retainedPackages(group)
+	parts := make([]string, 0, len(retained))
+	for _, pkg := range retained {
+		parts = append(parts, fmt.Sprintf("%s (%.0f%%, %s / %s)", pkg.Package, pkg.Ratio*100,
+			analyzer.FormatBytes(pkg.InuseSpace), analyzer.FormatBytes(pkg.AllocSpace)))
+	}
+
+	return renderEvidence(action.EvidenceTemplate, action.EvidenceOrder, map[string]evidenceVar{
+		"retained_packages": textVar(strings.Join(parts, ", ")),
+		"retained_count":    countVar(len(retained), "packages"),
+		"file_count":        countVar(len(group.Files), "files"),
+	})
+}
+
+// conversionHotspots 返回组内最新 heap profile 中的 string/[]byte 转换热点
+func conversionHotspots(group analyzer.ProfileGroup) []analyzer.ConversionHotspot {
+	if len(group.Files) == 0 {
+		return nil
+	}
+	latest := group.Files[len(group.Files)-1]
+	return analyzer.DetectConversionHotspots(latest.Metrics, analyzer.DefaultConversionMinShare)
+}
+
+// buildConversionEvidence 构建 string/[]byte 转换热点的证据数据
+// 支持 {{.conversion_share}}、{{.conversion_callers}} 和 {{.file_count}}
+func (e *Engine) buildConversionEvidence(action Action, group analyzer.ProfileGroup) Evidence {
+	if action.EvidenceTemplate == nil {
+		return nil
+	}
+
+	hotspots := conversionHotspots(group)
+	parts := make([]string, 0, len(hotspots))
+	for _, h := range hotspots {
+		parts = append(parts, fmt.Sprintf("%s → %s (%.1f%%, %s)", h.Caller, strings.TrimPrefix(h.RuntimeFunc, "runtime."),
+			h.Share*100, analyzer.FormatBytes(h.AllocSpace)))
+	}
+
+	share := analyzer.ConversionShare(hotspots)
+	return renderEvidence(action.EvidenceTemplate, action.EvidenceOrder, map[string]evidenceVar{
+		"conversion_share":   numberVar(fmt.Sprintf("%.1f%%", share*100), share, "ratio"),
+		"conversion_callers": textVar(strings.Join(parts, ", ")),
+		"file_count":         countVar(len(group.Files), "files"),
+	})
+}
+
+// reflectionHotspots 返回组内最新 profile 中的反射热点
+func reflectionHotspots(group analyzer.ProfileGroup) []analyzer.ReflectionHotspot {
+	if len(group.Files) == 0 {
+		return nil
+	}
+	latest := group.Files[len(group.Files)-1]
+	return analyzer.DetectReflectionHotspots(latest.Metrics, analyzer.DefaultReflectionMinShare)
+}
+
+// buildReflectionEvidence 构建反射热点的证据数据
+// 支持 {{.reflection_share}}、{{.reflection_callers}} 和 {{.file_count}}
+func (e *Engine) buildReflectionEvidence(action Action, group analyzer.ProfileGroup) Evidence {
+	if action.EvidenceTemplate == nil {
+		return nil
+	}
+
+	hotspots := reflectionHotspots(group)
+	parts := make([]string, 0, len(hotspots))
+	for _, h := range hotspots {
+		value := analyzer.FormatBytes(h.Value)
+		if group.Type == "cpu" {
+			value = time.Duration(h.Value).String()
 		}
+		parts = append(parts, fmt.Sprintf("%s (%.1f%%, %s)", h.Caller, h.Share*100, value))
+	}
+
+	share := analyzer.ReflectionShare(hotspots)
+	return renderEvidence(action.EvidenceTemplate, action.EvidenceOrder, map[string]evidenceVar{
+		"reflection_share":   numberVar(fmt.Sprintf("%.1f%%", share*100), share, "ratio"),
+		"reflection_callers": textVar(strings.Join(parts, ", ")),
+		"file_count":         countVar(len(group.Files), "files"),
+	})
+}
+
+// gcAssistStalls 返回组内最新 cpu profile 中触发 GC 辅助标记的分配点
+func gcAssistStalls(group analyzer.ProfileGroup) []analyzer.GCAssistSite {
+	if len(group.Files) == 0 {
+		return nil
+	}
+	latest := group.Files[len(group.Files)-1]
+	return analyzer.DetectGCAssistStalls(latest.Metrics, analyzer.DefaultGCAssistMinShare)
+}
+
+// buildGCAssistEvidence 构建 GC 辅助标记停顿的证据数据
+// 支持 {{.gc_assist_share}}、{{.gc_assist_sites}} 和 {{.file_count}}
+func (e *Engine) buildGCAssistEvidence(action Action, group analyzer.ProfileGroup) Evidence {
+	if action.EvidenceTemplate == nil {
+		return nil
+	}
+
+	sites := gcAssistStalls(group)
+	parts := make([]string, 0, len(sites))
+	for _, s := range sites {
+		parts = append(parts, fmt.Sprintf("%s (%.1f%%, %s)", s.Site, s.Share*100, time.Duration(s.Value)))
+	}
+
+	share := analyzer.GCAssistShare(sites)
+	return renderEvidence(action.EvidenceTemplate, action.EvidenceOrder, map[string]evidenceVar{
+		"gc_assist_share": numberVar(fmt.Sprintf("%.1f%%", share*100), share, "ratio"),
+		"gc_assist_sites": textVar(strings.Join(parts, ", ")),
+		"file_count":      countVar(len(group.Files), "files"),
+	})
+}
+
+// oversizedAllocations 返回组内最新 heap profile 中平均单次分配超大的分配点
+func oversizedAllocations(group analyzer.ProfileGroup) []analyzer.AllocationSite {
+	if len(group.Files) == 0 {
+		return nil
+	}
+	latest := group.Files[len(group.Files)-1]
+	return analyzer.DetectOversizedAllocations(latest.Metrics, analyzer.DefaultOversizedMinAvgSize)
+}
 
-		// 替换时间范围相关变量
-		if len(group.Files) > 1 {
-			first := group.Files[0].Time
-			last := group.Files[len(group.Files)-1].Time
-			duration := last.Sub(first)
-			value = strings.ReplaceAll(value, "{{.duration}}", formatDuration(duration))
-			value = strings.ReplaceAll(value, "{{.start_time}}", first.Format(time.RFC3339))
-			value = strings.ReplaceAll(value, "{{.end_time}}", last.Format(time.RFC3339))
+// buildOversizedEvidence 构建超大单次分配的证据数据
+// 支持 {{.oversized_sites}}、{{.oversized_count}} 和 {{.file_count}}
+func (e *Engine) buildOversizedEvidence(action Action, group analyzer.ProfileGroup) Evidence {
+	if action.EvidenceTemplate == nil {
+		return nil
+	}
+
+	sites := oversizedAllocations(group)
+	parts := make([]string, 0, len(sites))
+	for _, site := range sites {
+		name := site.Caller()
+		if site.BusinessFrame != "" && site.BusinessFrame != site.Function {
+			name = fmt.Sprintf("%s → %s", site.BusinessFrame, site.Function)
 		}
+		parts = append(parts, fmt.Sprintf("%s (约 %s/次, %d 次)", name, analyzer.FormatBytes(site.AvgSize), site.AllocObjects))
+	}
 
-		// 替换文件数量
-		value = strings.ReplaceAll(value, "{{.file_count}}", fmt.Sprintf("%d", len(group.Files)))
+	return renderEvidence(action.EvidenceTemplate, action.EvidenceOrder, map[string]evidenceVar{
+		"oversized_sites": textVar(strings.Join(parts, ", ")),
+		"oversized_count": countVar(len(sites), "sites"),
+		"file_count":      countVar(len(group.Files), "files"),
+	})
+}
+
+// newTopFunctions 返回组内最新快照中新进入 Top-N 的函数
+func newTopFunctions(group analyzer.ProfileGroup) []analyzer.NewTopFunction {
+	return analyzer.DetectNewTopFunctions(group.Files, analyzer.DefaultNewTopConfig())
+}
+
+// buildNewTopEvidence 构建新进入 Top-N 的证据数据
+// 支持 {{.new_top_function}} (排名最高的新函数)、{{.new_top_functions}}、{{.new_top_count}}、
+// {{.top_n}} 和 {{.file_count}}
+func (e *Engine) buildNewTopEvidence(action Action, group analyzer.ProfileGroup) Evidence {
+	if action.EvidenceTemplate == nil {
+		return nil
+	}
 
-		evidence[key] = value
+	functions := newTopFunctions(group)
+	parts := make([]string, 0, len(functions))
+	for _, fn := range functions {
+		parts = append(parts, fmt.Sprintf("#%d %s (%.1f%%)", fn.Rank, fn.Name, fn.FlatPct))
 	}
-	return evidence
+	first := ""
+	if len(functions) > 0 {
+		first = functions[0].Name
+	}
+
+	return renderEvidence(action.EvidenceTemplate, action.EvidenceOrder, map[string]evidenceVar{
+		"new_top_functions": textVar(strings.Join(parts, ", ")),
+		"new_top_function":  textVar(first),
+		"new_top_count":     countVar(len(functions), "functions"),
+		"top_n":             countVar(analyzer.DefaultNewTopN, ""),
+		"file_count":        countVar(len(group.Files), "files"),
+	})
 }
 
 // formatMemoryRate 格式化内存增长速率，自动选择合适的单位
@@ -202,7 +202,7 @@ func TestEngine_MatchesProfileType(t *testing.T) {
 func TestEngine_Evaluate_NoTrends(t *testing.T) {
 	engine := &Engine{
 		rules: []Rule{
-			{
+			compiledRule(Rule{
 				ID:           "test_rule",
 				Name:         "Test Rule",
 				ProfileTypes: []string{"heap"},
@@ -214,7 +214,7 @@ func TestEngine_Evaluate_NoTrends(t *testing.T) {
 						Title:    "Test Finding",
 					},
 				},
-			},
+			}),
 		},
 	}
 
@@ -233,11 +233,11 @@ func TestEngine_Evaluate_NoTrends(t *testing.T) {
 func TestEngine_Evaluate_WithMatchingTrends(t *testing.T) {
 	engine := &Engine{
 		rules: []Rule{
-			{
+			compiledRule(Rule{
 				ID:           "memory_growth",
 				Name:         "Memory Growth",
 				ProfileTypes: []string{"heap"},
-				Condition:    "trends.heap_inuse.slope > 10.0",
+				Condition:    "trends.heap_inuse.slope > 10.0 && trends.heap_inuse.r2 > 0.85",
 				Actions: []Action{
 					{
 						Type:        "report",
@@ -250,7 +250,7 @@ func TestEngine_Evaluate_WithMatchingTrends(t *testing.T) {
 						},
 					},
 				},
-			},
+			}),
 		},
 	}
 
@@ -288,8 +288,8 @@ func TestEngine_Evaluate_WithMatchingTrends(t *testing.T) {
 
 	// 验证证据模板变量被正确替换
 	// 斜率 = 1MB/样本点 * 2个间隔 / 1分钟 = 2MB/分钟
-	assert.Equal(t, "2.00 MB", findings[0].Evidence["斜率"])
-	assert.Equal(t, "0.90", findings[0].Evidence["R²"])
+	assert.Equal(t, "2.00 MB", findings[0].Evidence.Map()["斜率"])
+	assert.Equal(t, "0.90", findings[0].Evidence.Map()["R²"])
 }
 
 // TestEngine_Evaluate_NilEngine 测试 nil 引擎
@@ -317,6 +317,14 @@ func TestLoadDefaultRules(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, engine)
 	assert.True(t, len(engine.rules) > 0, "应该加载至少一条规则")
+	// 默认规则都是命名条件或可解析的表达式，不会回退到关键字匹配
+	assert.Empty(t, engine.Warnings())
+
+	ids := make([]string, 0, len(engine.rules))
+	for _, rule := range engine.rules {
+		ids = append(ids, rule.ID)
+	}
+	assert.Contains(t, ids, "cpu_spike")
 }
 
 // TestEngine_BuildEvidence_TemplateReplacement 测试证据模板变量替换
@@ -348,7 +356,7 @@ func TestEngine_BuildEvidence_TemplateReplacement(t *testing.T) {
 		},
 	}
 
-	evidence := engine.buildEvidence(template, trends, group)
+	evidence := engine.buildEvidence(Action{EvidenceTemplate: template}, trends, group).Map()
 
 	// 斜率 = 5MB/样本点 * 2个间隔 / 1分钟 = 10MB/分钟
 	assert.Equal(t, "10.00 MB/分钟", evidence["内存增长速率"])
@@ -357,15 +365,911 @@ func TestEngine_BuildEvidence_TemplateReplacement(t *testing.T) {
 	assert.Equal(t, "1.0 分钟", evidence["时间范围"])
 }
 
+// TestEngine_BuildEvidence_TypedValues 测试只包含单个数值变量的证据携带原始数值和单位
+func TestEngine_BuildEvidence_TypedValues(t *testing.T) {
+	engine := &Engine{}
+
+	action := Action{
+		EvidenceTemplate: map[string]string{
+			"斜率": "{{.slope}}",
+			"R²": " {{.r2}} ",
+			"时长": "{{.duration}}",
+			"方向": "{{.direction}}",
+			"说明": "{{.r2}} (1.0为完美线性)",
+		},
+		EvidenceOrder: []string{"时长", "斜率", "不存在"},
+	}
+	trends := &analyzer.GroupTrends{
+		HeapInuse: &analyzer.TrendMetrics{Slope: 5 * 1024 * 1024, R2: 0.95, Direction: "increasing"},
+	}
+	now := time.Now()
+	group := analyzer.ProfileGroup{
+		Type: "heap",
+		Files: []analyzer.ProfileFile{
+			{Path: "/test1.pprof", Time: now},
+			{Path: "/test2.pprof", Time: now.Add(30 * time.Second)},
+			{Path: "/test3.pprof", Time: now.Add(60 * time.Second)},
+		},
+	}
+
+	evidence := engine.buildEvidence(action, trends, group)
+
+	// 先按声明顺序，其余的键按名称排序
+	var names []string
+	for _, item := range evidence {
+		names = append(names, item.Name)
+	}
+	assert.Equal(t, []string{"时长", "斜率", "R²", "方向", "说明"}, names)
+
+	slope, ok := evidence.Item("斜率")
+	require.True(t, ok)
+	assert.Equal(t, "10.00 MB", slope.Display)
+	assert.True(t, slope.HasValue)
+	assert.InDelta(t, 10*1024*1024, slope.Value, 0.001)
+	assert.Equal(t, "bytes/min", slope.Unit)
+
+	r2, _ := evidence.Item("R²")
+	assert.True(t, r2.HasValue)
+	assert.InDelta(t, 0.95, r2.Value, 0.0001)
+
+	duration, _ := evidence.Item("时长")
+	assert.InDelta(t, 60, duration.Value, 0.001)
+	assert.Equal(t, "s", duration.Unit)
+
+	// 文本变量和带其他文字的模板只有展示文本
+	direction, _ := evidence.Item("方向")
+	assert.False(t, direction.HasValue)
+	note, _ := evidence.Item("说明")
+	assert.False(t, note.HasValue)
+	assert.Equal(t, "0.95 (1.0为完美线性)", note.Display)
+}
+
 // TestEngine_BuildEvidence_NilInputs 测试空输入
 func TestEngine_BuildEvidence_NilInputs(t *testing.T) {
 	engine := &Engine{}
 
 	// nil template
-	evidence := engine.buildEvidence(nil, &analyzer.GroupTrends{}, analyzer.ProfileGroup{})
+	evidence := engine.buildEvidence(Action{}, &analyzer.GroupTrends{}, analyzer.ProfileGroup{})
 	assert.Nil(t, evidence)
 
 	// nil trends
-	evidence = engine.buildEvidence(map[string]string{"key": "value"}, nil, analyzer.ProfileGroup{})
+	evidence = engine.buildEvidence(Action{EvidenceTemplate: map[string]string{"key": "value"}}, nil, analyzer.ProfileGroup{})
 	assert.Nil(t, evidence)
 }
+
+// TestEngine_Evaluate_RuleMinR2 测试规则级 R² 门槛
+func TestEngine_Evaluate_RuleMinR2(t *testing.T) {
+	newEngine := func(minR2 float64) *Engine {
+		return &Engine{
+			rules: []Rule{
+				compiledRule(Rule{
+					ID:           "memory_growth",
+					Name:         "Memory Growth",
+					ProfileTypes: []string{"heap"},
+					Condition:    "heap_inuse.slope > 10.0",
+					MinR2:        minR2,
+					Actions:      []Action{{Type: "report", Severity: "high", Title: "Memory Growing"}},
+				}),
+			},
+		}
+	}
+
+	now := time.Now()
+	groups := []analyzer.ProfileGroup{
+		{
+			Type: "heap",
+			Files: []analyzer.ProfileFile{
+				{Path: "/test1.pprof", Time: now},
+				{Path: "/test2.pprof", Time: now.Add(30 * time.Second)},
+				{Path: "/test3.pprof", Time: now.Add(60 * time.Second)},
+			},
+		},
+	}
+	trends := map[string]*analyzer.GroupTrends{
+		"heap": {
+			HeapInuse: &analyzer.TrendMetrics{Slope: 1024 * 1024, R2: 0.75, Direction: "increasing"},
+		},
+	}
+
+	// 未配置 min_r2 时只看表达式，R²=0.75 也触发
+	assert.Len(t, newEngine(0).Evaluate(groups, trends), 1)
+	// 表达式引用的趋势 R² 必须高于门槛
+	assert.Empty(t, newEngine(0.8).Evaluate(groups, trends))
+	assert.Len(t, newEngine(0.7).Evaluate(groups, trends), 1)
+	// 收紧门槛后不触发
+	trends["heap"].HeapInuse.R2 = 0.9
+	assert.Empty(t, newEngine(0.95).Evaluate(groups, trends))
+}
+
+// rulesYAML 生成一条规则的 YAML，id 和 title 用于区分规则
+func rulesYAML(id, title string) string {
+	return `  - id: "` + id + `"
+    name: "测试规则"
+    profile_types: ["heap"]
+    condition: "heap_inuse.slope > 10.0"
+    actions:
+      - type: "report"
+        severity: "high"
+        title: "` + title + `"
+`
+}
+
+// TestNewEngine_MergeRulesFiles 测试多个规则文件按 ID 合并，后面的文件覆盖前面的
+func TestNewEngine_MergeRulesFiles(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	service := filepath.Join(dir, "service.yaml")
+	require.NoError(t, os.WriteFile(base, []byte("rules:\n"+rulesYAML("a", "base a")+rulesYAML("b", "base b")), 0644))
+	require.NoError(t, os.WriteFile(service, []byte("rules:\n"+rulesYAML("c", "service c")+rulesYAML("a", "service a")), 0644))
+
+	engine, err := NewEngine(base + ", " + service)
+	require.NoError(t, err)
+	require.Len(t, engine.rules, 3)
+	// 覆盖的规则保留原来的位置，新规则追加在末尾
+	assert.Equal(t, "a", engine.rules[0].ID)
+	assert.Equal(t, "service a", engine.rules[0].Actions[0].Title)
+	assert.Equal(t, "b", engine.rules[1].ID)
+	assert.Equal(t, "c", engine.rules[2].ID)
+
+	// 合并后的规则照常校验
+	broken := filepath.Join(dir, "broken.yaml")
+	require.NoError(t, os.WriteFile(broken, []byte(`rules:
+  - id: "b"
+    name: "测试规则"
+    profile_types: ["heap"]
+    condition: "heap_inuse.slope > 10.0"
+`), 0644))
+	_, err = NewEngine(base + "," + broken)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rule b: missing actions")
+
+	// 任一文件不存在时报错
+	_, err = NewEngine(base + "," + filepath.Join(dir, "missing.yaml"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
+
+// TestNewEngine_DuplicateRuleID 测试同一文件中重复的规则 ID 报错
+func TestNewEngine_DuplicateRuleID(t *testing.T) {
+	rulesPath := filepath.Join(t.TempDir(), "rules.yaml")
+	require.NoError(t, os.WriteFile(rulesPath, []byte("rules:\n"+rulesYAML("a", "first")+rulesYAML("a", "second")), 0644))
+
+	engine, err := NewEngine(rulesPath)
+	require.Error(t, err)
+	assert.Nil(t, engine)
+	assert.Contains(t, err.Error(), "duplicate rule id 'a'")
+}
+
+func TestSplitRulesPaths(t *testing.T) {
+	assert.Equal(t, []string{"base.yaml", "service.yaml"}, splitRulesPaths(" base.yaml , service.yaml,"))
+	assert.Empty(t, splitRulesPaths(""))
+	assert.Empty(t, splitRulesPaths(" , "))
+}
+
+// TestNewEngine_InvalidMinR2 测试非法的 min_r2 配置
+func TestNewEngine_InvalidMinR2(t *testing.T) {
+	tempDir := t.TempDir()
+
+	rulesContent := `rules:
+  - id: "test_rule"
+    name: "测试规则"
+    profile_types: ["heap"]
+    condition: "trends.heap_inuse.slope > 10.0"
+    min_r2: 1.5
+    actions:
+      - type: "report"
+        severity: "high"
+        title: "测试发现"
+`
+	rulesPath := filepath.Join(tempDir, "rules.yaml")
+	require.NoError(t, os.WriteFile(rulesPath, []byte(rulesContent), 0644))
+
+	engine, err := NewEngine(rulesPath)
+	assert.Error(t, err)
+	assert.Nil(t, engine)
+	assert.Contains(t, err.Error(), "min_r2")
+}
+
+// writeConditionRules 写入只有一条 heap 规则的规则文件
+func writeConditionRules(t *testing.T, condition string) string {
+	rulesPath := filepath.Join(t.TempDir(), "rules.yaml")
+	content := `rules:
+  - id: "test_rule"
+    name: "测试规则"
+    profile_types: ["heap"]
+    condition: "` + condition + `"
+    actions:
+      - type: "report"
+        severity: "high"
+        title: "测试发现"
+`
+	require.NoError(t, os.WriteFile(rulesPath, []byte(content), 0644))
+	return rulesPath
+}
+
+// TestNewEngine_CompileCondition 加载规则时解析表达式条件，无法解析的条件记录弃用警告而不是加载失败
+func TestNewEngine_CompileCondition(t *testing.T) {
+	engine, err := NewEngine(writeConditionRules(t, "heap_inuse.slop > 10.0"))
+	require.NoError(t, err)
+	assert.Nil(t, engine.rules[0].expr)
+	require.Len(t, engine.Warnings(), 1)
+	assert.Contains(t, engine.Warnings()[0], "rule test_rule: invalid condition 'heap_inuse.slop > 10.0': unknown variable 'heap_inuse.slop'")
+	assert.Contains(t, engine.Warnings()[0], "已弃用的关键字匹配")
+
+	// 表达式只解析一次，缓存在规则上
+	engine, err = NewEngine(writeConditionRules(t, "trends.heap_inuse.slope > 10.0"))
+	require.NoError(t, err)
+	assert.NotNil(t, engine.rules[0].expr)
+	assert.Empty(t, engine.Warnings())
+
+	// 命名条件不需要解析
+	engine, err = NewEngine(writeConditionRules(t, ConditionProfileExists))
+	require.NoError(t, err)
+	assert.Nil(t, engine.rules[0].expr)
+	assert.Empty(t, engine.Warnings())
+}
+
+// TestNewEngine_LegacyCondition 旧版规则文件的关键字条件仍按内置阈值触发，并受 minPoints 限制
+func TestNewEngine_LegacyCondition(t *testing.T) {
+	engine, err := NewEngine(writeConditionRules(t, "trends.heap_inuse.slope > 10.0 && metricsSeries.length > 3"))
+	require.NoError(t, err)
+	require.Len(t, engine.Warnings(), 1)
+
+	now := time.Now()
+	group := analyzer.ProfileGroup{Type: "heap", Files: []analyzer.ProfileFile{
+		{Path: "/heap1.pprof", Time: now},
+		{Path: "/heap2.pprof", Time: now.Add(time.Minute)},
+		{Path: "/heap3.pprof", Time: now.Add(2 * time.Minute)},
+	}}
+	trends := map[string]*analyzer.GroupTrends{"heap": {
+		HeapInuse: &analyzer.TrendMetrics{Slope: 1024 * 1024, R2: 0.9, Direction: "increasing", Points: 3},
+	}}
+
+	findings, stats := engine.EvaluateWithStats([]analyzer.ProfileGroup{group}, trends)
+	require.Len(t, findings, 1)
+	assert.Equal(t, "test_rule", findings[0].RuleID)
+	assert.Equal(t, 1, stats.Matched)
+
+	// 低于内置的 R² 门槛不触发
+	trends["heap"].HeapInuse.R2 = 0.8
+	assert.Empty(t, engine.Evaluate([]analyzer.ProfileGroup{group}, trends))
+
+	// 快照数不足时数据不足，不评估
+	trends["heap"].HeapInuse.R2 = 0.9
+	group.Files = group.Files[:2]
+	findings, stats = engine.EvaluateWithStats([]analyzer.ProfileGroup{group}, trends)
+	assert.Empty(t, findings)
+	assert.Equal(t, 1, stats.SkippedData)
+}
+
+// TestEngine_Evaluate_InuseAllocDivergence 测试单个 heap profile 的 inuse/alloc 背离检测
+func TestEngine_Evaluate_InuseAllocDivergence(t *testing.T) {
+	const mb = 1024 * 1024
+	engine := &Engine{
+		rules: []Rule{
+			{
+				ID:           "heap_inuse_alloc_divergence",
+				Name:         "inuse 与 alloc 背离",
+				ProfileTypes: []string{"heap"},
+				Condition:    ConditionInuseAllocDivergence,
+				Actions: []Action{
+					{
+						Type:     "report",
+						Severity: "medium",
+						Title:    "分配未被释放",
+						EvidenceTemplate: map[string]string{
+							"包":  "{{.retained_packages}}",
+							"数量": "{{.retained_count}}",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	newGroup := func(retention []analyzer.PackageRetention) []analyzer.ProfileGroup {
+		return []analyzer.ProfileGroup{
+			{
+				Type: "heap",
+				Files: []analyzer.ProfileFile{
+					{Path: "/heap.pprof", Metrics: &analyzer.ProfileMetrics{PackageRetention: retention}},
+				},
+			},
+		}
+	}
+
+	t.Run("retained package triggers without trends", func(t *testing.T) {
+		groups := newGroup([]analyzer.PackageRetention{
+			{Package: "github.com/myapp/cache", AllocSpace: 10 * mb, InuseSpace: 9 * mb, Ratio: 0.9},
+			{Package: "encoding/json", AllocSpace: 100 * mb, InuseSpace: 1 * mb, Ratio: 0.01},
+		})
+
+		findings := engine.Evaluate(groups, nil)
+		require.Len(t, findings, 1)
+		assert.Equal(t, "heap_inuse_alloc_divergence", findings[0].RuleID)
+		assert.Equal(t, "github.com/myapp/cache (90%, 9.00 MB / 10.00 MB)", findings[0].Evidence.Map()["包"])
+		assert.Equal(t, "1", findings[0].Evidence.Map()["数量"])
+	})
+
+	t.Run("healthy heap does not trigger", func(t *testing.T) {
+		groups := newGroup([]analyzer.PackageRetention{
+			{Package: "encoding/json", AllocSpace: 100 * mb, InuseSpace: 1 * mb, Ratio: 0.01},
+		})
+		assert.Empty(t, engine.Evaluate(groups, nil))
+	})
+
+	t.Run("missing metrics does not trigger", func(t *testing.T) {
+		assert.Empty(t, engine.Evaluate(newGroup(nil), nil))
+	})
+}
+
+// TestEngine_Evaluate_ConversionHotspot 测试 string/[]byte 转换热点检测
+func TestEngine_Evaluate_ConversionHotspot(t *testing.T) {
+	const mb = 1024 * 1024
+	engine := &Engine{
+		rules: []Rule{
+			{
+				ID:           "heap_string_conversion_hotspot",
+				Name:         "string/[]byte 转换热点",
+				ProfileTypes: []string{"heap"},
+				Condition:    ConditionConversionHotspot,
+				Actions: []Action{
+					{
+						Type:     "report",
+						Severity: "medium",
+						Title:    "转换分配过多",
+						EvidenceTemplate: map[string]string{
+							"占比":  "{{.conversion_share}}",
+							"调用点": "{{.conversion_callers}}",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	newGroup := func(hotspots []analyzer.ConversionHotspot) []analyzer.ProfileGroup {
+		return []analyzer.ProfileGroup{
+			{
+				Type: "heap",
+				Files: []analyzer.ProfileFile{
+					{Path: "/heap.pprof", Metrics: &analyzer.ProfileMetrics{ConversionHotspots: hotspots}},
+				},
+			},
+		}
+	}
+
+	t.Run("dominant conversions trigger", func(t *testing.T) {
+		groups := newGroup([]analyzer.ConversionHotspot{
+			{RuntimeFunc: "runtime.stringtoslicebyte", Caller: "github.com/myapp/codec.Encode", AllocSpace: 30 * mb, Share: 0.3},
+			{RuntimeFunc: "runtime.slicebytetostring", Caller: "github.com/myapp/codec.Decode", AllocSpace: 10 * mb, Share: 0.1},
+		})
+
+		findings := engine.Evaluate(groups, nil)
+		require.Len(t, findings, 1)
+		assert.Equal(t, "heap_string_conversion_hotspot", findings[0].RuleID)
+		assert.Equal(t, "40.0%", findings[0].Evidence.Map()["占比"])
+		assert.Equal(t, "github.com/myapp/codec.Encode → stringtoslicebyte (30.0%, 30.00 MB), "+
+			"github.com/myapp/codec.Decode → slicebytetostring (10.0%, 10.00 MB)", findings[0].Evidence.Map()["调用点"])
+	})
+
+	t.Run("minor conversions do not trigger", func(t *testing.T) {
+		groups := newGroup([]analyzer.ConversionHotspot{
+			{RuntimeFunc: "runtime.stringtoslicebyte", Caller: "github.com/myapp/codec.Encode", AllocSpace: mb, Share: 0.05},
+		})
+		assert.Empty(t, engine.Evaluate(groups, nil))
+	})
+
+	t.Run("missing metrics does not trigger", func(t *testing.T) {
+		assert.Empty(t, engine.Evaluate(newGroup(nil), nil))
+	})
+}
+
+// TestEngine_Evaluate_ReflectionHotspot 测试反射热点检测
+func TestEngine_Evaluate_ReflectionHotspot(t *testing.T) {
+	const mb = 1024 * 1024
+	engine := &Engine{
+		rules: []Rule{
+			{
+				ID:           "reflection_hotspot",
+				Name:         "反射开销过高",
+				ProfileTypes: []string{"cpu", "heap"},
+				Condition:    ConditionReflectionHotspot,
+				Actions: []Action{
+					{
+						Type:     "report",
+						Severity: "medium",
+						Title:    "反射开销过高",
+						EvidenceTemplate: map[string]string{
+							"占比":  "{{.reflection_share}}",
+							"调用点": "{{.reflection_callers}}",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	newGroup := func(profileType string, hotspots []analyzer.ReflectionHotspot) []analyzer.ProfileGroup {
+		return []analyzer.ProfileGroup{
+			{
+				Type: profileType,
+				Files: []analyzer.ProfileFile{
+					{Path: "/" + profileType + ".pprof", Metrics: &analyzer.ProfileMetrics{ReflectionHotspots: hotspots}},
+				},
+			},
+		}
+	}
+
+	t.Run("heap reflection triggers", func(t *testing.T) {
+		groups := newGroup("heap", []analyzer.ReflectionHotspot{
+			{Caller: "github.com/myapp/api.DecodeOrder", Value: 20 * mb, Share: 0.2},
+			{Caller: "github.com/myapp/orm.Scan", Value: 5 * mb, Share: 0.05},
+		})
+
+		findings := engine.Evaluate(groups, nil)
+		require.Len(t, findings, 1)
+		assert.Equal(t, "reflection_hotspot", findings[0].RuleID)
+		assert.Equal(t, []string{"heap"}, findings[0].ProfileTypes)
+		item, ok := findings[0].Evidence.Item("占比")
+		require.True(t, ok)
+		assert.Equal(t, "25.0%", item.Display)
+		assert.InDelta(t, 0.25, item.Value, 0.0001)
+		assert.Equal(t, "github.com/myapp/api.DecodeOrder (20.0%, 20.00 MB), "+
+			"github.com/myapp/orm.Scan (5.0%, 5.00 MB)", findings[0].Evidence.Map()["调用点"])
+	})
+
+	t.Run("cpu reflection reports time", func(t *testing.T) {
+		groups := newGroup("cpu", []analyzer.ReflectionHotspot{
+			{Caller: "github.com/myapp/api.Encode", Value: int64(1500 * time.Millisecond), Share: 0.15},
+		})
+
+		findings := engine.Evaluate(groups, nil)
+		require.Len(t, findings, 1)
+		assert.Equal(t, "github.com/myapp/api.Encode (15.0%, 1.5s)", findings[0].Evidence.Map()["调用点"])
+	})
+
+	t.Run("minor reflection does not trigger", func(t *testing.T) {
+		groups := newGroup("heap", []analyzer.ReflectionHotspot{
+			{Caller: "github.com/myapp/orm.Scan", Value: mb, Share: 0.05},
+		})
+		assert.Empty(t, engine.Evaluate(groups, nil))
+	})
+
+	t.Run("goroutine profile does not trigger", func(t *testing.T) {
+		groups := newGroup("goroutine", []analyzer.ReflectionHotspot{
+			{Caller: "github.com/myapp/orm.Scan", Value: 50, Share: 0.5},
+		})
+		assert.Empty(t, engine.Evaluate(groups, nil))
+	})
+}
+
+func TestEngine_Evaluate_GCAssistStall(t *testing.T) {
+	engine := &Engine{
+		rules: []Rule{
+			{
+				ID:           "cpu_gc_assist_stall",
+				Name:         "GC 辅助标记停顿",
+				ProfileTypes: []string{"cpu"},
+				Condition:    ConditionGCAssistStall,
+				Actions: []Action{
+					{
+						Type:     "report",
+						Severity: "high",
+						Title:    "GC 辅助标记停顿",
+						EvidenceTemplate: map[string]string{
+							"占比":  "{{.gc_assist_share}}",
+							"分配点": "{{.gc_assist_sites}}",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	newGroup := func(profileType string, sites []analyzer.GCAssistSite) []analyzer.ProfileGroup {
+		return []analyzer.ProfileGroup{
+			{
+				Type: profileType,
+				Files: []analyzer.ProfileFile{
+					{Path: "/" + profileType + ".pprof", Metrics: &analyzer.ProfileMetrics{GCAssistSites: sites}},
+				},
+			},
+		}
+	}
+
+	t.Run("gc assist triggers", func(t *testing.T) {
+		groups := newGroup("cpu", []analyzer.GCAssistSite{
+			{Site: "github.com/myapp/api.DecodeOrder", Value: int64(600 * time.Millisecond), Share: 0.06},
+			{Site: "github.com/myapp/cache.Put", Value: int64(200 * time.Millisecond), Share: 0.02},
+		})
+
+		findings := engine.Evaluate(groups, nil)
+		require.Len(t, findings, 1)
+		assert.Equal(t, "cpu_gc_assist_stall", findings[0].RuleID)
+		item, ok := findings[0].Evidence.Item("占比")
+		require.True(t, ok)
+		assert.Equal(t, "8.0%", item.Display)
+		assert.InDelta(t, 0.08, item.Value, 0.0001)
+		assert.Equal(t, "github.com/myapp/api.DecodeOrder (6.0%, 600ms), "+
+			"github.com/myapp/cache.Put (2.0%, 200ms)", findings[0].Evidence.Map()["分配点"])
+	})
+
+	t.Run("minor gc assist does not trigger", func(t *testing.T) {
+		groups := newGroup("cpu", []analyzer.GCAssistSite{
+			{Site: "github.com/myapp/cache.Put", Value: int64(20 * time.Millisecond), Share: 0.02},
+		})
+		assert.Empty(t, engine.Evaluate(groups, nil))
+	})
+
+	t.Run("heap profile does not trigger", func(t *testing.T) {
+		groups := newGroup("heap", []analyzer.GCAssistSite{
+			{Site: "github.com/myapp/cache.Put", Value: 50, Share: 0.5},
+		})
+		assert.Empty(t, engine.Evaluate(groups, nil))
+	})
+}
+
+// TestEngine_Evaluate_OversizedAllocation 测试超大单次分配检测
+func TestEngine_Evaluate_OversizedAllocation(t *testing.T) {
+	const mb = 1024 * 1024
+	engine := &Engine{
+		rules: []Rule{
+			{
+				ID:           "heap_oversized_allocation",
+				Name:         "超大单次分配",
+				ProfileTypes: []string{"heap"},
+				Condition:    ConditionOversizedAllocation,
+				Actions: []Action{
+					{
+						Type:     "report",
+						Severity: "medium",
+						Title:    "超大单次分配",
+						EvidenceTemplate: map[string]string{
+							"分配点": "{{.oversized_sites}}",
+							"数量":  "{{.oversized_count}}",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	newGroup := func(sites []analyzer.AllocationSite) []analyzer.ProfileGroup {
+		return []analyzer.ProfileGroup{
+			{
+				Type: "heap",
+				Files: []analyzer.ProfileFile{
+					{Path: "/heap.pprof", Metrics: &analyzer.ProfileMetrics{AllocationSites: sites}},
+				},
+			},
+		}
+	}
+
+	t.Run("oversized sites trigger", func(t *testing.T) {
+		groups := newGroup([]analyzer.AllocationSite{
+			{Function: "io.ReadAll", BusinessFrame: "github.com/myapp/api.Upload", AllocObjects: 3, AllocSpace: 12 * mb, AvgSize: 4 * mb},
+			{Function: "github.com/myapp/report.Build", BusinessFrame: "github.com/myapp/report.Build", AllocObjects: 1, AllocSpace: 2 * mb, AvgSize: 2 * mb},
+			{Function: "github.com/myapp/cache.New", AllocObjects: 1000, AllocSpace: 10 * mb, AvgSize: 10 * 1024},
+		})
+
+		findings := engine.Evaluate(groups, nil)
+		require.Len(t, findings, 1)
+		assert.Equal(t, "heap_oversized_allocation", findings[0].RuleID)
+		assert.Equal(t, "2", findings[0].Evidence.Map()["数量"])
+		assert.Equal(t, "github.com/myapp/api.Upload → io.ReadAll (约 4.00 MB/次, 3 次), "+
+			"github.com/myapp/report.Build (约 2.00 MB/次, 1 次)", findings[0].Evidence.Map()["分配点"])
+	})
+
+	t.Run("small allocations do not trigger", func(t *testing.T) {
+		groups := newGroup([]analyzer.AllocationSite{
+			{Function: "github.com/myapp/cache.New", AllocObjects: 1000, AllocSpace: 10 * mb, AvgSize: 10 * 1024},
+		})
+		assert.Empty(t, engine.Evaluate(groups, nil))
+	})
+
+	t.Run("missing metrics does not trigger", func(t *testing.T) {
+		assert.Empty(t, engine.Evaluate(newGroup(nil), nil))
+	})
+}
+
+// TestEngine_Evaluate_ChannelBacklog 测试 channel 积压联合分析
+func TestEngine_Evaluate_ChannelBacklog(t *testing.T) {
+	const kb = 1024
+	engine := &Engine{
+		crossAnalysisRules: []CrossAnalysisRule{
+			{
+				ID:   "channel_backlog",
+				Name: "Channel 积压",
+				Conditions: map[string]string{
+					"heap":      "increasing",
+					"goroutine": "increasing && slope > 0",
+				},
+				Correlation: CorrelationChannelBacklog,
+				Actions: []Action{
+					{
+						Type:     "report",
+						Severity: "high",
+						Title:    "Channel 积压",
+						EvidenceTemplate: map[string]string{
+							"阻塞":  "{{.blocked_sends}}",
+							"发送点": "{{.channel_send_sites}}",
+							"增长":  "{{.channel_growth}}",
+							"创建点": "{{.channel_alloc_sites}}",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	now := time.Now()
+	newGroups := func(sends []int64, chanInuse []int64) []analyzer.ProfileGroup {
+		goroutine := analyzer.ProfileGroup{Type: "goroutine"}
+		heap := analyzer.ProfileGroup{Type: "heap"}
+		for i := range sends {
+			at := now.Add(time.Duration(i) * time.Minute)
+			goroutine.Files = append(goroutine.Files, analyzer.ProfileFile{Time: at, Metrics: &analyzer.ProfileMetrics{
+				ChannelBlocks: []analyzer.ChannelBlockSite{{Op: "send", Caller: "github.com/myapp/queue.Push", Count: sends[i]}},
+			}})
+			heap.Files = append(heap.Files, analyzer.ProfileFile{Time: at, Metrics: &analyzer.ProfileMetrics{
+				ChannelAllocations: []analyzer.ChannelAllocation{{Caller: "github.com/myapp/queue.New", InuseSpace: chanInuse[i]}},
+			}})
+		}
+		return []analyzer.ProfileGroup{heap, goroutine}
+	}
+	trends := map[string]*analyzer.GroupTrends{
+		"heap":      {HeapInuse: &analyzer.TrendMetrics{Slope: 1024, R2: 0.95, Direction: "increasing"}},
+		"goroutine": {GoroutineCount: &analyzer.TrendMetrics{Slope: 10, R2: 0.95, Direction: "increasing"}},
+	}
+
+	t.Run("blocked sends with growing channel memory", func(t *testing.T) {
+		findings := engine.Evaluate(newGroups([]int64{10, 30, 50}, []int64{kb, 2 * kb, 3 * kb}), trends)
+		require.Len(t, findings, 1)
+		assert.True(t, findings[0].IsCrossAnalysis)
+		assert.Equal(t, "50 (+40)", findings[0].Evidence.Map()["阻塞"])
+		assert.Equal(t, "github.com/myapp/queue.Push (50 个)", findings[0].Evidence.Map()["发送点"])
+		assert.Equal(t, "2.00 KB", findings[0].Evidence.Map()["增长"])
+		assert.Equal(t, "github.com/myapp/queue.New (+2.00 KB)", findings[0].Evidence.Map()["创建点"])
+	})
+
+	t.Run("growth outside channels does not trigger", func(t *testing.T) {
+		assert.Empty(t, engine.Evaluate(newGroups([]int64{10, 30, 50}, []int64{kb, kb, kb}), trends))
+	})
+
+	t.Run("no blocked sends does not trigger", func(t *testing.T) {
+		assert.Empty(t, engine.Evaluate(newGroups([]int64{0, 0, 0}, []int64{kb, 2 * kb, 3 * kb}), trends))
+	})
+}
+
+// TestCheckCorrelation_Direction 测试 same_direction 至少需要两条一致的有方向趋势，inverse_direction 需要一升一降
+func TestCheckCorrelation_Direction(t *testing.T) {
+	trends := func(directions ...string) map[string]*analyzer.TrendMetrics {
+		names := []string{"heap", "goroutine", "cpu"}
+		result := make(map[string]*analyzer.TrendMetrics, len(directions))
+		for i, direction := range directions {
+			result[names[i]] = &analyzer.TrendMetrics{Direction: direction}
+		}
+		return result
+	}
+
+	tests := []struct {
+		name        string
+		correlation string
+		trends      map[string]*analyzer.TrendMetrics
+		want        bool
+	}{
+		{"two agree", CorrelationSameDirection, trends("increasing", "increasing"), true},
+		{"two agree with present", CorrelationSameDirection, trends("increasing", "increasing", "present"), true},
+		{"three agree", CorrelationSameDirection, trends("decreasing", "decreasing", "decreasing"), true},
+		{"one only", CorrelationSameDirection, trends("increasing", "present"), false},
+		{"none directional", CorrelationSameDirection, trends("present", "present"), false},
+		{"conflicting", CorrelationSameDirection, trends("increasing", "decreasing"), false},
+		{"conflicting third", CorrelationSameDirection, trends("increasing", "increasing", "decreasing"), false},
+		{"one up one down", CorrelationInverseDirection, trends("increasing", "decreasing", "present"), true},
+		{"both up", CorrelationInverseDirection, trends("increasing", "increasing"), false},
+		{"one only inverse", CorrelationInverseDirection, trends("decreasing", "present"), false},
+		{"stable breaks inverse", CorrelationInverseDirection, trends("increasing", "decreasing", "stable"), false},
+	}
+	engine := &Engine{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, engine.checkCorrelation(tt.correlation, tt.trends, nil))
+		})
+	}
+}
+
+// TestEngine_Evaluate_AllocsPerGoroutine 测试区分 goroutine 增多和单 goroutine 分配增长
+func TestEngine_Evaluate_AllocsPerGoroutine(t *testing.T) {
+	newRule := func(id, correlation string) CrossAnalysisRule {
+		return CrossAnalysisRule{
+			ID:          id,
+			Name:        id,
+			Conditions:  map[string]string{"heap": "present", "goroutine": "present"},
+			Correlation: correlation,
+			Actions: []Action{
+				{
+					Type:     "report",
+					Severity: "high",
+					Title:    id,
+					EvidenceTemplate: map[string]string{
+						"速率":   "{{.alloc_rate}}",
+						"数量":   "{{.goroutine_growth}}",
+						"单个":   "{{.allocs_per_goroutine}}",
+						"单个变化": "{{.per_goroutine_growth}}",
+					},
+				},
+			},
+		}
+	}
+	engine := &Engine{
+		crossAnalysisRules: []CrossAnalysisRule{
+			newRule("fanout", CorrelationGoroutineFanout),
+			newRule("per_goroutine", CorrelationPerGoroutineAllocation),
+		},
+	}
+
+	const kb = 1024
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	newGroups := func(allocSpace, goroutines []int64) []analyzer.ProfileGroup {
+		heap := analyzer.ProfileGroup{Type: "heap"}
+		goroutine := analyzer.ProfileGroup{Type: "goroutine"}
+		for i := range allocSpace {
+			at := start.Add(time.Duration(i) * time.Minute)
+			heap.Files = append(heap.Files, analyzer.ProfileFile{Time: at, Metrics: &analyzer.ProfileMetrics{AllocSpace: allocSpace[i]}})
+			goroutine.Files = append(goroutine.Files, analyzer.ProfileFile{Time: at, Metrics: &analyzer.ProfileMetrics{GoroutineCount: goroutines[i]}})
+		}
+		return []analyzer.ProfileGroup{heap, goroutine}
+	}
+	trends := map[string]*analyzer.GroupTrends{
+		"heap":      {HeapInuse: &analyzer.TrendMetrics{Slope: 0, R2: 0.1, Direction: "stable"}},
+		"goroutine": {GoroutineCount: &analyzer.TrendMetrics{Slope: 5, R2: 0.95, Direction: "increasing"}},
+	}
+
+	t.Run("more goroutines", func(t *testing.T) {
+		findings := engine.Evaluate(newGroups([]int64{0, 600 * kb, 1500 * kb, 2700 * kb}, []int64{10, 10, 15, 20}), trends)
+		require.Len(t, findings, 1)
+		assert.Equal(t, "fanout", findings[0].RuleID)
+		assert.Equal(t, "10.00 KB/s → 20.00 KB/s (+100.0%)", findings[0].Evidence.Map()["速率"])
+		assert.Equal(t, "10 → 20 (+100.0%)", findings[0].Evidence.Map()["数量"])
+		assert.Equal(t, "1.00 KB/s → 1.00 KB/s → 1.00 KB/s", findings[0].Evidence.Map()["单个"])
+		assert.Equal(t, "+0.0%", findings[0].Evidence.Map()["单个变化"])
+	})
+
+	t.Run("allocations per goroutine rising", func(t *testing.T) {
+		findings := engine.Evaluate(newGroups([]int64{0, 600 * kb, 1800 * kb, 3600 * kb}, []int64{10, 10, 10, 10}), trends)
+		require.Len(t, findings, 1)
+		assert.Equal(t, "per_goroutine", findings[0].RuleID)
+		assert.Equal(t, "1.00 KB/s → 2.00 KB/s → 3.00 KB/s", findings[0].Evidence.Map()["单个"])
+		assert.Equal(t, "+200.0%", findings[0].Evidence.Map()["单个变化"])
+	})
+
+	t.Run("steady allocation rate does not trigger", func(t *testing.T) {
+		assert.Empty(t, engine.Evaluate(newGroups([]int64{0, 600 * kb, 1200 * kb, 1800 * kb}, []int64{10, 10, 10, 10}), trends))
+	})
+}
+
+// TestEngine_Evaluate_NewTopFunction 测试新进入 Top-N 的函数检测
+func TestEngine_Evaluate_NewTopFunction(t *testing.T) {
+	engine := &Engine{
+		rules: []Rule{
+			{
+				ID:           "cpu_new_top_function",
+				Name:         "新函数进入 CPU Top-N",
+				ProfileTypes: []string{"cpu"},
+				Condition:    ConditionNewTopFunction,
+				Actions: []Action{
+					{
+						Type:     "report",
+						Severity: "medium",
+						Title:    "新函数跃入耗时 Top 排名",
+						EvidenceTemplate: map[string]string{
+							"函数": "{{.new_top_functions}}",
+							"首位": "{{.new_top_function}}",
+							"范围": "前 {{.top_n}} 名, {{.file_count}} 个快照",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	newGroup := func(tops ...[]analyzer.FunctionStat) []analyzer.ProfileGroup {
+		group := analyzer.ProfileGroup{Type: "cpu"}
+		for _, top := range tops {
+			group.Files = append(group.Files, analyzer.ProfileFile{Metrics: &analyzer.ProfileMetrics{TopFlatFunctions: top}})
+		}
+		return []analyzer.ProfileGroup{group}
+	}
+	old := []analyzer.FunctionStat{{Name: "main.parse", Flat: 60, FlatPct: 60}, {Name: "main.write", Flat: 40, FlatPct: 40}}
+
+	t.Run("new function triggers", func(t *testing.T) {
+		latest := []analyzer.FunctionStat{{Name: "main.regexpCompile", Flat: 70, FlatPct: 70}, {Name: "main.parse", Flat: 30, FlatPct: 30}}
+		findings := engine.Evaluate(newGroup(old, old, latest), nil)
+		require.Len(t, findings, 1)
+		assert.Equal(t, "#1 main.regexpCompile (70.0%)", findings[0].Evidence.Map()["函数"])
+		assert.Equal(t, "main.regexpCompile", findings[0].Evidence.Map()["首位"])
+		assert.Equal(t, "前 20 名, 3 个快照", findings[0].Evidence.Map()["范围"])
+	})
+
+	t.Run("unchanged ranking does not trigger", func(t *testing.T) {
+		assert.Empty(t, engine.Evaluate(newGroup(old, old), nil))
+	})
+
+	t.Run("single snapshot does not trigger", func(t *testing.T) {
+		assert.Empty(t, engine.Evaluate(newGroup(old), nil))
+	})
+}
+
+// TestExtractTitleKeyword 测试标题匹配多个关键词时结果稳定
+func TestExtractTitleKeyword(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, "memory_leak", extractTitleKeyword("内存增长伴随 Goroutine 增长"))
+	}
+	assert.Equal(t, "cpu_hotspot", extractTitleKeyword("🔥 CPU 热点函数分析"))
+	assert.Equal(t, "", extractTitleKeyword("分配未被释放"))
+	assert.Equal(t, []string{"memory_leak", "goroutine_leak"}, extractAllTitleKeywords("内存增长伴随 Goroutine 增长"))
+}
+
+// newObjectGrowthGroup 创建 inuse_space 平稳、inuse_objects 按 objectStep 增长的 heap 分组
+func newObjectGrowthGroup(objectStep int64) (analyzer.ProfileGroup, map[string]*analyzer.GroupTrends) {
+	now := time.Now()
+	group := analyzer.ProfileGroup{Type: "heap"}
+	for i := int64(0); i < 4; i++ {
+		group.Files = append(group.Files, analyzer.ProfileFile{
+			Path:    "/heap.pprof",
+			Time:    now.Add(time.Duration(i) * time.Minute),
+			Metrics: &analyzer.ProfileMetrics{InuseSpace: 64 << 20, InuseObjects: 100000 + i*objectStep},
+		})
+	}
+	return group, map[string]*analyzer.GroupTrends{"heap": analyzer.CalculateTrends(group)}
+}
+
+// TestEngine_Evaluate_HeapObjectGrowth 测试空间平稳时对象数增长仍然触发
+func TestEngine_Evaluate_HeapObjectGrowth(t *testing.T) {
+	engine := &Engine{
+		rules: []Rule{
+			compiledRule(Rule{
+				ID:           "heap_object_growth",
+				Name:         "堆对象数持续增长",
+				ProfileTypes: []string{"heap"},
+				Condition:    "heap_inuse_objects.slope > 0 && heap_inuse_objects.r2 > 0.85 && heap_inuse_objects.growth >= 0.2",
+				Actions: []Action{{
+					Type:     "report",
+					Severity: "high",
+					Title:    "🧩 堆对象数持续增长",
+					EvidenceTemplate: map[string]string{
+						"速率": "{{.object_slope}} 个/分钟",
+						"对象": "{{.object_growth}}",
+						"空间": "{{.space_growth}}",
+						"R²": "{{.object_r2}}",
+					},
+				}},
+			}),
+			compiledRule(Rule{
+				ID:           "memory_growth",
+				Name:         "Memory Growth",
+				ProfileTypes: []string{"heap"},
+				Condition:    "heap_inuse.slope > 10.0 && heap_inuse.r2 > 0.85",
+				Actions:      []Action{{Type: "report", Severity: "high", Title: "Memory Growing"}},
+			}),
+		},
+	}
+
+	group, trends := newObjectGrowthGroup(50000)
+	findings := engine.Evaluate([]analyzer.ProfileGroup{group}, trends)
+	require.Len(t, findings, 1)
+	assert.Equal(t, "heap_object_growth", findings[0].RuleID)
+	assert.Equal(t, "50000 个/分钟", findings[0].Evidence.Map()["速率"])
+	assert.Equal(t, "+150.0%", findings[0].Evidence.Map()["对象"])
+	assert.Equal(t, "+0.0%", findings[0].Evidence.Map()["空间"])
+	assert.Equal(t, "1.00", findings[0].Evidence.Map()["R²"])
+
+	// 增长不足 20% 不触发
+	group, trends = newObjectGrowthGroup(1000)
+	assert.Empty(t, engine.Evaluate([]analyzer.ProfileGroup{group}, trends))
+
+	// 对象数平稳不触发
+	group, trends = newObjectGrowthGroup(0)
+	assert.Empty(t, engine.Evaluate([]analyzer.ProfileGroup{group}, trends))
+}
@@ -0,0 +1,166 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EvidenceItem 一条证据
+// Display 为模板替换后的展示文本；模板只包含单个数值变量 (如 "{{.r2}}") 时，
+// HasValue 为 true，Value 和 Unit 携带未经格式化的原始数值，便于机器处理
+type EvidenceItem struct {
+	Name     string
+	Display  string
+	Value    float64
+	HasValue bool
+	Unit     string // 如 "bytes/min"、"goroutines/min"、"ratio" (0.5 表示 50%)，无量纲时为空
+}
+
+// Evidence 有序的证据列表，顺序与规则文件中 evidence_template 的声明顺序一致
+type Evidence []EvidenceItem
+
+// Get 返回指定名称证据的展示文本
+func (e Evidence) Get(name string) (string, bool) {
+	if item, ok := e.Item(name); ok {
+		return item.Display, true
+	}
+	return "", false
+}
+
+// Item 返回指定名称的证据
+func (e Evidence) Item(name string) (EvidenceItem, bool) {
+	for _, item := range e {
+		if item.Name == name {
+			return item, true
+		}
+	}
+	return EvidenceItem{}, false
+}
+
+// Map 将证据转换为名称到展示文本的映射，兼容按 map[string]string 读取证据的代码
+func (e Evidence) Map() map[string]string {
+	if e == nil {
+		return nil
+	}
+	m := make(map[string]string, len(e))
+	for _, item := range e {
+		m[item.Name] = item.Display
+	}
+	return m
+}
+
+// EvidenceFromMap 将名称到展示文本的映射转换为按名称排序的证据，不携带原始数值
+func EvidenceFromMap(m map[string]string) Evidence {
+	if m == nil {
+		return nil
+	}
+	evidence := make(Evidence, 0, len(m))
+	for _, name := range sortedKeys(m) {
+		evidence = append(evidence, EvidenceItem{Name: name, Display: m[name]})
+	}
+	return evidence
+}
+
+// UnmarshalYAML 解析 Action，并按声明顺序记录 evidence_template 的键
+func (a *Action) UnmarshalYAML(node *yaml.Node) error {
+	type rawAction Action
+	var raw rawAction
+	if err := node.Decode(&raw); err != nil {
+		return err
+	}
+	raw.EvidenceOrder = nil
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.Value != "evidence_template" || value.Kind != yaml.MappingNode {
+			continue
+		}
+		for j := 0; j+1 < len(value.Content); j += 2 {
+			raw.EvidenceOrder = append(raw.EvidenceOrder, value.Content[j].Value)
+		}
+	}
+	*a = Action(raw)
+	return nil
+}
+
+// evidenceVar 模板变量的取值
+type evidenceVar struct {
+	display  string
+	value    float64
+	hasValue bool
+	unit     string
+}
+
+// textVar 只有展示文本的变量
+func textVar(display string) evidenceVar {
+	return evidenceVar{display: display}
+}
+
+// numberVar 带原始数值和单位的变量
+func numberVar(display string, value float64, unit string) evidenceVar {
+	return evidenceVar{display: display, value: value, hasValue: true, unit: unit}
+}
+
+// countVar 计数变量
+func countVar(n int, unit string) evidenceVar {
+	return numberVar(fmt.Sprintf("%d", n), float64(n), unit)
+}
+
+// renderEvidence 用 vars 替换模板中的 {{.name}} 变量，按 order 中的顺序生成证据
+// order 中没有出现的模板键 (如代码中直接构造的 Action) 按名称排序追加在后面；
+// vars 中没有的变量保持原样
+func renderEvidence(template map[string]string, order []string, vars map[string]evidenceVar) Evidence {
+	if template == nil {
+		return nil
+	}
+
+	pairs := make([]string, 0, len(vars)*2)
+	for name, v := range vars {
+		pairs = append(pairs, "{{."+name+"}}", v.display)
+	}
+	replacer := strings.NewReplacer(pairs...)
+
+	evidence := make(Evidence, 0, len(template))
+	for _, key := range evidenceOrder(template, order) {
+		tmpl := template[key]
+		item := EvidenceItem{Name: key, Display: replacer.Replace(tmpl)}
+		name := strings.TrimSpace(tmpl)
+		if strings.HasPrefix(name, "{{.") && strings.HasSuffix(name, "}}") {
+			if v, ok := vars[strings.TrimSuffix(strings.TrimPrefix(name, "{{."), "}}")]; ok && v.hasValue {
+				item.Value, item.HasValue, item.Unit = v.value, true, v.unit
+			}
+		}
+		evidence = append(evidence, item)
+	}
+	return evidence
+}
+
+// evidenceOrder 返回模板键的展示顺序：先按 order，再按名称排序补齐其余的键
+func evidenceOrder(template map[string]string, order []string) []string {
+	keys := make([]string, 0, len(template))
+	seen := make(map[string]bool, len(template))
+	for _, key := range order {
+		if _, ok := template[key]; ok && !seen[key] {
+			keys = append(keys, key)
+			seen[key] = true
+		}
+	}
+	for _, key := range sortedKeys(template) {
+		if !seen[key] {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// sortedKeys 返回排序后的键
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
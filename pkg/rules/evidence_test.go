@@ -0,0 +1,110 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewEngine_EvidenceOrder 测试加载规则时记录 evidence_template 的声明顺序
+func TestNewEngine_EvidenceOrder(t *testing.T) {
+	tempDir := t.TempDir()
+	rulesContent := `rules:
+  - id: "test_rule"
+    name: "测试规则"
+    profile_types: ["heap"]
+    condition: "trends.heap_inuse.slope > 10.0"
+    actions:
+      - type: "report"
+        severity: "high"
+        title: "测试发现"
+        evidence_template:
+          速率: "{{.slope}}/分钟"
+          R²: "{{.r2}}"
+          持续时间: "{{.duration}}"
+          文件: "{{.file_count}}"
+cross_analysis_rules:
+  - id: "cross_rule"
+    name: "联合规则"
+    conditions:
+      heap: "present"
+      goroutine: "present"
+    actions:
+      - type: "report"
+        severity: "high"
+        title: "联合发现"
+        evidence_template:
+          z: "{{.heap_r2}}"
+          a: "{{.heap_slope}}"
+`
+	rulesPath := filepath.Join(tempDir, "rules.yaml")
+	require.NoError(t, os.WriteFile(rulesPath, []byte(rulesContent), 0644))
+
+	engine, err := NewEngine(rulesPath)
+	require.NoError(t, err)
+	require.Len(t, engine.rules, 1)
+	action := engine.rules[0].Actions[0]
+	assert.Equal(t, []string{"速率", "R²", "持续时间", "文件"}, action.EvidenceOrder)
+	assert.Equal(t, "{{.r2}}", action.EvidenceTemplate["R²"])
+	assert.Equal(t, "测试发现", action.Title)
+
+	require.Len(t, engine.crossAnalysisRules, 1)
+	assert.Equal(t, []string{"z", "a"}, engine.crossAnalysisRules[0].Actions[0].EvidenceOrder)
+}
+
+func TestEvidence_Lookup(t *testing.T) {
+	evidence := Evidence{
+		{Name: "斜率", Display: "2.00 MB", Value: 2 * 1024 * 1024, HasValue: true, Unit: "bytes/min"},
+		{Name: "方向", Display: "increasing"},
+	}
+
+	display, ok := evidence.Get("斜率")
+	assert.True(t, ok)
+	assert.Equal(t, "2.00 MB", display)
+	_, ok = evidence.Get("不存在")
+	assert.False(t, ok)
+
+	item, ok := evidence.Item("斜率")
+	require.True(t, ok)
+	assert.Equal(t, "bytes/min", item.Unit)
+
+	assert.Equal(t, map[string]string{"斜率": "2.00 MB", "方向": "increasing"}, evidence.Map())
+	assert.Nil(t, Evidence(nil).Map())
+}
+
+func TestEvidenceFromMap(t *testing.T) {
+	evidence := EvidenceFromMap(map[string]string{"b": "2", "a": "1", "c": "3"})
+	assert.Equal(t, Evidence{
+		{Name: "a", Display: "1"},
+		{Name: "b", Display: "2"},
+		{Name: "c", Display: "3"},
+	}, evidence)
+	assert.Nil(t, EvidenceFromMap(nil))
+}
+
+func TestRenderEvidence(t *testing.T) {
+	template := map[string]string{
+		"数量": "{{.count}}",
+		"说明": "{{.count}} 个, {{.missing}}",
+		"名称": "{{.name}}",
+		"嵌套": "{{.name}}{{.count}}",
+	}
+	vars := map[string]evidenceVar{
+		"count": countVar(3, "files"),
+		// 替换结果中的变量语法不会再次被替换
+		"name": textVar("{{.count}}"),
+	}
+
+	evidence := renderEvidence(template, []string{"说明"}, vars)
+	assert.Equal(t, Evidence{
+		{Name: "说明", Display: "3 个, {{.missing}}"},
+		{Name: "名称", Display: "{{.count}}"},
+		{Name: "嵌套", Display: "{{.count}}3"},
+		{Name: "数量", Display: "3", Value: 3, HasValue: true, Unit: "files"},
+	}, evidence)
+
+	assert.Nil(t, renderEvidence(nil, nil, vars))
+}
@@ -0,0 +1,160 @@
+package rules
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+)
+
+// 规则的评估范围
+const (
+	// ScopeGroup 默认范围：对整个分组 (趋势、最新快照) 评估一次
+	ScopeGroup = "group"
+	// ScopeFile 对分组中的每个 profile 文件单独评估，每个满足条件的文件产生一个发现
+	ScopeFile = "file"
+)
+
+// fileMetrics 单文件规则条件可以引用的指标，条件中写作 "file.<name>"
+// 百分比为 0-100，内存为 MB，CPU 时间为秒
+var fileMetrics = map[string]func(m *analyzer.ProfileMetrics) float64{
+	"top_function.flat_pct": func(m *analyzer.ProfileMetrics) float64 {
+		if top := topFlatFunction(m); top != nil {
+			return top.FlatPct
+		}
+		return 0
+	},
+	"cpu_time":        func(m *analyzer.ProfileMetrics) float64 { return m.CPUTime.Seconds() },
+	"gc_pct":          func(m *analyzer.ProfileMetrics) float64 { return m.GCFraction * 100 },
+	"inuse_space_mb":  func(m *analyzer.ProfileMetrics) float64 { return float64(m.InuseSpace) / (1024 * 1024) },
+	"alloc_space_mb":  func(m *analyzer.ProfileMetrics) float64 { return float64(m.AllocSpace) / (1024 * 1024) },
+	"inuse_objects":   func(m *analyzer.ProfileMetrics) float64 { return float64(m.InuseObjects) },
+	"alloc_objects":   func(m *analyzer.ProfileMetrics) float64 { return float64(m.AllocObjects) },
+	"goroutine_count": func(m *analyzer.ProfileMetrics) float64 { return float64(m.GoroutineCount) },
+	"total_samples":   func(m *analyzer.ProfileMetrics) float64 { return float64(m.TotalSamples) },
+}
+
+// fileComparison 单文件条件中的一个比较，如 "file.top_function.flat_pct > 50"
+type fileComparison struct {
+	metric string
+	op     string
+	value  float64
+}
+
+// comparisonOps 支持的比较运算符，两个字符的运算符在前，避免 ">=" 被拆成 ">"
+var comparisonOps = []string{">=", "<=", "==", "!=", ">", "<"}
+
+// parseFileCondition 解析单文件条件：由 && 连接的若干 "file.<指标> <运算符> <数值>"
+func parseFileCondition(condition string) ([]fileComparison, error) {
+	var comparisons []fileComparison
+	for _, clause := range strings.Split(condition, "&&") {
+		clause = strings.TrimSpace(clause)
+		var cmp fileComparison
+		for _, op := range comparisonOps {
+			if idx := strings.Index(clause, op); idx >= 0 {
+				cmp.metric = strings.TrimSpace(clause[:idx])
+				cmp.op = op
+				value, err := strconv.ParseFloat(strings.TrimSpace(clause[idx+len(op):]), 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid number in '%s'", clause)
+				}
+				cmp.value = value
+				break
+			}
+		}
+		if cmp.op == "" {
+			return nil, fmt.Errorf("invalid comparison '%s', must be file.<metric> <op> <number>", clause)
+		}
+		name := strings.TrimPrefix(cmp.metric, "file.")
+		if _, ok := fileMetrics[name]; !ok || name == cmp.metric {
+			return nil, fmt.Errorf("unknown file metric '%s', must be one of: %s", cmp.metric, strings.Join(fileMetricNames(), ", "))
+		}
+		cmp.metric = name
+		comparisons = append(comparisons, cmp)
+	}
+	return comparisons, nil
+}
+
+// fileMetricNames 返回排序后的单文件指标名 (带 file. 前缀)
+func fileMetricNames() []string {
+	names := make([]string, 0, len(fileMetrics))
+	for name := range fileMetrics {
+		names = append(names, "file."+name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// matches 判断指标是否满足比较
+func (c fileComparison) matches(m *analyzer.ProfileMetrics) bool {
+	return compareNumbers(fileMetrics[c.metric](m), c.op, c.value)
+}
+
+// evaluateFileCondition 对单个文件的指标评估规则条件，缺少指标的文件不匹配
+func (e *Engine) evaluateFileCondition(rule Rule, file analyzer.ProfileFile) bool {
+	if file.Metrics == nil {
+		return false
+	}
+	comparisons, err := parseFileCondition(rule.Condition)
+	if err != nil {
+		return false
+	}
+	for _, cmp := range comparisons {
+		if !cmp.matches(file.Metrics) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchingFiles 返回分组中满足单文件规则条件的文件
+func (e *Engine) matchingFiles(rule Rule, group analyzer.ProfileGroup) []analyzer.ProfileFile {
+	var matched []analyzer.ProfileFile
+	for _, file := range group.Files {
+		if e.evaluateFileCondition(rule, file) {
+			matched = append(matched, file)
+		}
+	}
+	return matched
+}
+
+// topFlatFunction 返回 flat 占比最高的函数，没有时返回 nil
+func topFlatFunction(m *analyzer.ProfileMetrics) *analyzer.FunctionStat {
+	var top *analyzer.FunctionStat
+	for i := range m.TopFunctions {
+		if top == nil || m.TopFunctions[i].FlatPct > top.FlatPct {
+			top = &m.TopFunctions[i]
+		}
+	}
+	return top
+}
+
+// buildFileEvidence 构建单文件规则的证据数据
+// 支持 {{.file}}、{{.file_path}}、{{.file_time}}、{{.top_function}}、{{.top_function_pct}}、
+// {{.cpu_time}}、{{.gc_pct}}、{{.inuse_space}}、{{.alloc_space}} 和 {{.goroutine_count}}
+func (e *Engine) buildFileEvidence(action Action, file analyzer.ProfileFile) Evidence {
+	if action.EvidenceTemplate == nil {
+		return nil
+	}
+
+	m := file.Metrics
+	vars := map[string]evidenceVar{
+		"file":            textVar(filepath.Base(file.Path)),
+		"file_path":       textVar(file.Path),
+		"file_time":       textVar(file.Time.Format(time.RFC3339)),
+		"cpu_time":        numberVar(m.CPUTime.String(), m.CPUTime.Seconds(), "s"),
+		"gc_pct":          numberVar(fmt.Sprintf("%.1f%%", m.GCFraction*100), m.GCFraction, "ratio"),
+		"inuse_space":     numberVar(analyzer.FormatBytes(m.InuseSpace), float64(m.InuseSpace), "bytes"),
+		"alloc_space":     numberVar(analyzer.FormatBytes(m.AllocSpace), float64(m.AllocSpace), "bytes"),
+		"goroutine_count": numberVar(analyzer.FormatInt(m.GoroutineCount), float64(m.GoroutineCount), "goroutines"),
+	}
+	if top := topFlatFunction(m); top != nil {
+		vars["top_function"] = textVar(top.Name)
+		vars["top_function_pct"] = numberVar(fmt.Sprintf("%.1f%%", top.FlatPct), top.FlatPct/100, "ratio")
+	}
+	return renderEvidence(action.EvidenceTemplate, action.EvidenceOrder, vars)
+}
@@ -0,0 +1,162 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFileCondition(t *testing.T) {
+	comparisons, err := parseFileCondition("file.top_function.flat_pct > 50 && file.cpu_time >= 10")
+	require.NoError(t, err)
+	assert.Equal(t, []fileComparison{
+		{metric: "top_function.flat_pct", op: ">", value: 50},
+		{metric: "cpu_time", op: ">=", value: 10},
+	}, comparisons)
+
+	_, err = parseFileCondition("file.cpu_time")
+	assert.EqualError(t, err, "invalid comparison 'file.cpu_time', must be file.<metric> <op> <number>")
+
+	_, err = parseFileCondition("file.cpu_time > ten")
+	assert.EqualError(t, err, "invalid number in 'file.cpu_time > ten'")
+
+	_, err = parseFileCondition("cpu_time > 10")
+	assert.ErrorContains(t, err, "unknown file metric 'cpu_time', must be one of: file.alloc_objects")
+
+	_, err = parseFileCondition("file.latency > 10")
+	assert.ErrorContains(t, err, "unknown file metric 'file.latency'")
+}
+
+func TestFileComparison_Matches(t *testing.T) {
+	m := &analyzer.ProfileMetrics{GoroutineCount: 100}
+	tests := []struct {
+		op       string
+		value    float64
+		expected bool
+	}{
+		{">", 99, true}, {">", 100, false},
+		{">=", 100, true}, {"<", 100, false},
+		{"<=", 100, true}, {"==", 100, true}, {"!=", 100, false},
+	}
+	for _, tt := range tests {
+		cmp := fileComparison{metric: "goroutine_count", op: tt.op, value: tt.value}
+		assert.Equal(t, tt.expected, cmp.matches(m), "%s %v", tt.op, tt.value)
+	}
+}
+
+func TestEngine_Evaluate_FileScope(t *testing.T) {
+	engine := &Engine{
+		rules: []Rule{
+			{
+				ID:           "single_cpu_hotspot",
+				Name:         "单个快照中的主导函数",
+				ProfileTypes: []string{"cpu"},
+				Condition:    "file.top_function.flat_pct > 50",
+				Scope:        ScopeFile,
+				Actions: []Action{
+					{
+						Type:     "report",
+						Severity: "medium",
+						Title:    "单个 CPU 快照被一个函数主导",
+						EvidenceTemplate: map[string]string{
+							"文件": "{{.file}}",
+							"函数": "{{.top_function}} ({{.top_function_pct}})",
+							"占比": "{{.top_function_pct}}",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cpuFile := func(name string, flatPct float64) analyzer.ProfileFile {
+		return analyzer.ProfileFile{
+			Path: "/profiles/" + name,
+			Time: base,
+			Metrics: &analyzer.ProfileMetrics{TopFunctions: []analyzer.FunctionStat{
+				{Name: "main.work", FlatPct: 10},
+				{Name: "main.encode", FlatPct: flatPct},
+			}},
+		}
+	}
+	groups := []analyzer.ProfileGroup{{Type: "cpu", Files: []analyzer.ProfileFile{
+		cpuFile("cpu1.pprof", 20),
+		cpuFile("cpu2.pprof", 72.5),
+		{Path: "/profiles/broken.pprof"},
+		cpuFile("cpu3.pprof", 55),
+	}}}
+
+	findings, stats := engine.EvaluateWithStats(groups, nil)
+	require.Len(t, findings, 2, "each matching file produces its own finding")
+	assert.Equal(t, "/profiles/cpu2.pprof", findings[0].File)
+	assert.Equal(t, "/profiles/cpu3.pprof", findings[1].File)
+	assert.Equal(t, "cpu2.pprof", findings[0].Evidence.Map()["文件"])
+	assert.Equal(t, "main.encode (72.5%)", findings[0].Evidence.Map()["函数"])
+	item, ok := findings[0].Evidence.Item("占比")
+	require.True(t, ok)
+	assert.InDelta(t, 0.725, item.Value, 1e-9)
+	assert.Equal(t, 1, stats.Matched)
+
+	t.Run("no file matches", func(t *testing.T) {
+		groups := []analyzer.ProfileGroup{{Type: "cpu", Files: []analyzer.ProfileFile{cpuFile("cpu1.pprof", 20)}}}
+		findings, stats := engine.EvaluateWithStats(groups, nil)
+		assert.Empty(t, findings)
+		assert.Equal(t, 1, stats.NotMatched)
+	})
+
+	t.Run("empty group lacks data", func(t *testing.T) {
+		_, stats := engine.EvaluateWithStats([]analyzer.ProfileGroup{{Type: "cpu"}}, nil)
+		assert.Equal(t, 1, stats.SkippedData)
+	})
+}
+
+func TestNewEngine_FileScope(t *testing.T) {
+	writeRules := func(t *testing.T, scope, condition string) string {
+		rulesPath := filepath.Join(t.TempDir(), "rules.yaml")
+		content := `rules:
+  - id: "test_rule"
+    name: "测试规则"
+    profile_types: ["cpu"]
+    condition: "` + condition + `"
+    scope: "` + scope + `"
+    actions:
+      - type: "report"
+        severity: "high"
+        title: "测试发现"
+`
+		require.NoError(t, os.WriteFile(rulesPath, []byte(content), 0644))
+		return rulesPath
+	}
+
+	engine, err := NewEngine(writeRules(t, "file", "file.cpu_time > 10"))
+	require.NoError(t, err)
+	assert.Equal(t, ScopeFile, engine.rules[0].Scope)
+
+	_, err = NewEngine(writeRules(t, "file", "trends.heap_inuse.slope > 10"))
+	assert.ErrorContains(t, err, "rule test_rule: unknown file metric 'trends.heap_inuse.slope'")
+
+	_, err = NewEngine(writeRules(t, "snapshot", "file.cpu_time > 10"))
+	assert.EqualError(t, err, "rule test_rule: unknown scope 'snapshot', must be group or file")
+
+	_, err = NewEngine(writeRules(t, "group", "cpu_profile_exists"))
+	assert.NoError(t, err)
+}
+
+func TestDeduplicateFindings_FileScope(t *testing.T) {
+	engine := &Engine{}
+	findings := engine.deduplicateFindings([]Finding{
+		{RuleID: "cpu_rule", Title: "CPU 热点", File: "/a.pprof"},
+		{RuleID: "cpu_rule", Title: "CPU 热点", File: "/b.pprof"},
+		{RuleID: "cpu_rule", Title: "CPU 热点", File: "/b.pprof"},
+		{RuleID: "other_cpu_rule", Title: "CPU 热点函数"},
+	})
+	require.Len(t, findings, 2)
+	assert.Equal(t, "/a.pprof", findings[0].File)
+	assert.Equal(t, "/b.pprof", findings[1].File)
+}
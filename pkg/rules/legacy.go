@@ -0,0 +1,64 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+)
+
+// 旧版关键字条件的默认趋势 R² 门槛，规则未配置 min_r2 时使用
+const (
+	DefaultHeapGrowthMinR2      = 0.85 // 内存增长
+	DefaultObjectGrowthMinR2    = 0.85 // 堆对象数增长
+	DefaultGoroutineGrowthMinR2 = 0.9  // Goroutine 增长
+)
+
+// DefaultObjectGrowthMinRatio 旧版堆对象数增长条件要求最新快照的 inuse_objects 比最早快照至少增长 20%
+const DefaultObjectGrowthMinRatio = 0.2
+
+// legacyConditionWarning 无法解析为表达式的条件的弃用警告
+func legacyConditionWarning(rule Rule, err error) string {
+	return fmt.Sprintf("rule %s: %v，按已弃用的关键字匹配评估，请改写为表达式 (如 heap_inuse.slope > 10 && heap_inuse.r2 > 0.85)", rule.ID, err)
+}
+
+// evaluateLegacyCondition 按条件中出现的关键字检查内置的趋势阈值，兼容无法解析为表达式的旧版规则文件
+// 条件中的数值被忽略，阈值为内置值；R² 门槛可由 min_r2 覆盖，所有检查都需要至少 minPoints 个快照
+func (e *Engine) evaluateLegacyCondition(rule Rule, group analyzer.ProfileGroup, trends *analyzer.GroupTrends) bool {
+	condition := rule.Condition
+	if trends == nil || len(group.Files) < e.minPoints() {
+		return false
+	}
+
+	// 检查堆对象数增长趋势，与使用中内存是否增长无关
+	if contains(condition, analyzer.MetricHeapInuseObjects) {
+		objects := trends.HeapInuseObjects
+		return contains(condition, "slope") &&
+			objects != nil && objects.R2 > ruleMinR2(rule, DefaultObjectGrowthMinR2) && objects.Slope > 0 &&
+			heapGrowthRatio(group, analyzer.HeapSampleInuseObjects) >= DefaultObjectGrowthMinRatio
+	}
+
+	// 检查内存增长趋势
+	if contains(condition, analyzer.MetricHeapInuse) && contains(condition, "slope") {
+		heap := trends.HeapInuse
+		if heap != nil && heap.R2 > ruleMinR2(rule, DefaultHeapGrowthMinR2) && heap.Slope > 10.0 {
+			return true
+		}
+	}
+
+	// 检查 goroutine 增长趋势
+	if contains(condition, analyzer.MetricGoroutineCount) && contains(condition, "slope") {
+		goroutines := trends.GoroutineCount
+		if goroutines != nil && goroutines.R2 > ruleMinR2(rule, DefaultGoroutineGrowthMinR2) && goroutines.Slope > 1.0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleMinR2 返回规则的 R² 门槛，未配置时使用指标默认值
+func ruleMinR2(rule Rule, defaultMinR2 float64) float64 {
+	if rule.MinR2 > 0 {
+		return rule.MinR2
+	}
+	return defaultMinR2
+}
@@ -0,0 +1,91 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+)
+
+// EvaluationStats 一次规则评估的汇总统计，每条规则按其最好的结果计入一项
+// (匹配 > 未匹配 > 数据不足 > 类型不适用)，Matched+NotMatched+SkippedType+SkippedData == Rules
+type EvaluationStats struct {
+	Rules       int // 参与评估的规则数 (单类型规则 + 联合分析规则)
+	Groups      int // 参与评估的 profile 分组数
+	Matched     int // 至少产生一个发现的规则数
+	NotMatched  int // 数据充足但条件不满足的规则数
+	SkippedType int // 没有适用的 profile 类型 (单类型规则) 或缺少某个类型 (联合分析规则) 的规则数
+	SkippedData int // 类型适用但快照数或趋势不足以判断的规则数
+	// Outcomes 每条规则的评估结果，单类型规则在前、联合分析规则在后，各自按规则文件中的顺序
+	Outcomes []RuleOutcome
+}
+
+// 单条规则的评估结果
+const (
+	OutcomeMatched     = "matched"      // 至少产生一个发现
+	OutcomeNotMatched  = "not_matched"  // 数据充足但条件不满足
+	OutcomeSkippedType = "skipped_type" // 没有适用的 profile 类型
+	OutcomeSkippedData = "skipped_data" // 快照数或趋势不足以判断
+)
+
+// RuleOutcome 一条规则的评估结果
+type RuleOutcome struct {
+	RuleID  string
+	Outcome string // OutcomeMatched、OutcomeNotMatched、OutcomeSkippedType 或 OutcomeSkippedData
+}
+
+// String 返回一行汇总，如 "评估了 14 条规则 (3 个分组): 4 条匹配, 10 条未匹配"
+func (s EvaluationStats) String() string {
+	summary := fmt.Sprintf("评估了 %d 条规则 (%d 个分组): %d 条匹配, %d 条未匹配",
+		s.Rules, s.Groups, s.Matched, s.NotMatched+s.SkippedType+s.SkippedData)
+	if s.SkippedType > 0 || s.SkippedData > 0 {
+		summary += fmt.Sprintf(" (其中 %d 条类型不适用, %d 条数据不足)", s.SkippedType, s.SkippedData)
+	}
+	return summary
+}
+
+// ruleOutcome 单条规则的评估结果，数值越大越好
+type ruleOutcome int
+
+const (
+	outcomeSkippedType ruleOutcome = iota
+	outcomeSkippedData
+	outcomeNotMatched
+	outcomeMatched
+)
+
+// record 把一条规则的结果计入统计
+func (s *EvaluationStats) record(ruleID string, outcome ruleOutcome) {
+	s.Rules++
+	name := OutcomeSkippedType
+	switch outcome {
+	case outcomeMatched:
+		s.Matched++
+		name = OutcomeMatched
+	case outcomeNotMatched:
+		s.NotMatched++
+		name = OutcomeNotMatched
+	case outcomeSkippedData:
+		s.SkippedData++
+		name = OutcomeSkippedData
+	default:
+		s.SkippedType++
+	}
+	s.Outcomes = append(s.Outcomes, RuleOutcome{RuleID: ruleID, Outcome: name})
+}
+
+// hasEnoughData 分组的数据是否足以评估规则条件
+// 单快照条件、单文件规则和不引用趋势变量的表达式需要至少一个文件，new_top_function 需要两个，
+// 趋势条件需要趋势和至少 minPoints 个文件
+func hasEnoughData(rule Rule, group analyzer.ProfileGroup, trends *analyzer.GroupTrends, minPoints int) bool {
+	if rule.Scope == ScopeFile {
+		return len(group.Files) > 0
+	}
+	switch {
+	case rule.Condition == ConditionNewTopFunction:
+		return len(group.Files) >= 2
+	case isNamedCondition(rule.Condition), rule.expr != nil && !rule.expr.usesTrends():
+		return len(group.Files) > 0
+	default:
+		return trends != nil && len(group.Files) >= minPoints
+	}
+}
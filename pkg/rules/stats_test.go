@@ -0,0 +1,187 @@
+package rules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/songzhibin97/perfinspector/pkg/analyzer"
+	"github.com/stretchr/testify/assert"
+)
+
+// newStatsRule 创建带一个 report 动作的单类型规则
+func newStatsRule(id, condition string, profileTypes ...string) Rule {
+	return compiledRule(Rule{
+		ID:           id,
+		Name:         id,
+		ProfileTypes: profileTypes,
+		Condition:    condition,
+		Actions:      []Action{{Type: "report", Severity: "medium", Title: id}},
+	})
+}
+
+// newStatsGroup 创建包含 n 个文件的分组
+func newStatsGroup(profileType string, n int) analyzer.ProfileGroup {
+	group := analyzer.ProfileGroup{Type: profileType}
+	now := time.Now()
+	for i := 0; i < n; i++ {
+		group.Files = append(group.Files, analyzer.ProfileFile{Path: "/test.pprof", Time: now.Add(time.Duration(i) * time.Minute)})
+	}
+	return group
+}
+
+// TestEngine_EvaluateWithStats 测试规则评估的汇总统计
+func TestEngine_EvaluateWithStats(t *testing.T) {
+	engine := &Engine{
+		rules: []Rule{
+			newStatsRule("cpu_hotspot", "cpu_profile_exists", "cpu"),
+			newStatsRule("memory_growth", "trends.heap_inuse.slope > 10.0", "heap"),
+			newStatsRule("goroutine_leak", "trends.goroutine_count.slope > 1.0", "goroutine"),
+			newStatsRule("mutex_rule", ConditionProfileExists, "mutex"),
+		},
+		crossAnalysisRules: []CrossAnalysisRule{
+			{
+				ID:         "heap_goroutine",
+				Name:       "heap_goroutine",
+				Conditions: map[string]string{"heap": "increasing", "goroutine": "increasing"},
+				Actions:    []Action{{Type: "report", Severity: "high", Title: "联合"}},
+			},
+		},
+	}
+
+	groups := []analyzer.ProfileGroup{
+		newStatsGroup("cpu", 1),
+		newStatsGroup("heap", 3),
+		newStatsGroup("goroutine", 2),
+	}
+	trends := map[string]*analyzer.GroupTrends{
+		"heap":      {HeapInuse: &analyzer.TrendMetrics{Slope: 1, R2: 0.95, Direction: "increasing"}},
+		"goroutine": {GoroutineCount: &analyzer.TrendMetrics{Slope: 5, R2: 0.95, Direction: "increasing"}},
+	}
+
+	findings, stats := engine.EvaluateWithStats(groups, trends)
+	assert.Len(t, findings, 1)
+	assert.Equal(t, "cpu_hotspot", findings[0].RuleID)
+
+	// cpu_hotspot 匹配；memory_growth 斜率太小未匹配；goroutine_leak 只有 2 个快照；
+	// mutex_rule 没有 mutex profile；联合分析的 goroutine 快照不足
+	assert.Equal(t, EvaluationStats{Rules: 5, Groups: 3, Matched: 1, NotMatched: 1, SkippedType: 1, SkippedData: 2, Outcomes: []RuleOutcome{
+		{RuleID: "cpu_hotspot", Outcome: OutcomeMatched},
+		{RuleID: "memory_growth", Outcome: OutcomeNotMatched},
+		{RuleID: "goroutine_leak", Outcome: OutcomeSkippedData},
+		{RuleID: "mutex_rule", Outcome: OutcomeSkippedType},
+		{RuleID: "heap_goroutine", Outcome: OutcomeSkippedData},
+	}}, stats)
+	assert.Equal(t, "评估了 5 条规则 (3 个分组): 1 条匹配, 4 条未匹配 (其中 1 条类型不适用, 2 条数据不足)", stats.String())
+
+	// Evaluate 返回相同的发现
+	assert.Equal(t, findings, engine.Evaluate(groups, trends))
+}
+
+// TestEngine_Evaluate_ProfileExists 测试 profile_exists 条件只按 profile_types 限定类型
+func TestEngine_Evaluate_ProfileExists(t *testing.T) {
+	engine := &Engine{
+		rules: []Rule{newStatsRule("custom_profile_hotspot", ConditionProfileExists, "unknown")},
+	}
+
+	findings, stats := engine.EvaluateWithStats([]analyzer.ProfileGroup{newStatsGroup("unknown", 1), newStatsGroup("cpu", 1)}, nil)
+	assert.Len(t, findings, 1)
+	assert.Equal(t, "custom_profile_hotspot", findings[0].RuleID)
+	assert.Equal(t, []string{"unknown"}, findings[0].ProfileTypes)
+	assert.Equal(t, 1, stats.Matched)
+
+	findings, stats = engine.EvaluateWithStats([]analyzer.ProfileGroup{newStatsGroup("unknown", 0)}, nil)
+	assert.Empty(t, findings)
+	assert.Equal(t, 1, stats.SkippedData)
+}
+
+// TestEngine_EvaluateWithStats_CrossAnalysis 测试联合分析规则的统计
+func TestEngine_EvaluateWithStats_CrossAnalysis(t *testing.T) {
+	engine := &Engine{
+		crossAnalysisRules: []CrossAnalysisRule{
+			{
+				ID:         "heap_goroutine",
+				Name:       "heap_goroutine",
+				Conditions: map[string]string{"heap": "increasing", "goroutine": "increasing"},
+				Actions:    []Action{{Type: "report", Severity: "high", Title: "联合"}},
+			},
+			{
+				ID:         "heap_mutex",
+				Name:       "heap_mutex",
+				Conditions: map[string]string{"heap": "increasing", "mutex": "increasing"},
+				Actions:    []Action{{Type: "report", Severity: "high", Title: "联合"}},
+			},
+		},
+	}
+
+	groups := []analyzer.ProfileGroup{newStatsGroup("heap", 3), newStatsGroup("goroutine", 3)}
+	trends := map[string]*analyzer.GroupTrends{
+		"heap":      {HeapInuse: &analyzer.TrendMetrics{Slope: 1024, R2: 0.95, Direction: "increasing"}},
+		"goroutine": {GoroutineCount: &analyzer.TrendMetrics{Slope: 5, R2: 0.95, Direction: "increasing"}},
+	}
+
+	findings, stats := engine.EvaluateWithStats(groups, trends)
+	assert.Len(t, findings, 1)
+	assert.Equal(t, EvaluationStats{Rules: 2, Groups: 2, Matched: 1, SkippedType: 1, Outcomes: []RuleOutcome{
+		{RuleID: "heap_goroutine", Outcome: OutcomeMatched},
+		{RuleID: "heap_mutex", Outcome: OutcomeSkippedType},
+	}}, stats)
+
+	// 缺少趋势时数据不足
+	_, stats = engine.EvaluateWithStats(groups, map[string]*analyzer.GroupTrends{"heap": trends["heap"]})
+	assert.Equal(t, EvaluationStats{Rules: 2, Groups: 2, SkippedType: 1, SkippedData: 1, Outcomes: []RuleOutcome{
+		{RuleID: "heap_goroutine", Outcome: OutcomeSkippedData},
+		{RuleID: "heap_mutex", Outcome: OutcomeSkippedType},
+	}}, stats)
+}
+
+// TestEngine_EvaluateWithStats_NilEngine 测试 nil 引擎只统计分组数
+func TestEngine_EvaluateWithStats_NilEngine(t *testing.T) {
+	var engine *Engine
+	findings, stats := engine.EvaluateWithStats([]analyzer.ProfileGroup{newStatsGroup("cpu", 1)}, nil)
+	assert.Nil(t, findings)
+	assert.Equal(t, EvaluationStats{Groups: 1}, stats)
+	assert.Equal(t, "评估了 0 条规则 (1 个分组): 0 条匹配, 0 条未匹配", stats.String())
+}
+
+// TestEngine_SetMinTrendPoints 测试趋势条件所需的最少快照数可配置
+func TestEngine_SetMinTrendPoints(t *testing.T) {
+	engine := &Engine{
+		rules: []Rule{newStatsRule("goroutine_leak", "trends.goroutine_count.slope > 1.0", "goroutine")},
+		crossAnalysisRules: []CrossAnalysisRule{
+			{
+				ID:         "heap_goroutine",
+				Name:       "heap_goroutine",
+				Conditions: map[string]string{"heap": "increasing", "goroutine": "increasing"},
+				Actions:    []Action{{Type: "report", Severity: "high", Title: "联合"}},
+			},
+		},
+	}
+	groups := []analyzer.ProfileGroup{newStatsGroup("heap", 4), newStatsGroup("goroutine", 4)}
+	trends := map[string]*analyzer.GroupTrends{
+		"heap":      {HeapInuse: &analyzer.TrendMetrics{Slope: 1024, R2: 0.95, Direction: "increasing"}},
+		"goroutine": {GoroutineCount: &analyzer.TrendMetrics{Slope: 5, R2: 0.95, Direction: "increasing"}},
+	}
+
+	// 默认 3 个快照即可评估
+	findings, stats := engine.EvaluateWithStats(groups, trends)
+	assert.Len(t, findings, 2)
+	assert.Equal(t, 2, stats.Matched)
+
+	// 要求 6 个快照时两条规则都数据不足
+	engine.SetMinTrendPoints(6)
+	findings, stats = engine.EvaluateWithStats(groups, trends)
+	assert.Empty(t, findings)
+	assert.Equal(t, EvaluationStats{Rules: 2, Groups: 2, SkippedData: 2, Outcomes: []RuleOutcome{
+		{RuleID: "goroutine_leak", Outcome: OutcomeSkippedData},
+		{RuleID: "heap_goroutine", Outcome: OutcomeSkippedData},
+	}}, stats)
+
+	// <= 0 恢复默认值
+	engine.SetMinTrendPoints(0)
+	findings, _ = engine.EvaluateWithStats(groups, trends)
+	assert.Len(t, findings, 2)
+
+	// nil 引擎可以安全调用
+	var nilEngine *Engine
+	nilEngine.SetMinTrendPoints(5)
+}
@@ -6,7 +6,11 @@ type Rule struct {
 	Name         string   `yaml:"name"`
 	ProfileTypes []string `yaml:"profile_types"`
 	Condition    string   `yaml:"condition"`
+	MinR2        float64  `yaml:"min_r2"` // 表达式引用的趋势须高于的 R² 门槛，0 表示不额外限制
+	Scope        string   `yaml:"scope"`  // 评估范围: group (默认，整个分组) 或 file (每个文件单独评估)
 	Actions      []Action `yaml:"actions"`
+
+	expr conditionExpr // 加载规则时解析的表达式条件，命名条件、单文件规则和无法解析的旧版条件为 nil
 }
 
 // CrossAnalysisRule 联合分析规则 - 跨多种 profile 类型的关联分析
@@ -14,7 +18,8 @@ type CrossAnalysisRule struct {
 	ID          string            `yaml:"id"`
 	Name        string            `yaml:"name"`
 	Conditions  map[string]string `yaml:"conditions"`  // 每种 profile 类型的条件
-	Correlation string            `yaml:"correlation"` // 关联类型: same_direction, time_correlated
+	Correlation string            `yaml:"correlation"` // 关联类型: same_direction, inverse_direction, both_increasing, time_correlated
+	MinR2       float64           `yaml:"min_r2"`      // 趋势 R² 门槛，0 表示使用默认值 0.7
 	Actions     []Action          `yaml:"actions"`
 }
 
@@ -24,6 +29,7 @@ type Action struct {
 	Severity         string            `yaml:"severity"`
 	Title            string            `yaml:"title"`
 	EvidenceTemplate map[string]string `yaml:"evidence_template"`
+	EvidenceOrder    []string          `yaml:"-"` // evidence_template 中键的声明顺序，加载规则文件时自动填充
 	Suggestions      []string          `yaml:"suggestions"`
 }
 
@@ -33,9 +39,11 @@ type Finding struct {
 	RuleName        string
 	Severity        string
 	Title           string
-	Evidence        map[string]string
+	Evidence        Evidence
 	Suggestions     []string
-	IsCrossAnalysis bool // 是否为联合分析发现
+	IsCrossAnalysis bool     // 是否为联合分析发现
+	ProfileTypes    []string // 发现涉及的 profile 类型，联合分析发现包含多个类型
+	File            string   // 单文件规则 (scope: file) 触发的文件路径，分组规则为空
 }
 
 // RulesConfig 规则配置文件结构
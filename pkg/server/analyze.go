@@ -0,0 +1,216 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/pprof/profile"
+	"github.com/songzhibin97/perfinspector/pkg/locator"
+	"github.com/songzhibin97/perfinspector/pkg/perfinspector"
+	"github.com/songzhibin97/perfinspector/pkg/reporter"
+)
+
+// DefaultMaxUploadSize POST /analyze 请求体的默认上限
+const DefaultMaxUploadSize int64 = 32 << 20
+
+// uploadedProfile 保存到临时目录的上传文件
+type uploadedProfile struct {
+	name string // 上传时的文件名，报告中代替临时路径
+	path string
+}
+
+// serveAnalyze 分析 multipart 上传的 profile 文件并返回 JSON 报告
+// 任意字段名的文件都会参与分析；请求体超过 MaxUploadSize 时返回 413，
+// 没有上传文件或文件无法解析为 profile 时返回 400 和解析错误
+func (h *Handler) serveAnalyze(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.config.MaxUploadSize)
+	if err := r.ParseMultipartForm(h.config.MaxUploadSize); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, fmt.Sprintf("upload exceeds %d bytes", tooLarge.Limit), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, fmt.Sprintf("invalid multipart upload: %v", err), http.StatusBadRequest)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	dir, err := os.MkdirTemp("", "perfinspector-upload-")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	uploads, err := saveUploads(dir, r.MultipartForm)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	paths := make([]string, len(uploads))
+	for i, upload := range uploads {
+		paths[i] = upload.path
+	}
+	ctx := r.Context()
+	if h.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.config.Timeout)
+		defer cancel()
+	}
+	result, err := perfinspector.AnalyzeCtx(ctx, paths, h.config.Options)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("analysis failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+	restoreUploadNames(result, dir, uploads)
+
+	report := &reporter.Report{
+		Groups:   result.Groups,
+		Trends:   result.Trends,
+		Findings: result.Findings,
+		Contexts: result.Contexts,
+		Options:  h.config.ReportOptions,
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := reporter.WriteJSONReport(w, report); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// saveUploads 校验上传的文件都能解析为 profile，并保存到 dir
+// 每个文件放在以序号命名的子目录中，保留原文件名以便按文件名排序和识别序号
+func saveUploads(dir string, form *multipart.Form) ([]uploadedProfile, error) {
+	var headers []*multipart.FileHeader
+	for _, field := range sortedKeys(form.File) {
+		headers = append(headers, form.File[field]...)
+	}
+	if len(headers) == 0 {
+		return nil, errors.New("no profile files uploaded")
+	}
+
+	uploads := make([]uploadedProfile, 0, len(headers))
+	for i, header := range headers {
+		name := filepath.Base(header.Filename)
+		if name == "." || name == string(filepath.Separator) {
+			name = "profile"
+		}
+		path := filepath.Join(dir, strconv.Itoa(i), name)
+		if err := saveUpload(header, path); err != nil {
+			return nil, fmt.Errorf("failed to parse profile '%s': %w", header.Filename, err)
+		}
+		uploads = append(uploads, uploadedProfile{name: header.Filename, path: path})
+	}
+	return uploads, nil
+}
+
+// saveUpload 解析上传的文件，成功后将原始内容写入 path
+func saveUpload(header *multipart.FileHeader, path string) error {
+	src, err := header.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	if _, err := profile.Parse(src); err != nil {
+		return err
+	}
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	dst, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// restoreUploadNames 将分析结果中的临时路径替换为上传时的文件名
+// 文件路径会出现在文件列表、单文件发现、证据、建议和调试命令中，请求结束后临时文件即被删除，
+// 这些位置都改用客户端本地的文件名；残留的临时目录前缀一并去掉，不向客户端暴露服务端的目录结构
+func restoreUploadNames(result *perfinspector.Result, dir string, uploads []uploadedProfile) {
+	pairs := make([]string, 0, 2*len(uploads)+2)
+	for _, upload := range uploads {
+		pairs = append(pairs, upload.path, upload.name)
+	}
+	pairs = append(pairs, dir+string(filepath.Separator), "", dir, "")
+	replacer := strings.NewReplacer(pairs...)
+
+	for _, group := range result.Groups {
+		for i := range group.Files {
+			file := &group.Files[i]
+			file.Path = replacer.Replace(file.Path)
+			file.DuplicateOf = replacer.Replace(file.DuplicateOf)
+		}
+	}
+
+	for i := range result.Findings {
+		finding := &result.Findings[i]
+		finding.Title = replacer.Replace(finding.Title)
+		finding.File = replacer.Replace(finding.File)
+		for j := range finding.Evidence {
+			finding.Evidence[j].Display = replacer.Replace(finding.Evidence[j].Display)
+		}
+		for j := range finding.Suggestions {
+			finding.Suggestions[j] = replacer.Replace(finding.Suggestions[j])
+		}
+	}
+
+	for _, ctx := range result.Contexts {
+		if ctx == nil {
+			continue
+		}
+		ctx.Title = replacer.Replace(ctx.Title)
+		ctx.Explanation = replacer.Replace(ctx.Explanation)
+		ctx.Impact = replacer.Replace(ctx.Impact)
+		for j := range ctx.Commands {
+			restoreCommand(&ctx.Commands[j], replacer)
+		}
+		if ctx.PrimaryCommand != nil {
+			restoreCommand(ctx.PrimaryCommand, replacer)
+		}
+		for j := range ctx.Suggestions {
+			ctx.Suggestions[j].Content = replacer.Replace(ctx.Suggestions[j].Content)
+		}
+	}
+}
+
+// restoreCommand 替换调试命令中的临时路径
+func restoreCommand(cmd *locator.ExecutableCmd, replacer *strings.Replacer) {
+	cmd.Command = replacer.Replace(cmd.Command)
+	cmd.Description = replacer.Replace(cmd.Description)
+	cmd.OutputHint = replacer.Replace(cmd.OutputHint)
+}
+
+// sortedKeys 按字段名排序返回 multipart 表单的文件字段，map 遍历顺序不固定
+func sortedKeys(files map[string][]*multipart.FileHeader) []string {
+	keys := make([]string, 0, len(files))
+	for key := range files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
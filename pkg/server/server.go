@@ -0,0 +1,258 @@
+// Package server 以 HTTP 服务的形式提供实时的 HTML 报告
+// profile 文件变化后重新分析，浏览器中打开的报告自动刷新；
+// POST /analyze 分析随请求上传的 profile 文件并返回 JSON 报告
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/songzhibin97/perfinspector/pkg/perfinspector"
+	"github.com/songzhibin97/perfinspector/pkg/reporter"
+)
+
+// DefaultPollInterval 检查 profile 文件变化的默认间隔
+const DefaultPollInterval = 2 * time.Second
+
+// Config 服务配置
+type Config struct {
+	Inputs        []string                                // profile 目录、文件或 glob 模式
+	Resolve       func(inputs []string) ([]string, error) // 将 Inputs 展开为 profile 文件列表，为 nil 时 Inputs 即文件列表
+	Options       perfinspector.Options                   // 分析选项
+	ReportOptions reporter.Options                        // HTML 报告渲染选项
+	PollInterval  time.Duration                           // 检查 profile 文件变化的间隔，<= 0 时使用 DefaultPollInterval
+	Timeout       time.Duration                           // 单次分析的超时，0 表示不限制
+	MaxUploadSize int64                                   // POST /analyze 请求体的上限 (字节)，<= 0 时使用 DefaultMaxUploadSize
+}
+
+// Handler 提供 HTML 报告的 http.Handler
+// 渲染结果缓存到 profile 文件 (路径、大小、修改时间) 发生变化为止；并发请求共用同一次渲染，
+// 渲染期间不持有锁，/version 和 Refresh 不会被耗时的分析阻塞
+type Handler struct {
+	config Config
+	mux    *http.ServeMux
+
+	mu          sync.Mutex
+	fingerprint string      // 最近一次检查时 profile 文件的快照
+	version     uint64      // profile 文件变化的次数，页面据此判断是否需要刷新
+	cache       []byte      // 当前版本的渲染结果，nil 表示需要重新渲染
+	rendering   *renderCall // 正在进行的渲染，nil 表示没有
+}
+
+// renderCall 一次进行中的渲染，同一版本的并发请求等待 done 后共用结果
+type renderCall struct {
+	version uint64
+	done    chan struct{}
+	page    []byte
+	err     error
+}
+
+// NewHandler 创建报告 Handler，并记录 profile 文件的初始快照
+func NewHandler(config Config) *Handler {
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultPollInterval
+	}
+	if config.MaxUploadSize <= 0 {
+		config.MaxUploadSize = DefaultMaxUploadSize
+	}
+	h := &Handler{config: config, mux: http.NewServeMux()}
+	h.fingerprint = h.snapshot()
+	h.mux.HandleFunc("/", h.serveReport)
+	h.mux.HandleFunc("/version", h.serveVersion)
+	h.mux.HandleFunc("/analyze", h.serveAnalyze)
+	return h
+}
+
+// Serve 在 addr 上启动 HTTP 服务，阻塞直到服务出错
+// 后台按 PollInterval 轮询 profile 文件的修改时间，变化后下一次请求重新分析
+func Serve(addr string, config Config) error {
+	h := NewHandler(config)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go h.Watch(ctx)
+	return http.ListenAndServe(addr, h)
+}
+
+// ServeHTTP 实现 http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// Watch 按 PollInterval 检查 profile 文件变化，直到 ctx 取消
+func (h *Handler) Watch(ctx context.Context) {
+	ticker := time.NewTicker(h.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.Refresh()
+		}
+	}
+}
+
+// Refresh 检查 profile 文件是否变化，变化时丢弃缓存的渲染结果并返回 true
+func (h *Handler) Refresh() bool {
+	fingerprint := h.snapshot()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if fingerprint == h.fingerprint {
+		return false
+	}
+	h.fingerprint = fingerprint
+	h.version++
+	h.cache = nil
+	return true
+}
+
+// serveReport 返回当前版本的 HTML 报告，分析失败时返回 500 和错误信息
+func (h *Handler) serveReport(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	page, err := h.page()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(page)
+}
+
+// page 返回当前版本的渲染结果，没有缓存时渲染
+// 在锁内记录版本号，在锁外分析和渲染，完成后版本未变化时才写入缓存；
+// 同一版本已有渲染在进行时等待它完成，不重复分析
+func (h *Handler) page() ([]byte, error) {
+	h.mu.Lock()
+	if h.cache != nil {
+		page := h.cache
+		h.mu.Unlock()
+		return page, nil
+	}
+	if call := h.rendering; call != nil && call.version == h.version {
+		h.mu.Unlock()
+		<-call.done
+		return call.page, call.err
+	}
+	call := &renderCall{version: h.version, done: make(chan struct{})}
+	h.rendering = call
+	h.mu.Unlock()
+
+	call.page, call.err = h.render(call.version)
+
+	h.mu.Lock()
+	if call.err == nil && h.version == call.version {
+		h.cache = call.page
+	}
+	if h.rendering == call {
+		h.rendering = nil
+	}
+	h.mu.Unlock()
+	close(call.done)
+	return call.page, call.err
+}
+
+// serveVersion 返回 profile 文件变化的次数，供页面轮询
+func (h *Handler) serveVersion(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	version := h.version
+	h.mu.Unlock()
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	fmt.Fprint(w, version)
+}
+
+// render 分析 profile 文件并渲染 HTML 报告，页面中嵌入版本号用于自动刷新
+func (h *Handler) render(version uint64) ([]byte, error) {
+	paths, err := h.resolve()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	if h.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.config.Timeout)
+		defer cancel()
+	}
+	result, err := perfinspector.AnalyzeCtx(ctx, paths, h.config.Options)
+	if err != nil {
+		return nil, fmt.Errorf("analysis failed: %w", err)
+	}
+
+	var buf bytes.Buffer
+	report := &reporter.Report{
+		Groups:   result.Groups,
+		Trends:   result.Trends,
+		Findings: result.Findings,
+		Contexts: result.Contexts,
+		Options:  h.config.ReportOptions,
+	}
+	if err := reporter.WriteHTMLReport(&buf, report); err != nil {
+		return nil, fmt.Errorf("report generation failed: %w", err)
+	}
+	return injectAutoRefresh(buf.Bytes(), version, h.config.PollInterval), nil
+}
+
+// resolve 将 Inputs 展开为 profile 文件列表
+func (h *Handler) resolve() ([]string, error) {
+	if h.config.Resolve == nil {
+		return h.config.Inputs, nil
+	}
+	return h.config.Resolve(h.config.Inputs)
+}
+
+// snapshot 返回 profile 文件的快照 (路径、大小、修改时间)，展开失败时快照为错误信息
+func (h *Handler) snapshot() string {
+	paths, err := h.resolve()
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	entries := make([]string, 0, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			entries = append(entries, path+"|missing")
+			continue
+		}
+		entries = append(entries, fmt.Sprintf("%s|%d|%d", path, info.Size(), info.ModTime().UnixNano()))
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, "\n")
+}
+
+// injectAutoRefresh 在页面末尾加入轮询 /version 的脚本，版本变化时重新加载页面
+func injectAutoRefresh(page []byte, version uint64, interval time.Duration) []byte {
+	script := fmt.Sprintf(`<script>
+(function() {
+    var version = "%s";
+    setInterval(function() {
+        fetch("version", {cache: "no-store"})
+            .then(function(r) { return r.text(); })
+            .then(function(v) { if (v !== version) { location.reload(); } })
+            .catch(function() {});
+    }, %d);
+})();
+</script>
+`, strconv.FormatUint(version, 10), interval.Milliseconds())
+
+	idx := bytes.LastIndex(page, []byte("</body>"))
+	if idx < 0 {
+		return append(page, script...)
+	}
+	result := make([]byte, 0, len(page)+len(script))
+	result = append(result, page[:idx]...)
+	result = append(result, script...)
+	return append(result, page[idx:]...)
+}
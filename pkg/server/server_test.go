@@ -0,0 +1,318 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/pprof/profile"
+	"github.com/songzhibin97/perfinspector/pkg/perfinspector"
+	"github.com/songzhibin97/perfinspector/pkg/reporter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeHeapProfile 在 dir 中写入第 i 个 heap profile
+func writeHeapProfile(t *testing.T, dir string, i int) string {
+	fn := &profile.Function{ID: 1, Name: "github.com/myapp/cache.(*Store).Put", Filename: "/src/myapp/cache/store.go"}
+	loc := &profile.Location{ID: 1, Line: []profile.Line{{Function: fn, Line: 42}}}
+	size := int64(i) * 64 << 20
+	p := &profile.Profile{
+		TimeNanos: time.Date(2023, 11, 15, 14, i, 0, 0, time.UTC).UnixNano(),
+		SampleType: []*profile.ValueType{
+			{Type: "alloc_objects", Unit: "count"},
+			{Type: "alloc_space", Unit: "bytes"},
+			{Type: "inuse_objects", Unit: "count"},
+			{Type: "inuse_space", Unit: "bytes"},
+		},
+		Sample:   []*profile.Sample{{Location: []*profile.Location{loc}, Value: []int64{int64(i), size, int64(i), size}}},
+		Location: []*profile.Location{loc},
+		Function: []*profile.Function{fn},
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("heap.%03d.pprof", i))
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, p.Write(f))
+	require.NoError(t, f.Close())
+	return path
+}
+
+// cpuProfileBytes 返回第 i 个 30 秒 CPU profile 的编码内容
+func cpuProfileBytes(t *testing.T, i int) []byte {
+	fn := &profile.Function{ID: 1, Name: "github.com/myapp/api.(*Server).Handle", Filename: "/src/myapp/api/server.go"}
+	loc := &profile.Location{ID: 1, Line: []profile.Line{{Function: fn, Line: 17}}}
+	p := &profile.Profile{
+		TimeNanos:     time.Date(2023, 11, 15, 14, i, 0, 0, time.UTC).UnixNano(),
+		DurationNanos: int64(30 * time.Second),
+		SampleType: []*profile.ValueType{
+			{Type: "samples", Unit: "count"},
+			{Type: "cpu", Unit: "nanoseconds"},
+		},
+		PeriodType: &profile.ValueType{Type: "cpu", Unit: "nanoseconds"},
+		Period:     int64(10 * time.Millisecond),
+		Sample:     []*profile.Sample{{Location: []*profile.Location{loc}, Value: []int64{int64(i) * 100, int64(i) * int64(time.Second)}}},
+		Location:   []*profile.Location{loc},
+		Function:   []*profile.Function{fn},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, p.Write(&buf))
+	return buf.Bytes()
+}
+
+// upload 以 multipart 表单的 profile 字段上传文件 (文件名 -> 内容) 到 /analyze
+func upload(t *testing.T, h http.Handler, files map[string][]byte) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, name := range []string{"cpu.001.pprof", "cpu.002.pprof", "broken.pprof"} {
+		data, ok := files[name]
+		if !ok {
+			continue
+		}
+		part, err := mw.CreateFormFile("profile", name)
+		require.NoError(t, err)
+		_, err = part.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, mw.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/analyze", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+// globResolve 展开目录中的 .pprof 文件
+func globResolve(inputs []string) ([]string, error) {
+	var paths []string
+	for _, input := range inputs {
+		matches, err := filepath.Glob(filepath.Join(input, "*.pprof"))
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, matches...)
+	}
+	if len(paths) == 0 {
+		return nil, errors.New("no profile files")
+	}
+	return paths, nil
+}
+
+func newTestHandler(dir string) *Handler {
+	return NewHandler(Config{
+		Inputs:        []string{dir},
+		Resolve:       globResolve,
+		Options:       perfinspector.DefaultOptions(),
+		ReportOptions: reporter.DefaultOptions(),
+	})
+}
+
+func get(t *testing.T, h http.Handler, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
+
+func TestHandler_ServeReport(t *testing.T) {
+	dir := t.TempDir()
+	writeHeapProfile(t, dir, 1)
+	h := newTestHandler(dir)
+
+	rec := get(t, h, "/")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+	body := rec.Body.String()
+	assert.Contains(t, body, "PerfInspector 分析报告")
+	assert.Contains(t, body, "heap.001.pprof")
+	assert.Contains(t, body, `var version = "0";`)
+	assert.True(t, strings.Index(body, "<script>") < strings.LastIndex(body, "</body>"))
+
+	assert.Equal(t, "0", get(t, h, "/version").Body.String())
+	assert.Equal(t, http.StatusNotFound, get(t, h, "/favicon.ico").Code)
+}
+
+// TestHandler_Refresh profile 文件变化后丢弃缓存，下一次请求重新分析
+func TestHandler_Refresh(t *testing.T) {
+	dir := t.TempDir()
+	writeHeapProfile(t, dir, 1)
+	h := newTestHandler(dir)
+	require.Contains(t, get(t, h, "/").Body.String(), "heap.001.pprof")
+
+	// 没有变化时保留缓存
+	assert.False(t, h.Refresh())
+
+	// 新文件只有在检查到变化后才出现在报告中
+	writeHeapProfile(t, dir, 2)
+	assert.NotContains(t, get(t, h, "/").Body.String(), "heap.002.pprof")
+	assert.True(t, h.Refresh())
+	assert.Equal(t, "1", get(t, h, "/version").Body.String())
+
+	body := get(t, h, "/").Body.String()
+	assert.Contains(t, body, "heap.002.pprof")
+	assert.Contains(t, body, `var version = "1";`)
+}
+
+// TestHandler_AnalysisError 分析失败时返回 500 和错误信息，不缓存失败结果
+func TestHandler_AnalysisError(t *testing.T) {
+	dir := t.TempDir()
+	h := newTestHandler(dir)
+
+	rec := get(t, h, "/")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Contains(t, rec.Body.String(), "no profile files")
+
+	// 失败不缓存，文件出现后恢复
+	writeHeapProfile(t, dir, 1)
+	assert.True(t, h.Refresh())
+	assert.Equal(t, http.StatusOK, get(t, h, "/").Code)
+}
+
+// TestHandler_Concurrent 并发请求共用同一次渲染
+func TestHandler_Concurrent(t *testing.T) {
+	dir := t.TempDir()
+	writeHeapProfile(t, dir, 1)
+	h := newTestHandler(dir)
+
+	var wg sync.WaitGroup
+	bodies := make([]string, 8)
+	for i := range bodies {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%2 == 0 {
+				h.Refresh()
+			}
+			bodies[i] = get(t, h, "/").Body.String()
+		}(i)
+	}
+	wg.Wait()
+	for _, body := range bodies {
+		assert.Contains(t, body, "heap.001.pprof")
+	}
+}
+
+// TestHandler_RenderUnlocked 渲染期间不持有锁：/version 和 Refresh 立即返回，
+// 渲染期间文件变化时不缓存旧版本的结果
+func TestHandler_RenderUnlocked(t *testing.T) {
+	dir := t.TempDir()
+	writeHeapProfile(t, dir, 1)
+	var block atomic.Bool
+	started := make(chan struct{})
+	release := make(chan struct{})
+	h := NewHandler(Config{
+		Inputs: []string{dir},
+		Resolve: func(inputs []string) ([]string, error) {
+			if block.CompareAndSwap(true, false) {
+				close(started)
+				<-release
+			}
+			return globResolve(inputs)
+		},
+		Options:       perfinspector.DefaultOptions(),
+		ReportOptions: reporter.DefaultOptions(),
+	})
+
+	block.Store(true)
+	done := make(chan string)
+	go func() { done <- get(t, h, "/").Body.String() }()
+	<-started
+
+	assert.Equal(t, "0", get(t, h, "/version").Body.String())
+	writeHeapProfile(t, dir, 2)
+	assert.True(t, h.Refresh())
+	assert.Equal(t, "1", get(t, h, "/version").Body.String())
+
+	close(release)
+	assert.Contains(t, <-done, `var version = "0";`)
+	body := get(t, h, "/").Body.String()
+	assert.Contains(t, body, `var version = "1";`)
+	assert.Contains(t, body, "heap.002.pprof")
+}
+
+// TestHandler_Analyze 上传的 profile 按类型分组分析，返回 JSON 报告
+func TestHandler_Analyze(t *testing.T) {
+	options := perfinspector.DefaultOptions()
+	options.RulesPath = "../../assets/default_rules.yaml"
+	h := NewHandler(Config{Options: options, ReportOptions: reporter.DefaultOptions()})
+
+	rec := upload(t, h, map[string][]byte{
+		"cpu.001.pprof": cpuProfileBytes(t, 1),
+		"cpu.002.pprof": cpuProfileBytes(t, 2),
+	})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+
+	var report reporter.JSONReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	require.Len(t, report.Groups, 1)
+	group := report.Groups[0]
+	assert.Equal(t, "cpu", group.Type)
+	require.Len(t, group.Files, 2)
+
+	// 报告中使用上传时的文件名，而不是临时路径
+	assert.Equal(t, "cpu.001.pprof", group.Files[0].Path)
+	assert.Equal(t, "cpu.002.pprof", group.Files[1].Path)
+	require.NotNil(t, group.Files[1].Metrics)
+	assert.Equal(t, int64(2*time.Second), group.Files[1].Metrics.CPUTimeNs)
+
+	// 调试命令指向上传时的文件名，响应中不出现服务端的临时目录
+	require.NotEmpty(t, report.Findings)
+	var commands []string
+	for _, finding := range report.Findings {
+		if finding.Context != nil {
+			for _, cmd := range finding.Context.Commands {
+				commands = append(commands, cmd.Command)
+			}
+		}
+	}
+	require.NotEmpty(t, commands)
+	assert.Contains(t, strings.Join(commands, "\n"), "cpu.002.pprof")
+	assert.NotContains(t, rec.Body.String(), filepath.Join(os.TempDir(), "perfinspector-upload-"))
+}
+
+// TestHandler_AnalyzeErrors 无法解析、缺少文件、超过上限和非 POST 请求都不分析
+func TestHandler_AnalyzeErrors(t *testing.T) {
+	h := newTestHandler(t.TempDir())
+
+	rec := upload(t, h, map[string][]byte{
+		"cpu.001.pprof": cpuProfileBytes(t, 1),
+		"broken.pprof":  []byte("not a profile"),
+	})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "failed to parse profile 'broken.pprof'")
+
+	rec = upload(t, h, nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "no profile files uploaded")
+
+	small := NewHandler(Config{Options: perfinspector.DefaultOptions(), MaxUploadSize: 64})
+	rec = upload(t, small, map[string][]byte{"cpu.001.pprof": cpuProfileBytes(t, 1)})
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	rec = get(t, h, "/analyze")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, http.MethodPost, rec.Header().Get("Allow"))
+}
+
+func TestInjectAutoRefresh(t *testing.T) {
+	page := injectAutoRefresh([]byte("<html><body><p>x</p></body></html>"), 3, 2*time.Second)
+	assert.Contains(t, string(page), `var version = "3";`)
+	assert.Contains(t, string(page), "}, 2000);")
+	assert.True(t, strings.HasSuffix(string(page), "</script>\n</body></html>"))
+
+	// 没有 </body> 时追加到末尾
+	assert.True(t, strings.HasSuffix(string(injectAutoRefresh([]byte("<p>x</p>"), 0, time.Second)), "</script>\n"))
+}